@@ -5,10 +5,9 @@ package builtins
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
 
 	"sigs.k8s.io/kustomize/api/filters/imagetag"
+	"sigs.k8s.io/kustomize/api/image"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filtersutil"
@@ -55,10 +54,10 @@ func (p *ImageTagTransformerPlugin) mutateImage(in interface{}) (interface{}, er
 		return nil, fmt.Errorf("image path is not of type string but %T", in)
 	}
 
-	if !isImageMatched(original, p.ImageTag.Name) {
+	if !image.IsImageMatched(original, p.ImageTag.Name) {
 		return original, nil
 	}
-	name, tag := split(original)
+	name, tag := image.Split(original)
 	if p.ImageTag.NewName != "" {
 		name = p.ImageTag.NewName
 	}
@@ -108,7 +107,7 @@ func (p *ImageTagTransformerPlugin) updateContainers(in interface{}) (interface{
 			continue
 		}
 		imageName := containerImage.(string)
-		if isImageMatched(imageName, p.ImageTag.Name) {
+		if image.IsImageMatched(imageName, p.ImageTag.Name) {
 			newImage, err := p.mutateImage(imageName)
 			if err != nil {
 				return nil, err
@@ -143,45 +142,6 @@ func (p *ImageTagTransformerPlugin) findContainers(obj map[string]interface{}) e
 	return nil
 }
 
-func isImageMatched(s, t string) bool {
-	// Tag values are limited to [a-zA-Z0-9_.{}-].
-	// Some tools like Bazel rules_k8s allow tag patterns with {} characters.
-	// More info: https://github.com/bazelbuild/rules_k8s/pull/423
-	pattern, _ := regexp.Compile("^" + t + "(@sha256)?(:[a-zA-Z0-9_.{}-]*)?$")
-	return pattern.MatchString(s)
-}
-
-// split separates and returns the name and tag parts
-// from the image string using either colon `:` or at `@` separators.
-// Note that the returned tag keeps its separator.
-func split(imageName string) (name string, tag string) {
-	// check if image name contains a domain
-	// if domain is present, ignore domain and check for `:`
-	ic := -1
-	if slashIndex := strings.Index(imageName, "/"); slashIndex < 0 {
-		ic = strings.LastIndex(imageName, ":")
-	} else {
-		lastIc := strings.LastIndex(imageName[slashIndex:], ":")
-		// set ic only if `:` is present
-		if lastIc > 0 {
-			ic = slashIndex + lastIc
-		}
-	}
-	ia := strings.LastIndex(imageName, "@")
-	if ic < 0 && ia < 0 {
-		return imageName, ""
-	}
-
-	i := ic
-	if ia > 0 {
-		i = ia
-	}
-
-	name = imageName[:i]
-	tag = imageName[i:]
-	return
-}
-
 func NewImageTagTransformerPlugin() resmap.TransformerPlugin {
 	return &ImageTagTransformerPlugin{}
 }
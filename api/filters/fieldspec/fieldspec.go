@@ -5,6 +5,7 @@ package fieldspec
 
 import (
 	"strings"
+	"sync"
 
 	"sigs.k8s.io/kustomize/api/filters/filtersutil"
 	"sigs.k8s.io/kustomize/api/types"
@@ -160,7 +161,21 @@ func isMatchGVK(fs types.FieldSpec, obj *yaml.RNode) (bool, error) {
 	return true, nil
 }
 
+// splitPaths caches the result of splitPath for each path, since
+// the same few paths are applied to every resource.  The cached
+// slices are only ever resliced, never changed.
+var splitPaths sync.Map
+
 func splitPath(path string) []string {
+	if ps, ok := splitPaths.Load(path); ok {
+		return ps.([]string)
+	}
+	ps, _ := splitPaths.LoadOrStore(path, parsePath(path))
+	return ps.([]string)
+}
+
+// parsePath splits path on the slashes not escaped by a backslash.
+func parsePath(path string) []string {
 	ps := strings.Split(path, "/")
 	var res []string
 	res = append(res, ps[0])
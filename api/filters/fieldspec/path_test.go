@@ -0,0 +1,40 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fieldspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPath(t *testing.T) {
+	for path, expected := range map[string][]string{
+		"spec/template/metadata/labels": {"spec", "template", "metadata", "labels"},
+		`metadata/annotations/a.io\/b`:  {"metadata", "annotations", "a.io/b"},
+		"name":                          {"name"},
+	} {
+		assert.Equal(t, expected, splitPath(path))
+		// the second call is answered from the cache
+		assert.Equal(t, expected, splitPath(path))
+	}
+}
+
+// BenchmarkSplitPath compares splitting with the cache
+// with parsing the path each time.
+func BenchmarkSplitPath(b *testing.B) {
+	path := "spec/template/spec/containers/env/valueFrom/configMapKeyRef/name"
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			splitPath(path)
+		}
+	})
+	b.Run("parsed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			parsePath(path)
+		}
+	})
+}
@@ -6,16 +6,30 @@ package image
 import (
 	"regexp"
 	"strings"
+	"sync"
 )
 
+// patterns caches the compiled pattern of each image name, since
+// every container of every resource is matched against the same
+// few names.
+var patterns sync.Map
+
 // IsImageMatched returns true if the value of t is identical to the
 // image name in the full image name and tag as given by s.
 func IsImageMatched(s, t string) bool {
-	// Tag values are limited to [a-zA-Z0-9_.{}-].
-	// Some tools like Bazel rules_k8s allow tag patterns with {} characters.
-	// More info: https://github.com/bazelbuild/rules_k8s/pull/423
-	pattern, _ := regexp.Compile("^" + t + "(@sha256)?(:[a-zA-Z0-9_.{}-]*)?$")
-	return pattern.MatchString(s)
+	pattern, ok := patterns.Load(t)
+	if !ok {
+		// Tag values are limited to [a-zA-Z0-9_.{}-].
+		// Some tools like Bazel rules_k8s allow tag patterns with {} characters.
+		// More info: https://github.com/bazelbuild/rules_k8s/pull/423
+		p, err := regexp.Compile("^" + t + "(@sha256)?(:[a-zA-Z0-9_.{}-]*)?$")
+		if err != nil {
+			// a name which isn't a valid pattern matches nothing
+			return false
+		}
+		pattern, _ = patterns.LoadOrStore(t, p)
+	}
+	return pattern.(*regexp.Regexp).MatchString(s)
 }
 
 // Split separates and returns the name and tag parts
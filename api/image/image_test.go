@@ -4,6 +4,7 @@
 package image
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,6 +44,18 @@ func TestIsImageMatched(t *testing.T) {
 	}
 }
 
+func TestIsImageMatchedCachesPatterns(t *testing.T) {
+	assert.True(t, IsImageMatched("busybox:1.0", "busybox"))
+	p, ok := patterns.Load("busybox")
+	assert.True(t, ok)
+	assert.True(t, IsImageMatched("busybox@sha256", "busybox"))
+	q, _ := patterns.Load("busybox")
+	assert.True(t, p == q)
+
+	// a name which isn't a valid pattern matches nothing
+	assert.False(t, IsImageMatched("busybox", "busy(box"))
+}
+
 func TestSplit(t *testing.T) {
 	testCases := []struct {
 		testName string
@@ -78,3 +91,21 @@ func TestSplit(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkIsImageMatched compares matching with the cached pattern
+// with compiling the pattern for each match.
+func BenchmarkIsImageMatched(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			IsImageMatched("gcr.io/project/nginx:1.7.9", "gcr.io/project/nginx")
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p, _ := regexp.Compile("^gcr.io/project/nginx(@sha256)?(:[a-zA-Z0-9_.{}-]*)?$")
+			p.MatchString("gcr.io/project/nginx:1.7.9")
+		}
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// The grammar of image names of docker's distribution.
+	hostComponent = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	host          = hostComponent + `(?:\.` + hostComponent + `)*(?::[0-9]+)?`
+	pathComponent = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+	nameRegex     = regexp.MustCompile(
+		`^(?:` + host + `/)?` + pathComponent + `(?:/` + pathComponent + `)*$`)
+
+	// Some tools like Bazel rules_k8s allow tag patterns
+	// with {} characters, see IsImageMatched.
+	tagRegex    = regexp.MustCompile(`^[\w{}][\w.{}-]{0,127}$`)
+	digestRegex = regexp.MustCompile(`^([a-z0-9]+(?:[+._-][a-z0-9]+)*):([a-zA-Z0-9=_-]+)$`)
+	hexRegex    = regexp.MustCompile(`^[a-f0-9]+$`)
+
+	// digestLengths are the hex digits of the
+	// digests of the registered algorithms.
+	digestLengths = map[string]int{"sha256": 64, "sha384": 96, "sha512": 128}
+)
+
+// ValidateName returns an error if name isn't an image
+// name without a tag or digest, e.g. nginx, or
+// registry.example.com:5000/team/app.
+func ValidateName(name string) error {
+	if nameRegex.MatchString(name) {
+		return nil
+	}
+	if n, tag := Split(name); tag != "" && nameRegex.MatchString(n) {
+		if strings.HasPrefix(tag, "@") {
+			return fmt.Errorf("image name %q has a digest; give it as the digest", name)
+		}
+		return fmt.Errorf("image name %q has a tag; give it as the new tag", name)
+	}
+	return fmt.Errorf("invalid image name %q: names are lowercase words "+
+		"separated by '/', '.', '_' or '-', optionally after a registry host", name)
+}
+
+// ValidateTag returns an error if tag isn't an image tag, e.g. 1.19.
+func ValidateTag(tag string) error {
+	if tagRegex.MatchString(tag) {
+		return nil
+	}
+	return fmt.Errorf("invalid tag %q: tags are up to 128 letters, digits, "+
+		"'_', '.' and '-', and don't start with '.' or '-'", tag)
+}
+
+// ValidateDigest returns an error if digest isn't the digest
+// of an image, e.g. sha256: and 64 hex digits.
+func ValidateDigest(digest string) error {
+	m := digestRegex.FindStringSubmatch(digest)
+	if m == nil {
+		return fmt.Errorf("invalid digest %q: digests are an algorithm "+
+			"and an encoded hash, e.g. sha256:, then 64 hex digits", digest)
+	}
+	length, registered := digestLengths[m[1]]
+	if !registered {
+		return nil
+	}
+	if !hexRegex.MatchString(m[2]) {
+		return fmt.Errorf("invalid digest %q: %s digests are lowercase hex digits", digest, m[1])
+	}
+	if len(m[2]) != length {
+		return fmt.Errorf("invalid digest %q: %s digests have %d hex digits, not %d",
+			digest, m[1], length, len(m[2]))
+	}
+	return nil
+}
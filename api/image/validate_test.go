@@ -0,0 +1,74 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	testCases := map[string]string{
+		"nginx":                              "",
+		"library/nginx":                      "",
+		"registry.example.com:5000/team/app": "",
+		"gcr.io/my-project/app_v2":           "",
+		"nginx:1.19":                         `image name "nginx:1.19" has a tag; give it as the new tag`,
+		"nginx@sha256:24a0c4b4":              `image name "nginx@sha256:24a0c4b4" has a digest; give it as the digest`,
+		"Nginx":                              `invalid image name "Nginx": names are lowercase words`,
+		"app/":                               `invalid image name "app/"`,
+	}
+	for name, expected := range testCases {
+		t.Run(name, func(t *testing.T) {
+			checkError(t, ValidateName(name), expected)
+		})
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	testCases := map[string]string{
+		"1.19":                   "",
+		"v1.0.1-rc_2":            "",
+		"{STABLE_GIT_COMMIT}":    "",
+		".hidden":                `invalid tag ".hidden": tags are up to 128`,
+		"a/b":                    `invalid tag "a/b"`,
+		strings.Repeat("a", 129): "invalid tag",
+	}
+	for tag, expected := range testCases {
+		t.Run(tag, func(t *testing.T) {
+			checkError(t, ValidateTag(tag), expected)
+		})
+	}
+}
+
+func TestValidateDigest(t *testing.T) {
+	hex := strings.Repeat("0123456789abcdef", 8)
+	testCases := map[string]string{
+		"sha256:" + hex[:64]:                  "",
+		"sha512:" + hex:                       "",
+		"other+b64:abc=":                      "",
+		"24a0c4b4":                            `invalid digest "24a0c4b4": digests are an algorithm`,
+		"sha256:24a0c4b4":                     `invalid digest "sha256:24a0c4b4": sha256 digests have 64 hex digits, not 8`,
+		"sha512:" + hex[:64]:                  "sha512 digests have 128 hex digits, not 64",
+		"sha256:" + strings.ToUpper(hex[:64]): "sha256 digests are lowercase hex digits",
+	}
+	for digest, expected := range testCases {
+		t.Run(digest, func(t *testing.T) {
+			checkError(t, ValidateDigest(digest), expected)
+		})
+	}
+}
+
+func checkError(t *testing.T, err error, expected string) {
+	t.Helper()
+	if expected == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected an error containing %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package accumulator
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/transform"
+)
+
+// DanglingReferenceTargets are the kinds references to which
+// are checked by DanglingReferences.  References to other
+// kinds commonly name objects made outside of kustomize.
+var DanglingReferenceTargets = []resid.Gvk{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+}
+
+// DanglingReference is a reference, in the field at Path of
+// Referrer, to a Target named Name which isn't accumulated.
+type DanglingReference struct {
+	Referrer resid.ResId
+	Path     string
+	Target   resid.Gvk
+	Name     string
+}
+
+func (d DanglingReference) String() string {
+	return fmt.Sprintf("%s %s: %s %s not found",
+		d.Referrer, d.Path, d.Target.Kind, d.Name)
+}
+
+// DanglingReferences returns the references, by the name
+// reference fields of the accumulated configuration, to
+// DanglingReferenceTargets which name none of the
+// accumulated resources.  It's meant to be called after
+// FixBackReferences, so that references hold the names
+// the resources have in the output.
+func (ra *ResAccumulator) DanglingReferences() ([]DanglingReference, error) {
+	var result []DanglingReference
+	seen := map[string]bool{}
+	for _, referrer := range ra.resMap.Resources() {
+		for _, backRef := range ra.tConfig.NameReference {
+			if !isDanglingReferenceTarget(backRef.Gvk) {
+				continue
+			}
+			for _, fSpec := range backRef.FieldSpecs {
+				if !referrer.OrgId().IsSelected(&fSpec.Gvk) {
+					continue
+				}
+				check := func(name, namespace string) {
+					if name == "" || ra.hasNamed(backRef.Gvk, name, namespace) {
+						return
+					}
+					d := DanglingReference{
+						Referrer: referrer.CurId(),
+						Path:     fSpec.Path,
+						Target:   backRef.Gvk,
+						Name:     name,
+					}
+					if !seen[d.String()] {
+						seen[d.String()] = true
+						result = append(result, d)
+					}
+				}
+				err := transform.MutateField(
+					referrer.Map(), fSpec.PathSlice(), false,
+					referencedNamesFunc(referrer, check))
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func isDanglingReferenceTarget(gvk resid.Gvk) bool {
+	for _, t := range DanglingReferenceTargets {
+		if gvk.Equals(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedNamesFunc returns a function, for MutateField,
+// which calls check with the names and namespaces the field
+// refers to, and leaves the field as it is.  References
+// without a namespace are to the namespace of the referrer.
+func referencedNamesFunc(
+	referrer *resource.Resource,
+	check func(name, namespace string)) func(in interface{}) (interface{}, error) {
+	checkMap := func(m map[string]interface{}) {
+		name, _ := m["name"].(string)
+		namespace, ok := m["namespace"].(string)
+		if !ok {
+			namespace = referrer.GetNamespace()
+		}
+		check(name, namespace)
+	}
+	return func(in interface{}) (interface{}, error) {
+		switch thing := in.(type) {
+		case string:
+			check(thing, referrer.GetNamespace())
+		case map[string]interface{}:
+			checkMap(thing)
+		case []interface{}:
+			for _, item := range thing {
+				switch value := item.(type) {
+				case string:
+					check(value, referrer.GetNamespace())
+				case map[string]interface{}:
+					checkMap(value)
+				}
+			}
+		}
+		return in, nil
+	}
+}
+
+// hasNamed returns whether a resource of kind target
+// is accumulated with the name and namespace.
+func (ra *ResAccumulator) hasNamed(target resid.Gvk, name, namespace string) bool {
+	for _, r := range ra.resMap.Resources() {
+		if r.OrgId().IsSelected(&target) && r.GetName() == name &&
+			sameNamespace(r.GetNamespace(), namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameNamespace(a, b string) bool {
+	if a == "" {
+		a = "default"
+	}
+	if b == "" {
+		b = "default"
+	}
+	return a == b
+}
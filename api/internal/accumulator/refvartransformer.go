@@ -4,9 +4,12 @@
 package accumulator
 
 import (
+	"sort"
+
 	expansion2 "sigs.k8s.io/kustomize/api/internal/accumulator/expansion"
 
 	"sigs.k8s.io/kustomize/api/filters/refvar"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filtersutil"
@@ -17,6 +20,14 @@ type refVarTransformer struct {
 	replacementCounts map[string]int
 	fieldSpecs        []types.FieldSpec
 	mappingFunc       func(string) interface{}
+	unresolved        []UnresolvedVar
+}
+
+// UnresolvedVar is a reference to a var, $(Name), in
+// Resource, which names no var.
+type UnresolvedVar struct {
+	Resource resid.ResId
+	Name     string
 }
 
 // newRefVarTransformer returns a new refVarTransformer
@@ -43,21 +54,83 @@ func (rv *refVarTransformer) UnusedVars() []string {
 	return unused
 }
 
+// UnresolvedVars returns the references to names which
+// aren't vars after a Transform run, in the order of the
+// resources, and then of the names.  Names of the env vars
+// of the containers of a resource aren't included, since
+// Kubernetes resolves those.
+func (rv *refVarTransformer) UnresolvedVars() []UnresolvedVar {
+	return rv.unresolved
+}
+
 // Transform replaces $(VAR) style variables with values.
 func (rv *refVarTransformer) Transform(m resmap.ResMap) error {
 	rv.replacementCounts = make(map[string]int)
 	rv.mappingFunc = expansion2.MappingFuncFor(
 		rv.replacementCounts, rv.varMap)
+	rv.unresolved = nil
 	for _, res := range m.Resources() {
+		var envVars map[string]bool
+		unresolved := map[string]bool{}
+		mappingFunc := func(name string) interface{} {
+			if _, found := rv.varMap[name]; !found {
+				if envVars == nil {
+					envVars = envVarNames(res.ReadOnlyMap())
+				}
+				if !envVars[name] {
+					unresolved[name] = true
+				}
+			}
+			return rv.mappingFunc(name)
+		}
 		for _, fieldSpec := range rv.fieldSpecs {
 			err := filtersutil.ApplyToJSON(refvar.Filter{
-				MappingFunc: rv.mappingFunc,
+				MappingFunc: mappingFunc,
 				FieldSpec:   fieldSpec,
 			}, res)
 			if err != nil {
 				return err
 			}
 		}
+		var names []string
+		for name := range unresolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rv.unresolved = append(rv.unresolved,
+				UnresolvedVar{Resource: res.CurId(), Name: name})
+		}
 	}
 	return nil
 }
+
+// envVarNames returns the names of the env vars
+// declared anywhere in obj, e.g. by its containers.
+func envVarNames(obj interface{}) map[string]bool {
+	names := map[string]bool{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			for k, e := range x {
+				if env, ok := e.([]interface{}); ok && k == "env" {
+					for _, item := range env {
+						if m, ok := item.(map[string]interface{}); ok {
+							if name, ok := m["name"].(string); ok {
+								names[name] = true
+							}
+						}
+					}
+				}
+				walk(e)
+			}
+		case []interface{}:
+			for _, e := range x {
+				walk(e)
+			}
+		}
+	}
+	walk(obj)
+	return names
+}
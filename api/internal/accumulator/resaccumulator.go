@@ -5,13 +5,15 @@ package accumulator
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
+	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 // ResAccumulator accumulates resources and the rules
@@ -21,6 +23,11 @@ type ResAccumulator struct {
 	resMap  resmap.ResMap
 	tConfig *builtinconfig.TransformerConfig
 	varSet  types.VarSet
+	warner  types.Warner
+
+	// strictVars makes unused vars, and references to
+	// names which aren't vars, fail ResolveVars.
+	strictVars bool
 }
 
 func MakeEmptyAccumulator() *ResAccumulator {
@@ -31,6 +38,17 @@ func MakeEmptyAccumulator() *ResAccumulator {
 	return ra
 }
 
+// DeepCopy returns a copy of the accumulator whose resources
+// can be changed without changing those of the original.
+// The transformer config is never changed in place, so it's shared.
+func (ra *ResAccumulator) DeepCopy() *ResAccumulator {
+	return &ResAccumulator{
+		resMap:  ra.resMap.DeepCopy(),
+		tConfig: ra.tConfig,
+		varSet:  ra.varSet.Copy(),
+	}
+}
+
 // ResMap returns a copy of the internal resMap.
 func (ra *ResAccumulator) ResMap() resmap.ResMap {
 	return ra.resMap.ShallowCopy()
@@ -96,6 +114,63 @@ func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
 	return ra.varSet.MergeSet(other.varSet)
 }
 
+// MergeAccumulatorWithDuplicates is like MergeAccumulator, but
+// the resources of other with the IDs of resources of ra are
+// handled as duplicates says, one of the values of
+// types.Kustomization.DuplicateResources.  Errors, and the
+// merges and replacements logged, name the files both
+// resources came from.
+func (ra *ResAccumulator) MergeAccumulatorWithDuplicates(
+	other *ResAccumulator, duplicates string) (err error) {
+	for _, res := range other.resMap.Resources() {
+		matches := ra.resMap.GetMatchingResourcesByCurrentId(res.CurId().Equals)
+		if len(matches) == 0 {
+			if err = ra.resMap.Append(res); err != nil {
+				return err
+			}
+			continue
+		}
+		old := matches[0]
+		switch duplicates {
+		case types.DuplicateResourcesMerge:
+			log.Info("merging duplicate resource", "id", res.CurId().String(),
+				"from", originOf(res), "into", originOf(old))
+			merged := old.DeepCopy()
+			if err = merged.Patch(res.Copy()); err != nil {
+				return errors.Wrapf(err, "merging %s from %s into %s",
+					res.CurId(), originOf(res), originOf(old))
+			}
+			_, err = ra.resMap.Replace(merged)
+		case types.DuplicateResourcesReplace:
+			log.Info("replacing duplicate resource", "id", res.CurId().String(),
+				"from", originOf(old), "with", originOf(res))
+			_, err = ra.resMap.Replace(res)
+		default:
+			return fmt.Errorf(
+				"may not add resource with an already registered id: %s; "+
+					"it's in both %s and %s; set duplicateResources "+
+					"to merge or replace to override it",
+				res.CurId(), originOf(old), originOf(res))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err = ra.MergeConfig(other.tConfig)
+	if err != nil {
+		return err
+	}
+	return ra.varSet.MergeSet(other.varSet)
+}
+
+// originOf describes where r came from.
+func originOf(r *resource.Resource) string {
+	if o := r.GetOrigin(); o != "" {
+		return "'" + o + "'"
+	}
+	return "a generator"
+}
+
 func (ra *ResAccumulator) findVarValueFromResources(v types.Var) (interface{}, error) {
 	for _, res := range ra.resMap.Resources() {
 		for _, varName := range res.GetRefVarNames() {
@@ -134,6 +209,27 @@ func (ra *ResAccumulator) makeVarReplacementMap() (map[string]interface{}, error
 	return result, nil
 }
 
+// SetWarner sets where the warnings of ra go.
+// They're logged if it's nil.
+func (ra *ResAccumulator) SetWarner(w types.Warner) {
+	ra.warner = w
+}
+
+// SetStrictVars sets whether vars which are never referred
+// to, and references to names which aren't vars, fail
+// ResolveVars, rather than being warned about.
+func (ra *ResAccumulator) SetStrictVars(on bool) {
+	ra.strictVars = on
+}
+
+func (ra *ResAccumulator) warn(w types.Warning) {
+	if ra.warner != nil {
+		ra.warner(w)
+		return
+	}
+	log.Warn(w.Message, w.KeysAndValues()...)
+}
+
 func (ra *ResAccumulator) Transform(t resmap.Transformer) error {
 	return t.Transform(ra.resMap)
 }
@@ -149,12 +245,39 @@ func (ra *ResAccumulator) ResolveVars() error {
 	t := newRefVarTransformer(
 		replacementMap, ra.tConfig.VarReference)
 	err = ra.Transform(t)
-	if len(t.UnusedVars()) > 0 {
-		log.Printf(
-			"well-defined vars that were never replaced: %s\n",
-			strings.Join(t.UnusedVars(), ","))
+	if err != nil {
+		return err
 	}
-	return err
+	var problems []string
+	if unused := t.UnusedVars(); len(unused) > 0 {
+		msg := "well-defined vars that were never replaced: " +
+			strings.Join(unused, ",")
+		if ra.strictVars {
+			problems = append(problems, msg)
+		} else {
+			ra.warn(types.Warning{
+				Code:    types.WarningUnusedVars,
+				Message: msg,
+			})
+		}
+	}
+	for _, u := range t.UnresolvedVars() {
+		msg := "$(" + u.Name + ") refers to no var"
+		if ra.strictVars {
+			problems = append(problems, u.Resource.String()+": "+msg)
+		} else {
+			ra.warn(types.Warning{
+				Code:     types.WarningUnresolvedVars,
+				Message:  msg,
+				Resource: u.Resource.String(),
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problems with vars:\n  %s",
+			len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
 }
 
 func (ra *ResAccumulator) FixBackReferences() (err error) {
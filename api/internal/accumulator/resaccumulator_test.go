@@ -5,8 +5,6 @@ package accumulator_test
 
 import (
 	"bytes"
-	"log"
-	"os"
 	"strings"
 	"testing"
 
@@ -18,6 +16,7 @@ import (
 	"sigs.k8s.io/kustomize/api/resource"
 	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 func makeResAccumulator(t *testing.T) (*ResAccumulator, *resource.Factory) {
@@ -118,15 +117,13 @@ func TestResolveVarsOneUnused(t *testing.T) {
 		t.Fatalf("unexpected err: %v", err)
 	}
 	var buf bytes.Buffer
-	log.SetOutput(&buf)
-	defer func() {
-		log.SetOutput(os.Stderr)
-	}()
+	defer log.SetLogger(log.GetLogger())
+	log.SetLogger(log.New(&buf, log.WarnLevel, log.TextFormat))
 	err = ra.ResolveVars()
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	expectLog(t, buf, "well-defined vars that were never replaced: SERVICE_UNUSED")
+	expectLog(t, buf, "well-defined vars that were never replaced: SERVICE_UNUSED code=UnusedVars")
 	c := getCommand(find("deploy1", ra.ResMap()))
 	if c != "myserver --somebackendService backendOne --yetAnother $(SERVICE_TWO)" {
 		t.Fatalf("unexpected command: %s", c)
@@ -0,0 +1,322 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/kyaml/log"
+)
+
+const (
+	defaultGitHubAPI  = "https://api.github.com"
+	defaultGitLabAPI  = "https://gitlab.com/api/v4"
+	defaultAPIRetries = 3
+
+	// maxRateLimitWait is the longest an APICloner
+	// waits for a rate limit to be lifted.
+	maxRateLimitWait = 5 * time.Minute
+)
+
+// APIOptions configures fetching repositories hosted on
+// github.com or gitlab.com through the APIs of the hosts.
+type APIOptions struct {
+	// GitHubToken and GitLabToken authenticate requests,
+	// which is needed for private repositories.  They're
+	// read from the GITHUB_TOKEN and GITLAB_TOKEN
+	// environment variables if empty.
+	GitHubToken string
+	GitLabToken string
+
+	// Proxy is the URL of the proxy to send requests
+	// through.  If empty, the proxy is chosen from the
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+
+	// Retries is how many times failed requests are retried,
+	// including requests refused by rate limits, which are
+	// retried once the limit is lifted.  3 if zero; negative
+	// values mean no retries.
+	Retries int
+
+	// GitHubAPI and GitLabAPI are the base URLs of the
+	// APIs, the public ones if empty.
+	GitHubAPI string
+	GitLabAPI string
+}
+
+// sleep waits between retries.
+// It's a variable so it can be mocked in tests.
+var sleep = time.Sleep
+
+// ClonerUsingAPI returns a Cloner that downloads the archive of
+// the requested ref of repositories hosted on github.com or
+// gitlab.com through the APIs of the hosts, and extracts it,
+// rather than running git.  It's meant for environments without
+// git, or without git credentials.  Other repositories are
+// cloned with fallback.
+//
+// Archives don't include submodules, and archives containing
+// symlinks are refused.  An empty ref means the default branch
+// of the repository.
+func ClonerUsingAPI(o APIOptions, fallback Cloner) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		req, err := o.archiveRequest(repoSpec)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return fallback(repoSpec)
+		}
+		client, err := o.client()
+		if err != nil {
+			return err
+		}
+		log.Info("downloading repo archive", "repo", repoSpec.CloneSpec(), "ref", repoSpec.Ref)
+		resp, err := o.do(client, req)
+		if err != nil {
+			return errors.Wrapf(err, "trouble downloading %s", repoSpec.CloneSpec())
+		}
+		defer resp.Body.Close()
+		dir, err := filesys.NewTmpConfirmedDir()
+		if err != nil {
+			return err
+		}
+		if err = extractArchive(resp.Body, dir.String()); err != nil {
+			os.RemoveAll(dir.String())
+			return errors.Wrapf(err, "trouble extracting archive of %s", repoSpec.CloneSpec())
+		}
+		repoSpec.Dir = dir
+		return nil
+	}
+}
+
+// archiveRequest returns the request for the archive of
+// repoSpec, or nil if its host isn't supported.
+func (o APIOptions) archiveRequest(repoSpec *RepoSpec) (*http.Request, error) {
+	orgRepo := strings.TrimSuffix(repoSpec.OrgRepo, gitSuffix)
+	var u, header, token string
+	switch hostName(repoSpec.Host) {
+	case "github.com":
+		parts := strings.Split(orgRepo, "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected owner/repo, got %s", orgRepo)
+		}
+		u = strings.TrimSuffix(orDefault(o.GitHubAPI, defaultGitHubAPI), "/") +
+			"/repos/" + url.PathEscape(parts[0]) + "/" + url.PathEscape(parts[1]) + "/tarball"
+		if repoSpec.Ref != "" {
+			u += "/" + url.PathEscape(repoSpec.Ref)
+		}
+		header, token = "Authorization", orDefault(o.GitHubToken, os.Getenv("GITHUB_TOKEN"))
+		if token != "" {
+			token = "token " + token
+		}
+	case "gitlab.com":
+		u = strings.TrimSuffix(orDefault(o.GitLabAPI, defaultGitLabAPI), "/") +
+			"/projects/" + url.PathEscape(orgRepo) + "/repository/archive.tar.gz"
+		if repoSpec.Ref != "" {
+			u += "?sha=" + url.QueryEscape(repoSpec.Ref)
+		}
+		header, token = "PRIVATE-TOKEN", orDefault(o.GitLabToken, os.Getenv("GITLAB_TOKEN"))
+	default:
+		return nil, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set(header, token)
+	}
+	return req, nil
+}
+
+// hostName returns the name of the host of a RepoSpec.Host,
+// e.g. github.com for git@github.com: or https://github.com/.
+func hostName(host string) string {
+	host = strings.ToLower(host)
+	if host == "gh:" {
+		return "github.com"
+	}
+	for _, p := range []string{"git::", "https://", "http://", "ssh://", "git@"} {
+		host = strings.TrimPrefix(host, p)
+	}
+	if i := strings.IndexAny(host, "/:"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.TrimPrefix(host, "www.")
+}
+
+func orDefault(s, def string) string {
+	if s != "" {
+		return s
+	}
+	return def
+}
+
+func (o APIOptions) client() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.Proxy != "" {
+		proxy, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid proxy %s", o.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// do sends req, retrying failed requests, with exponential
+// backoff, and requests refused by rate limits, once the
+// limit is lifted.  The response has a 200 status.
+func (o APIOptions) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	retries := o.Retries
+	if retries == 0 {
+		retries = defaultAPIRetries
+	}
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		var wait time.Duration
+		switch {
+		case err != nil:
+			wait = backoff
+		case resp.StatusCode == http.StatusOK:
+			return resp, nil
+		default:
+			body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+			resp.Body.Close()
+			err = fmt.Errorf("%s %s: %s: %s", req.Method, req.URL,
+				resp.Status, strings.TrimSpace(string(body)))
+			if limited, w := rateLimitWait(resp); limited {
+				wait = w
+			} else if resp.StatusCode >= 500 {
+				wait = backoff
+			} else {
+				return nil, err
+			}
+		}
+		if attempt >= retries || wait > maxRateLimitWait {
+			return nil, err
+		}
+		log.Info("retrying", "url", req.URL.String(), "in", wait.String(), "error", err.Error())
+		sleep(wait)
+		backoff *= 2
+	}
+}
+
+// rateLimitWait returns whether resp was refused by a rate
+// limit, and if so, how long until the limit is lifted.
+func rateLimitWait(resp *http.Response) (bool, time.Duration) {
+	if resp.StatusCode != http.StatusTooManyRequests &&
+		!(resp.StatusCode == http.StatusForbidden &&
+			resp.Header.Get("X-RateLimit-Remaining") == "0") {
+		return false, 0
+	}
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return true, time.Duration(s) * time.Second
+	}
+	for _, h := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		if reset, err := strconv.ParseInt(resp.Header.Get(h), 10, 64); err == nil {
+			wait := time.Until(time.Unix(reset, 0))
+			if wait < 0 {
+				wait = 0
+			}
+			return true, wait
+		}
+	}
+	return true, time.Minute
+}
+
+// maxArchiveSize bounds the size of the files extracted
+// from an archive, so a hostile archive can't fill the disk.
+const maxArchiveSize = 1 << 30
+
+// extractArchive extracts the gzipped tarball in r into dir,
+// removing the top directory the hosts put the files in.
+// It refuses links.
+func extractArchive(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if i := strings.IndexRune(name, filepath.Separator); i >= 0 {
+			name = name[i+1:]
+		} else {
+			// The top directory, or a pax global header.
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if !within(dir, path) {
+			return fmt.Errorf("%s is outside the archive", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0700)
+		case tar.TypeReg, tar.TypeRegA:
+			total += hdr.Size
+			if total > maxArchiveSize {
+				return fmt.Errorf("archive is larger than %d bytes", maxArchiveSize)
+			}
+			err = writeFile(path, tr, hdr.FileInfo().Mode())
+		case tar.TypeSymlink, tar.TypeLink:
+			// Links may be chained to point outside dir, and
+			// files may be written through them, so they're
+			// refused rather than checked.
+			return fmt.Errorf("refusing to extract link %s", hdr.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// within returns whether path is in dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." &&
+		!strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// makeArchive returns a gzipped tarball holding files,
+// in a top directory, as the hosts make them.
+func makeArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	write := func(hdr *tar.Header, content string) {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(&tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header",
+		PAXRecords: map[string]string{"comment": "0123"}}, "")
+	write(&tar.Header{Typeflag: tar.TypeDir, Name: "org-repo-0123/", Mode: 0755}, "")
+	for name, content := range files {
+		write(&tar.Header{Typeflag: tar.TypeReg, Name: "org-repo-0123/" + name,
+			Mode: 0644, Size: int64(len(content))}, content)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestClonerUsingAPI(t *testing.T) {
+	archive := makeArchive(t, map[string]string{
+		"a/kustomization.yaml": "namePrefix: a-\n",
+	})
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.String())
+		switch r.URL.EscapedPath() {
+		case "/github/repos/org/repo/tarball/v1.0.0":
+			if r.Header.Get("Authorization") != "token gh" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+		case "/gitlab/projects/group%2Fsub%2Frepo/repository/archive.tar.gz":
+			if r.Header.Get("PRIVATE-TOKEN") != "gl" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+	o := APIOptions{
+		GitHubToken: "gh",
+		GitLabToken: "gl",
+		GitHubAPI:   server.URL + "/github",
+		GitLabAPI:   server.URL + "/gitlab",
+	}
+	var fellBack []string
+	cloner := ClonerUsingAPI(o, func(rs *RepoSpec) error {
+		fellBack = append(fellBack, rs.Host)
+		return nil
+	})
+	for _, rs := range []*RepoSpec{
+		{Host: "https://github.com/", OrgRepo: "org/repo", Path: "a", Ref: "v1.0.0"},
+		{Host: "git@gitlab.com:", OrgRepo: "group/sub/repo", GitSuffix: ".git", Path: "a"},
+	} {
+		if err := cloner(rs); err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(filepath.Join(rs.AbsPath(), "kustomization.yaml"))
+		os.RemoveAll(rs.Dir.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "namePrefix: a-\n" {
+			t.Fatalf("unexpected kustomization %q", b)
+		}
+	}
+	if err := cloner(&RepoSpec{Host: "https://example.com/", OrgRepo: "org/repo"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fellBack) != 1 || fellBack[0] != "https://example.com/" {
+		t.Fatalf("unexpected fallbacks %v", fellBack)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+}
+
+func TestClonerUsingAPIRetries(t *testing.T) {
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	archive := makeArchive(t, map[string]string{"kustomization.yaml": "\n"})
+	var count int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		switch count {
+		case 1:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write(archive)
+		}
+	}))
+	defer server.Close()
+	rs := &RepoSpec{Host: "https://github.com/", OrgRepo: "org/repo"}
+	err := ClonerUsingAPI(APIOptions{GitHubAPI: server.URL}, nil)(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(rs.Dir.String())
+	if len(waits) != 2 || waits[0] != 7*time.Second || waits[1] != 2*time.Second {
+		t.Fatalf("unexpected waits %v", waits)
+	}
+
+	count = 1
+	waits = nil
+	err = ClonerUsingAPI(APIOptions{GitHubAPI: server.URL, Retries: -1}, nil)(rs)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if len(waits) != 0 {
+		t.Fatalf("unexpected waits %v", waits)
+	}
+}
+
+func TestExtractArchiveRejectsEscapes(t *testing.T) {
+	for _, archive := range [][]*tar.Header{
+		{{Typeflag: tar.TypeReg, Name: "top/../../evil"}},
+		{{Typeflag: tar.TypeSymlink, Name: "top/link", Linkname: "../../etc"}},
+		{{Typeflag: tar.TypeSymlink, Name: "top/link", Linkname: "/etc"}},
+		// Each link is within the archive on its own, but
+		// chained they point outside it.
+		{
+			{Typeflag: tar.TypeSymlink, Name: "top/a", Linkname: "."},
+			{Typeflag: tar.TypeSymlink, Name: "top/b", Linkname: "a/.."},
+			{Typeflag: tar.TypeReg, Name: "top/b/evil"},
+		},
+		{{Typeflag: tar.TypeLink, Name: "top/link", Linkname: "/etc/passwd"}},
+		{{Typeflag: tar.TypeReg, Name: "top/big", Size: maxArchiveSize + 1}},
+	} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for _, hdr := range archive {
+			if err := tw.WriteHeader(hdr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The content of files is left out, so closing
+		// the writer may fail; the headers are written.
+		tw.Close()
+		gz.Close()
+		dir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = extractArchive(&buf, dir)
+		os.RemoveAll(dir)
+		if err == nil {
+			t.Fatalf("expected an error extracting %s", archive[len(archive)-1].Name)
+		}
+	}
+}
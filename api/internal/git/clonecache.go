@@ -0,0 +1,100 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+// CloneCache shares clones between RepoSpecs naming the same
+// repository and ref, so that a build referring to several
+// paths in one repository clones it only once.  It also limits
+// the number of clones running at the same time.
+//
+// The shared clones outlive the loaders using them, and are
+// removed by Cleanup.
+type CloneCache struct {
+	cloner Cloner
+	// sem limits the number of concurrent clones, if non-nil.
+	sem chan struct{}
+
+	mu     sync.Mutex
+	clones map[string]*sharedClone
+}
+
+type sharedClone struct {
+	// done is closed once the clone finished.
+	done chan struct{}
+	spec RepoSpec
+	err  error
+}
+
+// NewCloneCache returns a CloneCache using the given Cloner
+// to make clones, running at most limit of them at the same
+// time.  A limit less than 1 means no limit.
+func NewCloneCache(cloner Cloner, limit int) *CloneCache {
+	c := &CloneCache{
+		cloner: cloner,
+		clones: map[string]*sharedClone{},
+	}
+	if limit > 0 {
+		c.sem = make(chan struct{}, limit)
+	}
+	return c
+}
+
+// Cloner returns a Cloner that reuses an existing clone of the
+// repository and ref if there is one, and makes one otherwise.
+func (c *CloneCache) Cloner() Cloner {
+	return func(repoSpec *RepoSpec) error {
+		key := repoSpec.CloneSpec() + "@" + repoSpec.Ref
+		c.mu.Lock()
+		clone, found := c.clones[key]
+		if !found {
+			clone = &sharedClone{done: make(chan struct{}), spec: *repoSpec}
+			c.clones[key] = clone
+		}
+		c.mu.Unlock()
+
+		if found {
+			<-clone.done
+		} else {
+			if c.sem != nil {
+				c.sem <- struct{}{}
+			}
+			clone.err = c.cloner(&clone.spec)
+			if c.sem != nil {
+				<-c.sem
+			}
+			close(clone.done)
+		}
+		if clone.err != nil {
+			return clone.err
+		}
+		repoSpec.Dir = clone.spec.Dir
+		repoSpec.Ref = clone.spec.Ref
+		repoSpec.sparse = clone.spec.sparse
+		repoSpec.shared = true
+		return repoSpec.CheckOut(repoSpec.Path)
+	}
+}
+
+// Cleanup removes all the clones made by the cache.
+func (c *CloneCache) Cleanup(fSys filesys.FileSystem) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var result error
+	for key, clone := range c.clones {
+		<-clone.done
+		if clone.err == nil && clone.spec.Dir != "" && !clone.spec.shared {
+			if err := fSys.RemoveAll(clone.spec.Dir.String()); err != nil && result == nil {
+				result = err
+			}
+		}
+		delete(c.clones, key)
+	}
+	return result
+}
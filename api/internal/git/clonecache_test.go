@@ -0,0 +1,117 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+func TestCloneCacheSharesClones(t *testing.T) {
+	var calls int32
+	cloner := func(rs *RepoSpec) error {
+		n := atomic.AddInt32(&calls, 1)
+		rs.Dir = filesys.ConfirmedDir(fmt.Sprintf("/clone%d", n))
+		return nil
+	}
+	fSys := filesys.MakeFsInMemory()
+	cache := NewCloneCache(cloner, 0)
+
+	var specs []*RepoSpec
+	for _, url := range []string{
+		"github.com/someOrg/someRepo/a?ref=v1",
+		"github.com/someOrg/someRepo/b?ref=v1",
+		"github.com/someOrg/someRepo/a?ref=v2",
+		"github.com/someOrg/otherRepo/a?ref=v1",
+	} {
+		rs, err := NewRepoSpecFromUrl(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specs = append(specs, rs)
+	}
+	var wg sync.WaitGroup
+	for i := range specs {
+		wg.Add(1)
+		go func(rs *RepoSpec) {
+			defer wg.Done()
+			if err := cache.Cloner()(rs); err != nil {
+				t.Error(err)
+			}
+		}(specs[i])
+	}
+	wg.Wait()
+
+	if calls != 3 {
+		t.Fatalf("expected 3 clones, got %d", calls)
+	}
+	if specs[0].Dir != specs[1].Dir {
+		t.Fatalf("expected shared clone, got %s and %s", specs[0].Dir, specs[1].Dir)
+	}
+	if specs[0].Dir == specs[2].Dir || specs[0].Dir == specs[3].Dir {
+		t.Fatalf("expected separate clones for other refs and repos")
+	}
+
+	// The loaders' cleaners leave shared clones alone.
+	for _, rs := range specs {
+		fSys.MkdirAll(rs.Dir.String())
+		if err := rs.Cleaner(fSys)(); err != nil {
+			t.Fatal(err)
+		}
+		if !fSys.Exists(rs.Dir.String()) {
+			t.Fatalf("shared clone %s removed by loader cleaner", rs.Dir)
+		}
+	}
+	if err := cache.Cleanup(fSys); err != nil {
+		t.Fatal(err)
+	}
+	for _, rs := range specs {
+		if fSys.Exists(rs.Dir.String()) {
+			t.Fatalf("clone %s not removed by cache cleanup", rs.Dir)
+		}
+	}
+}
+
+func TestCloneCacheLimit(t *testing.T) {
+	var running, maxRunning int32
+	cloner := func(rs *RepoSpec) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		rs.Dir = filesys.ConfirmedDir("/" + rs.OrgRepo)
+		return nil
+	}
+	cache := NewCloneCache(cloner, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		rs, err := NewRepoSpecFromUrl(
+			fmt.Sprintf("github.com/someOrg/repo%d/a", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cache.Cloner()(rs); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxRunning > 2 {
+		t.Fatalf("expected at most 2 concurrent clones, got %d", maxRunning)
+	}
+}
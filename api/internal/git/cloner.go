@@ -4,11 +4,18 @@
 package git
 
 import (
-	"log"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 // Cloner is a function that can clone a git repo.
@@ -17,6 +24,11 @@ type Cloner func(repoSpec *RepoSpec) error
 // ClonerUsingGitExec uses a local git install, as opposed
 // to say, some remote API, to obtain a local clone of
 // a remote repo.
+//
+// Only the commit at the requested ref is fetched.  If the
+// repoSpec has a Path, only that directory is checked out,
+// and other directories are checked out by RepoSpec.CheckOut
+// when they're needed.
 func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	gitProgram, err := exec.LookPath("git")
 	if err != nil {
@@ -26,65 +38,217 @@ func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	if err != nil {
 		return err
 	}
+	return cloneInto(gitProgram, repoSpec)
+}
 
+// cloneInto makes a shallow, and possibly sparse,
+// clone of the repoSpec in the existing repoSpec.Dir.
+func cloneInto(gitProgram string, repoSpec *RepoSpec) error {
 	if repoSpec.Ref == "" {
 		repoSpec.Ref = "master"
 	}
-	cmd := exec.Command(
-		gitProgram,
-		"clone",
-		"--depth=1",
-		repoSpec.CloneSpec(),
-		repoSpec.Dir.String())
-	out, err := cmd.CombinedOutput()
+	if err := checkNotOptions(repoSpec); err != nil {
+		return err
+	}
+	log.Info("cloning git repo", "repo", repoSpec.CloneSpec(), "ref", repoSpec.Ref)
+	dir := repoSpec.Dir.String()
+	err := runGit(gitProgram, dir, "init")
+	if err == nil {
+		err = runGit(gitProgram, dir, "remote", "add", "--", "origin", repoSpec.CloneSpec())
+	}
 	if err != nil {
-		log.Printf("Error cloning git repo: %s", out)
 		return errors.Wrapf(
 			err,
 			"trouble cloning git repo %v in %s",
-			repoSpec.CloneSpec(), repoSpec.Dir.String())
+			repoSpec.CloneSpec(), dir)
 	}
 
-	cmd = exec.Command(
-		gitProgram,
-		"fetch",
-		"--depth=1",
-		"origin",
-		repoSpec.Ref)
-	cmd.Dir = repoSpec.Dir.String()
-	out, err = cmd.CombinedOutput()
+	fetch := []string{"fetch", "--depth=1"}
+	if repoSpec.Path != "" {
+		err = runGit(gitProgram, dir, "config", "core.sparseCheckout", "true")
+		if err != nil {
+			return errors.Wrapf(err, "trouble configuring sparse checkout")
+		}
+		repoSpec.sparse = &sparseCheckout{gitProgram: gitProgram}
+		err = repoSpec.sparse.write(dir, []string{repoSpec.Path})
+		if err != nil {
+			return err
+		}
+		// Servers not supporting filters ignore this,
+		// and send every file of the commit.
+		fetch = append(fetch, "--filter=blob:none")
+	}
+	err = runGit(gitProgram, dir, append(fetch, "--", "origin", repoSpec.Ref)...)
 	if err != nil {
-		log.Printf("Error fetching ref: %s", out)
 		return errors.Wrapf(err, "trouble fetching %s", repoSpec.Ref)
 	}
 
-	cmd = exec.Command(
-		gitProgram,
-		"checkout",
-		"FETCH_HEAD")
-	cmd.Dir = repoSpec.Dir.String()
-	out, err = cmd.CombinedOutput()
+	err = runGit(gitProgram, dir, "checkout", "FETCH_HEAD")
 	if err != nil {
-		log.Printf("Error checking out ref: %s", out)
 		return errors.Wrapf(err, "trouble checking out %s", repoSpec.Ref)
 	}
 
-	cmd = exec.Command(
-		gitProgram,
-		"submodule",
-		"update",
-		"--init",
-		"--recursive")
-	cmd.Dir = repoSpec.Dir.String()
-	out, err = cmd.CombinedOutput()
+	err = runGit(gitProgram, dir, "submodule", "update", "--init", "--recursive", "--depth=1")
 	if err != nil {
-		log.Printf("Error fetching submodules: %s", out)
 		return errors.Wrapf(err, "trouble fetching submodules for %s", repoSpec.CloneSpec())
 	}
+	return nil
+}
 
+// checkNotOptions fails if the repo or ref of repoSpec starts
+// with -, which git may take for an option, e.g. --upload-pack
+// running any command, wherever it isn't passed after --.
+func checkNotOptions(repoSpec *RepoSpec) error {
+	if strings.HasPrefix(repoSpec.CloneSpec(), "-") {
+		return fmt.Errorf("repo %s must not start with -", repoSpec.CloneSpec())
+	}
+	if strings.HasPrefix(repoSpec.Ref, "-") {
+		return fmt.Errorf("ref %s must not start with -", repoSpec.Ref)
+	}
 	return nil
 }
 
+// runGit runs git with the given arguments in dir,
+// logging its output if it fails.
+func runGit(gitProgram string, dir string, args ...string) error {
+	cmd := exec.Command(gitProgram, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Warn("error running git", "args", strings.Join(args, " "), "output", string(out))
+	}
+	return err
+}
+
+var commitHash = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// ClonerUsingGitCache returns a Cloner like ClonerUsingGitExec,
+// except that it keeps its clones in cacheDir, by repository and
+// commit, and reuses them in later builds.  Refs other than full
+// commit hashes are looked up with "git ls-remote" first, so a
+// branch is only fetched again once it has moved.
+//
+// The cached clones are never removed by kustomize.  They may
+// be shared by concurrent builds, in this and other processes;
+// see sparseCheckout.
+func ClonerUsingGitCache(cacheDir string) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		gitProgram, err := exec.LookPath("git")
+		if err != nil {
+			return errors.Wrap(err, "no 'git' program on path")
+		}
+		if repoSpec.Ref == "" {
+			repoSpec.Ref = "master"
+		}
+		repoDir := filepath.Join(cacheDir, fmt.Sprintf(
+			"%x", sha256.Sum256([]byte(repoSpec.CloneSpec()))))
+		commit, err := resolveRef(gitProgram, repoSpec)
+		if err != nil {
+			return err
+		}
+		if commit != "" {
+			if ok, err := findCachedClone(gitProgram, repoSpec, filepath.Join(repoDir, commit)); ok || err != nil {
+				return err
+			}
+		}
+
+		if err = os.MkdirAll(repoDir, 0700); err != nil {
+			return err
+		}
+		tmp, err := ioutil.TempDir(repoDir, "clone-")
+		if err != nil {
+			return err
+		}
+		repoSpec.Dir = filesys.ConfirmedDir(tmp)
+		if err = cloneInto(gitProgram, repoSpec); err != nil {
+			os.RemoveAll(tmp)
+			return err
+		}
+		out, err := exec.Command(gitProgram, "-C", tmp, "rev-parse", "HEAD").Output()
+		if err != nil {
+			os.RemoveAll(tmp)
+			return errors.Wrapf(err, "trouble reading the commit of %s", repoSpec.Ref)
+		}
+		// The clone is stored at the commit it actually has,
+		// in case the ref moved after it was looked up.
+		dir := filepath.Join(repoDir, strings.TrimSpace(string(out)))
+		if err = os.Rename(tmp, dir); err != nil {
+			// Another build stored the same commit first.
+			os.RemoveAll(tmp)
+		}
+		if ok, err := findCachedClone(gitProgram, repoSpec, dir); ok || err != nil {
+			return err
+		}
+		return errors.Wrapf(err, "trouble caching clone of %s", repoSpec.CloneSpec())
+	}
+}
+
+// resolveRef returns the commit hash that repoSpec.Ref names,
+// or "" if it can't be looked up without fetching.
+func resolveRef(gitProgram string, repoSpec *RepoSpec) (string, error) {
+	if commitHash.MatchString(repoSpec.Ref) {
+		return repoSpec.Ref, nil
+	}
+	if err := checkNotOptions(repoSpec); err != nil {
+		return "", err
+	}
+	out, err := exec.Command(
+		gitProgram, "ls-remote", "--", repoSpec.CloneSpec(), repoSpec.Ref).Output()
+	if err != nil {
+		return "", errors.Wrapf(
+			err, "trouble looking up %s in %s", repoSpec.Ref, repoSpec.CloneSpec())
+	}
+	return parseLsRemote(string(out), repoSpec.Ref), nil
+}
+
+// parseLsRemote returns the commit that ref names in the
+// output of "git ls-remote", preferring the commit a tag
+// points to over the tag object itself.
+func parseLsRemote(out string, ref string) string {
+	var commit string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[1] {
+		case "refs/tags/" + ref + "^{}":
+			return fields[0]
+		case ref, "refs/heads/" + ref, "refs/tags/" + ref:
+			if commit == "" {
+				commit = fields[0]
+			}
+		}
+	}
+	return commit
+}
+
+// findCachedClone uses the cached clone in dir for
+// repoSpec, if there is one.
+func findCachedClone(gitProgram string, repoSpec *RepoSpec, dir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return false, nil
+	}
+	if _, err := os.Stat(sparseCheckoutFile(dir)); err == nil {
+		repoSpec.sparse = cachedSparseCheckout(gitProgram, dir)
+	} else {
+		repoSpec.sparse = nil
+	}
+	return true, useCachedClone(repoSpec, dir)
+}
+
+// useCachedClone points repoSpec at the cached clone in
+// dir, checking out repoSpec.Path if the clone is sparse.
+func useCachedClone(repoSpec *RepoSpec, dir string) error {
+	deLinked, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	repoSpec.Dir = filesys.ConfirmedDir(deLinked)
+	repoSpec.shared = true
+	return repoSpec.CheckOut(repoSpec.Path)
+}
+
 // DoNothingCloner returns a cloner that only sets
 // cloneDir field in the repoSpec.  It's assumed that
 // the cloneDir is associated with some fake filesystem
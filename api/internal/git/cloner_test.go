@@ -0,0 +1,202 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// makeRepo makes a git repository with the directories
+// a and b, each holding a kustomization file.
+func makeRepo(t *testing.T) *RepoSpec {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("no git program on path")
+	}
+	dir, err := ioutil.TempDir("", "kustomize-repo-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{"a", "b"} {
+		if err = os.Mkdir(filepath.Join(dir, d), 0700); err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(
+			filepath.Join(dir, d, "kustomization.yaml"), []byte("namePrefix: "+d+"-\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "uploadpack.allowFilter", "true"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-m", "first"},
+		{"branch", "-M", "main"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return &RepoSpec{Host: "file://", OrgRepo: dir, Path: "a", Ref: "main"}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestClonerChecksOutOnlyPath(t *testing.T) {
+	rs := makeRepo(t)
+	defer os.RemoveAll(rs.OrgRepo)
+	if err := ClonerUsingGitExec(rs); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rs.Dir.String())
+	if !exists(rs.Dir.Join("a/kustomization.yaml")) {
+		t.Fatalf("expected a to be checked out")
+	}
+	if exists(rs.Dir.Join("b")) {
+		t.Fatalf("expected b not to be checked out")
+	}
+	if err := rs.CheckOut("b"); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(rs.Dir.Join("b/kustomization.yaml")) {
+		t.Fatalf("expected b to be checked out")
+	}
+}
+
+func TestClonerUsingGitCacheReusesClones(t *testing.T) {
+	rs := makeRepo(t)
+	defer os.RemoveAll(rs.OrgRepo)
+	cacheDir, err := ioutil.TempDir("", "kustomize-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cacheDir)
+	cloner := ClonerUsingGitCache(cacheDir)
+
+	if err = cloner(rs); err != nil {
+		t.Fatal(err)
+	}
+	if !rs.shared {
+		t.Fatalf("expected the cached clone to be shared")
+	}
+	rs2 := &RepoSpec{Host: rs.Host, OrgRepo: rs.OrgRepo, Path: "b", Ref: "main"}
+	if err = cloner(rs2); err != nil {
+		t.Fatal(err)
+	}
+	if rs2.Dir != rs.Dir {
+		t.Fatalf("expected clone %s to be reused, got %s", rs.Dir, rs2.Dir)
+	}
+	if !exists(rs2.Dir.Join("b/kustomization.yaml")) {
+		t.Fatalf("expected b to be checked out")
+	}
+}
+
+func TestParseLsRemote(t *testing.T) {
+	out := `1111111111111111111111111111111111111111	refs/heads/v1
+2222222222222222222222222222222222222222	refs/tags/v1
+3333333333333333333333333333333333333333	refs/tags/v1^{}
+4444444444444444444444444444444444444444	refs/heads/main
+`
+	for ref, expected := range map[string]string{
+		"v1":      "3333333333333333333333333333333333333333",
+		"main":    "4444444444444444444444444444444444444444",
+		"missing": "",
+	} {
+		if actual := parseLsRemote(out, ref); actual != expected {
+			t.Errorf("ref %s: expected %q, got %q", ref, expected, actual)
+		}
+	}
+}
+
+func TestCoversPath(t *testing.T) {
+	paths := []string{"a/b", "c/"}
+	for p, expected := range map[string]bool{
+		"a/b":    true,
+		"a/b/c":  true,
+		"./c/d":  true,
+		"a":      false,
+		"a/bc":   false,
+		"a/b/..": false,
+	} {
+		if actual := coversPath(paths, p); actual != expected {
+			t.Errorf("path %s: expected %v, got %v", p, expected, actual)
+		}
+	}
+}
+
+func TestSparseCheckoutsOfOtherProcesses(t *testing.T) {
+	rs := makeRepo(t)
+	defer os.RemoveAll(rs.OrgRepo)
+	if err := ClonerUsingGitExec(rs); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rs.Dir.String())
+	// Other processes have their own sparseCheckouts
+	// of the same clone, so only the lock file keeps
+	// them from losing each other's paths.
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, p := range []string{"b", "c"} {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			s := &sparseCheckout{gitProgram: rs.sparse.gitProgram}
+			errs[i] = s.add(rs.Dir.String(), p)
+		}(i, p)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths, err := rs.sparse.read(rs.Dir.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !coversPath(paths, "b") || !coversPath(paths, "c") {
+		t.Fatalf("expected b and c to be checked out, got %v", paths)
+	}
+}
+
+func TestCachedSparseCheckoutIsShared(t *testing.T) {
+	if cachedSparseCheckout("git", "/a") != cachedSparseCheckout("git", "/a") {
+		t.Fatalf("expected the clone's sparseCheckout to be shared")
+	}
+	if cachedSparseCheckout("git", "/a") == cachedSparseCheckout("git", "/b") {
+		t.Fatalf("expected clones to have their own sparseCheckouts")
+	}
+}
+
+func TestClonersRejectOptions(t *testing.T) {
+	rs := makeRepo(t)
+	defer os.RemoveAll(rs.OrgRepo)
+	cacheDir, err := ioutil.TempDir("", "kustomize-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cacheDir)
+	// options would run any command
+	marker := filepath.Join(cacheDir, "marker")
+	option := "--upload-pack=touch " + marker
+	for _, cloner := range []Cloner{ClonerUsingGitExec, ClonerUsingGitCache(cacheDir)} {
+		err = cloner(&RepoSpec{Host: rs.Host, OrgRepo: rs.OrgRepo, Ref: option})
+		if err == nil || !strings.Contains(err.Error(), "ref "+option+" must not start with -") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	if _, err = os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("expected the option not to run a command")
+	}
+}
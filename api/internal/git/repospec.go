@@ -44,6 +44,15 @@ type RepoSpec struct {
 
 	// e.g. .git or empty in case of _git is present
 	GitSuffix string
+
+	// shared is true if Dir belongs to a CloneCache, or
+	// to a persistent cache of clones, which is then
+	// responsible for removing it.
+	shared bool
+
+	// sparse is non-nil if only some directories
+	// of the clone in Dir are checked out.
+	sparse *sparseCheckout
 }
 
 // CloneSpec returns a string suitable for "git clone {spec}".
@@ -66,8 +75,23 @@ func (x *RepoSpec) AbsPath() string {
 	return x.Dir.Join(x.Path)
 }
 
+// CheckOut makes sure the directory at path, relative
+// to the top of the clone, is checked out.  Only sparse
+// clones have directories that aren't.
+func (x *RepoSpec) CheckOut(path string) error {
+	if x.sparse == nil {
+		return nil
+	}
+	return x.sparse.add(x.Dir.String(), path)
+}
+
 func (x *RepoSpec) Cleaner(fSys filesys.FileSystem) func() error {
-	return func() error { return fSys.RemoveAll(x.Dir.String()) }
+	return func() error {
+		if x.shared {
+			return nil
+		}
+		return fSys.RemoveAll(x.Dir.String())
+	}
 }
 
 // From strings like git@github.com:someOrg/someRepo.git or
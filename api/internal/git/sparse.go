@@ -0,0 +1,135 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// sparseCheckout widens a clone that has only some of
+// its directories checked out.  It's shared by all the
+// RepoSpecs sharing the clone.
+//
+// Cached clones are also shared with other processes, so
+// widening a clone also takes a lock on a file in it.
+// Widening only adds files, so reading a clone while it's
+// widened is safe.
+type sparseCheckout struct {
+	gitProgram string
+	mu         sync.Mutex
+}
+
+var (
+	cachedSparseCheckoutsMu sync.Mutex
+	// cachedSparseCheckouts holds the sparseCheckout of
+	// each cached clone, by directory, so that all the
+	// RepoSpecs of this process using a clone share it.
+	cachedSparseCheckouts = map[string]*sparseCheckout{}
+)
+
+// cachedSparseCheckout returns the sparseCheckout of
+// the cached clone in dir.
+func cachedSparseCheckout(gitProgram string, dir string) *sparseCheckout {
+	cachedSparseCheckoutsMu.Lock()
+	defer cachedSparseCheckoutsMu.Unlock()
+	s, found := cachedSparseCheckouts[dir]
+	if !found {
+		s = &sparseCheckout{gitProgram: gitProgram}
+		cachedSparseCheckouts[dir] = s
+	}
+	return s
+}
+
+// sparseCheckoutFile returns the file listing the
+// directories checked out in the clone in dir.
+func sparseCheckoutFile(dir string) string {
+	return filepath.Join(dir, ".git", "info", "sparse-checkout")
+}
+
+// sparseCheckoutLockFile returns the file locked while
+// the clone in dir is widened.
+func sparseCheckoutLockFile(dir string) string {
+	return filepath.Join(dir, ".git", "kustomize-sparse-checkout.lock")
+}
+
+// add checks out the directory p, relative to the clone
+// in dir, unless it, or a directory above it, already is.
+func (s *sparseCheckout) add(dir string, p string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(sparseCheckoutLockFile(dir))
+	if err != nil {
+		return errors.Wrapf(err, "trouble locking clone in %s", dir)
+	}
+	defer unlock()
+	paths, err := s.read(dir)
+	if err != nil {
+		return err
+	}
+	if coversPath(paths, p) {
+		return nil
+	}
+	if err = s.write(dir, append(paths, p)); err != nil {
+		return err
+	}
+	if err = runGit(s.gitProgram, dir, "read-tree", "-mu", "HEAD"); err != nil {
+		return errors.Wrapf(err, "trouble checking out %s", p)
+	}
+	return nil
+}
+
+// read returns the directories checked out in the clone in dir.
+func (s *sparseCheckout) read(dir string) ([]string, error) {
+	b, err := ioutil.ReadFile(sparseCheckoutFile(dir))
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.Trim(line, "/"); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// write sets the directories to check out in the clone in dir.
+func (s *sparseCheckout) write(dir string, paths []string) error {
+	var b strings.Builder
+	for _, p := range paths {
+		b.WriteString("/" + cleanRepoPath(p) + "/\n")
+	}
+	f := sparseCheckoutFile(dir)
+	if err := os.MkdirAll(filepath.Dir(f), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f, []byte(b.String()), 0600)
+}
+
+// coversPath returns true if p, or a directory above
+// it, is one of the given paths.
+func coversPath(paths []string, p string) bool {
+	p = cleanRepoPath(p)
+	for _, q := range paths {
+		q = cleanRepoPath(q)
+		if q == "" || p == q || strings.HasPrefix(p, q+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanRepoPath returns p, a slash separated path relative
+// to the top of a repository, without redundant elements.
+func cleanRepoPath(p string) string {
+	p = path.Clean("/" + filepath.ToSlash(p))
+	return strings.TrimPrefix(p, "/")
+}
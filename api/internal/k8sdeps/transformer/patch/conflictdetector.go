@@ -36,7 +36,7 @@ func newJMPConflictDetector(rf *resource.Factory) conflictDetector {
 
 func (jmp *jsonMergePatch) hasConflict(
 	patch1, patch2 *resource.Resource) (bool, error) {
-	return mergepatch.HasConflicts(patch1.Map(), patch2.Map())
+	return mergepatch.HasConflicts(patch1.ReadOnlyMap(), patch2.ReadOnlyMap())
 }
 
 func (jmp *jsonMergePatch) findConflict(
@@ -49,8 +49,8 @@ func (jmp *jsonMergePatch) findConflict(
 			continue
 		}
 		conflict, err := mergepatch.HasConflicts(
-			patch.Map(),
-			patches[conflictingPatchIdx].Map())
+			patch.ReadOnlyMap(),
+			patches[conflictingPatchIdx].ReadOnlyMap())
 		if err != nil {
 			return nil, err
 		}
@@ -63,11 +63,11 @@ func (jmp *jsonMergePatch) findConflict(
 
 func (jmp *jsonMergePatch) mergePatches(
 	patch1, patch2 *resource.Resource) (*resource.Resource, error) {
-	baseBytes, err := json.Marshal(patch1.Map())
+	baseBytes, err := json.Marshal(patch1.ReadOnlyMap())
 	if err != nil {
 		return nil, err
 	}
-	patchBytes, err := json.Marshal(patch2.Map())
+	patchBytes, err := json.Marshal(patch2.ReadOnlyMap())
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +96,7 @@ func newSMPConflictDetector(
 
 func (smp *strategicMergePatch) hasConflict(p1, p2 *resource.Resource) (bool, error) {
 	return strategicpatch.MergingMapsHaveConflicts(
-		p1.Map(), p2.Map(), smp.lookupPatchMeta)
+		p1.ReadOnlyMap(), p2.ReadOnlyMap(), smp.lookupPatchMeta)
 }
 
 func (smp *strategicMergePatch) findConflict(
@@ -109,8 +109,8 @@ func (smp *strategicMergePatch) findConflict(
 			continue
 		}
 		conflict, err := strategicpatch.MergingMapsHaveConflicts(
-			patch.Map(),
-			patches[conflictingPatchIdx].Map(),
+			patch.ReadOnlyMap(),
+			patches[conflictingPatchIdx].ReadOnlyMap(),
 			smp.lookupPatchMeta)
 		if err != nil {
 			return nil, err
@@ -123,14 +123,14 @@ func (smp *strategicMergePatch) findConflict(
 }
 
 func (smp *strategicMergePatch) mergePatches(patch1, patch2 *resource.Resource) (*resource.Resource, error) {
-	if hasDeleteDirectiveMarker(patch2.Map()) {
-		if hasDeleteDirectiveMarker(patch1.Map()) {
+	if hasDeleteDirectiveMarker(patch2.ReadOnlyMap()) {
+		if hasDeleteDirectiveMarker(patch1.ReadOnlyMap()) {
 			return nil, fmt.Errorf("cannot merge patches both containing '$patch: delete' directives")
 		}
 		patch1, patch2 = patch2, patch1
 	}
 	mergeJSONMap, err := strategicpatch.MergeStrategicMergeMapPatchUsingLookupPatchMeta(
-		smp.lookupPatchMeta, patch1.Map(), patch2.Map())
+		smp.lookupPatchMeta, patch1.ReadOnlyMap(), patch2.ReadOnlyMap())
 	return smp.rf.FromMap(mergeJSONMap), err
 }
 
@@ -175,7 +175,7 @@ func MergePatches(patches []*resource.Resource,
 			}
 			return nil, fmt.Errorf(
 				"conflict between %#v and %#v",
-				conflictingPatch.Map(), patch.Map())
+				conflictingPatch.ReadOnlyMap(), patch.ReadOnlyMap())
 		}
 		merged, err := cd.mergePatches(existing[0], patch)
 		if err != nil {
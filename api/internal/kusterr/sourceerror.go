@@ -0,0 +1,98 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SourceError is an error about a file, or about a resource,
+// located as precisely as is known, e.g.
+// kustomization.yaml:3:1: json: unknown field "resource"
+type SourceError struct {
+	// Path is the file the error is in, if known.
+	Path string
+
+	// Line and Column locate the error in Path,
+	// counting from 1.  They're 0 if unknown.
+	Line   int
+	Column int
+
+	// Resource is the ID of the resource the
+	// error is about, if any.
+	Resource string
+
+	Err error
+}
+
+func (e *SourceError) Error() string {
+	var parts []string
+	if e.Path != "" {
+		location := e.Path
+		if e.Line > 0 {
+			location += ":" + strconv.Itoa(e.Line)
+			if e.Column > 0 {
+				location += ":" + strconv.Itoa(e.Column)
+			}
+		}
+		parts = append(parts, location)
+	}
+	if e.Resource != "" {
+		parts = append(parts, e.Resource)
+	}
+	return strings.Join(append(parts, e.Err.Error()), ": ")
+}
+
+// Cause returns the error located, for errors.Cause.
+func (e *SourceError) Cause() error {
+	return e.Err
+}
+
+// isLocated returns whether err, or an error it
+// wraps, already says which file it's about.
+func isLocated(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case *SourceError, YamlFormatError:
+			return true
+		case interface{ Cause() error }:
+			err = e.Cause()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// InFile returns err as an error in the file at path, at
+// line and column, if they aren't 0, or else at the line of
+// a YAML syntax error, if err is one.  Errors which already
+// say which file they're about are returned as is.
+func InFile(err error, path string, line, column int) error {
+	if err == nil || isLocated(err) {
+		return err
+	}
+	if line == 0 {
+		if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+	}
+	return &SourceError{Path: path, Line: line, Column: column, Err: err}
+}
+
+// OfResource returns err as an error about the resource
+// with the ID id, read from the file origin, if it's not
+// empty.  Errors which already say which file they're
+// about are returned as is.
+func OfResource(err error, id fmt.Stringer, origin string) error {
+	if err == nil || isLocated(err) {
+		return err
+	}
+	return &SourceError{Path: origin, Resource: id.String(), Err: err}
+}
+
+var yamlLineRegex = regexp.MustCompile(`yaml: line ([0-9]+):`)
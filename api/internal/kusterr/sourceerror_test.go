@@ -0,0 +1,71 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+func TestInFile(t *testing.T) {
+	testCases := map[string]struct {
+		err          error
+		line, column int
+		expected     string
+	}{
+		"located": {
+			err:  fmt.Errorf(`json: unknown field "resource"`),
+			line: 3, column: 1,
+			expected: `/app/kustomization.yaml:3:1: ` +
+				`json: unknown field "resource"`,
+		},
+		"yaml syntax": {
+			err: fmt.Errorf("error converting YAML to JSON: yaml: line 4: " +
+				"could not find expected ':'"),
+			expected: `/app/kustomization.yaml:4: error converting YAML to ` +
+				`JSON: yaml: line 4: could not find expected ':'`,
+		},
+		"unlocated": {
+			err:      fmt.Errorf("missing kind"),
+			expected: `/app/kustomization.yaml: missing kind`,
+		},
+		"already located": {
+			err: errors.Wrap(&SourceError{
+				Path: "/app/patch.yaml", Line: 2, Err: fmt.Errorf("bad"),
+			}, "loading patch"),
+			expected: `loading patch: /app/patch.yaml:2: bad`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := InFile(tc.err, "/app/kustomization.yaml", tc.line, tc.column)
+			if err.Error() != tc.expected {
+				t.Fatalf("expected\n%s\ngot\n%s", tc.expected, err.Error())
+			}
+			if errors.Cause(err) != errors.Cause(tc.err) {
+				t.Fatalf("expected the cause %v, got %v", errors.Cause(tc.err), errors.Cause(err))
+			}
+		})
+	}
+	if InFile(nil, "/app/kustomization.yaml", 0, 0) != nil {
+		t.Fatal("expected nil")
+	}
+}
+
+func TestOfResource(t *testing.T) {
+	id := resid.NewResId(resid.Gvk{Version: "v1", Kind: "ConfigMap"}, "settings")
+	err := OfResource(fmt.Errorf("bad"), id, "/app/settings.yaml")
+	expected := "/app/settings.yaml: ~G_v1_ConfigMap|~X|settings: bad"
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+	err = OfResource(fmt.Errorf("bad"), id, "")
+	expected = "~G_v1_ConfigMap|~X|settings: bad"
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+}
@@ -4,17 +4,21 @@
 package builtinconfig
 
 import (
+	"fmt"
+	"strings"
+
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/konfig/builtinpluginconsts"
 	"sigs.k8s.io/yaml"
 )
 
 // loadDefaultConfig returns a TranformerConfig
-// object from a list of files.
+// object from a list of files, or names of bundles.
 func loadDefaultConfig(
 	ldr ifc.Loader, paths []string) (*TransformerConfig, error) {
 	result := &TransformerConfig{}
 	for _, path := range paths {
-		data, err := ldr.Load(path)
+		data, err := loadConfig(ldr, path)
 		if err != nil {
 			return nil, err
 		}
@@ -30,6 +34,22 @@ func loadDefaultConfig(
 	return result, nil
 }
 
+// loadConfig returns the content of the file at path,
+// or of the bundle it names.
+func loadConfig(ldr ifc.Loader, path string) ([]byte, error) {
+	if !strings.HasPrefix(path, builtinpluginconsts.FieldSpecBundlePrefix) {
+		return ldr.Load(path)
+	}
+	name := strings.TrimPrefix(path, builtinpluginconsts.FieldSpecBundlePrefix)
+	bundle, found := builtinpluginconsts.GetFieldSpecBundle(name)
+	if !found {
+		return nil, fmt.Errorf(
+			"unknown configuration bundle %s; known bundles: %v",
+			path, builtinpluginconsts.FieldSpecBundleNames())
+	}
+	return []byte(bundle), nil
+}
+
 // makeTransformerConfigFromBytes returns a TransformerConfig object from bytes
 func makeTransformerConfigFromBytes(data []byte) (*TransformerConfig, error) {
 	var t TransformerConfig
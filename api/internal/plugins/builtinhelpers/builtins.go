@@ -25,8 +25,10 @@ const (
 	PatchTransformer
 	PrefixSuffixTransformer
 	ReplicaCountTransformer
+	SealedSecretGenerator
 	SecretGenerator
 	ValueAddTransformer
+	YttOverlayTransformer
 )
 
 var stringToBuiltinPluginTypeMap map[string]BuiltinPluginType
@@ -55,8 +57,9 @@ func GetBuiltinPluginType(n string) BuiltinPluginType {
 }
 
 var GeneratorFactories = map[BuiltinPluginType]func() resmap.GeneratorPlugin{
-	ConfigMapGenerator: builtins.NewConfigMapGeneratorPlugin,
-	SecretGenerator:    builtins.NewSecretGeneratorPlugin,
+	ConfigMapGenerator:    builtins.NewConfigMapGeneratorPlugin,
+	SealedSecretGenerator: builtins.NewSealedSecretGeneratorPlugin,
+	SecretGenerator:       builtins.NewSecretGeneratorPlugin,
 }
 
 var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin{
@@ -72,4 +75,5 @@ var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin
 	PrefixSuffixTransformer:        builtins.NewPrefixSuffixTransformerPlugin,
 	ReplicaCountTransformer:        builtins.NewReplicaCountTransformerPlugin,
 	ValueAddTransformer:            builtins.NewValueAddTransformerPlugin,
+	YttOverlayTransformer:          builtins.NewYttOverlayTransformerPlugin,
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/log"
 	"sigs.k8s.io/yaml"
 )
 
@@ -163,6 +164,7 @@ func (p *ExecPlugin) invokePlugin(input []byte) ([]byte, error) {
 	if _, err := os.Stat(p.h.Loader().Root()); err == nil {
 		cmd.Dir = p.h.Loader().Root()
 	}
+	log.Debug("running exec plugin", "path", p.path, "args", strings.Join(p.args, " "))
 	result, err := cmd.Output()
 	if err != nil {
 		return nil, errors.Wrapf(
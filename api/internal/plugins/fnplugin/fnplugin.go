@@ -77,6 +77,7 @@ func NewFnPlugin(o *types.FnPluginLoadingOptions) *FnPlugin {
 			NetworkName:    o.NetworkName,
 			EnableStarlark: o.EnableStar,
 			EnableExec:     o.EnableExec,
+			EnableRego:     o.EnableRego,
 			StorageMounts:  toStorageMounts(o.Mounts),
 		},
 	}
@@ -4,16 +4,19 @@
 package loader
 
 import (
+	"crypto/sha256"
 	"fmt"
-	"log"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"plugin"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
 	"sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
@@ -23,19 +26,70 @@ import (
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 // Loader loads plugins using a file loader (a different loader).
 type Loader struct {
-	pc *types.PluginConfig
-	rf *resmap.Factory
+	pc       *types.PluginConfig
+	rf       *resmap.Factory
+	recorder PluginRecorder
 }
 
+// PluginRecorder is told about each plugin, other than the
+// builtins, that a Loader loads: its kind, which is
+// "container", "exec", "starlark", "rego" or "go", the image,
+// path or URL it's loaded from, and the hex encoded sha256
+// digest of the file it's loaded from, if it's a local file.
+// It may be called concurrently.
+type PluginRecorder func(kind, location, digest string)
+
 func NewLoader(
 	pc *types.PluginConfig, rf *resmap.Factory) *Loader {
 	return &Loader{pc: pc, rf: rf}
 }
 
+// SetRecorder sets a PluginRecorder told about
+// the plugins the loader loads.
+func (l *Loader) SetRecorder(r PluginRecorder) {
+	l.recorder = r
+}
+
+// record tells the recorder, if any, about a plugin loaded
+// from location, hashing the file at path, if not empty.
+func (l *Loader) record(kind, location, path string) {
+	if l.recorder == nil {
+		return
+	}
+	digest := ""
+	if path != "" {
+		if b, err := ioutil.ReadFile(path); err == nil {
+			digest = fmt.Sprintf("%x", sha256.Sum256(b))
+		}
+	}
+	l.recorder(kind, location, digest)
+}
+
+// recordFunction tells the recorder, if any,
+// about the function plugin spec.
+func (l *Loader) recordFunction(spec *runtimeutil.FunctionSpec) {
+	switch {
+	case spec.Container.Image != "":
+		l.record("container", spec.Container.Image, "")
+	case spec.Exec.Path != "":
+		l.record("exec", spec.Exec.Path, spec.Exec.Path)
+	case spec.Starlark.URL != "":
+		l.record("starlark", spec.Starlark.URL, "")
+	case spec.Starlark.Path != "":
+		l.record("starlark", spec.Starlark.Path, spec.Starlark.Path)
+	case spec.Rego.Path != "":
+		l.record("rego", spec.Rego.Path, spec.Rego.Path)
+	case spec.Rego.Program != "":
+		l.record("rego", spec.Rego.Name, "")
+	}
+}
+
 func (l *Loader) LoadGenerators(
 	ldr ifc.Loader, v ifc.Validator, rm resmap.ResMap) ([]resmap.Generator, error) {
 	var result []resmap.Generator
@@ -115,6 +169,13 @@ func (l *Loader) loadAndConfigurePlugin(
 	v ifc.Validator,
 	res *resource.Resource) (c resmap.Configurable, err error) {
 	if isBuiltinPlugin(res) {
+		if res.GetKind() == builtinhelpers.YttOverlayTransformer.String() &&
+			!l.pc.FnpLoadingOptions.EnableYtt {
+			return nil, kusterr.OfResource(
+				errors.Errorf("%s runs ytt, which isn't enabled (use --enable-ytt)",
+					res.GetKind()),
+				res.OrgId(), res.GetOrigin())
+		}
 		switch l.pc.BpLoadingOptions {
 		case types.BploLoadFromFileSys:
 			c, err = l.loadPlugin(res)
@@ -150,8 +211,9 @@ func (l *Loader) loadAndConfigurePlugin(
 	}
 	err = c.Config(resmap.NewPluginHelpers(ldr, v, l.rf), yaml)
 	if err != nil {
-		return nil, errors.Wrapf(
-			err, "plugin %s fails configuration", res.OrgId())
+		return nil, kusterr.OfResource(
+			errors.Wrap(err, "plugin fails configuration"),
+			res.OrgId(), res.GetOrigin())
 	}
 	return c, nil
 }
@@ -170,6 +232,8 @@ func (l *Loader) makeBuiltinPlugin(r resid.Gvk) (resmap.Configurable, error) {
 func (l *Loader) loadPlugin(res *resource.Resource) (resmap.Configurable, error) {
 	spec := fnplugin.GetFunctionSpec(res)
 	if spec != nil {
+		log.Debug("loading function plugin", "id", res.OrgId().String())
+		l.recordFunction(spec)
 		return fnplugin.NewFnPlugin(&l.pc.FnpLoadingOptions), nil
 	}
 	return l.loadExecOrGoPlugin(res.OrgId())
@@ -180,6 +244,8 @@ func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, err
 	p := execplugin.NewExecPlugin(l.absolutePluginPath(resId))
 	err := p.ErrIfNotExecutable()
 	if err == nil {
+		log.Debug("loading exec plugin", "path", p.Path())
+		l.record("exec", p.Path(), p.Path())
 		return p, nil
 	}
 	if !os.IsNotExist(err) {
@@ -197,6 +263,8 @@ func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, err
 	if err != nil {
 		return nil, err
 	}
+	path := l.absolutePluginPath(resId) + ".so"
+	l.record("go", path, path)
 	return c, nil
 }
 
@@ -206,10 +274,17 @@ func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, err
 // but the loaded .so files are in shared memory, so one will get
 // "this plugin already loaded" errors if the registry is maintained
 // as a Loader instance variable.  So make it a package variable.
-var registry = make(map[string]resmap.Configurable)
+// Bases are loaded concurrently, so it's guarded by registryMu,
+// which is held while a plugin is opened so it's opened once.
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]resmap.Configurable)
+)
 
 func (l *Loader) loadGoPlugin(id resid.ResId) (resmap.Configurable, error) {
 	regId := relativePluginPath(id)
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	if c, ok := registry[regId]; ok {
 		return copyPlugin(c), nil
 	}
@@ -218,7 +293,7 @@ func (l *Loader) loadGoPlugin(id resid.ResId) (resmap.Configurable, error) {
 		return nil, fmt.Errorf(
 			"expected file with Go object code at: %s", absPath)
 	}
-	log.Printf("Attempting plugin load from '%s'", absPath)
+	log.Debug("loading Go plugin", "path", absPath)
 	p, err := plugin.Open(absPath)
 	if err != nil {
 		return nil, errors.Wrapf(err, "plugin %s fails to load", absPath)
@@ -82,3 +82,50 @@ func TestLoader(t *testing.T) {
 		}
 	}
 }
+
+func TestLoaderRecorder(t *testing.T) {
+	rmF := resmap.NewFactory(resource.NewFactory(
+		kunstruct.NewKunstructuredFactoryImpl()), nil)
+	fLdr, err := loader.NewLoader(
+		loader.RestrictionRootOnly,
+		filesys.Separator, filesys.MakeFsInMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: example.com/v1
+kind: Fn
+metadata:
+  name: fn
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/fn:v1
+---
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: labels
+labels:
+  app: web
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := konfig.MakePluginConfig(
+		types.PluginRestrictionsNone, types.BploUseStaticallyLinked,
+		konfig.NoPluginHomeSentinal)
+	pLdr := NewLoader(c, rmF)
+	var recorded []string
+	pLdr.SetRecorder(func(kind, location, digest string) {
+		recorded = append(recorded, kind+" "+location+" "+digest)
+	})
+	_, err = pLdr.LoadTransformers(
+		fLdr, valtest_test.MakeFakeValidator(), configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 || recorded[0] != "container example.com/fn:v1 " {
+		t.Fatalf("unexpected plugins recorded: %q", recorded)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"regexp"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var (
+	unknownFieldRegex = regexp.MustCompile(`json: unknown field "([^"]+)"`)
+	fieldTypeRegex    = regexp.MustCompile(
+		`json: cannot unmarshal .* into Go struct field [^.]+\.([^ ]+) of type`)
+	indexRegex = regexp.MustCompile(`\.([0-9]+)(\.|$)`)
+)
+
+// LocateDecodeError returns the line and column in the yaml
+// content of the field which err, an error decoding content
+// into a Go struct, is about, e.g. an unknown field or a
+// field of the wrong type.  It returns zeros if err names
+// no field, or the field isn't in content.
+func LocateDecodeError(content []byte, err error) (int, int) {
+	var doc yaml.Node
+	if yaml.Unmarshal(content, &doc) != nil || len(doc.Content) != 1 {
+		return 0, 0
+	}
+	msg := err.Error()
+	if m := unknownFieldRegex.FindStringSubmatch(msg); m != nil {
+		if n := findKey(doc.Content[0], m[1]); n != nil {
+			return n.Line, n.Column
+		}
+		return 0, 0
+	}
+	if m := fieldTypeRegex.FindStringSubmatch(msg); m != nil {
+		// Go's path, e.g. patches.0.target, as a Problem's.
+		path := m[1]
+		for indexRegex.MatchString(path) {
+			path = indexRegex.ReplaceAllString(path, "[$1]$2")
+		}
+		lp, found := locate(yaml.NewRNode(doc.Content[0]), Problem{Path: path})
+		if found {
+			return lp.Line, lp.Column
+		}
+	}
+	return 0, 0
+}
+
+// findKey returns the first mapping key named key under
+// node, depth first, or nil.
+func findKey(node *yaml.Node, key string) *yaml.Node {
+	for i, c := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			if c.Value == key {
+				return c
+			}
+			continue
+		}
+		if n := findKey(c, key); n != nil {
+			return n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLocateDecodeError(t *testing.T) {
+	content := []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resource:
+- deployment.yaml
+patches:
+- path: first.yaml
+- target:
+    kind: 3
+`)
+	testCases := map[string]struct {
+		err          error
+		line, column int
+	}{
+		"unknown field": {
+			err:  fmt.Errorf(`json: unknown field "resource"`),
+			line: 3, column: 1,
+		},
+		"wrong type": {
+			err: fmt.Errorf("json: cannot unmarshal number into Go struct " +
+				"field Kustomization.patches.1.target.kind of type string"),
+			line: 8, column: 5,
+		},
+		"absent field": {
+			err: fmt.Errorf("json: cannot unmarshal number into Go struct " +
+				"field Kustomization.images.0.name of type string"),
+		},
+		"no field": {
+			err: fmt.Errorf("unexpected end of JSON input"),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			line, column := LocateDecodeError(content, tc.err)
+			if line != tc.line || column != tc.column {
+				t.Fatalf("expected %d:%d, got %d:%d", tc.line, tc.column, line, column)
+			}
+		})
+	}
+}
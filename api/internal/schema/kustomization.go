@@ -0,0 +1,337 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-openapi/spec"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// LocatedProblem is a Problem found in a file,
+// with the line and column it was found at.
+type LocatedProblem struct {
+	Problem
+
+	// Line and Column locate the field with the problem,
+	// counting from 1.  They're 0 if it can't be located.
+	Line, Column int
+}
+
+func (p LocatedProblem) String() string {
+	if p.Line == 0 {
+		return p.Problem.String()
+	}
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Problem.String())
+}
+
+var (
+	kustomizationSchemaOnce sync.Once
+	kustomizationSchema     *spec.Schema
+)
+
+// KustomizationSchema returns the JSON schema of
+// kustomization files, derived from types.Kustomization.
+// It doesn't allow fields other than those declared.
+func KustomizationSchema() *spec.Schema {
+	kustomizationSchemaOnce.Do(func() {
+		kustomizationSchema = schemaOf(reflect.TypeOf(types.Kustomization{}))
+	})
+	return kustomizationSchema
+}
+
+// schemaOf returns the schema of the json encoding of t.
+func schemaOf(t reflect.Type) *spec.Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.String:
+		return &spec.Schema{SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"string"}}}
+	case reflect.Bool:
+		return &spec.Schema{SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"boolean"}}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &spec.Schema{SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"integer"}}}
+	case reflect.Float32, reflect.Float64:
+		return &spec.Schema{SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"number"}}}
+	case reflect.Slice, reflect.Array:
+		return &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:  spec.StringOrArray{"array"},
+			Items: &spec.SchemaOrArray{Schema: schemaOf(t.Elem())},
+		}}
+	case reflect.Map:
+		return &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:                 spec.StringOrArray{"object"},
+			AdditionalProperties: &spec.SchemaOrBool{Allows: true, Schema: schemaOf(t.Elem())},
+		}}
+	case reflect.Struct:
+		s := &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:                 spec.StringOrArray{"object"},
+			Properties:           map[string]spec.Schema{},
+			AdditionalProperties: &spec.SchemaOrBool{Allows: false},
+		}}
+		addProperties(s, t)
+		return s
+	default:
+		// Anything goes.
+		return &spec.Schema{}
+	}
+}
+
+// addProperties adds the fields of the struct t,
+// and of the structs embedded in it, to s.
+func addProperties(s *spec.Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addProperties(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = *schemaOf(f.Type)
+	}
+}
+
+// deprecatedKustomizationFields are the top level fields
+// of kustomization files which are deprecated, and what
+// to use instead.
+var deprecatedKustomizationFields = map[string]string{
+	"bases":     "resources",
+	"imageTags": "images",
+}
+
+// ValidateKustomization checks the kustomization file content
+// against the KustomizationSchema, returning its problems,
+// and the deprecated fields it uses, which include fields
+// not spelled in the case declared.  The content is checked
+// as types.FixKustomizationPreUnmarshalling fixes it, which
+// keeps fields on their lines.
+func ValidateKustomization(content []byte) (problems, deprecations []LocatedProblem, err error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, nil, nil
+	}
+	original, err := yaml.Parse(string(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	fixed, err := types.FixKustomizationPreUnmarshalling(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err := yaml.Parse(string(fixed))
+	if err != nil {
+		return nil, nil, err
+	}
+	if yaml.IsMissingOrNull(node) {
+		return nil, nil, nil
+	}
+	var obj interface{}
+	if err = node.YNode().Decode(&obj); err != nil {
+		return nil, nil, err
+	}
+	obj = normalize(obj)
+	for _, p := range canonicalize(KustomizationSchema(), "", obj) {
+		lp, _ := locate(node, p)
+		deprecations = append(deprecations, lp)
+	}
+	for _, p := range Validate(KustomizationSchema(), obj, true) {
+		lp, _ := locate(node, p)
+		problems = append(problems, lp)
+	}
+	if original.YNode().Kind != yaml.MappingNode {
+		sortByLocation(problems)
+		return problems, nil, nil
+	}
+	fields := original.YNode().Content
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, value := fields[i], fields[i+1]
+		var message string
+		if instead, found := deprecatedKustomizationFields[key.Value]; found {
+			message = "deprecated; use " + instead + " instead"
+		} else if key.Value == "patches" && hasScalarItem(value) {
+			message = "paths of patches are deprecated; " +
+				"list them in patchesStrategicMerge instead"
+		} else {
+			continue
+		}
+		deprecations = append(deprecations, LocatedProblem{
+			Problem: Problem{Path: key.Value, Message: message},
+			Line:    key.Line,
+			Column:  key.Column,
+		})
+	}
+	sortByLocation(problems)
+	sortByLocation(deprecations)
+	return problems, deprecations, nil
+}
+
+func sortByLocation(problems []LocatedProblem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+}
+
+func hasScalarItem(n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode {
+		return false
+	}
+	for _, item := range n.Content {
+		if item.Kind == yaml.ScalarNode {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize converts the maps yaml decodes into maps
+// with string keys, as Validate expects.
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = normalize(e)
+		}
+		return x
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range x {
+			x[i] = normalize(e)
+		}
+		return x
+	default:
+		return v
+	}
+}
+
+// canonicalize renames the fields of obj which match the
+// properties of s only ignoring case, as the json decoder
+// accepts them, to those properties, and returns problems
+// for them.  The paths of the problems are the original.
+func canonicalize(s *spec.Schema, path string, value interface{}) []Problem {
+	var problems []Problem
+	switch x := value.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(x))
+		for name := range x {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v := x[name]
+			fieldPath := joinPath(path, name)
+			p, found := s.Properties[name]
+			if !found && s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+				problems = append(problems,
+					canonicalize(s.AdditionalProperties.Schema, fieldPath, v)...)
+				continue
+			}
+			if !found {
+				for declared, ds := range s.Properties {
+					if strings.EqualFold(declared, name) {
+						if _, taken := x[declared]; taken {
+							break
+						}
+						problems = append(problems, Problem{
+							Path: fieldPath, Message: "should be spelled " + declared})
+						delete(x, name)
+						x[declared] = v
+						p, found = ds, true
+						break
+					}
+				}
+			}
+			if found {
+				problems = append(problems, canonicalize(&p, fieldPath, v)...)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil && s.Items.Schema != nil {
+			for i, item := range x {
+				problems = append(problems,
+					canonicalize(s.Items.Schema, path+"["+fmt.Sprint(i)+"]", item)...)
+			}
+		}
+	}
+	return problems
+}
+
+// locate finds the line and column of the field at the
+// path of p in node: the field's key, or its item in a list.
+// If the field isn't in node, the nearest field containing
+// it is found, and false is returned.
+func locate(node *yaml.RNode, p Problem) (LocatedProblem, bool) {
+	lp := LocatedProblem{Problem: p}
+	n := node.YNode()
+	lp.Line, lp.Column = n.Line, n.Column
+	rest := p.Path
+	for rest != "" && n != nil {
+		rest = strings.TrimPrefix(rest, ".")
+		var next *yaml.Node
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			var i int
+			if end < 0 {
+				return lp, false
+			}
+			if _, err := fmt.Sscanf(rest[1:end], "%d", &i); err != nil ||
+				n.Kind != yaml.SequenceNode || i >= len(n.Content) {
+				return lp, false
+			}
+			next = n.Content[i]
+			lp.Line, lp.Column = next.Line, next.Column
+			rest = rest[end+1:]
+		case n.Kind == yaml.MappingNode:
+			// Keys may hold dots and brackets,
+			// so the longest matching key wins.
+			matched := ""
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k := n.Content[i].Value
+				// Paths may differ in case, see canonicalize.
+				r := strings.ToLower(rest)
+				if l := strings.ToLower(k); len(k) > len(matched) && (r == l ||
+					strings.HasPrefix(r, l+".") || strings.HasPrefix(r, l+"[")) {
+					matched = k
+					lp.Line, lp.Column = n.Content[i].Line, n.Content[i].Column
+					next = n.Content[i+1]
+				}
+			}
+			if matched == "" {
+				return lp, false
+			}
+			rest = rest[len(matched):]
+		default:
+			return lp, false
+		}
+		n = next
+	}
+	return lp, rest == ""
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateKustomization(t *testing.T) {
+	problems, deprecations, err := ValidateKustomization([]byte(`
+namePrefx: x-
+bases:
+- ../base
+imageTags:
+- name: nginx
+  newTag: "1.19"
+commonLabels:
+  app.kubernetes.io/name: web
+  tier: 1
+configMapGenerator:
+- name: settings
+  literals: A=B
+vars:
+- name: SERVICE
+  objref:
+    kind: Service
+    name: web
+    apiVersion: v1
+  fieldref:
+    fieldpath: metadata.name
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"2:1: namePrefx: unknown field",
+		"10:3: commonLabels.tier: expected string, got integer",
+		"13:3: configMapGenerator[0].literals: expected array, got string",
+	}
+	actual := stringsOf(problems)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected problems %q, got %q", expected, actual)
+	}
+	expected = []string{
+		"3:1: bases: deprecated; use resources instead",
+		"5:1: imageTags: deprecated; use images instead",
+		"21:5: vars[0].fieldref.fieldpath: should be spelled fieldPath",
+	}
+	actual = stringsOf(deprecations)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected deprecations %q, got %q", expected, actual)
+	}
+}
+
+func TestValidateKustomizationLegacyPatches(t *testing.T) {
+	problems, deprecations, err := ValidateKustomization([]byte(`
+patches:
+- patch.yaml
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("unexpected problems %q", stringsOf(problems))
+	}
+	expected := []string{"2:1: patches: paths of patches are deprecated; " +
+		"list them in patchesStrategicMerge instead"}
+	if actual := stringsOf(deprecations); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected deprecations %q, got %q", expected, actual)
+	}
+}
+
+func stringsOf(problems []LocatedProblem) []string {
+	var result []string
+	for _, p := range problems {
+		result = append(result, p.String())
+	}
+	return result
+}
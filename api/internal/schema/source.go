@@ -0,0 +1,39 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"io"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// LocateInSource finds the line and column, in content, of
+// the field with the problem p of the object of kind named
+// name, where content holds the yaml documents the object was
+// read from.  It returns false if the object or the field
+// isn't in content, e.g. if the field was added by a patch.
+func LocateInSource(content []byte, kind, name string, p Problem) (LocatedProblem, bool) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if err != io.EOF {
+				return LocatedProblem{Problem: p}, false
+			}
+			break
+		}
+		node := yaml.NewRNode(&doc)
+		if node.YNode().Kind != yaml.MappingNode {
+			continue
+		}
+		meta, err := node.GetMeta()
+		if err != nil || meta.Kind != kind || meta.Name != name {
+			continue
+		}
+		return locate(node, p)
+	}
+	return LocatedProblem{Problem: p}, false
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"testing"
+)
+
+func TestLocateInSource(t *testing.T) {
+	content := []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replcias: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        imagee: nginx
+`)
+	for _, tc := range []struct {
+		kind, path string
+		expected   string
+		found      bool
+	}{
+		{"Deployment", "spec.replcias", "11:3: spec.replcias: unknown field", true},
+		{"Deployment", "spec.template.spec.containers[0].imagee",
+			"16:9: spec.template.spec.containers[0].imagee: unknown field", true},
+		{"Service", "spec.replcias", "", false},
+		{"Deployment", "spec.selector", "", false},
+		{"ConfigMap", "data", "", false},
+	} {
+		lp, found := LocateInSource(content, tc.kind, "web",
+			Problem{Path: tc.path, Message: "unknown field"})
+		if found != tc.found {
+			t.Errorf("%s %s: expected found %v", tc.kind, tc.path, tc.found)
+			continue
+		}
+		if found && lp.String() != tc.expected {
+			t.Errorf("%s %s: expected %q, got %q", tc.kind, tc.path, tc.expected, lp.String())
+		}
+	}
+}
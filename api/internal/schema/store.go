@@ -0,0 +1,160 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-openapi/spec"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultLocation holds the schemas of the
+// builtin kinds, for each Kubernetes version.
+const DefaultLocation = "https://raw.githubusercontent.com/yannh/kubernetes-json-schema/master/" +
+	"{{ .NormalizedKubernetesVersion }}-standalone{{ .StrictSuffix }}/" +
+	"{{ .ResourceKind }}{{ .KindSuffix }}.json"
+
+// fileNameTemplate names the schema files in
+// locations that aren't templates themselves.
+const fileNameTemplate = "{{ .ResourceKind }}{{ .KindSuffix }}.json"
+
+// Store finds the schemas of kinds in a list of locations,
+// and keeps the ones it found.  It's safe for concurrent use.
+//
+// A location is a directory or URL, holding files named as
+// in kubeconform, e.g. deployment-apps-v1.json; or a template
+// of a file name or URL, using the variables ResourceKind,
+// ResourceAPIVersion, Group, KindSuffix, StrictSuffix and
+// NormalizedKubernetesVersion; or "default", for DefaultLocation.
+type Store struct {
+	fSys              filesys.FileSystem
+	locations         []*template.Template
+	kubernetesVersion string
+	strict            bool
+	client            *http.Client
+
+	mu      sync.Mutex
+	schemas map[resid.Gvk]*spec.Schema
+}
+
+// NewStore returns a Store looking in the given locations, in
+// order.  Schemas not fetched by URL are read from fSys.  The
+// kubernetesVersion is a version like 1.18.0, or "master".
+func NewStore(
+	fSys filesys.FileSystem, locations []string,
+	kubernetesVersion string, strict bool) (*Store, error) {
+	s := &Store{
+		fSys:              fSys,
+		kubernetesVersion: kubernetesVersion,
+		strict:            strict,
+		client:            &http.Client{Timeout: 30 * time.Second},
+		schemas:           map[resid.Gvk]*spec.Schema{},
+	}
+	if s.kubernetesVersion == "" {
+		s.kubernetesVersion = "master"
+	}
+	for _, l := range locations {
+		if l == "default" {
+			l = DefaultLocation
+		} else if !strings.Contains(l, "{{") {
+			l = strings.TrimSuffix(l, "/") + "/" + fileNameTemplate
+		}
+		t, err := template.New(l).Parse(l)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing schema location %s", l)
+		}
+		s.locations = append(s.locations, t)
+	}
+	return s, nil
+}
+
+// Find returns the schema of gvk, or nil if no
+// location has it.
+func (s *Store) Find(gvk resid.Gvk) (*spec.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sch, found := s.schemas[gvk]; found {
+		return sch, nil
+	}
+	for _, t := range s.locations {
+		var b bytes.Buffer
+		if err := t.Execute(&b, s.templateData(gvk)); err != nil {
+			return nil, errors.Wrapf(err, "expanding schema location %s", t.Name())
+		}
+		data, err := s.read(b.String())
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+		var sch spec.Schema
+		if err = yaml.Unmarshal(data, &sch); err != nil {
+			return nil, errors.Wrapf(err, "parsing schema %s", b.String())
+		}
+		s.schemas[gvk] = &sch
+		return &sch, nil
+	}
+	s.schemas[gvk] = nil
+	return nil, nil
+}
+
+// templateData returns the variables of
+// the location templates for gvk.
+func (s *Store) templateData(gvk resid.Gvk) map[string]string {
+	version := s.kubernetesVersion
+	if version != "master" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	strictSuffix := ""
+	if s.strict {
+		strictSuffix = "-strict"
+	}
+	kindSuffix := "-" + strings.ToLower(gvk.Version)
+	if gvk.Group != "" {
+		kindSuffix = "-" + strings.ToLower(strings.Split(gvk.Group, ".")[0]) + kindSuffix
+	}
+	return map[string]string{
+		"ResourceKind":                strings.ToLower(gvk.Kind),
+		"ResourceAPIVersion":          gvk.Version,
+		"Group":                       gvk.Group,
+		"KindSuffix":                  kindSuffix,
+		"StrictSuffix":                strictSuffix,
+		"NormalizedKubernetesVersion": version,
+	}
+}
+
+// read returns the schema at location, or nil
+// if there's nothing there.
+func (s *Store) read(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		if !s.fSys.Exists(location) {
+			return nil, nil
+		}
+		return s.fSys.ReadFile(location)
+	}
+	resp, err := s.client.Get(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching schema %s", location)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching schema %s: %s", location, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
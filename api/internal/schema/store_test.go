@@ -0,0 +1,83 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+func TestStoreFindsSchemasInDirectories(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/schemas/deployment-apps-v1.json", []byte(`{"type": "object"}`))
+	fSys.WriteFile("/crds/example.com/widget_v1beta1.json", []byte(`{"type": "array"}`))
+	s, err := NewStore(fSys, []string{
+		"/schemas/",
+		"/crds/{{ .Group }}/{{ .ResourceKind }}_{{ .ResourceAPIVersion }}.json",
+	}, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gvk, expected := range map[resid.Gvk]string{
+		{Group: "apps", Version: "v1", Kind: "Deployment"}:             "object",
+		{Group: "example.com", Version: "v1beta1", Kind: "Widget"}:     "array",
+		{Group: "example.com", Version: "v1", Kind: "Widget"}:          "",
+		{Group: "", Version: "v1", Kind: "Service"}:                    "",
+		{Group: "apps.example.com", Version: "v1", Kind: "Deployment"}: "object",
+	} {
+		sch, err := s.Find(gvk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected == "" {
+			if sch != nil {
+				t.Errorf("%v: expected no schema, got %v", gvk, sch.Type)
+			}
+			continue
+		}
+		if sch == nil || !sch.Type.Contains(expected) {
+			t.Errorf("%v: expected a schema of type %s, got %v", gvk, expected, sch)
+		}
+	}
+}
+
+func TestStoreFetchesSchemas(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path != "/v1.18.0-standalone-strict/service-v1.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"type": "object"}`))
+	}))
+	defer server.Close()
+	s, err := NewStore(filesys.MakeFsInMemory(), []string{
+		server.URL + "/{{ .NormalizedKubernetesVersion }}-standalone{{ .StrictSuffix }}/" +
+			"{{ .ResourceKind }}{{ .KindSuffix }}.json",
+	}, "1.18.0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		sch, err := s.Find(resid.Gvk{Version: "v1", Kind: "Service"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sch == nil {
+			t.Fatalf("expected a schema")
+		}
+		sch, err = s.Find(resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"})
+		if err != nil || sch != nil {
+			t.Fatalf("expected no schema, got %v, %v", sch, err)
+		}
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected the schemas to be fetched once, got %v", requests)
+	}
+}
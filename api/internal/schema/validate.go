@@ -0,0 +1,209 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package schema checks resources against the JSON schemas
+// of their kinds, in the manner of kubeconform.
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// Problem is a way in which a resource doesn't match its schema.
+type Problem struct {
+	// Path is the field with the problem, e.g.
+	// spec.template.spec.containers[0].image.
+	// It's empty for problems with the whole resource.
+	Path string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// Validate returns the problems found checking obj against
+// the schema s.  If strict is true, fields not declared by
+// objects with declared properties are reported as unknown,
+// even if the schema doesn't forbid additional properties.
+//
+// Null values are accepted anywhere, since kubectl and the
+// API server drop them.
+func Validate(s *spec.Schema, obj interface{}, strict bool) []Problem {
+	v := &validator{root: s, strict: strict}
+	v.validate(s, "", obj)
+	return v.problems
+}
+
+type validator struct {
+	root     *spec.Schema
+	strict   bool
+	problems []Problem
+}
+
+func (v *validator) report(path string, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(s *spec.Schema, path string, value interface{}) {
+	s = v.resolve(s)
+	if s == nil || value == nil {
+		return
+	}
+	if !matchesType(s, value) {
+		v.report(path, "expected %s, got %s", strings.Join(s.Type, " or "), jsonType(value))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		v.report(path, "value %v isn't one of %v", value, s.Enum)
+	}
+	for i := range s.AllOf {
+		v.validate(&s.AllOf[i], path, value)
+	}
+	if len(s.AnyOf) > 0 && !v.matchesOne(s.AnyOf, path, value) {
+		v.report(path, "value doesn't match any of the allowed schemas")
+	}
+	if len(s.OneOf) > 0 && !v.matchesOne(s.OneOf, path, value) {
+		v.report(path, "value doesn't match any of the allowed schemas")
+	}
+	switch x := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(s, path, x)
+	case []interface{}:
+		if s.Items != nil && s.Items.Schema != nil {
+			for i, item := range x {
+				v.validate(s.Items.Schema, path+"["+strconv.Itoa(i)+"]", item)
+			}
+		}
+	}
+}
+
+func (v *validator) validateObject(s *spec.Schema, path string, obj map[string]interface{}) {
+	for _, name := range s.Required {
+		if _, found := obj[name]; !found {
+			v.report(path, "missing required field %q", name)
+		}
+	}
+	_, preserve := s.Extensions["x-kubernetes-preserve-unknown-fields"]
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fieldPath := joinPath(path, name)
+		if p, found := s.Properties[name]; found {
+			v.validate(&p, fieldPath, obj[name])
+			continue
+		}
+		switch {
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+			v.validate(s.AdditionalProperties.Schema, fieldPath, obj[name])
+		case s.AdditionalProperties != nil && !s.AdditionalProperties.Allows:
+			v.report(fieldPath, "unknown field")
+		case s.AdditionalProperties == nil && v.strict && len(s.Properties) > 0 && !preserve:
+			v.report(fieldPath, "unknown field")
+		}
+	}
+}
+
+// matchesOne returns true if value has no problems
+// with at least one of the schemas.
+func (v *validator) matchesOne(schemas []spec.Schema, path string, value interface{}) bool {
+	for i := range schemas {
+		sub := &validator{root: v.root, strict: v.strict}
+		sub.validate(&schemas[i], path, value)
+		if len(sub.problems) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve follows s's reference into the definitions
+// of the root schema, if s is a reference.
+func (v *validator) resolve(s *spec.Schema) *spec.Schema {
+	for i := 0; s != nil && s.Ref.String() != "" && i < 10; i++ {
+		name := strings.TrimPrefix(s.Ref.String(), "#/definitions/")
+		d, found := v.root.Definitions[name]
+		if !found {
+			// Unresolvable references are not checked.
+			return nil
+		}
+		s = &d
+	}
+	return s
+}
+
+func matchesType(s *spec.Schema, value interface{}) bool {
+	actual := jsonType(value)
+	if _, found := s.Extensions["x-kubernetes-int-or-string"]; found || s.Format == "int-or-string" {
+		return actual == "integer" || actual == "string"
+	}
+	if len(s.Type) == 0 {
+		return true
+	}
+	for _, t := range s.Type {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON schema type of value;
+// whole numbers are integers.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int32, int64, uint, uint32, uint64:
+		return "integer"
+	case float64:
+		if x := value.(float64); x == math.Trunc(x) {
+			return "integer"
+		}
+		return "number"
+	case float32:
+		if x := float64(value.(float32)); x == math.Trunc(x) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) || fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
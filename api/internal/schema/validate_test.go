@@ -0,0 +1,151 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"sigs.k8s.io/yaml"
+)
+
+const deploymentSchema = `
+type: object
+required: [spec]
+properties:
+  apiVersion: {type: string}
+  kind: {type: string}
+  metadata:
+    type: object
+    x-kubernetes-preserve-unknown-fields: true
+    properties:
+      name: {type: string}
+  spec:
+    $ref: "#/definitions/spec"
+definitions:
+  spec:
+    type: object
+    properties:
+      replicas: {type: integer}
+      strategy:
+        type: string
+        enum: [Recreate, RollingUpdate]
+      port:
+        oneOf:
+        - type: string
+        - type: integer
+      containers:
+        type: array
+        items:
+          type: object
+          additionalProperties: false
+          properties:
+            image: {type: string}
+      env:
+        type: object
+        additionalProperties: {type: string}
+`
+
+func TestValidate(t *testing.T) {
+	var s spec.Schema
+	if err := yaml.Unmarshal([]byte(deploymentSchema), &s); err != nil {
+		t.Fatal(err)
+	}
+	testCases := map[string]struct {
+		obj      string
+		strict   bool
+		expected []Problem
+	}{
+		"valid": {
+			obj: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  creationTimestamp: null
+spec:
+  replicas: 3
+  strategy: Recreate
+  port: http
+  containers:
+  - image: nginx
+  env:
+    A: b
+`,
+		},
+		"typeErrors": {
+			obj: `
+spec:
+  replicas: three
+  port: true
+  containers:
+  - image: 1
+  env:
+    A: 1
+`,
+			expected: []Problem{
+				{Path: "spec.containers[0].image", Message: "expected string, got integer"},
+				{Path: "spec.env.A", Message: "expected string, got integer"},
+				{Path: "spec.port", Message: "value doesn't match any of the allowed schemas"},
+				{Path: "spec.replicas", Message: "expected integer, got string"},
+			},
+		},
+		"unknownFields": {
+			obj: `
+metadata:
+  labels: {}
+spec:
+  replica: 3
+  containers:
+  - image: nginx
+    imagePullPolicy: Always
+`,
+			expected: []Problem{
+				{Path: "spec.containers[0].imagePullPolicy", Message: "unknown field"},
+			},
+		},
+		"unknownFieldsStrict": {
+			obj: `
+metadata:
+  labels: {}
+spec:
+  replica: 3
+`,
+			strict: true,
+			expected: []Problem{
+				{Path: "spec.replica", Message: "unknown field"},
+			},
+		},
+		"enumAndRequired": {
+			obj: `
+kind: Deployment
+`,
+			expected: []Problem{
+				{Message: `missing required field "spec"`},
+			},
+		},
+		"badEnum": {
+			obj: `
+spec:
+  strategy: Blue
+`,
+			expected: []Problem{
+				{Path: "spec.strategy", Message: "value Blue isn't one of [Recreate RollingUpdate]"},
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var obj map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tc.obj), &obj); err != nil {
+				t.Fatal(err)
+			}
+			actual := Validate(&s, obj, tc.strict)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
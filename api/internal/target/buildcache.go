@@ -0,0 +1,202 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path/filepath"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+)
+
+// BuildCache holds the accumulated results of the kustomization
+// directories used as bases, so that builds sharing bases only
+// accumulate each of them once.
+//
+// A result is reused as long as every file read to make it
+// still has the same content.  Remote bases are keyed by their
+// repository, ref and path rather than by the directory they
+// were cloned to.  A BuildCache should only be shared by builds
+// with the same options, and is safe for concurrent use.
+type BuildCache struct {
+	fSys    filesys.FileSystem
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is the result of accumulating one directory.
+type cacheEntry struct {
+	// files maps the paths, relative to the directory, of the
+	// files read to accumulate it to hashes of their contents.
+	files map[string]string
+	ra    *accumulator.ResAccumulator
+}
+
+// NewBuildCache returns an empty BuildCache reading
+// files from the given file system.
+func NewBuildCache(fSys filesys.FileSystem) *BuildCache {
+	return &BuildCache{fSys: fSys, entries: map[string]*cacheEntry{}}
+}
+
+// get returns a copy of the result cached for the directory
+// at root with the given key, and the files it was made from,
+// or nil if there's no such result or the files have changed.
+func (c *BuildCache) get(key, root string) (
+	*accumulator.ResAccumulator, map[string]string) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	for path, hash := range e.files {
+		if hashFile(c.fSys, filepath.Join(root, path)) != hash {
+			return nil, nil
+		}
+	}
+	return e.ra.DeepCopy(), e.files
+}
+
+// put caches a copy of the result of accumulating the
+// directory at root, made by reading the recorded files.
+func (c *BuildCache) put(
+	key, root string, rec *fileRecorder, ra *accumulator.ResAccumulator) {
+	files, ok := rec.relativeTo(root)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &cacheEntry{files: files, ra: ra.DeepCopy()}
+}
+
+// hashFile returns a hash of the file's content,
+// or the empty string if it can't be read.
+func hashFile(fSys filesys.FileSystem, path string) string {
+	b, err := fSys.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return hashBytes(b)
+}
+
+func hashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// fileRecorder records the files read by a recordingLoader,
+// and passes them on to the recorder of its parent directory.
+type fileRecorder struct {
+	parent *fileRecorder
+	mu     sync.Mutex
+	// files maps absolute paths to hashes of their contents.
+	files map[string]string
+	// uncacheable is true if something other than a file was read.
+	uncacheable bool
+}
+
+func newFileRecorder(parent *fileRecorder) *fileRecorder {
+	return &fileRecorder{parent: parent, files: map[string]string{}}
+}
+
+func (r *fileRecorder) record(path, hash string) {
+	for ; r != nil; r = r.parent {
+		r.mu.Lock()
+		r.files[path] = hash
+		r.mu.Unlock()
+	}
+}
+
+func (r *fileRecorder) recordUncacheable() {
+	for ; r != nil; r = r.parent {
+		r.mu.Lock()
+		r.uncacheable = true
+		r.mu.Unlock()
+	}
+}
+
+// recordEntry records the files of a cached result,
+// read relative to root, as if they had been read.
+func (r *fileRecorder) recordEntry(root string, files map[string]string) {
+	for path, hash := range files {
+		r.record(filepath.Join(root, path), hash)
+	}
+}
+
+// relativeTo returns the recorded files relative to root,
+// or false if the result can't be cached.
+func (r *fileRecorder) relativeTo(root string) (map[string]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.uncacheable {
+		return nil, false
+	}
+	files := make(map[string]string, len(r.files))
+	for path, hash := range r.files {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, false
+		}
+		files[rel] = hash
+	}
+	return files, true
+}
+
+// recordingLoader is a Loader that records the files it reads.
+// Loaders it makes record into their own recorder, whose files
+// are also recorded by this one.
+type recordingLoader struct {
+	ifc.Loader
+	fSys filesys.FileSystem
+	rec  *fileRecorder
+}
+
+func newRecordingLoader(
+	ldr ifc.Loader, fSys filesys.FileSystem, parent *fileRecorder) *recordingLoader {
+	return &recordingLoader{Loader: ldr, fSys: fSys, rec: newFileRecorder(parent)}
+}
+
+// New implements ifc.Loader.
+func (l *recordingLoader) New(newRoot string) (ifc.Loader, error) {
+	ldr, err := l.Loader.New(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRecordingLoader(ldr, l.fSys, l.rec), nil
+}
+
+// Load implements ifc.Loader.
+func (l *recordingLoader) Load(location string) ([]byte, error) {
+	b, err := l.Loader.Load(location)
+	if u, uErr := url.Parse(location); uErr == nil && u.Scheme != "" {
+		l.rec.recordUncacheable()
+		return b, err
+	}
+	path := location
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.Root(), path)
+	}
+	if err == nil {
+		l.rec.record(path, hashBytes(b))
+	} else {
+		// The file may exist but be out of bounds, so record
+		// what BuildCache.get will find when it checks it.
+		l.rec.record(path, hashFile(l.fSys, path))
+	}
+	return b, err
+}
+
+// cacheKey returns the key of the directory the loader is rooted at.
+func (l *recordingLoader) cacheKey() string {
+	if k, ok := l.Loader.(interface{ CacheKey() string }); ok {
+		return k.CacheKey()
+	}
+	return l.Root()
+}
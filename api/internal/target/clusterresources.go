@@ -0,0 +1,168 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// ClusterResourcePrefix prefixes the resources of a
+// kustomization which are read from the live cluster, e.g.
+// cluster://apps/v1/Deployment/default/web, or for core
+// kinds, cluster://v1/ConfigMap/default/settings.  The
+// namespace is left out for cluster scoped kinds.
+const ClusterResourcePrefix = "cluster://"
+
+// ClusterReader reads resources from the live cluster with
+// the kubectl program, so that kustomizations can patch
+// objects as they are, e.g. to correct drift.
+type ClusterReader struct {
+	// Kubeconfig is the kubeconfig file to use.
+	// kubectl's default if empty.
+	Kubeconfig string
+
+	// Context is the kubeconfig context to use.
+	// The current context if empty.
+	Context string
+}
+
+// clusterObject names an object in the cluster.
+type clusterObject struct {
+	group, version, kind, namespace, name string
+}
+
+var versionRegex = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+func parseClusterResource(path string) (clusterObject, error) {
+	invalid := fmt.Errorf(
+		"expected %s[group/]version/kind/[namespace/]name, got %s",
+		ClusterResourcePrefix, path)
+	parts := strings.Split(strings.TrimPrefix(path, ClusterResourcePrefix), "/")
+	for _, p := range parts {
+		if p == "" {
+			return clusterObject{}, invalid
+		}
+	}
+	var o clusterObject
+	if !versionRegex.MatchString(parts[0]) {
+		o.group, parts = parts[0], parts[1:]
+	}
+	switch len(parts) {
+	case 3:
+		o.version, o.kind, o.name = parts[0], parts[1], parts[2]
+	case 4:
+		o.version, o.kind, o.namespace, o.name = parts[0], parts[1], parts[2], parts[3]
+	default:
+		return clusterObject{}, invalid
+	}
+	if !versionRegex.MatchString(o.version) {
+		return clusterObject{}, invalid
+	}
+	return o, nil
+}
+
+func (o clusterObject) apiVersion() string {
+	if o.group == "" {
+		return o.version
+	}
+	return o.group + "/" + o.version
+}
+
+// read returns the object at path in the cluster,
+// without the fields the cluster manages.
+func (c *ClusterReader) read(path string) ([]byte, error) {
+	o, err := parseClusterResource(path)
+	if err != nil {
+		return nil, err
+	}
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, errors.Wrap(err, "reading resources from the cluster needs kubectl")
+	}
+	resource := o.kind
+	if o.group != "" {
+		resource += "." + o.version + "." + o.group
+	}
+	args := []string{"get", resource, o.name, "--output", "json"}
+	if o.namespace != "" {
+		args = append(args, "--namespace", o.namespace)
+	}
+	if c.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", c.Kubeconfig)
+	}
+	if c.Context != "" {
+		args = append(args, "--context", c.Context)
+	}
+	cmd := exec.Command(kubectl, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "kubectl get: %s", strings.TrimSpace(stderr.String()))
+	}
+	var obj map[string]interface{}
+	if err = json.Unmarshal(stdout.Bytes(), &obj); err != nil {
+		return nil, errors.Wrap(err, "kubectl get")
+	}
+	if obj["apiVersion"] != o.apiVersion() || obj["kind"] != o.kind {
+		return nil, fmt.Errorf(
+			"kubectl get returned a %v %v rather than a %s %s",
+			obj["apiVersion"], obj["kind"], o.apiVersion(), o.kind)
+	}
+	stripClusterFields(obj)
+	return json.Marshal(obj)
+}
+
+// stripClusterFields removes the fields of obj which
+// the cluster sets, and which a kustomization shouldn't.
+func stripClusterFields(obj map[string]interface{}) {
+	delete(obj, "status")
+	meta, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, f := range []string{
+		"managedFields", "resourceVersion", "uid", "selfLink",
+		"creationTimestamp", "generation", "deletionTimestamp",
+		"deletionGracePeriodSeconds",
+	} {
+		delete(meta, f)
+	}
+	if annotations, ok := meta["annotations"].(map[string]interface{}); ok {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		delete(annotations, "deployment.kubernetes.io/revision")
+		if len(annotations) == 0 {
+			delete(meta, "annotations")
+		}
+	}
+}
+
+// accumulateClusterResource returns the object at path in
+// the cluster, if reading the cluster is enabled.
+func (kt *KustTarget) accumulateClusterResource(path string) (resmap.ResMap, error) {
+	if kt.cluster == nil {
+		return nil, fmt.Errorf(
+			"reading resources from the cluster isn't enabled: %s", path)
+	}
+	b, err := kt.cluster.read(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	m, err := kt.rFactory.NewResMapFromBytes(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	for _, r := range m.Resources() {
+		r.SetOrigin(path)
+	}
+	return m, nil
+}
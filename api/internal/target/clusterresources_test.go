@@ -0,0 +1,70 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"testing"
+)
+
+func TestParseClusterResource(t *testing.T) {
+	testCases := map[string]clusterObject{
+		"cluster://apps/v1/Deployment/default/web": {
+			group: "apps", version: "v1", kind: "Deployment", namespace: "default", name: "web"},
+		"cluster://v1/ConfigMap/default/settings": {
+			version: "v1", kind: "ConfigMap", namespace: "default", name: "settings"},
+		"cluster://rbac.authorization.k8s.io/v1/ClusterRole/admin": {
+			group: "rbac.authorization.k8s.io", version: "v1", kind: "ClusterRole", name: "admin"},
+		"cluster://v1/Namespace/default": {
+			version: "v1", kind: "Namespace", name: "default"},
+		"cluster://example.com/v1beta2/Widget/ns/w": {
+			group: "example.com", version: "v1beta2", kind: "Widget", namespace: "ns", name: "w"},
+	}
+	for path, expected := range testCases {
+		actual, err := parseClusterResource(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if actual != expected {
+			t.Fatalf("%s: expected %+v, got %+v", path, expected, actual)
+		}
+	}
+	for _, path := range []string{
+		"cluster://v1/ConfigMap",
+		"cluster://apps/Deployment/default/web",
+		"cluster://apps/v1/Deployment/default/web/extra",
+		"cluster://v1/ConfigMap//settings",
+	} {
+		if _, err := parseClusterResource(path); err == nil {
+			t.Fatalf("%s: expected an error", path)
+		}
+	}
+}
+
+func TestStripClusterFields(t *testing.T) {
+	obj := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":              "settings",
+			"uid":               "1234",
+			"resourceVersion":   "42",
+			"creationTimestamp": "2020-01-01T00:00:00Z",
+			"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+			"labels": map[string]interface{}{"app": "web"},
+		},
+		"data":   map[string]interface{}{"a": "b"},
+		"status": map[string]interface{}{},
+	}
+	stripClusterFields(obj)
+	meta := obj["metadata"].(map[string]interface{})
+	if len(meta) != 2 || meta["name"] != "settings" || meta["labels"] == nil {
+		t.Fatalf("unexpected metadata %v", meta)
+	}
+	if _, found := obj["status"]; found {
+		t.Fatalf("status should be removed")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// ReferenceCheck configures reporting references to
+// ConfigMaps, Secrets and Services which name neither a
+// resource of the build output nor one of ExternalNames.
+type ReferenceCheck struct {
+	// ExternalNames are the names of objects made outside
+	// of the build, either as kind/name, e.g.
+	// Secret/registry-credentials, or as a name of
+	// any kind.
+	ExternalNames []string
+
+	// When true, dangling references fail the build,
+	// rather than being warned about.
+	FailOnError bool
+}
+
+// isExternal returns whether d refers to one of ExternalNames.
+func (c *ReferenceCheck) isExternal(d accumulator.DanglingReference) bool {
+	for _, n := range c.ExternalNames {
+		if n == d.Name || n == d.Target.Kind+"/"+d.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetReferenceCheck enables checking for references to
+// ConfigMaps, Secrets and Services that aren't in the
+// output, once name references are fixed.
+func (kt *KustTarget) SetReferenceCheck(c *ReferenceCheck) {
+	kt.refCheck = c
+}
+
+func (kt *KustTarget) checkDanglingReferences(ra *accumulator.ResAccumulator) error {
+	if kt.refCheck == nil {
+		return nil
+	}
+	dangling, err := ra.DanglingReferences()
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, d := range dangling {
+		if kt.refCheck.isExternal(d) {
+			continue
+		}
+		if !kt.refCheck.FailOnError {
+			kt.warn(types.Warning{
+				Code:     types.WarningDanglingReference,
+				Message:  fmt.Sprintf("%s: %s %s not found", d.Path, d.Target.Kind, d.Name),
+				Resource: d.Referrer.String(),
+			})
+		}
+		problems = append(problems, d.String())
+	}
+	if kt.refCheck.FailOnError && len(problems) > 0 {
+		return fmt.Errorf(
+			"%d dangling references:\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
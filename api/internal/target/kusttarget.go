@@ -6,30 +6,54 @@ package target
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/builtins"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/schema"
 	"sigs.k8s.io/kustomize/api/konfig"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/transform"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/log"
+	"sigs.k8s.io/kustomize/kyaml/metrics"
 	"sigs.k8s.io/yaml"
 )
 
 // KustTarget encapsulates the entirety of a kustomization build.
 type KustTarget struct {
-	kustomization *types.Kustomization
-	ldr           ifc.Loader
-	validator     ifc.Validator
-	rFactory      *resmap.Factory
-	tFactory      resmap.PatchFactory
-	pLdr          *loader.Loader
+	kustomization        *types.Kustomization
+	ldr                  ifc.Loader
+	validator            ifc.Validator
+	rFactory             *resmap.Factory
+	tFactory             resmap.PatchFactory
+	pLdr                 *loader.Loader
+	parallelism          int
+	parallelTransformers bool
+	sops                 types.SopsDecryption
+	kms                  bool
+	strict               bool
+	patchesMustMatch     bool
+	strictVars           bool
+	timer                func(phase string, elapsed time.Duration)
+	steps                func(step string, m resmap.ResMap)
+	cache                *BuildCache
+	input                resmap.ResMap
+	cluster              *ClusterReader
+	refCheck             *ReferenceCheck
+	nsCheck              *NamespaceCheck
+	warner               types.Warner
+	metrics              metrics.Recorder
 }
 
 // NewKustTarget returns a new instance of KustTarget.
@@ -48,20 +72,138 @@ func NewKustTarget(
 	}
 }
 
+// SetParallelism sets the maximum number of resources and bases
+// that are accumulated concurrently at each level of the build.
+// Values less than 2 accumulate sequentially.
+func (kt *KustTarget) SetParallelism(n int) {
+	kt.parallelism = n
+}
+
+// SetParallelTransformers sets whether builtin transformers
+// which only change each resource based on that resource
+// are applied to disjoint shards of the resources
+// concurrently, up to the parallelism of the target.
+func (kt *KustTarget) SetParallelTransformers(on bool) {
+	kt.parallelTransformers = on
+}
+
+// SetSopsDecryption sets which files encrypted with SOPS
+// are decrypted as they're read.  It must be called before
+// Load.
+func (kt *KustTarget) SetSopsDecryption(d types.SopsDecryption) {
+	kt.sops = d
+	if d == types.SopsDecryptionAll {
+		kt.ldr = fLdr.NewSopsLoader(kt.ldr)
+	}
+}
+
+// SetKmsDecryption sets whether the files read by
+// secretGenerators and sealedSecretGenerators which are
+// KMS envelopes are decrypted as they're read.
+func (kt *KustTarget) SetKmsDecryption(on bool) {
+	kt.kms = on
+}
+
+// SetStrict sets whether deprecated fields in the
+// kustomization files of this target and its bases
+// fail loading, rather than being warned about.
+func (kt *KustTarget) SetStrict(on bool) {
+	kt.strict = on
+}
+
+// SetPatchesMustMatch sets whether patches of this target
+// and its bases whose targets select no resources fail the
+// build, unless they allow it.
+func (kt *KustTarget) SetPatchesMustMatch(on bool) {
+	kt.patchesMustMatch = on
+}
+
+// SetStrictVars sets whether vars which are never referred
+// to, and references to names which aren't vars, fail the
+// build, rather than being warned about.
+func (kt *KustTarget) SetStrictVars(on bool) {
+	kt.strictVars = on
+}
+
+// SetPhaseTimer sets a function which is told how long each
+// transformer of this target and its bases took to run.
+// It may be called concurrently when bases are accumulated
+// concurrently.
+func (kt *KustTarget) SetPhaseTimer(timer func(phase string, elapsed time.Duration)) {
+	kt.timer = timer
+}
+
+// SetStepRecorder sets a function which is given the
+// resources of this target and its bases after each generator
+// and transformer runs, with the step, e.g.
+// "transform <root> <type>".  It may be called concurrently
+// when bases are accumulated concurrently.
+func (kt *KustTarget) SetStepRecorder(steps func(step string, m resmap.ResMap)) {
+	kt.steps = steps
+}
+
+// SetWarner sets where the warnings of this target and its
+// bases go.  They're logged if it's nil.
+func (kt *KustTarget) SetWarner(w types.Warner) {
+	kt.warner = w
+}
+
+func (kt *KustTarget) warn(w types.Warning) {
+	if kt.warner != nil {
+		kt.warner(w)
+		return
+	}
+	log.Warn(w.Message, w.KeysAndValues()...)
+}
+
+// SetBuildCache sets a cache of the results of accumulating the
+// bases of this target, which may be shared with other targets.
+// It must be called before Load.
+func (kt *KustTarget) SetBuildCache(c *BuildCache) {
+	kt.cache = c
+	kt.ldr = newRecordingLoader(kt.ldr, c.fSys, nil)
+}
+
+// SetMetrics sets where lookups of bases in the build
+// cache are recorded.
+func (kt *KustTarget) SetMetrics(r metrics.Recorder) {
+	kt.metrics = r
+}
+
+// SetClusterReader enables reading the resources with the
+// ClusterResourcePrefix from the live cluster, with c.  Bases
+// aren't cached when it's set, since the cluster may change.
+func (kt *KustTarget) SetClusterReader(c *ClusterReader) {
+	kt.cluster = c
+}
+
+// SetInput sets resources, e.g. the manifests rendered by a
+// Helm chart, to treat as resources of this target, in
+// addition to those its kustomization lists.  The bases of
+// this target don't see them.
+func (kt *KustTarget) SetInput(m resmap.ResMap) {
+	kt.input = m
+}
+
 // Load attempts to load the target's kustomization file.
 func (kt *KustTarget) Load() error {
-	content, err := loadKustFile(kt.ldr)
+	content, name, err := loadKustFile(kt.ldr)
 	if err != nil {
 		return err
 	}
-	content, err = types.FixKustomizationPreUnmarshalling(content)
-	if err != nil {
+	if err = kt.validateKustomization(content, name); err != nil {
 		return err
 	}
+	path := filepath.Join(kt.ldr.Root(), name)
+	fixed, err := types.FixKustomizationPreUnmarshalling(content)
+	if err != nil {
+		return kusterr.InFile(err, path, 0, 0)
+	}
 	var k types.Kustomization
-	err = k.Unmarshal(content)
+	err = k.Unmarshal(fixed)
 	if err != nil {
-		return err
+		line, column := schema.LocateDecodeError(content, err)
+		return kusterr.InFile(err, path, line, column)
 	}
 	k.FixKustomizationPostUnmarshalling()
 	errs := k.EnforceFields()
@@ -82,23 +224,57 @@ func (kt *KustTarget) Kustomization() types.Kustomization {
 	return result
 }
 
-func loadKustFile(ldr ifc.Loader) ([]byte, error) {
+// validateKustomization checks the content of the kustomization
+// file name against its schema.  Unknown fields and type errors
+// fail, and deprecated fields are warned about, or fail in
+// strict mode.  Problems are reported with their locations.
+func (kt *KustTarget) validateKustomization(content []byte, name string) error {
+	problems, deprecations, err := schema.ValidateKustomization(content)
+	if err != nil {
+		// Left to unmarshalling to report.
+		return nil
+	}
+	path := filepath.Join(kt.ldr.Root(), name)
+	var errs []string
+	for _, p := range problems {
+		errs = append(errs, path+":"+p.String())
+	}
+	for _, d := range deprecations {
+		if kt.strict {
+			errs = append(errs, path+":"+d.String())
+		} else {
+			kt.warn(types.Warning{
+				Code:     types.WarningDeprecatedField,
+				Message:  d.Problem.String(),
+				Location: fmt.Sprintf("%s:%d:%d", path, d.Line, d.Column),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf(
+			"invalid kustomization file:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+func loadKustFile(ldr ifc.Loader) ([]byte, string, error) {
 	var content []byte
+	var name string
 	match := 0
 	for _, kf := range konfig.RecognizedKustomizationFileNames() {
 		c, err := ldr.Load(kf)
 		if err == nil {
 			match += 1
-			content = c
+			content, name = c, kf
 		}
 	}
 	switch match {
 	case 0:
-		return nil, NewErrMissingKustomization(ldr.Root())
+		return nil, "", NewErrMissingKustomization(ldr.Root())
 	case 1:
-		return content, nil
+		return content, name, nil
 	default:
-		return nil, fmt.Errorf(
+		return nil, "", fmt.Errorf(
 			"Found multiple kustomization files under: %s\n", ldr.Root())
 	}
 }
@@ -130,7 +306,24 @@ func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 		return nil, err
 	}
 
+	err = kt.checkDanglingReferences(ra)
+	if err != nil {
+		return nil, err
+	}
+
+	err = kt.checkMissingNamespaces(ra)
+	if err != nil {
+		return nil, err
+	}
+
+	err = kt.checkSealedSecrets(ra)
+	if err != nil {
+		return nil, err
+	}
+
 	// With all the back references fixed, it's OK to resolve Vars.
+	ra.SetWarner(kt.warner)
+	ra.SetStrictVars(kt.strictVars)
 	err = ra.ResolveVars()
 	if err != nil {
 		return nil, err
@@ -166,6 +359,12 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating resources")
 	}
+	if kt.input != nil {
+		err = ra.AppendAll(kt.input)
+		if err != nil {
+			return nil, errors.Wrap(err, "accumulating input resources")
+		}
+	}
 	ra, err = kt.accumulateComponents(ra, kt.kustomization.Components)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating components")
@@ -194,6 +393,10 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	if err != nil {
 		return nil, err
 	}
+	err = kt.checkReplacedNamespaces(ra)
+	if err != nil {
+		return nil, err
+	}
 	err = kt.runTransformers(ra)
 	if err != nil {
 		return nil, err
@@ -232,6 +435,10 @@ func (kt *KustTarget) runGenerators(
 		if err != nil {
 			return errors.Wrapf(err, "merging from generator %v", g)
 		}
+		if kt.steps != nil {
+			kt.steps(fmt.Sprintf("generate %s %s",
+				kt.ldr.Root(), strings.TrimPrefix(fmt.Sprintf("%T", g), "*")), ra.ResMap())
+		}
 	}
 	return nil
 }
@@ -258,10 +465,60 @@ func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
 		return err
 	}
 	r = append(r, lts...)
+	if kt.parallelTransformers && kt.parallelism > 1 {
+		r = kt.shardTransformers(r)
+	}
+	for i := range r {
+		name := transformerName(r[i])
+		if kt.timer != nil {
+			r[i] = &timedTransformer{
+				Transformer: r[i],
+				phase:       fmt.Sprintf("transform %s %s", kt.ldr.Root(), name),
+				timer:       kt.timer,
+			}
+		}
+		if kt.steps != nil {
+			r[i] = &recordedTransformer{
+				Transformer: r[i],
+				step:        fmt.Sprintf("transform %s %s", kt.ldr.Root(), name),
+				steps:       kt.steps,
+			}
+		}
+	}
 	t := transform.NewMultiTransformer(r)
 	return ra.Transform(t)
 }
 
+// timedTransformer reports how long a transformer took to run.
+type timedTransformer struct {
+	resmap.Transformer
+	phase string
+	timer func(phase string, elapsed time.Duration)
+}
+
+func (t *timedTransformer) Transform(m resmap.ResMap) error {
+	start := time.Now()
+	err := t.Transformer.Transform(m)
+	t.timer(t.phase, time.Since(start))
+	return err
+}
+
+// recordedTransformer gives the resources a transformer
+// made to a step recorder.
+type recordedTransformer struct {
+	resmap.Transformer
+	step  string
+	steps func(step string, m resmap.ResMap)
+}
+
+func (t *recordedTransformer) Transform(m resmap.ResMap) error {
+	if err := t.Transformer.Transform(m); err != nil {
+		return err
+	}
+	t.steps(t.step, m)
+	return nil
+}
+
 func (kt *KustTarget) configureExternalTransformers(transformers []string) ([]resmap.Transformer, error) {
 	ra := accumulator.MakeEmptyAccumulator()
 	ra, err := kt.accumulateResources(ra, transformers)
@@ -312,25 +569,97 @@ func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) {
 
 // accumulateResources fills the given resourceAccumulator
 // with resources read from the given list of paths.
+// The paths are loaded concurrently, but always merged in
+// the order given so that the result is deterministic.
 func (kt *KustTarget) accumulateResources(
 	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
-	for _, path := range paths {
-		// try loading resource as file then as base (directory or git repository)
-		if errF := kt.accumulateFile(ra, path); errF != nil {
-			ldr, errL := kt.ldr.New(path)
-			if errL != nil {
-				return nil, fmt.Errorf("accumulateFile %q, loader.New %q", errF, errL)
+	results := make([]*accumulator.ResAccumulator, len(paths))
+	errs := make([]error, len(paths))
+	kt.forEach(len(paths), func(i int) {
+		results[i], errs[i] = kt.accumulateResource(paths[i])
+	})
+	for i, path := range paths {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		err := ra.MergeAccumulatorWithDuplicates(
+			results[i], kt.kustomization.DuplicateResources)
+		if err != nil {
+			return nil, errors.Wrapf(err, "merging resources from '%s'", path)
+		}
+	}
+	return ra, nil
+}
+
+// accumulateResource returns a new resourceAccumulator holding
+// the resources read from the given path.
+func (kt *KustTarget) accumulateResource(
+	path string) (*accumulator.ResAccumulator, error) {
+	ra := accumulator.MakeEmptyAccumulator()
+	if strings.HasPrefix(path, ClusterResourcePrefix) {
+		m, err := kt.accumulateClusterResource(path)
+		if err != nil {
+			return nil, err
+		}
+		return ra, ra.AppendAll(m)
+	}
+	// try loading resource as file then as base (directory or git repository)
+	if errF := kt.accumulateFile(ra, path); errF != nil {
+		ldr, errL := kt.ldr.New(path)
+		if errL != nil {
+			if cycle := asCycleError(errL); cycle != nil {
+				return nil, cycle
 			}
-			var errD error
-			ra, errD = kt.accumulateDirectory(ra, ldr, false)
-			if errD != nil {
-				return nil, fmt.Errorf("accumulateFile %q, accumulateDirector: %q", errF, errD)
+			return nil, fmt.Errorf("accumulateFile %q, loader.New %q", errF, errL)
+		}
+		var errD error
+		ra, errD = kt.accumulateDirectory(ra, ldr, false)
+		if errD != nil {
+			if cycle := asCycleError(errD); cycle != nil {
+				return nil, cycle
 			}
+			return nil, fmt.Errorf("accumulateFile %q, accumulateDirector: %q", errF, errD)
 		}
 	}
 	return ra, nil
 }
 
+// asCycleError returns the CycleError causing err, if any.
+// Cycles are reported by their inclusion chains, which name
+// each kustomization included, rather than by the errors of
+// each level of the recursion.
+func asCycleError(err error) *fLdr.CycleError {
+	cycle, _ := errors.Cause(err).(*fLdr.CycleError)
+	return cycle
+}
+
+// forEach calls fn for each index in [0, n), running up to
+// kt.parallelism calls at a time.  Each level of the build
+// has its own limit, so a base waiting on its own bases
+// never blocks its parent.
+func (kt *KustTarget) forEach(n int, fn func(i int)) {
+	if kt.parallelism < 2 || n < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	sem := make(chan struct{}, kt.parallelism)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
 // accumulateResources fills the given resourceAccumulator
 // with resources read from the given list of paths.
 func (kt *KustTarget) accumulateComponents(
@@ -339,22 +668,70 @@ func (kt *KustTarget) accumulateComponents(
 		// Components always refer to directories
 		ldr, errL := kt.ldr.New(path)
 		if errL != nil {
+			if cycle := asCycleError(errL); cycle != nil {
+				return nil, cycle
+			}
 			return nil, fmt.Errorf("loader.New %q", errL)
 		}
 		var errD error
 		ra, errD = kt.accumulateDirectory(ra, ldr, true)
 		if errD != nil {
+			if cycle := asCycleError(errD); cycle != nil {
+				return nil, cycle
+			}
 			return nil, fmt.Errorf("accumulateDirectory: %q", errD)
 		}
 	}
 	return ra, nil
 }
 
+// recordCacheLookup records a lookup of a base in the build cache.
+func (kt *KustTarget) recordCacheLookup(hit bool) {
+	if kt.metrics == nil {
+		return
+	}
+	result := metrics.ResultMiss
+	if hit {
+		result = metrics.ResultHit
+	}
+	kt.metrics.IncCounter(metrics.BuildCacheLookupsTotal, metrics.LabelResult, result)
+}
+
 func (kt *KustTarget) accumulateDirectory(
 	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (*accumulator.ResAccumulator, error) {
 	defer ldr.Cleanup()
+	// Components are accumulated into their parent's
+	// accumulator, so only kustomizations are cached.
+	rl, cacheable := ldr.(*recordingLoader)
+	cacheable = cacheable && kt.cache != nil && !isComponent && kt.cluster == nil
+	if cacheable {
+		cached, files := kt.cache.get(rl.cacheKey(), ldr.Root())
+		kt.recordCacheLookup(cached != nil)
+		if cached != nil {
+			rl.rec.recordEntry(ldr.Root(), files)
+			if err := ra.MergeAccumulator(cached); err != nil {
+				return nil, errors.Wrapf(
+					err, "recursed merging from path '%s'", ldr.Root())
+			}
+			return ra, nil
+		}
+	}
 	subKt := NewKustTarget(
 		ldr, kt.validator, kt.rFactory, kt.tFactory, kt.pLdr)
+	subKt.SetParallelism(kt.parallelism)
+	subKt.SetParallelTransformers(kt.parallelTransformers)
+	subKt.SetPhaseTimer(kt.timer)
+	subKt.SetStepRecorder(kt.steps)
+	// ldr already decrypts if kt's does.
+	subKt.sops = kt.sops
+	subKt.kms = kt.kms
+	subKt.strict = kt.strict
+	subKt.patchesMustMatch = kt.patchesMustMatch
+	subKt.nsCheck = kt.nsCheck
+	subKt.cache = kt.cache
+	subKt.cluster = kt.cluster
+	subKt.metrics = kt.metrics
+	subKt.warner = kt.warner
 	err := subKt.Load()
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -382,6 +759,9 @@ func (kt *KustTarget) accumulateDirectory(
 		return nil, errors.Wrapf(
 			err, "recursed accumulation of path '%s'", ldr.Root())
 	}
+	if cacheable {
+		kt.cache.put(rl.cacheKey(), ldr.Root(), rl.rec, subRa)
+	}
 	err = ra.MergeAccumulator(subRa)
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -396,6 +776,17 @@ func (kt *KustTarget) accumulateFile(
 	if err != nil {
 		return errors.Wrapf(err, "accumulating resources from '%s'", path)
 	}
+	if resources.Size() == 0 {
+		location := path
+		if !filepath.IsAbs(path) && !strings.Contains(path, "://") {
+			location = filepath.Join(kt.ldr.Root(), path)
+		}
+		kt.warn(types.Warning{
+			Code:     types.WarningEmptyFile,
+			Message:  "no resources in file",
+			Location: location,
+		})
+	}
 	err = ra.AppendAll(resources)
 	if err != nil {
 		return errors.Wrapf(err, "merging resources from '%s'", path)
@@ -413,7 +804,16 @@ func (kt *KustTarget) configureBuiltinPlugin(
 				err, "builtin %s marshal", bpt)
 		}
 	}
-	err = p.Config(resmap.NewPluginHelpers(kt.ldr, kt.validator, kt.rFactory), y)
+	ldr := kt.ldr
+	if bpt == builtinhelpers.SecretGenerator || bpt == builtinhelpers.SealedSecretGenerator {
+		if kt.sops != types.SopsDecryptionNone {
+			ldr = fLdr.NewSopsLoader(ldr)
+		}
+		if kt.kms {
+			ldr = fLdr.NewKmsLoader(ldr)
+		}
+	}
+	err = p.Config(resmap.NewPluginHelpers(ldr, kt.validator, kt.rFactory), y)
 	if err != nil {
 		return errors.Wrapf(
 			err, "trouble configuring builtin %s with config: `\n%s`", bpt, string(y))
@@ -32,6 +32,7 @@ func (kt *KustTarget) configureBuiltinGenerators() (
 	for _, bpt := range []builtinhelpers.BuiltinPluginType{
 		builtinhelpers.ConfigMapGenerator,
 		builtinhelpers.SecretGenerator,
+		builtinhelpers.SealedSecretGenerator,
 	} {
 		r, err := generatorConfigurators[bpt](
 			kt, bpt, builtinhelpers.GeneratorFactories[bpt])
@@ -92,6 +93,25 @@ var generatorConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return
 	},
 
+	builtinhelpers.SealedSecretGenerator: func(kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f gFactory) (
+		result []resmap.Generator, err error) {
+		var c struct {
+			types.SealedSecretArgs
+		}
+		for _, args := range kt.kustomization.SealedSecretGenerator {
+			c.SealedSecretArgs = args
+			c.SealedSecretArgs.Options = types.MergeGlobalOptionsIntoLocal(
+				c.SealedSecretArgs.Options, kt.kustomization.GeneratorOptions)
+			p := f()
+			err := kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		return
+	},
+
 	builtinhelpers.ConfigMapGenerator: func(kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f gFactory) (
 		result []resmap.Generator, err error) {
 		var c struct {
@@ -154,7 +174,14 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			if err != nil {
 				return nil, err
 			}
-			result = append(result, p)
+			result = append(result, kt.checkPatchTarget(p, types.Patch{
+				Path: args.Path,
+				Target: &types.Selector{
+					Gvk:       c.Target.Gvk,
+					Namespace: c.Target.Namespace,
+					Name:      c.Target.Name,
+				},
+			}))
 		}
 		return
 	},
@@ -197,7 +224,7 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			if err != nil {
 				return nil, err
 			}
-			result = append(result, p)
+			result = append(result, kt.checkPatchTarget(p, pc))
 		}
 		return
 	},
@@ -242,16 +269,44 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			Prefix     string
 			Suffix     string
 			FieldSpecs []types.FieldSpec
+			Targets    []*types.Selector
 		}
-		c.Prefix = kt.kustomization.NamePrefix
-		c.Suffix = kt.kustomization.NameSuffix
 		c.FieldSpecs = tc.NamePrefix
-		p := f()
-		err = kt.configureBuiltinPlugin(p, c, bpt)
-		if err != nil {
-			return nil, err
+		if len(kt.kustomization.NamePrefixTargets) == 0 &&
+			len(kt.kustomization.NameSuffixTargets) == 0 {
+			c.Prefix = kt.kustomization.NamePrefix
+			c.Suffix = kt.kustomization.NameSuffix
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+			return
+		}
+		// The prefix and suffix may target different resources,
+		// so they need separate plugin instances.
+		if kt.kustomization.NamePrefix != "" {
+			c.Prefix = kt.kustomization.NamePrefix
+			c.Targets = kt.kustomization.NamePrefixTargets
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		if kt.kustomization.NameSuffix != "" {
+			c.Prefix = ""
+			c.Suffix = kt.kustomization.NameSuffix
+			c.Targets = kt.kustomization.NameSuffixTargets
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
 		}
-		result = append(result, p)
 		return
 	},
 	builtinhelpers.ImageTagTransformer: func(
@@ -0,0 +1,99 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// NamespaceCheck configures reporting namespaced resources
+// without a namespace in the build output, and resources
+// whose namespace the namespace field of a kustomization
+// replaces.
+type NamespaceCheck struct {
+	// When true, such resources fail the build,
+	// rather than being warned about.
+	FailOnError bool
+}
+
+// SetNamespaceCheck enables checking the namespaces of the
+// resources of this target and its bases.
+func (kt *KustTarget) SetNamespaceCheck(c *NamespaceCheck) {
+	kt.nsCheck = c
+}
+
+// checkReplacedNamespaces reports the resources of ra whose
+// namespace, as read or generated, differs from the namespace
+// field of kt, which is about to replace it.
+func (kt *KustTarget) checkReplacedNamespaces(ra *accumulator.ResAccumulator) error {
+	ns := kt.kustomization.Namespace
+	if kt.nsCheck == nil || ns == "" {
+		return nil
+	}
+	return kt.reportNamespaceProblems(
+		ra, types.WarningNamespaceReplaced,
+		konfig.AllowNamespaceReplacementAnnotationKey,
+		func(r *resource.Resource) string {
+			if orig := r.GetOriginalNs(); orig != "" && orig != ns {
+				return fmt.Sprintf("namespace %s is replaced by %s", orig, ns)
+			}
+			return ""
+		})
+}
+
+// checkMissingNamespaces reports the namespaced
+// resources of the output without a namespace.
+func (kt *KustTarget) checkMissingNamespaces(ra *accumulator.ResAccumulator) error {
+	if kt.nsCheck == nil {
+		return nil
+	}
+	return kt.reportNamespaceProblems(
+		ra, types.WarningMissingNamespace,
+		konfig.AllowMissingNamespaceAnnotationKey,
+		func(r *resource.Resource) string {
+			if r.GetNamespace() == "" {
+				return "namespaced resource has no namespace"
+			}
+			return ""
+		})
+}
+
+// reportNamespaceProblems warns about, or fails on, the
+// namespaced resources of ra which problem describes,
+// unless they're exempt by annotation.
+func (kt *KustTarget) reportNamespaceProblems(
+	ra *accumulator.ResAccumulator, code types.WarningCode,
+	exemption string, problem func(*resource.Resource) string) error {
+	var problems []string
+	for _, r := range ra.ResMap().Resources() {
+		if !r.GetGvk().IsNamespaceableKind() ||
+			r.GetAnnotations()[exemption] == "true" {
+			continue
+		}
+		p := problem(r)
+		if p == "" {
+			continue
+		}
+		if !kt.nsCheck.FailOnError {
+			kt.warn(types.Warning{
+				Code:     code,
+				Message:  p,
+				Resource: r.CurId().String(),
+				Location: r.GetOrigin(),
+			})
+		}
+		problems = append(problems, r.CurId().String()+": "+p)
+	}
+	if kt.nsCheck.FailOnError && len(problems) > 0 {
+		return fmt.Errorf(
+			"%d namespace problems:\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
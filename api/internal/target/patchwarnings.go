@@ -0,0 +1,113 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// targetedPatch is a patch transformer which warns, before
+// it runs, if its target selects no resources, or selects
+// all the resources of a kind, and more than one.  Targets
+// which name resources, or select them by their labels or
+// annotations, are taken to mean what they select.
+//
+// Patches selecting no resources fail instead if the patch
+// or the target says they must match, and are allowed
+// silently if the patch allows it.
+type targetedPatch struct {
+	resmap.Transformer
+	kt      *KustTarget
+	target  types.Selector
+	path    string
+	noMatch noMatchPolicy
+}
+
+// noMatchPolicy is what's done with a patch
+// whose target selects no resources.
+type noMatchPolicy int
+
+const (
+	noMatchWarn noMatchPolicy = iota
+	noMatchFail
+	noMatchAllow
+)
+
+// checkPatchTarget wraps p, the transformer of the patch pc,
+// in a targetedPatch if pc has a target.
+func (kt *KustTarget) checkPatchTarget(p resmap.Transformer, pc types.Patch) resmap.Transformer {
+	if pc.Target == nil {
+		return p
+	}
+	t := &targetedPatch{Transformer: p, kt: kt, target: *pc.Target, path: pc.Path}
+	switch {
+	case pc.AllowNoMatch:
+		t.noMatch = noMatchAllow
+	case pc.FailOnNoMatch || kt.patchesMustMatch:
+		t.noMatch = noMatchFail
+	}
+	return t
+}
+
+func (t *targetedPatch) Transform(m resmap.ResMap) error {
+	if selected, err := m.Select(t.target); err == nil {
+		w := types.Warning{Location: t.kt.ldr.Root()}
+		if t.path != "" {
+			w.Location = filepath.Join(t.kt.ldr.Root(), t.path)
+		}
+		switch {
+		case len(selected) == 0 && t.noMatch == noMatchFail:
+			return fmt.Errorf(
+				"the target of a patch in %s, %s, selects no resources; "+
+					"set allowNoMatch on the patch if that's expected",
+				w.Location, t.describeTarget())
+		case len(selected) == 0 && t.noMatch == noMatchWarn:
+			w.Code = types.WarningPatchMatchedNothing
+			w.Message = fmt.Sprintf(
+				"the target of a patch, %s, selects no resources", t.describeTarget())
+			t.kt.warn(w)
+		case len(selected) > 1 && t.target.Name == "" &&
+			t.target.LabelSelector == "" && t.target.AnnotationSelector == "":
+			w.Code = types.WarningPatchMatchedBroadly
+			w.Message = fmt.Sprintf(
+				"the target of a patch, %s, selects all %d resources of its kind",
+				t.describeTarget(), len(selected))
+			if t.target.Kind == "" {
+				w.Message = fmt.Sprintf(
+					"the target of a patch, %s, selects all %d resources",
+					t.describeTarget(), len(selected))
+			}
+			t.kt.warn(w)
+		}
+	}
+	return t.Transformer.Transform(m)
+}
+
+// describeTarget returns the fields of the target
+// which are set, e.g. "kind=Deployment namespace=prod".
+func (t *targetedPatch) describeTarget() string {
+	var fields []string
+	for _, f := range []struct{ name, value string }{
+		{"group", t.target.Group},
+		{"version", t.target.Version},
+		{"kind", t.target.Kind},
+		{"namespace", t.target.Namespace},
+		{"name", t.target.Name},
+		{"labelSelector", t.target.LabelSelector},
+		{"annotationSelector", t.target.AnnotationSelector},
+	} {
+		if f.value != "" {
+			fields = append(fields, f.name+"="+f.value)
+		}
+	}
+	if len(fields) == 0 {
+		return "empty"
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// The annotations of SealedSecrets sealed with a wider scope
+// than the default, strict one, as kubeseal writes them.
+const (
+	sealedSecretNamespaceWide = "sealedsecrets.bitnami.com/namespace-wide"
+	sealedSecretClusterWide   = "sealedsecrets.bitnami.com/cluster-wide"
+)
+
+// checkSealedSecrets fails on the SealedSecrets of ra whose
+// name or namespace changed, e.g. with a namePrefix or the
+// namespace field, although their scope seals them to it, as
+// the controller couldn't unseal them.
+func (kt *KustTarget) checkSealedSecrets(ra *accumulator.ResAccumulator) error {
+	var problems []string
+	for _, r := range ra.ResMap().Resources() {
+		if p := sealedSecretProblem(r); p != "" {
+			problems = append(problems, r.CurId().String()+": "+p)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf(
+			"%d sealed secrets can't be unsealed:\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// sealedSecretProblem describes why r, if it's a SealedSecret,
+// can't be unsealed, or returns "".
+func sealedSecretProblem(r *resource.Resource) string {
+	gvk := r.GetGvk()
+	if gvk.Group != "bitnami.com" || gvk.Kind != "SealedSecret" {
+		return ""
+	}
+	annotations := r.GetAnnotations()
+	if annotations[sealedSecretClusterWide] == "true" {
+		return ""
+	}
+	// SealedSecrets read without a namespace may have been
+	// sealed to the one they're given.
+	if orig := r.GetOriginalNs(); orig != "" && orig != r.GetNamespace() {
+		return fmt.Sprintf(
+			"sealed to namespace %s, but it's changed to %s; "+
+				"seal it cluster-wide to change it", orig, r.GetNamespace())
+	}
+	if annotations[sealedSecretNamespaceWide] == "true" {
+		return ""
+	}
+	if orig := r.GetOriginalName(); orig != r.GetName() {
+		return fmt.Sprintf(
+			"sealed to name %s, but it's changed to %s; "+
+				"seal it namespace-wide or cluster-wide to change it", orig, r.GetName())
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/builtins"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// isResourceLocal returns true if t changes each resource
+// using only that resource's own fields, and doesn't look at
+// the ResMap as a whole, so that applying it to disjoint
+// subsets of a ResMap gives the same result as applying it
+// to the whole ResMap.
+//
+// Patches aren't, since they look their targets up by id,
+// nor are the namespace transformer (it checks the whole
+// ResMap for id conflicts), the prefix/suffix transformer
+// (it selects its targets from the whole ResMap) or the
+// replica count transformer (it fails if nothing matches).
+func isResourceLocal(t resmap.Transformer) bool {
+	switch t.(type) {
+	case *builtins.LabelTransformerPlugin,
+		*builtins.AnnotationsTransformerPlugin,
+		*builtins.ImageTagTransformerPlugin:
+		return true
+	}
+	return false
+}
+
+// shardTransformers replaces each run of consecutive resource
+// local transformers in ts with a single shardedTransformer.
+// Since each resource is only changed by its own shard, and
+// its shard applies the run in order, the result is the same
+// as applying ts one after another.
+func (kt *KustTarget) shardTransformers(
+	ts []resmap.Transformer) (result []resmap.Transformer) {
+	var run []resmap.Transformer
+	flush := func() {
+		switch len(run) {
+		case 0:
+		case 1:
+			result = append(result, run[0])
+		default:
+			result = append(result, &shardedTransformer{
+				transformers: run, forEach: kt.forEach, shards: kt.parallelism})
+		}
+		run = nil
+	}
+	for _, t := range ts {
+		if isResourceLocal(t) {
+			run = append(run, t)
+			continue
+		}
+		flush()
+		result = append(result, t)
+	}
+	flush()
+	return result
+}
+
+// shardedTransformer splits a ResMap into contiguous shards,
+// and applies its transformers, in order, to each shard
+// concurrently.
+type shardedTransformer struct {
+	transformers []resmap.Transformer
+	forEach      func(n int, fn func(i int))
+	shards       int
+}
+
+func (t *shardedTransformer) Transform(m resmap.ResMap) error {
+	rs := m.Resources()
+	n := t.shards
+	if n > len(rs) {
+		n = len(rs)
+	}
+	if n < 2 {
+		return t.transformAll(m)
+	}
+	errs := make([]error, n)
+	t.forEach(n, func(i int) {
+		errs[i] = t.transformShard(rs[i*len(rs)/n : (i+1)*len(rs)/n])
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *shardedTransformer) transformShard(rs []*resource.Resource) error {
+	m := resmap.New()
+	for _, r := range rs {
+		if err := m.Append(r); err != nil {
+			return err
+		}
+	}
+	return t.transformAll(m)
+}
+
+func (t *shardedTransformer) transformAll(m resmap.ResMap) error {
+	for _, tr := range t.transformers {
+		if err := tr.Transform(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// name returns the names of the transformers, joined by "+".
+func (t *shardedTransformer) name() string {
+	names := make([]string, len(t.transformers))
+	for i, tr := range t.transformers {
+		names[i] = transformerName(tr)
+	}
+	return strings.Join(names, "+")
+}
+
+// transformerName returns the name of t's type, used
+// to tell the phase timer which transformer ran.
+func transformerName(t resmap.Transformer) string {
+	switch x := t.(type) {
+	case *shardedTransformer:
+		return x.name()
+	case *targetedPatch:
+		return transformerName(x.Transformer)
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", t), "*")
+}
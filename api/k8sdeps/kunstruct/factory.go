@@ -5,6 +5,7 @@ package kunstruct
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -20,13 +21,44 @@ import (
 // KunstructuredFactoryImpl hides construction using apimachinery types.
 type KunstructuredFactoryImpl struct {
 	hasher *kustHash
+	// lazy defers decoding resources read from bytes
+	// until more than their metadata is needed.
+	lazy bool
 }
 
 var _ ifc.KunstructuredFactory = &KunstructuredFactoryImpl{}
 
+// FactoryOptions holds the options of a KunstructuredFactoryImpl.
+type FactoryOptions struct {
+	// LazyParsing makes SliceFromBytes defer decoding each
+	// resource until something other than its apiVersion, kind,
+	// name, namespace, labels or annotations is read, or the
+	// resource is changed.
+	LazyParsing bool
+
+	// LegacyHashing makes the Hasher convert ConfigMaps and
+	// Secrets to their typed forms to hash them, as it used to.
+	// The hashes are the same either way, only slower.
+	LegacyHashing bool
+}
+
 // NewKunstructuredFactoryImpl returns a factory.
 func NewKunstructuredFactoryImpl() ifc.KunstructuredFactory {
-	return &KunstructuredFactoryImpl{hasher: NewKustHash()}
+	return NewKunstructuredFactoryWithOptions(FactoryOptions{})
+}
+
+// NewLazyKunstructuredFactoryImpl returns a factory
+// with the LazyParsing option set.
+func NewLazyKunstructuredFactoryImpl() ifc.KunstructuredFactory {
+	return NewKunstructuredFactoryWithOptions(FactoryOptions{LazyParsing: true})
+}
+
+// NewKunstructuredFactoryWithOptions returns a factory with the given options.
+func NewKunstructuredFactoryWithOptions(o FactoryOptions) ifc.KunstructuredFactory {
+	return &KunstructuredFactoryImpl{
+		hasher: &kustHash{legacy: o.LegacyHashing},
+		lazy:   o.LazyParsing,
+	}
 }
 
 // Hasher returns a kunstructured hasher
@@ -38,6 +70,9 @@ func (kf *KunstructuredFactoryImpl) Hasher() ifc.KunstructuredHasher {
 // SliceFromBytes returns a slice of Kunstructured.
 func (kf *KunstructuredFactoryImpl) SliceFromBytes(
 	in []byte) ([]ifc.Kunstructured, error) {
+	if kf.lazy {
+		return kf.lazySliceFromBytes(in)
+	}
 	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(in), 1024)
 	var result []ifc.Kunstructured
 	var err error
@@ -63,6 +98,52 @@ func (kf *KunstructuredFactoryImpl) SliceFromBytes(
 	return result, nil
 }
 
+// lazySliceFromBytes is like SliceFromBytes, but only decodes
+// the objects it can't vouch for without doing so.
+func (kf *KunstructuredFactoryImpl) lazySliceFromBytes(
+	in []byte) ([]ifc.Kunstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(in), 1024)
+	var result []ifc.Kunstructured
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if l, ok := newLazyUnstructAdapter(raw); ok {
+			if !l.isIgnoredByKustomize() {
+				result = append(result, l)
+			}
+			continue
+		}
+		var out unstructured.Unstructured
+		err = out.UnmarshalJSON(raw)
+		if err != nil {
+			if isEmptyYamlError(err) {
+				continue
+			}
+			return nil, err
+		}
+		if len(out.Object) == 0 {
+			continue
+		}
+		err = kf.validate(out)
+		if err != nil {
+			return nil, err
+		}
+		if !kf.skipResource(out) {
+			result = append(result, &UnstructAdapter{Unstructured: out})
+		}
+	}
+}
+
+func isListKind(kind string) bool {
+	return strings.HasSuffix(kind, "List")
+}
+
 func isEmptyYamlError(err error) bool {
 	return strings.Contains(err.Error(), "is missing in 'null'")
 }
@@ -99,7 +180,7 @@ func (kf *KunstructuredFactoryImpl) validate(u unstructured.Unstructured) error
 	kind := u.GetKind()
 	if kind == "" {
 		return fmt.Errorf("missing kind in object %v", u)
-	} else if strings.HasSuffix(kind, "List") {
+	} else if isListKind(kind) {
 		return nil
 	}
 	if u.GetName() == "" {
@@ -128,6 +209,12 @@ func (kf *KunstructuredFactoryImpl) skipResource(u unstructured.Unstructured) bo
 	return found
 }
 
+// isIgnoredByKustomize is skipResource for a lazyUnstructAdapter.
+func (l *lazyUnstructAdapter) isIgnoredByKustomize() bool {
+	_, found := l.meta.Metadata.Annotations[ignoredByKustomizeResourceAnnotation]
+	return found
+}
+
 func checkListItemNil(in interface{}) (bool, string) {
 	switch v := in.(type) {
 	case map[string]interface{}:
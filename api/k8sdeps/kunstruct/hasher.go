@@ -4,6 +4,7 @@
 package kunstruct
 
 import (
+	"encoding/base64"
 	"encoding/json"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,7 +14,12 @@ import (
 )
 
 // kustHash computes a hash of an unstructured object.
-type kustHash struct{}
+type kustHash struct {
+	// legacy converts ConfigMaps and Secrets to their
+	// typed forms to hash them, rather than reading the
+	// hashed fields directly.
+	legacy bool
+}
 
 // NewKustHash returns a kustHash object
 func NewKustHash() *kustHash {
@@ -22,6 +28,20 @@ func NewKustHash() *kustHash {
 
 // Hash returns a hash of the given object
 func (h *kustHash) Hash(m ifc.Kunstructured) (string, error) {
+	if !h.legacy {
+		switch m.GetKind() {
+		case "ConfigMap":
+			if cm, ok := readConfigMap(m); ok {
+				return configMapHash(cm)
+			}
+		case "Secret":
+			if sec, ok := readSecret(m); ok {
+				return secretHash(sec)
+			}
+		}
+		// Anything which can't be read directly takes the
+		// legacy path, which reports the same errors as before.
+	}
 	u := unstructured.Unstructured{
 		Object: m.Map(),
 	}
@@ -44,6 +64,78 @@ func (h *kustHash) Hash(m ifc.Kunstructured) (string, error) {
 	}
 }
 
+// readConfigMap reads the hashed fields of a ConfigMap
+// without converting all of it, returning false if they
+// aren't of the expected types.
+func readConfigMap(m ifc.Kunstructured) (*corev1.ConfigMap, bool) {
+	cm := &corev1.ConfigMap{}
+	cm.Name = m.GetName()
+	var ok bool
+	if cm.Data, ok = readStringMap(m, "data"); !ok {
+		return nil, false
+	}
+	binaryData, ok := readStringMap(m, "binaryData")
+	if !ok {
+		return nil, false
+	}
+	if cm.BinaryData, ok = decodeBytesMap(binaryData); !ok {
+		return nil, false
+	}
+	return cm, true
+}
+
+// readSecret reads the hashed fields of a Secret
+// without converting all of it, returning false if they
+// aren't of the expected types.
+func readSecret(m ifc.Kunstructured) (*corev1.Secret, bool) {
+	sec := &corev1.Secret{}
+	sec.Name = m.GetName()
+	t, err := m.GetString("type")
+	if _, isNoField := err.(NoFieldError); err != nil && !isNoField {
+		return nil, false
+	}
+	sec.Type = corev1.SecretType(t)
+	data, ok := readStringMap(m, "data")
+	if !ok {
+		return nil, false
+	}
+	if sec.Data, ok = decodeBytesMap(data); !ok {
+		return nil, false
+	}
+	if sec.StringData, ok = readStringMap(m, "stringData"); !ok {
+		return nil, false
+	}
+	return sec, true
+}
+
+// readStringMap returns the map of strings at path, or nil
+// if there's no such field, or false if it isn't one.
+func readStringMap(m ifc.Kunstructured, path string) (map[string]string, bool) {
+	result, err := m.GetStringMap(path)
+	if err != nil {
+		_, isNoField := err.(NoFieldError)
+		return nil, isNoField
+	}
+	return result, true
+}
+
+// decodeBytesMap decodes base64 encoded values the way
+// encoding/json decodes them into []byte.
+func decodeBytesMap(m map[string]string) (map[string][]byte, bool) {
+	if m == nil {
+		return nil, true
+	}
+	result := make(map[string][]byte, len(m))
+	for k, v := range m {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, false
+		}
+		result[k] = b
+	}
+	return result, true
+}
+
 // configMapHash returns a hash of the ConfigMap.
 // The Data, Kind, and Name are taken into account.
 func configMapHash(cm *corev1.ConfigMap) (string, error) {
@@ -4,6 +4,7 @@
 package kunstruct
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -222,3 +223,119 @@ func SkipRest(t *testing.T, desc string, err error, contains string) bool {
 	}
 	return false
 }
+
+func TestHashMatchesLegacyHash(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"configmap": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "cm", "labels": map[string]interface{}{"a": "b"}},
+			"data":       map[string]interface{}{"two": "2", "one": ""},
+		},
+		"configmap without data": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "cm"},
+		},
+		"configmap with empty data": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "cm"},
+			"data":       map[string]interface{}{},
+		},
+		"configmap with binary data": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "cm"},
+			"data":       map[string]interface{}{"one": ""},
+			"binaryData": map[string]interface{}{"two": "AQID\n"},
+		},
+		"configmap with null data": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "cm"},
+			"data":       nil,
+		},
+		"configmap with bad data": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "cm"},
+			"data":       map[string]interface{}{"one": int64(1)},
+		},
+		"secret": {
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "sec"},
+			"type":       "Opaque",
+			"data":       map[string]interface{}{"one": "MQ=="},
+			"stringData": map[string]interface{}{"two": "2"},
+		},
+		"secret without type": {
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "sec"},
+			"data":       map[string]interface{}{"one": "MQ=="},
+		},
+		"secret with bad data": {
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "sec"},
+			"data":       map[string]interface{}{"one": "not base64!"},
+		},
+	}
+	for desc, obj := range cases {
+		u := &UnstructAdapter{Unstructured: unstructured.Unstructured{Object: obj}}
+		expected, expectedErr := (&kustHash{legacy: true}).Hash(u)
+		actual, err := NewKustHash().Hash(u)
+		if (err == nil) != (expectedErr == nil) ||
+			(err != nil && err.Error() != expectedErr.Error()) {
+			t.Errorf("case %q, expected error %v but got %v", desc, expectedErr, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("case %q, expected hash %q but got %q", desc, expected, actual)
+		}
+	}
+}
+
+// BenchmarkHash compares hashing ConfigMaps and Secrets directly
+// with converting them to their typed forms first.
+func BenchmarkHash(b *testing.B) {
+	data := map[string]interface{}{}
+	for i := 0; i < 20; i++ {
+		data[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	objs := map[string]map[string]interface{}{
+		"configmap": {
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "cm", "labels": map[string]interface{}{"a": "b"}},
+			"data":       data,
+		},
+		"secret": {
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "sec"},
+			"type":       "Opaque",
+			"data":       map[string]interface{}{"one": "MQ==", "two": "Mg=="},
+		},
+	}
+	for kind, obj := range objs {
+		u := &UnstructAdapter{Unstructured: unstructured.Unstructured{Object: obj}}
+		for _, legacy := range []bool{true, false} {
+			name := kind + "/direct"
+			if legacy {
+				name = kind + "/legacy"
+			}
+			h := &kustHash{legacy: legacy}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := h.Hash(u); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
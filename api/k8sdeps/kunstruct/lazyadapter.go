@@ -0,0 +1,314 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kunstruct
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+var _ ifc.Kunstructured = &lazyUnstructAdapter{}
+
+// lazyMeta holds the fields needed to identify and
+// select a resource without decoding all of it.
+type lazyMeta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+// lazyUnstructAdapter is a Kunstructured that keeps the JSON
+// it was read from, and only decodes it into an UnstructAdapter
+// the first time something other than its identifying metadata
+// is read, or anything is changed.  Resources which pass through
+// a build untouched are never fully decoded.
+//
+// Like UnstructAdapter, it is not safe for concurrent use.
+type lazyUnstructAdapter struct {
+	raw  []byte
+	meta *lazyMeta
+	u    *UnstructAdapter
+	// json caches what MarshalJSON returns for raw.
+	json []byte
+}
+
+// newLazyUnstructAdapter returns a lazyUnstructAdapter for the
+// given JSON object, or false if the object must be decoded
+// eagerly to be validated.
+func newLazyUnstructAdapter(raw []byte) (*lazyUnstructAdapter, bool) {
+	meta := &lazyMeta{}
+	if err := json.Unmarshal(raw, meta); err != nil {
+		return nil, false
+	}
+	if meta.Kind == "" || meta.Metadata.Name == "" ||
+		isListKind(meta.Kind) || hasNullListItem(raw) {
+		return nil, false
+	}
+	return &lazyUnstructAdapter{raw: raw, meta: meta}, true
+}
+
+// decoded returns the fully decoded object, decoding it if needed.
+func (l *lazyUnstructAdapter) decoded() *UnstructAdapter {
+	if l.u == nil {
+		l.u = &UnstructAdapter{}
+		// raw was checked to be a valid object when l was made.
+		_ = l.u.UnmarshalJSON(l.raw)
+		l.raw = nil
+		l.meta = nil
+		l.json = nil
+	}
+	return l.u
+}
+
+// isDecoded returns true if the object has been decoded,
+// in which case the metadata may no longer be used.
+func (l *lazyUnstructAdapter) isDecoded() bool {
+	return l.u != nil
+}
+
+func (l *lazyUnstructAdapter) Copy() ifc.Kunstructured {
+	if l.isDecoded() {
+		return l.u.Copy()
+	}
+	// raw and json are never modified, so they can be shared.
+	meta := *l.meta
+	meta.Metadata.Labels = copyStringMap(l.meta.Metadata.Labels)
+	meta.Metadata.Annotations = copyStringMap(l.meta.Metadata.Annotations)
+	return &lazyUnstructAdapter{raw: l.raw, meta: &meta, json: l.json}
+}
+
+func (l *lazyUnstructAdapter) GetAnnotations() map[string]string {
+	if l.isDecoded() {
+		return l.u.GetAnnotations()
+	}
+	return copyStringMap(l.meta.Metadata.Annotations)
+}
+
+func (l *lazyUnstructAdapter) GetLabels() map[string]string {
+	if l.isDecoded() {
+		return l.u.GetLabels()
+	}
+	return copyStringMap(l.meta.Metadata.Labels)
+}
+
+func (l *lazyUnstructAdapter) GetKind() string {
+	if l.isDecoded() {
+		return l.u.GetKind()
+	}
+	return l.meta.Kind
+}
+
+func (l *lazyUnstructAdapter) GetName() string {
+	if l.isDecoded() {
+		return l.u.GetName()
+	}
+	return l.meta.Metadata.Name
+}
+
+func (l *lazyUnstructAdapter) GetGvk() resid.Gvk {
+	if l.isDecoded() {
+		return l.u.GetGvk()
+	}
+	gv, err := schema.ParseGroupVersion(l.meta.APIVersion)
+	if err != nil {
+		return resid.Gvk{}
+	}
+	return resid.Gvk{Group: gv.Group, Version: gv.Version, Kind: l.meta.Kind}
+}
+
+func (l *lazyUnstructAdapter) GetString(path string) (string, error) {
+	if !l.isDecoded() {
+		var s string
+		switch path {
+		case "apiVersion":
+			s = l.meta.APIVersion
+		case "kind":
+			s = l.meta.Kind
+		case "metadata.name":
+			s = l.meta.Metadata.Name
+		case "metadata.namespace":
+			s = l.meta.Metadata.Namespace
+		default:
+			return l.decoded().GetString(path)
+		}
+		if s == "" {
+			return "", NoFieldError{Field: path}
+		}
+		return s, nil
+	}
+	return l.u.GetString(path)
+}
+
+func (l *lazyUnstructAdapter) MatchesLabelSelector(selector string) (bool, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels.Set(l.GetLabels())), nil
+}
+
+func (l *lazyUnstructAdapter) MatchesAnnotationSelector(selector string) (bool, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels.Set(l.GetAnnotations())), nil
+}
+
+func (l *lazyUnstructAdapter) MarshalJSON() ([]byte, error) {
+	if l.isDecoded() {
+		return l.u.MarshalJSON()
+	}
+	if l.json == nil {
+		// Round trip through the decoded form once, so that the
+		// output is formatted the same as for an UnstructAdapter.
+		var u unstructured.Unstructured
+		if err := u.UnmarshalJSON(l.raw); err != nil {
+			return nil, err
+		}
+		b, err := u.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		l.json = b
+	}
+	// Callers may modify what they're given.
+	return append([]byte(nil), l.json...), nil
+}
+
+// The remaining methods read or change more than
+// the metadata, so they decode the object.
+
+func (l *lazyUnstructAdapter) GetBool(path string) (bool, error) {
+	return l.decoded().GetBool(path)
+}
+
+func (l *lazyUnstructAdapter) GetFieldValue(path string) (interface{}, error) {
+	return l.decoded().GetFieldValue(path)
+}
+
+func (l *lazyUnstructAdapter) GetFloat64(path string) (float64, error) {
+	return l.decoded().GetFloat64(path)
+}
+
+func (l *lazyUnstructAdapter) GetInt64(path string) (int64, error) {
+	return l.decoded().GetInt64(path)
+}
+
+func (l *lazyUnstructAdapter) GetMap(path string) (map[string]interface{}, error) {
+	return l.decoded().GetMap(path)
+}
+
+func (l *lazyUnstructAdapter) GetSlice(path string) ([]interface{}, error) {
+	return l.decoded().GetSlice(path)
+}
+
+func (l *lazyUnstructAdapter) GetStringMap(path string) (map[string]string, error) {
+	return l.decoded().GetStringMap(path)
+}
+
+func (l *lazyUnstructAdapter) GetStringSlice(path string) ([]string, error) {
+	return l.decoded().GetStringSlice(path)
+}
+
+func (l *lazyUnstructAdapter) Map() map[string]interface{} {
+	return l.decoded().Map()
+}
+
+func (l *lazyUnstructAdapter) Patch(patch ifc.Kunstructured) error {
+	return l.decoded().Patch(patch)
+}
+
+func (l *lazyUnstructAdapter) SetAnnotations(m map[string]string) {
+	l.decoded().SetAnnotations(m)
+}
+
+func (l *lazyUnstructAdapter) SetGvk(g resid.Gvk) {
+	l.decoded().SetGvk(g)
+}
+
+func (l *lazyUnstructAdapter) SetLabels(m map[string]string) {
+	l.decoded().SetLabels(m)
+}
+
+func (l *lazyUnstructAdapter) SetMap(m map[string]interface{}) {
+	l.decoded().SetMap(m)
+}
+
+func (l *lazyUnstructAdapter) SetName(name string) {
+	l.decoded().SetName(name)
+}
+
+func (l *lazyUnstructAdapter) SetNamespace(namespace string) {
+	l.decoded().SetNamespace(namespace)
+}
+
+func (l *lazyUnstructAdapter) UnmarshalJSON(data []byte) error {
+	return l.decoded().UnmarshalJSON(data)
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+// hasNullListItem returns true if the JSON holds a list with
+// a null item, without decoding it.  Such objects are rejected
+// by validate, which needs them decoded to report the path.
+func hasNullListItem(raw []byte) bool {
+	var stack []byte
+	inString, escaped := false, false
+	// prev is the previous non-space byte outside of strings.
+	var prev byte
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				prev = c
+			}
+			continue
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '"':
+			inString = true
+		case '[', '{':
+			stack = append(stack, c)
+		case ']', '}':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case 'n':
+			if len(stack) > 0 && stack[len(stack)-1] == '[' &&
+				(prev == '[' || prev == ',') {
+				return true
+			}
+		}
+		prev = c
+	}
+	return false
+}
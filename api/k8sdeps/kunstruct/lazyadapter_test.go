@@ -0,0 +1,188 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kunstruct
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+)
+
+const lazyInput = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+  namespace: ns
+  labels:
+    app: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  annotations:
+    config.kubernetes.io/local-config: "true"
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: svc
+`
+
+func TestLazySliceFromBytesMatchesEager(t *testing.T) {
+	eager, err := NewKunstructuredFactoryImpl().SliceFromBytes([]byte(lazyInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lazy, err := NewLazyKunstructuredFactoryImpl().SliceFromBytes([]byte(lazyInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lazy) != len(eager) {
+		t.Fatalf("expected %d objects, got %d", len(eager), len(lazy))
+	}
+	if _, ok := lazy[0].(*lazyUnstructAdapter); !ok {
+		t.Fatalf("expected deployment to be lazy, got %T", lazy[0])
+	}
+	if _, ok := lazy[1].(*UnstructAdapter); !ok {
+		t.Fatalf("expected List to be decoded eagerly, got %T", lazy[1])
+	}
+	for i := range eager {
+		if lazy[i].GetGvk() != eager[i].GetGvk() ||
+			lazy[i].GetName() != eager[i].GetName() {
+			t.Fatalf("expected %v, got %v", eager[i].GetGvk(), lazy[i].GetGvk())
+		}
+		if !reflect.DeepEqual(lazy[i].Map(), eager[i].Map()) {
+			t.Fatalf("expected %v, got %v", eager[i].Map(), lazy[i].Map())
+		}
+	}
+}
+
+func TestLazyUnstructAdapterDefersDecoding(t *testing.T) {
+	objs, err := NewLazyKunstructuredFactoryImpl().SliceFromBytes([]byte(lazyInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := objs[0].(*lazyUnstructAdapter)
+
+	ns, err := l.GetString("metadata.namespace")
+	if err != nil || ns != "ns" {
+		t.Fatalf("unexpected namespace %q, %v", ns, err)
+	}
+	if m, _ := l.MatchesLabelSelector("app=web"); !m {
+		t.Fatalf("expected label selector to match")
+	}
+	labels := l.GetLabels()
+	labels["app"] = "changed"
+	if l.GetLabels()["app"] != "web" {
+		t.Fatalf("GetLabels must return a copy")
+	}
+	b, err := l.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[0] = 'x'
+	again, err := l.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := l.Copy().(*lazyUnstructAdapter).decoded().MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(expected) {
+		t.Fatalf("expected MarshalJSON to return %s, got %s", expected, again)
+	}
+	c := l.Copy()
+	if l.isDecoded() {
+		t.Fatalf("expected metadata reads not to decode the object")
+	}
+
+	replicas, err := l.GetInt64("spec.replicas")
+	if err != nil || replicas != 3 {
+		t.Fatalf("unexpected replicas %d, %v", replicas, err)
+	}
+	if !l.isDecoded() {
+		t.Fatalf("expected spec read to decode the object")
+	}
+	l.SetName("renamed")
+	if l.GetName() != "renamed" || c.GetName() != "dep" {
+		t.Fatalf("expected copy to be unaffected by changes, got %s and %s",
+			l.GetName(), c.GetName())
+	}
+}
+
+// BenchmarkMarshalJSON compares marshalling objects which haven't
+// been decoded with marshalling decoded ones.
+func BenchmarkMarshalJSON(b *testing.B) {
+	objs, err := NewLazyKunstructuredFactoryImpl().SliceFromBytes([]byte(lazyInput))
+	if err != nil {
+		b.Fatal(err)
+	}
+	l := objs[0].(*lazyUnstructAdapter)
+	for name, k := range map[string]ifc.Kunstructured{
+		"lazy":    l,
+		"decoded": l.Copy().(*lazyUnstructAdapter).decoded(),
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := k.MarshalJSON(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestLazySliceFromBytesNullListItem(t *testing.T) {
+	input := []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+spec:
+  items:
+  - a
+  -
+`)
+	_, eagerErr := NewKunstructuredFactoryImpl().SliceFromBytes(input)
+	_, lazyErr := NewLazyKunstructuredFactoryImpl().SliceFromBytes(input)
+	if eagerErr == nil || lazyErr == nil {
+		t.Fatalf("expected errors, got %v and %v", eagerErr, lazyErr)
+	}
+	if eagerErr.Error() != lazyErr.Error() {
+		t.Fatalf("expected %q, got %q", eagerErr, lazyErr)
+	}
+}
+
+func TestHasNullListItem(t *testing.T) {
+	tests := map[string]bool{
+		`{"a":[1,null]}`:             true,
+		`{"a":[null]}`:               true,
+		`{"a":[ null ]}`:             true,
+		`{"a":null}`:                 false,
+		`{"a":["null",",null"]}`:     false,
+		`{"a":[{"b":null}]}`:         false,
+		`{"a":["x\"",null]}`:         true,
+		`{"a":[[1],{"b":[2,null]}]}`: true,
+	}
+	for in, expected := range tests {
+		if actual := hasNullListItem([]byte(in)); actual != expected {
+			t.Errorf("%s: expected %v, got %v", in, expected, actual)
+		}
+	}
+}
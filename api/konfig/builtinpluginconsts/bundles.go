@@ -0,0 +1,406 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package builtinpluginconsts
+
+import (
+	"sort"
+)
+
+// FieldSpecBundlePrefix prefixes the names of the bundles of
+// fieldSpecs which kustomizations can list as configurations,
+// e.g. builtin:istio/v1, rather than maintaining their own.
+//
+// Each bundle covers the custom resources of a project.  Its
+// name ends in the version of the bundle, which changes when
+// the bundle changes in ways that could change the output of
+// existing kustomizations.
+const FieldSpecBundlePrefix = "builtin:"
+
+var fieldSpecBundles = map[string]string{
+	"istio/v1":               istioFieldSpecsV1,
+	"argo-rollouts/v1":       argoRolloutsFieldSpecsV1,
+	"cert-manager/v1":        certManagerFieldSpecsV1,
+	"prometheus-operator/v1": prometheusOperatorFieldSpecsV1,
+}
+
+// GetFieldSpecBundle returns the fieldSpecs of the bundle
+// with the given name, without the FieldSpecBundlePrefix.
+func GetFieldSpecBundle(name string) (string, bool) {
+	b, found := fieldSpecBundles[name]
+	return b, found
+}
+
+// FieldSpecBundleNames returns the names of the bundles, sorted.
+func FieldSpecBundleNames() []string {
+	var names []string
+	for n := range fieldSpecBundles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+const (
+	istioFieldSpecsV1 = `
+nameReference:
+- kind: Service
+  version: v1
+  fieldSpecs:
+  - path: spec/http/route/destination/host
+    group: networking.istio.io
+    kind: VirtualService
+  - path: spec/http/mirror/host
+    group: networking.istio.io
+    kind: VirtualService
+  - path: spec/tcp/route/destination/host
+    group: networking.istio.io
+    kind: VirtualService
+  - path: spec/tls/route/destination/host
+    group: networking.istio.io
+    kind: VirtualService
+  - path: spec/host
+    group: networking.istio.io
+    kind: DestinationRule
+
+- kind: Gateway
+  group: networking.istio.io
+  fieldSpecs:
+  - path: spec/gateways
+    group: networking.istio.io
+    kind: VirtualService
+  - path: spec/http/match/gateways
+    group: networking.istio.io
+    kind: VirtualService
+  - path: spec/tcp/match/gateways
+    group: networking.istio.io
+    kind: VirtualService
+  - path: spec/tls/match/gateways
+    group: networking.istio.io
+    kind: VirtualService
+
+- kind: Secret
+  version: v1
+  fieldSpecs:
+  - path: spec/servers/tls/credentialName
+    group: networking.istio.io
+    kind: Gateway
+  - path: spec/trafficPolicy/tls/credentialName
+    group: networking.istio.io
+    kind: DestinationRule
+
+varReference:
+- path: spec/hosts
+  group: networking.istio.io
+  kind: VirtualService
+- path: spec/servers/hosts
+  group: networking.istio.io
+  kind: Gateway
+- path: spec/host
+  group: networking.istio.io
+  kind: DestinationRule
+- path: spec/hosts
+  group: networking.istio.io
+  kind: ServiceEntry
+`
+
+	argoRolloutsFieldSpecsV1 = `
+nameReference:
+- kind: Service
+  version: v1
+  fieldSpecs:
+  - path: spec/strategy/canary/canaryService
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/strategy/canary/stableService
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/strategy/blueGreen/activeService
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/strategy/blueGreen/previewService
+    group: argoproj.io
+    kind: Rollout
+
+- kind: Ingress
+  fieldSpecs:
+  - path: spec/strategy/canary/trafficRouting/nginx/stableIngress
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/strategy/canary/trafficRouting/alb/ingress
+    group: argoproj.io
+    kind: Rollout
+
+- kind: VirtualService
+  group: networking.istio.io
+  fieldSpecs:
+  - path: spec/strategy/canary/trafficRouting/istio/virtualService/name
+    group: argoproj.io
+    kind: Rollout
+
+- kind: AnalysisTemplate
+  group: argoproj.io
+  fieldSpecs:
+  - path: spec/strategy/canary/analysis/templates/templateName
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/strategy/canary/steps/analysis/templates/templateName
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/strategy/blueGreen/prePromotionAnalysis/templates/templateName
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/strategy/blueGreen/postPromotionAnalysis/templates/templateName
+    group: argoproj.io
+    kind: Rollout
+
+- kind: ConfigMap
+  version: v1
+  fieldSpecs:
+  - path: spec/template/spec/volumes/configMap/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/containers/env/valueFrom/configMapKeyRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/initContainers/env/valueFrom/configMapKeyRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/containers/envFrom/configMapRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/initContainers/envFrom/configMapRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/volumes/projected/sources/configMap/name
+    group: argoproj.io
+    kind: Rollout
+
+- kind: Secret
+  version: v1
+  fieldSpecs:
+  - path: spec/template/spec/volumes/secret/secretName
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/containers/env/valueFrom/secretKeyRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/initContainers/env/valueFrom/secretKeyRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/containers/envFrom/secretRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/initContainers/envFrom/secretRef/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/imagePullSecrets/name
+    group: argoproj.io
+    kind: Rollout
+  - path: spec/template/spec/volumes/projected/sources/secret/name
+    group: argoproj.io
+    kind: Rollout
+
+- kind: ServiceAccount
+  version: v1
+  fieldSpecs:
+  - path: spec/template/spec/serviceAccountName
+    group: argoproj.io
+    kind: Rollout
+
+- kind: PersistentVolumeClaim
+  version: v1
+  fieldSpecs:
+  - path: spec/template/spec/volumes/persistentVolumeClaim/claimName
+    group: argoproj.io
+    kind: Rollout
+
+replicas:
+- path: spec/replicas
+  create: true
+  group: argoproj.io
+  kind: Rollout
+
+varReference:
+- path: spec/template/spec/containers/args
+  group: argoproj.io
+  kind: Rollout
+- path: spec/template/spec/containers/command
+  group: argoproj.io
+  kind: Rollout
+- path: spec/template/spec/containers/env/value
+  group: argoproj.io
+  kind: Rollout
+- path: spec/template/spec/containers/volumeMounts/mountPath
+  group: argoproj.io
+  kind: Rollout
+- path: spec/template/spec/initContainers/args
+  group: argoproj.io
+  kind: Rollout
+- path: spec/template/spec/initContainers/command
+  group: argoproj.io
+  kind: Rollout
+- path: spec/template/spec/initContainers/env/value
+  group: argoproj.io
+  kind: Rollout
+`
+
+	certManagerFieldSpecsV1 = `
+nameReference:
+- kind: Secret
+  version: v1
+  fieldSpecs:
+  - path: spec/secretName
+    group: cert-manager.io
+    kind: Certificate
+  - path: spec/ca/secretName
+    group: cert-manager.io
+    kind: Issuer
+  - path: spec/ca/secretName
+    group: cert-manager.io
+    kind: ClusterIssuer
+  - path: spec/acme/privateKeySecretRef/name
+    group: cert-manager.io
+    kind: Issuer
+  - path: spec/acme/privateKeySecretRef/name
+    group: cert-manager.io
+    kind: ClusterIssuer
+  - path: spec/vault/auth/tokenSecretRef/name
+    group: cert-manager.io
+    kind: Issuer
+  - path: spec/vault/auth/tokenSecretRef/name
+    group: cert-manager.io
+    kind: ClusterIssuer
+
+- kind: Issuer
+  group: cert-manager.io
+  fieldSpecs:
+  - path: spec/issuerRef/name
+    group: cert-manager.io
+    kind: Certificate
+  - path: spec/issuerRef/name
+    group: cert-manager.io
+    kind: CertificateRequest
+
+- kind: ClusterIssuer
+  group: cert-manager.io
+  fieldSpecs:
+  - path: spec/issuerRef/name
+    group: cert-manager.io
+    kind: Certificate
+  - path: spec/issuerRef/name
+    group: cert-manager.io
+    kind: CertificateRequest
+
+varReference:
+- path: spec/commonName
+  group: cert-manager.io
+  kind: Certificate
+- path: spec/dnsNames
+  group: cert-manager.io
+  kind: Certificate
+`
+
+	prometheusOperatorFieldSpecsV1 = `
+nameReference:
+- kind: ServiceAccount
+  version: v1
+  fieldSpecs:
+  - path: spec/serviceAccountName
+    group: monitoring.coreos.com
+    kind: Prometheus
+  - path: spec/serviceAccountName
+    group: monitoring.coreos.com
+    kind: Alertmanager
+
+- kind: Secret
+  version: v1
+  fieldSpecs:
+  - path: spec/secrets
+    group: monitoring.coreos.com
+    kind: Prometheus
+  - path: spec/secrets
+    group: monitoring.coreos.com
+    kind: Alertmanager
+  - path: spec/configSecret
+    group: monitoring.coreos.com
+    kind: Alertmanager
+  - path: spec/imagePullSecrets/name
+    group: monitoring.coreos.com
+    kind: Prometheus
+  - path: spec/imagePullSecrets/name
+    group: monitoring.coreos.com
+    kind: Alertmanager
+  - path: spec/endpoints/basicAuth/username/name
+    group: monitoring.coreos.com
+    kind: ServiceMonitor
+  - path: spec/endpoints/basicAuth/password/name
+    group: monitoring.coreos.com
+    kind: ServiceMonitor
+  - path: spec/endpoints/bearerTokenSecret/name
+    group: monitoring.coreos.com
+    kind: ServiceMonitor
+
+- kind: ConfigMap
+  version: v1
+  fieldSpecs:
+  - path: spec/configMaps
+    group: monitoring.coreos.com
+    kind: Prometheus
+  - path: spec/configMaps
+    group: monitoring.coreos.com
+    kind: Alertmanager
+
+- kind: Service
+  version: v1
+  fieldSpecs:
+  - path: spec/alerting/alertmanagers/name
+    group: monitoring.coreos.com
+    kind: Prometheus
+
+images:
+- path: spec/image
+  group: monitoring.coreos.com
+  kind: Prometheus
+- path: spec/image
+  group: monitoring.coreos.com
+  kind: Alertmanager
+- path: spec/image
+  group: monitoring.coreos.com
+  kind: ThanosRuler
+- path: spec/containers[]/image
+  group: monitoring.coreos.com
+  kind: Prometheus
+- path: spec/containers[]/image
+  group: monitoring.coreos.com
+  kind: Alertmanager
+
+replicas:
+- path: spec/replicas
+  create: true
+  group: monitoring.coreos.com
+  kind: Prometheus
+- path: spec/replicas
+  create: true
+  group: monitoring.coreos.com
+  kind: Alertmanager
+- path: spec/replicas
+  create: true
+  group: monitoring.coreos.com
+  kind: ThanosRuler
+
+varReference:
+- path: spec/externalUrl
+  group: monitoring.coreos.com
+  kind: Prometheus
+- path: spec/externalUrl
+  group: monitoring.coreos.com
+  kind: Alertmanager
+- path: spec/namespaceSelector/matchNames
+  group: monitoring.coreos.com
+  kind: ServiceMonitor
+- path: spec/namespaceSelector/matchNames
+  group: monitoring.coreos.com
+  kind: PodMonitor
+`
+)
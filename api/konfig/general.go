@@ -39,4 +39,23 @@ const (
 
 	// Label key that indicates the resources are validated by a validator
 	ValidatedByLabelKey = "validated-by"
+
+	// Default annotation keys recording the git repository, commit and
+	// path of the kustomization a resource was built from, and the
+	// version of kustomize that built it.
+	BuildRepoAnnotationKey    = "kustomize.config.k8s.io/build-repo"
+	BuildCommitAnnotationKey  = "kustomize.config.k8s.io/build-commit"
+	BuildPathAnnotationKey    = "kustomize.config.k8s.io/build-path"
+	BuildVersionAnnotationKey = "kustomize.config.k8s.io/build-version"
+
+	// Annotation key hinting at the field manager to
+	// server-side apply resources with.
+	FieldManagerAnnotationKey = "kustomize.config.k8s.io/field-manager"
+
+	// Annotation keys which, set to "true", exempt a resource
+	// from the reports of namespaced resources without a
+	// namespace, and of namespaces which a kustomization
+	// replaces.
+	AllowMissingNamespaceAnnotationKey     = "kustomize.config.k8s.io/allow-missing-namespace"
+	AllowNamespaceReplacementAnnotationKey = "kustomize.config.k8s.io/allow-namespace-replacement"
 )
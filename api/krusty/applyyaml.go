@@ -0,0 +1,110 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"io"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// ApplyYamlOptions configure WriteApplyYaml.
+type ApplyYamlOptions struct {
+	// AsList writes the resources as the items of a v1
+	// List, rather than as a stream of documents.
+	AsList bool
+
+	// FieldManager, if not empty, is recorded in the
+	// FieldManagerAnnotationKey annotation of each resource,
+	// as a hint to appliers of the field manager to apply with.
+	FieldManager string
+}
+
+// bookkeepingFields are the metadata fields the
+// cluster sets, which apply configurations leave out.
+var bookkeepingFields = []string{
+	"managedFields", "resourceVersion", "uid", "selfLink",
+	"creationTimestamp", "generation", "deletionTimestamp",
+	"deletionGracePeriodSeconds",
+}
+
+// WriteApplyYaml writes the resources of m to w as apply
+// configurations for server-side apply: without status, the
+// bookkeeping fields of metadata, or the annotation of
+// client-side apply, and with their fields in a stable order,
+// apiVersion, kind and metadata first.
+func WriteApplyYaml(w io.Writer, m resmap.ResMap, o ApplyYamlOptions) error {
+	var nodes []*kyaml.RNode
+	for _, r := range m.Resources() {
+		b, err := yaml.Marshal(r.ReadOnlyMap())
+		if err != nil {
+			return err
+		}
+		n, err := kyaml.Parse(string(b))
+		if err != nil {
+			return err
+		}
+		if err = toApplyConfiguration(n, o.FieldManager); err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+	}
+	nodes, err := filters.FormatFilter{}.Filter(nodes)
+	if err != nil {
+		return err
+	}
+	if !o.AsList {
+		return kio.ByteWriter{Writer: w}.Write(nodes)
+	}
+	list, err := kyaml.Parse("apiVersion: v1\nkind: List\nitems: []\n")
+	if err != nil {
+		return err
+	}
+	items := list.Field("items").Value.YNode()
+	items.Style = 0
+	for _, n := range nodes {
+		items.Content = append(items.Content, n.YNode())
+	}
+	return kio.ByteWriter{Writer: w}.Write([]*kyaml.RNode{list})
+}
+
+// toApplyConfiguration removes the fields of n which
+// aren't applied, and records the field manager, if any.
+func toApplyConfiguration(n *kyaml.RNode, fieldManager string) error {
+	if _, err := n.Pipe(kyaml.Clear("status")); err != nil {
+		return err
+	}
+	meta := n.Field("metadata")
+	if meta == nil {
+		return nil
+	}
+	for _, f := range bookkeepingFields {
+		if _, err := meta.Value.Pipe(kyaml.Clear(f)); err != nil {
+			return err
+		}
+	}
+	if _, err := n.Pipe(kyaml.ClearAnnotation(
+		"kubectl.kubernetes.io/last-applied-configuration")); err != nil {
+		return err
+	}
+	if fieldManager != "" {
+		if err := n.PipeE(
+			kyaml.LookupCreate(kyaml.MappingNode, "metadata", "annotations"),
+			kyaml.SetField(konfig.FieldManagerAnnotationKey,
+				kyaml.NewScalarRNode(fieldManager))); err != nil {
+			return err
+		}
+	}
+	if a := meta.Value.Field("annotations"); a != nil && len(a.Value.YNode().Content) == 0 {
+		if _, err := meta.Value.Pipe(kyaml.Clear("annotations")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
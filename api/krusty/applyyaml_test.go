@@ -0,0 +1,110 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeApplyYamlKustomization(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - a=b
+  options:
+    disableNameSuffixHash: true
+    annotations:
+      team: payments
+`)
+	th.WriteF("/app/deployment.yaml", `
+kind: Deployment
+apiVersion: apps/v1
+spec:
+  replicas: 2
+status:
+  readyReplicas: 2
+metadata:
+  name: web
+  creationTimestamp: null
+  resourceVersion: "42"
+  managedFields:
+  - manager: kubectl
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: "{}"
+`)
+}
+
+func TestWriteApplyYaml(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeApplyYamlKustomization(th)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	var out bytes.Buffer
+	if err := krusty.WriteApplyYaml(&out, m, krusty.ApplyYamlOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  annotations:
+    team: payments
+data:
+  a: b
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteApplyYamlAsList(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeApplyYamlKustomization(th)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	var out bytes.Buffer
+	err := krusty.WriteApplyYaml(&out, m, krusty.ApplyYamlOptions{
+		AsList:       true,
+		FieldManager: "deployer",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: web
+    annotations:
+      kustomize.config.k8s.io/field-manager: deployer
+  spec:
+    replicas: 2
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: settings
+    annotations:
+      kustomize.config.k8s.io/field-manager: deployer
+      team: payments
+  data:
+    a: b
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
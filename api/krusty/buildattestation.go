@@ -0,0 +1,229 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/provenance"
+)
+
+// The types of the statements a BuildAttestation makes.
+const (
+	InTotoStatementType = "https://in-toto.io/Statement/v0.1"
+	SLSAProvenanceType  = "https://slsa.dev/provenance/v0.2"
+	KustomizeBuildType  = "https://sigs.k8s.io/kustomize/build@v1"
+	KustomizeBuilderID  = "https://sigs.k8s.io/kustomize"
+)
+
+// BuildAttestation records the inputs of a build, so that an
+// in-toto statement of its SLSA provenance can be made once
+// its output is written, to attest to how that output was
+// made.  The inputs, or materials, are
+//
+//   - each file read, by its path relative to the
+//     kustomization, or its URL if it's remote,
+//   - each remote base, by its URL and the commit cloned,
+//   - each function image, and each exec, starlark, rego
+//     and Go plugin, by its path.
+//
+// The materials which are files have their sha256 digests.
+// The kustomize version is part of the builder ID.
+//
+// A BuildAttestation records a Run at a time, and is
+// reset by each Run.  Bases reused from a BuildCache
+// aren't read, so a BuildCache isn't used for Runs
+// recorded by a BuildAttestation.
+type BuildAttestation struct {
+	mu        sync.Mutex
+	root      string
+	source    *AttestationMaterial
+	path      string
+	materials map[string]AttestationMaterial
+}
+
+// AttestationMaterial is an input of a build, or,
+// as a subject, an output.
+type AttestationMaterial struct {
+	URI    string            `json:"uri,omitempty"`
+	Name   string            `json:"name,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// NewBuildAttestation returns an empty BuildAttestation.
+func NewBuildAttestation() *BuildAttestation {
+	return &BuildAttestation{materials: map[string]AttestationMaterial{}}
+}
+
+// start resets a, and returns a loader recording
+// the files and remote bases read through ldr.
+func (a *BuildAttestation) start(ldr ifc.Loader) ifc.Loader {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.root = ldr.Root()
+	a.materials = map[string]AttestationMaterial{}
+	a.source, a.path = nil, ""
+	if repo, commit, path := gitProvenance(a.root); commit != "" {
+		a.source = &AttestationMaterial{
+			URI:    "git+" + repo,
+			Digest: map[string]string{"sha1": commit},
+		}
+		a.path = path
+	}
+	return &attestingLoader{Loader: ldr, a: a}
+}
+
+func (a *BuildAttestation) add(uri, algorithm, digest string) {
+	m := AttestationMaterial{URI: uri}
+	if digest != "" {
+		m.Digest = map[string]string{algorithm: digest}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.materials[uri] = m
+}
+
+// recordPlugin is a PluginRecorder adding plugins as materials.
+func (a *BuildAttestation) recordPlugin(kind, location, digest string) {
+	switch kind {
+	case "container":
+		if i := strings.Index(location, "@sha256:"); i >= 0 {
+			digest = location[i+len("@sha256:"):]
+			location = location[:i]
+		}
+		a.add("docker://"+location, "sha256", digest)
+	default:
+		if abs, err := filepath.Abs(location); err == nil && !isURL(location) {
+			location = "file://" + filepath.ToSlash(abs)
+		}
+		a.add(location, "sha256", digest)
+	}
+}
+
+// Statement returns the in-toto statement, in json, that the
+// subjects, e.g. the output of the last Run, were built from
+// the materials recorded.  The subjects should have sha256
+// digests.
+func (a *BuildAttestation) Statement(subjects ...AttestationMaterial) ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var materials []AttestationMaterial
+	for _, uri := range sortedMaterialURIs(a.materials) {
+		materials = append(materials, a.materials[uri])
+	}
+	version := provenance.GetProvenance().Version
+	invocation := map[string]interface{}{
+		"environment": map[string]string{"kustomizeVersion": version},
+	}
+	if a.source != nil {
+		invocation["configSource"] = map[string]interface{}{
+			"uri":        a.source.URI,
+			"digest":     a.source.Digest,
+			"entryPoint": a.path,
+		}
+	}
+	b, err := json.MarshalIndent(map[string]interface{}{
+		"_type":         InTotoStatementType,
+		"subject":       subjects,
+		"predicateType": SLSAProvenanceType,
+		"predicate": map[string]interface{}{
+			"builder":    map[string]string{"id": KustomizeBuilderID + "@" + version},
+			"buildType":  KustomizeBuildType,
+			"invocation": invocation,
+			"materials":  materials,
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func sortedMaterialURIs(m map[string]AttestationMaterial) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// attestingLoader is a Loader adding the files and remote
+// bases it, and the loaders made from it, read to a
+// BuildAttestation.
+type attestingLoader struct {
+	ifc.Loader
+	a *BuildAttestation
+}
+
+// New implements ifc.Loader.
+func (l *attestingLoader) New(newRoot string) (ifc.Loader, error) {
+	ldr, err := l.Loader.New(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	if key := cacheKey(ldr); key != ldr.Root() {
+		commit, _ := exec.Command(
+			"git", "-C", ldr.Root(), "rev-parse", "HEAD").Output()
+		l.a.add("git+"+key, "sha1", strings.TrimSpace(string(commit)))
+	}
+	return &attestingLoader{Loader: ldr, a: l.a}, nil
+}
+
+// Load implements ifc.Loader.
+func (l *attestingLoader) Load(location string) ([]byte, error) {
+	b, err := l.Loader.Load(location)
+	if err != nil {
+		return nil, err
+	}
+	digest := fmt.Sprintf("%x", sha256.Sum256(b))
+	switch key := cacheKey(l.Loader); {
+	case isURL(location):
+		l.a.add(location, "sha256", digest)
+	case filepath.IsAbs(location):
+		l.a.add(l.relative(location), "sha256", digest)
+	case key != l.Root():
+		// In a remote base.
+		l.a.add(key+"/"+filepath.ToSlash(location), "sha256", digest)
+	default:
+		l.a.add(l.relative(filepath.Join(l.Root(), location)), "sha256", digest)
+	}
+	return b, nil
+}
+
+// relative returns path relative to the root of the build,
+// if it can, and absolute otherwise.
+func (l *attestingLoader) relative(path string) string {
+	if rel, err := filepath.Rel(l.a.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// CacheKey returns the CacheKey of the wrapped loader, if
+// it has one, and its root otherwise.
+func (l *attestingLoader) CacheKey() string {
+	return cacheKey(l.Loader)
+}
+
+func cacheKey(ldr ifc.Loader) string {
+	if k, ok := ldr.(interface{ CacheKey() string }); ok {
+		return k.CacheKey()
+	}
+	return ldr.Root()
+}
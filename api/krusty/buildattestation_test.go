@@ -0,0 +1,91 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestBuildAttestation(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- service.yaml
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteK("/app/prod", `
+resources:
+- ../base
+namePrefix: prod-
+`)
+	opts := th.MakeDefaultOptions()
+	opts.BuildAttestation = krusty.NewBuildAttestation()
+	// The cache is bypassed, so the base is read again.
+	opts.BuildCache = krusty.NewBuildCache(th.GetFSys())
+	for i := 0; i < 2; i++ {
+		th.Run("/app/prod", opts)
+	}
+	statement, err := opts.BuildAttestation.Statement(krusty.AttestationMaterial{
+		Name:   "stdout",
+		Digest: map[string]string{"sha256": "0123"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "predicate": {
+    "buildType": "https://sigs.k8s.io/kustomize/build@v1",
+    "builder": {
+      "id": "https://sigs.k8s.io/kustomize@v444.333.222"
+    },
+    "invocation": {
+      "environment": {
+        "kustomizeVersion": "v444.333.222"
+      }
+    },
+    "materials": [
+      {
+        "uri": "../base/kustomization.yaml",
+        "digest": {
+          "sha256": "c2d747afe2f5dd9ae0abd69c2b146b892f3d22cc25ecf14fc202efefa62c6f43"
+        }
+      },
+      {
+        "uri": "../base/service.yaml",
+        "digest": {
+          "sha256": "60f9141c0ae528cc45e5392a1306a686de665ae3915eda6096df0522e880475e"
+        }
+      },
+      {
+        "uri": "kustomization.yaml",
+        "digest": {
+          "sha256": "c3c66f32a3ace57817c6059a9f69236da79a47e85c73ba662c431ea692a39083"
+        }
+      }
+    ]
+  },
+  "predicateType": "https://slsa.dev/provenance/v0.2",
+  "subject": [
+    {
+      "name": "stdout",
+      "digest": {
+        "sha256": "0123"
+      }
+    }
+  ]
+}
+`
+	if string(statement) != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, statement)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/target"
+)
+
+// BuildCache holds the results of building the bases of
+// kustomizations, so that Runs of many kustomizations sharing
+// the same bases, e.g. every overlay in a repository, only
+// build each base once.
+//
+// A base's result is reused as long as every file read to
+// build it is unchanged.  A BuildCache may be shared by any
+// number of Kustomizers, concurrently, as long as they have
+// the same Options (other than BuildCache) and file system.
+type BuildCache struct {
+	c *target.BuildCache
+}
+
+// NewBuildCache returns an empty BuildCache for
+// Kustomizers using the given file system.
+func NewBuildCache(fSys filesys.FileSystem) *BuildCache {
+	return &BuildCache{c: target.NewBuildCache(fSys)}
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestBuildCache(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+namePrefix: base-
+resources:
+- service.yaml
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+  - port: 80
+`)
+	th.WriteK("/app/dev", `
+namespace: dev
+resources:
+- ../base
+`)
+	th.WriteK("/app/prod", `
+namespace: prod
+resources:
+- ../base
+`)
+
+	var mu sync.Mutex
+	baseBuilds := 0
+	opts := th.MakeDefaultOptions()
+	opts.BuildCache = krusty.NewBuildCache(th.GetFSys())
+	opts.PhaseTimer = func(phase string, elapsed time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasPrefix(phase, "transform /app/base ") &&
+			strings.HasSuffix(phase, "PrefixSuffixTransformerPlugin") {
+			baseBuilds++
+		}
+	}
+
+	th.AssertActualEqualsExpected(th.Run("/app/dev", opts), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: base-svc
+  namespace: dev
+spec:
+  ports:
+  - port: 80
+`)
+	th.AssertActualEqualsExpected(th.Run("/app/prod", opts), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: base-svc
+  namespace: prod
+spec:
+  ports:
+  - port: 80
+`)
+	if baseBuilds != 1 {
+		t.Fatalf("expected base to be built once, got %d", baseBuilds)
+	}
+
+	// Building dev again doesn't see changes made to the cached
+	// result by the prod build.
+	th.AssertActualEqualsExpected(th.Run("/app/dev", opts), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: base-svc
+  namespace: dev
+spec:
+  ports:
+  - port: 80
+`)
+
+	// Changing a file of the base invalidates its result.
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+  - port: 8080
+`)
+	th.AssertActualEqualsExpected(th.Run("/app/prod", opts), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: base-svc
+  namespace: prod
+spec:
+  ports:
+  - port: 8080
+`)
+	if baseBuilds != 2 {
+		t.Fatalf("expected base to be rebuilt after change, got %d", baseBuilds)
+	}
+}
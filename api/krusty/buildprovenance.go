@@ -0,0 +1,117 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/builtins"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/provenance"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// BuildProvenance holds the annotation keys under which the
+// resources of a build are stamped with where they were built
+// from, so that tools deploying them can trace them back.
+// Annotations with an empty key are left out.
+type BuildProvenance struct {
+	// RepoKey annotates the URL of the git repository
+	// holding the kustomization, without any credentials.
+	RepoKey string
+
+	// CommitKey annotates the commit checked out in
+	// that repository.
+	CommitKey string
+
+	// PathKey annotates the path of the kustomization,
+	// relative to the top of the repository.
+	PathKey string
+
+	// VersionKey annotates the version of kustomize.
+	VersionKey string
+}
+
+// MakeDefaultBuildProvenance returns a BuildProvenance
+// using the default annotation keys.
+func MakeDefaultBuildProvenance() *BuildProvenance {
+	return &BuildProvenance{
+		RepoKey:    konfig.BuildRepoAnnotationKey,
+		CommitKey:  konfig.BuildCommitAnnotationKey,
+		PathKey:    konfig.BuildPathAnnotationKey,
+		VersionKey: konfig.BuildVersionAnnotationKey,
+	}
+}
+
+// transformer returns the transformer adding the provenance
+// annotations to the resources built from the kustomization
+// in root, or nil if there are none to add.  The repository,
+// commit and path are left out if root isn't in a git
+// repository.
+func (p *BuildProvenance) transformer(root string) resmap.Transformer {
+	values := map[string]string{}
+	add := func(key, value string) {
+		if key != "" && value != "" {
+			values[key] = value
+		}
+	}
+	repo, commit, path := gitProvenance(root)
+	add(p.RepoKey, repo)
+	add(p.CommitKey, commit)
+	add(p.PathKey, path)
+	add(p.VersionKey, provenance.GetProvenance().Version)
+	if len(values) == 0 {
+		return nil
+	}
+	return &builtins.AnnotationsTransformerPlugin{
+		Annotations: values,
+		FieldSpecs: []types.FieldSpec{{
+			Path:               "metadata/annotations",
+			CreateIfNotPresent: true,
+		}},
+	}
+}
+
+// gitProvenance returns the URL of the origin remote of the
+// git repository holding dir, the commit checked out in it,
+// and the path of dir in it, or empty strings if dir isn't
+// in a git repository.
+func gitProvenance(dir string) (repo, commit, path string) {
+	git := func(args ...string) string {
+		out, err := exec.Command(
+			"git", append([]string{"-C", dir}, args...)...).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	top := git("rev-parse", "--show-toplevel")
+	if top == "" {
+		return "", "", ""
+	}
+	commit = git("rev-parse", "HEAD")
+	repo = withoutCredentials(git("config", "--get", "remote.origin.url"))
+	if deLinked, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = deLinked
+	}
+	if rel, err := filepath.Rel(top, dir); err == nil {
+		path = filepath.ToSlash(rel)
+	}
+	return repo, commit, path
+}
+
+// withoutCredentials removes any user and password
+// from a repository URL.
+func withoutCredentials(repo string) string {
+	u, err := url.Parse(repo)
+	if err != nil || u.User == nil || u.Scheme == "" {
+		return repo
+	}
+	u.User = nil
+	return u.String()
+}
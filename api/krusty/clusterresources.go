@@ -0,0 +1,20 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+// ClusterResources configures reading the resources of
+// kustomizations named like cluster://apps/v1/Deployment/ns/web
+// from the live cluster, with the kubectl program, so that
+// overlays can patch objects as they are, e.g. to correct
+// drift or to migrate them.  Fields set by the cluster, like
+// status and managedFields, are removed from the objects.
+type ClusterResources struct {
+	// Kubeconfig is the kubeconfig file to use.
+	// kubectl's default if empty.
+	Kubeconfig string
+
+	// Context is the kubeconfig context to use.
+	// The current context if empty.
+	Context string
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const liveDeployment = `{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {
+    "annotations": {
+      "deployment.kubernetes.io/revision": "3",
+      "team": "payments"
+    },
+    "creationTimestamp": "2020-06-01T00:00:00Z",
+    "generation": 3,
+    "managedFields": [{"manager": "kubectl", "operation": "Update"}],
+    "name": "web",
+    "namespace": "prod",
+    "resourceVersion": "1234",
+    "uid": "5a0e0d52-4c4a-4d0e-9c1b-2b6f5e1f8c3a"
+  },
+  "spec": {
+    "replicas": 2,
+    "template": {
+      "spec": {
+        "containers": [{"name": "web", "image": "web:1.0"}]
+      }
+    }
+  },
+  "status": {"replicas": 2, "readyReplicas": 2}
+}
+`
+
+// fakeKubectl puts a kubectl program on the PATH which
+// only knows the live Deployment above.
+func fakeKubectl(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake kubectl is a shell script")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-kubectl-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(bin) })
+	if err = ioutil.WriteFile(
+		filepath.Join(bin, "deployment.json"), []byte(liveDeployment), 0600); err != nil {
+		t.Fatal(err)
+	}
+	script := `#!/bin/sh
+if [ "$*" != "get Deployment.v1.apps web --output json --namespace prod --context live" ]; then
+  echo "Error from server (NotFound): $*" >&2
+  exit 1
+fi
+cat "` + filepath.Join(bin, "deployment.json") + `"
+`
+	if err = ioutil.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+}
+
+func TestClusterResources(t *testing.T) {
+	fakeKubectl(t)
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- cluster://apps/v1/Deployment/prod/web
+images:
+- name: web
+  newTag: "1.1"
+`)
+	opts := th.MakeDefaultOptions()
+	opts.ClusterResources = &krusty.ClusterResources{Context: "live"}
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    team: payments
+  name: web
+  namespace: prod
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - image: web:1.1
+        name: web
+`)
+}
+
+func TestClusterResourcesNotFound(t *testing.T) {
+	fakeKubectl(t)
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- cluster://apps/v1/Deployment/prod/api
+`)
+	opts := th.MakeDefaultOptions()
+	opts.ClusterResources = &krusty.ClusterResources{Context: "live"}
+	err := th.RunWithErr("/app", opts)
+	if err == nil || !strings.Contains(err.Error(), "Error from server (NotFound)") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClusterResourcesDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- cluster://apps/v1/Deployment/prod/web
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"reading resources from the cluster isn't enabled: cluster://apps/v1/Deployment/prod/web") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestConfigurationBundles(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: x-
+resources:
+- resources.yaml
+replicas:
+- name: app
+  count: 3
+configurations:
+- builtin:istio/v1
+- builtin:argo-rollouts/v1
+- builtin:cert-manager/v1
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: tls
+---
+apiVersion: networking.istio.io/v1beta1
+kind: Gateway
+metadata:
+  name: gw
+spec:
+  servers:
+  - tls:
+      credentialName: tls
+---
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: app
+spec:
+  gateways:
+  - gw
+  http:
+  - route:
+    - destination:
+        host: app
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: app
+spec:
+  strategy:
+    canary:
+      stableService: app
+---
+apiVersion: cert-manager.io/v1
+kind: Issuer
+metadata:
+  name: issuer
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: cert
+spec:
+  secretName: tls
+  issuerRef:
+    name: issuer
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: x-app
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: x-tls
+---
+apiVersion: networking.istio.io/v1beta1
+kind: Gateway
+metadata:
+  name: x-gw
+spec:
+  servers:
+  - tls:
+      credentialName: x-tls
+---
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: x-app
+spec:
+  gateways:
+  - x-gw
+  http:
+  - route:
+    - destination:
+        host: x-app
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: x-app
+spec:
+  replicas: 3
+  strategy:
+    canary:
+      stableService: x-app
+---
+apiVersion: cert-manager.io/v1
+kind: Issuer
+metadata:
+  name: x-issuer
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: x-cert
+spec:
+  issuerRef:
+    name: x-issuer
+  secretName: x-tls
+`)
+}
+
+func TestConfigurationBundlesUnknown(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+configurations:
+- builtin:istio/v0
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"unknown configuration bundle builtin:istio/v0; known bundles: "+
+			"[argo-rollouts/v1 cert-manager/v1 istio/v1 prometheus-operator/v1]") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestCycleThroughBasesAndComponents(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/prod", `
+resources:
+- ../base
+`)
+	th.WriteK("/app/base", `
+resources:
+- service.yaml
+components:
+- ../monitoring
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteC("/app/monitoring", `
+resources:
+- ../prod
+`)
+	err := th.RunWithErr("/app/prod", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "accumulating resources: cycle detected: " +
+		"/app/prod -> /app/base -> /app/monitoring -> /app/prod"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+// DanglingReferences configures checking, once name references
+// are fixed, that the references by name to ConfigMaps, Secrets
+// and Services, e.g. in configMapKeyRefs, volumes, envFrom and
+// Ingress backends, name objects of the build output, so that
+// a typo in the name of a generator isn't shipped.  Dangling
+// references are logged as warnings, and fail the build if
+// FailOnError is true.
+type DanglingReferences struct {
+	// ExternalNames are the names of objects made outside of
+	// the build, which may be referred to, either as
+	// kind/name, e.g. Secret/registry-credentials, or as a
+	// name of any kind.
+	ExternalNames []string
+
+	// When true, the build fails if any dangling
+	// reference is found.
+	FailOnError bool
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeDanglingReferences(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+namePrefix: prod-
+resources:
+- deployment.yaml
+- ingress.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - LEVEL=debug
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: registry-credentials
+      containers:
+      - name: web
+        image: nginx
+        env:
+        - name: LEVEL
+          valueFrom:
+            configMapKeyRef:
+              name: settings
+              key: LEVEL
+        envFrom:
+        - configMapRef:
+            name: setings
+`)
+	th.WriteF("/app/ingress.yaml", `
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  backend:
+    serviceName: web
+    servicePort: 80
+`)
+}
+
+func TestDanglingReferencesFail(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDanglingReferences(th)
+	opts := th.MakeDefaultOptions()
+	opts.DanglingReferences = &krusty.DanglingReferences{FailOnError: true}
+	err := th.RunWithErr("/app", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{
+		"3 dangling references:",
+		"spec/template/spec/containers/envFrom/configMapRef/name: ConfigMap setings not found",
+		"spec/template/spec/imagePullSecrets/name: Secret registry-credentials not found",
+		"spec/backend/serviceName: Service web not found",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in error %q", expected, err.Error())
+		}
+	}
+}
+
+func TestDanglingReferencesExternalNames(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDanglingReferences(th)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteF("/app/deployment.yaml", strings.Replace(
+		string(mustRead(t, th, "/app/deployment.yaml")), "setings", "settings", 1))
+	th.WriteK("/app", `
+namePrefix: prod-
+resources:
+- deployment.yaml
+- ingress.yaml
+- service.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - LEVEL=debug
+`)
+	opts := th.MakeDefaultOptions()
+	opts.DanglingReferences = &krusty.DanglingReferences{
+		ExternalNames: []string{"Secret/registry-credentials"},
+		FailOnError:   true,
+	}
+	th.Run("/app", opts)
+}
+
+func mustRead(t *testing.T, th kusttest_test.Harness, path string) []byte {
+	b, err := th.GetFSys().ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// stepDigests records the digests of the resources after
+// each step of a run, i.e. each generator and transformer
+// of each kustomization, in the order they ran.
+type stepDigests struct {
+	mu      sync.Mutex
+	steps   []string
+	digests map[string][sha256.Size]byte
+}
+
+func newStepDigests() *stepDigests {
+	return &stepDigests{digests: map[string][sha256.Size]byte{}}
+}
+
+// record is a step recorder for KustTargets.  Steps run more
+// than once, e.g. in a base included twice, are numbered.
+func (d *stepDigests) record(step string, m resmap.ResMap) {
+	y, err := m.AsYaml()
+	if err != nil {
+		y = []byte(err.Error())
+	}
+	digest := sha256.Sum256(y)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := step
+	for i := 2; ; i++ {
+		if _, found := d.digests[key]; !found {
+			break
+		}
+		key = fmt.Sprintf("%s (run %d)", step, i)
+	}
+	d.steps = append(d.steps, key)
+	d.digests[key] = digest
+}
+
+// firstDifference returns the first step of d whose
+// resources differ in other, or "" if there's none.
+func (d *stepDigests) firstDifference(other *stepDigests) string {
+	for _, step := range d.steps {
+		if digest, found := other.digests[step]; !found || digest != d.digests[step] {
+			return step
+		}
+	}
+	for _, step := range other.steps {
+		if _, found := d.digests[step]; !found {
+			return step
+		}
+	}
+	return ""
+}
+
+// verifiedRun runs the kustomization at path twice, and fails
+// if the outputs differ, naming the first generator or
+// transformer which made different resources.  If the
+// VerifyRoundTrip option is set, it also fails if the output
+// changes when written and read again.
+func (b *Kustomizer) verifiedRun(path string, input []byte) (resmap.ResMap, error) {
+	first, second := newStepDigests(), newStepDigests()
+	m, err := b.run(path, input, first.record)
+	if err != nil {
+		return nil, err
+	}
+	again, err := b.run(path, input, second.record)
+	if err != nil {
+		return nil, fmt.Errorf("running again to verify determinism: %v", err)
+	}
+	y, err := m.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+	yAgain, err := again.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(y, yAgain) {
+		msg := "the output isn't deterministic"
+		if step := first.firstDifference(second); step != "" {
+			msg += "; it first differed after step '" + step + "'"
+		}
+		return nil, fmt.Errorf("%s:\n%s", msg, firstDifferentLine(y, yAgain))
+	}
+	if !b.options.VerifyRoundTrip {
+		return m, nil
+	}
+	read, err := b.makeResMapFactory().NewResMapFromBytes(y)
+	if err != nil {
+		return nil, fmt.Errorf("reading the output again: %v", err)
+	}
+	yRead, err := read.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(y, yRead) {
+		return nil, fmt.Errorf(
+			"the output changes when written and read again:\n%s",
+			firstDifferentLine(y, yRead))
+	}
+	return m, nil
+}
+
+// firstDifferentLine describes the first line
+// which differs between the outputs a and b.
+func firstDifferentLine(a, b []byte) string {
+	la := strings.Split(string(a), "\n")
+	lb := strings.Split(string(b), "\n")
+	for i := 0; i < len(la) || i < len(lb); i++ {
+		var x, y string
+		if i < len(la) {
+			x = la[i]
+		}
+		if i < len(lb) {
+			y = lb[i]
+		}
+		if x != y {
+			return fmt.Sprintf("  line %d was %q\n  and then %q", i+1, x, y)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// A generator which counts the times it runs,
+// in the file COUNTER.
+const countingGenerator = `#!/bin/sh
+n=$(($(cat COUNTER 2>/dev/null || echo 0) + 1))
+echo $n > COUNTER
+cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: build
+data:
+  run: "$n"
+EOF
+`
+
+func TestVerifyDeterminism(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+namePrefix: prod-
+commonLabels:
+  app: web
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`)
+	opts := th.MakeDefaultOptions()
+	opts.VerifyDeterminism = true
+	opts.VerifyRoundTrip = true
+	th.AssertActualEqualsExpected(th.Run("/app", opts), `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: web
+  name: prod-web
+spec:
+  ports:
+  - port: 80
+  selector:
+    app: web
+`)
+}
+
+func TestVerifyDeterminismFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the generator is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "kustomize-determinism-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "generate.sh")
+	err = ioutil.WriteFile(script, []byte(strings.ReplaceAll(
+		countingGenerator, "COUNTER", filepath.Join(dir, "counter"))), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	th := kusttest_test.MakeEnhancedHarness(t)
+	defer th.Reset()
+	th.WriteK("/app", `
+generators:
+- generator.yaml
+commonLabels:
+  app: web
+`)
+	th.WriteF("/app/generator.yaml", `
+kind: executable
+metadata:
+  name: counting
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: `+script+`
+`)
+	opts := th.MakeOptionsPluginsEnabled()
+	opts.PluginConfig.FnpLoadingOptions.EnableExec = true
+	opts.VerifyDeterminism = true
+	err = th.RunWithErr("/app", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "the output isn't deterministic; " +
+		"it first differed after step 'generate /app "
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+	if !strings.Contains(err.Error(), `was "  run: \"1\""`) {
+		t.Fatalf("expected the differing line in %q", err.Error())
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeDuplicateDeployments(th kusttest_test.Harness, duplicates string) {
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1
+`)
+	th.WriteK("/app/prod", `
+resources:
+- ../base
+- deployment.yaml
+duplicateResources: `+duplicates+`
+`)
+	th.WriteF("/app/prod/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+}
+
+func TestDuplicateResourcesError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDuplicateDeployments(th, "error")
+	err := th.RunWithErr("/app/prod", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"may not add resource with an already registered id: "+
+			"apps_v1_Deployment|~X|web; it's in both "+
+			"'/app/base/deployment.yaml' and '/app/prod/deployment.yaml'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDuplicateResourcesMerge(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDuplicateDeployments(th, "merge")
+	th.AssertActualEqualsExpected(th.Run("/app/prod", th.MakeDefaultOptions()), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: web:1
+        name: web
+`)
+}
+
+func TestDuplicateResourcesReplace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDuplicateDeployments(th, "replace")
+	th.AssertActualEqualsExpected(th.Run("/app/prod", th.MakeDefaultOptions()), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+}
+
+func TestDuplicateResourcesUnknown(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDuplicateDeployments(th, "ignore")
+	err := th.RunWithErr("/app/prod", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"duplicateResources should be error, merge or replace") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -1,7 +1,12 @@
 package krusty_test
 
 import (
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -464,3 +469,65 @@ metadata:
   name: another-namespace
 `)
 }
+
+func TestFnRegoValidator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	if _, err := exec.LookPath("opa"); err != nil {
+		// A fake opa program reporting the
+		// violation the policy would.
+		bin, err := ioutil.TempDir("", "kustomize-opa-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(bin)
+		err = ioutil.WriteFile(filepath.Join(bin, "opa"), []byte(`#!/bin/sh
+echo '{"result": [{"expressions": [{"value": [
+  {"index": 0, "policy": "k8srequiredlabels", "msg": "missing label owner"}
+]}]}]}'
+`), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Setenv("PATH", os.Getenv("PATH"))
+		os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+
+	th := kusttest_test.MakeEnhancedHarness(t)
+	defer th.Reset()
+
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+validators:
+- validator.yaml
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+`)
+	th.WriteF("/app/validator.yaml", `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: must-have-owner
+  annotations:
+    config.kubernetes.io/function: |
+      rego:
+        path: fnplugin_test/policies
+spec:
+  parameters:
+    labels: [owner]
+`)
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableRego = true
+	err := th.RunWithErr("/app", o)
+	if err == nil || !strings.Contains(err.Error(), "[k8srequiredlabels] missing label owner") {
+		t.Fatalf("expected a policy violation, got %v", err)
+	}
+}
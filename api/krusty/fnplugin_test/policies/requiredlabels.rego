@@ -0,0 +1,7 @@
+package k8srequiredlabels
+
+violation[{"msg": msg}] {
+	required := input.parameters.labels[_]
+	not input.review.object.metadata.labels[required]
+	msg := sprintf("missing label %v", [required])
+}
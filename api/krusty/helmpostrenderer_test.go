@@ -0,0 +1,142 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// The manifests of a chart, as helm passes them to a post-renderer.
+const helmRenderedManifests = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: release-web
+spec:
+  selector:
+    app: web
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: release-web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0
+`
+
+func TestRunWithInput(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+commonLabels:
+  team: payments
+resources:
+- configmap.yaml
+patchesStrategicMerge:
+- patch.yaml
+images:
+- name: web
+  newTag: "1.1"
+`)
+	th.WriteF("/app/configmap.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: extra
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: release-web
+spec:
+  replicas: 3
+`)
+	k := krusty.MakeKustomizer(th.GetFSys(), krusty.MakeDefaultOptions())
+	m, err := k.RunWithInput("/app", []byte(helmRenderedManifests))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    team: payments
+  name: extra
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: payments
+  name: release-web
+spec:
+  selector:
+    app: web
+    team: payments
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: payments
+  name: release-web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      team: payments
+  template:
+    metadata:
+      labels:
+        team: payments
+    spec:
+      containers:
+      - image: web:1.1
+        name: web
+`)
+}
+
+func TestRunWithInputEmpty(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: p-
+`)
+	k := krusty.MakeKustomizer(th.GetFSys(), krusty.MakeDefaultOptions())
+	m, err := k.RunWithInput("/app", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Size() != 0 {
+		t.Fatalf("expected no resources, got %d", m.Size())
+	}
+}
+
+func TestRunWithInputConflict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: release-web
+`)
+	k := krusty.MakeKustomizer(th.GetFSys(), krusty.MakeDefaultOptions())
+	_, err := k.RunWithInput("/app", []byte(helmRenderedManifests))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+)
+
+// HostingAPI configures downloading remote bases hosted on
+// github.com or gitlab.com as archives, through the APIs of
+// the hosts, e.g. in CI environments without git, or without
+// git credentials for private repositories.  Archives don't
+// include git submodules, and archives containing symlinks
+// are refused.  Other remote bases are still cloned with git.
+type HostingAPI struct {
+	// GitHubToken and GitLabToken authenticate requests.
+	// They're read from the GITHUB_TOKEN and GITLAB_TOKEN
+	// environment variables if empty.
+	GitHubToken string
+	GitLabToken string
+
+	// Proxy is the URL of the proxy to send requests
+	// through.  If empty, it's chosen from the HTTPS_PROXY
+	// and NO_PROXY environment variables.
+	Proxy string
+
+	// Retries is how many times failed requests, and those
+	// refused by rate limits, are retried.  3 if zero;
+	// negative values mean no retries.
+	Retries int
+}
+
+func (h *HostingAPI) loaderOptions() *fLdr.HostingAPIOptions {
+	if h == nil {
+		return nil
+	}
+	return &fLdr.HostingAPIOptions{
+		GitHubToken: h.GitHubToken,
+		GitLabToken: h.GitLabToken,
+		Proxy:       h.Proxy,
+		Retries:     h.Retries,
+	}
+}
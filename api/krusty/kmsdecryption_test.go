@@ -0,0 +1,85 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// A fake gcloud program, which "decrypts" by
+// returning its input.
+const fakeGcloud = `#!/bin/sh
+[ "$1 $2" = "kms decrypt" ] || exit 1
+cat
+`
+
+func TestKmsDecryption(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake gcloud is a shell script")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-gcloud-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bin)
+	if err = ioutil.WriteFile(filepath.Join(bin, "gcloud"), []byte(fakeGcloud), 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+secretGenerator:
+- name: db
+  envs:
+  - db.env
+  options:
+    disableNameSuffixHash: true
+configMapGenerator:
+- name: settings
+  files:
+  - db.env
+  options:
+    disableNameSuffixHash: true
+`)
+	th.WriteF("/app/db.env", `
+kms:
+  provider: gcp
+  key: projects/p/locations/global/keyRings/r/cryptoKeys/k
+ciphertext: `+base64.StdEncoding.EncodeToString([]byte("PASSWORD=hunter2\n"))+`
+`)
+	opts := th.MakeDefaultOptions()
+	opts.KmsDecryption = true
+	m := th.Run("/app", opts)
+	// ConfigMaps aren't decrypted.
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  db.env: |2
+
+    kms:
+      provider: gcp
+      key: projects/p/locations/global/keyRings/r/cryptoKeys/k
+    ciphertext: UEFTU1dPUkQ9aHVudGVyMgo=
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: v1
+data:
+  PASSWORD: aHVudGVyMg==
+kind: Secret
+metadata:
+  name: db
+type: Opaque
+`)
+}
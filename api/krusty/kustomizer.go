@@ -5,9 +5,11 @@ package krusty
 
 import (
 	"fmt"
+	"time"
 
 	"sigs.k8s.io/kustomize/api/builtins"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/k8sdeps/transformer"
 	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/internal/target"
@@ -19,6 +21,8 @@ import (
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/log"
+	"sigs.k8s.io/kustomize/kyaml/metrics"
 )
 
 // Kustomizer performs kustomizations.  It's meant to behave
@@ -49,48 +53,244 @@ func MakeKustomizer(fSys filesys.FileSystem, o *Options) *Kustomizer {
 // on any number of internal paths (e.g. the filesystem may contain
 // multiple overlays, and Run can be called on each of them).
 func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
+	return b.meteredRun(path, nil)
+}
+
+// RunWithInput performs a kustomization like Run, treating the
+// resources in the YAML input as resources of the kustomization
+// at path, after those it lists.  This suits Helm post-renderers,
+// which read the rendered manifests of a chart from stdin, and
+// apply the kustomization's transformers and patches to them.
+func (b *Kustomizer) RunWithInput(path string, input []byte) (resmap.ResMap, error) {
+	if input == nil {
+		input = []byte{}
+	}
+	return b.meteredRun(path, input)
+}
+
+// meteredRun runs, recording the run to the Metrics option.
+func (b *Kustomizer) meteredRun(path string, input []byte) (resmap.ResMap, error) {
+	start := time.Now()
+	var m resmap.ResMap
+	var err error
+	if b.options.VerifyDeterminism {
+		m, err = b.verifiedRun(path, input)
+	} else {
+		m, err = b.run(path, input, nil)
+	}
+	if err == nil && b.options.ServerDryRun != nil {
+		err = b.timePhase("dryrun", func() error {
+			return b.options.ServerDryRun.dryRun(m, b.warn)
+		})
+		if err != nil {
+			m = nil
+		}
+	}
+	metrics.RecordRun(b.options.Metrics,
+		metrics.BuildsTotal, metrics.BuildDurationSeconds, start, err)
+	return m, err
+}
+
+// makeResMapFactory returns a factory of resources
+// as the options call for.
+func (b *Kustomizer) makeResMapFactory() *resmap.Factory {
+	kf := kunstruct.NewKunstructuredFactoryWithOptions(kunstruct.FactoryOptions{
+		LazyParsing:   b.options.LazyParsing,
+		LegacyHashing: b.options.LegacyHashing,
+	})
+	return resmap.NewFactory(resource.NewFactory(kf), transformer.NewFactoryImpl())
+}
+
+// run performs the kustomization at path, giving the
+// resources after each generator and transformer to
+// steps, if it isn't nil.
+func (b *Kustomizer) run(
+	path string, input []byte, steps func(string, resmap.ResMap)) (resmap.ResMap, error) {
+	m, ldr, err := b.accumulate(path, input, steps)
+	if ldr != nil {
+		defer ldr.Cleanup()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b.options.DoLegacyResourceSort {
+		err = b.timePhase("sort", func() error {
+			return builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if b.options.ResourceOrder != nil {
+		err = b.timePhase("sort", func() error {
+			return sortByKind(m, *b.options.ResourceOrder)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if finish := b.finisher(ldr.Root()); finish != nil {
+		err = b.timePhase("label", func() error {
+			return finish(m)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if b.options.SchemaValidation != nil {
+		err = b.timePhase("validate", func() error {
+			return b.options.SchemaValidation.validate(b.fSys, m, ldr.Root(), b.warn)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// accumulate loads the kustomization at path, and returns
+// its resources, with vars and name references resolved,
+// and its loader, which the caller must clean up once done
+// with the files of the kustomization, if it isn't nil.
+func (b *Kustomizer) accumulate(
+	path string, input []byte, steps func(string, resmap.ResMap)) (
+	resmap.ResMap, ifc.Loader, error) {
 	pf := transformer.NewFactoryImpl()
-	rf := resmap.NewFactory(
-		resource.NewFactory(
-			kunstruct.NewKunstructuredFactoryImpl()),
-		pf)
+	rf := b.makeResMapFactory()
 	lr := fLdr.RestrictionNone
 	if b.options.LoadRestrictions == types.LoadRestrictionsRootOnly {
 		lr = fLdr.RestrictionRootOnly
 	}
-	ldr, err := fLdr.NewLoader(lr, path, b.fSys)
-	if err != nil {
-		return nil, err
-	}
-	defer ldr.Cleanup()
-	kt := target.NewKustTarget(
-		ldr,
-		validator.NewKustValidator(),
-		rf,
-		pf,
-		pLdr.NewLoader(b.options.PluginConfig, rf),
-	)
-	err = kt.Load()
+	var ldr ifc.Loader
+	var kt *target.KustTarget
+	err := b.timePhase("load", func() error {
+		var err error
+		ldr, err = fLdr.NewLoaderWithHostingAPI(
+			lr, path, b.fSys, b.options.CloneParallelism, b.options.GitCacheDir,
+			b.options.HostingAPI.loaderOptions())
+		if err != nil {
+			return err
+		}
+		pl := pLdr.NewLoader(b.options.PluginConfig, rf)
+		if a := b.options.BuildAttestation; a != nil {
+			ldr = a.start(ldr)
+			pl.SetRecorder(a.recordPlugin)
+		}
+		kt = target.NewKustTarget(
+			ldr,
+			validator.NewKustValidator(),
+			rf,
+			pf,
+			pl,
+		)
+		kt.SetParallelism(b.options.Parallelism)
+		kt.SetParallelTransformers(b.options.ParallelTransformers)
+		kt.SetPhaseTimer(b.options.PhaseTimer)
+		kt.SetStepRecorder(steps)
+		kt.SetWarner(b.options.Warner)
+		kt.SetSopsDecryption(b.options.SopsDecryption)
+		kt.SetKmsDecryption(b.options.KmsDecryption)
+		kt.SetStrict(b.options.Strict)
+		kt.SetPatchesMustMatch(b.options.PatchesMustMatch)
+		kt.SetStrictVars(b.options.StrictVars)
+		if c := b.options.DanglingReferences; c != nil {
+			kt.SetReferenceCheck(&target.ReferenceCheck{
+				ExternalNames: c.ExternalNames,
+				FailOnError:   c.FailOnError,
+			})
+		}
+		if c := b.options.NamespaceCheck; c != nil {
+			kt.SetNamespaceCheck(&target.NamespaceCheck{
+				FailOnError: c.FailOnError,
+			})
+		}
+		if b.options.BuildCache != nil && b.options.BuildAttestation == nil &&
+			!b.options.VerifyDeterminism {
+			kt.SetBuildCache(b.options.BuildCache.c)
+		}
+		kt.SetMetrics(b.options.Metrics)
+		if c := b.options.ClusterResources; c != nil {
+			kt.SetClusterReader(&target.ClusterReader{
+				Kubeconfig: c.Kubeconfig,
+				Context:    c.Context,
+			})
+		}
+		if input != nil {
+			m, err := rf.NewResMapFromBytes(input)
+			if err != nil {
+				return fmt.Errorf("reading input resources: %v", err)
+			}
+			kt.SetInput(m)
+		}
+		return kt.Load()
+	})
 	if err != nil {
-		return nil, err
+		return nil, ldr, err
 	}
 	var m resmap.ResMap
-	m, err = kt.MakeCustomizedResMap()
+	err = b.timePhase("accumulate", func() error {
+		var err error
+		m, err = kt.MakeCustomizedResMap()
+		return err
+	})
 	if err != nil {
-		return nil, err
-	}
-	if b.options.DoLegacyResourceSort {
-		builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+		return nil, ldr, err
 	}
+	return m, ldr, nil
+}
+
+// finisher returns the function which adds the managed-by
+// label and the provenance annotations, if asked, to the
+// resources built from the kustomization in root, or nil if
+// neither is asked for.  Those are the last changes to the
+// resources, each made to each resource alone, so they may
+// be made as resources are emitted.
+func (b *Kustomizer) finisher(root string) func(resmap.ResMap) error {
+	var ts []resmap.Transformer
 	if b.options.AddManagedbyLabel {
-		t := builtins.LabelTransformerPlugin{
+		ts = append(ts, &builtins.LabelTransformerPlugin{
 			Labels: map[string]string{konfig.ManagedbyLabelKey: fmt.Sprintf("kustomize-%s", provenance.GetProvenance().Version)},
 			FieldSpecs: []types.FieldSpec{{
 				Path:               "metadata/labels",
 				CreateIfNotPresent: true,
 			}},
+		})
+	}
+	if b.options.BuildProvenance != nil {
+		if t := b.options.BuildProvenance.transformer(root); t != nil {
+			ts = append(ts, t)
 		}
-		t.Transform(m)
 	}
-	return m, nil
+	if len(ts) == 0 {
+		return nil
+	}
+	return func(m resmap.ResMap) error {
+		for _, t := range ts {
+			if err := t.Transform(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// warn gives w to the Warner option, or logs it.
+func (b *Kustomizer) warn(w types.Warning) {
+	if b.options.Warner != nil {
+		b.options.Warner(w)
+		return
+	}
+	log.Warn(w.Message, w.KeysAndValues()...)
+}
+
+// timePhase calls fn, and reports how long it took
+// to the PhaseTimer option, if any.
+func (b *Kustomizer) timePhase(phase string, fn func() error) error {
+	if b.options.PhaseTimer == nil {
+		return fn()
+	}
+	start := time.Now()
+	err := fn()
+	b.options.PhaseTimer(phase, time.Since(start))
+	return err
 }
@@ -0,0 +1,66 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestLazyParsingMatchesEagerParsing(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: p-
+commonLabels:
+  team: a
+resources:
+- resources.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: example.com/v1
+kind: Untouched
+metadata:
+  name: thing
+spec:
+  values: [1, 2.5, "three"]
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+	opts := th.MakeDefaultOptions()
+	expected, err := th.Run("/app", opts).AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.LazyParsing = true
+	th.AssertActualEqualsExpected(th.Run("/app", opts), string(expected))
+}
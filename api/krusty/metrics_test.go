@@ -0,0 +1,61 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- service.yaml
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`)
+	th.WriteK("/app/dev", `
+namespace: dev
+resources:
+- ../base
+`)
+	th.WriteK("/app/broken", `
+resources:
+- missing.yaml
+`)
+	p := metrics.NewPrometheus()
+	opts := th.MakeDefaultOptions()
+	opts.BuildCache = krusty.NewBuildCache(th.GetFSys())
+	opts.Metrics = p
+	th.Run("/app/dev", opts)
+	th.Run("/app/dev", opts)
+	if err := th.RunWithErr("/app/broken", opts); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	var b strings.Builder
+	if _, err := p.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`kustomize_build_cache_lookups_total{result="hit"} 1`,
+		`kustomize_build_cache_lookups_total{result="miss"} 1`,
+		`kustomize_builds_total{result="error"} 1`,
+		`kustomize_builds_total{result="success"} 2`,
+		`kustomize_build_duration_seconds_count{result="success"} 2`,
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, b.String())
+		}
+	}
+}
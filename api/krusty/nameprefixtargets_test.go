@@ -0,0 +1,66 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestNamePrefixSuffixTargets(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: myService
+spec:
+  ports:
+  - port: 7002
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myConfig
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: myWidget
+`)
+	th.WriteK("/app", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: acme-
+namePrefixTargets:
+- kind: Service
+- kind: ConfigMap
+nameSuffix: -v1
+nameSuffixTargets:
+- kind: Service
+resources:
+- resources.yaml
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: acme-myService-v1
+spec:
+  ports:
+  - port: 7002
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: acme-myConfig
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: myWidget
+`)
+}
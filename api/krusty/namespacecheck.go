@@ -0,0 +1,20 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+// NamespaceCheck configures checking the namespaces of the
+// resources of a build, which regularly end up applied to the
+// default namespace by mistake.  Namespaced resources of the
+// output without a namespace, and resources whose namespace,
+// as written, the namespace field of a kustomization replaces,
+// are logged as warnings, and fail the build if FailOnError is
+// true.  Resources annotated with
+// kustomize.config.k8s.io/allow-missing-namespace or
+// kustomize.config.k8s.io/allow-namespace-replacement set to
+// "true" are exempt from the respective check.
+type NamespaceCheck struct {
+	// When true, the build fails if any
+	// namespace problem is found.
+	FailOnError bool
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeNamespaceApp(th kusttest_test.Harness) {
+	th.WriteK("/base", `
+namespace: team
+resources:
+- deployment.yaml
+- role.yaml
+- service.yaml
+`)
+	th.WriteF("/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: other
+`)
+	th.WriteF("/base/role.yaml", `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: reader
+  namespace: other
+  annotations:
+    kustomize.config.k8s.io/allow-namespace-replacement: "true"
+`)
+	th.WriteF("/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteK("/app", `
+resources:
+- ../base
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  annotations:
+    kustomize.config.k8s.io/allow-missing-namespace: "true"
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+}
+
+func TestNamespaceCheckWarns(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeNamespaceApp(th)
+	var warnings []types.Warning
+	opts := th.MakeDefaultOptions()
+	opts.NamespaceCheck = &krusty.NamespaceCheck{}
+	opts.Warner = func(w types.Warning) {
+		warnings = append(warnings, w)
+	}
+	th.Run("/app", opts)
+	expected := []types.Warning{
+		{
+			Code:     types.WarningNamespaceReplaced,
+			Message:  "namespace other is replaced by team",
+			Resource: "apps_v1_Deployment|other|web",
+			Location: "/base/deployment.yaml",
+		},
+		{
+			Code:     types.WarningMissingNamespace,
+			Message:  "namespaced resource has no namespace",
+			Resource: "~G_v1_ConfigMap|~X|settings",
+			Location: "/app/resources.yaml",
+		},
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected warnings\n%v\ngot\n%v", expected, warnings)
+	}
+}
+
+func TestNamespaceCheckFails(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeNamespaceApp(th)
+	th.WriteK("/base", `
+resources:
+- deployment.yaml
+- role.yaml
+- service.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	opts.NamespaceCheck = &krusty.NamespaceCheck{FailOnError: true}
+	err := th.RunWithErr("/app", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := `2 namespace problems:
+  ~G_v1_Service|~X|web: namespaced resource has no namespace
+  ~G_v1_ConfigMap|~X|settings: namespaced resource has no namespace`
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+}
+
+func TestNamespaceCheckFailsOnReplacement(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeNamespaceApp(th)
+	opts := th.MakeDefaultOptions()
+	opts.NamespaceCheck = &krusty.NamespaceCheck{FailOnError: true}
+	err := th.RunWithErr("/base", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := `1 namespace problems:
+  apps_v1_Deployment|other|web: namespace other is replaced by team`
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+}
@@ -4,8 +4,12 @@
 package krusty
 
 import (
+	"time"
+
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/metrics"
 )
 
 // Options holds high-level kustomize configuration options,
@@ -18,6 +22,12 @@ type Options struct {
 	// order as specified by the kustomization file(s).
 	DoLegacyResourceSort bool
 
+	// If not nil, the resources are sorted by their kinds
+	// before emitting them, after the legacy sort if it's
+	// done, so that they may be applied safely, e.g. with
+	// Namespaces and CRDs first, and webhooks last.
+	ResourceOrder *filters.SortFilter
+
 	// When true, a label
 	//     app.kubernetes.io/managed-by: kustomize-<version>
 	// is added to all the resources in the build out.
@@ -32,6 +42,144 @@ type Options struct {
 
 	// Options related to kustomize plugins.
 	PluginConfig *types.PluginConfig
+
+	// The maximum number of resources and bases loaded
+	// concurrently by each kustomization in the build.
+	// Values less than 2 load them sequentially, which is
+	// the default.  The output order doesn't depend on
+	// this value.
+	Parallelism int
+
+	// When true, runs of builtin transformers that change
+	// each resource based only on that resource (labels,
+	// annotations and images) are applied concurrently to
+	// disjoint shards of the resources, up to Parallelism
+	// shards at a time.  The output is the same either way.
+	ParallelTransformers bool
+
+	// The maximum number of remote git repositories cloned
+	// at the same time.  Values less than 1 mean no limit.
+	// Bases in the same repository at the same ref share
+	// a single clone regardless of this value.
+	CloneParallelism int
+
+	// If not empty, remote git repositories are cloned into
+	// GitCacheDir, and kept there to be reused by later
+	// builds, rather than being cloned into temporary
+	// directories for each build.
+	GitCacheDir string
+
+	// If not nil, remote bases hosted on github.com or
+	// gitlab.com are downloaded through the APIs of the
+	// hosts, rather than cloned with git.
+	HostingAPI *HostingAPI
+
+	// When true, resources read from files are only fully
+	// decoded once a transformer needs more than their
+	// identifying metadata, which saves time and memory on
+	// large inputs that mostly pass through unchanged.
+	LazyParsing bool
+
+	// When true, the name suffix hashes of ConfigMaps and
+	// Secrets are computed by converting them to their typed
+	// forms, as kustomize used to.  The hashes are the same
+	// either way; this is slower, and only meant as a fallback.
+	LegacyHashing bool
+
+	// Which files encrypted with SOPS are decrypted, with
+	// the sops program, as they're read.  None by default.
+	SopsDecryption types.SopsDecryption
+
+	// When true, the files read by secretGenerators and
+	// sealedSecretGenerators which are KMS envelopes (see
+	// loader.KmsEnvelope) are decrypted with the CLI of
+	// their cloud, gcloud, aws or az, and so its ambient
+	// credentials.
+	KmsDecryption bool
+
+	// When true, deprecated fields in kustomization files,
+	// and fields not spelled in the case declared, fail the
+	// build, rather than being warned about.  Unknown fields
+	// and type errors always fail it.
+	Strict bool
+
+	// When true, the build is run twice, and fails if the
+	// outputs differ, naming the first generator or
+	// transformer whose resources differed, so that
+	// non-deterministic plugins are caught.  The BuildCache
+	// isn't used then.
+	VerifyDeterminism bool
+
+	// When true, along with VerifyDeterminism, the build also
+	// fails if its output changes when written and read again.
+	VerifyRoundTrip bool
+
+	// When true, patches whose targets select no resources
+	// fail the build, rather than being warned about, unless
+	// they set allowNoMatch.  Patches may also set
+	// failOnNoMatch to fail whether or not this is true.
+	PatchesMustMatch bool
+
+	// When true, vars which are never referred to, and
+	// references like $(NAME) to names which are neither
+	// vars nor env vars of the resource, fail the build,
+	// rather than being warned about.
+	StrictVars bool
+
+	// If not nil, PhaseTimer is told how long each phase of
+	// the build took: "load", "accumulate" (which includes
+	// the transformers), each transformer of each
+	// kustomization as "transform <root> <type>", "sort",
+	// "label", "validate" and "dryrun".  It must be safe for
+	// concurrent use, since bases may be accumulated
+	// concurrently.
+	PhaseTimer func(phase string, elapsed time.Duration)
+
+	// If not nil, each resource in the build output is
+	// annotated with where it was built from.
+	BuildProvenance *BuildProvenance
+
+	// If not nil, BuildAttestation records the inputs of the
+	// build, so that the provenance of its output can be
+	// attested.
+	BuildAttestation *BuildAttestation
+
+	// If not nil, the resources in the build output are
+	// checked against the schemas of their kinds.
+	SchemaValidation *SchemaValidation
+
+	// If not nil, references by name to ConfigMaps, Secrets
+	// and Services which are neither in the build output nor
+	// declared external are reported.
+	DanglingReferences *DanglingReferences
+
+	// If not nil, namespaced resources without a namespace,
+	// and resources whose namespaces kustomizations replace,
+	// are reported.
+	NamespaceCheck *NamespaceCheck
+
+	// If not nil, the results of building bases are
+	// cached in, and reused from, BuildCache.
+	BuildCache *BuildCache
+
+	// If not nil, resources may be read from the live
+	// cluster.  Bases aren't cached then.
+	ClusterResources *ClusterResources
+
+	// If not nil, the build output is submitted to the live
+	// cluster in a server-side dry-run once it's built, to
+	// find what the cluster would reject.
+	ServerDryRun *ServerDryRun
+
+	// If not nil, Warner is given the warnings of each Run,
+	// e.g. deprecated fields used, patches selecting no
+	// resources, or vars never replaced, rather than them
+	// being logged.
+	Warner types.Warner
+
+	// If not nil, Metrics records each Run, and
+	// lookups of bases in the BuildCache.
+	Metrics metrics.Recorder
 }
 
 // MakeDefaultOptions returns a default instance of Options.
@@ -41,5 +189,6 @@ func MakeDefaultOptions() *Options {
 		LoadRestrictions:     types.LoadRestrictionsRootOnly,
 		DoPrune:              false,
 		PluginConfig:         konfig.DisabledPluginConfig(),
+		Parallelism:          1,
 	}
 }
@@ -0,0 +1,190 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// Accumulating bases and resources concurrently must
+// not change the order or content of the output.
+func TestParallelAccumulationIsDeterministic(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	var resources string
+	for i := 0; i < 8; i++ {
+		th.WriteK(fmt.Sprintf("/app/base%d", i), fmt.Sprintf(`
+namePrefix: b%d-
+resources:
+- map.yaml
+- sub
+`, i))
+		th.WriteF(fmt.Sprintf("/app/base%d/map.yaml", i), fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: map
+data:
+  index: "%d"
+`, i))
+		th.WriteK(fmt.Sprintf("/app/base%d/sub", i), `
+resources:
+- service.yaml
+`)
+		th.WriteF(fmt.Sprintf("/app/base%d/sub/service.yaml", i), fmt.Sprintf(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc%d
+`, i))
+		resources += fmt.Sprintf("- base%d\n", i)
+	}
+	th.WriteK("/app", "resources:\n"+resources)
+
+	opts := th.MakeDefaultOptions()
+	opts.Parallelism = 1
+	expected, err := th.Run("/app", opts).AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Parallelism = 4
+	for i := 0; i < 10; i++ {
+		th.AssertActualEqualsExpected(th.Run("/app", opts), string(expected))
+	}
+}
+
+func TestParallelAccumulationReportsFirstError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- missing1
+- ok.yaml
+- missing2
+`)
+	th.WriteF("/app/ok.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: ok
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Parallelism = 4
+	err := th.RunWithErr("/app", opts)
+	if !strings.Contains(err.Error(), "missing1") ||
+		strings.Contains(err.Error(), "missing2") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Applying resource local transformers to shards of the
+// resources concurrently must not change the output.
+func TestParallelTransformersMatchSequential(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	var resources string
+	for i := 0; i < 10; i++ {
+		th.WriteF(fmt.Sprintf("/app/deploy%d.yaml", i), fmt.Sprintf(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web%d
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+`, i))
+		resources += fmt.Sprintf("- deploy%d.yaml\n", i)
+	}
+	th.WriteK("/app", `
+namePrefix: p-
+commonLabels:
+  team: a
+commonAnnotations:
+  note: b
+images:
+- name: nginx
+  newTag: "1.19"
+patchesStrategicMerge:
+- patch.yaml
+resources:
+`+resources)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web3
+spec:
+  replicas: 3
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Parallelism = 1
+	expected, err := th.Run("/app", opts).AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Parallelism = 4
+	opts.ParallelTransformers = true
+	for i := 0; i < 10; i++ {
+		th.AssertActualEqualsExpected(th.Run("/app", opts), string(expected))
+	}
+}
+
+// BenchmarkParallelTransformers compares applying the resource local
+// transformers sequentially with applying them to shards concurrently.
+func BenchmarkParallelTransformers(b *testing.B) {
+	fSys := filesys.MakeFsInMemory()
+	var resources string
+	for i := 0; i < 500; i++ {
+		fSys.WriteFile(fmt.Sprintf("/app/deploy%d.yaml", i), []byte(fmt.Sprintf(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web%d
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: busybox
+`, i)))
+		resources += fmt.Sprintf("- deploy%d.yaml\n", i)
+	}
+	fSys.WriteFile("/app/kustomization.yaml", []byte(`
+commonLabels:
+  team: a
+commonAnnotations:
+  note: b
+images:
+- name: nginx
+  newTag: "1.19"
+- name: busybox
+  newTag: "1.32"
+resources:
+`+resources))
+	for _, parallel := range []bool{false, true} {
+		name := "sequential"
+		if parallel {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			opts := krusty.MakeDefaultOptions()
+			opts.Parallelism = runtime.NumCPU()
+			opts.ParallelTransformers = parallel
+			for i := 0; i < b.N; i++ {
+				if _, err := krusty.MakeKustomizer(fSys, opts).Run("/app"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
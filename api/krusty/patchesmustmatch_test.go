@@ -0,0 +1,84 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeUnmatchedPatches(th kusttest_test.Harness, options string) {
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+patches:
+- target:
+    kind: Deployment
+    name: web
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 3
+- target:
+    kind: Deployment
+    name: frontend
+`+options+`
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 5
+`)
+}
+
+func TestPatchesMustMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeUnmatchedPatches(th, "")
+	opts := th.MakeDefaultOptions()
+	// Only warned about by default.
+	th.Run("/app", opts)
+
+	opts.PatchesMustMatch = true
+	err := th.RunWithErr("/app", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "the target of a patch in /app, kind=Deployment name=frontend, " +
+		"selects no resources; set allowNoMatch on the patch if that's expected"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestPatchFailOnNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeUnmatchedPatches(th, "  failOnNoMatch: true")
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestPatchAllowNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeUnmatchedPatches(th, "  allowNoMatch: true")
+	opts := th.MakeDefaultOptions()
+	opts.PatchesMustMatch = true
+	th.AssertActualEqualsExpected(th.Run("/app", opts), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+}
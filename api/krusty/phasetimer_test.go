@@ -0,0 +1,57 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestPhaseTimer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+namePrefix: b-
+resources:
+- service.yaml
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`)
+	th.WriteK("/app", `
+namespace: ns
+resources:
+- base
+`)
+	var mu sync.Mutex
+	phases := map[string]int{}
+	opts := th.MakeDefaultOptions()
+	opts.DoLegacyResourceSort = true
+	opts.PhaseTimer = func(phase string, elapsed time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		phases[phase]++
+	}
+	th.Run("/app", opts)
+
+	for _, phase := range []string{
+		"load",
+		"accumulate",
+		"sort",
+		"transform /app builtins.NamespaceTransformerPlugin",
+		"transform /app/base builtins.PrefixSuffixTransformerPlugin",
+	} {
+		if phases[phase] != 1 {
+			t.Errorf("expected phase %q to be timed once, got %v", phase, phases)
+		}
+	}
+	if _, ok := phases["label"]; ok {
+		t.Errorf("unexpected label phase")
+	}
+}
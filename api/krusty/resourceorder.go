@@ -0,0 +1,29 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+// sortByKind orders the resources of m by the priorities of
+// their kinds in order, keeping the order of resources with
+// the same priority.
+func sortByKind(m resmap.ResMap, order filters.SortFilter) error {
+	resources := m.Resources()
+	sort.SliceStable(resources, func(i, j int) bool {
+		return order.Priority(resources[i].GetKind()) <
+			order.Priority(resources[j].GetKind())
+	})
+	m.Clear()
+	for _, r := range resources {
+		if err := m.Append(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
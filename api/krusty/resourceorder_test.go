@@ -0,0 +1,102 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+func writeResourceOrderApp(th kusttest_test.Harness) {
+	th.WriteF("/app/resources.yaml", `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: webhook
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: service
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crd
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+`)
+}
+
+func TestResourceOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeResourceOrderApp(th)
+	options := th.MakeDefaultOptions()
+	options.DoLegacyResourceSort = false
+	options.ResourceOrder = &filters.SortFilter{}
+	m := th.Run("/app", options)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crd
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: service
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: webhook
+`)
+}
+
+func TestResourceOrderOverridden(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeResourceOrderApp(th)
+	options := th.MakeDefaultOptions()
+	options.DoLegacyResourceSort = false
+	options.ResourceOrder = &filters.SortFilter{
+		First: []string{"Service"},
+		Last:  []string{"Namespace"},
+	}
+	m := th.Run("/app", options)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: service
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: webhook
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crd
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`)
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/schema"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// SchemaValidation configures checking the resources of
+// a build against the JSON schemas of their kinds, as
+// kubeconform does.  The problems found are logged as
+// warnings, and fail the build if FailOnError is true.
+type SchemaValidation struct {
+	// SchemaLocations are searched in order for the schema
+	// of each kind.  Each is a directory or URL holding files
+	// named as in kubeconform, e.g. deployment-apps-v1.json;
+	// a template of a file name or URL using the kubeconform
+	// variables, e.g. {{ .ResourceKind }}{{ .KindSuffix }};
+	// or "default", for the schemas of the builtin kinds.
+	SchemaLocations []string
+
+	// KubernetesVersion selects the schemas of the default
+	// location, e.g. 1.18.0.  It's "master" if empty.
+	KubernetesVersion string
+
+	// When true, fields not declared by the schemas are
+	// reported, even where the schemas allow them.
+	Strict bool
+
+	// When true, resources of kinds without a schema
+	// aren't reported.
+	IgnoreMissingSchemas bool
+
+	// When true, the build fails if any problem is found.
+	FailOnError bool
+}
+
+// MakeDefaultSchemaValidation returns a SchemaValidation
+// using the schemas of the builtin kinds.
+func MakeDefaultSchemaValidation() *SchemaValidation {
+	return &SchemaValidation{
+		SchemaLocations:   []string{"default"},
+		KubernetesVersion: "master",
+	}
+}
+
+// validate checks the resources built from the kustomization
+// in root, attributing each problem to the resource and the
+// file it was read from, if any, with the line and column of
+// the field in that file, if it's there.  The problems are
+// given to warn, unless they fail the build.
+func (v *SchemaValidation) validate(
+	fSys filesys.FileSystem, m resmap.ResMap, root string, warn types.Warner) error {
+	store, err := schema.NewStore(fSys, v.SchemaLocations, v.KubernetesVersion, v.Strict)
+	if err != nil {
+		return err
+	}
+	sources := map[string][]byte{}
+	var problems []string
+	for _, r := range m.Resources() {
+		origin := r.GetOrigin()
+		if rel, err := filepath.Rel(root, origin); err == nil && !strings.HasPrefix(rel, "..") {
+			origin = rel
+		}
+		where := r.CurId().String()
+		if origin != "" {
+			where += " (" + origin + ")"
+		}
+		s, err := store.Find(r.GetGvk())
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			if !v.IgnoreMissingSchemas {
+				if !v.FailOnError {
+					warn(types.Warning{
+						Code:     types.WarningMissingSchema,
+						Message:  "no schema found",
+						Resource: r.CurId().String(),
+						Location: origin,
+					})
+				}
+				problems = append(problems, where+": no schema found")
+			}
+			continue
+		}
+		for _, p := range schema.Validate(s, r.ReadOnlyMap(), v.Strict) {
+			where, location := where, origin
+			if lp, found := locateInOrigin(fSys, sources, r, p); found {
+				location = fmt.Sprintf("%s:%d:%d", origin, lp.Line, lp.Column)
+				where = fmt.Sprintf("%s (%s)", r.CurId(), location)
+			}
+			if !v.FailOnError {
+				warn(types.Warning{
+					Code:     types.WarningSchemaProblem,
+					Message:  p.String(),
+					Resource: r.CurId().String(),
+					Location: location,
+				})
+			}
+			problems = append(problems, where+": "+p.String())
+		}
+	}
+	if v.FailOnError && len(problems) > 0 {
+		return fmt.Errorf(
+			"%d schema validation problems:\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// locateInOrigin finds the field with the problem p of r in
+// the file r was read from, keeping the files read in sources.
+func locateInOrigin(
+	fSys filesys.FileSystem, sources map[string][]byte,
+	r *resource.Resource, p schema.Problem) (schema.LocatedProblem, bool) {
+	origin := r.GetOrigin()
+	if origin == "" || !filepath.IsAbs(origin) {
+		return schema.LocatedProblem{Problem: p}, false
+	}
+	content, read := sources[origin]
+	if !read {
+		// Files of remote bases are gone by now.
+		content, _ = fSys.ReadFile(origin)
+		sources[origin] = content
+	}
+	return schema.LocateInSource(content, r.OrgId().Kind, r.GetOriginalName(), p)
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeSchemaValidationFiles(th kusttest_test.Harness) {
+	th.WriteF("/schemas/deployment-apps-v1.json", `{
+  "type": "object",
+  "properties": {
+    "apiVersion": {"type": "string"},
+    "kind": {"type": "string"},
+    "metadata": {"type": "object"},
+    "spec": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "replicas": {"type": "integer"}
+      }
+    }
+  }
+}`)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: "3"
+  replica: 3
+`)
+	th.WriteK("/app/overlay", `
+namePrefix: prod-
+resources:
+- ../base
+- service.yaml
+`)
+	th.WriteF("/app/overlay/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+}
+
+func TestSchemaValidationFailsBuild(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSchemaValidationFiles(th)
+	opts := th.MakeDefaultOptions()
+	opts.SchemaValidation = &krusty.SchemaValidation{
+		SchemaLocations: []string{"/schemas"},
+		FailOnError:     true,
+	}
+	err := th.RunWithErr("/app/overlay", opts)
+	if err == nil {
+		t.Fatalf("expected the build to fail")
+	}
+	for _, expected := range []string{
+		"3 schema validation problems",
+		"apps_v1_Deployment|~X|prod-web (/app/base/deployment.yaml:8:3): spec.replica: unknown field",
+		"apps_v1_Deployment|~X|prod-web (/app/base/deployment.yaml:7:3): spec.replicas: expected integer, got string",
+		"~G_v1_Service|~X|prod-web (service.yaml): no schema found",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in error:\n%v", expected, err)
+		}
+	}
+}
+
+func TestSchemaValidationLocatesOnlyFieldsInSources(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSchemaValidationFiles(th)
+	th.WriteK("/app/overlay", `
+namePrefix: prod-
+resources:
+- ../base
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteF("/app/overlay/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replcias: 2
+`)
+	opts := th.MakeDefaultOptions()
+	opts.SchemaValidation = &krusty.SchemaValidation{
+		SchemaLocations: []string{"/schemas"},
+		FailOnError:     true,
+	}
+	err := th.RunWithErr("/app/overlay", opts)
+	if err == nil {
+		t.Fatalf("expected the build to fail")
+	}
+	for _, expected := range []string{
+		"3 schema validation problems",
+		"apps_v1_Deployment|~X|prod-web (/app/base/deployment.yaml:8:3): spec.replica: unknown field",
+		"apps_v1_Deployment|~X|prod-web (/app/base/deployment.yaml): spec.replcias: unknown field",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in error:\n%v", expected, err)
+		}
+	}
+}
+
+func TestSchemaValidationWarns(t *testing.T) {
+	var buf bytes.Buffer
+	th := kusttest_test.MakeHarness(t)
+	writeSchemaValidationFiles(th)
+	opts := th.MakeDefaultOptions()
+	opts.Warner = types.NewWriterWarner(&buf)
+	opts.SchemaValidation = &krusty.SchemaValidation{
+		SchemaLocations:      []string{"/schemas"},
+		IgnoreMissingSchemas: true,
+	}
+	m := th.Run("/app/overlay", opts)
+	if m.Size() != 2 {
+		t.Fatalf("expected 2 resources, got %d", m.Size())
+	}
+	out := buf.String()
+	if !strings.Contains(out, "spec.replica: unknown field") ||
+		strings.Contains(out, "no schema found") {
+		t.Fatalf("unexpected warnings:\n%s", out)
+	}
+}
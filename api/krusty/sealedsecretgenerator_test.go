@@ -0,0 +1,240 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// makeSealingCert returns a new private key, and
+// a PEM encoded certificate for its public key.
+func makeSealingCert(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// unseal decrypts a value sealed with the given label,
+// as the sealed secrets controller does.
+func unseal(t *testing.T, key *rsa.PrivateKey, sealed string, label string) string {
+	b, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, b[2:2+n], []byte(label))
+	if err != nil {
+		t.Fatalf("unsealing with label %q: %v", label, err)
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := aead.Open(nil, make([]byte, aead.NonceSize()), b[2+n:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(plain)
+}
+
+func TestSealedSecretGenerator(t *testing.T) {
+	key, cert := makeSealingCert(t)
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/cert.pem", cert)
+	th.WriteF("/app/password.txt", "hunter2")
+	th.WriteK("/app", `
+generatorOptions:
+  labels:
+    app: web
+sealedSecretGenerator:
+- name: db
+  namespace: prod
+  cert: cert.pem
+  literals:
+  - user=admin
+  files:
+  - password.txt
+- name: shared
+  namespace: prod
+  cert: cert.pem
+  scope: namespace-wide
+  literals:
+  - token=abc
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	if m.Size() != 2 {
+		t.Fatalf("expected 2 resources, got %d", m.Size())
+	}
+	for _, tc := range []struct {
+		name     string
+		label    string
+		expected map[string]string
+	}{
+		{"db", "prod/db", map[string]string{"user": "admin", "password.txt": "hunter2"}},
+		{"shared", "prod", map[string]string{"token": "abc"}},
+	} {
+		var found bool
+		for _, r := range m.Resources() {
+			if r.GetName() != tc.name {
+				continue
+			}
+			found = true
+			obj := r.Map()
+			if obj["kind"] != "SealedSecret" {
+				t.Fatalf("expected a SealedSecret, got %v", obj["kind"])
+			}
+			spec := obj["spec"].(map[string]interface{})
+			data := spec["encryptedData"].(map[string]interface{})
+			if len(data) != len(tc.expected) {
+				t.Fatalf("%s: expected %d values, got %v", tc.name, len(tc.expected), data)
+			}
+			for k, v := range tc.expected {
+				if actual := unseal(t, key, data[k].(string), tc.label); actual != v {
+					t.Fatalf("%s: expected %s=%q, got %q", tc.name, k, v, actual)
+				}
+			}
+			template := spec["template"].(map[string]interface{})
+			meta := template["metadata"].(map[string]interface{})
+			if meta["name"] != tc.name || template["type"] != "Opaque" {
+				t.Fatalf("%s: unexpected template %v", tc.name, template)
+			}
+			if r.GetLabels()["app"] != "web" {
+				t.Fatalf("%s: expected the generator labels, got %v", tc.name, r.GetLabels())
+			}
+		}
+		if !found {
+			t.Fatalf("expected a SealedSecret named %s, without a hash suffix", tc.name)
+		}
+	}
+}
+
+func TestSealedSecretGeneratorNeedsNamespace(t *testing.T) {
+	_, cert := makeSealingCert(t)
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/cert.pem", cert)
+	th.WriteK("/app", `
+sealedSecretGenerator:
+- name: db
+  cert: cert.pem
+  literals:
+  - user=admin
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(), "needs a namespace") {
+		t.Fatalf("expected a missing namespace error, got %v", err)
+	}
+}
+
+func TestSealedSecretGeneratorRenamed(t *testing.T) {
+	_, cert := makeSealingCert(t)
+	for _, tc := range []struct {
+		name          string
+		kustomization string
+		expectedErr   string
+		expectedName  string
+	}{
+		{
+			name: "strict-name-prefix",
+			kustomization: `
+namePrefix: dev-
+sealedSecretGenerator:
+- name: db
+  namespace: prod
+  cert: cert.pem
+  literals:
+  - user=admin
+`,
+			expectedErr: "sealed to name db, but it's changed to dev-db",
+		},
+		{
+			name: "strict-namespace",
+			kustomization: `
+namespace: dev
+sealedSecretGenerator:
+- name: db
+  namespace: prod
+  cert: cert.pem
+  literals:
+  - user=admin
+`,
+			expectedErr: "sealed to namespace prod, but it's changed to dev",
+		},
+		{
+			name: "namespace-wide-name-prefix",
+			kustomization: `
+namePrefix: dev-
+sealedSecretGenerator:
+- name: db
+  namespace: prod
+  cert: cert.pem
+  scope: namespace-wide
+  literals:
+  - user=admin
+`,
+			expectedName: "dev-db",
+		},
+		{
+			name: "cluster-wide-namespace",
+			kustomization: `
+namespace: dev
+nameSuffix: -v1
+sealedSecretGenerator:
+- name: db
+  cert: cert.pem
+  scope: cluster-wide
+  literals:
+  - user=admin
+`,
+			expectedName: "db-v1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			th.WriteF("/app/cert.pem", cert)
+			th.WriteK("/app", tc.kustomization)
+			if tc.expectedErr != "" {
+				err := th.RunWithErr("/app", th.MakeDefaultOptions())
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected an error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			m := th.Run("/app", th.MakeDefaultOptions())
+			if m.Size() != 1 || m.Resources()[0].GetName() != tc.expectedName {
+				t.Fatalf("expected a SealedSecret named %s, got %v", tc.expectedName, m.AllIds())
+			}
+		})
+	}
+}
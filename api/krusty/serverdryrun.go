@@ -0,0 +1,147 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// ServerDryRun configures submitting each resource of the
+// build output to the live cluster in a server-side apply
+// dry-run, with the kubectl program, so that what admission
+// would reject, and fields owned by other field managers,
+// are found before the output is applied.  The problems found
+// are logged as warnings, and fail the build if FailOnError
+// is true.
+type ServerDryRun struct {
+	// Kubeconfig is the kubeconfig file to use.
+	// kubectl's default if empty.
+	Kubeconfig string
+
+	// Context is the kubeconfig context to use.
+	// The current context if empty.
+	Context string
+
+	// FieldManager is the field manager the output would be
+	// applied as.  "kustomize" if empty.
+	FieldManager string
+
+	// When true, the build fails if any problem is found.
+	FailOnError bool
+}
+
+// dryRunProblem is the rejection of a resource by a dry-run.
+type dryRunProblem struct {
+	resource string
+	conflict bool
+	message  string
+}
+
+func (p dryRunProblem) String() string {
+	return p.resource + ": " + p.message
+}
+
+// dryRun submits each resource of m to the cluster, giving
+// the problems found to warn, unless they fail the build.
+func (d *ServerDryRun) dryRun(m resmap.ResMap, warn types.Warner) error {
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		return errors.Wrap(err, "a server-side dry-run needs kubectl")
+	}
+	var problems []string
+	for _, r := range m.Resources() {
+		p, err := d.submit(kubectl, r)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			continue
+		}
+		if !d.FailOnError {
+			code := types.WarningDryRunRejected
+			if p.conflict {
+				code = types.WarningDryRunConflict
+			}
+			warn(types.Warning{
+				Code:     code,
+				Message:  p.message,
+				Resource: p.resource,
+			})
+		}
+		problems = append(problems, p.String())
+	}
+	if d.FailOnError && len(problems) > 0 {
+		return fmt.Errorf(
+			"%d resources failed a server-side dry-run:\n  %s",
+			len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// submit applies r to the cluster in a dry-run, returning
+// the problem kubectl reported, if any.
+func (d *ServerDryRun) submit(kubectl string, r *resource.Resource) (*dryRunProblem, error) {
+	y, err := r.AsYAML()
+	if err != nil {
+		return nil, err
+	}
+	fieldManager := d.FieldManager
+	if fieldManager == "" {
+		fieldManager = "kustomize"
+	}
+	args := []string{
+		"apply", "--server-side", "--dry-run=server",
+		"--field-manager", fieldManager, "--output", "name", "--filename", "-",
+	}
+	if d.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", d.Kubeconfig)
+	}
+	if d.Context != "" {
+		args = append(args, "--context", d.Context)
+	}
+	cmd := exec.Command(kubectl, args...)
+	var stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(y)
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		return nil, nil
+	}
+	if _, exited := err.(*exec.ExitError); !exited {
+		return nil, errors.Wrap(err, "kubectl apply")
+	}
+	message := dryRunMessage(stderr.String())
+	if message == "" {
+		message = err.Error()
+	}
+	return &dryRunProblem{
+		resource: r.CurId().String(),
+		conflict: strings.HasPrefix(message, "Apply failed with"),
+		message:  message,
+	}, nil
+}
+
+// dryRunMessage returns the error kubectl wrote on one line,
+// without its advice on resolving conflicts.
+func dryRunMessage(stderr string) string {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Please review the fields above") {
+			break
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimPrefix(strings.Join(lines, " "), "error: ")
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// fakeDryRunKubectl puts a kubectl program on the PATH which
+// rejects the Deployment named api, finds a conflict in the
+// one named web, and accepts all else.
+func fakeDryRunKubectl(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake kubectl is a shell script")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-kubectl-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(bin) })
+	script := `#!/bin/sh
+if [ "$*" != "apply --server-side --dry-run=server --field-manager kustomize --output name --filename - --context live" ]; then
+  echo "unexpected arguments: $*" >&2
+  exit 2
+fi
+in=$(cat)
+case "$in" in
+*"name: api"*)
+  echo 'The Deployment "api" is invalid: spec.replicas: Invalid value: -1: must be greater than or equal to 0' >&2
+  exit 1;;
+*"name: web"*)
+  cat >&2 <<'END'
+error: Apply failed with 1 conflict: conflict with "helm" using apps/v1: .spec.replicas
+Please review the fields above--they currently have other managers. Here
+are the ways you can resolve this warning:
+* If you intend to manage all of these fields, please re-run the apply
+  command with the --force-conflicts flag.
+END
+  exit 1;;
+esac
+echo deployment.apps/worker serverside-applied
+`
+	if err = ioutil.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+}
+
+func writeDryRunApp(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+resources:
+- deployments.yaml
+`)
+	th.WriteF("/app/deployments.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: -1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+`)
+}
+
+func TestServerDryRunWarns(t *testing.T) {
+	fakeDryRunKubectl(t)
+	th := kusttest_test.MakeHarness(t)
+	writeDryRunApp(th)
+	var warnings []types.Warning
+	opts := th.MakeDefaultOptions()
+	opts.ServerDryRun = &krusty.ServerDryRun{Context: "live"}
+	opts.Warner = func(w types.Warning) {
+		warnings = append(warnings, w)
+	}
+	th.Run("/app", opts)
+	expected := []types.Warning{
+		{
+			Code: types.WarningDryRunRejected,
+			Message: `The Deployment "api" is invalid: spec.replicas: ` +
+				`Invalid value: -1: must be greater than or equal to 0`,
+			Resource: "apps_v1_Deployment|~X|api",
+		},
+		{
+			Code: types.WarningDryRunConflict,
+			Message: `Apply failed with 1 conflict: ` +
+				`conflict with "helm" using apps/v1: .spec.replicas`,
+			Resource: "apps_v1_Deployment|~X|web",
+		},
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected warnings\n%v\ngot\n%v", expected, warnings)
+	}
+}
+
+func TestServerDryRunFails(t *testing.T) {
+	fakeDryRunKubectl(t)
+	th := kusttest_test.MakeHarness(t)
+	writeDryRunApp(th)
+	opts := th.MakeDefaultOptions()
+	opts.ServerDryRun = &krusty.ServerDryRun{Context: "live", FailOnError: true}
+	err := th.RunWithErr("/app", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := `2 resources failed a server-side dry-run:
+  apps_v1_Deployment|~X|api: The Deployment "api" is invalid: spec.replicas: Invalid value: -1: must be greater than or equal to 0
+  apps_v1_Deployment|~X|web: Apply failed with 1 conflict: conflict with "helm" using apps/v1: .spec.replicas`
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+}
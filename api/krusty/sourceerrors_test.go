@@ -0,0 +1,54 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestErrorsLocateKustomizationSyntax(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/kustomization.yaml", `
+resources:
+- service.yaml
+namePrefix: [prod-
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "/app/kustomization.yaml:4: error converting YAML to JSON: " +
+		"yaml: line 4: did not find expected ',' or ']'"
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+}
+
+func TestErrorsLocatePluginConfigs(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+transformers:
+- prefix.yaml
+`)
+	th.WriteF("/app/prefix.yaml", `
+apiVersion: builtin
+kind: PrefixSuffixTransformer
+metadata:
+  name: prod
+prefix:
+- prod-
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "/app/prefix.yaml: ~G_builtin_PrefixSuffixTransformer|~X|prod: " +
+		"plugin fails configuration: error unmarshaling JSON: while decoding JSON: " +
+		"json: cannot unmarshal array into Go struct field .prefix of type string"
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+}
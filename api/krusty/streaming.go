@@ -0,0 +1,100 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/metrics"
+)
+
+// RunStreaming performs a kustomization like Run, but gives
+// each resource to emit as soon as it's final, in the order
+// of the kustomization, rather than returning them all.
+//
+// Resources are final once the vars and name references of
+// the whole build are resolved; the managed-by label and
+// provenance annotations are then added to each resource just
+// before it's emitted, and the Kustomizer holds no reference
+// to a resource once it's emitted, so the build output is
+// never held in full, either as resources or serialized.
+//
+// Sorting the resources, verifying determinism and server-side
+// dry-runs need the whole build output, so RunStreaming fails
+// if the options ask for any of them.  Schema validation is done
+// before the first resource is emitted.
+func (b *Kustomizer) RunStreaming(
+	path string, emit func(*resource.Resource) error) error {
+	return b.meteredStream(path, nil, emit)
+}
+
+// RunStreamingWithInput performs a kustomization like
+// RunStreaming, treating the resources in the YAML input as
+// RunWithInput does.
+func (b *Kustomizer) RunStreamingWithInput(
+	path string, input []byte, emit func(*resource.Resource) error) error {
+	if input == nil {
+		input = []byte{}
+	}
+	return b.meteredStream(path, input, emit)
+}
+
+// meteredStream streams, recording the run to the Metrics option.
+func (b *Kustomizer) meteredStream(
+	path string, input []byte, emit func(*resource.Resource) error) error {
+	start := time.Now()
+	err := b.stream(path, input, emit)
+	metrics.RecordRun(b.options.Metrics,
+		metrics.BuildsTotal, metrics.BuildDurationSeconds, start, err)
+	return err
+}
+
+func (b *Kustomizer) stream(
+	path string, input []byte, emit func(*resource.Resource) error) error {
+	switch {
+	case b.options.DoLegacyResourceSort || b.options.ResourceOrder != nil:
+		return fmt.Errorf("resources can't be streamed when they're sorted")
+	case b.options.VerifyDeterminism:
+		return fmt.Errorf("resources can't be streamed when verifying determinism")
+	case b.options.ServerDryRun != nil:
+		return fmt.Errorf("resources can't be streamed with a server-side dry-run")
+	}
+	m, ldr, err := b.accumulate(path, input, nil)
+	if ldr != nil {
+		defer ldr.Cleanup()
+	}
+	if err != nil {
+		return err
+	}
+	if b.options.SchemaValidation != nil {
+		err = b.timePhase("validate", func() error {
+			return b.options.SchemaValidation.validate(b.fSys, m, ldr.Root(), b.warn)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	finish := b.finisher(ldr.Root())
+	resources := m.Resources()
+	m.Clear()
+	for i := range resources {
+		if finish != nil {
+			one := resmap.New()
+			if err = one.Append(resources[i]); err != nil {
+				return err
+			}
+			if err = finish(one); err != nil {
+				return err
+			}
+		}
+		if err = emit(resources[i]); err != nil {
+			return err
+		}
+		resources[i] = nil
+	}
+	return nil
+}
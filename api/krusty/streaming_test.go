@@ -0,0 +1,97 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeStreamingApp(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+namePrefix: p-
+resources:
+- deployment.yaml
+- service.yaml
+vars:
+- name: SERVICE
+  objref:
+    kind: Service
+    name: web
+    apiVersion: v1
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+        args: ["--service=$(SERVICE)"]
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+}
+
+func TestRunStreaming(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeStreamingApp(th)
+	options := th.MakeDefaultOptions()
+	options.DoLegacyResourceSort = false
+	m := resmap.New()
+	err := krusty.MakeKustomizer(th.GetFSys(), &options).RunStreaming(
+		"/app", func(r *resource.Resource) error {
+			return m.Append(r)
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: p-web
+spec:
+  template:
+    spec:
+      containers:
+      - args:
+        - --service=p-web
+        image: web
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: p-web
+`)
+}
+
+func TestRunStreamingSorted(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeStreamingApp(th)
+	options := th.MakeDefaultOptions()
+	options.DoLegacyResourceSort = true
+	err := krusty.MakeKustomizer(th.GetFSys(), &options).RunStreaming(
+		"/app", func(r *resource.Resource) error {
+			t.Fatalf("unexpected resource %s", r.CurId())
+			return nil
+		})
+	if err == nil || !strings.Contains(err.Error(), "sorted") {
+		t.Fatalf("expected error about sorting, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestUnknownKustomizationFieldsAreLocated(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+namePrefx: x-
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"/app/kustomization.yaml:7:1: namePrefx: unknown field") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStrictKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteK("/app/base", `
+resources:
+- service.yaml
+`)
+	th.WriteK("/app/prod", `
+bases:
+- ../base
+nameprefix: prod-
+`)
+	opts := th.MakeDefaultOptions()
+	th.AssertActualEqualsExpected(th.Run("/app/prod", opts), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: prod-web
+`)
+	opts.Strict = true
+	err := th.RunWithErr("/app/prod", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{
+		"/app/prod/kustomization.yaml:5:1: bases: deprecated; use resources instead",
+		"/app/prod/kustomization.yaml:7:1: nameprefix: should be spelled namePrefix",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in error: %v", expected, err)
+		}
+	}
+}
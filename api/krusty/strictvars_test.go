@@ -0,0 +1,124 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"reflect"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeVarsApp(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+- service.yaml
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: web
+- name: UNUSED
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: web
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+        command:
+        - serve
+        - --upstream=$(SERVICE)
+        - --port=$(PORT)
+        - --cache=$(CACHE)
+        env:
+        - name: PORT
+          value: "8080"
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+}
+
+func TestUnresolvedVarsAreWarned(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeVarsApp(th)
+	var warnings []types.Warning
+	opts := th.MakeDefaultOptions()
+	opts.Warner = func(w types.Warning) {
+		warnings = append(warnings, w)
+	}
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - command:
+        - serve
+        - --upstream=web
+        - --port=$(PORT)
+        - --cache=$(CACHE)
+        env:
+        - name: PORT
+          value: "8080"
+        image: web
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	expected := []types.Warning{
+		{
+			Code:    types.WarningUnusedVars,
+			Message: "well-defined vars that were never replaced: UNUSED",
+		},
+		{
+			Code:     types.WarningUnresolvedVars,
+			Message:  "$(CACHE) refers to no var",
+			Resource: "apps_v1_Deployment|~X|web",
+		},
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected warnings\n%v\ngot\n%v", expected, warnings)
+	}
+}
+
+func TestStrictVars(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeVarsApp(th)
+	opts := th.MakeDefaultOptions()
+	opts.StrictVars = true
+	err := th.RunWithErr("/app", opts)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := `2 problems with vars:
+  well-defined vars that were never replaced: UNUSED
+  apps_v1_Deployment|~X|web: $(CACHE) refers to no var`
+	if err.Error() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, err.Error())
+	}
+}
@@ -22,7 +22,7 @@ images:
 - name: myprivaterepohostname:1234/my/image
   newTag: v1.0.1
 - name: foobar
-  digest: sha256:24a0c4b4
+  digest: sha256:24a0c4b400000000000000000000000000000000000000000000000000000000
 - name: alpine
   newName: myprivaterepohostname:1234/my/cool-alpine
 - name: gcr.io:8080/my-project/my-cool-app
@@ -32,7 +32,7 @@ images:
   newTag: v3
 - name: docker
   newName: my-docker
-  digest: sha256:25a0d4b4
+  digest: sha256:25a0d4b400000000000000000000000000000000000000000000000000000000
 `)
 	th.WriteF("/app/base/deploy1.yaml", `
 group: apps
@@ -192,7 +192,7 @@ spec:
       containers:
       - image: nginx:v2
         name: ngnix
-      - image: foobar@sha256:24a0c4b4
+      - image: foobar@sha256:24a0c4b400000000000000000000000000000000000000000000000000000000
         name: repliaced-with-digest
       - image: my-postgres:v3
         name: postgresdb
@@ -252,7 +252,7 @@ images:
 - name: myprivaterepohostname:1234/my/image
   newTag: v1.0.1
 - name: foobar
-  digest: sha256:24a0c4b4
+  digest: sha256:24a0c4b400000000000000000000000000000000000000000000000000000000
 - name: alpine
   newName: myprivaterepohostname:1234/my/cool-alpine
 - name: gcr.io:8080/my-project/my-cool-app
@@ -262,7 +262,7 @@ images:
   newTag: v3
 - name: docker
   newName: my-docker
-  digest: sha256:25a0d4b4
+  digest: sha256:25a0d4b400000000000000000000000000000000000000000000000000000000
 `)
 	th.WriteF("/app/base/custom.yaml", `
 kind: customKind
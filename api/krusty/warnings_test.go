@@ -0,0 +1,114 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestWarner(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployments.yaml
+- empty.yaml
+`)
+	th.WriteF("/app/base/deployments.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec: {}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  labels:
+    app: web
+spec: {}
+`)
+	th.WriteF("/app/base/empty.yaml", `
+# Nothing yet.
+`)
+	th.WriteK("/app/prod", `
+bases:
+- ../base
+patches:
+- target:
+    kind: Deployment
+  patch: |-
+    - op: add
+      path: /spec/replicas
+      value: 3
+- target:
+    kind: Job
+  patch: |-
+    - op: add
+      path: /spec/parallelism
+      value: 3
+- target:
+    kind: Deployment
+    labelSelector: app=web
+  patch: |-
+    - op: add
+      path: /spec/paused
+      value: true
+vars:
+- name: WORKER
+  objref:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: worker
+`)
+	var mu sync.Mutex
+	var warnings []types.Warning
+	opts := th.MakeDefaultOptions()
+	opts.Warner = func(w types.Warning) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, w)
+	}
+	th.Run("/app/prod", opts)
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Code < warnings[j].Code
+	})
+	expected := []types.Warning{
+		{
+			Code:     types.WarningDeprecatedField,
+			Message:  "bases: deprecated; use resources instead",
+			Location: "/app/prod/kustomization.yaml:5:1",
+		},
+		{
+			Code:     types.WarningEmptyFile,
+			Message:  "no resources in file",
+			Location: "/app/base/empty.yaml",
+		},
+		{
+			Code:     types.WarningPatchMatchedBroadly,
+			Message:  "the target of a patch, kind=Deployment, selects all 2 resources of its kind",
+			Location: "/app/prod",
+		},
+		{
+			Code:     types.WarningPatchMatchedNothing,
+			Message:  "the target of a patch, kind=Job, selects no resources",
+			Location: "/app/prod",
+		},
+		{
+			Code:    types.WarningUnusedVars,
+			Message: "well-defined vars that were never replaced: WORKER",
+		},
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Fatalf("expected warnings\n%v\ngot\n%v", expected, warnings)
+	}
+}
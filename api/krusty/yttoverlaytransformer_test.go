@@ -0,0 +1,166 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// fakeYtt puts a ytt program on the PATH which scales
+// the resources in its first file to 3 replicas, and adds
+// a ConfigMap counting them, holding its data value.
+func fakeYtt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ytt is a shell script")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-ytt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(bin) })
+	script := `#!/bin/sh
+if [ "$1 $3 $5" != "-f -f --data-value" ] || ! grep -q overlay/match "$4"; then
+  echo "unexpected arguments: $*" >&2
+  exit 1
+fi
+sed 's/replicas: 1/replicas: 3/' "$2"
+echo ---
+echo "apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ytt
+data:
+  count: \"$(grep -c '^kind:' "$2")\"
+  value: $6"
+`
+	if err = ioutil.WriteFile(filepath.Join(bin, "ytt"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+}
+
+func TestYttOverlayTransformer(t *testing.T) {
+	fakeYtt(t)
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: x-
+resources:
+- resources.yaml
+transformers:
+- ytt.yaml
+`)
+	th.WriteF("/app/ytt.yaml", `
+apiVersion: builtin
+kind: YttOverlayTransformer
+metadata:
+  name: scale
+overlays:
+- overlays/scale.yaml
+dataValues:
+  env: prod
+target:
+  kind: Deployment
+`)
+	th.WriteF("/app/overlays/scale.yaml", `
+#@ load("@ytt:overlay", "overlay")
+#@overlay/match by=overlay.subset({"kind": "Deployment"})
+---
+spec:
+  replicas: 3
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    team: web
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	options := th.MakeDefaultOptions()
+	options.PluginConfig.FnpLoadingOptions.EnableYtt = true
+	m := th.Run("/app", options)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    team: web
+  name: x-web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: x-web
+spec:
+  replicas: 1
+---
+apiVersion: v1
+data:
+  count: "1"
+  value: env=prod
+kind: ConfigMap
+metadata:
+  name: ytt
+`)
+}
+
+func TestYttOverlayTransformerNeedsOverlays(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+transformers:
+- ytt.yaml
+`)
+	th.WriteF("/app/ytt.yaml", `
+apiVersion: builtin
+kind: YttOverlayTransformer
+metadata:
+  name: scale
+`)
+	options := th.MakeDefaultOptions()
+	options.PluginConfig.FnpLoadingOptions.EnableYtt = true
+	err := th.RunWithErr("/app", options)
+	if err == nil || !strings.Contains(err.Error(), "must specify at least one overlay") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestYttOverlayTransformerDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+transformers:
+- ytt.yaml
+`)
+	th.WriteF("/app/ytt.yaml", `
+apiVersion: builtin
+kind: YttOverlayTransformer
+metadata:
+  name: scale
+overlays:
+- overlays/scale.yaml
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(), "--enable-ytt") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -6,15 +6,16 @@ package loader
 import (
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 // fileLoader is a kustomization's interface to files.
@@ -126,12 +127,28 @@ func (fl *fileLoader) Root() string {
 	return fl.root.String()
 }
 
+// CacheKey returns a string identifying the root of the loader.
+// Unlike Root, it's the same for each clone of a given repository
+// and ref, so that results can be cached across clones.
+func (fl *fileLoader) CacheKey() string {
+	repo := fl.containingRepo()
+	if repo == nil {
+		return fl.Root()
+	}
+	rel, err := filepath.Rel(repo.Dir.String(), fl.Root())
+	if err != nil {
+		return fl.Root()
+	}
+	return repo.CloneSpec() + "?ref=" + repo.Ref + "//" + filepath.ToSlash(rel)
+}
+
 func newLoaderOrDie(
 	lr LoadRestrictorFunc,
 	fSys filesys.FileSystem, path string) *fileLoader {
 	root, err := demandDirectoryRoot(fSys, path)
 	if err != nil {
-		log.Fatalf("unable to make loader at '%s'; %v", path, err)
+		log.Error("unable to make loader", "path", path, "error", err)
+		os.Exit(1)
 	}
 	return newLoaderAtConfirmedDir(
 		lr, root, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
@@ -187,6 +204,7 @@ func (fl *fileLoader) New(path string) (ifc.Loader, error) {
 	repoSpec, errGit := git.NewRepoSpecFromUrl(path)
 	if errGit == nil {
 		// Treat this as git repo clone request.
+		log.Debug("loading remote base", "url", path)
 		if errGit := fl.errIfRepoCycle(repoSpec); errGit != nil {
 			return nil, errGit
 		}
@@ -197,6 +215,9 @@ func (fl *fileLoader) New(path string) (ifc.Loader, error) {
 	if filepath.IsAbs(path) {
 		return nil, fmt.Errorf("new root '%s' cannot be absolute", path)
 	}
+	if err := fl.checkOutInRepo(path); err != nil {
+		return nil, err
+	}
 	root, errDir := demandDirectoryRoot(fl.fSys, fl.root.Join(path))
 	if errDir != nil {
 		return nil, fmt.Errorf("Error loading %s with git: %v, dir: %v, get: %v", path, errGit, errDir, errGet)
@@ -266,6 +287,22 @@ func (fl *fileLoader) errIfGitContainmentViolation(
 	return nil
 }
 
+// checkOutInRepo checks out the directory at path,
+// relative to the root, if the root is in a sparse
+// clone of a git repo.
+func (fl *fileLoader) checkOutInRepo(path string) error {
+	repo := fl.containingRepo()
+	if repo == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(repo.Dir.String(), fl.root.Join(path))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		// errIfGitContainmentViolation reports this.
+		return nil
+	}
+	return repo.CheckOut(rel)
+}
+
 // Looks back through referrers for a git repo, returning nil
 // if none found.
 func (fl *fileLoader) containingRepo() *git.RepoSpec {
@@ -278,19 +315,67 @@ func (fl *fileLoader) containingRepo() *git.RepoSpec {
 	return fl.referrer.containingRepo()
 }
 
+// CycleError reports a kustomization which includes itself,
+// through its bases and components, or includes a directory
+// containing itself.
+type CycleError struct {
+	// Chain holds the roots of the kustomizations included,
+	// from the one included again, or contained, to the one
+	// including it, and then the root it included.  Remote
+	// roots are given by their URLs.
+	Chain []string
+
+	// Contains is true if the root last included isn't
+	// the first root of the Chain, but contains it.
+	Contains bool
+}
+
+func (e *CycleError) Error() string {
+	chain := strings.Join(e.Chain, " -> ")
+	if e.Contains {
+		return fmt.Sprintf(
+			"cycle detected: candidate root '%s' contains visited root '%s': %s",
+			e.Chain[len(e.Chain)-1], e.Chain[0], chain)
+	}
+	return "cycle detected: " + chain
+}
+
+// inclusionChain returns the names of the loaders from
+// ancestor, a referrer of fl, down to fl.
+func (fl *fileLoader) inclusionChain(ancestor *fileLoader) []string {
+	var chain []string
+	for l := fl; l != nil; l = l.referrer {
+		chain = append([]string{l.name()}, chain...)
+		if l == ancestor {
+			break
+		}
+	}
+	return chain
+}
+
+// name returns the URL of the root of fl if it's
+// a cloned repo, and the root otherwise.
+func (fl *fileLoader) name() string {
+	if fl.repoSpec != nil {
+		return fl.repoSpec.Raw()
+	}
+	return fl.Root()
+}
+
 // errIfArgEqualOrHigher tests whether the argument,
 // is equal to or above the root of any ancestor.
 func (fl *fileLoader) errIfArgEqualOrHigher(
 	candidateRoot filesys.ConfirmedDir) error {
-	if fl.root.HasPrefix(candidateRoot) {
-		return fmt.Errorf(
-			"cycle detected: candidate root '%s' contains visited root '%s'",
-			candidateRoot, fl.root)
-	}
-	if fl.referrer == nil {
-		return nil
+	for l := fl; l != nil; l = l.referrer {
+		if !l.root.HasPrefix(candidateRoot) {
+			continue
+		}
+		return &CycleError{
+			Chain:    append(fl.inclusionChain(l), candidateRoot.String()),
+			Contains: l.root != candidateRoot,
+		}
 	}
-	return fl.referrer.errIfArgEqualOrHigher(candidateRoot)
+	return nil
 }
 
 // TODO(monopole): Distinguish branches?
@@ -299,16 +384,15 @@ func (fl *fileLoader) errIfArgEqualOrHigher(
 // path but a different tag?
 func (fl *fileLoader) errIfRepoCycle(newRepoSpec *git.RepoSpec) error {
 	// TODO(monopole): Use parsed data instead of Raw().
-	if fl.repoSpec != nil &&
-		strings.HasPrefix(fl.repoSpec.Raw(), newRepoSpec.Raw()) {
-		return fmt.Errorf(
-			"cycle detected: URI '%s' referenced by previous URI '%s'",
-			newRepoSpec.Raw(), fl.repoSpec.Raw())
-	}
-	if fl.referrer == nil {
-		return nil
+	for l := fl; l != nil; l = l.referrer {
+		if l.repoSpec != nil &&
+			strings.HasPrefix(l.repoSpec.Raw(), newRepoSpec.Raw()) {
+			return &CycleError{
+				Chain: append(fl.inclusionChain(l), newRepoSpec.Raw()),
+			}
+		}
 	}
-	return fl.referrer.errIfRepoCycle(newRepoSpec)
+	return nil
 }
 
 // Load returns the content of file at the given path,
@@ -322,6 +406,7 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 		} else {
 			hc = &http.Client{}
 		}
+		log.Debug("loading remote file", "url", path)
 		resp, err := hc.Get(path)
 		if err != nil {
 			return nil, err
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -282,6 +283,31 @@ func doSanityChecksAndDropIntoBase(
 	return l
 }
 
+func TestLoaderCycleChain(t *testing.T) {
+	l1, err := makeLoader().New("foo/project/subdir1")
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	l2, err := l1.New("../subdir2")
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	for path, expected := range map[string]string{
+		"../subdir1": "cycle detected: /foo/project/subdir1 -> " +
+			"/foo/project/subdir2 -> /foo/project/subdir1",
+		"..": "cycle detected: candidate root '/foo/project' contains " +
+			"visited root '/foo/project/subdir2': /foo/project/subdir2 -> /foo/project",
+	} {
+		_, err = l2.New(path)
+		if _, ok := err.(*CycleError); !ok {
+			t.Fatalf("%s: expected a CycleError, got %v", path, err)
+		}
+		if err.Error() != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, err.Error())
+		}
+	}
+}
+
 func TestRestrictionRootOnlyInRealLoader(t *testing.T) {
 	dir, fSys, err := commonSetupForLoaderRestrictionTest()
 	if err != nil {
@@ -672,3 +698,53 @@ func TestLoaderHTTP(t *testing.T) {
 		}
 	}
 }
+
+// TestRemoteTargetsConcurrently tests that remote targets may be
+// fetched concurrently, as bases are accumulated, which the race
+// detector checks.
+func TestRemoteTargetsConcurrently(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-remote-targets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var srcs []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		src := filepath.Join(dir, name)
+		if err = os.MkdirAll(src, 0700); err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(src, "kustomization.yaml"),
+			[]byte("namePrefix: "+name+"-\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srcs = append(srcs, "file://"+src)
+	}
+
+	specs := make([]*remoteTargetSpec, len(srcs))
+	errs := make([]error, len(srcs))
+	var wg sync.WaitGroup
+	for i := range srcs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			specs[i] = &remoteTargetSpec{Raw: srcs[i]}
+			errs[i] = getRemoteTarget(specs[i])
+		}(i)
+	}
+	wg.Wait()
+	for i := range srcs {
+		if errs[i] != nil {
+			t.Fatalf("unexpected error getting %s: %v", srcs[i], errs[i])
+		}
+		defer os.RemoveAll(filepath.Dir(specs[i].Dir.String()))
+		b, err := ioutil.ReadFile(filepath.Join(specs[i].Dir.String(), "kustomization.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(b), "namePrefix: "+filepath.Base(srcs[i])) {
+			t.Fatalf("unexpected kustomization from %s: %s", srcs[i], b)
+		}
+	}
+}
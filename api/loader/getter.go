@@ -80,6 +80,7 @@ func getRemoteTarget(rs *remoteTargetSpec) error {
 			new(getter.GitDetector),
 			new(getter.BitBucketDetector),
 		},
+		Getters: objectStorageGetters(),
 		Options: opts,
 	}
 	return client.Get()
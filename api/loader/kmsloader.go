@@ -0,0 +1,221 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/yaml"
+)
+
+// The cloud KMS which encrypted a KMS envelope.
+const (
+	KmsProviderGCP   = "gcp"
+	KmsProviderAWS   = "aws"
+	KmsProviderAzure = "azure"
+)
+
+// KmsEnvelope is a file encrypted with a cloud KMS:
+//
+//	kms:
+//	  provider: gcp
+//	  key: projects/p/locations/global/keyRings/r/cryptoKeys/k
+//	  dataKey: CiQA...
+//	ciphertext: 3q2+...
+//
+// If DataKey is empty, Ciphertext was encrypted by the KMS
+// itself, which limits it to the few kilobytes the KMS
+// accepts.  Otherwise DataKey is a 256 bit AES key encrypted
+// by the KMS, and Ciphertext is the file encrypted with it
+// with AES-GCM, prefixed with the nonce.
+type KmsEnvelope struct {
+	Kms struct {
+		// Provider is gcp, aws or azure.
+		Provider string `json:"provider"`
+
+		// Key is the resource name of the key in GCP, the
+		// key ID or ARN in AWS, which is optional there, and
+		// the key identifier URL, including the version,
+		// in Azure Key Vault.
+		Key string `json:"key,omitempty"`
+
+		// Algorithm is the Azure Key Vault encryption
+		// algorithm; RSA-OAEP-256 by default.
+		Algorithm string `json:"algorithm,omitempty"`
+
+		// DataKey is the base64 encoded, encrypted data key.
+		DataKey string `json:"dataKey,omitempty"`
+	} `json:"kms"`
+
+	// Ciphertext is the base64 encoded, encrypted file.
+	Ciphertext string `json:"ciphertext"`
+}
+
+// kmsLoader is a Loader which decrypts KMS envelopes as
+// it loads them, using the CLI of the cloud which holds
+// the key, and so its ambient credentials.  Other files are
+// returned unchanged.
+type kmsLoader struct {
+	ifc.Loader
+}
+
+// NewKmsLoader returns a Loader decrypting the KMS
+// envelopes loaded by ldr, and by the loaders made from it.
+func NewKmsLoader(ldr ifc.Loader) ifc.Loader {
+	if _, ok := ldr.(*kmsLoader); ok {
+		return ldr
+	}
+	return &kmsLoader{Loader: ldr}
+}
+
+// New implements ifc.Loader.
+func (l *kmsLoader) New(newRoot string) (ifc.Loader, error) {
+	ldr, err := l.Loader.New(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsLoader{Loader: ldr}, nil
+}
+
+// Load implements ifc.Loader.
+func (l *kmsLoader) Load(location string) ([]byte, error) {
+	b, err := l.Loader.Load(location)
+	if err != nil || !IsKmsEnvelope(b) {
+		return b, err
+	}
+	b, err = kmsDecrypt(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting %s", location)
+	}
+	return b, nil
+}
+
+// CacheKey returns the CacheKey of the wrapped loader, if
+// it has one, and its root otherwise.
+func (l *kmsLoader) CacheKey() string {
+	if k, ok := l.Loader.(interface{ CacheKey() string }); ok {
+		return k.CacheKey()
+	}
+	return l.Root()
+}
+
+// kmsEnvelopeFields match the top level fields of
+// KMS envelopes, in yaml or json.
+var kmsEnvelopeFields = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^kms:|"kms"\s*:\s*\{`),
+	regexp.MustCompile(`(?m)^ciphertext:|"ciphertext"\s*:`),
+}
+
+// IsKmsEnvelope returns true if b looks like a KmsEnvelope.
+func IsKmsEnvelope(b []byte) bool {
+	for _, re := range kmsEnvelopeFields {
+		if !re.Match(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func kmsDecrypt(b []byte) ([]byte, error) {
+	var e KmsEnvelope
+	if err := yaml.Unmarshal(b, &e); err != nil {
+		return nil, errors.Wrap(err, "reading KMS envelope")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(e.Ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding ciphertext")
+	}
+	if e.Kms.DataKey == "" {
+		return kmsDecryptWith(&e, ciphertext)
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(e.Kms.DataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding dataKey")
+	}
+	if dataKey, err = kmsDecryptWith(&e, dataKey); err != nil {
+		return nil, errors.Wrap(err, "decrypting dataKey")
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext shorter than its nonce")
+	}
+	nonce := ciphertext[:gcm.NonceSize()]
+	return gcm.Open(nil, nonce, ciphertext[gcm.NonceSize():], nil)
+}
+
+// kmsDecryptWith decrypts ciphertext with the KMS
+// and key of the envelope e.
+func kmsDecryptWith(e *KmsEnvelope, ciphertext []byte) ([]byte, error) {
+	switch e.Kms.Provider {
+	case KmsProviderGCP:
+		if e.Kms.Key == "" {
+			return nil, fmt.Errorf("gcp KMS envelope has no key")
+		}
+		return runKmsCLI(ciphertext, false,
+			"gcloud", "kms", "decrypt", "--key", e.Kms.Key,
+			"--ciphertext-file", "-", "--plaintext-file", "-")
+	case KmsProviderAWS:
+		args := []string{"kms", "decrypt",
+			"--ciphertext-blob", "fileb:///dev/stdin",
+			"--query", "Plaintext", "--output", "text"}
+		if e.Kms.Key != "" {
+			args = append(args, "--key-id", e.Kms.Key)
+		}
+		return runKmsCLI(ciphertext, true, "aws", args...)
+	case KmsProviderAzure:
+		if e.Kms.Key == "" {
+			return nil, fmt.Errorf("azure KMS envelope has no key")
+		}
+		algorithm := e.Kms.Algorithm
+		if algorithm == "" {
+			algorithm = "RSA-OAEP-256"
+		}
+		return runKmsCLI(nil, true,
+			"az", "keyvault", "key", "decrypt", "--id", e.Kms.Key,
+			"--algorithm", algorithm,
+			"--value", base64.StdEncoding.EncodeToString(ciphertext),
+			"--query", "result", "--output", "tsv")
+	default:
+		return nil, fmt.Errorf(
+			"unknown KMS provider '%s'; known providers: %v", e.Kms.Provider,
+			[]string{KmsProviderGCP, KmsProviderAWS, KmsProviderAzure})
+	}
+}
+
+// runKmsCLI runs program with args and stdin, and returns
+// its output, decoded from base64 if encoded is true.
+func runKmsCLI(stdin []byte, encoded bool, program string, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return nil, errors.Wrapf(err, "no '%s' program on path", program)
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: %s", program, strings.TrimSpace(stderr.String()))
+	}
+	if !encoded {
+		return out, nil
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+}
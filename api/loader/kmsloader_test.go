@@ -0,0 +1,141 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+func TestIsKmsEnvelope(t *testing.T) {
+	for content, expected := range map[string]bool{
+		"kms:\n  provider: aws\nciphertext: AAAA\n":    true,
+		`{"kms": {"provider": "aws"}, "ciphertext": ""}`: true,
+		"kms:\n  provider: aws\n":                       false,
+		"a: b\nciphertext: AAAA\n":                      false,
+		"apiVersion: v1\nkind: Secret\n":                false,
+	} {
+		if actual := IsKmsEnvelope([]byte(content)); actual != expected {
+			t.Errorf("%q: expected %v, got %v", content, expected, actual)
+		}
+	}
+}
+
+// fakeKmsCLIs puts gcloud, aws and az programs on the PATH,
+// which "decrypt" by returning their input, and aws and az
+// also a given data key.
+func fakeKmsCLIs(t *testing.T, dataKey []byte) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs shell scripts")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-kms-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(bin) })
+	key := base64.StdEncoding.EncodeToString(dataKey)
+	for program, script := range map[string]string{
+		"gcloud": "#!/bin/sh\n" +
+			"[ \"$4\" = projects/p/cryptoKeys/k ] || exit 1\ncat\n",
+		"aws": "#!/bin/sh\n" +
+			"[ \"$2 $4\" = \"decrypt fileb:///dev/stdin\" ] || exit 1\n" +
+			"echo " + key + "\n",
+		"az": "#!/bin/sh\n" +
+			"[ \"$5\" = https://v.vault.azure.net/keys/k/1 ] || exit 1\n" +
+			"echo " + key + "\n",
+	} {
+		err = ioutil.WriteFile(filepath.Join(bin, program), []byte(script), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := os.Getenv("PATH")
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+}
+
+func sealWithDataKey(t *testing.T, dataKey, plaintext []byte) string {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	return base64.StdEncoding.EncodeToString(
+		gcm.Seal(nonce, nonce, plaintext, nil))
+}
+
+func TestKmsLoader(t *testing.T) {
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	fakeKmsCLIs(t, dataKey)
+	sealed := sealWithDataKey(t, dataKey, []byte("PASSWORD=hunter2\n"))
+
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/plain.env", []byte("A=B\n"))
+	fSys.WriteFile("/app/gcp.env", []byte(`
+kms:
+  provider: gcp
+  key: projects/p/cryptoKeys/k
+ciphertext: `+base64.StdEncoding.EncodeToString([]byte("A=C\n"))+`
+`))
+	fSys.WriteFile("/app/sub/aws.env", []byte(`
+kms:
+  provider: aws
+  dataKey: ZW5jcnlwdGVk
+ciphertext: `+sealed+`
+`))
+	fSys.WriteFile("/app/azure.env", []byte(`
+kms:
+  provider: azure
+  key: https://v.vault.azure.net/keys/k/1
+  dataKey: ZW5jcnlwdGVk
+ciphertext: `+sealed+`
+`))
+	fSys.WriteFile("/app/other.env", []byte(`
+kms:
+  provider: vault
+ciphertext: AAAA
+`))
+	ldr := NewKmsLoader(NewFileLoaderAtRoot(fSys))
+	for location, expected := range map[string]string{
+		"/app/plain.env": "A=B\n",
+		"/app/gcp.env":   "A=C\n",
+		"/app/azure.env": "PASSWORD=hunter2\n",
+	} {
+		b, err := ldr.Load(location)
+		if err != nil {
+			t.Fatalf("%s: %v", location, err)
+		}
+		if string(b) != expected {
+			t.Errorf("%s: expected %q, got %q", location, expected, b)
+		}
+	}
+	sub, err := ldr.New("app/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := sub.Load("aws.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "PASSWORD=hunter2\n" {
+		t.Fatalf("unexpected aws.env: %q", b)
+	}
+	_, err = ldr.Load("/app/other.env")
+	if err == nil || !strings.Contains(err.Error(), "unknown KMS provider 'vault'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
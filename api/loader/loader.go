@@ -21,8 +21,85 @@ import (
 func NewLoader(
 	lr LoadRestrictorFunc,
 	target string, fSys filesys.FileSystem) (ifc.Loader, error) {
+	return NewLoaderWithCloneLimit(lr, target, fSys, 0)
+}
+
+// NewLoaderWithCloneLimit is like NewLoader, but clones at
+// most limit git repositories at the same time.  A limit less
+// than 1 means no limit.
+//
+// The loader and all loaders made from it share their git
+// clones, so a repository referenced by several bases at the
+// same ref is only cloned once.  The clones are removed when
+// the returned loader is cleaned up.
+func NewLoaderWithCloneLimit(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem, limit int) (ifc.Loader, error) {
+	return NewLoaderWithGitCache(lr, target, fSys, limit, "")
+}
+
+// NewLoaderWithGitCache is like NewLoaderWithCloneLimit, but
+// if cacheDir isn't empty, it keeps git clones in cacheDir
+// rather than removing them, and reuses them in later builds.
+func NewLoaderWithGitCache(
+	lr LoadRestrictorFunc, target string, fSys filesys.FileSystem,
+	limit int, cacheDir string) (ifc.Loader, error) {
+	return NewLoaderWithHostingAPI(lr, target, fSys, limit, cacheDir, nil)
+}
+
+// HostingAPIOptions configures fetching remote bases hosted
+// on github.com or gitlab.com as archives, through the APIs
+// of the hosts, rather than cloning them with git.
+type HostingAPIOptions struct {
+	// GitHubToken and GitLabToken authenticate requests.
+	// They're read from the GITHUB_TOKEN and GITLAB_TOKEN
+	// environment variables if empty.
+	GitHubToken string
+	GitLabToken string
+
+	// Proxy is the URL of the proxy to send requests
+	// through.  If empty, it's chosen from the environment.
+	Proxy string
+
+	// Retries is how many times failed requests, and those
+	// refused by rate limits, are retried.  3 if zero;
+	// negative values mean no retries.
+	Retries int
+}
+
+// NewLoaderWithHostingAPI is like NewLoaderWithGitCache, but if
+// o isn't nil, remote bases hosted on github.com or gitlab.com
+// are downloaded through the APIs of the hosts, configured by o.
+// Those aren't kept in cacheDir.
+func NewLoaderWithHostingAPI(
+	lr LoadRestrictorFunc, target string, fSys filesys.FileSystem,
+	limit int, cacheDir string, o *HostingAPIOptions) (ifc.Loader, error) {
+	cloner := git.ClonerUsingGitExec
+	if cacheDir != "" {
+		cloner = git.ClonerUsingGitCache(cacheDir)
+	}
+	if o != nil {
+		cloner = git.ClonerUsingAPI(git.APIOptions{
+			GitHubToken: o.GitHubToken,
+			GitLabToken: o.GitLabToken,
+			Proxy:       o.Proxy,
+			Retries:     o.Retries,
+		}, cloner)
+	}
+	clones := git.NewCloneCache(cloner, limit)
+	ldr, err := newLoader(lr, target, fSys, clones.Cloner())
+	if err != nil {
+		clones.Cleanup(fSys)
+		return nil, err
+	}
+	return &rootLoader{Loader: ldr, fSys: fSys, clones: clones}, nil
+}
+
+func newLoader(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem, cloner git.Cloner) (ifc.Loader, error) {
 
-	ldr, errGet := newLoaderAtGetter(target, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
+	ldr, errGet := newLoaderAtGetter(target, fSys, nil, cloner, getRemoteTarget)
 	if errGet == nil {
 		return ldr, nil
 	}
@@ -31,13 +108,30 @@ func NewLoader(
 	if errGit == nil {
 		// The target qualifies as a remote git target.
 		return newLoaderAtGitClone(
-			repoSpec, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
+			repoSpec, fSys, nil, cloner, getRemoteTarget)
 	}
 
 	root, errDir := demandDirectoryRoot(fSys, target)
 	if errDir == nil {
-		return newLoaderAtConfirmedDir(lr, root, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget), nil
+		return newLoaderAtConfirmedDir(lr, root, fSys, nil, cloner, getRemoteTarget), nil
 	}
 
 	return nil, fmt.Errorf("Error creating new loader with git: %v, dir: %v, get: %v", errGit, errDir, errGet)
 }
+
+// rootLoader is the loader at the root of a build; it
+// owns the git clones shared by all the loaders below it.
+type rootLoader struct {
+	ifc.Loader
+	fSys   filesys.FileSystem
+	clones *git.CloneCache
+}
+
+// Cleanup cleans the loader, then removes the shared clones.
+func (rl *rootLoader) Cleanup() error {
+	err := rl.Loader.Cleanup()
+	if errC := rl.clones.Cleanup(rl.fSys); err == nil {
+		err = errC
+	}
+	return err
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/yujunz/go-getter"
+)
+
+// azureBlobHostSuffix ends the hosts of Azure blob storage
+// accounts, e.g. myaccount.blob.core.windows.net.
+const azureBlobHostSuffix = ".blob.core.windows.net"
+
+// objectStorageGetters returns the getters of remote targets
+// in object storage buckets: s3://bucket/prefix,
+// gs://bucket/prefix and
+// https://account.blob.core.windows.net/container/prefix.
+//
+// The prefix may name an archive, e.g. base.tar.gz, which is
+// extracted, or a "directory", whose objects are all downloaded.
+// Objects are downloaded with the aws, gsutil and az programs,
+// so the default credentials of each cloud are used.  Other
+// https URLs are downloaded as before.
+//
+// The getters are new for each call, rather than the shared
+// getter.Getters, since clients set themselves on their getters,
+// and remote targets are fetched concurrently.
+func objectStorageGetters() map[string]getter.Getter {
+	return map[string]getter.Getter{
+		"file": new(getter.FileGetter),
+		"git":  new(getter.GitGetter),
+		"hg":   new(getter.HgGetter),
+		"http": &getter.HttpGetter{Netrc: true},
+		"https": &azureOrHTTPSGetter{
+			Getter: &getter.HttpGetter{Netrc: true},
+			azure:  &cliGetter{get: azureBlobGet, getFile: azureBlobGetFile},
+		},
+		"s3": &cliGetter{get: s3Get, getFile: s3GetFile},
+		"gs": &cliGetter{get: gsGet, getFile: gsGetFile},
+	}
+}
+
+// cliGetter is a getter.Getter running a cloud's program.
+type cliGetter struct {
+	get     func(dst string, u *url.URL) error
+	getFile func(dst string, u *url.URL) error
+}
+
+func (g *cliGetter) Get(dst string, u *url.URL) error {
+	return g.get(dst, u)
+}
+
+func (g *cliGetter) GetFile(dst string, u *url.URL) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	return g.getFile(dst, u)
+}
+
+// ClientMode is always a directory, since targets are roots of
+// kustomizations.  Archives are recognized by the getter client,
+// from their extensions, before the mode is asked for.
+func (g *cliGetter) ClientMode(*url.URL) (getter.ClientMode, error) {
+	return getter.ClientModeDir, nil
+}
+
+func (g *cliGetter) SetClient(*getter.Client) {}
+
+// azureOrHTTPSGetter gets https URLs of Azure blob storage
+// with azure, and others with the embedded Getter.
+type azureOrHTTPSGetter struct {
+	getter.Getter
+	azure getter.Getter
+}
+
+func (g *azureOrHTTPSGetter) pick(u *url.URL) getter.Getter {
+	if strings.HasSuffix(strings.ToLower(u.Hostname()), azureBlobHostSuffix) {
+		return g.azure
+	}
+	return g.Getter
+}
+
+func (g *azureOrHTTPSGetter) Get(dst string, u *url.URL) error {
+	return g.pick(u).Get(dst, u)
+}
+
+func (g *azureOrHTTPSGetter) GetFile(dst string, u *url.URL) error {
+	return g.pick(u).GetFile(dst, u)
+}
+
+func (g *azureOrHTTPSGetter) ClientMode(u *url.URL) (getter.ClientMode, error) {
+	return g.pick(u).ClientMode(u)
+}
+
+// bucketURL returns u without its query, which
+// only holds options for the programs.
+func bucketURL(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+func s3Args(u *url.URL, args ...string) []string {
+	if region := u.Query().Get("region"); region != "" {
+		args = append(args, "--region", region)
+	}
+	if endpoint := u.Query().Get("endpoint"); endpoint != "" {
+		args = append(args, "--endpoint-url", endpoint)
+	}
+	return append(args, "--only-show-errors")
+}
+
+func s3Get(dst string, u *url.URL) error {
+	return runCloudProgram("aws", s3Args(u, "s3", "sync", bucketURL(u), dst)...)
+}
+
+func s3GetFile(dst string, u *url.URL) error {
+	return runCloudProgram("aws", s3Args(u, "s3", "cp", bucketURL(u), dst)...)
+}
+
+func gsGet(dst string, u *url.URL) error {
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	return runCloudProgram("gsutil", "-q", "-m", "rsync", "-r", bucketURL(u), dst)
+}
+
+func gsGetFile(dst string, u *url.URL) error {
+	return runCloudProgram("gsutil", "-q", "cp", bucketURL(u), dst)
+}
+
+// azureBlob returns the account, container and blob,
+// or prefix of blobs, of a blob storage URL.
+func azureBlob(u *url.URL) (string, string, string, error) {
+	account := strings.TrimSuffix(strings.ToLower(u.Hostname()), azureBlobHostSuffix)
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	if parts[0] == "" {
+		return "", "", "", fmt.Errorf("%s lacks a container", u)
+	}
+	if len(parts) == 1 {
+		return account, parts[0], "", nil
+	}
+	return account, parts[0], strings.TrimSuffix(parts[1], "/"), nil
+}
+
+func azureBlobGet(dst string, u *url.URL) error {
+	account, container, prefix, err := azureBlob(u)
+	if err != nil {
+		return err
+	}
+	args := []string{"storage", "blob", "download-batch",
+		"--account-name", account, "--source", container, "--auth-mode", "login",
+		"--only-show-errors"}
+	if prefix == "" {
+		if err = os.MkdirAll(dst, 0700); err != nil {
+			return err
+		}
+		return runCloudProgram("az", append(args, "--destination", dst)...)
+	}
+	// The blobs are downloaded to paths including
+	// their prefix, which is removed afterwards.
+	tmp, err := ioutil.TempDir("", "kustomize-blobs-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	err = runCloudProgram("az", append(args,
+		"--destination", tmp, "--pattern", prefix+"/*")...)
+	if err != nil {
+		return err
+	}
+	if err = os.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(tmp, filepath.FromSlash(prefix)), dst)
+}
+
+func azureBlobGetFile(dst string, u *url.URL) error {
+	account, container, blob, err := azureBlob(u)
+	if err != nil {
+		return err
+	}
+	return runCloudProgram("az", "storage", "blob", "download",
+		"--account-name", account, "--container-name", container,
+		"--name", blob, "--file", dst, "--auth-mode", "login", "--only-show-errors")
+}
+
+// runCloudProgram runs program with args, returning
+// an error holding its output if it fails.
+func runCloudProgram(program string, args ...string) error {
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return fmt.Errorf(
+			"downloading from object storage needs the %s program: %v", program, err)
+	}
+	cmd := exec.Command(path, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %v: %s",
+			program, strings.Join(args, " "), err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
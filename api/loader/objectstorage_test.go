@@ -0,0 +1,174 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/yujunz/go-getter"
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+// Fake cloud programs, which log their arguments to $LOG.
+// Directories get a kustomization naming the program,
+// and files are copied from $ARCHIVE.
+const (
+	fakeAws = `#!/bin/sh
+echo aws "$@" >> "$LOG"
+case "$2" in
+sync) mkdir -p "$4" && echo "namePrefix: aws-" > "$4/kustomization.yaml" ;;
+cp) cp "$ARCHIVE" "$4" ;;
+esac
+`
+	fakeGsutil = `#!/bin/sh
+echo gsutil "$@" >> "$LOG"
+cp "$ARCHIVE" "$4"
+`
+	fakeAz = `#!/bin/sh
+echo az "$@" >> "$LOG"
+while [ $# -gt 0 ]; do
+  case "$1" in
+  --destination) dst="$2" ;;
+  --pattern) prefix="${2%/\*}" ;;
+  esac
+  shift
+done
+mkdir -p "$dst/$prefix" && echo "namePrefix: az-" > "$dst/$prefix/kustomization.yaml"
+`
+)
+
+// writeArchive writes a tar.gz holding a kustomization.
+func writeArchive(t *testing.T, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	content := "namePrefix: archive-\n"
+	err = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg,
+		Name: "kustomization.yaml", Mode: 0644, Size: int64(len(content))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObjectStorageTargets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs shell scripts")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-clouds-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bin)
+	for name, script := range map[string]string{
+		"aws": fakeAws, "gsutil": fakeGsutil, "az": fakeAz} {
+		err = ioutil.WriteFile(filepath.Join(bin, name), []byte(script), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	log := filepath.Join(bin, "log")
+	archive := filepath.Join(bin, "base.tar.gz")
+	writeArchive(t, archive)
+	for k, v := range map[string]string{
+		"PATH":    bin + string(os.PathListSeparator) + os.Getenv("PATH"),
+		"LOG":     log,
+		"ARCHIVE": archive,
+	} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Setenv(k, v)
+	}
+
+	testCases := map[string]struct {
+		target   string
+		expected string
+		args     string
+	}{
+		"s3 directory": {
+			target:   "s3://bucket/bases/web?region=eu-west-1",
+			expected: "namePrefix: aws-\n",
+			args:     "aws s3 sync s3://bucket/bases/web DST --region eu-west-1 --only-show-errors",
+		},
+		"s3 archive": {
+			target:   "s3://bucket/bases/web.tar.gz",
+			expected: "namePrefix: archive-\n",
+			args:     "aws s3 cp s3://bucket/bases/web.tar.gz DST --only-show-errors",
+		},
+		"gs archive": {
+			target:   "gs://bucket/web.tar.gz",
+			expected: "namePrefix: archive-\n",
+			args:     "gsutil -q cp gs://bucket/web.tar.gz DST",
+		},
+		"azure directory": {
+			target:   "https://acct.blob.core.windows.net/packages/bases/web",
+			expected: "namePrefix: az-\n",
+			args: "az storage blob download-batch --account-name acct --source packages " +
+				"--auth-mode login --only-show-errors --destination DST --pattern bases/web/*",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			os.Remove(log)
+			ldr, err := NewLoader(RestrictionRootOnly, tc.target, filesys.MakeFsOnDisk())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ldr.Cleanup()
+			b, err := ldr.Load("kustomization.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, b)
+			}
+			if _, err = ldr.Load("../../etc/passwd"); err == nil {
+				t.Fatalf("expected loads outside the target to be restricted")
+			}
+			out, err := ioutil.ReadFile(log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields := strings.Fields(string(out))
+			for i, f := range fields {
+				if filepath.IsAbs(f) {
+					fields[i] = "DST"
+				}
+			}
+			if args := strings.Join(fields, " "); args != tc.args {
+				t.Fatalf("expected %q, got %q", tc.args, args)
+			}
+		})
+	}
+}
+
+func TestObjectStorageGettersAreNotShared(t *testing.T) {
+	a, b := objectStorageGetters(), objectStorageGetters()
+	for scheme := range a {
+		if a[scheme] == b[scheme] {
+			t.Fatalf("expected a new %s getter for each call", scheme)
+		}
+		if a[scheme] == getter.Getters[scheme] {
+			t.Fatalf("expected the %s getter not to be the shared one", scheme)
+		}
+	}
+}
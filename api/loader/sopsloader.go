@@ -0,0 +1,117 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+)
+
+// sopsLoader is a Loader which decrypts the files encrypted with
+// SOPS (https://github.com/mozilla/sops) as it loads them, using
+// the sops program.  Other files are returned unchanged.
+//
+// The sops program finds the keys itself, so age, KMS and PGP
+// keys are configured as usual for sops, e.g. by setting
+// SOPS_AGE_KEY_FILE.
+type sopsLoader struct {
+	ifc.Loader
+}
+
+// NewSopsLoader returns a Loader decrypting the SOPS encrypted
+// files loaded by ldr, and by the loaders made from it.
+func NewSopsLoader(ldr ifc.Loader) ifc.Loader {
+	if _, ok := ldr.(*sopsLoader); ok {
+		return ldr
+	}
+	return &sopsLoader{Loader: ldr}
+}
+
+// New implements ifc.Loader.
+func (l *sopsLoader) New(newRoot string) (ifc.Loader, error) {
+	ldr, err := l.Loader.New(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &sopsLoader{Loader: ldr}, nil
+}
+
+// Load implements ifc.Loader.
+func (l *sopsLoader) Load(location string) ([]byte, error) {
+	b, err := l.Loader.Load(location)
+	if err != nil || !IsSopsEncrypted(b) {
+		return b, err
+	}
+	b, err = sopsDecrypt(location, b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting %s", location)
+	}
+	return b, nil
+}
+
+// CacheKey returns the CacheKey of the wrapped loader, if
+// it has one, and its root otherwise.
+func (l *sopsLoader) CacheKey() string {
+	if k, ok := l.Loader.(interface{ CacheKey() string }); ok {
+		return k.CacheKey()
+	}
+	return l.Root()
+}
+
+// sopsMetadata matches the metadata sops adds to the files
+// it encrypts, in each of the formats it supports.
+var sopsMetadata = regexp.MustCompile(
+	`(?m)^sops:|"sops"\s*:\s*\{|^sops_mac=|^\[sops\]`)
+
+// IsSopsEncrypted returns true if b looks like a file
+// encrypted by sops.
+func IsSopsEncrypted(b []byte) bool {
+	return bytes.Contains(b, []byte("sops")) && sopsMetadata.Match(b)
+}
+
+// sopsFormat returns the sops input and output types
+// of the encrypted file at location.
+func sopsFormat(location string, b []byte) (in, out string) {
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".yaml", ".yml":
+		return "yaml", "yaml"
+	case ".json":
+		return "json", "json"
+	case ".env":
+		return "dotenv", "dotenv"
+	case ".ini":
+		return "ini", "ini"
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		// sops stores other files as json
+		// holding the encrypted bytes.
+		return "json", "binary"
+	}
+	return "yaml", "yaml"
+}
+
+func sopsDecrypt(location string, b []byte) ([]byte, error) {
+	sopsProgram, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, errors.Wrap(err, "no 'sops' program on path")
+	}
+	in, out := sopsFormat(location, b)
+	cmd := exec.Command(
+		sopsProgram, "--decrypt",
+		"--input-type", in, "--output-type", out, "/dev/stdin")
+	cmd.Stdin = bytes.NewReader(b)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	plain, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "sops: %s", strings.TrimSpace(stderr.String()))
+	}
+	return plain, nil
+}
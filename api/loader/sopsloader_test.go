@@ -0,0 +1,73 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+func TestIsSopsEncrypted(t *testing.T) {
+	for content, expected := range map[string]bool{
+		"a: b\nsops:\n  mac: ENC[x]\n":          true,
+		`{"a": "b", "sops": {"mac": "ENC[x]"}}`: true,
+		"A=ENC[x]\nsops_mac=ENC[x]\n":           true,
+		"[sops]\nmac = ENC[x]\n":                true,
+		"a: b\nc:\n  sops: d\n":                 false,
+		"apiVersion: v1\nkind: Secret\n":        false,
+		`{"a": "sops"}`:                         false,
+	} {
+		if actual := IsSopsEncrypted([]byte(content)); actual != expected {
+			t.Errorf("%q: expected %v, got %v", content, expected, actual)
+		}
+	}
+}
+
+func TestSopsLoader(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	// A fake sops program which "decrypts" by
+	// reporting its arguments.
+	bin, err := ioutil.TempDir("", "kustomize-sops-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bin)
+	err = ioutil.WriteFile(filepath.Join(bin, "sops"), []byte("#!/bin/sh\necho \"$@\"\n"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/plain.env", []byte("A=B\n"))
+	fSys.WriteFile("/app/sub/secret.env", []byte("A=ENC[x]\nsops_mac=ENC[x]\n"))
+	ldr := NewSopsLoader(NewFileLoaderAtRoot(fSys))
+	b, err := ldr.Load("/app/plain.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "A=B\n" {
+		t.Fatalf("unexpected plain file: %q", b)
+	}
+	sub, err := ldr.New("app/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = sub.Load("secret.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "--decrypt --input-type dotenv --output-type dotenv /dev/stdin\n"
+	if string(b) != expected {
+		t.Fatalf("expected %q, got %q", expected, b)
+	}
+}
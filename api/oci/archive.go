@@ -0,0 +1,128 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+// maxArchiveSize bounds the size of the files unpacked
+// from a package, so a hostile package can't fill the disk.
+const maxArchiveSize = 256 << 20
+
+// pack returns a gzipped tarball of the files in dir,
+// skipping .git directories.  The tarball depends only
+// on the names and contents of the files, so packing
+// the same files always results in the same digest.
+func pack(fSys filesys.FileSystem, dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	err := fSys.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if fi.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		content, err := fSys.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     filepath.ToSlash(rel),
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  time.Unix(0, 0),
+			Format:   tar.FormatPAX,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unpack writes the files in the gzipped tarball b into
+// dir, refusing any that would be written outside dir.
+func unpack(fSys filesys.FileSystem, dir string, b []byte) error {
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(h.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("refusing to unpack %q outside of %s", h.Name, dir)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err = fSys.MkdirAll(target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += h.Size
+			if total > maxArchiveSize {
+				return fmt.Errorf("package is larger than %d bytes", maxArchiveSize)
+			}
+			content, err := ioutil.ReadAll(io.LimitReader(tr, h.Size))
+			if err != nil {
+				return err
+			}
+			if err = fSys.MkdirAll(filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err = fSys.WriteFile(target, content); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("refusing to unpack %q: not a file or directory", h.Name)
+		}
+	}
+}
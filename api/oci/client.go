@@ -0,0 +1,334 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxManifestSize bounds the size of the manifests read.
+const maxManifestSize = 4 << 20
+
+// client talks to the registry of a reference, following
+// the OCI distribution spec, authenticating as registries
+// ask with basic auth or bearer tokens.
+type client struct {
+	ref     Reference
+	options Options
+	scope   string
+
+	mu    sync.Mutex
+	token string
+	basic bool
+
+	credsOnce sync.Once
+	username  string
+	password  string
+	credsErr  error
+}
+
+func newClient(ref Reference, o Options, actions string) *client {
+	if o.HTTPClient == nil {
+		o.HTTPClient = http.DefaultClient
+	}
+	return &client{
+		ref:     ref,
+		options: o,
+		scope:   "repository:" + ref.Repository + ":" + actions,
+	}
+}
+
+func (c *client) url(format string, args ...interface{}) string {
+	scheme := "https"
+	if c.options.PlainHTTP {
+		scheme = "http"
+	}
+	return scheme + "://" + c.ref.host() + "/v2/" + c.ref.Repository + fmt.Sprintf(format, args...)
+}
+
+// do sends a request, authenticating and sending it again
+// if the registry asks for credentials.
+func (c *client) do(method, u string, header http.Header, body []byte) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequest(method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		c.mu.Lock()
+		token, basic := c.token, c.basic
+		c.mu.Unlock()
+		switch {
+		case token != "":
+			req.Header.Set("Authorization", "Bearer "+token)
+		case c.options.Username != "" || basic:
+			username, password, _ := c.credentials()
+			req.SetBasicAuth(username, password)
+		}
+		return c.options.HTTPClient.Do(req)
+	}
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	switch scheme := strings.ToLower(challenge); {
+	case strings.HasPrefix(scheme, "bearer "):
+		if err = c.authenticate(challenge[len("bearer "):]); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(scheme, "basic "):
+		username, _, err := c.credentials()
+		if err != nil {
+			return nil, err
+		}
+		if username == "" {
+			return nil, fmt.Errorf("%s %s: unauthorized, and no credentials for %s",
+				method, u, c.ref.Registry)
+		}
+		c.mu.Lock()
+		c.basic = true
+		c.mu.Unlock()
+	default:
+		return nil, fmt.Errorf("%s %s: unauthorized", method, u)
+	}
+	return send()
+}
+
+// credentials returns the credentials for the registry: those
+// of the options, or else those docker has, looked up once.
+func (c *client) credentials() (string, string, error) {
+	c.credsOnce.Do(func() {
+		c.username, c.password = c.options.Username, c.options.Password
+		if c.username == "" {
+			c.username, c.password, c.credsErr = dockerCredentials(
+				c.options.DockerConfigDir, c.ref.Registry)
+		}
+	})
+	return c.username, c.password, c.credsErr
+}
+
+// authenticate fetches a bearer token, as asked by the
+// parameters of a WWW-Authenticate challenge.
+func (c *client) authenticate(params string) error {
+	p := parseChallenge(params)
+	if p["realm"] == "" {
+		return fmt.Errorf("registry asked for a token without a realm")
+	}
+	u, err := url.Parse(p["realm"])
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	if p["service"] != "" {
+		q.Set("service", p["service"])
+	}
+	q.Set("scope", c.scope)
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	username, password, err := c.credentials()
+	if err != nil {
+		return err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := c.options.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching token from %s: %s", p["realm"], resp.Status)
+	}
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&t); err != nil {
+		return fmt.Errorf("fetching token from %s: %v", p["realm"], err)
+	}
+	if t.Token == "" {
+		t.Token = t.AccessToken
+	}
+	if t.Token == "" {
+		return fmt.Errorf("fetching token from %s: no token", p["realm"])
+	}
+	c.mu.Lock()
+	c.token = t.Token
+	c.mu.Unlock()
+	return nil
+}
+
+var challengeParamRegex = regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^,]*))`)
+
+// parseChallenge parses the comma separated key="value"
+// parameters of a WWW-Authenticate challenge.
+func parseChallenge(s string) map[string]string {
+	result := map[string]string{}
+	for _, m := range challengeParamRegex.FindAllStringSubmatch(s, -1) {
+		result[strings.ToLower(m[1])] = m[2] + m[3]
+	}
+	return result
+}
+
+// pushBlob uploads b, unless the registry has it already.
+func (c *client) pushBlob(b []byte) (Descriptor, error) {
+	d := Descriptor{Digest: digestOf(b), Size: int64(len(b))}
+	resp, err := c.do(http.MethodHead, c.url("/blobs/%s", d.Digest), nil, nil)
+	if err != nil {
+		return d, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return d, nil
+	}
+	resp, err = c.do(http.MethodPost, c.url("/blobs/uploads/"), nil, nil)
+	if err != nil {
+		return d, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return d, fmt.Errorf("starting upload of %s: %s", d.Digest, resp.Status)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return d, err
+	}
+	q := location.Query()
+	q.Set("digest", d.Digest)
+	location.RawQuery = q.Encode()
+	resp, err = c.do(http.MethodPut, location.String(),
+		http.Header{"Content-Type": {"application/octet-stream"}}, b)
+	if err != nil {
+		return d, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return d, fmt.Errorf("uploading %s: %s", d.Digest, resp.Status)
+	}
+	return d, nil
+}
+
+// pullBlob downloads the blob of d, checking its digest.
+func (c *client) pullBlob(d Descriptor, limit int64) ([]byte, error) {
+	resp, err := c.do(http.MethodGet, c.url("/blobs/%s", d.Digest), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", d.Digest, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("fetching %s: larger than %d bytes", d.Digest, limit)
+	}
+	if actual := digestOf(b); actual != d.Digest {
+		return nil, fmt.Errorf("fetching %s: got content with digest %s", d.Digest, actual)
+	}
+	return b, nil
+}
+
+// pushManifest uploads m, tagged with the tag of the
+// reference, and returns its digest.
+func (c *client) pushManifest(m []byte) (string, error) {
+	resp, err := c.do(http.MethodPut, c.url("/manifests/%s", c.ref.Tag),
+		http.Header{"Content-Type": {MediaTypeManifest}}, m)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("uploading manifest %s: %s", c.ref, resp.Status)
+	}
+	return digestOf(m), nil
+}
+
+// pullManifest downloads the manifest of the reference,
+// checking its digest if the reference has one.
+func (c *client) pullManifest() ([]byte, string, error) {
+	resp, err := c.do(http.MethodGet, c.url("/manifests/%s", c.ref.version()),
+		http.Header{"Accept": {MediaTypeManifest}}, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetching manifest %s: %s", c.ref, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, "", err
+	}
+	digest := digestOf(b)
+	if c.ref.Digest != "" && digest != c.ref.Digest {
+		return nil, "", fmt.Errorf(
+			"fetching manifest %s: got content with digest %s", c.ref, digest)
+	}
+	return b, digest, nil
+}
+
+// imageManifestTypes are the media types of the manifests,
+// and indexes of manifests, of container images.
+var imageManifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	MediaTypeManifest,
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// manifestDigest returns the digest of the manifest of
+// the reference, as the registry reports it, or else
+// by fetching the manifest.
+func (c *client) manifestDigest() (string, error) {
+	accept := http.Header{"Accept": imageManifestTypes}
+	resp, err := c.do(http.MethodHead, c.url("/manifests/%s", c.ref.version()), accept, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching manifest %s: %s", c.ref, resp.Status)
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digestRegex.MatchString(digest) {
+		return digest, nil
+	}
+	resp, err = c.do(http.MethodGet, c.url("/manifests/%s", c.ref.version()), accept, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching manifest %s: %s", c.ref, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return "", err
+	}
+	return digestOf(b), nil
+}
+
+func digestOf(b []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+}
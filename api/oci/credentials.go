@@ -0,0 +1,151 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dockerHubServer is the address docker
+// keeps the credentials of docker.io under.
+const dockerHubServer = "https://index.docker.io/v1/"
+
+// dockerConfig is the part of a docker config.json
+// which holds the credentials of registries.
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredsStore  string                `json:"credsStore"`
+	CredHelpers map[string]string     `json:"credHelpers"`
+}
+
+type dockerAuth struct {
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// dockerConfigDir returns the directory holding the
+// docker config.json, as docker looks it up.
+func dockerConfigDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+	if dir = os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// dockerCredentials returns the credentials docker would use
+// for registry, from the credential helper configured for it,
+// the credentials store, or the auths of the config.json in
+// dir.  They're empty if there are none.
+func dockerCredentials(dir, registry string) (string, string, error) {
+	dir = dockerConfigDir(dir)
+	if dir == "" {
+		return "", "", nil
+	}
+	path := filepath.Join(dir, "config.json")
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	var config dockerConfig
+	if err = json.Unmarshal(b, &config); err != nil {
+		return "", "", fmt.Errorf("reading %s: %v", path, err)
+	}
+	server := registry
+	if registry == defaultRegistry {
+		server = dockerHubServer
+	}
+	helper := config.CredHelpers[registry]
+	if helper == "" {
+		helper = config.CredsStore
+	}
+	if helper != "" {
+		username, password, err := runCredentialHelper(helper, server)
+		if err != nil || username != "" {
+			return username, password, err
+		}
+	}
+	for key, auth := range config.Auths {
+		if serverHost(key) != serverHost(server) {
+			continue
+		}
+		if auth.Auth == "" {
+			return auth.Username, auth.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("reading auth of %s in %s: %v", key, path, err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf(
+				"reading auth of %s in %s: expected username:password", key, path)
+		}
+		return parts[0], parts[1], nil
+	}
+	return "", "", nil
+}
+
+// serverHost returns the host of a server address,
+// which may be a URL, as the keys of auths may be.
+func serverHost(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	if i := strings.Index(server, "/"); i >= 0 {
+		server = server[:i]
+	}
+	if server == "index.docker.io" || server == "registry-1.docker.io" {
+		return defaultRegistry
+	}
+	return server
+}
+
+// runCredentialHelper asks the docker credential helper
+// docker-credential-<helper> for the credentials of server.
+// They're empty if the helper has none.
+func runCredentialHelper(helper, server string) (string, string, error) {
+	program := "docker-credential-" + helper
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return "", "", fmt.Errorf("credential helper %s: %v", program, err)
+	}
+	cmd := exec.Command(path, "get")
+	cmd.Stdin = strings.NewReader(server)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		out := strings.TrimSpace(stdout.String() + stderr.String())
+		if strings.Contains(out, "credentials not found") {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("credential helper %s: %v: %s", program, err, out)
+	}
+	var creds struct {
+		Username string
+		Secret   string
+	}
+	if err = json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return "", "", fmt.Errorf("credential helper %s: %v", program, err)
+	}
+	return creds.Username, creds.Secret, nil
+}
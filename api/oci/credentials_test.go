@@ -0,0 +1,83 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDockerCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string, mode os.FileMode) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("config.json", `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "aHViOmh1YnNlY3JldA=="},
+    "quay.io": {"username": "quser", "password": "qsecret"},
+    "gcr.io": {}
+  },
+  "credHelpers": {"gcr.io": "fake"}
+}`, 0600)
+	write("docker-credential-fake", `#!/bin/sh
+read server
+if [ "$1" = get ] && [ "$server" = gcr.io ]; then
+  echo '{"ServerURL": "gcr.io", "Username": "oauth2accesstoken", "Secret": "ya29"}'
+  exit 0
+fi
+echo "credentials not found in native keychain"
+exit 1
+`, 0700)
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	testCases := map[string][2]string{
+		"docker.io":      {"hub", "hubsecret"},
+		"quay.io":        {"quser", "qsecret"},
+		"gcr.io":         {"oauth2accesstoken", "ya29"},
+		"ghcr.io":        {"", ""},
+		"localhost:5000": {"", ""},
+	}
+	for registry, expected := range testCases {
+		username, password, err := dockerCredentials(dir, registry)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", registry, err)
+		}
+		if username != expected[0] || password != expected[1] {
+			t.Fatalf("%s: expected %v, got %s:%s", registry, expected, username, password)
+		}
+	}
+}
+
+func TestDockerCredentialsWithCredsStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "config.json"),
+		[]byte(`{"credsStore": "missing-helper"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = dockerCredentials(dir, "ghcr.io")
+	if err == nil || !strings.HasPrefix(err.Error(),
+		"credential helper docker-credential-missing-helper") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package oci pushes kustomization directories to OCI
+// registries as artifacts, and pulls them back, so that
+// packages of configuration can be distributed, and
+// pinned by digest, like container images.  It also
+// resolves the digests of container images.
+//
+// A package is an OCI image manifest with an empty config,
+// and a single layer holding a gzipped tarball of the files
+// of the directory.  The tarball depends only on the names
+// and contents of the files, so pushing the same files again
+// results in the same layer digest.
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+const (
+	// MediaTypeManifest is the media type of the manifest of a package.
+	MediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+
+	// MediaTypeConfig is the media type of the config of a package.
+	MediaTypeConfig = "application/vnd.cncf.kustomize.config.v1+json"
+
+	// MediaTypeLayer is the media type of the files of a package.
+	MediaTypeLayer = "application/vnd.cncf.kustomize.package.layer.v1.tar+gzip"
+
+	// AnnotationCreated is the time a package was created, in RFC 3339.
+	AnnotationCreated = "org.opencontainers.image.created"
+
+	// AnnotationSource is the URL of the source of a package.
+	AnnotationSource = "org.opencontainers.image.source"
+
+	// AnnotationRevision is the revision of the source of a package.
+	AnnotationRevision = "org.opencontainers.image.revision"
+)
+
+// Options configure access to registries.
+type Options struct {
+	// Username and Password authenticate to the registry,
+	// directly or to fetch a token.  If empty, the credentials
+	// docker has for the registry are used, if any, as for
+	// docker pull: from the credential helper configured for
+	// the registry, the credentials store, or the auths of
+	// the docker config.json.
+	Username string
+	Password string
+
+	// DockerConfigDir is the directory of the docker
+	// config.json.  If empty, $DOCKER_CONFIG, or ~/.docker.
+	DockerConfigDir string
+
+	// PlainHTTP talks to the registry over http rather
+	// than https, e.g. to a local registry.
+	PlainHTTP bool
+
+	// HTTPClient sends the requests.  http.DefaultClient
+	// is used if nil.
+	HTTPClient *http.Client
+}
+
+// Descriptor describes a blob of a package.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is the OCI image manifest of a package.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Push packages the kustomization directory dir, and pushes it
+// to the registry as ref, with the given manifest annotations.
+// It returns ref pinned to the digest of the pushed manifest.
+func Push(
+	fSys filesys.FileSystem, dir string, ref string,
+	annotations map[string]string, o Options) (Reference, error) {
+	r, err := ParseReference(ref)
+	if err != nil {
+		return Reference{}, err
+	}
+	if r.Digest != "" {
+		return Reference{}, fmt.Errorf("cannot push to %s: it has a digest", ref)
+	}
+	if !hasKustomization(fSys, dir) {
+		return Reference{}, fmt.Errorf("no kustomization file in %s", dir)
+	}
+	layer, err := pack(fSys, dir)
+	if err != nil {
+		return Reference{}, err
+	}
+	c := newClient(r, o, "pull,push")
+	config, err := c.pushBlob([]byte("{}"))
+	if err != nil {
+		return Reference{}, err
+	}
+	config.MediaType = MediaTypeConfig
+	l, err := c.pushBlob(layer)
+	if err != nil {
+		return Reference{}, err
+	}
+	l.MediaType = MediaTypeLayer
+	m, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeManifest,
+		Config:        config,
+		Layers:        []Descriptor{l},
+		Annotations:   annotations,
+	})
+	if err != nil {
+		return Reference{}, err
+	}
+	digest, err := c.pushManifest(m)
+	if err != nil {
+		return Reference{}, err
+	}
+	return r.WithDigest(digest), nil
+}
+
+// Pull pulls the package ref from its registry, and unpacks
+// it into dir, which must not exist, or be empty.  It returns
+// ref pinned to the digest of the pulled manifest, and the
+// manifest, holding the annotations of the package.
+func Pull(
+	fSys filesys.FileSystem, ref string, dir string,
+	o Options) (Reference, *Manifest, error) {
+	r, err := ParseReference(ref)
+	if err != nil {
+		return Reference{}, nil, err
+	}
+	if fSys.Exists(dir) {
+		entries, err := fSys.Glob(filepath.Join(dir, "*"))
+		if err != nil {
+			return Reference{}, nil, err
+		}
+		if !fSys.IsDir(dir) || len(entries) > 0 {
+			return Reference{}, nil, fmt.Errorf("%s exists, and isn't an empty directory", dir)
+		}
+	}
+	c := newClient(r, o, "pull")
+	b, digest, err := c.pullManifest()
+	if err != nil {
+		return Reference{}, nil, err
+	}
+	var m Manifest
+	if err = json.Unmarshal(b, &m); err != nil {
+		return Reference{}, nil, fmt.Errorf("reading manifest %s: %v", r, err)
+	}
+	if m.Config.MediaType != MediaTypeConfig ||
+		len(m.Layers) != 1 || m.Layers[0].MediaType != MediaTypeLayer {
+		return Reference{}, nil, fmt.Errorf("%s isn't a kustomize package", r)
+	}
+	layer, err := c.pullBlob(m.Layers[0], maxArchiveSize)
+	if err != nil {
+		return Reference{}, nil, err
+	}
+	if err = fSys.MkdirAll(dir); err != nil {
+		return Reference{}, nil, err
+	}
+	if err = unpack(fSys, dir, layer); err != nil {
+		return Reference{}, nil, err
+	}
+	return r.WithDigest(digest), &m, nil
+}
+
+// ResolveDigest looks up the digest of the manifest, or
+// index of manifests, tagged ref in its registry, e.g. to pin
+// a container image, and returns ref pinned to it.
+func ResolveDigest(ref string, o Options) (Reference, error) {
+	r, err := ParseReference(ref)
+	if err != nil {
+		return Reference{}, err
+	}
+	if r.Digest != "" {
+		return r, nil
+	}
+	digest, err := newClient(r, o, "pull").manifestDigest()
+	if err != nil {
+		return Reference{}, err
+	}
+	return r.WithDigest(digest), nil
+}
+
+func hasKustomization(fSys filesys.FileSystem, dir string) bool {
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if fSys.Exists(filepath.Join(dir, n)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/oci"
+)
+
+// registry is a minimal OCI registry, which asks for
+// bearer tokens if token isn't empty.
+type registry struct {
+	token     string
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	uploads   int
+}
+
+func newRegistry(t *testing.T, token string) (*registry, *httptest.Server) {
+	r := &registry{
+		token:     token,
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+	}
+	s := httptest.NewServer(r)
+	t.Cleanup(s.Close)
+	return r, s
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if req.URL.Path == "/token" {
+		user, password, _ := req.BasicAuth()
+		if user != "me" || password != "secret" ||
+			!strings.HasPrefix(req.URL.Query().Get("scope"), "repository:example/app:pull") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"token": %q}`, r.token)
+		return
+	}
+	if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+			`Bearer realm="http://%s/token",service="registry"`, req.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	const prefix = "/v2/example/app/"
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, prefix)
+	body, _ := ioutil.ReadAll(req.Body)
+	switch {
+	case req.Method == http.MethodPost && path == "blobs/uploads/":
+		w.Header().Set("Location", "/v2/example/app/blobs/uploads/1?state=x")
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && path == "blobs/uploads/1":
+		digest := req.URL.Query().Get("digest")
+		if req.URL.Query().Get("state") != "x" ||
+			digest != fmt.Sprintf("sha256:%x", sha256.Sum256(body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = body
+		r.uploads++
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "blobs/"):
+		b, found := r.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+		if req.Header.Get("Content-Type") != oci.MediaTypeManifest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+		r.manifests[strings.TrimPrefix(path, "manifests/")] = body
+		r.manifests[digest] = body
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodGet && strings.HasPrefix(path, "manifests/"):
+		b, found := r.manifests[strings.TrimPrefix(path, "manifests/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	case req.Method == http.MethodHead && strings.HasPrefix(path, "manifests/"):
+		b, found := r.manifests[strings.TrimPrefix(path, "manifests/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(b)))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writePackage(fSys filesys.FileSystem) {
+	fSys.WriteFile("/app/kustomization.yaml", []byte("resources:\n- deployment.yaml\n"))
+	fSys.WriteFile("/app/deployment.yaml", []byte("kind: Deployment\n"))
+	fSys.WriteFile("/app/base/kustomization.yaml", []byte("namePrefix: a-\n"))
+	fSys.WriteFile("/app/.git/HEAD", []byte("ref: refs/heads/master\n"))
+}
+
+func TestPushPull(t *testing.T) {
+	reg, s := newRegistry(t, "")
+	host := strings.TrimPrefix(s.URL, "http://")
+	fSys := filesys.MakeFsInMemory()
+	writePackage(fSys)
+	o := oci.Options{PlainHTTP: true}
+
+	pushed, err := oci.Push(fSys, "/app", host+"/example/app:v1",
+		map[string]string{oci.AnnotationRevision: "abc123"}, o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pushed.Tag != "v1" || !strings.HasPrefix(pushed.Digest, "sha256:") {
+		t.Fatalf("unexpected reference %s", pushed)
+	}
+
+	// Pushing the same files again only uploads the manifest.
+	again, err := oci.Push(fSys, "/app", host+"/example/app:v1",
+		map[string]string{oci.AnnotationRevision: "abc123"}, o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again != pushed || reg.uploads != 2 {
+		t.Fatalf("expected %s after 2 uploads, got %s after %d", pushed, again, reg.uploads)
+	}
+
+	pulled, m, err := oci.Pull(fSys, host+"/example/app@"+pushed.Digest, "/pulled", o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pulled.Digest != pushed.Digest || m.Annotations[oci.AnnotationRevision] != "abc123" {
+		t.Fatalf("unexpected reference %s, manifest %+v", pulled, m)
+	}
+	for name, expected := range map[string]string{
+		"/pulled/kustomization.yaml":      "resources:\n- deployment.yaml\n",
+		"/pulled/deployment.yaml":         "kind: Deployment\n",
+		"/pulled/base/kustomization.yaml": "namePrefix: a-\n",
+	} {
+		b, err := fSys.ReadFile(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(b) != expected {
+			t.Fatalf("%s: expected %q, got %q", name, expected, string(b))
+		}
+	}
+	if fSys.Exists("/pulled/.git") {
+		t.Fatalf(".git shouldn't be pushed")
+	}
+
+	_, _, err = oci.Pull(fSys, host+"/example/app:v1", "/pulled", o)
+	if err == nil || !strings.Contains(err.Error(), "isn't an empty directory") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPushPullWithToken(t *testing.T) {
+	_, s := newRegistry(t, "t0ken")
+	host := strings.TrimPrefix(s.URL, "http://")
+	fSys := filesys.MakeFsInMemory()
+	writePackage(fSys)
+
+	empty, err := ioutil.TempDir("", "docker-config-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(empty)
+	_, err = oci.Push(fSys, "/app", host+"/example/app:v1", nil,
+		oci.Options{PlainHTTP: true, DockerConfigDir: empty})
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o := oci.Options{PlainHTTP: true, Username: "me", Password: "secret"}
+	pushed, err := oci.Push(fSys, "/app", host+"/example/app:v1", nil, o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pulled, _, err := oci.Pull(fSys, host+"/example/app:v1", "/pulled", o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pulled != pushed {
+		t.Fatalf("expected %s, got %s", pushed, pulled)
+	}
+}
+
+func TestResolveDigestWithDockerConfig(t *testing.T) {
+	reg, s := newRegistry(t, "t0ken")
+	host := strings.TrimPrefix(s.URL, "http://")
+	reg.manifests["1.19"] = []byte(`{"schemaVersion": 2}`)
+	expected := fmt.Sprintf("sha256:%x", sha256.Sum256(reg.manifests["1.19"]))
+
+	dir, err := ioutil.TempDir("", "docker-config-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(fmt.Sprintf(
+		`{"auths": {"http://%s/v2/": {"auth": "bWU6c2VjcmV0"}}}`, host)), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, err := oci.ResolveDigest(host+"/example/app:1.19",
+		oci.Options{PlainHTTP: true, DockerConfigDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Tag != "1.19" || r.Digest != expected {
+		t.Fatalf("expected %s, got %s", expected, r)
+	}
+
+	_, err = oci.ResolveDigest(host+"/example/app:1.20",
+		oci.Options{PlainHTTP: true, DockerConfigDir: dir})
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPushWithoutKustomization(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/deployment.yaml", []byte("kind: Deployment\n"))
+	_, err := oci.Push(fSys, "/app", "localhost/example/app:v1", nil, oci.Options{})
+	if err == nil || err.Error() != "no kustomization file in /app" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPullRejectsDigestMismatch(t *testing.T) {
+	reg, s := newRegistry(t, "")
+	host := strings.TrimPrefix(s.URL, "http://")
+	fSys := filesys.MakeFsInMemory()
+	writePackage(fSys)
+	o := oci.Options{PlainHTTP: true}
+	pushed, err := oci.Push(fSys, "/app", host+"/example/app:v1", nil, o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reg.manifests[pushed.Digest] = append(reg.manifests[pushed.Digest], ' ')
+	_, _, err = oci.Pull(fSys, host+"/example/app@"+pushed.Digest, "/pulled", o)
+	if err == nil || !strings.Contains(err.Error(), "got content with digest") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPullRejectsPathsOutsideDir(t *testing.T) {
+	reg, s := newRegistry(t, "")
+	host := strings.TrimPrefix(s.URL, "http://")
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "../evil.yaml", Size: 1, Mode: 0644})
+	tw.Write([]byte("x"))
+	tw.Close()
+	gz.Close()
+	layer := buf.Bytes()
+	layerDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(layer))
+	reg.blobs[layerDigest] = layer
+	m, _ := json.Marshal(oci.Manifest{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeManifest,
+		Config:        oci.Descriptor{MediaType: oci.MediaTypeConfig},
+		Layers: []oci.Descriptor{{
+			MediaType: oci.MediaTypeLayer, Digest: layerDigest, Size: int64(len(layer))}},
+	})
+	reg.manifests["evil"] = m
+
+	fSys := filesys.MakeFsInMemory()
+	_, _, err := oci.Pull(fSys, host+"/example/app:evil", "/pulled", oci.Options{PlainHTTP: true})
+	if err == nil || !strings.Contains(err.Error(), "refusing to unpack") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fSys.Exists("/evil.yaml") {
+		t.Fatalf("unpacked a file outside of the directory")
+	}
+}
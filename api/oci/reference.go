@@ -0,0 +1,127 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Scheme optionally prefixes references, to tell
+	// them apart from paths and URLs.
+	Scheme = "oci://"
+
+	defaultRegistry = "docker.io"
+	defaultTag      = "latest"
+)
+
+var (
+	repositoryRegex = regexp.MustCompile(`^[a-z0-9]+(?:[._-]+[a-z0-9]+)*(?:/[a-z0-9]+(?:[._-]+[a-z0-9]+)*)*$`)
+	tagRegex        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	digestRegex     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// Reference names a package in a registry, e.g.
+// ghcr.io/example/app:v1.0.0, or with a digest
+// pinning its content, ghcr.io/example/app@sha256:...
+type Reference struct {
+	// Registry is the host, and optionally
+	// the port, of the registry.
+	Registry string
+
+	// Repository is the path of the package
+	// in the registry, e.g. example/app.
+	Repository string
+
+	// Tag is the tag of the package, if any.
+	Tag string
+
+	// Digest is the digest of the manifest of
+	// the package, if any.  It takes precedence
+	// over the tag when pulling.
+	Digest string
+}
+
+// ParseReference parses a reference in the form used
+// by docker, optionally prefixed with oci://.  As with
+// docker, the registry defaults to docker.io, and the
+// tag to latest if there's no digest.
+func ParseReference(s string) (Reference, error) {
+	var r Reference
+	rest := strings.TrimPrefix(s, Scheme)
+	if i := strings.Index(rest, "@"); i >= 0 {
+		r.Digest = rest[i+1:]
+		rest = rest[:i]
+		if !digestRegex.MatchString(r.Digest) {
+			return Reference{}, fmt.Errorf(
+				"invalid digest %q in reference %q", r.Digest, s)
+		}
+	}
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		r.Tag = rest[i+1:]
+		rest = rest[:i]
+		if !tagRegex.MatchString(r.Tag) {
+			return Reference{}, fmt.Errorf(
+				"invalid tag %q in reference %q", r.Tag, s)
+		}
+	}
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) == 2 &&
+		(strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		r.Registry = parts[0]
+		r.Repository = parts[1]
+	} else {
+		r.Registry = defaultRegistry
+		r.Repository = rest
+		if len(parts) == 1 {
+			r.Repository = "library/" + rest
+		}
+	}
+	if !repositoryRegex.MatchString(r.Repository) {
+		return Reference{}, fmt.Errorf(
+			"invalid repository %q in reference %q", r.Repository, s)
+	}
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = defaultTag
+	}
+	return r, nil
+}
+
+// String returns the reference in the form parsed
+// by ParseReference, without the scheme.
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// WithDigest returns the reference pinned to digest.
+func (r Reference) WithDigest(digest string) Reference {
+	r.Digest = digest
+	return r
+}
+
+// host returns the host serving the registry's API.
+func (r Reference) host() string {
+	if r.Registry == defaultRegistry {
+		return "registry-1.docker.io"
+	}
+	return r.Registry
+}
+
+// version returns the tag or digest to fetch
+// the manifest of the package with.
+func (r Reference) version() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	testCases := []struct {
+		input    string
+		expected Reference
+		str      string
+	}{
+		{
+			input:    "ghcr.io/example/app:v1.0.0",
+			expected: Reference{Registry: "ghcr.io", Repository: "example/app", Tag: "v1.0.0"},
+			str:      "ghcr.io/example/app:v1.0.0",
+		},
+		{
+			input:    "oci://localhost:5000/app",
+			expected: Reference{Registry: "localhost:5000", Repository: "app", Tag: "latest"},
+			str:      "localhost:5000/app:latest",
+		},
+		{
+			input:    "localhost/app@" + digest,
+			expected: Reference{Registry: "localhost", Repository: "app", Digest: digest},
+			str:      "localhost/app@" + digest,
+		},
+		{
+			input:    "example/app:v1@" + digest,
+			expected: Reference{Registry: "docker.io", Repository: "example/app", Tag: "v1", Digest: digest},
+			str:      "docker.io/example/app:v1@" + digest,
+		},
+		{
+			input:    "app",
+			expected: Reference{Registry: "docker.io", Repository: "library/app", Tag: "latest"},
+			str:      "docker.io/library/app:latest",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := ParseReference(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r != tc.expected {
+				t.Fatalf("expected %+v, got %+v", tc.expected, r)
+			}
+			if r.String() != tc.str {
+				t.Fatalf("expected %s, got %s", tc.str, r.String())
+			}
+		})
+	}
+}
+
+func TestParseReferenceErrors(t *testing.T) {
+	for _, input := range []string{
+		"ghcr.io/Example/app",
+		"ghcr.io/example/app:",
+		"ghcr.io/example/app@sha256:abc",
+		"ghcr.io/",
+	} {
+		if _, err := ParseReference(input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
@@ -4,6 +4,9 @@
 package resmap
 
 import (
+	"path/filepath"
+	"strings"
+
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/kusterr"
@@ -49,7 +52,14 @@ func (rmF *Factory) FromFile(
 	}
 	m, err := rmF.NewResMapFromBytes(content)
 	if err != nil {
-		return nil, kusterr.Handler(err, path)
+		return nil, kusterr.InFile(kusterr.Handler(err, path), path, 0, 0)
+	}
+	origin := path
+	if !filepath.IsAbs(path) && !strings.Contains(path, "://") {
+		origin = filepath.Join(loader.Root(), path)
+	}
+	for _, r := range m.Resources() {
+		r.SetOrigin(origin)
 	}
 	return m, nil
 }
@@ -8,7 +8,9 @@ package resmap
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"regexp"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
@@ -133,6 +135,11 @@ type ResMap interface {
 	// AsYaml returns the yaml form of resources.
 	AsYaml() ([]byte, error)
 
+	// WriteYaml writes the yaml form of resources to w,
+	// one resource at a time, rather than holding all of
+	// it in memory like AsYaml.
+	WriteYaml(w io.Writer) error
+
 	// GetByIndex returns a resource at the given index,
 	// nil if out of range.
 	GetByIndex(int) *resource.Resource
@@ -209,6 +216,8 @@ type ResMap interface {
 	SubsetThatCouldBeReferencedByResource(*resource.Resource) ResMap
 
 	// DeepCopy copies the ResMap and underlying resources.
+	// The resources' data is copied on first change, so
+	// copies that are only read are cheap.
 	DeepCopy() ResMap
 
 	// ShallowCopy copies the ResMap but
@@ -346,7 +355,7 @@ func (m *resWrangler) Debug(title string) {
 			fmt.Println("---")
 		}
 		fmt.Printf("# %d  %s\n", i, r.OrgId())
-		blob, err := yaml.Marshal(r.Map())
+		blob, err := yaml.Marshal(r.ReadOnlyMap())
 		if err != nil {
 			panic(err)
 		}
@@ -495,26 +504,30 @@ func (m *resWrangler) groupedByOriginalNamespace() map[string][]*resource.Resour
 
 // AsYaml implements ResMap.
 func (m *resWrangler) AsYaml() ([]byte, error) {
-	firstObj := true
-	var b []byte
-	buf := bytes.NewBuffer(b)
-	for _, res := range m.Resources() {
-		out, err := yaml.Marshal(res.Map())
+	var buf bytes.Buffer
+	if err := m.WriteYaml(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteYaml implements ResMap.
+func (m *resWrangler) WriteYaml(w io.Writer) error {
+	for i, res := range m.rList {
+		out, err := yaml.Marshal(res.ReadOnlyMap())
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if firstObj {
-			firstObj = false
-		} else {
-			if _, err = buf.WriteString("---\n"); err != nil {
-				return nil, err
+		if i > 0 {
+			if _, err = io.WriteString(w, "---\n"); err != nil {
+				return err
 			}
 		}
-		if _, err = buf.Write(out); err != nil {
-			return nil, err
+		if _, err = w.Write(out); err != nil {
+			return err
 		}
 	}
-	return buf.Bytes(), nil
+	return nil
 }
 
 // ErrorIfNotEqualSets implements ResMap.
@@ -746,11 +759,25 @@ func anchorRegex(pattern string) string {
 	return "^" + pattern + "$"
 }
 
+// selectorRegexps caches the compiled selector patterns,
+// since the same selectors are used by many Selects.
+var selectorRegexps sync.Map
+
+// compileSelectorRegex returns the compiled anchored pattern.
+func compileSelectorRegex(pattern string) *regexp.Regexp {
+	if r, ok := selectorRegexps.Load(pattern); ok {
+		return r.(*regexp.Regexp)
+	}
+	r, _ := selectorRegexps.LoadOrStore(
+		pattern, regexp.MustCompile(anchorRegex(pattern)))
+	return r.(*regexp.Regexp)
+}
+
 // Select returns a list of resources that
 // are selected by a Selector
 func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
-	ns := regexp.MustCompile(anchorRegex(s.Namespace))
-	nm := regexp.MustCompile(anchorRegex(s.Name))
+	ns := compileSelectorRegex(s.Namespace)
+	nm := compileSelectorRegex(s.Name)
 	var result []*resource.Resource
 	for _, r := range m.Resources() {
 		curId := r.CurId()
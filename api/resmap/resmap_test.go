@@ -189,6 +189,46 @@ metadata:
 	}
 }
 
+// chunkWriter records each write separately.
+type chunkWriter struct {
+	chunks []string
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, string(p))
+	return len(p), nil
+}
+
+func TestWriteYaml(t *testing.T) {
+	input := resmaptest_test.NewRmBuilder(t, rf).Add(
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "cm1",
+			},
+		}).Add(
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "cm2",
+			},
+		}).ResMap()
+	var w chunkWriter
+	if err := input.WriteYaml(&w); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n",
+		"---\n",
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm2\n",
+	}
+	if !reflect.DeepEqual(w.chunks, expected) {
+		t.Fatalf("expected %q, got %q", expected, w.chunks)
+	}
+}
+
 func TestGetMatchingResourcesByCurrentId(t *testing.T) {
 	r1 := rf.FromMap(
 		map[string]interface{}{
@@ -70,6 +70,7 @@ func (rf *Factory) makeOne(
 	}
 	r := &Resource{
 		kunStr:  u,
+		refs:    newRefs(),
 		options: o,
 	}
 	return r.setOriginalName(r.kunStr.GetName()).setOriginalNs(r.GetNamespace())
@@ -87,7 +88,8 @@ func (rf *Factory) SliceFromPatches(
 		}
 		res, err := rf.SliceFromBytes(content)
 		if err != nil {
-			return nil, kusterr.Handler(err, string(path))
+			return nil, kusterr.InFile(
+				kusterr.Handler(err, string(path)), string(path), 0, 0)
 		}
 		result = append(result, res...)
 	}
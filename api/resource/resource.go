@@ -7,6 +7,7 @@ package resource
 import (
 	"reflect"
 	"strings"
+	"sync/atomic"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resid"
@@ -63,10 +64,17 @@ import (
 // dependence, switch to kyaml, and thus allow kustomize to be imported into
 // kubectl via normal Go module imports.
 //
+// Copies made by DeepCopy share their kunStr with the original until
+// one of them is changed, so copies that are only read are cheap.
+// Methods which change the kunStr, or expose it to changes (Map),
+// first call own.
 type Resource struct {
-	kunStr       ifc.Kunstructured
+	kunStr ifc.Kunstructured
+	// refs counts the Resources sharing kunStr.
+	refs         *int32
 	originalName string
 	originalNs   string
+	origin       string
 	options      *types.GenArgs
 	refBy        []resid.ResId
 	refVarNames  []string
@@ -75,7 +83,9 @@ type Resource struct {
 }
 
 func (r *Resource) ResetPrimaryData(incoming *Resource) {
-	r.kunStr = incoming.kunStr.Copy()
+	r.release()
+	r.kunStr = incoming.kunStr
+	r.refs = incoming.share()
 }
 
 func (r *Resource) GetAnnotations() map[string]string {
@@ -138,7 +148,16 @@ func (r *Resource) GetStringSlice(p string) ([]string, error) {
 	return r.kunStr.GetStringSlice(p)
 }
 
+// Map returns the data of the resource, which may be changed in
+// place, copying it first if it's shared with copies.
 func (r *Resource) Map() map[string]interface{} {
+	r.own()
+	return r.kunStr.Map()
+}
+
+// ReadOnlyMap returns the data of the resource without copying
+// it, so it may be shared with copies and must not be changed.
+func (r *Resource) ReadOnlyMap() map[string]interface{} {
 	return r.kunStr.Map()
 }
 
@@ -155,34 +174,42 @@ func (r *Resource) MatchesAnnotationSelector(selector string) (bool, error) {
 }
 
 func (r *Resource) Patch(other ifc.Kunstructured) error {
+	r.own()
 	return r.kunStr.Patch(other)
 }
 
 func (r *Resource) SetAnnotations(m map[string]string) {
+	r.own()
 	r.kunStr.SetAnnotations(m)
 }
 
 func (r *Resource) SetGvk(gvk resid.Gvk) {
+	r.own()
 	r.kunStr.SetGvk(gvk)
 }
 
 func (r *Resource) SetLabels(m map[string]string) {
+	r.own()
 	r.kunStr.SetLabels(m)
 }
 
 func (r *Resource) SetMap(m map[string]interface{}) {
+	r.own()
 	r.kunStr.SetMap(m)
 }
 
 func (r *Resource) SetName(n string) {
+	r.own()
 	r.kunStr.SetName(n)
 }
 
 func (r *Resource) SetNamespace(n string) {
+	r.own()
 	r.kunStr.SetNamespace(n)
 }
 
 func (r *Resource) UnmarshalJSON(s []byte) error {
+	r.own()
 	return r.kunStr.UnmarshalJSON(s)
 }
 
@@ -202,17 +229,51 @@ type ResCtx interface {
 // modified in the same kustomize context.
 type ResCtxMatcher func(ResCtx) bool
 
-// DeepCopy returns a new copy of resource
+// DeepCopy returns a new copy of resource.
+// The underlying data is only copied once either
+// the original or the copy is changed.
 func (r *Resource) DeepCopy() *Resource {
 	rc := &Resource{
-		kunStr: r.kunStr.Copy(),
+		kunStr: r.kunStr,
+		refs:   r.share(),
 	}
 	rc.copyOtherFields(r)
 	return rc
 }
 
+// newRefs returns the counter for a kunStr held
+// by one Resource.
+func newRefs() *int32 {
+	refs := int32(1)
+	return &refs
+}
+
+// share records that another Resource shares r's kunStr,
+// and returns the counter for that Resource to hold.
+func (r *Resource) share() *int32 {
+	atomic.AddInt32(r.refs, 1)
+	return r.refs
+}
+
+// own makes r the only holder of its kunStr, copying
+// it if it's shared, so that it can be changed.
+func (r *Resource) own() {
+	if atomic.LoadInt32(r.refs) == 1 {
+		return
+	}
+	r.kunStr = r.kunStr.Copy()
+	r.release()
+	r.refs = newRefs()
+}
+
+// release gives up r's share of its kunStr.
+func (r *Resource) release() {
+	atomic.AddInt32(r.refs, -1)
+}
+
 // Replace performs replace with other resource.
 func (r *Resource) Replace(other *Resource) {
+	r.own()
 	r.kunStr.SetLabels(mergeStringMaps(other.kunStr.GetLabels(), r.kunStr.GetLabels()))
 	r.kunStr.SetAnnotations(
 		mergeStringMaps(other.kunStr.GetAnnotations(), r.kunStr.GetAnnotations()))
@@ -224,6 +285,7 @@ func (r *Resource) Replace(other *Resource) {
 func (r *Resource) copyOtherFields(other *Resource) {
 	r.originalName = other.originalName
 	r.originalNs = other.originalNs
+	r.origin = other.origin
 	r.options = other.options
 	r.refBy = other.copyRefBy()
 	r.refVarNames = copyStringSlice(other.refVarNames)
@@ -382,6 +444,17 @@ func (r *Resource) setOriginalNs(n string) *Resource {
 	return r
 }
 
+// GetOrigin returns the file the resource was read
+// from, or "" if it wasn't read from a file.
+func (r *Resource) GetOrigin() string {
+	return r.origin
+}
+
+// SetOrigin records the file the resource was read from.
+func (r *Resource) SetOrigin(path string) {
+	r.origin = path
+}
+
 // String returns resource as JSON.
 func (r *Resource) String() string {
 	bs, err := r.kunStr.MarshalJSON()
@@ -18,6 +18,7 @@ package resource_test
 
 import (
 	"reflect"
+	"sync"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
@@ -137,3 +138,71 @@ func TestDeepCopy(t *testing.T) {
 		t.Errorf("expected %v\nbut got%v", r, cr)
 	}
 }
+
+func TestDeepCopyIsCopyOnWrite(t *testing.T) {
+	r := factory.FromMap(
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "winnie",
+			},
+			"data": map[string]interface{}{
+				"a": "b",
+			},
+		})
+	c1 := r.DeepCopy()
+	c2 := c1.DeepCopy()
+
+	c1.SetName("pooh")
+	c2.Map()["data"].(map[string]interface{})["a"] = "c"
+	if r.GetName() != "winnie" || c1.GetName() != "pooh" || c2.GetName() != "winnie" {
+		t.Fatalf("unexpected names %s, %s, %s",
+			r.GetName(), c1.GetName(), c2.GetName())
+	}
+	for _, x := range []*Resource{r, c1} {
+		if v, _ := x.GetString("data.a"); v != "b" {
+			t.Fatalf("change to copy leaked into %s", x.GetName())
+		}
+	}
+	if v, _ := c2.GetString("data.a"); v != "c" {
+		t.Fatalf("expected change to copy, got %s", v)
+	}
+
+	// r is the last holder, so it's changed in place.
+	r.SetName("piglet")
+	if c1.GetName() != "pooh" || c2.GetName() != "winnie" {
+		t.Fatalf("change to original leaked into copies")
+	}
+}
+
+func TestDeepCopyConcurrentReads(t *testing.T) {
+	r := factory.FromMap(
+		map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "winnie",
+			},
+		})
+	var wg sync.WaitGroup
+	copies := make([]*Resource, 8)
+	for i := range copies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			copies[i] = r.DeepCopy()
+			copies[i].ReadOnlyMap()
+			if _, err := copies[i].GetFieldValue("metadata.name"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	// Reads don't copy the data.
+	for _, c := range copies {
+		if reflect.ValueOf(c.ReadOnlyMap()).Pointer() != reflect.ValueOf(r.ReadOnlyMap()).Pointer() {
+			t.Fatalf("read of copy copied the data")
+		}
+	}
+}
@@ -75,6 +75,7 @@ func (th Harness) MakeOptionsPluginsDisabled() krusty.Options {
 	return krusty.Options{
 		LoadRestrictions: types.LoadRestrictionsRootOnly,
 		PluginConfig:     konfig.DisabledPluginConfig(),
+		Warner:           th.warner(),
 	}
 }
 
@@ -92,9 +93,27 @@ func (th Harness) MakeOptionsPluginsEnabled() krusty.Options {
 	return krusty.Options{
 		LoadRestrictions: types.LoadRestrictionsRootOnly,
 		PluginConfig:     c,
+		Warner:           th.warner(),
 	}
 }
 
+// warner returns a Warner writing warnings to the log of
+// the test, which is only shown if it fails or is verbose,
+// rather than to stderr.
+func (th Harness) warner() types.Warner {
+	return types.NewWriterWarner(testLogWriter{th.t})
+}
+
+// testLogWriter writes to the log of a test.
+type testLogWriter struct {
+	t *testing.T
+}
+
+func (w testLogWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
 // Run, failing on error.
 func (th Harness) Run(path string, o krusty.Options) resmap.ResMap {
 	m, err := krusty.MakeKustomizer(th.fSys, &o).Run(path)
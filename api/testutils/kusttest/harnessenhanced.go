@@ -37,6 +37,9 @@ type HarnessEnhanced struct {
 
 	// A plugin loader that loads plugins from a (real) file system.
 	pl *pLdr.Loader
+
+	// The configuration of pl.
+	pc *types.PluginConfig
 }
 
 func MakeEnhancedHarness(t *testing.T) *HarnessEnhanced {
@@ -55,7 +58,8 @@ func MakeEnhancedHarness(t *testing.T) *HarnessEnhanced {
 		Harness: MakeHarness(t),
 		pte:     pte,
 		rf:      rf,
-		pl:      pLdr.NewLoader(pc, rf)}
+		pl:      pLdr.NewLoader(pc, rf),
+		pc:      pc}
 
 	// Point the file loader to the root ('/') of the in-memory file system.
 	result.ResetLoaderRoot(filesys.Separator)
@@ -67,6 +71,14 @@ func (th *HarnessEnhanced) Reset() {
 	th.pte.reset()
 }
 
+// SetFnpLoadingOptions sets the options restricting
+// what the plugins loaded may run.
+func (th *HarnessEnhanced) SetFnpLoadingOptions(
+	o types.FnPluginLoadingOptions) *HarnessEnhanced {
+	th.pc.FnpLoadingOptions = o
+	return th
+}
+
 func (th *HarnessEnhanced) PrepBuiltin(k string) *HarnessEnhanced {
 	return th.BuildGoPlugin(konfig.BuiltinPluginPackage, "", k)
 }
@@ -5,8 +5,9 @@ package transform
 
 import (
 	"fmt"
-	"log"
 	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 type mutateFunc func(interface{}) (interface{}, error)
@@ -45,9 +46,8 @@ func MutateField(
 	newPathToField := pathToField[1:]
 	switch typedV := v.(type) {
 	case nil:
-		log.Printf(
-			"nil value at `%s` ignored in mutation attempt",
-			strings.Join(pathToField, "."))
+		log.Debug("nil value ignored in mutation attempt",
+			"path", strings.Join(pathToField, "."))
 		return nil
 	case map[string]interface{}:
 		return MutateField(typedV, newPathToField, createIfNotPresent, fns...)
@@ -3,6 +3,12 @@
 
 package types
 
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/image"
+)
+
 // Image contains an image name, a new name, a new tag or digest,
 // which will replace the original name and tag.
 type Image struct {
@@ -19,3 +25,29 @@ type Image struct {
 	// If digest is present NewTag value is ignored.
 	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
 }
+
+// Validate returns an error if the names, tag or digest
+// of img are malformed, or if it has both a tag and a
+// digest, since the tag would be ignored.
+func (img Image) Validate() error {
+	if img.Name == "" {
+		return fmt.Errorf("image has no name")
+	}
+	err := image.ValidateName(img.Name)
+	if err == nil && img.NewName != "" {
+		err = image.ValidateName(img.NewName)
+	}
+	if err == nil && img.NewTag != "" && img.Digest != "" {
+		err = fmt.Errorf("both a new tag and a digest are given; the tag would be ignored")
+	}
+	if err == nil && img.NewTag != "" {
+		err = image.ValidateTag(img.NewTag)
+	}
+	if err == nil && img.Digest != "" {
+		err = image.ValidateDigest(img.Digest)
+	}
+	if err != nil {
+		return fmt.Errorf("image %s: %v", img.Name, err)
+	}
+	return nil
+}
@@ -18,6 +18,13 @@ const (
 	MetadataNamespacePath = "metadata/namespace"
 )
 
+// The values of Kustomization.DuplicateResources.
+const (
+	DuplicateResourcesError   = "error"
+	DuplicateResourcesMerge   = "merge"
+	DuplicateResourcesReplace = "replace"
+)
+
 // Kustomization holds the information needed to generate customized k8s api resources.
 type Kustomization struct {
 	TypeMeta `json:",inline" yaml:",inline"`
@@ -34,6 +41,14 @@ type Kustomization struct {
 	// file including generated configmaps and secrets.
 	NameSuffix string `json:"nameSuffix,omitempty" yaml:"nameSuffix,omitempty"`
 
+	// NamePrefixTargets, if not empty, limits NamePrefix to the resources
+	// matching at least one of the selectors.
+	NamePrefixTargets []*Selector `json:"namePrefixTargets,omitempty" yaml:"namePrefixTargets,omitempty"`
+
+	// NameSuffixTargets, if not empty, limits NameSuffix to the resources
+	// matching at least one of the selectors.
+	NameSuffixTargets []*Selector `json:"nameSuffixTargets,omitempty" yaml:"nameSuffixTargets,omitempty"`
+
 	// Namespace to add to all objects.
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
@@ -86,6 +101,14 @@ type Kustomization struct {
 	// via relative paths, absolute paths, or URLs.
 	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
 
+	// DuplicateResources sets what happens when a resource of
+	// Resources, or of the bases it lists, has the ID of an
+	// earlier one: "error", the default, fails; "merge" patches
+	// the earlier resource with the later one, as a strategic
+	// merge patch; and "replace" replaces the earlier resource
+	// with the later one.
+	DuplicateResources string `json:"duplicateResources,omitempty" yaml:"duplicateResources,omitempty"`
+
 	// Components specifies relative paths to specifications of other Components
 	// via relative paths, absolute paths, or URLs.
 	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
@@ -119,6 +142,14 @@ type Kustomization struct {
 	// the map will have a suffix hash generated from its contents.
 	SecretGenerator []SecretArgs `json:"secretGenerator,omitempty" yaml:"secretGenerator,omitempty"`
 
+	// SealedSecretGenerator is a list of Bitnami SealedSecrets
+	// to generate from local data (one per list item), which
+	// are encrypted with the given certificate as they're
+	// generated, so the plain secrets never appear in the output.
+	// The encryption is randomized, so the encrypted values
+	// differ on every build.
+	SealedSecretGenerator []SealedSecretArgs `json:"sealedSecretGenerator,omitempty" yaml:"sealedSecretGenerator,omitempty"`
+
 	// GeneratorOptions modify behavior of all ConfigMap and Secret generators.
 	GeneratorOptions *GeneratorOptions `json:"generatorOptions,omitempty" yaml:"generatorOptions,omitempty"`
 
@@ -170,6 +201,17 @@ func (k *Kustomization) EnforceFields() []string {
 	if k.APIVersion != "" && k.APIVersion != requiredVersion {
 		errs = append(errs, "apiVersion for "+k.Kind+" should be "+requiredVersion)
 	}
+	switch k.DuplicateResources {
+	case "", DuplicateResourcesError, DuplicateResourcesMerge, DuplicateResourcesReplace:
+	default:
+		errs = append(errs, "duplicateResources should be "+DuplicateResourcesError+
+			", "+DuplicateResourcesMerge+" or "+DuplicateResourcesReplace)
+	}
+	for _, img := range k.Images {
+		if err := img.Validate(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
 	return errs
 }
 
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -135,6 +136,31 @@ func TestEnforceFields(t *testing.T) {
 	}
 }
 
+func TestEnforceFields_InvalidImages(t *testing.T) {
+	k := Kustomization{
+		TypeMeta: TypeMeta{
+			Kind:       KustomizationKind,
+			APIVersion: KustomizationVersion,
+		},
+		Images: []Image{
+			{Name: "nginx", NewTag: "1.19"},
+			{Name: "nginx:1.18", NewTag: "1.19"},
+			{Name: "app", NewTag: "v1", Digest: "sha256:24a0c4b4"},
+			{Name: "app", Digest: "sha256:24a0c4b4"},
+		},
+	}
+
+	errs := k.EnforceFields()
+	expected := []string{
+		`image nginx:1.18: image name "nginx:1.18" has a tag; give it as the new tag`,
+		`image app: both a new tag and a digest are given; the tag would be ignored`,
+		`image app: invalid digest "sha256:24a0c4b4": sha256 digests have 64 hex digits, not 8`,
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Fatalf("errors should be %v but got: %v", expected, errs)
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	y := []byte(`
 apiVersion: kustomize.config.k8s.io/v1beta1
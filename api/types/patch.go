@@ -16,4 +16,14 @@ type Patch struct {
 
 	// Target points to the resources that the patch is applied to
 	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// When true, the build fails if Target selects no
+	// resources, as it does for every patch when builds
+	// are made with patches that must match.
+	FailOnNoMatch bool `json:"failOnNoMatch,omitempty" yaml:"failOnNoMatch,omitempty"`
+
+	// When true, Target may select no resources, even in
+	// builds made with patches that must match, and it
+	// isn't warned about.
+	AllowNoMatch bool `json:"allowNoMatch,omitempty" yaml:"allowNoMatch,omitempty"`
 }
@@ -48,6 +48,10 @@ type FnPluginLoadingOptions struct {
 	EnableExec bool
 	// Allow to run starlark
 	EnableStar bool
+	// Allow to evaluate rego policies with opa
+	EnableRego bool
+	// Allow to run ytt overlays with YttOverlayTransformer
+	EnableYtt bool
 	// Allow container access to network
 	Network     bool
 	NetworkName string
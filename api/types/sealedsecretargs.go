@@ -0,0 +1,27 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// SealedSecretArgs contains the metadata of how to generate
+// a Bitnami SealedSecret, which holds a secret encrypted
+// so that only the sealed secrets controller can read it.
+type SealedSecretArgs struct {
+	// SecretArgs for the secret that is sealed.  The generated
+	// SealedSecret never gets a name suffix hash, since its
+	// name is part of what's sealed.
+	SecretArgs `json:",inline,omitempty" yaml:",inline,omitempty"`
+
+	// Cert is the path of the PEM encoded certificate
+	// of the sealed secrets controller, as printed
+	// by "kubeseal --fetch-cert".
+	Cert string `json:"cert,omitempty" yaml:"cert,omitempty"`
+
+	// Scope limits where the sealed secret may be unsealed:
+	// "strict" (default) only with the same name and namespace,
+	// "namespace-wide" only in the same namespace, and
+	// "cluster-wide" anywhere.  The build fails if a
+	// namePrefix, nameSuffix or namespace changes the name
+	// or namespace the scope seals it to.
+	Scope string `json:"scope,omitempty" yaml:"scope,omitempty"`
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Which files encrypted with SOPS (https://github.com/mozilla/sops)
+// are decrypted as they're read.
+//
+//go:generate stringer -type=SopsDecryption
+type SopsDecryption int
+
+const (
+	// No files are decrypted.
+	SopsDecryptionNone SopsDecryption = iota
+
+	// Only the files and env files read by secretGenerators
+	// and sealedSecretGenerators are decrypted.
+	SopsDecryptionSecrets
+
+	// Every file read is decrypted, including resources
+	// and patches.
+	SopsDecryptionAll
+)
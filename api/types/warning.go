@@ -0,0 +1,121 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// WarningCode identifies the kind of a Warning.
+type WarningCode string
+
+const (
+	// A deprecated field, or a field not spelled
+	// in the case declared, in a kustomization file.
+	WarningDeprecatedField WarningCode = "DeprecatedField"
+
+	// A reference to a ConfigMap, Secret or
+	// Service which isn't in the output.
+	WarningDanglingReference WarningCode = "DanglingReference"
+
+	// Vars which no field refers to.
+	WarningUnusedVars WarningCode = "UnusedVars"
+
+	// A reference, $(NAME), to a name which is neither a
+	// var nor an env var of the resource.
+	WarningUnresolvedVars WarningCode = "UnresolvedVars"
+
+	// A patch whose target selects no resources.
+	WarningPatchMatchedNothing WarningCode = "PatchMatchedNothing"
+
+	// A patch whose target selects every resource of a
+	// kind, or every resource, and more than one.
+	WarningPatchMatchedBroadly WarningCode = "PatchMatchedBroadly"
+
+	// A file of resources which holds none.
+	WarningEmptyFile WarningCode = "EmptyFile"
+
+	// A resource which doesn't match the schema of its kind.
+	WarningSchemaProblem WarningCode = "SchemaProblem"
+
+	// A resource of a kind without a schema.
+	WarningMissingSchema WarningCode = "MissingSchema"
+
+	// A resource which the cluster rejected
+	// in a server-side dry-run.
+	WarningDryRunRejected WarningCode = "DryRunRejected"
+
+	// A resource whose fields are owned by other field
+	// managers, found in a server-side dry-run.
+	WarningDryRunConflict WarningCode = "DryRunConflict"
+
+	// A namespaced resource of the output without a namespace,
+	// which would be applied to the default namespace.
+	WarningMissingNamespace WarningCode = "MissingNamespace"
+
+	// A resource whose namespace the namespace
+	// field of a kustomization replaces.
+	WarningNamespaceReplaced WarningCode = "NamespaceReplaced"
+)
+
+// Warning is something questionable about a build, which
+// doesn't fail it.
+type Warning struct {
+	Code WarningCode
+
+	// Message describes what's questionable.
+	Message string
+
+	// Resource is the ID of the resource warned
+	// about, if any.
+	Resource string
+
+	// Location is the file warned about, if any, with the
+	// line and column in it, e.g. kustomization.yaml:3:1,
+	// if known.
+	Location string
+}
+
+func (w Warning) String() string {
+	s := w.Message
+	if w.Resource != "" {
+		s = w.Resource + ": " + s
+	}
+	if w.Location != "" {
+		s = w.Location + ": " + s
+	}
+	return s
+}
+
+// KeysAndValues returns the fields of w which are set,
+// as alternating keys and values, e.g. for logging.
+func (w Warning) KeysAndValues() []interface{} {
+	kv := []interface{}{"code", string(w.Code)}
+	if w.Resource != "" {
+		kv = append(kv, "resource", w.Resource)
+	}
+	if w.Location != "" {
+		kv = append(kv, "location", w.Location)
+	}
+	return kv
+}
+
+// Warner is given the warnings of a build as they're
+// found.  It must be safe for concurrent use, since
+// bases may be accumulated concurrently.
+type Warner func(Warning)
+
+// NewWriterWarner returns a Warner writing each warning
+// to w as a line, rather than logging it, e.g. to keep
+// warnings apart from logs, or to capture them in tests.
+func NewWriterWarner(w io.Writer) Warner {
+	var mu sync.Mutex
+	return func(warning Warning) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "warning: %s (%s)\n", warning, warning.Code)
+	}
+}
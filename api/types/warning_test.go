@@ -0,0 +1,25 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewWriterWarner(t *testing.T) {
+	var b bytes.Buffer
+	warn := NewWriterWarner(&b)
+	warn(Warning{
+		Code:     WarningDeprecatedField,
+		Message:  "bases is deprecated",
+		Location: "kustomization.yaml:3:1",
+	})
+	warn(Warning{Code: WarningEmptyFile, Message: "no resources"})
+	expected := "warning: kustomization.yaml:3:1: bases is deprecated (DeprecatedField)\n" +
+		"warning: no resources (EmptyFile)\n"
+	if b.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, b.String())
+	}
+}
@@ -5,6 +5,7 @@ package complete
 
 import (
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/posener/complete/v2"
@@ -52,7 +53,10 @@ func Complete(cmd *cobra.Command) *complete.Command {
 		Flags: map[string]complete.Predictor{},
 		Sub:   map[string]*complete.Command{},
 	}
-	if strings.Contains(cmd.Use, "DIR") {
+	if cmd.ValidArgsFunction != nil {
+		// if the command provides dynamic completion, then use it to predict the args
+		cc.Args = argsPredictor(cmd)
+	} else if strings.Contains(cmd.Use, "DIR") {
 		// if usage contains directory, then use a file predictor
 		cc.Args = predict.Dirs("*")
 	}
@@ -70,3 +74,51 @@ func Complete(cmd *cobra.Command) *complete.Command {
 	})
 	return cc
 }
+
+// argsPredictor returns a Predictor which invokes the ValidArgsFunction of cmd
+// with the arguments already present on the command line.
+func argsPredictor(cmd *cobra.Command) complete.Predictor {
+	return complete.PredictFunc(func(prefix string) []string {
+		values, directive := cmd.ValidArgsFunction(cmd, completedArgs(cmd), prefix)
+		if directive&cobra.ShellCompDirectiveError != 0 {
+			return nil
+		}
+		if len(values) == 0 && directive&cobra.ShellCompDirectiveNoFileComp == 0 {
+			// no values -- fallback to completing directories
+			return predict.Dirs("*").Predict(prefix)
+		}
+		return values
+	})
+}
+
+// completedArgs returns the positional arguments for cmd which precede the
+// word being completed on the command line.
+func completedArgs(cmd *cobra.Command) []string {
+	line := os.Getenv("COMP_LINE")
+	if point, err := strconv.Atoi(os.Getenv("COMP_POINT")); err == nil && point < len(line) {
+		line = line[:point]
+	}
+	words := strings.Fields(line)
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		// the last word is the one being completed
+		words = words[:len(words)-1]
+	}
+
+	// skip the program name and the names of the commands
+	i := 1
+	for _, name := range strings.Fields(cmd.CommandPath())[1:] {
+		for i < len(words) && words[i] != name {
+			i++
+		}
+		i++
+	}
+	if i > len(words) {
+		return nil
+	}
+
+	// parse the flags so they aren't mistaken for arguments
+	if err := cmd.ParseFlags(words[i:]); err != nil {
+		return nil
+	}
+	return cmd.Flags().Args()
+}
@@ -21,11 +21,15 @@ func GetCfg(name string) *cobra.Command {
 	cmd.AddCommand(commands.CreateSubstitutionCommand(name))
 	cmd.AddCommand(commands.FmtCommand(name))
 	cmd.AddCommand(commands.GrepCommand(name))
+	cmd.AddCommand(commands.ImportCommand(name))
+	cmd.AddCommand(commands.InferSettersCommand(name))
 	cmd.AddCommand(commands.InitCommand(name))
+	cmd.AddCommand(commands.LabelCommand(name))
 	cmd.AddCommand(commands.ListSettersCommand(name))
 	cmd.AddCommand(commands.MergeCommand(name))
 	cmd.AddCommand(commands.Merge3Command(name))
 	cmd.AddCommand(commands.SetCommand(name))
+	cmd.AddCommand(commands.SuggestSettersCommand(name))
 	cmd.AddCommand(commands.TreeCommand(name))
 
 	return cmd
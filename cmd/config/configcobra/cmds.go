@@ -22,7 +22,10 @@ var (
 	DeleteSetter       = commands.DeleteSetterCommand
 	Fmt                = commands.FmtCommand
 	Grep               = commands.GrepCommand
+	Import             = commands.ImportCommand
+	InferSetters       = commands.InferSettersCommand
 	Init               = commands.InitCommand
+	Label              = commands.LabelCommand
 	ListSetters        = commands.ListSettersCommand
 	Merge              = commands.MergeCommand
 	Merge3             = commands.Merge3Command
@@ -30,6 +33,7 @@ var (
 	Set                = commands.SetCommand
 	Sink               = commands.SinkCommand
 	Source             = commands.SourceCommand
+	SuggestSetters     = commands.SuggestSettersCommand
 	Tree               = commands.TreeCommand
 	Wrap               = commands.WrapCommand
 	XArgs              = commands.XArgsCommand
@@ -6,6 +6,7 @@ require (
 	github.com/go-errors/errors v1.0.1
 	github.com/go-openapi/spec v0.19.5
 	github.com/olekukonko/tablewriter v0.0.4
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/posener/complete/v2 v2.0.1-alpha.12
 	github.com/spf13/cobra v1.0.0
 	github.com/spf13/pflag v1.0.5
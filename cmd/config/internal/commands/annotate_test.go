@@ -85,6 +85,84 @@ func TestAnnotateCommand(t *testing.T) {
 	}
 }
 
+func TestAnnotateCommand_dryRunDiff(t *testing.T) {
+	d := initTestDir(t)
+	defer os.RemoveAll(d)
+
+	a := NewAnnotateRunner("")
+	a.Command.SetArgs([]string{d, "--kv", "a=b", "--name", "bar", "--dry-run", "--diff"})
+	out := &bytes.Buffer{}
+	a.Command.SetOut(out)
+	a.Command.SilenceUsage = true
+	a.Command.SilenceErrors = true
+	if !assert.NoError(t, a.Command.Execute()) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, `--- a/f2.yaml
++++ b/f2.yaml
+@@ -19,6 +19,7 @@
+   name: bar
+   annotations:
+     app: nginx
++    a: 'b'
+   namespace: foo
+ spec:
+   replicas: 3
+`, out.String())
+
+	// the files must not be modified
+	b, err := ioutil.ReadFile(filepath.Join(d, "f2.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, f2Input, string(b))
+}
+
+func TestAnnotateCommand_subpackages(t *testing.T) {
+	d := initTestDir(t)
+	defer os.RemoveAll(d)
+	sub := filepath.Join(d, "sub")
+	if !assert.NoError(t, os.Mkdir(sub, 0700)) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(sub, "Kptfile"), []byte(""), 0600)) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(sub, "f3.yaml"), []byte(`kind: Service
+metadata:
+  name: sub
+`), 0600)) {
+		t.FailNow()
+	}
+
+	a := NewAnnotateRunner("")
+	a.Command.SetArgs([]string{d, "--kv", "a=b", "--include-subpackages=false"})
+	a.Command.SilenceUsage = true
+	a.Command.SilenceErrors = true
+	if !assert.NoError(t, a.Command.Execute()) {
+		t.FailNow()
+	}
+	b, err := ioutil.ReadFile(filepath.Join(sub, "f3.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NotContains(t, string(b), "a: 'b'")
+
+	a = NewAnnotateRunner("")
+	a.Command.SetArgs([]string{d, "--kv", "a=b"})
+	a.Command.SilenceUsage = true
+	a.Command.SilenceErrors = true
+	if !assert.NoError(t, a.Command.Execute()) {
+		t.FailNow()
+	}
+	b, err = ioutil.ReadFile(filepath.Join(sub, "f3.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, string(b), "a: 'b'")
+}
+
 func initTestDir(t *testing.T) string {
 	d, err := ioutil.TempDir("", "kustomize-annotate-test")
 	if !assert.NoError(t, err) {
@@ -56,9 +56,21 @@ func NewCreateSetterRunner(parent string) *CreateSetterRunner {
 		"use this version of the setter format")
 	set.Flags().BoolVar(&r.CreateSetter.Required, "required", false,
 		"indicates that this setter must be set by package consumer before live apply/preview")
+	set.Flags().BoolVar(&r.CreateSetter.RecordHistory, "record-history", false,
+		"record each value the setter is set to, with who set it and when")
+	set.Flags().BoolVar(&r.CreateSetter.Locked, "locked", false,
+		"pin the value of the setter, so it can only be changed with set --force")
 	set.Flags().StringVar(&r.SchemaPath, "schema-path", "",
 		`openAPI schema file path for setter constraints -- file content `+
 			`e.g. {"type": "string", "maxLength": 15, "enum": ["allowedValue1", "allowedValue2"]}`)
+	set.Flags().Float64Var(&r.Minimum, "minimum", 0,
+		"the least value the setter may be set to")
+	set.Flags().Float64Var(&r.Maximum, "maximum", 0,
+		"the greatest value the setter may be set to")
+	set.Flags().Int64Var(&r.MaxLength, "max-length", 0,
+		"the most characters a value of the setter may have")
+	set.Flags().StringVar(&r.CreateSetter.Pattern, "pattern", "",
+		"regular expression which values of the setter must match")
 	set.Flags().MarkHidden("version")
 	fixDocs(parent, set)
 	r.Command = set
@@ -75,6 +87,9 @@ type CreateSetterRunner struct {
 	CreateSetter settersutil.SetterCreator
 	OpenAPIFile  string
 	SchemaPath   string
+	Minimum      float64
+	Maximum      float64
+	MaxLength    int64
 }
 
 func (r *CreateSetterRunner) runE(c *cobra.Command, args []string) error {
@@ -133,6 +148,15 @@ func (r *CreateSetterRunner) preRunE(c *cobra.Command, args []string) error {
 		r.CreateSetter.Description = r.Set.SetPartialField.Description
 		r.CreateSetter.SetBy = r.Set.SetPartialField.SetBy
 		r.CreateSetter.Type = r.Set.SetPartialField.Type
+		if c.Flag("minimum").Changed {
+			r.CreateSetter.Minimum = &r.Minimum
+		}
+		if c.Flag("maximum").Changed {
+			r.CreateSetter.Maximum = &r.Maximum
+		}
+		if c.Flag("max-length").Changed {
+			r.CreateSetter.MaxLength = &r.MaxLength
+		}
 
 		err = r.processSchema()
 		if err != nil {
@@ -30,7 +30,10 @@ func NewCreateSubstitutionRunner(parent string) *CreateSubstitutionRunner {
 		"value of the field to create substitution for -- e.g. --field-value nginx:0.1.0")
 	cs.Flags().StringVar(&r.CreateSubstitution.Pattern, "pattern", "",
 		`substitution pattern -- e.g. --pattern \${my-image-setter}:\${my-tag-setter}`)
-	_ = cs.MarkFlagRequired("pattern")
+	cs.Flags().StringVar(&r.CreateSubstitution.Regex, "regex", "",
+		`substitution regex, instead of --pattern, whose named groups are setters -- e.g. --regex ':(?P<tag>[^:]+)$'`)
+	cs.Flags().StringToStringVar(&r.CreateSubstitution.Defaults, "default", nil,
+		"value of a setter of the substitution to use when the setter is empty -- e.g. --default my-tag-setter=latest")
 	_ = cs.MarkFlagRequired("field-value")
 	fixDocs(parent, cs)
 	r.Command = cs
@@ -59,6 +62,10 @@ func (r *CreateSubstitutionRunner) preRunE(c *cobra.Command, args []string) erro
 		return err
 	}
 
+	if (r.CreateSubstitution.Pattern == "") == (r.CreateSubstitution.Regex == "") {
+		return errors.Errorf("one of --pattern and --regex must be specified")
+	}
+
 	r.OpenAPIFile, err = ext.GetOpenAPIFile(args)
 	if err != nil {
 		return err
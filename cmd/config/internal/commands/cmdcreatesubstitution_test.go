@@ -104,6 +104,78 @@ spec:
         image: sidecar:1.7.9
  `,
 		},
+		{
+			name: "substitution regex",
+			args: []string{
+				"image-tag", "--field", "image", "--field-value", "nginx:1.7.9",
+				"--regex", `^(?:.*/)?[^/:]+:(?P<tag>[^@]+)$`},
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9
+      - name: sidecar
+        image: gcr.io/example/sidecar:1.7.9
+      - name: untagged
+        image: busybox
+ `,
+			inputOpenAPI: `
+apiVersion: v1alpha1
+kind: Example
+ `,
+			expectedOpenAPI: `
+apiVersion: v1alpha1
+kind: Example
+openAPI:
+  definitions:
+    io.k8s.cli.substitutions.image-tag:
+      x-k8s-cli:
+        substitution:
+          name: image-tag
+          regex: ^(?:.*/)?[^/:]+:(?P<tag>[^@]+)$
+          values:
+          - marker: tag
+            ref: '#/definitions/io.k8s.cli.setters.tag'
+    io.k8s.cli.setters.tag:
+      x-k8s-cli:
+        setter:
+          name: tag
+          value: 1.7.9
+ `,
+			expectedResources: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$openapi":"image-tag"}
+      - name: sidecar
+        image: gcr.io/example/sidecar:1.7.9 # {"$openapi":"image-tag"}
+      - name: untagged
+        image: busybox
+ `,
+		},
+		{
+			name: "error if neither pattern nor regex",
+			args: []string{"my-image-subst", "--field-value", "nginx:1.7.9"},
+			inputOpenAPI: `
+apiVersion: v1alpha1
+kind: Example
+ `,
+			err: "one of --pattern and --regex must be specified",
+		},
 		{
 			name: "error if setter with same name exists",
 			args: []string{
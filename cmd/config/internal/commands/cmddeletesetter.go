@@ -22,6 +22,8 @@ func NewDeleteSetterRunner(parent string) *DeleteSetterRunner {
 		Example: commands.DeleteSetterExamples,
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
+
+		ValidArgsFunction: completeSetterNames,
 	}
 	fixDocs(parent, c)
 	r.Command = c
@@ -0,0 +1,77 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/cmd/config/ext"
+	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/kyaml/setters2/settersutil"
+)
+
+// NewInferSettersRunner returns a command runner.
+func NewInferSettersRunner(parent string) *InferSettersRunner {
+	r := &InferSettersRunner{}
+	c := &cobra.Command{
+		Use:     "infer-setters DIR",
+		Args:    cobra.ExactArgs(1),
+		Short:   commands.InferSettersShort,
+		Long:    commands.InferSettersLong,
+		Example: commands.InferSettersExamples,
+		RunE:    r.runE,
+	}
+	c.Flags().BoolVar(&r.Inferrer.DryRun, "dry-run", false,
+		"print the setters which would be updated, without updating them")
+	c.Flags().StringVar(&r.Inferrer.SetBy, "set-by", "",
+		"annotate the setters with who set them")
+	fixDocs(parent, c)
+	r.Command = c
+	return r
+}
+
+func InferSettersCommand(parent string) *cobra.Command {
+	return NewInferSettersRunner(parent).Command
+}
+
+type InferSettersRunner struct {
+	Command  *cobra.Command
+	Inferrer settersutil.SetterInferrer
+}
+
+func (r *InferSettersRunner) runE(c *cobra.Command, args []string) error {
+	path, err := ext.GetOpenAPIFile(args)
+	if err != nil {
+		return handleError(c, err)
+	}
+	inferred, err := r.Inferrer.Infer(path, args[0])
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	table := newTable(c.OutOrStdout(), false)
+	table.SetHeader([]string{"NAME", "OLD VALUE", "NEW VALUE"})
+	for _, v := range inferred {
+		table.Append([]string{v.Name, setterValue(v.OldValues), setterValue(v.Values)})
+	}
+	table.Render()
+
+	if r.Inferrer.DryRun {
+		fmt.Fprintf(c.OutOrStdout(), "would update %d setters\n", len(inferred))
+	} else {
+		fmt.Fprintf(c.OutOrStdout(), "updated %d setters\n", len(inferred))
+	}
+	return nil
+}
+
+// setterValue returns the values of a setter as a scalar,
+// or in flow style if it's a list setter.
+func setterValue(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
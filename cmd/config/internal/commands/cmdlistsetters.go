@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/ext"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
 	"sigs.k8s.io/kustomize/kyaml/setters"
 	"sigs.k8s.io/kustomize/kyaml/setters2"
@@ -29,11 +30,17 @@ func NewListSettersRunner(parent string) *ListSettersRunner {
 		Example: commands.ListSettersExamples,
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
+
+		ValidArgsFunction: completeSetterNames,
 	}
 	c.Flags().BoolVar(&r.Markdown, "markdown", false,
 		"output as github markdown")
 	c.Flags().BoolVar(&r.IncludeSubst, "include-subst", false,
 		"include substitutions in the output")
+	c.Flags().BoolVar(&r.History, "history", false,
+		"list the values the NAME setter was set to, if it records its history")
+	c.Flags().StringVar(&r.List.Group, "group", "",
+		"list only the setters in this group")
 	fixDocs(parent, c)
 	r.Command = c
 	return r
@@ -49,9 +56,13 @@ type ListSettersRunner struct {
 	List         setters2.List
 	Markdown     bool
 	IncludeSubst bool
+	History      bool
 }
 
 func (r *ListSettersRunner) preRunE(c *cobra.Command, args []string) error {
+	if r.History && len(args) < 2 {
+		return errors.Errorf("--history needs the NAME of a setter")
+	}
 	if len(args) > 1 {
 		r.Lookup.Name = args[1]
 		r.List.Name = args[1]
@@ -62,6 +73,9 @@ func (r *ListSettersRunner) preRunE(c *cobra.Command, args []string) error {
 }
 
 func (r *ListSettersRunner) runE(c *cobra.Command, args []string) error {
+	if setterVersion == "v2" && r.History {
+		return handleError(c, r.ListHistory(c, args))
+	}
 	if setterVersion == "v2" {
 		if err := r.ListSetters(c, args); err != nil {
 			return err
@@ -95,6 +109,17 @@ func (r *ListSettersRunner) ListSetters(c *cobra.Command, args []string) error {
 			v = strings.Join(s.ListValues, ",")
 			v = fmt.Sprintf("[%s]", v)
 		}
+		description := s.Description
+		if s.Deprecated {
+			deprecated := "deprecated"
+			if s.ReplacedBy != "" {
+				deprecated += ", set " + s.ReplacedBy + " instead"
+			}
+			description = strings.TrimSpace("(" + deprecated + ") " + description)
+		}
+		if s.Locked {
+			description = strings.TrimSpace("(locked) " + description)
+		}
 		var required string
 		if s.Required {
 			required = "Yes"
@@ -102,7 +127,7 @@ func (r *ListSettersRunner) ListSetters(c *cobra.Command, args []string) error {
 			required = "No"
 		}
 		table.Append([]string{
-			s.Name, v, s.SetBy, s.Description, fmt.Sprintf("%d", s.Count), required})
+			s.Name, v, s.SetBy, description, fmt.Sprintf("%d", s.Count), required})
 	}
 	table.Render()
 
@@ -115,6 +140,29 @@ func (r *ListSettersRunner) ListSetters(c *cobra.Command, args []string) error {
 	return nil
 }
 
+// ListHistory lists the values the setter was set to, oldest first.
+func (r *ListSettersRunner) ListHistory(c *cobra.Command, args []string) error {
+	path, err := ext.GetOpenAPIFile(args)
+	if err != nil {
+		return err
+	}
+	history, err := setters2.SetterHistory(path, args[1])
+	if err != nil {
+		return err
+	}
+	table := newTable(c.OutOrStdout(), r.Markdown)
+	table.SetHeader([]string{"TIMESTAMP", "VALUE", "SET BY"})
+	for _, h := range history {
+		v := h.Value
+		if len(h.ListValues) > 0 {
+			v = fmt.Sprintf("[%s]", strings.Join(h.ListValues, ","))
+		}
+		table.Append([]string{h.Timestamp, v, h.SetBy})
+	}
+	table.Render()
+	return nil
+}
+
 func (r *ListSettersRunner) ListSubstitutions(c *cobra.Command, args []string) error {
 	// use setters v2
 	path, err := ext.GetOpenAPIFile(args)
@@ -140,8 +188,13 @@ func (r *ListSettersRunner) ListSubstitutions(c *cobra.Command, args []string) e
 			refs = refs + "," + ref
 		}
 		refs = fmt.Sprintf("[%s]", strings.TrimPrefix(refs, ","))
+		pattern := s.Pattern
+		if s.Regex != "" {
+			// show regex substitutions as their regex
+			pattern = s.Regex
+		}
 		table.Append([]string{
-			s.Name, s.Pattern, refs})
+			s.Name, pattern, refs})
 	}
 	if len(r.List.Substitutions) == 0 {
 		return nil
@@ -6,6 +6,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -14,6 +15,7 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/setters"
+	"sigs.k8s.io/kustomize/kyaml/setters2"
 	"sigs.k8s.io/kustomize/kyaml/setters2/settersutil"
 )
 
@@ -22,12 +24,14 @@ func NewSetRunner(parent string) *SetRunner {
 	r := &SetRunner{}
 	c := &cobra.Command{
 		Use:     "set DIR NAME --values [VALUE]",
-		Args:    cobra.MinimumNArgs(2),
+		Args:    cobra.MinimumNArgs(1),
 		Short:   commands.SetShort,
 		Long:    commands.SetLong,
 		Example: commands.SetExamples,
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
+
+		ValidArgsFunction: completeSetterNames,
 	}
 	fixDocs(parent, c)
 	r.Command = c
@@ -37,9 +41,26 @@ func NewSetRunner(parent string) *SetRunner {
 		"annotate the field with who set it")
 	c.Flags().StringVar(&r.Perform.Description, "description", "",
 		"annotate the field with a description of its value")
+	c.Flags().StringVar(&r.TerraformOutputs, "from-terraform", "",
+		"set setters to the outputs in this file, written by `terraform output -json`, or terraform state")
+	c.Flags().StringVar(&r.Set.FromEnv, "from-env", "",
+		"set the setter to the value of this environment variable, and record it as its source")
+	c.Flags().StringVar(&r.ValuesFile, "from-values", "",
+		"set the setters named in this file to their values in it")
+	c.Flags().StringVar(&r.Group, "group", "",
+		"set the setters of this group, whose names in --from-values are relative to it")
+	c.Flags().BoolVarP(&r.Recursive, "recurse-subpackages", "R", false,
+		"also set the setter in subpackages with their own Krmfile which define a setter of the same name")
+	c.Flags().BoolVar(&r.DryRun, "dry-run", false,
+		"print the fields which would change, with their old and new values, without setting them")
+	c.Flags().BoolVar(&r.Set.Force, "force", false,
+		"set locked setters, whose values otherwise can't be changed")
+	c.Flags().StringArrayVar(&r.TerraformMappings, "terraform-output", []string{},
+		"with --from-terraform, set a setter to an output not of its name, as SETTER=OUTPUT")
 	c.Flags().StringVar(&setterVersion, "version", "",
 		"use this version of the setter format")
 	c.Flags().MarkHidden("version")
+	addVerifyRoundTripFlag(c, &r.VerifyRoundTrip)
 
 	return r
 }
@@ -57,6 +78,14 @@ type SetRunner struct {
 	Set         settersutil.FieldSetter
 	OpenAPIFile string
 	Values      []string
+
+	TerraformOutputs  string
+	TerraformMappings []string
+	ValuesFile        string
+	Group             string
+	DryRun            bool
+	Recursive         bool
+	VerifyRoundTrip   string
 }
 
 func initSetterVersion(c *cobra.Command, args []string) error {
@@ -79,12 +108,54 @@ func initSetterVersion(c *cobra.Command, args []string) error {
 }
 
 func (r *SetRunner) preRunE(c *cobra.Command, args []string) error {
+	if r.DryRun && (r.ValuesFile != "" || r.TerraformOutputs != "") {
+		return errors.Errorf("--dry-run can't be specified with --from-values or --from-terraform")
+	}
+	if r.Recursive && (r.ValuesFile != "" || r.TerraformOutputs != "" || r.DryRun) {
+		return errors.Errorf(
+			"--recurse-subpackages can't be specified with --from-values, --from-terraform or --dry-run")
+	}
+	if r.Group != "" && r.ValuesFile == "" {
+		return errors.Errorf("--group can only be specified with --from-values")
+	}
+	if r.ValuesFile != "" {
+		if len(args) > 1 || c.Flag("values").Changed {
+			return errors.Errorf("NAME and VALUE can't be specified with --from-values")
+		}
+		if r.Set.FromEnv != "" || r.TerraformOutputs != "" {
+			return errors.Errorf(
+				"--from-env and --from-terraform can't be specified with --from-values")
+		}
+		var err error
+		r.OpenAPIFile, err = ext.GetOpenAPIFile(args)
+		return err
+	}
+	if r.TerraformOutputs != "" {
+		if len(args) > 1 {
+			return errors.Errorf("NAME and VALUE can't be specified with --from-terraform")
+		}
+		if r.Set.FromEnv != "" {
+			return errors.Errorf("--from-env can't be specified with --from-terraform")
+		}
+		var err error
+		r.OpenAPIFile, err = ext.GetOpenAPIFile(args)
+		return err
+	}
+	if len(args) < 2 {
+		return errors.Errorf("requires at least 2 arg(s), only received %d", len(args))
+	}
+
 	valueFlagSet := c.Flag("values").Changed
 
 	if valueFlagSet && len(args) > 2 {
 		return errors.Errorf("value should set either from flag or arg")
 	}
 
+	fromEnv := r.Set.FromEnv != ""
+	if fromEnv && (valueFlagSet || len(args) > 2) {
+		return errors.Errorf("VALUE can't be specified with --from-env")
+	}
+
 	if len(args) > 1 {
 		r.Perform.Name = args[1]
 		r.Lookup.Name = args[1]
@@ -97,18 +168,30 @@ func (r *SetRunner) preRunE(c *cobra.Command, args []string) error {
 	}
 
 	if setterVersion == "" {
-		if len(args) < 2 || len(args) < 3 && !valueFlagSet {
+		if len(args) < 2 || len(args) < 3 && !valueFlagSet && !fromEnv {
 			setterVersion = "v1"
 		} else if err := initSetterVersion(c, args); err != nil {
 			return err
 		}
 	}
+	if fromEnv && setterVersion != "v2" {
+		return errors.Errorf("--from-env needs setters created with create-setter")
+	}
+	if r.DryRun && setterVersion != "v2" {
+		return errors.Errorf("--dry-run needs setters created with create-setter")
+	}
+	if r.Recursive && setterVersion != "v2" {
+		return errors.Errorf("--recurse-subpackages needs setters created with create-setter")
+	}
 	if setterVersion == "v2" {
 		var err error
 		r.Set.Name = args[1]
-		if valueFlagSet {
+		switch {
+		case fromEnv:
+			// the value is read by the setter
+		case valueFlagSet:
 			r.Set.Value = r.Values[0]
-		} else {
+		default:
 			r.Set.Value = args[2]
 		}
 
@@ -131,10 +214,33 @@ func (r *SetRunner) preRunE(c *cobra.Command, args []string) error {
 }
 
 func (r *SetRunner) runE(c *cobra.Command, args []string) error {
+	if r.TerraformOutputs != "" {
+		return handleError(c, r.setFromTerraform(c, args))
+	}
+	var err error
+	r.Set.OnFormattingChurn, err = onFormattingChurn(c, r.VerifyRoundTrip)
+	if err != nil {
+		return handleError(c, err)
+	}
+	r.Set.OnDeprecation = func(d setters2.Deprecation) {
+		fmt.Fprintf(c.ErrOrStderr(), "warning: %s\n", d)
+	}
+	if r.ValuesFile != "" {
+		return handleError(c, r.setFromValues(c, args))
+	}
+	if setterVersion == "v2" && r.DryRun {
+		return handleError(c, r.preview(c, args))
+	}
+	if setterVersion == "v2" && r.Recursive {
+		return handleError(c, r.setRecursive(c, args))
+	}
 	if setterVersion == "v2" {
 		count, err := r.Set.Set(r.OpenAPIFile, args[0])
 		fmt.Fprintf(c.OutOrStdout(), "set %d fields\n", count)
-		return handleError(c, err)
+		if err != nil {
+			return handleError(c, err)
+		}
+		return handleError(c, remindRequiredSetters(c, r.OpenAPIFile))
 	}
 	if len(args) > 2 || c.Flag("values").Changed {
 		return handleError(c, r.perform(c, args))
@@ -142,6 +248,102 @@ func (r *SetRunner) runE(c *cobra.Command, args []string) error {
 	return handleError(c, lookup(r.Lookup, c, args))
 }
 
+// remindRequiredSetters prints the required setters of the
+// openAPI file which still need to be set before the package
+// is rendered.
+func remindRequiredSetters(c *cobra.Command, openAPIFile string) error {
+	unset, err := setters2.UnsetRequiredSettersInFile(openAPIFile)
+	if err != nil || len(unset) == 0 {
+		return err
+	}
+	fmt.Fprintf(c.OutOrStdout(), "required setters not set yet: %s\n",
+		strings.Join(unset, ", "))
+	return nil
+}
+
+// setRecursive sets the setter in the package and its subpackages
+// which define it, and prints the number of fields set in each.
+func (r *SetRunner) setRecursive(c *cobra.Command, args []string) error {
+	counts, err := r.Set.SetRecursive(r.OpenAPIFile, args[0])
+	for _, pc := range counts {
+		fmt.Fprintf(c.OutOrStdout(), "set %d fields in %s\n", pc.Count, pc.Path)
+	}
+	if err != nil {
+		return err
+	}
+	return remindRequiredSetters(c, r.OpenAPIFile)
+}
+
+// preview prints the changes setting the setter would make
+// to the fields, without making them.
+func (r *SetRunner) preview(c *cobra.Command, args []string) error {
+	changes, err := r.Set.Preview(r.OpenAPIFile, args[0])
+	if err != nil {
+		return err
+	}
+	table := newTable(c.OutOrStdout(), false)
+	table.SetHeader([]string{"FILE", "RESOURCE", "FIELD", "OLD VALUE", "NEW VALUE"})
+	for _, ch := range changes {
+		table.Append([]string{ch.File, ch.Resource, ch.Field, ch.OldValue, ch.NewValue})
+	}
+	table.Render()
+	fmt.Fprintf(c.OutOrStdout(), "would set %d fields\n", len(changes))
+	return nil
+}
+
+// setFromValues sets the setters to the values of the values file.
+func (r *SetRunner) setFromValues(c *cobra.Command, args []string) error {
+	values, err := settersutil.ReadValues(r.ValuesFile)
+	if err != nil {
+		return err
+	}
+	vs := settersutil.ValuesSetter{
+		Values:            values,
+		Group:             r.Group,
+		SetBy:             r.Perform.SetBy,
+		Force:             r.Set.Force,
+		OnFormattingChurn: r.Set.OnFormattingChurn,
+		OnDeprecation:     r.Set.OnDeprecation,
+	}
+	count, err := vs.Set(r.OpenAPIFile, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.OutOrStdout(), "set %d fields\n", count)
+	return remindRequiredSetters(c, r.OpenAPIFile)
+}
+
+// setFromTerraform sets the setters to the terraform outputs.
+func (r *SetRunner) setFromTerraform(c *cobra.Command, args []string) error {
+	outputs, err := settersutil.ReadTerraformOutputs(r.TerraformOutputs)
+	if err != nil {
+		return err
+	}
+	ts := settersutil.TerraformSetter{
+		Outputs:  outputs,
+		Mappings: map[string]string{},
+		SetBy:    r.Perform.SetBy,
+		Force:    r.Set.Force,
+	}
+	for _, m := range r.TerraformMappings {
+		kv := strings.SplitN(m, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return errors.Errorf("--terraform-output %q must be SETTER=OUTPUT", m)
+		}
+		ts.Mappings[kv[0]] = kv[1]
+	}
+	counts, err := ts.Set(r.OpenAPIFile, args[0])
+	if err != nil {
+		return err
+	}
+	var count int
+	for _, n := range counts {
+		count += n
+	}
+	fmt.Fprintf(c.OutOrStdout(), "set %d fields\n", count)
+	return nil
+}
+
 func lookup(l setters.LookupSetters, c *cobra.Command, args []string) error {
 	// lookup the setters
 	err := kio.Pipeline{
@@ -186,10 +388,65 @@ func lookup(l setters.LookupSetters, c *cobra.Command, args []string) error {
 	return nil
 }
 
+// completeSetterNames completes the NAME argument with the names of the setters
+// defined for the package in the DIR argument.
+func completeSetterNames(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		// complete the DIR argument
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	if len(args) > 1 {
+		// values can't be completed
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, name := range setterNames(args[0]) {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// setterNames returns the names of the setters defined for the package in dir.
+// Errors are ignored, so that completion doesn't fail for partial packages.
+func setterNames(dir string) []string {
+	var names []string
+
+	// setters v2 are defined in the OpenAPI file
+	if path, err := ext.GetOpenAPIFile([]string{dir}); err == nil {
+		l := setters2.List{}
+		if err := l.ListSetters(path, dir); err == nil {
+			for i := range l.Setters {
+				names = append(names, l.Setters[i].Name)
+			}
+		}
+	}
+	if len(names) > 0 {
+		return names
+	}
+
+	// setters v1 are defined on the fields
+	l := setters.LookupSetters{}
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{&kio.LocalPackageReader{PackagePath: dir}},
+		Filters: []kio.Filter{&l},
+	}.Execute()
+	if err != nil {
+		return nil
+	}
+	for i := range l.SetterCounts {
+		names = append(names, l.SetterCounts[i].Name)
+	}
+	return names
+}
+
 // perform the setters
 func (r *SetRunner) perform(c *cobra.Command, args []string) error {
 	rw := &kio.LocalPackageReadWriter{
-		PackagePath: args[0],
+		PackagePath:       args[0],
+		OnFormattingChurn: r.Set.OnFormattingChurn,
 	}
 	// perform the setters in the package
 	err := kio.Pipeline{
@@ -7,9 +7,11 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/cmd/config/ext"
 	"sigs.k8s.io/kustomize/cmd/config/internal/commands"
@@ -650,8 +652,8 @@ apiVersion: example.com/v1beta1
 kind: Example
 spec:
   list: # {"$ref":"#/definitions/io.k8s.cli.setters.list"}
-  - "10"
-  - "11"
+  - 10
+  - 11
  `,
 		},
 
@@ -1082,3 +1084,387 @@ spec:
 		})
 	}
 }
+
+func TestSetCommand_completion(t *testing.T) {
+	// reset the openAPI afterward
+	openapi.ResetOpenAPI()
+	defer openapi.ResetOpenAPI()
+
+	dir, err := ioutil.TempDir("", "k8s-cli-")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "Krmfile"), []byte(`
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: "nginx"
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+    io.k8s.cli.setters.region:
+      x-k8s-cli:
+        setter:
+          name: region
+          value: "us-east1"
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "deploy.yaml"), []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3 # {"$ref": "#/definitions/io.k8s.cli.setters.replicas"}
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	runner := commands.NewSetRunner("")
+	names, directive := runner.Command.ValidArgsFunction(runner.Command, []string{dir}, "re")
+	assert.Equal(t, []string{"region", "replicas"}, names)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	names, directive = runner.Command.ValidArgsFunction(runner.Command, []string{dir, "replicas"}, "")
+	assert.Empty(t, names)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func TestSetCommand_fromTerraform(t *testing.T) {
+	openapi.ResetOpenAPI()
+	defer openapi.ResetOpenAPI()
+
+	f, err := ioutil.TempFile("", "k8s-cli-")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(f.Name())
+	err = ioutil.WriteFile(f.Name(), []byte(`
+apiVersion: v1alpha1
+kind: Example
+openAPI:
+  definitions:
+    io.k8s.cli.setters.vpc-id:
+      x-k8s-cli:
+        setter:
+          name: vpc-id
+          value: "unset"
+    io.k8s.cli.setters.db-host:
+      x-k8s-cli:
+        setter:
+          name: db-host
+          value: "unset"
+ `), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	old := ext.GetOpenAPIFile
+	defer func() { ext.GetOpenAPIFile = old }()
+	ext.GetOpenAPIFile = func(args []string) (s string, err error) {
+		return f.Name(), nil
+	}
+
+	outputs, err := ioutil.TempFile("", "outputs-*.json")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(outputs.Name())
+	err = ioutil.WriteFile(outputs.Name(), []byte(`{
+  "vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"},
+  "database_endpoint": {"sensitive": false, "type": "string", "value": "db.example.com"}
+}`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	r, err := ioutil.TempFile("", "k8s-cli-*.yaml")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(r.Name())
+	err = ioutil.WriteFile(r.Name(), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: infra
+data:
+  vpc: unset # {"$openapi":"vpc-id"}
+  db: unset # {"$openapi":"db-host"}
+ `), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	runner := commands.NewSetRunner("")
+	out := &bytes.Buffer{}
+	runner.Command.SetOut(out)
+	runner.Command.SetArgs([]string{r.Name(),
+		"--from-terraform", outputs.Name(),
+		"--terraform-output", "db-host=database_endpoint"})
+	if !assert.NoError(t, runner.Command.Execute()) {
+		t.FailNow()
+	}
+	assert.Equal(t, "set 2 fields\n", out.String())
+
+	actual, err := ioutil.ReadFile(r.Name())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: infra
+data:
+  vpc: vpc-123 # {"$openapi":"vpc-id"}
+  db: db.example.com # {"$openapi":"db-host"}
+`), strings.TrimSpace(string(actual)))
+}
+
+func TestSetCommand_fromEnv(t *testing.T) {
+	openapi.ResetOpenAPI()
+	defer openapi.ResetOpenAPI()
+
+	f, err := ioutil.TempFile("", "k8s-cli-")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(f.Name())
+	err = ioutil.WriteFile(f.Name(), []byte(`
+apiVersion: v1alpha1
+kind: Example
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.7.9"
+ `), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	old := ext.GetOpenAPIFile
+	defer func() { ext.GetOpenAPIFile = old }()
+	ext.GetOpenAPIFile = func(args []string) (s string, err error) {
+		return f.Name(), nil
+	}
+
+	r, err := ioutil.TempFile("", "k8s-cli-*.yaml")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(r.Name())
+	err = ioutil.WriteFile(r.Name(), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release
+data:
+  tag: 1.7.9 # {"$openapi":"image-tag"}
+ `), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	os.Setenv("KUSTOMIZE_TEST_IMAGE_TAG", "1.8.1")
+	defer os.Unsetenv("KUSTOMIZE_TEST_IMAGE_TAG")
+
+	runner := commands.NewSetRunner("")
+	out := &bytes.Buffer{}
+	runner.Command.SetOut(out)
+	runner.Command.SetArgs([]string{r.Name(), "image-tag",
+		"--from-env", "KUSTOMIZE_TEST_IMAGE_TAG"})
+	if !assert.NoError(t, runner.Command.Execute()) {
+		t.FailNow()
+	}
+	assert.Equal(t, "set 1 fields\n", out.String())
+
+	actual, err := ioutil.ReadFile(r.Name())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release
+data:
+  tag: 1.8.1 # {"$openapi":"image-tag"}
+`), strings.TrimSpace(string(actual)))
+
+	actual, err = ioutil.ReadFile(f.Name())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, string(actual), "fromEnv: KUSTOMIZE_TEST_IMAGE_TAG")
+}
+
+func TestSetCommand_fromValues(t *testing.T) {
+	openapi.ResetOpenAPI()
+	defer openapi.ResetOpenAPI()
+
+	f, err := ioutil.TempFile("", "k8s-cli-")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(f.Name())
+	err = ioutil.WriteFile(f.Name(), []byte(`
+apiVersion: v1alpha1
+kind: Example
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.7.9"
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "1"
+ `), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	old := ext.GetOpenAPIFile
+	defer func() { ext.GetOpenAPIFile = old }()
+	ext.GetOpenAPIFile = func(args []string) (s string, err error) {
+		return f.Name(), nil
+	}
+
+	r, err := ioutil.TempFile("", "k8s-cli-*.yaml")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(r.Name())
+	err = ioutil.WriteFile(r.Name(), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release
+data:
+  tag: 1.7.9 # {"$openapi":"image-tag"}
+  replicas: 1 # {"$openapi":"replicas"}
+ `), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	v, err := ioutil.TempFile("", "values-*.yaml")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(v.Name())
+	err = ioutil.WriteFile(v.Name(), []byte(`
+image-tag: 1.8.1
+replicas: 3
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	runner := commands.NewSetRunner("")
+	out := &bytes.Buffer{}
+	runner.Command.SetOut(out)
+	runner.Command.SetArgs([]string{r.Name(), "--from-values", v.Name()})
+	if !assert.NoError(t, runner.Command.Execute()) {
+		t.FailNow()
+	}
+	assert.Equal(t, "set 2 fields\n", out.String())
+
+	actual, err := ioutil.ReadFile(r.Name())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release
+data:
+  tag: 1.8.1 # {"$openapi":"image-tag"}
+  replicas: 3 # {"$openapi":"replicas"}
+`), strings.TrimSpace(string(actual)))
+}
+
+func TestSetCommand_dryRun(t *testing.T) {
+	openapi.ResetOpenAPI()
+	defer openapi.ResetOpenAPI()
+
+	openAPIFile := `
+apiVersion: v1alpha1
+kind: Example
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.7.9"
+ `
+	f, err := ioutil.TempFile("", "k8s-cli-")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(f.Name())
+	err = ioutil.WriteFile(f.Name(), []byte(openAPIFile), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	old := ext.GetOpenAPIFile
+	defer func() { ext.GetOpenAPIFile = old }()
+	ext.GetOpenAPIFile = func(args []string) (s string, err error) {
+		return f.Name(), nil
+	}
+
+	resourceFile := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release
+data:
+  tag: 1.7.9 # {"$openapi":"image-tag"}
+ `
+	r, err := ioutil.TempFile("", "k8s-cli-*.yaml")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(r.Name())
+	err = ioutil.WriteFile(r.Name(), []byte(resourceFile), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	runner := commands.NewSetRunner("")
+	out := &bytes.Buffer{}
+	runner.Command.SetOut(out)
+	runner.Command.SetArgs([]string{r.Name(), "image-tag", "1.8.1", "--dry-run"})
+	if !assert.NoError(t, runner.Command.Execute()) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), "ConfigMap/release")
+	assert.Contains(t, out.String(), "data.tag")
+	assert.Contains(t, out.String(), "would set 1 fields\n")
+
+	// nothing is written
+	for path, expected := range map[string]string{
+		f.Name(): openAPIFile, r.Name(): resourceFile} {
+		actual, err := ioutil.ReadFile(path)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, expected, string(actual))
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/cmd/config/ext"
+	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/kyaml/setters2/settersutil"
+)
+
+// NewSuggestSettersRunner returns a command runner.
+func NewSuggestSettersRunner(parent string) *SuggestSettersRunner {
+	r := &SuggestSettersRunner{}
+	c := &cobra.Command{
+		Use:     "suggest-setters DIR",
+		Args:    cobra.ExactArgs(1),
+		Short:   commands.SuggestSettersShort,
+		Long:    commands.SuggestSettersLong,
+		Example: commands.SuggestSettersExamples,
+		RunE:    r.runE,
+	}
+	c.Flags().BoolVar(&r.Create, "create", false,
+		"create the suggested setters, and reference them from their fields")
+	c.Flags().BoolVar(&r.Markdown, "markdown", false,
+		"output as github markdown")
+	fixDocs(parent, c)
+	r.Command = c
+	return r
+}
+
+func SuggestSettersCommand(parent string) *cobra.Command {
+	return NewSuggestSettersRunner(parent).Command
+}
+
+type SuggestSettersRunner struct {
+	Command  *cobra.Command
+	Create   bool
+	Markdown bool
+}
+
+func (r *SuggestSettersRunner) runE(c *cobra.Command, args []string) error {
+	path, err := ext.GetOpenAPIFile(args)
+	if err != nil {
+		return handleError(c, err)
+	}
+	suggestions, err := settersutil.SuggestSetters(path, args[0], r.Create)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	table := newTable(c.OutOrStdout(), r.Markdown)
+	table.SetHeader([]string{"NAME", "VALUE", "SUBSTITUTION", "FIELDS"})
+	for _, s := range suggestions {
+		var subst string
+		if s.Substitution != nil {
+			subst = s.Substitution.Name
+		}
+		for i, f := range s.Fields {
+			if i == 0 {
+				table.Append([]string{s.Name, s.Value, subst, f})
+			} else {
+				table.Append([]string{"", "", "", f})
+			}
+		}
+	}
+	table.Render()
+
+	if r.Create {
+		fmt.Fprintf(c.OutOrStdout(), "created %d setters\n", len(suggestions))
+	}
+	return nil
+}
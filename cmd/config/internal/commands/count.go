@@ -4,11 +4,14 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/sets"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -21,6 +24,7 @@ func GetCountRunner(name string) *CountRunner {
 		Short:   commands.CountShort,
 		Long:    commands.CountLong,
 		Example: commands.CountExamples,
+		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
 	fixDocs(name, c)
@@ -28,6 +32,10 @@ func GetCountRunner(name string) *CountRunner {
 		"also print resources from subpackages.")
 	c.Flags().BoolVar(&r.Kind, "kind", true,
 		"count resources by kind.")
+	c.Flags().StringVar(&r.GroupBy, "group-by", "",
+		"count resources by one of: kind, namespace, apiVersion, label=KEY. overrides --kind.")
+	c.Flags().StringVarP(&r.Output, "output", "o", outputText,
+		"output format. one of: text, json.")
 
 	r.Command = c
 	return r
@@ -41,7 +49,58 @@ func CountCommand(name string) *cobra.Command {
 type CountRunner struct {
 	IncludeSubpackages bool
 	Kind               bool
+	GroupBy            string
+	Output             string
 	Command            *cobra.Command
+
+	// groupKey returns the group a resource is counted in
+	groupKey func(meta yaml.ResourceMeta) string
+}
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	// groupNone is the group for resources which don't have the grouped field
+	groupNone = "<none>"
+)
+
+// countResult is the json output of count
+type countResult struct {
+	GroupBy string         `json:"groupBy,omitempty"`
+	Total   int            `json:"total"`
+	Counts  map[string]int `json:"counts,omitempty"`
+}
+
+func (r *CountRunner) preRunE(c *cobra.Command, args []string) error {
+	if r.Output != outputText && r.Output != outputJSON {
+		return errors.Errorf("unsupported output %q, must be one of: %s, %s",
+			r.Output, outputText, outputJSON)
+	}
+
+	if r.GroupBy == "" && r.Kind {
+		// backwards compatibility with --kind
+		r.GroupBy = "kind"
+	}
+	switch {
+	case r.GroupBy == "":
+	case r.GroupBy == "kind":
+		r.groupKey = func(meta yaml.ResourceMeta) string { return meta.Kind }
+	case r.GroupBy == "namespace":
+		r.groupKey = func(meta yaml.ResourceMeta) string { return meta.Namespace }
+	case r.GroupBy == "apiVersion":
+		r.groupKey = func(meta yaml.ResourceMeta) string { return meta.APIVersion }
+	case strings.HasPrefix(r.GroupBy, "label="):
+		key := strings.TrimPrefix(r.GroupBy, "label=")
+		if key == "" {
+			return errors.Errorf("--group-by label= requires a label key")
+		}
+		r.groupKey = func(meta yaml.ResourceMeta) string { return meta.Labels[key] }
+	default:
+		return errors.Errorf("unsupported --group-by %q, must be one of: "+
+			"kind, namespace, apiVersion, label=KEY", r.GroupBy)
+	}
+	return nil
 }
 
 func (r *CountRunner) runE(c *cobra.Command, args []string) error {
@@ -56,33 +115,48 @@ func (r *CountRunner) runE(c *cobra.Command, args []string) error {
 		inputs = append(inputs, &kio.ByteReader{Reader: c.InOrStdin()})
 	}
 
-	var out []kio.Writer
-	if r.Kind {
-		out = append(out, kio.WriterFunc(func(nodes []*yaml.RNode) error {
-			count := map[string]int{}
-			k := sets.String{}
-			for _, n := range nodes {
-				m, _ := n.GetMeta()
-				count[m.Kind]++
-				k.Insert(m.Kind)
-			}
-			order := k.List()
-			sort.Strings(order)
-			for _, k := range order {
-				fmt.Fprintf(c.OutOrStdout(), "%s: %d\n", k, count[k])
+	return handleError(c, kio.Pipeline{
+		Inputs:  inputs,
+		Outputs: []kio.Writer{kio.WriterFunc(r.count)},
+	}.Execute())
+}
+
+// count writes the number of resources in nodes, grouped if configured
+func (r *CountRunner) count(nodes []*yaml.RNode) error {
+	result := countResult{GroupBy: r.GroupBy, Total: len(nodes)}
+	if r.groupKey != nil {
+		result.Counts = map[string]int{}
+		for _, n := range nodes {
+			m, _ := n.GetMeta()
+			k := r.groupKey(m)
+			if k == "" {
+				k = groupNone
 			}
+			result.Counts[k]++
+		}
+	}
 
-			return nil
-		}))
+	if r.Output == outputJSON {
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		fmt.Fprintf(r.Command.OutOrStdout(), "%s\n", b)
+		return nil
+	}
 
-	} else {
-		out = append(out, kio.WriterFunc(func(nodes []*yaml.RNode) error {
-			fmt.Fprintf(c.OutOrStdout(), "%d\n", len(nodes))
-			return nil
-		}))
+	if r.groupKey == nil {
+		fmt.Fprintf(r.Command.OutOrStdout(), "%d\n", result.Total)
+		return nil
 	}
-	return handleError(c, kio.Pipeline{
-		Inputs:  inputs,
-		Outputs: out,
-	}.Execute())
+	k := sets.String{}
+	for g := range result.Counts {
+		k.Insert(g)
+	}
+	order := k.List()
+	sort.Strings(order)
+	for _, g := range order {
+		fmt.Fprintf(r.Command.OutOrStdout(), "%s: %d\n", g, result.Counts[g])
+	}
+	return nil
 }
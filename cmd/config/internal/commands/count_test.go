@@ -71,3 +71,102 @@ spec:
 		return
 	}
 }
+
+func TestCountCommand_groupBy(t *testing.T) {
+	d, err := ioutil.TempDir("", "kustomize-count-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(d)
+
+	err = ioutil.WriteFile(filepath.Join(d, "f1.yaml"), []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: nginx
+  name: foo
+  namespace: default
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: nginx
+  name: foo
+  namespace: default
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+`), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var tests = []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "namespace",
+			args:     []string{"--group-by", "namespace"},
+			expected: "<none>: 1\ndefault: 2\n",
+		},
+		{
+			name:     "apiVersion",
+			args:     []string{"--group-by", "apiVersion"},
+			expected: "apps/v1: 1\nv1: 2\n",
+		},
+		{
+			name:     "label",
+			args:     []string{"--group-by", "label=app"},
+			expected: "<none>: 1\nnginx: 2\n",
+		},
+		{
+			name:     "total",
+			args:     []string{"--kind=false"},
+			expected: "3\n",
+		},
+		{
+			name: "json",
+			args: []string{"--group-by", "kind", "-o", "json"},
+			expected: `{
+  "groupBy": "kind",
+  "total": 3,
+  "counts": {
+    "Deployment": 1,
+    "Namespace": 1,
+    "Service": 1
+  }
+}
+`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			r := commands.GetCountRunner("")
+			r.Command.SetArgs(append([]string{d}, test.args...))
+			r.Command.SetOut(b)
+			if !assert.NoError(t, r.Command.Execute()) {
+				return
+			}
+			assert.Equal(t, test.expected, b.String())
+		})
+	}
+}
+
+func TestCountCommand_groupByInvalid(t *testing.T) {
+	r := commands.GetCountRunner("")
+	r.Command.SetArgs([]string{"--group-by", "name"})
+	r.Command.SetOut(&bytes.Buffer{})
+	r.Command.SetErr(&bytes.Buffer{})
+	err := r.Command.Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unsupported --group-by "name"`)
+	}
+}
@@ -33,6 +33,7 @@ formatting substitution verbs {'%n': 'metadata.name', '%s': 'metadata.namespace'
 		`if true, override existing filepath annotations.`)
 	c.Flags().BoolVar(&r.UseSchema, "use-schema", false,
 		`if true, uses openapi resource schema to format resources.`)
+	addVerifyRoundTripFlag(c, &r.VerifyRoundTrip)
 	r.Command = c
 	return r
 }
@@ -49,6 +50,7 @@ type FmtRunner struct {
 	KeepAnnotations bool
 	Override        bool
 	UseSchema       bool
+	VerifyRoundTrip string
 }
 
 func (r *FmtRunner) preRunE(c *cobra.Command, args []string) error {
@@ -82,12 +84,17 @@ func (r *FmtRunner) runE(c *cobra.Command, args []string) error {
 			Inputs: []kio.Reader{rw}, Filters: f, Outputs: []kio.Writer{rw}}.Execute())
 	}
 
+	churn, err := onFormattingChurn(c, r.VerifyRoundTrip)
+	if err != nil {
+		return handleError(c, err)
+	}
 	for i := range args {
 		path := args[i]
 		rw := &kio.LocalPackageReadWriter{
 			NoDeleteFiles:         true,
 			PackagePath:           path,
-			KeepReaderAnnotations: r.KeepAnnotations}
+			KeepReaderAnnotations: r.KeepAnnotations,
+			OnFormattingChurn:     churn}
 		err := kio.Pipeline{
 			Inputs: []kio.Reader{rw}, Filters: f, Outputs: []kio.Writer{rw}}.Execute()
 		if err != nil {
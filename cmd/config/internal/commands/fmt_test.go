@@ -64,6 +64,34 @@ func TestFmtCommand_files(t *testing.T) {
 	}
 }
 
+// TestFmtCommand_verifyRoundTrip verifies the fmt command fails without
+// writing files it would only reformat with --verify-round-trip=fail
+func TestFmtCommand_verifyRoundTrip(t *testing.T) {
+	f, err := ioutil.TempFile("", "cmdfmt*.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(f.Name())
+	err = ioutil.WriteFile(f.Name(), testyaml.UnformattedYaml1, 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r := commands.GetFmtRunner("")
+	r.Command.SetArgs([]string{f.Name(), "--verify-round-trip", "fail"})
+	err = r.Command.Execute()
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "1 files would only be reformatted")
+
+	b, err := ioutil.ReadFile(f.Name())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(testyaml.UnformattedYaml1), string(b))
+}
+
 func TestFmtCommand_stdin(t *testing.T) {
 	out := &bytes.Buffer{}
 	r := commands.GetFmtRunner("")
@@ -49,6 +49,9 @@ type GrepRunner struct {
 	Command            *cobra.Command
 	filters.GrepFilter
 	Format bool
+
+	// query matches the conditions of a QUERY combining several
+	query kio.Filter
 }
 
 func (r *GrepRunner) preRunE(c *cobra.Command, args []string) error {
@@ -64,44 +67,77 @@ func (r *GrepRunner) preRunE(c *cobra.Command, args []string) error {
 
 		return qa.Cmp(qb), err
 	}
-	parts, err := parseFieldPath(args[0])
+
+	// conditions are combined with '&&', which binds tighter than '||'
+	var anyFltrs []kio.Filter
+	var conditions []filters.GrepFilter
+	for _, q := range strings.Split(args[0], "||") {
+		var allFltrs []kio.Filter
+		for _, condition := range strings.Split(q, "&&") {
+			f, err := r.parseCondition(strings.TrimSpace(condition))
+			if err != nil {
+				return err
+			}
+			allFltrs = append(allFltrs, f)
+			conditions = append(conditions, f)
+		}
+		anyFltrs = append(anyFltrs, filters.GrepAllFilter{Filters: allFltrs})
+	}
+	invert := r.InvertMatch
+	if len(conditions) == 1 {
+		r.GrepFilter = conditions[0]
+		r.GrepFilter.InvertMatch = invert
+		return nil
+	}
+	r.query = filters.GrepAnyFilter{Filters: anyFltrs, InvertMatch: invert}
+	return nil
+}
+
+// parseCondition parses a condition of a QUERY, e.g. spec.replicas>3,
+// into a GrepFilter.
+func (r *GrepRunner) parseCondition(condition string) (filters.GrepFilter, error) {
+	f := filters.GrepFilter{Compare: r.Compare}
+	parts, err := parseFieldPath(condition)
 	if err != nil {
-		return err
+		return f, err
 	}
 
 	var last []string
 	if strings.Contains(parts[len(parts)-1], ">=") {
 		last = strings.Split(parts[len(parts)-1], ">=")
-		r.MatchType = filters.GreaterThanEq
+		f.MatchType = filters.GreaterThanEq
 	} else if strings.Contains(parts[len(parts)-1], "<=") {
 		last = strings.Split(parts[len(parts)-1], "<=")
-		r.MatchType = filters.LessThanEq
+		f.MatchType = filters.LessThanEq
 	} else if strings.Contains(parts[len(parts)-1], ">") {
 		last = strings.Split(parts[len(parts)-1], ">")
-		r.MatchType = filters.GreaterThan
+		f.MatchType = filters.GreaterThan
 	} else if strings.Contains(parts[len(parts)-1], "<") {
 		last = strings.Split(parts[len(parts)-1], "<")
-		r.MatchType = filters.LessThan
+		f.MatchType = filters.LessThan
 	} else {
 		last = strings.Split(parts[len(parts)-1], "=")
-		r.MatchType = filters.Regexp
+		f.MatchType = filters.Regexp
 	}
 	if len(last) > 2 {
-		return fmt.Errorf(
+		return f, fmt.Errorf(
 			"ambiguous match -- multiple of ['<', '>', '<=', '>=', '=' in final path element: %s",
 			parts[len(parts)-1])
 	}
 
 	if len(last) > 1 {
-		r.Value = last[1]
+		f.Value = last[1]
 	}
 
-	r.Path = append(parts[:len(parts)-1], last[0])
-	return nil
+	f.Path = append(parts[:len(parts)-1], last[0])
+	return f, nil
 }
 
 func (r *GrepRunner) runE(c *cobra.Command, args []string) error {
 	var filters = []kio.Filter{r.GrepFilter}
+	if r.query != nil {
+		filters = []kio.Filter{r.query}
+	}
 
 	var inputs []kio.Reader
 	for _, a := range args[1:] {
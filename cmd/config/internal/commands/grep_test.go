@@ -161,6 +161,50 @@ spec:
 	}
 }
 
+// TestGrepCmd_conditions verifies the grep command matches queries
+// combining conditions with '&&' and '||'
+func TestGrepCmd_conditions(t *testing.T) {
+	b := &bytes.Buffer{}
+	r := commands.GetGrepRunner("")
+	r.Command.SetArgs([]string{"kind=Deployment && spec.replicas>2 || kind=^Service$"})
+	r.Command.SetOut(b)
+	r.Command.SetIn(bytes.NewBufferString(`
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  replicas: 1
+---
+kind: Service
+metadata:
+  name: foo
+---
+kind: Deployment
+metadata:
+  name: bar
+spec:
+  replicas: 3
+`))
+	if !assert.NoError(t, r.Command.Execute()) {
+		return
+	}
+
+	assert.Equal(t, `kind: Service
+metadata:
+  name: foo
+  annotations:
+    config.kubernetes.io/index: '1'
+---
+kind: Deployment
+metadata:
+  name: bar
+  annotations:
+    config.kubernetes.io/index: '2'
+spec:
+  replicas: 3
+`, b.String())
+}
+
 // TestGrepCmd_errInputs verifies the grep command errors on invalid matches
 func TestGrepCmd_errInputs(t *testing.T) {
 	b := &bytes.Buffer{}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/importer"
+)
+
+// GetImportRunner returns a command ImportRunner.
+func GetImportRunner(name string) *ImportRunner {
+	r := &ImportRunner{}
+	c := &cobra.Command{
+		Use:     "import DIR",
+		Args:    cobra.ExactArgs(1),
+		Short:   commands.ImportShort,
+		Long:    commands.ImportLong,
+		Example: commands.ImportExamples,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+	fixDocs(name, c)
+	c.Flags().StringVar(&r.Reader.Jsonnet, "jsonnet", "",
+		"path to the jsonnet program to render.")
+	c.Flags().StringArrayVarP(&r.Reader.JPaths, "jpath", "J", nil,
+		"library directory of the jsonnet program.")
+	c.Flags().StringArrayVar(&r.Reader.ExtStrs, "ext-str", nil,
+		"external variable of the jsonnet program, as KEY=VALUE.")
+	c.Flags().StringArrayVar(&r.Reader.TLAStrs, "tla-str", nil,
+		"top level argument of the jsonnet program, as KEY=VALUE.")
+	c.Flags().StringVar(&r.Reader.Cdk8s, "cdk8s", "",
+		"path to the directory of the cdk8s app to synthesize.")
+	r.Command = c
+	return r
+}
+
+func ImportCommand(name string) *cobra.Command {
+	return GetImportRunner(name).Command
+}
+
+// ImportRunner contains the run function
+type ImportRunner struct {
+	Command *cobra.Command
+	Reader  importer.Reader
+}
+
+func (r *ImportRunner) preRunE(c *cobra.Command, args []string) error {
+	if (r.Reader.Jsonnet == "") == (r.Reader.Cdk8s == "") {
+		return errors.Errorf("exactly one of --jsonnet and --cdk8s must be specified")
+	}
+	return nil
+}
+
+func (r *ImportRunner) runE(c *cobra.Command, args []string) error {
+	return handleError(c, importer.Import(args[0], r.Reader))
+}
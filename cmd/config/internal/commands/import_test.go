@@ -0,0 +1,99 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/cmd/config/internal/commands"
+)
+
+func TestImportCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake jsonnet program is a shell script")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-import-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(bin)
+	err = ioutil.WriteFile(filepath.Join(bin, "jsonnet"), []byte(`#!/bin/sh
+[ "$*" = "--jpath lib --ext-str env=prod app.jsonnet" ] || exit 1
+echo '{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "app"}, "spec": {"replicas": 3}},
+  {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "app"}}
+]}'
+`), 0700)
+	if !assert.NoError(t, err) {
+		return
+	}
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+
+	d, err := ioutil.TempDir("", "kustomize-import-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(d)
+	err = ioutil.WriteFile(filepath.Join(d, "deploy.yaml"), []byte(`# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  paused: true
+`), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r := commands.GetImportRunner("")
+	r.Command.SetArgs([]string{d, "--jsonnet", "app.jsonnet", "-J", "lib", "--ext-str", "env=prod"})
+	if !assert.NoError(t, r.Command.Execute()) {
+		t.FailNow()
+	}
+
+	actual, err := ioutil.ReadFile(filepath.Join(d, "deploy.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(`
+# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  paused: true
+`), strings.TrimSpace(string(actual)))
+
+	actual, err = ioutil.ReadFile(filepath.Join(d, "service_app.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`), strings.TrimSpace(string(actual)))
+}
+
+func TestImportCommand_noSource(t *testing.T) {
+	r := commands.GetImportRunner("")
+	r.Command.SetArgs([]string{"my-dir"})
+	r.Command.SilenceUsage = true
+	r.Command.SilenceErrors = true
+	err := r.Command.Execute()
+	assert.EqualError(t, err, "exactly one of --jsonnet and --cdk8s must be specified")
+}
@@ -0,0 +1,40 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// NewLabelRunner returns a command runner.
+func NewLabelRunner(parent string) *LabelRunner {
+	r := &LabelRunner{}
+	r.field = yaml.LabelsField
+	r.set = func(key, value string) yaml.Filter { return yaml.SetLabel(key, value) }
+	r.clear = func(key string) yaml.Filter { return yaml.ClearLabel(key) }
+	c := &cobra.Command{
+		Use:     "label [DIR]",
+		Args:    cobra.MaximumNArgs(1),
+		Short:   commands.LabelShort,
+		Long:    commands.LabelLong,
+		Example: commands.LabelExamples,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+	fixDocs(parent, c)
+	r.Command = c
+	r.addFlags(c, "label")
+	return r
+}
+
+func LabelCommand(parent string) *cobra.Command {
+	return NewLabelRunner(parent).Command
+}
+
+// LabelRunner adds and removes labels on Resources.
+type LabelRunner struct {
+	metadataRunner
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelCommand(t *testing.T) {
+	var tests = []struct {
+		name     string
+		args     []string
+		input    string
+		expected string
+		err      string
+	}{
+		{
+			name: "set",
+			args: []string{"--kv", "team=payments"},
+			input: `kind: Deployment
+metadata:
+  name: foo
+  labels:
+    app: nginx
+`,
+			expected: `kind: Deployment
+metadata:
+  name: foo
+  labels:
+    app: nginx
+    team: 'payments'
+`,
+		},
+		{
+			name: "selector",
+			args: []string{"--kv", "team=payments", "-l", "app!=nginx"},
+			input: `kind: Deployment
+metadata:
+  name: foo
+  labels:
+    app: nginx
+---
+kind: Service
+metadata:
+  name: bar
+`,
+			expected: `kind: Deployment
+metadata:
+  name: foo
+  labels:
+    app: nginx
+---
+kind: Service
+metadata:
+  name: bar
+  labels:
+    team: 'payments'
+`,
+		},
+		{
+			name: "remove",
+			args: []string{"--remove", "app"},
+			input: `kind: Deployment
+metadata:
+  name: foo
+  labels:
+    app: nginx
+`,
+			expected: `kind: Deployment
+metadata:
+  name: foo
+`,
+		},
+		{
+			name: "invalid selector",
+			args: []string{"--kv", "a=b", "-l", "app in (foo"},
+			err:  "invalid --selector",
+		},
+		{
+			name: "missing values",
+			args: []string{"--kind", "Deployment"},
+			err:  "must specify at least one of --kv or --remove",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewLabelRunner("")
+			r.Command.SetArgs(tt.args)
+			r.Command.SetIn(bytes.NewBufferString(tt.input))
+			out := &bytes.Buffer{}
+			r.Command.SetOut(out)
+			r.Command.SilenceUsage = true
+			r.Command.SilenceErrors = true
+
+			err := r.Command.Execute()
+			if tt.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.err)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t,
+				strings.TrimSpace(tt.expected),
+				strings.TrimSpace(out.String()))
+		})
+	}
+}
@@ -4,29 +4,59 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge3"
 )
 
+// conflictStrategies are the values of --on-conflict.
+var conflictStrategies = map[string]merge3.ConflictStrategy{
+	"update": merge3.TakeUpdate,
+	"dest":   merge3.TakeDest,
+	"fail":   merge3.FailOnConflict,
+}
+
 func GetMerge3Runner(name string) *Merge3Runner {
 	r := &Merge3Runner{}
 	c := &cobra.Command{
-		Use:     "merge3 --ancestor [ORIGINAL_DIR] --from [UPDATED_DIR] --to [DESTINATION_DIR]",
+		Use:     "merge3 --original [ORIGINAL_DIR] --updated [UPDATED_DIR] --dest [DEST_DIR]",
 		Short:   commands.Merge3Short,
 		Long:    commands.Merge3Long,
 		Example: commands.Merge3Examples,
 		RunE:    r.runE,
 	}
 	fixDocs(name, c)
+	c.Flags().StringVar(&r.ancestor, "original", "",
+		"Path to original package")
+	c.Flags().StringVar(&r.fromDir, "updated", "",
+		"Path to updated package")
+	c.Flags().StringVar(&r.toDir, "dest", "",
+		"Path to destination package")
+	c.Flags().BoolVar(&r.path, "path-merge-key", false,
+		"Use the path as part of the merge key when merging resources")
+	c.Flags().StringSliceVar(&r.schemas, "schema", []string{},
+		"Path to an OpenAPI schema file used to determine list merge keys. May be repeated.")
+	c.Flags().BoolVar(&r.infer, "infer-list-merge", false,
+		"Infer merge keys for lists without a schema from the fields of their elements")
+	c.Flags().StringVar(&r.onConflict, "on-conflict", "update",
+		"How to resolve fields changed in both the updated and the destination package: "+
+			"update, dest or fail")
+
+	// retained for compatibility with the original flag names
 	c.Flags().StringVar(&r.ancestor, "ancestor", "",
 		"Path to original package")
 	c.Flags().StringVar(&r.fromDir, "from", "",
 		"Path to updated package")
 	c.Flags().StringVar(&r.toDir, "to", "",
 		"Path to destination package")
-	c.Flags().BoolVar(&r.path, "path-merge-key", false,
-		"Use the path as part of the merge key when merging resources")
+	_ = c.Flags().MarkDeprecated("ancestor", "use --original instead")
+	_ = c.Flags().MarkDeprecated("from", "use --updated instead")
+	_ = c.Flags().MarkDeprecated("to", "use --dest instead")
 
 	r.Command = c
 	return r
@@ -38,22 +68,44 @@ func Merge3Command(name string) *cobra.Command {
 
 // Merge3Runner contains the run function
 type Merge3Runner struct {
-	Command  *cobra.Command
-	ancestor string
-	fromDir  string
-	toDir    string
-	path     bool
+	Command    *cobra.Command
+	ancestor   string
+	fromDir    string
+	toDir      string
+	path       bool
+	schemas    []string
+	infer      bool
+	onConflict string
 }
 
 func (r *Merge3Runner) runE(c *cobra.Command, args []string) error {
+	strategy, ok := conflictStrategies[r.onConflict]
+	if !ok {
+		return handleError(c, fmt.Errorf(
+			"--on-conflict must be update, dest or fail, not %q", r.onConflict))
+	}
+
+	// register the additional schemas so they are used to lookup the
+	// merge strategy of lists
+	for i := range r.schemas {
+		if err := openapi.AddSchemaFromFileUsingField(r.schemas[i], ""); err != nil {
+			return handleError(c, err)
+		}
+	}
+
 	err := filters.Merge3{
-		OriginalPath: r.ancestor,
-		UpdatedPath:  r.fromDir,
-		DestPath:     r.toDir,
-		MergeOnPath:  r.path,
+		OriginalPath:          r.ancestor,
+		UpdatedPath:           r.fromDir,
+		DestPath:              r.toDir,
+		MergeOnPath:           r.path,
+		InferAssociativeLists: r.infer,
+		ConflictStrategy:      strategy,
+		OnConflict: func(meta yaml.ResourceMeta, conflict merge3.Conflict) {
+			if strategy != merge3.FailOnConflict {
+				fmt.Fprintf(c.ErrOrStderr(), "conflict in %s %s: %s; took the %s value\n",
+					meta.Kind, meta.Name, conflict, r.onConflict)
+			}
+		},
 	}.Merge()
-	if err != nil {
-		return err
-	}
-	return nil
+	return handleError(c, err)
 }
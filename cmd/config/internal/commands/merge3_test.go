@@ -234,3 +234,156 @@ spec:
 		t.FailNow()
 	}
 }
+
+// TestMerge3Command_inferListMerge verifies merge3 merges the elements of lists
+// without a schema when --infer-list-merge is set
+func TestMerge3Command_inferListMerge(t *testing.T) {
+	original, err := ioutil.TempDir("", "test-data-original")
+	defer os.RemoveAll(original)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = ioutil.WriteFile(filepath.Join(original, "foo.yaml"), []byte(`apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  items:
+  - name: a
+    value: one
+  - name: b
+    value: two
+`), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	updated, err := ioutil.TempDir("", "test-data-updated")
+	defer os.RemoveAll(updated)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = ioutil.WriteFile(filepath.Join(updated, "foo.yaml"), []byte(`apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  items:
+  - name: a
+    value: three
+  - name: b
+    value: two
+`), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dest, err := ioutil.TempDir("", "test-data-dest")
+	defer os.RemoveAll(dest)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = ioutil.WriteFile(filepath.Join(dest, "foo.yaml"), []byte(`apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  items:
+  - name: a
+    value: one
+  - name: b
+    value: four
+  - name: c
+    value: five
+`), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r := commands.GetMerge3Runner("")
+	r.Command.SetArgs([]string{
+		"--original", original,
+		"--updated", updated,
+		"--dest", dest,
+		"--infer-list-merge",
+	})
+	if !assert.NoError(t, r.Command.Execute()) {
+		return
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dest, "foo.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  items:
+  - name: a
+    value: three
+  - name: b
+    value: four
+  - name: c
+    value: five
+`, string(b))
+}
+
+func TestMerge3Command_onConflict(t *testing.T) {
+	dirs := map[string]string{}
+	for name, replicas := range map[string]string{
+		"original": "1", "updated": "2", "dest": "3"} {
+		dir, err := ioutil.TempDir("", "test-data-"+name)
+		defer os.RemoveAll(dir)
+		if !assert.NoError(t, err) {
+			return
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, "foo.yaml"), []byte(`apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  replicas: `+replicas+`
+`), 0600)
+		if !assert.NoError(t, err) {
+			return
+		}
+		dirs[name] = dir
+	}
+
+	r := commands.GetMerge3Runner("")
+	r.Command.SetArgs([]string{
+		"--original", dirs["original"],
+		"--updated", dirs["updated"],
+		"--dest", dirs["dest"],
+		"--on-conflict", "fail",
+	})
+	err := r.Command.Execute()
+	if !assert.EqualError(t, err, `1 conflicts:
+  Foo foo: spec.replicas: original "1", dest "3", updated "2"`) {
+		return
+	}
+
+	r = commands.GetMerge3Runner("")
+	r.Command.SetArgs([]string{
+		"--original", dirs["original"],
+		"--updated", dirs["updated"],
+		"--dest", dirs["dest"],
+		"--on-conflict", "dest",
+	})
+	if !assert.NoError(t, r.Command.Execute()) {
+		return
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dirs["dest"], "foo.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  replicas: 3
+`, string(b))
+}
@@ -0,0 +1,257 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// kptfileName is the name of the file identifying a subpackage.
+const kptfileName = "Kptfile"
+
+// metadataRunner adds and removes metadata entries on the selected Resources
+// of a package.  It implements the annotate and label commands.
+type metadataRunner struct {
+	Command            *cobra.Command
+	Values             []string
+	Remove             []string
+	Kind               string
+	Name               string
+	ApiVersion         string
+	Namespace          string
+	Selector           string
+	IncludeSubpackages bool
+	DryRun             bool
+	Diff               bool
+	VerifyRoundTrip    string
+
+	// field is the metadata field which is modified, used for messages.
+	field string
+	// set and clear return the filters to set or remove a single entry.
+	set   func(key, value string) yaml.Filter
+	clear func(key string) yaml.Filter
+
+	selector labels.Selector
+	original map[string]string
+	churn    func([]string) error
+}
+
+func (r *metadataRunner) addFlags(c *cobra.Command, verb string) {
+	c.Flags().StringVar(&r.Kind, "kind", "", "Resource kind to "+verb)
+	c.Flags().StringVar(&r.ApiVersion, "apiVersion", "", "Resource apiVersion to "+verb)
+	c.Flags().StringVar(&r.Name, "name", "", "Resource name to "+verb)
+	c.Flags().StringVar(&r.Namespace, "namespace", "", "Resource namespace to "+verb)
+	c.Flags().StringVarP(&r.Selector, "selector", "l", "",
+		"label selector of the Resources to "+verb+", e.g. 'app=nginx,tier!=db'")
+	c.Flags().StringSliceVar(&r.Values, "kv", []string{},
+		fmt.Sprintf("%s as KEY=VALUE", strings.TrimSuffix(r.field, "s")))
+	c.Flags().StringSliceVar(&r.Remove, "remove", []string{},
+		fmt.Sprintf("%s KEY to remove", strings.TrimSuffix(r.field, "s")))
+	c.Flags().BoolVar(&r.IncludeSubpackages, "include-subpackages", true,
+		"also "+verb+" Resources in subpackages.")
+	c.Flags().BoolVar(&r.DryRun, "dry-run", false,
+		"print the modified Resources to stdout rather than writing them.")
+	c.Flags().BoolVar(&r.Diff, "diff", false,
+		"print a diff of the changes to stdout.")
+	addVerifyRoundTripFlag(c, &r.VerifyRoundTrip)
+}
+
+func (r *metadataRunner) preRunE(c *cobra.Command, args []string) error {
+	if len(r.Values) == 0 && len(r.Remove) == 0 {
+		return errors.Errorf("must specify at least one of --kv or --remove")
+	}
+	for i := range r.Values {
+		if len(strings.SplitN(r.Values[i], "=", 2)) != 2 {
+			return errors.Errorf("must specify --kv as KEY=VALUE: %s", r.Values[i])
+		}
+	}
+	var err error
+	r.selector, err = labels.Parse(r.Selector)
+	if err != nil {
+		return errors.WrapPrefixf(err, "invalid --selector")
+	}
+	r.churn, err = onFormattingChurn(c, r.VerifyRoundTrip)
+	return err
+}
+
+func (r *metadataRunner) runE(c *cobra.Command, args []string) error {
+	var rw kio.ReaderWriter
+	if len(args) == 0 {
+		rw = &kio.ByteReadWriter{Reader: c.InOrStdin(), Writer: c.OutOrStdout()}
+	} else {
+		rw = &kio.LocalPackageReadWriter{
+			PackagePath:        args[0],
+			PackageFileName:    kptfileName,
+			IncludeSubpackages: r.IncludeSubpackages,
+			NoDeleteFiles:      true,
+			OnFormattingChurn:  r.churn,
+		}
+	}
+
+	var fltrs []kio.Filter
+	var outputs []kio.Writer
+	if r.Diff {
+		// record the original contents so they can be compared after filtering
+		fltrs = append(fltrs, kio.FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			var err error
+			r.original, err = fileContents(nodes)
+			return nodes, err
+		}))
+	}
+	fltrs = append(fltrs, r)
+	switch {
+	case !r.DryRun:
+		outputs = append(outputs, rw)
+	case !r.Diff:
+		outputs = append(outputs, kio.ByteWriter{Writer: c.OutOrStdout()})
+	}
+	if r.Diff {
+		outputs = append(outputs, kio.WriterFunc(func(nodes []*yaml.RNode) error {
+			return r.writeDiff(c, nodes)
+		}))
+	}
+
+	return handleError(c, kio.Pipeline{
+		Inputs:  []kio.Reader{rw},
+		Filters: fltrs,
+		Outputs: outputs,
+	}.Execute())
+}
+
+// Filter sets and removes the metadata entries on the selected Resources.
+func (r *metadataRunner) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	for i := range nodes {
+		n := nodes[i]
+		m, err := n.GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		if !r.selects(m) {
+			continue
+		}
+
+		for i := range r.Values {
+			// split key, value pairs
+			kv := strings.SplitN(r.Values[i], "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.Errorf("must specify --kv as KEY=VALUE: %s", r.Values[i])
+			}
+			if err := n.PipeE(r.set(kv[0], kv[1])); err != nil {
+				return nil, err
+			}
+		}
+		for i := range r.Remove {
+			if err := n.PipeE(r.clear(r.Remove[i])); err != nil {
+				return nil, err
+			}
+		}
+		// don't leave an empty field behind if the last entry was removed
+		if err := n.PipeE(yaml.Lookup("metadata"), yaml.FieldClearer{
+			Name: r.field, IfEmpty: true}); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// selects returns true if the Resource matches all of the selection flags.
+func (r *metadataRunner) selects(m yaml.ResourceMeta) bool {
+	if r.Kind != "" && r.Kind != m.Kind {
+		return false
+	}
+	if r.ApiVersion != "" && r.ApiVersion != m.APIVersion {
+		return false
+	}
+	if r.Namespace != "" && r.Namespace != m.Namespace {
+		return false
+	}
+	if r.Name != "" && r.Name != m.Name {
+		return false
+	}
+	if r.selector != nil && !r.selector.Matches(labels.Set(m.Labels)) {
+		return false
+	}
+	return true
+}
+
+// writeDiff prints a unified diff for each file whose contents were changed.
+func (r *metadataRunner) writeDiff(c *cobra.Command, nodes []*yaml.RNode) error {
+	updated, err := fileContents(nodes)
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for path := range updated {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if r.original[path] == updated[path] {
+			continue
+		}
+		name := path
+		if name == "" {
+			name = "stdin"
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(r.original[path]),
+			B:        difflib.SplitLines(updated[path]),
+			FromFile: "a/" + name,
+			ToFile:   "b/" + name,
+			Context:  3,
+		})
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		fmt.Fprint(c.OutOrStdout(), diff)
+	}
+	return nil
+}
+
+// fileContents returns the serialized Resources keyed by the file they were read from.
+// The nodes are copied so that serializing them doesn't modify the originals.
+func fileContents(nodes []*yaml.RNode) (map[string]string, error) {
+	files := map[string][]*yaml.RNode{}
+	for i := range nodes {
+		s, err := nodes[i].String()
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		n, err := yaml.Parse(s)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		path, _, err := kioutil.GetFileAnnotations(n)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		files[path] = append(files[path], n)
+	}
+
+	contents := map[string]string{}
+	for path := range files {
+		var b bytes.Buffer
+		err := kio.ByteWriter{
+			Writer:           &b,
+			Sort:             true,
+			ClearAnnotations: []string{kioutil.PathAnnotation},
+		}.Write(files[path])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		contents[path] = b.String()
+	}
+	return contents, nil
+}
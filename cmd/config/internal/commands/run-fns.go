@@ -4,13 +4,20 @@
 package commands
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/metadata"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/endpoint"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/runfn"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -41,6 +48,9 @@ func GetRunFnRunner(name string) *RunFnRunner {
 	r.Command.Flags().StringVar(
 		&r.Image, "image", "",
 		"run this image as a function instead of discovering them.")
+	r.Command.Flags().StringVar(
+		&r.PipelineFile, "pipeline", "",
+		"run the functions of the Pipeline in this file instead of discovering them.")
 	// NOTE: exec plugins execute arbitrary code -- never change the default value of this flag!!!
 	r.Command.Flags().BoolVar(
 		&r.EnableExec, "enable-exec", false /*do not change!*/, "enable support for exec functions -- note: exec functions run arbitrary code -- do not use for untrusted configs!!! (Alpha)")
@@ -57,14 +67,88 @@ func GetRunFnRunner(name string) *RunFnRunner {
 
 	r.Command.Flags().StringVar(
 		&r.ResultsDir, "results-dir", "", "write function results to this dir")
+	r.Command.Flags().BoolVar(
+		&r.ContinueOnError, "continue-on-error", false,
+		"pass the input of failing functions on unchanged, and fail once all the functions have run")
+	r.Command.Flags().StringArrayVar(
+		&r.ExitCodes, "exit-code", []string{},
+		"exit with a code if a function reports a result of a severity, e.g. warning=2")
 
 	r.Command.Flags().BoolVar(
 		&r.Network, "network", false, "enable network access for functions that declare it")
 	r.Command.Flags().StringVar(
 		&r.NetworkName, "network-name", "bridge", "the docker network to run the container in")
+	r.Command.Flags().StringVar(
+		&r.ContainerRuntime, "container-runtime", "",
+		"the container runtime to run container functions with: docker, podman or nerdctl.  "+
+			"Defaults to $KUSTOMIZE_CONTAINER_RUNTIME, or docker")
+	r.Command.Flags().IntVar(
+		&r.Parallelism, "parallelism", 1,
+		"the most container functions to run at once, if their scopes don't overlap")
+	r.Command.Flags().StringVar(
+		&r.CacheDir, "cache-dir", "",
+		"cache the output of container functions in this directory, and reuse it for the same image and input")
+	r.Command.Flags().StringArrayVar(
+		&r.Env, "env", []string{},
+		"an environment variable given to container functions, as KEY=VALUE, or KEY to export the local value")
+	r.Command.Flags().DurationVar(
+		&r.Timeout, "timeout", 0,
+		"kill container functions which run for longer than this, e.g. 5m")
+	r.Command.Flags().StringVar(
+		&r.Memory, "memory", "",
+		"limit the memory of container functions which don't set a limit, e.g. 512m")
+	r.Command.Flags().StringVar(
+		&r.CPUs, "cpus", "",
+		"limit the CPUs of container functions which don't set a limit, e.g. 1.5")
+	r.Command.Flags().StringVar(
+		&r.ImagePullPolicy, "image-pull-policy", "",
+		"when to pull the images of container functions: Always, IfNotPresent or Never")
+	r.Command.Flags().StringVar(
+		&r.Platform, "platform", "",
+		"the platform of the images of container functions to run, e.g. linux/amd64")
+	r.Command.Flags().StringVar(
+		&r.PolicyFile, "policy", "",
+		"a file restricting which container functions may run, checked before any runs")
+	r.Command.Flags().BoolVar(
+		&r.EnableEndpoints, "enable-endpoints", false,
+		"enable support for functions hosted as HTTPS services. (Alpha)")
+	r.Command.Flags().BoolVar(
+		&r.EnableGRPC, "enable-grpc", false,
+		"enable support for functions served over gRPC by servers started once per run. (Alpha)")
+	r.Command.Flags().StringArrayVar(
+		&r.EndpointHeaders, "endpoint-header", []string{},
+		"a header set on the requests to endpoint functions, e.g. 'Authorization=Bearer ${TOKEN}', "+
+			"which requires --endpoint-url-prefix")
+	r.Command.Flags().StringArrayVar(
+		&r.EndpointURLPrefixes, "endpoint-url-prefix", []string{},
+		"a URL which the URLs of endpoint functions must start with, e.g. https://fn.example.com/")
+	r.Command.Flags().StringVar(
+		&r.EndpointCAFile, "endpoint-ca-file", "",
+		"a file of CA certificates to trust for endpoint functions")
+	r.Command.Flags().StringVar(
+		&r.EndpointCertFile, "endpoint-cert-file", "",
+		"a client certificate file to present to endpoint functions")
+	r.Command.Flags().StringVar(
+		&r.EndpointKeyFile, "endpoint-key-file", "",
+		"the key file of --endpoint-cert-file")
 	r.Command.Flags().StringArrayVar(
 		&r.Mounts, "mount", []string{},
-		"a list of storage options read from the filesystem")
+		"a list of storage options read from the filesystem, e.g. "+
+			"type=volume,src=charts,dst=/charts,rw=true.  Mounts are read-only unless rw=true")
+	r.Command.Flags().StringArrayVar(
+		&r.Catalogs, "catalog", []string{},
+		"validate functionConfigs against the schemas of the functions in these catalogs")
+	r.Command.Flags().BoolVar(
+		&r.InspectImages, "inspect-fn-images", false,
+		"validate functionConfigs against the schemas in the labels of function images")
+	addVerifyRoundTripFlag(c, &r.VerifyRoundTrip)
+
+	// print the usage of the function of --image after the help
+	help := c.HelpFunc()
+	c.SetHelpFunc(func(c *cobra.Command, args []string) {
+		help(c, args)
+		r.printFunctionUsage(c.OutOrStdout())
+	})
 	return r
 }
 
@@ -74,27 +158,55 @@ func RunCommand(name string) *cobra.Command {
 
 // RunFnRunner contains the run function
 type RunFnRunner struct {
-	IncludeSubpackages bool
-	Command            *cobra.Command
-	DryRun             bool
-	GlobalScope        bool
-	FnPaths            []string
-	Image              string
-	EnableStar         bool
-	StarPath           string
-	StarURL            string
-	StarName           string
-	EnableExec         bool
-	ExecPath           string
-	RunFns             runfn.RunFns
-	ResultsDir         string
-	Network            bool
-	NetworkName        string
-	Mounts             []string
+	IncludeSubpackages  bool
+	Command             *cobra.Command
+	DryRun              bool
+	GlobalScope         bool
+	FnPaths             []string
+	Image               string
+	PipelineFile        string
+	EnableStar          bool
+	StarPath            string
+	StarURL             string
+	StarName            string
+	EnableExec          bool
+	ExecPath            string
+	RunFns              runfn.RunFns
+	ResultsDir          string
+	ExitCodes           []string
+	ContinueOnError     bool
+	Network             bool
+	NetworkName         string
+	ContainerRuntime    string
+	Parallelism         int
+	CacheDir            string
+	Env                 []string
+	Timeout             time.Duration
+	Memory              string
+	CPUs                string
+	ImagePullPolicy     string
+	Platform            string
+	PolicyFile          string
+	EnableEndpoints     bool
+	EnableGRPC          bool
+	EndpointHeaders     []string
+	EndpointURLPrefixes []string
+	EndpointCAFile      string
+	EndpointCertFile    string
+	EndpointKeyFile     string
+	Mounts              []string
+	Catalogs            []string
+	InspectImages       bool
+	VerifyRoundTrip     string
 }
 
 func (r *RunFnRunner) runE(c *cobra.Command, args []string) error {
-	return handleError(c, r.RunFns.Execute())
+	err := r.RunFns.Execute()
+	if e, ok := err.(*runfn.ExitCodeError); ok && ExitOnError {
+		fmt.Fprintf(c.ErrOrStderr(), "Error: %v\n", e)
+		os.Exit(e.Code)
+	}
+	return handleError(c, err)
 }
 
 // getContainerFunctions parses the commandline flags and arguments into explicit
@@ -236,6 +348,61 @@ data: {}
 	return []*yaml.RNode{rc}, nil
 }
 
+// functionMetadata returns the finder of the definitions of
+// functions, or nil if neither catalogs nor images are read.
+func (r *RunFnRunner) functionMetadata(inspectImages bool) (*metadata.Finder, error) {
+	if len(r.Catalogs) == 0 && !inspectImages {
+		return nil, nil
+	}
+	f := &metadata.Finder{InspectImages: inspectImages}
+	for _, c := range r.Catalogs {
+		defs, err := metadata.ReadCatalog(c)
+		if err != nil {
+			return nil, err
+		}
+		f.Definitions = append(f.Definitions, defs...)
+	}
+	return f, nil
+}
+
+// printFunctionUsage prints the description and usage of
+// the function of --image, if its definition is found.
+func (r *RunFnRunner) printFunctionUsage(w io.Writer) {
+	if r.Image == "" {
+		return
+	}
+	f, err := r.functionMetadata(true)
+	if err != nil {
+		fmt.Fprintf(w, "\nError: %v\n", err)
+		return
+	}
+	d, v, err := f.Find(r.Image, nil)
+	if err != nil {
+		fmt.Fprintf(w, "\nError: %v\n", err)
+		return
+	}
+	if d == nil {
+		fmt.Fprintf(w, "\nNo definition found for function %s.\n", r.Image)
+		return
+	}
+	fmt.Fprintf(w, "\nFunction %s (%s):\n", d.Spec.Names.Kind, r.Image)
+	if d.Spec.Description != "" {
+		fmt.Fprintf(w, "  %s\n", d.Spec.Description)
+	}
+	if v == nil {
+		return
+	}
+	if v.Usage != "" {
+		fmt.Fprintf(w, "\nFunction Usage:\n%s\n", strings.TrimRight(v.Usage, "\n"))
+	}
+	if len(v.Examples) > 0 {
+		fmt.Fprintf(w, "\nFunction Examples:\n")
+		for _, e := range v.Examples {
+			fmt.Fprintf(w, "  %s\n", e)
+		}
+	}
+}
+
 func toStorageMounts(mounts []string) []runtimeutil.StorageMount {
 	var sms []runtimeutil.StorageMount
 	for _, mount := range mounts {
@@ -244,6 +411,33 @@ func toStorageMounts(mounts []string) []runtimeutil.StorageMount {
 	return sms
 }
 
+// toExitCodes parses the severities and exit codes of --exit-code,
+// e.g. warning=2.
+func toExitCodes(codes []string) (map[framework.Severity]int, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	m := map[framework.Severity]int{}
+	for _, c := range codes {
+		kv := strings.SplitN(c, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("exit codes must have severities and codes separated by =")
+		}
+		severity := framework.Severity(kv[0])
+		if severity != framework.Error && severity != framework.Warning &&
+			severity != framework.Info {
+			return nil, errors.Errorf(
+				"unsupported severity %s, must be one of error, warning or info", kv[0])
+		}
+		code, err := strconv.Atoi(kv[1])
+		if err != nil || code < 0 || code > 125 {
+			return nil, errors.Errorf("exit code %s of %s must be between 0 and 125", kv[1], kv[0])
+		}
+		m[severity] = code
+	}
+	return m, nil
+}
+
 func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	if !r.EnableStar && (r.StarPath != "" || r.StarURL != "") {
 		return errors.Errorf("must specify --enable-star with --star-path and --star-url")
@@ -271,6 +465,13 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if r.PipelineFile != "" {
+		pipelineFns, err := runfn.ReadPipeline(r.PipelineFile)
+		if err != nil {
+			return err
+		}
+		fns = append(fns, pipelineFns...)
+	}
 
 	// set the output to stdout if in dry-run mode or no arguments are specified
 	var output io.Writer
@@ -292,19 +493,82 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	// parse mounts to set storageMounts
 	storageMounts := toStorageMounts(r.Mounts)
 
+	fnMetadata, err := r.functionMetadata(r.InspectImages)
+	if err != nil {
+		return err
+	}
+
+	churn, err := onFormattingChurn(c, r.VerifyRoundTrip)
+	if err != nil {
+		return err
+	}
+
+	var policy *runfn.Policy
+	if r.PolicyFile != "" {
+		policy, err = runfn.ReadPolicy(r.PolicyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	headers := map[string]string{}
+	for _, h := range r.EndpointHeaders {
+		kv := strings.SplitN(h, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("endpoint headers must have names and values separated by =")
+		}
+		headers[kv[0]] = kv[1]
+	}
+	if len(headers) > 0 && len(r.EndpointURLPrefixes) == 0 {
+		return errors.Errorf("must specify --endpoint-url-prefix with --endpoint-header, " +
+			"so headers are only sent to the endpoints they're meant for")
+	}
+
+	var tlsConfig *tls.Config
+	if r.EndpointCAFile != "" || r.EndpointCertFile != "" || r.EndpointKeyFile != "" {
+		tlsConfig, err = endpoint.TLSConfig(r.EndpointCAFile, r.EndpointCertFile, r.EndpointKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	exitCodes, err := toExitCodes(r.ExitCodes)
+	if err != nil {
+		return err
+	}
+
 	r.RunFns = runfn.RunFns{
-		FunctionPaths:  r.FnPaths,
-		GlobalScope:    r.GlobalScope,
-		Functions:      fns,
-		Output:         output,
-		Input:          input,
-		Path:           path,
-		Network:        r.Network,
-		NetworkName:    r.NetworkName,
-		EnableStarlark: r.EnableStar,
-		EnableExec:     r.EnableExec,
-		StorageMounts:  storageMounts,
-		ResultsDir:     r.ResultsDir,
+		FunctionPaths:       r.FnPaths,
+		GlobalScope:         r.GlobalScope,
+		Functions:           fns,
+		Output:              output,
+		Input:               input,
+		Path:                path,
+		Network:             r.Network,
+		NetworkName:         r.NetworkName,
+		ContainerRuntime:    r.ContainerRuntime,
+		Parallelism:         r.Parallelism,
+		CacheDir:            r.CacheDir,
+		Env:                 r.Env,
+		Timeout:             r.Timeout,
+		Memory:              r.Memory,
+		CPUs:                r.CPUs,
+		ImagePullPolicy:     r.ImagePullPolicy,
+		Platform:            r.Platform,
+		Policy:              policy,
+		EnableStarlark:      r.EnableStar,
+		EnableExec:          r.EnableExec,
+		EnableEndpoints:     r.EnableEndpoints,
+		EnableGRPC:          r.EnableGRPC,
+		EndpointHeaders:     headers,
+		EndpointURLPrefixes: r.EndpointURLPrefixes,
+		EndpointTLSConfig:   tlsConfig,
+		StorageMounts:       storageMounts,
+		ResultsDir:          r.ResultsDir,
+		ExitCodes:           exitCodes,
+		ContinueOnError:     r.ContinueOnError,
+		FunctionMetadata:    fnMetadata,
+		OnFormattingChurn:   churn,
 	}
 
 	// don't consider args for the function
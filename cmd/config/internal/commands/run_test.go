@@ -4,8 +4,11 @@
 package commands
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -200,9 +203,13 @@ apiVersion: v1
 			args: []string{"run", "dir", "--enable-star"},
 			path: "dir",
 			expectedStruct: &runfn.RunFns{
-				Path:           "dir",
-				NetworkName:    "bridge",
-				EnableStarlark: true,
+				Path:                "dir",
+				NetworkName:         "bridge",
+				EnableStarlark:      true,
+				Parallelism:         1,
+				EndpointHeaders:     map[string]string{},
+				EndpointURLPrefixes: []string{},
+				Env:                 []string{},
 			},
 		},
 		{
@@ -253,9 +260,13 @@ apiVersion: v1
 			args: []string{"run", "dir", "--results-dir", "foo/", "--image", "foo:bar", "--", "a=b", "c=d", "e=f"},
 			path: "dir",
 			expectedStruct: &runfn.RunFns{
-				Path:        "dir",
-				NetworkName: "bridge",
-				ResultsDir:  "foo/",
+				Path:                "dir",
+				NetworkName:         "bridge",
+				ResultsDir:          "foo/",
+				Parallelism:         1,
+				EndpointHeaders:     map[string]string{},
+				EndpointURLPrefixes: []string{},
+				Env:                 []string{},
 			},
 			expected: `
 metadata:
@@ -387,3 +398,52 @@ apiVersion: v1
 	}
 
 }
+
+// TestRunFnCommand_helpFunctionUsage verifies that the help of
+// the run command prints the usage of the function of --image.
+func TestRunFnCommand_helpFunctionUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	catalog := filepath.Join(dir, "catalog.yaml")
+	if !assert.NoError(t, ioutil.WriteFile(catalog, []byte(`apiVersion: config.kubernetes.io/v1alpha1
+kind: KRMFunctionDefinition
+spec:
+  group: fn.example.com
+  names:
+    kind: SetNamespace
+  description: Sets the namespace of resources.
+  versions:
+  - name: v1
+    usage: |
+      Set spec.namespace to the namespace to use.
+    examples:
+    - https://example.com/set-namespace/examples/simple
+    runtime:
+      container:
+        image: example.com/set-namespace:v1.0.0
+`), 0600)) {
+		t.FailNow()
+	}
+
+	r := GetRunFnRunner("kustomize")
+	out := &bytes.Buffer{}
+	r.Command.SetOut(out)
+	r.Command.SetArgs([]string{
+		"--image", "example.com/set-namespace:v1.0.1", "--catalog", catalog, "--help"})
+	if !assert.NoError(t, r.Command.Execute()) {
+		t.FailNow()
+	}
+	assert.Contains(t, out.String(), `
+Function SetNamespace (example.com/set-namespace:v1.0.1):
+  Sets the namespace of resources.
+
+Function Usage:
+Set spec.namespace to the namespace to use.
+
+Function Examples:
+  https://example.com/set-namespace/examples/simple
+`)
+}
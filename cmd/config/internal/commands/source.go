@@ -5,6 +5,7 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
@@ -16,7 +17,7 @@ import (
 func GetSourceRunner(name string) *SourceRunner {
 	r := &SourceRunner{}
 	c := &cobra.Command{
-		Use:     "source DIR",
+		Use:     "source [DIR|URL]...",
 		Short:   commands.SourceShort,
 		Long:    commands.SourceLong,
 		Example: commands.SourceExamples,
@@ -29,6 +30,15 @@ func GetSourceRunner(name string) *SourceRunner {
 		"output using this format.")
 	c.Flags().StringVar(&r.FunctionConfig, "function-config", "",
 		"path to function config.")
+	c.Flags().StringArrayVar(&r.Headers, "header", []string{},
+		"a header set on the requests for URLs, e.g. 'Authorization=Bearer ${TOKEN}', "+
+			"which requires --header-url-prefix.")
+	c.Flags().StringArrayVar(&r.HeaderURLPrefixes, "header-url-prefix", []string{},
+		"a URL which the URLs sent the headers must start with, e.g. https://example.com/releases/.")
+	c.Flags().StringArrayVar(&r.Checksums, "checksum", []string{},
+		"the sha256 checksum of the document at a URL, as URL=CHECKSUM.")
+	c.Flags().StringVar(&r.CacheDir, "cache-dir", "",
+		"directory keeping the documents at URLs with checksums, so they're only fetched once.")
 	r.Command = c
 	_ = c.MarkFlagFilename("function-config", "yaml", "json", "yml")
 	return r
@@ -40,10 +50,33 @@ func SourceCommand(name string) *cobra.Command {
 
 // SourceRunner contains the run function
 type SourceRunner struct {
-	WrapKind       string
-	WrapApiVersion string
-	FunctionConfig string
-	Command        *cobra.Command
+	WrapKind          string
+	WrapApiVersion    string
+	FunctionConfig    string
+	Headers           []string
+	HeaderURLPrefixes []string
+	Checksums         []string
+	CacheDir          string
+	Command           *cobra.Command
+}
+
+// isURL returns true if the argument of source is a URL rather than a
+// directory.
+func isURL(arg string) bool {
+	return strings.Contains(arg, "://")
+}
+
+// toMap splits each of values into a key and a value separated by =.
+func toMap(values []string, name string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, v := range values {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%s must be KEY=VALUE, not %s", name, v)
+		}
+		m[kv[0]] = kv[1]
+	}
+	return m, nil
 }
 
 func (r *SourceRunner) runE(c *cobra.Command, args []string) error {
@@ -69,14 +102,37 @@ func (r *SourceRunner) runE(c *cobra.Command, args []string) error {
 		FunctionConfig:        functionConfig,
 	})
 
+	headers, err := toMap(r.Headers, "headers")
+	if err != nil {
+		return err
+	}
+	if len(headers) > 0 && len(r.HeaderURLPrefixes) == 0 {
+		return fmt.Errorf("must specify --header-url-prefix with --header, " +
+			"so headers are only sent to the URLs they're meant for")
+	}
+	checksums, err := toMap(r.Checksums, "checksums")
+	if err != nil {
+		return err
+	}
+
 	var inputs []kio.Reader
 	for _, a := range args {
+		if isURL(a) {
+			inputs = append(inputs, kio.HTTPReader{
+				URLs:              []string{a},
+				Headers:           headers,
+				HeaderURLPrefixes: r.HeaderURLPrefixes,
+				Checksums:         checksums,
+				CacheDir:          r.CacheDir,
+			})
+			continue
+		}
 		inputs = append(inputs, kio.LocalPackageReader{PackagePath: a, MatchFilesGlob: kio.MatchAll})
 	}
 	if len(inputs) == 0 {
 		inputs = []kio.Reader{&kio.ByteReader{Reader: c.InOrStdin()}}
 	}
 
-	err := kio.Pipeline{Inputs: inputs, Outputs: outputs}.Execute()
+	err = kio.Pipeline{Inputs: inputs, Outputs: outputs}.Execute()
 	return handleError(c, err)
 }
@@ -314,3 +314,14 @@ items:
 		return
 	}
 }
+
+func TestSourceCommand_HeaderWithoutURLPrefix(t *testing.T) {
+	r := commands.GetSourceRunner("")
+	r.Command.SetArgs([]string{"https://example.com/app.yaml",
+		"--header", "Authorization=Bearer ${TOKEN}"})
+	r.Command.SetOut(&bytes.Buffer{})
+	r.Command.SetErr(&bytes.Buffer{})
+	assert.EqualError(t, r.Command.Execute(),
+		"must specify --header-url-prefix with --header, "+
+			"so headers are only sent to the URLs they're meant for")
+}
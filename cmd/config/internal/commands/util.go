@@ -63,6 +63,38 @@ var ExitOnError bool
 // StackOnError if true, will print a stack trace on failure.
 var StackOnError bool
 
+// addVerifyRoundTripFlag adds the --verify-round-trip flag to
+// a command which writes Resources back to their files.
+func addVerifyRoundTripFlag(c *cobra.Command, value *string) {
+	c.Flags().StringVar(value, "verify-round-trip", "",
+		`check that files whose Resources aren't changed aren't reformatted either -- `+
+			`"report" lists them on stderr, "fail" fails without writing any file.`)
+}
+
+// onFormattingChurn returns the function which is told about the
+// files a command would only reformat, for the value of its
+// --verify-round-trip flag, or nil if the flag isn't set.
+func onFormattingChurn(c *cobra.Command, verify string) (func([]string) error, error) {
+	switch verify {
+	case "":
+		return nil, nil
+	case "report":
+		return func(paths []string) error {
+			for _, p := range paths {
+				fmt.Fprintf(c.ErrOrStderr(), "%s: only reformatted\n", p)
+			}
+			return nil
+		}, nil
+	case "fail":
+		return func(paths []string) error {
+			return fmt.Errorf("%d files would only be reformatted:\n  %s",
+				len(paths), strings.Join(paths, "\n  "))
+		}, nil
+	}
+	return nil, fmt.Errorf(
+		`--verify-round-trip must be "report" or "fail", not %q`, verify)
+}
+
 const cmdName = "kustomize config"
 
 // FixDocs replaces instances of old with new in the docs for c
@@ -4,19 +4,57 @@
 // Code generated by "mdtogo"; DO NOT EDIT.
 package commands
 
-var AnnotateShort = `[Alpha] Set an annotation on Resources.`
+var AnnotateShort = `[Alpha] Set or remove annotations on Resources.`
 var AnnotateLong = `
-[Alpha]  Set an annotation on Resources.
+[Alpha] Set or remove annotations on Resources.
+
+If DIR is not specified, Resources are read from stdin and written to stdout.
 
   DIR:
     Path to local directory.
+
+#### Flags:
+
+  --kv:
+    Annotation to set as KEY=VALUE.  May be specified multiple times.
+
+  --remove:
+    Annotation KEY to remove.  May be specified multiple times.
+
+  --kind, --apiVersion, --name, --namespace:
+    Only annotate Resources with a matching kind, apiVersion, name or namespace.
+
+  --selector, -l:
+    Only annotate Resources whose labels match the label selector,
+    e.g. 'app=nginx,tier!=db'.
+
+  --include-subpackages:
+    Also annotate Resources in subpackages -- directories containing a Kptfile.
+    Defaults to true.
+
+  --dry-run:
+    Print the modified Resources to stdout rather than writing them.
+
+  --diff:
+    Print a unified diff of the changed files to stdout.  Combine with --dry-run
+    to preview changes without writing them.
+
+  --verify-round-trip:
+    Check that files whose Resources aren't changed aren't reformatted either.
+    "report" lists such files on stderr, "fail" fails without writing any file.
 `
 var AnnotateExamples = `
     kustomize cfg annotate my-dir/ --kv foo=bar
 
     kustomize cfg annotate my-dir/ --kv foo=bar --kv a=b
 
-    kustomize cfg annotate my-dir/ --kv foo=bar --kind Deployment --name foo`
+    kustomize cfg annotate my-dir/ --kv foo=bar --kind Deployment --name foo
+
+    # remove an annotation from all Resources with the label app=nginx
+    kustomize cfg annotate my-dir/ --remove foo -l app=nginx
+
+    # preview the changes without writing them
+    kustomize cfg annotate my-dir/ --kv foo=bar --dry-run --diff`
 
 var CatShort = `[Alpha] Print Resource Config from a local directory.`
 var CatLong = `
@@ -45,6 +83,10 @@ Registers the completion command with known shells (e.g. .bashrc, .bash_profile,
 
     complete -C /Users/USER/go/bin/kustomize kustomize
 
+In addition to commands and flags, some arguments are completed from the package contents --
+e.g. setter names for 'kustomize cfg set DIR <TAB>' and image names from the kustomization for
+'kustomize edit set image <TAB>'.
+
 Because the completion command is embedded in kustomize directly, there is no need to update
 it separately from the kustomize binary.
 
@@ -58,10 +100,28 @@ var CountLong = `
 
   DIR:
     Path to local directory.
+
+#### Flags:
+
+  --group-by:
+    Count Resources grouped by a field.  One of: kind, namespace, apiVersion, label=KEY.
+    Resources missing the field are counted as <none>.  Overrides --kind.
+
+  --kind:
+    Count Resources grouped by kind.  Defaults to true.
+
+  --output, -o:
+    Output format.  One of: text, json.
 `
 var CountExamples = `
     # print Resource counts from a directory
-    kustomize cfg count my-dir/`
+    kustomize cfg count my-dir/
+
+    # print Resource counts by namespace
+    kustomize cfg count my-dir/ --group-by namespace
+
+    # print Resource counts by the value of the "app" label as json
+    kustomize cfg count my-dir/ --group-by label=app -o json`
 
 var CreateSetterShort = `[Alpha] Create a custom setter for a Resource field`
 var CreateSetterLong = `
@@ -87,7 +147,15 @@ var CreateSetterExamples = `
     # create a setter for a substring of a field rather than the full field -- e.g. only the
     # image tag, not the full image
     kustomize cfg create-setter DIR/ image-tag v1.0.1 --type "string" \
-        --field image --description "current stable release"`
+        --field image --description "current stable release"
+
+    # create a setter which records each value it's set to, with who set it and when
+    kustomize cfg create-setter DIR/ replicas 3 --type "integer" --field replicas \
+        --record-history
+
+    # create a setter for names which must be DNS-1123 labels
+    kustomize cfg create-setter DIR/ name nginx --type "string" --field name \
+        --pattern '^[a-z0-9]([-a-z0-9]*[a-z0-9])?$' --max-length 63`
 
 var DeleteSetterShort = `[Alpha] Delete a custom setter for a Resource field`
 var DeleteSetterLong = `
@@ -131,6 +199,10 @@ field paths.
 
 - .spec.template.spec.containers (by element name)
 - .webhooks.rules.operations (by element value)
+
+Files whose Resources would only be reformatted, rather than changed,
+are listed on stderr with --verify-round-trip=report, and fail the
+command before any file is written with --verify-round-trip=fail.
 `
 var FmtExamples = `
 	# format file1.yaml and file2.yml
@@ -143,7 +215,10 @@ var FmtExamples = `
 	kubectl get -o yaml deployments | kustomize cfg fmt
 
 	# format kustomize output
-	kustomize build | kustomize cfg fmt`
+	kustomize build | kustomize cfg fmt
+
+	# list the files which aren't formatted, without writing them
+	kustomize cfg fmt my-dir/ --verify-round-trip=fail`
 
 var GrepShort = `[Alpha] Search for matching Resources in a directory or from stdin`
 var GrepLong = `
@@ -155,6 +230,10 @@ var GrepLong = `
     List elements are matched as '[list-elem-field=field-value]'
     The value to match is expressed as '=value'
     '.' as part of a key or value can be escaped as '\.'
+    Values are matched as regular expressions with '=value', and as
+    quantities with '>value', '>=value', '<value' and '<=value'
+    Conditions may be combined with '&&' and '||', and '&&' binds
+    tighter than '||'
 
   DIR:
     Path to local directory.
@@ -170,7 +249,81 @@ var GrepExamples = `
     kustomize cfg grep "metadata.name=nginx" my-dir/ | kustomize cfg tree
 
     # look for Resources matching a specific container image
-    kustomize cfg grep "spec.template.spec.containers[name=nginx].image=nginx:1\.7\.9" my-dir/ | kustomize cfg tree`
+    kustomize cfg grep "spec.template.spec.containers[name=nginx].image=nginx:1\.7\.9" my-dir/ | kustomize cfg tree
+
+    # find Deployments with more than 3 replicas running images from gcr.io
+    kustomize cfg grep "kind=Deployment && spec.replicas>3 && spec.template.spec.containers[name=nginx].image=^gcr\.io/" my-dir/`
+
+var ImportShort = `[Alpha] Import the Resources rendered by jsonnet or cdk8s into a directory.`
+var ImportLong = `
+[Alpha] Import the Resources rendered by jsonnet or cdk8s into a directory.
+
+Renders a jsonnet program with the jsonnet program, or synthesizes a cdk8s app
+with the cdk8s program, and merges the Resources into a local directory, so
+that they can be kustomized further.
+
+Resources already in the directory are merged with the rendered Resources of
+the same Group/Version/Kind/Namespace/Name, keeping their files, comments and
+any fields only they have.  Other Resources are written to files named
+NAMESPACE/KIND_NAME.yaml.  The files are formatted.
+
+jsonnet output may be a Resource, a List of Resources, or an array or object
+of those, nested to any depth.
+
+  DIR:
+    Path to local directory.  Created if it doesn't exist.
+
+#### Flags:
+
+  --jsonnet:
+    Path to the jsonnet program to render.
+
+  --jpath, -J:
+    Library directory of the jsonnet program.  May be specified multiple times.
+
+  --ext-str, --tla-str:
+    External variable or top level argument of the jsonnet program, as
+    KEY=VALUE.  May be specified multiple times.
+
+  --cdk8s:
+    Path to the directory of the cdk8s app to synthesize.
+`
+var ImportExamples = `
+    # import a jsonnet program into my-dir/
+    kustomize cfg import my-dir/ --jsonnet app.jsonnet -J vendor/ --ext-str env=prod
+
+    # import a cdk8s app into my-dir/
+    kustomize cfg import my-dir/ --cdk8s my-app/`
+
+var InferSettersShort = `[Alpha] Update the values of setters to the values of the fields referencing them.`
+var InferSettersLong = `
+Update the values of the setters in the Krmfile to the values of the fields of
+Resources which reference them, rather than setting the fields to the values of
+the setters.  This adopts setters in a package whose fields were customized after
+the setters were created, e.g. by hand, without clobbering the fields when the
+setters are next set.
+
+  DIR
+
+    A directory containing Resource configuration, and a Krmfile.
+
+The values of setters of substitutions are inferred by matching the values of the
+fields against the patterns, or regexes, of the substitutions.  Fields which don't
+match are skipped.  If fields referencing the same setter have different values,
+nothing is updated.  The Resources are never changed.
+`
+var InferSettersExamples = `
+  Show the setters which would be updated:
+
+    $ kustomize cfg infer-setters DIR/ --dry-run
+      NAME      OLD VALUE   NEW VALUE
+    replicas    1           3
+    image-tag   1.7.9       1.8.1
+    would update 2 setters
+
+  Update the setters:
+
+    $ kustomize cfg infer-setters DIR/ --set-by alice`
 
 var InitShort = `[Alpha] Initialize a directory with a Krmfile.`
 var InitLong = `
@@ -186,6 +339,59 @@ var InitExamples = `
     # create a Krmfile in my-dir/
     kustomize cfg init my-dir/`
 
+var LabelShort = `[Alpha] Set or remove labels on Resources.`
+var LabelLong = `
+[Alpha] Set or remove labels on Resources.
+
+Only metadata.labels is modified -- selectors and Pod template labels are
+left unchanged.
+
+If DIR is not specified, Resources are read from stdin and written to stdout.
+
+  DIR:
+    Path to local directory.
+
+#### Flags:
+
+  --kv:
+    Label to set as KEY=VALUE.  May be specified multiple times.
+
+  --remove:
+    Label KEY to remove.  May be specified multiple times.
+
+  --kind, --apiVersion, --name, --namespace:
+    Only label Resources with a matching kind, apiVersion, name or namespace.
+
+  --selector, -l:
+    Only label Resources whose labels match the label selector,
+    e.g. 'app=nginx,tier!=db'.
+
+  --include-subpackages:
+    Also label Resources in subpackages -- directories containing a Kptfile.
+    Defaults to true.
+
+  --dry-run:
+    Print the modified Resources to stdout rather than writing them.
+
+  --diff:
+    Print a unified diff of the changed files to stdout.  Combine with --dry-run
+    to preview changes without writing them.
+
+  --verify-round-trip:
+    Check that files whose Resources aren't changed aren't reformatted either.
+    "report" lists such files on stderr, "fail" fails without writing any file.
+`
+var LabelExamples = `
+    kustomize cfg label my-dir/ --kv team=payments
+
+    kustomize cfg label my-dir/ --kv team=payments --kind Deployment --name foo
+
+    # remove the tier label from Resources with the label app=nginx
+    kustomize cfg label my-dir/ --remove tier -l app=nginx
+
+    # preview the changes without writing them
+    kustomize cfg label my-dir/ --kv team=payments --dry-run --diff`
+
 var ListSettersShort = `[Alpha] List setters for Resources.`
 var ListSettersLong = `
 List setters for Resources.
@@ -197,13 +403,37 @@ List setters for Resources.
   NAME
 
     Optional.  The name of the setter to display.
+
+  --history
+
+    List the values the NAME setter was set to, oldest first, with who set
+    them and when.  Only setters created with --record-history, or whose
+    definition has recordHistory: true, record their values.
+
+  --group
+
+    Optional.  List only the setters in the group, and in the groups nested
+    in it, e.g. frontend lists frontend.replicas and frontend.web.image.
 `
 var ListSettersExamples = `
   Show setters:
 
     $ kustomize cfg list-setters DIR/
         NAME      DESCRIPTION   VALUE     TYPE     COUNT   SETBY  
-    name-prefix   ''            PREFIX    string   2`
+    name-prefix   ''            PREFIX    string   2
+
+  Show the history of a setter:
+
+    $ kustomize cfg list-setters DIR/ replicas --history
+          TIMESTAMP          VALUE   SET BY
+    2020-07-01T12:00:00Z     4       alice
+    2020-07-02T15:30:00Z     5       bob
+
+  Show the setters of a group:
+
+    $ kustomize cfg list-setters DIR/ --group frontend
+            NAME          DESCRIPTION   VALUE   TYPE      COUNT   SETBY
+    frontend.replicas     ''            3       integer   1`
 
 var MergeShort = `[Alpha] Merge Resource configuration files`
 var MergeLong = `
@@ -237,12 +467,46 @@ set of Resources that was forked and an updated set of those Resources, then app
 If a field value differs between the ORIGINAL_DIR and UPDATED_DIR, the value from the UPDATED_DIR is taken and applied
 to the Resource in the DEST_DIR.
 
+If the DEST_DIR also changed the field, to a different value, the field is a conflict.  Conflicts are reported
+with their original, dest and updated values, and resolved as --on-conflict says.
+
+#### Flags:
+
+  --original:
+    Path to the original package (ORIGINAL_DIR).
+
+  --updated:
+    Path to the updated package (UPDATED_DIR).
+
+  --dest:
+    Path to the destination package (DEST_DIR).  The merged Resources are written here.
+
+  --schema:
+    Path to an OpenAPI schema file.  The schema is used to determine the merge keys of
+    lists, e.g. for lists in custom resources.  May be specified multiple times.
+
+  --infer-list-merge:
+    Infer the merge keys of lists which have no schema from the fields of their elements.
+
+  --path-merge-key:
+    Use the path of the Resource file as part of the Resource merge key.
+
+  --on-conflict:
+    How to resolve conflicts: 'update' takes the value from the UPDATED_DIR, 'dest' keeps
+    the value in the DEST_DIR, and 'fail' fails the merge, writing nothing.  Defaults to 'update'.
+
 For information on merge rules, run:
 
 	kustomize cfg docs-merge3
 `
 var Merge3Examples = `
-    kustomize cfg merge3 --ancestor a/ --from b/ --to c/`
+    kustomize cfg merge3 --original a/ --updated b/ --dest c/
+
+    # use the schema of a custom resource to merge its lists
+    kustomize cfg merge3 --original a/ --updated b/ --dest c/ --schema crd-schema.json
+
+    # keep local changes to fields which were also changed upstream
+    kustomize cfg merge3 --original a/ --updated b/ --dest c/ --on-conflict dest`
 
 var RunFnsShort = `[Alpha] Reoncile config functions to Resources.`
 var RunFnsLong = `
@@ -289,9 +553,206 @@ order they appear in the file).
   file contents.
 
   See ` + "`" + `kustomize help cfg docs-fn` + "`" + ` for more details on writing functions.
+
+#### Scoping:
+
+  Functions are given the Resources under the directory of their config, or under its parent
+  if the directory is named functions.  Functions may instead declare a selector, and are
+  then given the Resources it selects by apiVersion, kind, labels and annotations, wherever
+  they are, e.g. in monorepos which aren't laid out by directory:
+
+	config.kubernetes.io/function: |
+	  container:
+	    image: gcr.io/example/examplefunction:v1.0.1
+	  selector:
+	    kind: Deployment
+	    labels:
+	      app: frontend
+
+#### Pipelines:
+
+  Rather than discover the functions in DIR, run may run the functions a Pipeline declares,
+  in the order of its steps, with --pipeline FILE.  Each step is a function, as in the
+  config.kubernetes.io/function annotation, and its config, which defaults to an empty
+  ConfigMap.  Paths of starlark functions are relative to DIR, and starlark and exec
+  functions must still be enabled with --enable-star and --enable-exec.
+
+	apiVersion: config.kubernetes.io/v1alpha1
+	kind: Pipeline
+	metadata:
+	  name: app
+	steps:
+	- container:
+	    image: gcr.io/example/set-namespace:v1
+	  config:
+	    apiVersion: v1
+	    kind: ConfigMap
+	    data:
+	      namespace: app
+	- starlark:
+	    path: fns/set-replicas.star
+
+#### Storage Mounts:
+
+  Storage may be mounted into container functions with --mount, in the form of docker's
+  --mount flag, e.g. type=bind,src=/tmp/charts,dst=/charts.  Mounts are read-only, unless
+  they're given rw=true, e.g. so functions can cache chart downloads in a writable volume:
+
+	kustomize fn run example/ --mount type=volume,src=charts,dst=/charts,rw=true
+
+#### Container Runtimes:
+
+  Container functions are run with docker by default.  They may be run with podman or
+  nerdctl instead, by --container-runtime or the KUSTOMIZE_CONTAINER_RUNTIME environment
+  variable, e.g. where CI environments don't have the docker cli.
+
+#### Parallelism:
+
+  Container functions are run one after the other by default.  With --parallelism N,
+  consecutive container functions whose scopes don't overlap, e.g. those in frontend/
+  and backend/, are run concurrently, at most N at once.  Each is given the Resources
+  in its scope, and the output is the same for every run.  Functions scoped to all the
+  Resources are still run on their own.
+
+#### Caching:
+
+  With --cache-dir DIR, the output of each container function is cached in DIR, keyed by
+  the digest of its image, its environment and its input, which includes its functionConfig.
+  Functions are only run again if one of them changed.  Functions with storage mounts or
+  network access aren't cached, as their output may depend on more than their input.
+
+  Cached functions aren't given the local environment, only the variables they declare
+  under container.env and those given with --env, as KEY=VALUE, or KEY to export the
+  local value:
+
+	config.kubernetes.io/function: |
+	  container:
+	    image: gcr.io/my-org/fn:v1
+	    env:
+	    - LOG_LEVEL=debug
+
+#### Timeouts:
+
+  With --timeout, container functions which run for longer than the timeout, e.g. 5m, are
+  killed, and run fails with the output the function wrote to stderr before it was killed.
+
+#### Platforms:
+
+  With --platform, e.g. linux/amd64, container functions run the image of that platform,
+  so they behave the same on hosts of any architecture, e.g. Apple Silicon laptops and
+  amd64 CI runners.
+
+#### Resource Limits:
+
+  With --memory and --cpus, e.g. 512m and 1.5, the memory and CPUs of each container
+  function are limited, so a misbehaving function can't exhaust the host running it.
+  A function may set its own limits, which override these defaults:
+
+    config.kubernetes.io/function: |
+      container:
+        image: gcr.io/example.com/image:version
+        memory: 1g
+        cpus: "2"
+
+#### Image Pull Policy:
+
+  With --image-pull-policy, the images of container functions are pulled Always, e.g. so CI
+  runs the latest image of a tag, IfNotPresent, or Never, e.g. in air-gapped environments.
+  Otherwise the container runtime decides when to pull them.
+
+#### Policies:
+
+  With --policy FILE, container functions are checked against the policy in FILE before
+  any runs, and run fails if any violates it, e.g. so developers may only run the functions
+  their platform team trusts.  Images are allowed or denied by globs or regular expressions,
+  and network access and storage mounts may be denied.
+
+	allow:
+	- glob: gcr.io/my-org/*
+	deny:
+	- regex: experimental
+	denyNetwork: true
+	denyMounts: true
+
+#### Endpoints:
+
+  With --enable-endpoints, functions may be hosted as HTTPS services rather than images, so
+  organizations may share transformations without distributing images.  The ResourceList is
+  POSTed to the url of the function, and the response is the transformed ResourceList.
+  Headers, e.g. for auth, are set with --endpoint-header, and may reference environment
+  variables so tokens needn't be written down.  As the url is read from the package, headers
+  require --endpoint-url-prefix, and functions whose url doesn't have the scheme, host and
+  leading path segments of a prefix fail before any function runs, so a package can't send
+  the headers elsewhere.  --endpoint-ca-file trusts a private CA, and
+  --endpoint-cert-file and --endpoint-key-file present a client certificate.  --timeout
+  limits how long the endpoint may take to respond.
+
+	metadata:
+	  annotations:
+	    config.kubernetes.io/function: |
+	      endpoint:
+	        url: https://fn.example.com/set-labels
+
+	kustomize fn run example/ --enable-endpoints \
+	  --endpoint-header 'Authorization=Bearer ${FN_TOKEN}' \
+	  --endpoint-url-prefix https://fn.example.com/
+
+#### gRPC Servers:
+
+  With --enable-grpc, functions may be served over gRPC by a server which is started once
+  per run, as a container or a local executable, and reused by every function it serves,
+  so pipelines running the same function many times don't start a container each time.
+  The server listens on the unix socket in the KUSTOMIZE_FN_GRPC_ADDRESS environment
+  variable, and each function sends its ResourceList in a call of the Run method of the
+  kustomize.fn.v1.FunctionService.  Servers run as containers with the same options as
+  container functions, so they have no network unless they require one, and the socket is
+  mounted into their container.  Servers run as local executables also need --enable-exec.
+
+	metadata:
+	  annotations:
+	    config.kubernetes.io/function: |
+	      grpc:
+	        image: gcr.io/example.com/set-labels-server:v1
+
+	kustomize fn run example/ --enable-grpc
+
+#### Results:
+
+  With --results-dir DIR, the results of each function are written to DIR, and the results
+  of all the functions are merged into DIR/results.yaml, each with the function, severity,
+  message, file, field and resource of the result.
+
+  With --exit-code SEVERITY=CODE, e.g. warning=2, run exits with CODE if a function reports
+  a result of SEVERITY, so CI can distinguish warnings from errors.  Run exits with the
+  highest code of the results, errors exit with 1 unless they're mapped, and other
+  severities with 0.
+
+#### Continuing on Errors:
+
+  By default, run stops at the first function which fails.  With --continue-on-error, the
+  input of a failing function is passed on unchanged to the next, its error is added to the
+  results, and run fails once all the functions have run, so one failing function doesn't
+  hide the errors of the others.  The exit code is that of the worst failure or result.
+
+#### Function Metadata:
+
+  Functions may declare their metadata as KRMFunctionDefinitions, per the KRM Functions
+  Catalog spec: the schema of their functionConfigs, and their usage.  Definitions are
+  read from the catalogs given with --catalog, and, with --inspect-fn-images, from the
+  config.kubernetes.io/function-definition label of function images docker has locally.
+
+  The functionConfig of each function with a definition is validated against the schema
+  of the definition before the function is run.  'kustomize fn run --image IMAGE --help'
+  prints the usage of the function IMAGE runs, if its definition is found.
 `
 var RunFnsExamples = `
-kustomize fn run example/`
+kustomize fn run example/
+
+# validate functionConfigs against the schemas of a catalog
+kustomize fn run example/ --catalog catalog.yaml
+
+# print the usage of a function
+kustomize fn run --image gcr.io/example/examplefunction:v1.0.1 --catalog catalog.yaml --help`
 
 var SetShort = `[Alpha] Set values on Resources fields values.`
 var SetLong = `
@@ -322,6 +783,74 @@ the configuration as comments.
 To print the possible setters for the Resources in a directory, run
 ` + "`" + `list-setters` + "`" + ` on a directory -- e.g. ` + "`" + `kustomize cfg list-setters DIR/` + "`" + `.
 
+#### Environment variables
+
+A setter may be set to the value of an environment variable with
+` + "`" + `--from-env` + "`" + `, e.g. ` + "`" + `kustomize cfg set DIR/ image-tag --from-env IMAGE_TAG` + "`" + `.
+The variable is recorded as ` + "`" + `fromEnv` + "`" + ` in the setter's definition, until the
+setter is next set to a value given another way.
+
+#### Terraform outputs
+
+Setters may be set to the outputs of a terraform configuration with
+` + "`" + `--from-terraform` + "`" + `, reading a file written by ` + "`" + `terraform output -json` + "`" + `, or a
+terraform state file.  Each setter is set to the output of the same name, with
+'-' read as '_', e.g. vpc-id to vpc_id.  Setters may be mapped to other outputs
+with ` + "`" + `--terraform-output SETTER=OUTPUT` + "`" + `.  Sensitive outputs are only used for
+mapped setters.
+
+#### Values files
+
+Many setters may be set at once from a values file with ` + "`" + `--from-values` + "`" + `,
+mapping the names of setters to their values, or lists of values for list
+setters, e.g. ` + "`" + `replicas: 3` + "`" + `.  Every value is validated against its setter's
+definition before any is set, and the Resources are updated in a single pass.
+
+The setters of a group, e.g. ` + "`" + `frontend.replicas` + "`" + ` in the ` + "`" + `frontend` + "`" + ` group, may
+be nested in a map of the group, e.g. ` + "`" + `frontend: {replicas: 3}` + "`" + `.  With
+` + "`" + `--group frontend` + "`" + `, the names in the values file are relative to the group,
+e.g. ` + "`" + `replicas: 3` + "`" + ` sets ` + "`" + `frontend.replicas` + "`" + `.
+
+#### Dry runs
+
+With ` + "`" + `--dry-run` + "`" + `, ` + "`" + `set` + "`" + ` prints the fields which would change, with the files
+and Resources they're in, and their old and new values, but sets nothing --
+neither the setter's definition nor the fields.
+
+#### Subpackages
+
+With ` + "`" + `--recurse-subpackages` + "`" + ` (` + "`" + `-R` + "`" + `), the setter is also set in each subpackage
+-- a directory with its own Krmfile -- which defines a setter of the same name.
+Each package is set from its own Krmfile, and the number of fields set is
+printed for each package.
+
+#### List setters
+
+Setters whose definition has ` + "`" + `type: array` + "`" + ` are set to all the values given,
+e.g. ` + "`" + `kustomize cfg set DIR/ cidrs 10.0.0.0/8 192.168.0.0/16` + "`" + `, and replace
+the whole sequence of each field referencing them.  The elements are formatted
+for the type of the definition's ` + "`" + `items` + "`" + `.  A flow style sequence with the
+setter comment on the same line is kept in flow style.
+
+#### Required setters
+
+Setters marked ` + "`" + `required: true` + "`" + ` in the Krmfile must be set before the package
+is rendered -- ` + "`" + `kustomize build` + "`" + ` fails on a kustomization whose Krmfile has
+required setters which aren't set.  ` + "`" + `set` + "`" + ` prints those still to be set.
+
+#### Deprecated setters
+
+Setters marked ` + "`" + `deprecated: true` + "`" + ` in the Krmfile are still set, but ` + "`" + `set` + "`" + ` warns
+about them, naming the setter in their ` + "`" + `replacedBy` + "`" + ` if any, so packages may
+rename setters without breaking those who set them.  ` + "`" + `list-setters` + "`" + ` shows
+which setters are deprecated.
+
+#### Locked setters
+
+Setters marked ` + "`" + `locked: true` + "`" + ` in the Krmfile can't be changed by ` + "`" + `set` + "`" + `, unless
+it's given ` + "`" + `--force` + "`" + `, so packages may pin values such as registries.  Setting
+a locked setter to its current value isn't a change, and succeeds.
+
 #### Tips
 
 - A description of the value may be specified with ` + "`" + `--description` + "`" + `.
@@ -374,7 +903,34 @@ var SetExamples = `
     ...
     metadata:
         name: test-app2 # {"description":"test environment","type":"string","x-kustomize":{"setBy":"dev","setter":[{"name":"name-prefix","value":"test"}]}}
-    ...`
+    ...
+
+  Set setters to terraform outputs:
+
+    $ terraform output -json > outputs.json
+    $ kustomize cfg set DIR/ --from-terraform outputs.json --terraform-output db-host=database_endpoint
+    set 3 fields
+
+  Set setters from a values file:
+
+    $ cat values.yaml
+    replicas: 3
+    image-tag: 1.8.1
+    $ kustomize cfg set DIR/ --from-values values.yaml
+    set 2 fields
+
+  Preview setting a setter:
+
+    $ kustomize cfg set DIR/ replicas 5 --dry-run
+          FILE            RESOURCE          FIELD       OLD VALUE   NEW VALUE
+    deployment.yaml   Deployment/nginx   spec.replicas   3           5
+    would set 1 fields
+
+  Set a setter in a package and its subpackages:
+
+    $ kustomize cfg set DIR/ replicas 5 -R
+    set 2 fields in .
+    set 1 fields in app`
 
 var SinkShort = `[Alpha] Implement a Sink by writing input to a local directory.`
 var SinkLong = `
@@ -394,19 +950,61 @@ var SourceShort = `[Alpha] Implement a Source by reading a local directory.`
 var SourceLong = `
 [Alpha] Implement a Source by reading a local directory.
 
-    kustomize fn source DIR...
+    kustomize fn source [DIR|URL]...
 
   DIR:
     One or more paths to local directories.  Contents from directories will be concatenated.
     If no directories are provided, source will read from stdin as if it were a single file.
 
+  URL:
+    One or more https URLs of YAML documents, e.g. manifests published with a release.
+    Their Resources are annotated with the host and path of the URL as their path.
+    Use --header to authenticate, e.g. 'Authorization=Bearer ${TOKEN}', with
+    --header-url-prefix, e.g. https://example.com/releases/, as headers are only sent
+    to the URLs, and the https URLs they redirect to, starting with one of the prefixes.
+    Use --checksum URL=SHA256 to verify the documents, which --cache-dir then keeps.
+
 ` + "`" + `source` + "`" + ` emits configuration to act as input to a function
 `
 var SourceExamples = `
     # emity configuration directory as input source to a function
     kustomize fn source DIR/
 
-    kustomize fn source DIR/ | your-function | kustomize fn sink DIR/`
+    kustomize fn source DIR/ | your-function | kustomize fn sink DIR/
+
+    # emit the manifests published at a URL, verified by their checksum
+    kustomize fn source https://example.com/release/app.yaml \
+      --checksum https://example.com/release/app.yaml=SHA256`
+
+var SuggestSettersShort = `[Alpha] Suggest setters for the commonly parameterized fields of Resources.`
+var SuggestSettersLong = `
+Suggest setters for the fields of Resources which are commonly parameterized:
+namespaces, replicas, the tags of images, and the resource limits of containers.
+With ` + "`" + `--create` + "`" + `, the setters are created in bulk, rather than by one ` + "`" + `create-setter` + "`" + `
+at a time.
+
+  DIR
+
+    A directory containing Resource configuration, and a Krmfile.
+
+Fields with the same value share a setter, e.g. the namespace of all the Resources
+in it.  The tags of images are set through a substitution of the image, so the rest
+of the image is kept.  Fields which already reference a setter or substitution, and
+the names of the setters already defined, are skipped.
+`
+var SuggestSettersExamples = `
+  Show the setters suggested:
+
+    $ kustomize cfg suggest-setters DIR/
+        NAME        VALUE   SUBSTITUTION                      FIELDS
+    namespace       prod                   Deployment/web metadata.namespace
+                                           Service/web metadata.namespace
+    web-replicas    3                      Deployment/web spec.replicas
+    nginx-tag       1.7.9   nginx-image    Deployment/web spec.template.spec.containers[name=nginx].image
+
+  Create the setters:
+
+    $ kustomize cfg suggest-setters DIR/ --create`
 
 var TreeShort = `[Alpha] Display Resource structure from a directory or stdin.`
 var TreeLong = `
@@ -10,6 +10,7 @@ import (
 
 	"sigs.k8s.io/kustomize/cmd/config/configcobra"
 	"sigs.k8s.io/kustomize/kyaml/commandutil"
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 func main() {
@@ -22,6 +23,13 @@ func main() {
 Provides commands for working with Kubernetes resource configuration.
 `,
 	}, "")
+
+	logFlags := &log.Flags{}
+	logFlags.AddFlags(cmd.PersistentFlags())
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		return logFlags.Configure(os.Stderr)
+	}
+
 	if err := cmd.Execute(); err != nil {
 		os.Exit(1)
 	}
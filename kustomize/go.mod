@@ -9,11 +9,14 @@ require (
 	k8s.io/client-go v0.17.3
 	sigs.k8s.io/kustomize/api v0.5.1
 	sigs.k8s.io/kustomize/cmd/config v0.2.0
+	sigs.k8s.io/kustomize/kyaml v0.4.2
 	sigs.k8s.io/yaml v1.2.0
 )
 
 replace sigs.k8s.io/kustomize/api v0.5.1 => ../api
 
+replace sigs.k8s.io/kustomize/kyaml => ../kyaml
+
 exclude (
 	github.com/russross/blackfriday v2.0.0+incompatible
 	sigs.k8s.io/kustomize/api v0.2.0
@@ -4,8 +4,11 @@
 package build
 
 import (
+	"crypto/sha256"
 	"io"
+	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -17,6 +20,8 @@ import (
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/krmfile"
+	"sigs.k8s.io/kustomize/kyaml/setters2"
 	"sigs.k8s.io/yaml"
 )
 
@@ -26,6 +31,11 @@ type Options struct {
 	outputPath        string
 	outOrder          reorderOutput
 	fnOptions         types.FnPluginLoadingOptions
+	provenance        *krusty.BuildProvenance
+	sops              types.SopsDecryption
+	applyYaml         *krusty.ApplyYamlOptions
+	attestation       *krusty.BuildAttestation
+	in                io.Reader
 }
 
 // NewOptions creates a Options object
@@ -51,6 +61,20 @@ with a kustomization.yaml file, e.g.
 
 The URL should be formulated as described at
 https://github.com/hashicorp/go-getter#url-format
+
+To kustomize the manifests of a Helm chart as they're
+installed, with the kustomization in 'someDir', run
+
+  helm install myRelease myChart --post-renderer kustomize \
+    --post-renderer-args build \
+    --post-renderer-args --helm-post-renderer \
+    --post-renderer-args someDir
+
+To write the resources as apply configurations for
+server-side apply, e.g. as a v1 List, run
+
+  kustomize build someDir --output-format list \
+    --field-manager my-deployer
 `
 
 // NewCmdBuild creates a new build command.
@@ -67,6 +91,7 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			o.in = cmd.InOrStdin()
 			return o.RunBuild(out)
 		},
 	}
@@ -81,6 +106,12 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 	cmd.Flags().BoolVar(
 		&o.fnOptions.EnableStar, "enable-star", false,
 		"enable support for starlark functions. (Alpha)")
+	cmd.Flags().BoolVar(
+		&o.fnOptions.EnableRego, "enable-rego", false,
+		"enable support for functions evaluating rego policies with the opa program. (Alpha)")
+	cmd.Flags().BoolVar(
+		&o.fnOptions.EnableYtt, "enable-ytt", false,
+		"enable support for running ytt overlays with the YttOverlayTransformer. (Alpha)")
 	cmd.Flags().BoolVar(
 		&o.fnOptions.Network, "network", false,
 		"enable network access for functions that declare it")
@@ -92,9 +123,28 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 		"a list of storage options read from the filesystem")
 
 	addFlagLoadRestrictor(cmd.Flags())
+	addFlagStrict(cmd.Flags())
+	addFlagPatchesMustMatch(cmd.Flags())
+	addFlagStrictVars(cmd.Flags())
 	addFlagEnablePlugins(cmd.Flags())
 	addFlagReorderOutput(cmd.Flags())
 	addFlagEnableManagedbyLabel(cmd.Flags())
+	addFlagParallelism(cmd.Flags())
+	addFlagGitCacheDir(cmd.Flags())
+	addFlagHostingAPI(cmd.Flags())
+	addFlagProvenance(cmd.Flags())
+	addFlagAttestation(cmd.Flags())
+	addFlagSops(cmd.Flags())
+	addFlagKms(cmd.Flags())
+	addFlagSchemaValidation(cmd.Flags())
+	addFlagDanglingReferences(cmd.Flags())
+	addFlagNamespaceCheck(cmd.Flags())
+	addFlagVerifyDeterminism(cmd.Flags())
+	addFlagHelmPostRenderer(cmd.Flags())
+	addFlagClusterResources(cmd.Flags())
+	addFlagServerDryRun(cmd.Flags())
+	addFlagOutputFormat(cmd.Flags())
+	addFlagProfile(cmd.Flags())
 
 	return cmd
 }
@@ -116,13 +166,46 @@ func (o *Options) Validate(args []string) (err error) {
 		return err
 	}
 	o.outOrder, err = validateFlagReorderOutput()
+	if err != nil {
+		return err
+	}
+	o.provenance, err = getFlagProvenanceValue()
+	if err != nil {
+		return err
+	}
+	o.sops, err = getFlagSopsValue()
+	if err != nil {
+		return err
+	}
+	o.applyYaml, err = getFlagOutputFormatValue()
 	return
 }
 
 func (o *Options) makeOptions() *krusty.Options {
+	o.attestation = getFlagAttestationValue()
 	opts := &krusty.Options{
 		DoLegacyResourceSort: o.outOrder == legacy,
+		ResourceOrder:        getFlagResourceOrderValue(o.outOrder),
 		LoadRestrictions:     getFlagLoadRestrictorValue(),
+		Parallelism:          flagParallelismValue,
+		CloneParallelism:     flagCloneParallelismValue,
+		ParallelTransformers: flagParallelTransformersValue,
+		GitCacheDir:          flagGitCacheDirValue,
+		HostingAPI:           getFlagHostingAPIValue(),
+		BuildProvenance:      o.provenance,
+		BuildAttestation:     o.attestation,
+		SopsDecryption:       o.sops,
+		KmsDecryption:        flagEnableKmsValue,
+		Strict:               flagStrictValue,
+		PatchesMustMatch:     flagPatchesMustMatchValue,
+		StrictVars:           flagStrictVarsValue,
+		SchemaValidation:     getFlagSchemaValidationValue(),
+		DanglingReferences:   getFlagDanglingReferencesValue(),
+		NamespaceCheck:       getFlagNamespaceCheckValue(),
+		VerifyDeterminism:    flagVerifyDeterminismValue,
+		VerifyRoundTrip:      flagVerifyRoundTripValue,
+		ClusterResources:     getFlagClusterResourcesValue(),
+		ServerDryRun:         getFlagServerDryRunValue(),
 	}
 	if isFlagEnablePluginsSet() {
 		c, err := konfig.EnabledPluginConfig(types.BploUseStaticallyLinked)
@@ -136,6 +219,9 @@ func (o *Options) makeOptions() *krusty.Options {
 	} else {
 		opts.PluginConfig = konfig.DisabledPluginConfig()
 	}
+	// The YttOverlayTransformer is builtin, so
+	// it's enabled whether plugins are or not.
+	opts.PluginConfig.FnpLoadingOptions.EnableYtt = o.fnOptions.EnableYtt
 	if isManagedbyLabelEnabled() {
 		opts.AddManagedbyLabel = true
 	}
@@ -144,30 +230,245 @@ func (o *Options) makeOptions() *krusty.Options {
 
 func (o *Options) RunBuild(out io.Writer) error {
 	fSys := filesys.MakeFsOnDisk()
-	k := krusty.MakeKustomizer(fSys, o.makeOptions())
-	m, err := k.Run(o.kustomizationPath)
+	if err := o.checkRequiredSetters(fSys); err != nil {
+		return err
+	}
+	opts := o.makeOptions()
+	if flagProfileValue == "" && o.canStream(fSys, opts) {
+		return o.streamResources(out, fSys, krusty.MakeKustomizer(fSys, opts))
+	}
+	if flagProfileValue == "" {
+		k := krusty.MakeKustomizer(fSys, opts)
+		m, err := o.runKustomizer(k)
+		if err != nil {
+			return err
+		}
+		return o.emitResources(out, fSys, m)
+	}
+	p, err := startProfiling(flagProfileValue)
+	if err != nil {
+		return errors.Wrap(err, "starting profile")
+	}
+	opts.PhaseTimer = p.record
+	k := krusty.MakeKustomizer(fSys, opts)
+	m, err := o.runKustomizer(k)
+	if err == nil {
+		err = p.timePhase("output", func() error {
+			return o.emitResources(out, fSys, m)
+		})
+	}
+	if pErr := p.stop(); pErr != nil && err == nil {
+		err = errors.Wrap(pErr, "writing profile")
+	}
+	return err
+}
+
+// checkRequiredSetters fails if the Krmfile next to a local
+// kustomization, or next to any of the local kustomizations it
+// builds on, has required setters which aren't set, so their
+// placeholder values aren't rendered.  Remote bases aren't checked.
+func (o *Options) checkRequiredSetters(fSys filesys.FileSystem) error {
+	return checkRequiredSettersIn(fSys, o.kustomizationPath, true, map[string]bool{})
+}
+
+// checkRequiredSettersIn checks the Krmfile in dir, if it's a
+// directory, and then the directories its kustomization lists
+// as resources, bases or components.  Errors for other
+// directories than the one built name the directory.
+func checkRequiredSettersIn(
+	fSys filesys.FileSystem, dir string, built bool, visited map[string]bool) error {
+	dir = filepath.Clean(dir)
+	if visited[dir] || !fSys.IsDir(dir) {
+		return nil
+	}
+	visited[dir] = true
+	_, err := setters2.CheckRequiredSetters{
+		OpenAPIPath: filepath.Join(dir, krmfile.KrmfileName),
+	}.Filter(nil)
+	if err != nil {
+		if built {
+			return err
+		}
+		return errors.Wrapf(err, "kustomization %s", dir)
+	}
+	k, err := readKustomization(fSys, dir)
+	if err != nil || k == nil {
+		return err
+	}
+	var paths []string
+	paths = append(paths, k.Resources...)
+	paths = append(paths, k.Bases...)
+	paths = append(paths, k.Components...)
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		if err := checkRequiredSettersIn(fSys, p, false, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readKustomization reads the kustomization file in dir,
+// or returns nil if there isn't one.
+func readKustomization(fSys filesys.FileSystem, dir string) (*types.Kustomization, error) {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		path := filepath.Join(dir, name)
+		if !fSys.Exists(path) {
+			continue
+		}
+		b, err := fSys.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		k := &types.Kustomization{}
+		if err := yaml.Unmarshal(b, k); err != nil {
+			return nil, errors.Wrapf(err, "reading %s", path)
+		}
+		return k, nil
+	}
+	return nil, nil
+}
+
+// runKustomizer runs k on the kustomization, adding the
+// resources read from stdin as a Helm post-renderer, if asked.
+func (o *Options) runKustomizer(k *krusty.Kustomizer) (resmap.ResMap, error) {
+	if !flagHelmPostRendererValue {
+		return k.Run(o.kustomizationPath)
+	}
+	input, err := ioutil.ReadAll(o.in)
 	if err != nil {
+		return nil, errors.Wrap(err, "reading stdin")
+	}
+	return k.RunWithInput(o.kustomizationPath, input)
+}
+
+// canStream is true if the resources can be written as soon as
+// they're final, which needs them written in the order of the
+// kustomization, as YAML documents, to a file or stdout, and no
+// step of the build needing its whole output.  Builds which are
+// profiled aren't streamed, so that the output is timed apart.
+func (o *Options) canStream(fSys filesys.FileSystem, opts *krusty.Options) bool {
+	return o.outOrder == none && o.applyYaml == nil &&
+		!(o.outputPath != "" && fSys.IsDir(o.outputPath)) &&
+		!opts.VerifyDeterminism && opts.ServerDryRun == nil
+}
+
+// streamResources runs k on the kustomization, like
+// runKustomizer, writing each resource as soon as it's final.
+func (o *Options) streamResources(
+	out io.Writer, fSys filesys.FileSystem, k *krusty.Kustomizer) error {
+	return o.emit(out, fSys, func(w io.Writer) error {
+		emit := yamlEmitter(w)
+		if !flagHelmPostRendererValue {
+			return k.RunStreaming(o.kustomizationPath, emit)
+		}
+		input, err := ioutil.ReadAll(o.in)
+		if err != nil {
+			return errors.Wrap(err, "reading stdin")
+		}
+		return k.RunStreamingWithInput(o.kustomizationPath, input, emit)
+	})
+}
+
+// yamlEmitter returns a function writing each resource
+// it's given to w, as a stream of YAML documents.
+func yamlEmitter(w io.Writer) func(*resource.Resource) error {
+	first := true
+	return func(res *resource.Resource) error {
+		out, err := yaml.Marshal(res.ReadOnlyMap())
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err = io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = w.Write(out)
 		return err
 	}
-	return o.emitResources(out, fSys, m)
 }
 
 func (o *Options) emitResources(
 	out io.Writer, fSys filesys.FileSystem, m resmap.ResMap) error {
 	if o.outputPath != "" && fSys.IsDir(o.outputPath) {
+		if o.applyYaml != nil {
+			return errors.Errorf(
+				"--%s %s can't be written to a directory",
+				flagOutputFormatName, flagOutputFormatValue)
+		}
+		if o.attestation != nil {
+			return errors.Errorf(
+				"--%s needs the output written to a file or stdout",
+				flagAttestationName)
+		}
 		return writeIndividualFiles(fSys, o.outputPath, m)
 	}
-	res, err := m.AsYaml()
+	return o.emit(out, fSys, func(w io.Writer) error {
+		return o.writeYaml(w, m)
+	})
+}
+
+// emit writes the output with write, to the output
+// file, if any, or to out, attesting to it if asked.
+func (o *Options) emit(
+	out io.Writer, fSys filesys.FileSystem, write func(io.Writer) error) error {
+	if o.attestation == nil {
+		return o.writeOutput(out, write)
+	}
+	h := sha256.New()
+	err := o.writeOutput(out, func(w io.Writer) error {
+		return write(io.MultiWriter(w, h))
+	})
+	if err != nil {
+		return err
+	}
+	name := o.outputPath
+	if name == "" {
+		name = "stdout"
+	}
+	return writeAttestation(fSys, o.attestation, name, h)
+}
+
+// writeOutput gives write the output file, if any, or out.
+// The output file is written as a temporary file next to it,
+// which is only renamed to it once it's complete, so a failed
+// build leaves any earlier output as it was, and the output is
+// never seen half written.
+func (o *Options) writeOutput(out io.Writer, write func(io.Writer) error) error {
+	if o.outputPath == "" {
+		return write(out)
+	}
+	f, err := ioutil.TempFile(
+		filepath.Dir(o.outputPath), "."+filepath.Base(o.outputPath)+".tmp-")
 	if err != nil {
 		return err
 	}
-	if o.outputPath != "" {
-		return fSys.WriteFile(o.outputPath, res)
+	if err = f.Chmod(0644); err == nil {
+		err = write(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), o.outputPath)
+	}
+	if err != nil {
+		os.Remove(f.Name())
 	}
-	_, err = out.Write(res)
 	return err
 }
 
+func (o *Options) writeYaml(w io.Writer, m resmap.ResMap) error {
+	if o.applyYaml != nil {
+		return krusty.WriteApplyYaml(w, m, *o.applyYaml)
+	}
+	return m.WriteYaml(w)
+}
+
 func writeIndividualFiles(
 	fSys filesys.FileSystem, folderPath string, m resmap.ResMap) error {
 	byNamespace := m.GroupedByCurrentNamespace()
@@ -199,7 +500,7 @@ func fileName(res *resource.Resource) string {
 
 func writeFile(
 	fSys filesys.FileSystem, path, fName string, res *resource.Resource) error {
-	out, err := yaml.Marshal(res.Map())
+	out, err := yaml.Marshal(res.ReadOnlyMap())
 	if err != nil {
 		return err
 	}
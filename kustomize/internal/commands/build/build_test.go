@@ -4,6 +4,11 @@
 package build
 
 import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -52,3 +57,96 @@ func TestBuildValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-build-output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	o := NewOptions(".", filepath.Join(dir, "out.yaml"))
+	err = o.writeOutput(nil, func(w io.Writer) error {
+		_, err := io.WriteString(w, "first")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = o.writeOutput(nil, func(w io.Writer) error {
+		io.WriteString(w, "second")
+		return errors.New("failed")
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Fatalf("expected failure, got %v", err)
+	}
+	b, err := ioutil.ReadFile(o.outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "first" {
+		t.Fatalf("expected earlier output to be kept, got %q", b)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected the temporary file to be removed, got %d files", len(files))
+	}
+}
+
+func TestCheckRequiredSetters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-build-setters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"overlay/kustomization.yaml": `resources:
+- ../base
+- https://example.com/remote
+`,
+		"base/kustomization.yaml": `resources:
+- deployment.yaml
+`,
+		"base/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`,
+		"base/Krmfile": `apiVersion: config.k8s.io/v1alpha1
+kind: Krmfile
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: PLACEHOLDER
+          required: true
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fSys := filesys.MakeFsOnDisk()
+
+	err = NewOptions(filepath.Join(dir, "base"), "").checkRequiredSetters(fSys)
+	expected := "setter image is required but not set, " +
+		"please set it to new value and try again"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+
+	err = NewOptions(filepath.Join(dir, "overlay"), "").checkRequiredSetters(fSys)
+	expected = "kustomization " + filepath.Join(dir, "base") + ": " + expected
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagAttestationName = "attestation"
+	flagAttestationHelp = "file to write an in-toto statement of the SLSA " +
+		"provenance of the output to, listing the files, remote bases " +
+		"and plugins it was built from, with their digests"
+)
+
+var flagAttestationValue = ""
+
+func addFlagAttestation(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagAttestationValue, flagAttestationName,
+		"", flagAttestationHelp)
+}
+
+// getFlagAttestationValue returns a BuildAttestation if
+// one is to be written, or nil.
+func getFlagAttestationValue() *krusty.BuildAttestation {
+	if flagAttestationValue == "" {
+		return nil
+	}
+	return krusty.NewBuildAttestation()
+}
+
+// writeAttestation writes the statement that the output
+// named name, hashed by h, was built as recorded by a, to
+// the file named by the flag.
+func writeAttestation(
+	fSys filesys.FileSystem, a *krusty.BuildAttestation, name string, h hash.Hash) error {
+	statement, err := a.Statement(krusty.AttestationMaterial{
+		Name:   name,
+		Digest: map[string]string{"sha256": fmt.Sprintf("%x", h.Sum(nil))},
+	})
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(flagAttestationValue, statement)
+}
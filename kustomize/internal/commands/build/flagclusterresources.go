@@ -0,0 +1,49 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagEnableClusterResourcesName = "enable-cluster-resources"
+	flagEnableClusterResourcesHelp = "read the cluster:// resources of " +
+		"kustomizations from the live cluster, using the kubectl program"
+	flagKubeconfigName = "kubeconfig"
+	flagKubeconfigHelp = "with --" + flagEnableClusterResourcesName +
+		" or --" + flagServerDryRunName + ", the kubeconfig file to use"
+	flagKubeContextName = "kube-context"
+	flagKubeContextHelp = "with --" + flagEnableClusterResourcesName +
+		" or --" + flagServerDryRunName + ", the kubeconfig context to use"
+)
+
+var (
+	flagEnableClusterResourcesValue = false
+	flagKubeconfigValue             = ""
+	flagKubeContextValue            = ""
+)
+
+func addFlagClusterResources(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagEnableClusterResourcesValue, flagEnableClusterResourcesName,
+		false, flagEnableClusterResourcesHelp)
+	set.StringVar(
+		&flagKubeconfigValue, flagKubeconfigName,
+		"", flagKubeconfigHelp)
+	set.StringVar(
+		&flagKubeContextValue, flagKubeContextName,
+		"", flagKubeContextHelp)
+}
+
+func getFlagClusterResourcesValue() *krusty.ClusterResources {
+	if !flagEnableClusterResourcesValue {
+		return nil
+	}
+	return &krusty.ClusterResources{
+		Kubeconfig: flagKubeconfigValue,
+		Context:    flagKubeContextValue,
+	}
+}
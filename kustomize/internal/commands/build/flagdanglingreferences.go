@@ -0,0 +1,53 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagCheckReferencesName = "check-references"
+	flagCheckReferencesHelp = "warn about references to ConfigMaps, Secrets " +
+		"and Services which aren't in the output"
+	flagExternalNameName = "external-name"
+	flagExternalNameHelp = "the name of an object made outside of the build, " +
+		"which may be referred to, as kind/name or name; may be repeated"
+	flagFailOnDanglingName = "fail-on-dangling-references"
+	flagFailOnDanglingHelp = "fail the build if a reference doesn't name " +
+		"an object of the output, rather than only warning"
+)
+
+var (
+	flagCheckReferencesValue = false
+	flagExternalNameValue    = []string{}
+	flagFailOnDanglingValue  = false
+)
+
+func addFlagDanglingReferences(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagCheckReferencesValue, flagCheckReferencesName,
+		false, flagCheckReferencesHelp)
+	set.StringArrayVar(
+		&flagExternalNameValue, flagExternalNameName,
+		[]string{}, flagExternalNameHelp)
+	set.BoolVar(
+		&flagFailOnDanglingValue, flagFailOnDanglingName,
+		false, flagFailOnDanglingHelp)
+}
+
+// getFlagDanglingReferencesValue returns the DanglingReferences
+// check configured by the flags, or nil if it's disabled.
+// Failing on dangling references implies checking them.
+func getFlagDanglingReferencesValue() *krusty.DanglingReferences {
+	if !flagCheckReferencesValue && !flagFailOnDanglingValue {
+		return nil
+	}
+	return &krusty.DanglingReferences{
+		ExternalNames: flagExternalNameValue,
+		FailOnError:   flagFailOnDanglingValue,
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagGitCacheDirName = "git-cache-dir"
+	flagGitCacheDirHelp = "directory to keep clones of remote bases in, " +
+		"to be reused by later builds; by default they're removed"
+)
+
+var flagGitCacheDirValue = ""
+
+func addFlagGitCacheDir(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagGitCacheDirValue, flagGitCacheDirName,
+		"", flagGitCacheDirHelp)
+}
@@ -0,0 +1,25 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagHelmPostRendererName = "helm-post-renderer"
+	flagHelmPostRendererHelp = "read the manifests rendered by a Helm chart " +
+		"from stdin, and kustomize them as resources of the kustomization, " +
+		"for use as a helm --post-renderer"
+)
+
+var (
+	flagHelmPostRendererValue = false
+)
+
+func addFlagHelmPostRenderer(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagHelmPostRendererValue, flagHelmPostRendererName,
+		false, flagHelmPostRendererHelp)
+}
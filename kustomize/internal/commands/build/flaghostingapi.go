@@ -0,0 +1,55 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagHostingAPIName = "fetch-with-hosting-api"
+	flagHostingAPIHelp = "download remote bases on github.com and gitlab.com " +
+		"through the APIs of the hosts instead of cloning them with git; " +
+		"tokens are read from GITHUB_TOKEN and GITLAB_TOKEN"
+	flagHostingAPIProxyName = "hosting-api-proxy"
+	flagHostingAPIProxyHelp = "with --" + flagHostingAPIName +
+		", the URL of the proxy to use; by default it's read from HTTPS_PROXY"
+	flagHostingAPIRetriesName = "hosting-api-retries"
+	flagHostingAPIRetriesHelp = "with --" + flagHostingAPIName +
+		", how many times failed or rate limited requests are retried"
+)
+
+var (
+	flagHostingAPIValue        = false
+	flagHostingAPIProxyValue   = ""
+	flagHostingAPIRetriesValue = 3
+)
+
+func addFlagHostingAPI(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagHostingAPIValue, flagHostingAPIName,
+		false, flagHostingAPIHelp)
+	set.StringVar(
+		&flagHostingAPIProxyValue, flagHostingAPIProxyName,
+		"", flagHostingAPIProxyHelp)
+	set.IntVar(
+		&flagHostingAPIRetriesValue, flagHostingAPIRetriesName,
+		3, flagHostingAPIRetriesHelp)
+}
+
+func getFlagHostingAPIValue() *krusty.HostingAPI {
+	if !flagHostingAPIValue {
+		return nil
+	}
+	retries := flagHostingAPIRetriesValue
+	if retries == 0 {
+		// krusty takes zero to mean the default.
+		retries = -1
+	}
+	return &krusty.HostingAPI{
+		Proxy:   flagHostingAPIProxyValue,
+		Retries: retries,
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEnableKmsName = "enable-kms"
+	flagEnableKmsHelp = "decrypt the files read by secretGenerators which are " +
+		"KMS envelopes, using the gcloud, aws or az program"
+)
+
+var flagEnableKmsValue = false
+
+func addFlagKms(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagEnableKmsValue, flagEnableKmsName,
+		false, flagEnableKmsHelp)
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagCheckNamespacesName = "check-namespaces"
+	flagCheckNamespacesHelp = "warn about namespaced resources without a namespace, " +
+		"and resources whose namespace a kustomization's namespace field replaces"
+	flagFailOnNamespacesName = "fail-on-namespace-problems"
+	flagFailOnNamespacesHelp = "fail the build if a resource has a namespace " +
+		"problem, rather than only warning"
+)
+
+var (
+	flagCheckNamespacesValue  = false
+	flagFailOnNamespacesValue = false
+)
+
+func addFlagNamespaceCheck(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagCheckNamespacesValue, flagCheckNamespacesName,
+		false, flagCheckNamespacesHelp)
+	set.BoolVar(
+		&flagFailOnNamespacesValue, flagFailOnNamespacesName,
+		false, flagFailOnNamespacesHelp)
+}
+
+// getFlagNamespaceCheckValue returns the NamespaceCheck
+// configured by the flags, or nil if it's disabled.
+// Failing on namespace problems implies checking them.
+func getFlagNamespaceCheckValue() *krusty.NamespaceCheck {
+	if !flagCheckNamespacesValue && !flagFailOnNamespacesValue {
+		return nil
+	}
+	return &krusty.NamespaceCheck{
+		FailOnError: flagFailOnNamespacesValue,
+	}
+}
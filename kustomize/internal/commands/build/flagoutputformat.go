@@ -0,0 +1,63 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagOutputFormatName = "output-format"
+	flagOutputFormatHelp = "the format of the output: 'yaml' for the " +
+		"resources as they're built, 'apply' for them as apply " +
+		"configurations for server-side apply, or 'list' for those " +
+		"as the items of a v1 List"
+	flagFieldManagerName = "field-manager"
+	flagFieldManagerHelp = "with --" + flagOutputFormatName + " apply or " +
+		"list, record this field manager in an annotation of each " +
+		"resource, as a hint to appliers; with --" + flagServerDryRunName +
+		", the field manager of the dry-run"
+)
+
+var (
+	flagOutputFormatValue = "yaml"
+	flagFieldManagerValue = ""
+)
+
+func addFlagOutputFormat(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagOutputFormatValue, flagOutputFormatName,
+		"yaml", flagOutputFormatHelp)
+	set.StringVar(
+		&flagFieldManagerValue, flagFieldManagerName,
+		"", flagFieldManagerHelp)
+}
+
+// getFlagOutputFormatValue returns the options to write
+// apply configurations with, or nil to write plain yaml.
+func getFlagOutputFormatValue() (*krusty.ApplyYamlOptions, error) {
+	switch flagOutputFormatValue {
+	case "yaml":
+		if flagFieldManagerValue != "" && !flagServerDryRunValue {
+			return nil, fmt.Errorf(
+				"--%s needs --%s apply or list, or --%s",
+				flagFieldManagerName, flagOutputFormatName, flagServerDryRunName)
+		}
+		return nil, nil
+	case "apply":
+		return &krusty.ApplyYamlOptions{
+			FieldManager: flagFieldManagerValue}, nil
+	case "list":
+		return &krusty.ApplyYamlOptions{
+			AsList: true, FieldManager: flagFieldManagerValue}, nil
+	default:
+		return nil, fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagOutputFormatName, flagOutputFormatValue,
+			[]string{"yaml", "apply", "list"})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"runtime"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagParallelismName = "parallelism"
+	flagParallelismHelp = "maximum number of resources and bases loaded " +
+		"concurrently by each kustomization; 1 loads them sequentially"
+	flagCloneParallelismName = "clone-parallelism"
+	flagCloneParallelismHelp = "maximum number of remote git repositories " +
+		"cloned at the same time; 0 means no limit"
+	flagParallelTransformersName = "parallel-transformers"
+	flagParallelTransformersHelp = "apply the label, annotation and image " +
+		"transformers to shards of the resources concurrently"
+)
+
+var (
+	flagParallelismValue          = runtime.NumCPU()
+	flagCloneParallelismValue     = 0
+	flagParallelTransformersValue = false
+)
+
+func addFlagParallelism(set *pflag.FlagSet) {
+	set.IntVar(
+		&flagParallelismValue, flagParallelismName,
+		runtime.NumCPU(), flagParallelismHelp)
+	set.IntVar(
+		&flagCloneParallelismValue, flagCloneParallelismName,
+		0, flagCloneParallelismHelp)
+	set.BoolVar(
+		&flagParallelTransformersValue, flagParallelTransformersName,
+		false, flagParallelTransformersHelp)
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagPatchesMustMatchName = "patches-must-match"
+	flagPatchesMustMatchHelp = "fail if the target of a patch selects no " +
+		"resources, unless the patch sets allowNoMatch"
+)
+
+var flagPatchesMustMatchValue = false
+
+func addFlagPatchesMustMatch(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagPatchesMustMatchValue, flagPatchesMustMatchName,
+		false, flagPatchesMustMatchHelp)
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagProfileName = "profile"
+	flagProfileHelp = "if set, write a CPU profile (cpu.pprof), a heap " +
+		"profile (heap.pprof) and a per-phase timing breakdown " +
+		"(timings.txt) of the build to this directory"
+)
+
+var flagProfileValue = ""
+
+func addFlagProfile(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagProfileValue, flagProfileName,
+		"", flagProfileHelp)
+}
+
+// phaseTiming is the time taken by one phase of a build.
+type phaseTiming struct {
+	phase   string
+	elapsed time.Duration
+}
+
+// profiler records the profiles and phase timings of a build.
+type profiler struct {
+	dir     string
+	cpuFile *os.File
+	mu      sync.Mutex
+	timings []phaseTiming
+}
+
+// startProfiling starts a CPU profile written to dir.
+func startProfiling(dir string) (*profiler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &profiler{dir: dir, cpuFile: f}, nil
+}
+
+// record records the time taken by a phase.  It's
+// used as the krusty.Options PhaseTimer.
+func (p *profiler) record(phase string, elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.timings = append(p.timings, phaseTiming{phase: phase, elapsed: elapsed})
+}
+
+// timePhase calls fn and records how long it took.
+func (p *profiler) timePhase(phase string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	p.record(phase, time.Since(start))
+	return err
+}
+
+// stop stops the CPU profile, and writes the
+// heap profile and the phase timings.
+func (p *profiler) stop() error {
+	pprof.StopCPUProfile()
+	if err := p.cpuFile.Close(); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(p.dir, "heap.pprof"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return err
+	}
+	t, err := os.Create(filepath.Join(p.dir, "timings.txt"))
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+	return p.writeTimings(t)
+}
+
+// writeTimings writes the phase timings in the order the phases finished.
+func (p *profiler) writeTimings(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.timings {
+		if _, err := fmt.Fprintf(w, "%-12v %s\n", t.elapsed, t.phase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfiler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-profile-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := startProfiling(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.record("load", time.Millisecond)
+	p.record("accumulate", 2*time.Millisecond)
+	if err := p.timePhase("output", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"cpu.pprof", "heap.pprof"} {
+		if _, err := os.Stat(filepath.Join(dir, "out", name)); err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		}
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "out", "timings.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 3 ||
+		strings.Join(strings.Fields(lines[0]), " ") != "1ms load" ||
+		strings.Join(strings.Fields(lines[1]), " ") != "2ms accumulate" ||
+		!strings.HasSuffix(lines[2], " output") {
+		t.Fatalf("unexpected timings:\n%s", b)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagEnableProvenanceName = "enable-provenance"
+	flagEnableProvenanceHelp = "annotate each resource with the git repository, " +
+		"commit and path of the kustomization, and the kustomize version"
+	flagProvenanceKeysName = "provenance-annotations"
+	flagProvenanceKeysHelp = "annotation keys to use instead of the defaults, " +
+		"e.g. repo=example.com/repo,commit=example.com/commit; " +
+		"the names are repo, commit, path and version, and an empty key " +
+		"leaves the annotation out"
+)
+
+var (
+	flagEnableProvenanceValue = false
+	flagProvenanceKeysValue   = map[string]string{}
+)
+
+func addFlagProvenance(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagEnableProvenanceValue, flagEnableProvenanceName,
+		false, flagEnableProvenanceHelp)
+	set.StringToStringVar(
+		&flagProvenanceKeysValue, flagProvenanceKeysName,
+		map[string]string{}, flagProvenanceKeysHelp)
+}
+
+// getFlagProvenanceValue returns the BuildProvenance
+// configured by the flags, or nil if it's disabled.
+func getFlagProvenanceValue() (*krusty.BuildProvenance, error) {
+	if !flagEnableProvenanceValue {
+		return nil, nil
+	}
+	p := krusty.MakeDefaultBuildProvenance()
+	for name, key := range flagProvenanceKeysValue {
+		switch name {
+		case "repo":
+			p.RepoKey = key
+		case "commit":
+			p.CommitKey = key
+		case "path":
+			p.PathKey = key
+		case "version":
+			p.VersionKey = key
+		default:
+			return nil, fmt.Errorf(
+				"unknown provenance annotation %q; expected repo, commit, path or version", name)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagValidateSchemasName = "validate-schemas"
+	flagValidateSchemasHelp = "check the output against the JSON schemas of " +
+		"its kinds, and warn about unknown fields and type errors, at their " +
+		"lines in the files read"
+	flagSchemaLocationName = "schema-location"
+	flagSchemaLocationHelp = "a directory, URL or kubeconform-style template " +
+		"to look for schemas in, e.g. 'schemas/{{ .ResourceKind }}{{ .KindSuffix }}.json'; " +
+		"may be repeated, and 'default' means the schemas of the builtin kinds"
+	flagKubernetesVersionName = "kubernetes-version"
+	flagKubernetesVersionHelp = "the version of the schemas of the builtin kinds, " +
+		"e.g. 1.18.0"
+	flagStrictSchemasName = "strict-schemas"
+	flagStrictSchemasHelp = "report fields the schemas don't declare, " +
+		"even where the schemas allow them"
+	flagIgnoreMissingSchemasName = "ignore-missing-schemas"
+	flagIgnoreMissingSchemasHelp = "don't report resources of kinds without a schema"
+	flagFailOnInvalidName        = "fail-on-invalid"
+	flagFailOnInvalidHelp        = "fail the build if the output doesn't " +
+		"match the schemas, rather than only warning"
+)
+
+var (
+	flagValidateSchemasValue      = false
+	flagSchemaLocationValue       = []string{}
+	flagKubernetesVersionValue    = "master"
+	flagStrictSchemasValue        = false
+	flagIgnoreMissingSchemasValue = false
+	flagFailOnInvalidValue        = false
+)
+
+func addFlagSchemaValidation(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagValidateSchemasValue, flagValidateSchemasName,
+		false, flagValidateSchemasHelp)
+	set.StringArrayVar(
+		&flagSchemaLocationValue, flagSchemaLocationName,
+		[]string{}, flagSchemaLocationHelp)
+	set.StringVar(
+		&flagKubernetesVersionValue, flagKubernetesVersionName,
+		"master", flagKubernetesVersionHelp)
+	set.BoolVar(
+		&flagStrictSchemasValue, flagStrictSchemasName,
+		false, flagStrictSchemasHelp)
+	set.BoolVar(
+		&flagIgnoreMissingSchemasValue, flagIgnoreMissingSchemasName,
+		false, flagIgnoreMissingSchemasHelp)
+	set.BoolVar(
+		&flagFailOnInvalidValue, flagFailOnInvalidName,
+		false, flagFailOnInvalidHelp)
+}
+
+// getFlagSchemaValidationValue returns the SchemaValidation
+// configured by the flags, or nil if it's disabled.
+func getFlagSchemaValidationValue() *krusty.SchemaValidation {
+	if !flagValidateSchemasValue {
+		return nil
+	}
+	v := krusty.MakeDefaultSchemaValidation()
+	if len(flagSchemaLocationValue) > 0 {
+		v.SchemaLocations = flagSchemaLocationValue
+	}
+	v.KubernetesVersion = flagKubernetesVersionValue
+	v.Strict = flagStrictSchemasValue
+	v.IgnoreMissingSchemas = flagIgnoreMissingSchemasValue
+	v.FailOnError = flagFailOnInvalidValue
+	return v
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagServerDryRunName = "server-dry-run"
+	flagServerDryRunHelp = "submit the output to the live cluster in a " +
+		"server-side apply dry-run, using the kubectl program, and warn " +
+		"about the resources it rejects or finds conflicts in"
+	flagFailOnDryRunErrorsName = "fail-on-dry-run-errors"
+	flagFailOnDryRunErrorsHelp = "with --" + flagServerDryRunName +
+		", fail rather than warn if the dry-run finds problems"
+)
+
+var (
+	flagServerDryRunValue       = false
+	flagFailOnDryRunErrorsValue = false
+)
+
+func addFlagServerDryRun(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagServerDryRunValue, flagServerDryRunName,
+		false, flagServerDryRunHelp)
+	set.BoolVar(
+		&flagFailOnDryRunErrorsValue, flagFailOnDryRunErrorsName,
+		false, flagFailOnDryRunErrorsHelp)
+}
+
+func getFlagServerDryRunValue() *krusty.ServerDryRun {
+	if !flagServerDryRunValue {
+		return nil
+	}
+	return &krusty.ServerDryRun{
+		Kubeconfig:   flagKubeconfigValue,
+		Context:      flagKubeContextValue,
+		FieldManager: flagFieldManagerValue,
+		FailOnError:  flagFailOnDryRunErrorsValue,
+	}
+}
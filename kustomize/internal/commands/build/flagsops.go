@@ -0,0 +1,50 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagEnableSopsName = "enable-sops"
+	flagEnableSopsHelp = "decrypt files encrypted with sops as they're read, " +
+		"using the sops program"
+	flagSopsScopeName = "sops-scope"
+	flagSopsScopeHelp = "with --" + flagEnableSopsName + ", which files to " +
+		"decrypt: 'secrets' for the files read by secretGenerators, or 'all'"
+)
+
+var (
+	flagEnableSopsValue = false
+	flagSopsScopeValue  = "secrets"
+)
+
+func addFlagSops(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagEnableSopsValue, flagEnableSopsName,
+		false, flagEnableSopsHelp)
+	set.StringVar(
+		&flagSopsScopeValue, flagSopsScopeName,
+		"secrets", flagSopsScopeHelp)
+}
+
+func getFlagSopsValue() (types.SopsDecryption, error) {
+	if !flagEnableSopsValue {
+		return types.SopsDecryptionNone, nil
+	}
+	switch flagSopsScopeValue {
+	case "secrets":
+		return types.SopsDecryptionSecrets, nil
+	case "all":
+		return types.SopsDecryptionAll, nil
+	default:
+		return types.SopsDecryptionNone, fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagSopsScopeName, flagSopsScopeValue, []string{"secrets", "all"})
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagStrictName = "strict"
+	flagStrictHelp = "fail on deprecated and miscased fields in kustomization " +
+		"files, rather than only warning"
+)
+
+var flagStrictValue = false
+
+func addFlagStrict(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagStrictValue, flagStrictName,
+		false, flagStrictHelp)
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagStrictVarsName = "strict-vars"
+	flagStrictVarsHelp = "fail if a var is never referred to, or if $(NAME) " +
+		"names neither a var nor an env var of its resource"
+)
+
+var flagStrictVarsValue = false
+
+func addFlagStrictVars(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagStrictVarsValue, flagStrictVarsName,
+		false, flagStrictVarsHelp)
+}
@@ -0,0 +1,31 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagVerifyDeterminismName = "verify-determinism"
+	flagVerifyDeterminismHelp = "build twice, and fail if the outputs differ, " +
+		"naming the first generator or transformer whose output differed"
+	flagVerifyRoundTripName = "verify-round-trip"
+	flagVerifyRoundTripHelp = "with --" + flagVerifyDeterminismName + ", also fail " +
+		"if the output changes when written and read again"
+)
+
+var (
+	flagVerifyDeterminismValue = false
+	flagVerifyRoundTripValue   = false
+)
+
+func addFlagVerifyDeterminism(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagVerifyDeterminismValue, flagVerifyDeterminismName,
+		false, flagVerifyDeterminismHelp)
+	set.BoolVar(
+		&flagVerifyRoundTripValue, flagVerifyRoundTripName,
+		false, flagVerifyRoundTripHelp)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 )
 
 //go:generate stringer -type=reorderOutput
@@ -16,23 +17,39 @@ const (
 	unspecified reorderOutput = iota
 	none
 	legacy
+	kind
 )
 
 const (
 	flagReorderOutputName = "reorder"
+	flagFirstKindsName    = "reorder-first-kinds"
+	flagLastKindsName     = "reorder-last-kinds"
 )
 
 var (
 	flagReorderOutputValue = legacy.String()
 	flagReorderOutputHelp  = "Reorder the resources just before output. " +
 		"Use '" + legacy.String() + "' to apply a legacy reordering (Namespaces first, Webhooks last, etc). " +
-		"Use '" + none.String() + "' to suppress a final reordering."
+		"Use '" + kind.String() + "' to order the resources by kind for safe application " +
+		"(Namespaces and CRDs first, admission webhooks and APIServices last). " +
+		"Use '" + none.String() + "' to suppress a final reordering, " +
+		"and write each resource as soon as it's final."
+	flagFirstKindsValue []string
+	flagLastKindsValue  []string
 )
 
 func addFlagReorderOutput(set *pflag.FlagSet) {
 	set.StringVar(
 		&flagReorderOutputValue, flagReorderOutputName,
 		legacy.String(), flagReorderOutputHelp)
+	set.StringSliceVar(
+		&flagFirstKindsValue, flagFirstKindsName, nil,
+		"With --"+flagReorderOutputName+" "+kind.String()+
+			", the kinds which come first, in order, instead of the default ones.")
+	set.StringSliceVar(
+		&flagLastKindsValue, flagLastKindsName, nil,
+		"With --"+flagReorderOutputName+" "+kind.String()+
+			", the kinds which come last, in order, instead of the default ones.")
 }
 
 func validateFlagReorderOutput() (reorderOutput, error) {
@@ -41,10 +58,24 @@ func validateFlagReorderOutput() (reorderOutput, error) {
 		return none, nil
 	case legacy.String():
 		return legacy, nil
+	case kind.String():
+		return kind, nil
 	default:
 		return unspecified, fmt.Errorf(
 			"illegal flag value --%s %s; legal values: %v",
 			flagReorderOutputName, flagReorderOutputValue,
-			[]string{legacy.String(), none.String()})
+			[]string{legacy.String(), kind.String(), none.String()})
+	}
+}
+
+// getFlagResourceOrderValue returns the order of the kinds of
+// the resources, if they're ordered by kind.
+func getFlagResourceOrderValue(order reorderOutput) *filters.SortFilter {
+	if order != kind {
+		return nil
+	}
+	return &filters.SortFilter{
+		First: flagFirstKindsValue,
+		Last:  flagLastKindsValue,
 	}
 }
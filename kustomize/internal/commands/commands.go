@@ -19,7 +19,10 @@ import (
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/build"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/create"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/pull"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/push"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/version"
+	"sigs.k8s.io/kustomize/kyaml/log"
 )
 
 // NewDefaultCommand returns the default (aka root) command for kustomize command.
@@ -42,10 +45,18 @@ See https://sigs.k8s.io/kustomize
 		build.NewCmdBuild(stdOut),
 		edit.NewCmdEdit(fSys, v, uf),
 		create.NewCmdCreate(fSys, uf),
+		push.NewCmdPush(fSys, stdOut),
+		pull.NewCmdPull(fSys, stdOut),
 		version.NewCmdVersion(stdOut),
 	)
 	configcobra.AddCommands(c, "kustomize")
 
+	logFlags := &log.Flags{}
+	logFlags.AddFlags(c.PersistentFlags())
+	c.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return logFlags.Configure(os.Stderr)
+	}
+
 	c.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 
 	// Workaround for this issue:
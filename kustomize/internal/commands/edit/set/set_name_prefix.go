@@ -12,7 +12,8 @@ import (
 )
 
 type setNamePrefixOptions struct {
-	prefix string
+	prefix  string
+	targets targetOptions
 }
 
 // newCmdSetNamePrefix sets the value of the namePrefix field in the kustomization.
@@ -27,6 +28,10 @@ The command
   set nameprefix acme-
 will add the field "namePrefix: acme-" to the kustomization file if it doesn't exist,
 and overwrite the value with "acme-" if the field does exist.
+
+The command
+  set nameprefix --kind Deployment --kind Service acme-
+will only apply the prefix to Deployments and Services.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
@@ -36,6 +41,7 @@ and overwrite the value with "acme-" if the field does exist.
 			return o.RunSetNamePrefix(fSys)
 		},
 	}
+	o.targets.addFlags(cmd)
 	return cmd
 }
 
@@ -60,5 +66,6 @@ func (o *setNamePrefixOptions) RunSetNamePrefix(fSys filesys.FileSystem) error {
 		return err
 	}
 	m.NamePrefix = o.prefix
+	m.NamePrefixTargets = o.targets.selectors()
 	return mf.Write(m)
 }
@@ -46,3 +46,30 @@ func TestSetNamePrefixNoArgs(t *testing.T) {
 		t.Errorf("incorrect error: %v", err.Error())
 	}
 }
+
+func TestSetNamePrefixTargets(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+
+	cmd := newCmdSetNamePrefix(fSys)
+	cmd.SetArgs([]string{
+		"--kind", "Deployment", "--kind", "Service",
+		"--label-selector", "app=web", goodPrefixValue})
+	err := cmd.Execute()
+	if err != nil {
+		t.Errorf("unexpected cmd error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Errorf("unexpected read error: %v", err)
+	}
+	expected := `namePrefixTargets:
+- kind: Deployment
+  labelSelector: app=web
+- kind: Service
+  labelSelector: app=web
+`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("expected prefix targets in kustomization file, got:\n%s", content)
+	}
+}
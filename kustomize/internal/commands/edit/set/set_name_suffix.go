@@ -12,7 +12,8 @@ import (
 )
 
 type setNameSuffixOptions struct {
-	suffix string
+	suffix  string
+	targets targetOptions
 }
 
 // newCmdSetNameSuffix sets the value of the nameSuffix field in the kustomization.
@@ -27,6 +28,10 @@ The command
   set namesuffix -- -acme
 will add the field "nameSuffix: -acme" to the kustomization file if it doesn't exist,
 and overwrite the value with "-acme" if the field does exist.
+
+The command
+  set namesuffix --kind Deployment --kind Service -- -acme
+will only apply the suffix to Deployments and Services.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
@@ -36,6 +41,7 @@ and overwrite the value with "-acme" if the field does exist.
 			return o.RunSetNameSuffix(fSys)
 		},
 	}
+	o.targets.addFlags(cmd)
 	return cmd
 }
 
@@ -60,5 +66,6 @@ func (o *setNameSuffixOptions) RunSetNameSuffix(fSys filesys.FileSystem) error {
 		return err
 	}
 	m.NameSuffix = o.suffix
+	m.NameSuffixTargets = o.targets.selectors()
 	return mf.Write(m)
 }
@@ -45,3 +45,25 @@ func TestSetNameSuffixNoArgs(t *testing.T) {
 		t.Errorf("incorrect error: %v", err.Error())
 	}
 }
+
+func TestSetNameSuffixTargets(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+
+	cmd := newCmdSetNameSuffix(fSys)
+	cmd.SetArgs([]string{"--label-selector", "app=web", "--", goodSuffixValue})
+	err := cmd.Execute()
+	if err != nil {
+		t.Errorf("unexpected cmd error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Errorf("unexpected read error: %v", err)
+	}
+	expected := `nameSuffixTargets:
+- labelSelector: app=web
+`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("expected suffix targets in kustomization file, got:\n%s", content)
+	}
+}
@@ -5,6 +5,7 @@ package set
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
@@ -13,11 +14,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/oci"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/kustfile"
 )
 
 type setImageOptions struct {
-	imageMap map[string]types.Image
+	imageMap      map[string]types.Image
+	resolveDigest bool
 }
 
 var pattern = regexp.MustCompile("^(.*):([a-zA-Z0-9._-]*)$")
@@ -73,18 +76,63 @@ images:
 
 to the kustomization file if it doesn't exist,
 and overwrite the previous ones if the image name exists.
+
+The command
+  set image --resolve-digest nginx=nginx:1.19
+pins nginx to the digest nginx:1.19 has in its registry,
+authenticating with the credentials docker has for it.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
 				return err
 			}
+			if o.resolveDigest {
+				if err = o.resolveDigests(); err != nil {
+					return err
+				}
+			}
 			return o.RunSetImage(fSys)
 		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completeImageNames(fSys, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
 	}
+	cmd.Flags().BoolVar(&o.resolveDigest, "resolve-digest", false,
+		"pin the images to the digests their tags have in their registries")
 	return cmd
 }
 
+// completeImageNames returns the names of the images in the kustomization file
+// which start with toComplete and haven't already been given as args.
+func completeImageNames(fSys filesys.FileSystem, args []string, toComplete string) []string {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return nil
+	}
+	m, err := mf.Read()
+	if err != nil {
+		return nil
+	}
+
+	given := make(map[string]bool)
+	for _, arg := range args {
+		if img, err := parse(arg); err == nil {
+			given[img.Name] = true
+		}
+	}
+
+	var names []string
+	for _, im := range m.Images {
+		if given[im.Name] || !strings.HasPrefix(im.Name, toComplete) {
+			continue
+		}
+		names = append(names, im.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type overwrite struct {
 	name   string
 	digest string
@@ -105,11 +153,38 @@ func (o *setImageOptions) Validate(args []string) error {
 		if err != nil {
 			return err
 		}
+		if err = img.Validate(); err != nil {
+			return err
+		}
 		o.imageMap[img.Name] = img
 	}
 	return nil
 }
 
+// resolveDigests replaces the tags of the images
+// with the digests they have in their registries.
+func (o *setImageOptions) resolveDigests() error {
+	for name, img := range o.imageMap {
+		if img.Digest != "" {
+			continue
+		}
+		ref := img.Name
+		if img.NewName != "" {
+			ref = img.NewName
+		}
+		if img.NewTag != "" {
+			ref += ":" + img.NewTag
+		}
+		r, err := oci.ResolveDigest(ref, oci.Options{})
+		if err != nil {
+			return fmt.Errorf("resolving the digest of %s: %v", ref, err)
+		}
+		img.Digest, img.NewTag = r.Digest, ""
+		o.imageMap[name] = img
+	}
+	return nil
+}
+
 // RunSetImage runs setImage command.
 func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem) error {
 	mf, err := kustfile.NewKustomizationFile(fSys)
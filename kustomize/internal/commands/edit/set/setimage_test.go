@@ -247,3 +247,70 @@ func TestSetImage(t *testing.T) {
 		})
 	}
 }
+
+func TestSetImageInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"image1=my-image1@sha256:24a0c4b4": `image image1: invalid digest ` +
+			`"sha256:24a0c4b4": sha256 digests have 64 hex digits, not 8`,
+		"image1=my-image1:.tag": `image image1: invalid tag ".tag": tags are ` +
+			`up to 128 letters, digits, '_', '.' and '-', and don't start with '.' or '-'`,
+		"image1=My-Image1": `image image1: invalid image name "My-Image1": names ` +
+			`are lowercase words separated by '/', '.', '_' or '-', optionally after a registry host`,
+	}
+	for arg, expected := range testCases {
+		t.Run(arg, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			testutils_test.WriteTestKustomization(fSys)
+			cmd := newCmdSetImage(fSys)
+			err := cmd.RunE(cmd, []string{arg})
+			if err == nil || err.Error() != expected {
+				t.Fatalf("expected error\n%s\ngot\n%v", expected, err)
+			}
+		})
+	}
+}
+
+func TestCompleteImageNames(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+images:
+- name: postgres
+  newTag: "12"
+- name: nginx
+  newTag: "1.19"
+- name: node
+  newTag: 8.15.0
+`))
+
+	testCases := []struct {
+		description string
+		args        []string
+		toComplete  string
+		expected    []string
+	}{
+		{
+			description: "all images",
+			expected:    []string{"nginx", "node", "postgres"},
+		},
+		{
+			description: "images with prefix",
+			toComplete:  "n",
+			expected:    []string{"nginx", "node"},
+		},
+		{
+			description: "skip images already given",
+			args:        []string{"nginx=nginx:1.20"},
+			toComplete:  "n",
+			expected:    []string{"node"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			actual := completeImageNames(fSys, tc.args, tc.toComplete)
+			if fmt.Sprint(actual) != fmt.Sprint(tc.expected) {
+				t.Errorf("unexpected completion. \nActual:\n%v\nExpected:\n%v", actual, tc.expected)
+			}
+		})
+	}
+}
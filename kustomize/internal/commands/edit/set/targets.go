@@ -0,0 +1,44 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package set
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// targetOptions holds the flags selecting the resources
+// a kustomization field is applied to.
+type targetOptions struct {
+	kinds         []string
+	labelSelector string
+}
+
+// addFlags adds the target flags to cmd.
+func (o *targetOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&o.kinds, "kind", nil,
+		"only apply to resources of this kind; may be repeated")
+	cmd.Flags().StringVar(&o.labelSelector, "label-selector", "",
+		"only apply to resources matching this label selector")
+}
+
+// selectors returns the targets for the flags, or nil
+// if the field should apply to all resources.
+func (o *targetOptions) selectors() []*types.Selector {
+	if len(o.kinds) == 0 {
+		if o.labelSelector == "" {
+			return nil
+		}
+		return []*types.Selector{{LabelSelector: o.labelSelector}}
+	}
+	var result []*types.Selector
+	for _, k := range o.kinds {
+		result = append(result, &types.Selector{
+			Gvk:           resid.Gvk{Kind: k},
+			LabelSelector: o.labelSelector,
+		})
+	}
+	return result
+}
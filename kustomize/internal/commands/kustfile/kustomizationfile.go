@@ -41,7 +41,9 @@ func determineFieldOrder() []string {
 		"Resources",
 		"Bases",
 		"NamePrefix",
+		"NamePrefixTargets",
 		"NameSuffix",
+		"NameSuffixTargets",
 		"Namespace",
 		"Crds",
 		"CommonLabels",
@@ -21,7 +21,9 @@ func TestFieldOrder(t *testing.T) {
 		"Resources",
 		"Bases",
 		"NamePrefix",
+		"NamePrefixTargets",
 		"NameSuffix",
+		"NameSuffixTargets",
 		"Namespace",
 		"Crds",
 		"CommonLabels",
@@ -0,0 +1,53 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package pull
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/oci"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/util"
+)
+
+// NewCmdPull returns an instance of 'pull' subcommand.
+func NewCmdPull(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f util.RegistryFlags
+	c := &cobra.Command{
+		Use:   "pull {reference} {dir}",
+		Short: "Pull a kustomization directory from an OCI registry",
+		Long: `Pull a kustomization directory pushed to an OCI registry with
+'kustomize push' into a new or empty directory, and print the
+reference pinned to its digest.
+
+If the reference has a digest, the content pulled is checked
+against it.
+`,
+		Example: `
+	# Pull a tagged kustomization.
+	kustomize pull ghcr.io/example/app:v1.0.0 app
+
+	# Pull a kustomization pinned to a digest.
+	kustomize pull ghcr.io/example/app@sha256:9f86d0... app
+`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.Options()
+			if err != nil {
+				return err
+			}
+			r, _, err := oci.Pull(fSys, args[0], args[1], o)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, r.String())
+			return nil
+		},
+	}
+	f.AddFlags(c.Flags())
+	return c
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package push
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/oci"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/util"
+)
+
+type pushFlags struct {
+	util.RegistryFlags
+	annotations []string
+	source      string
+	revision    string
+}
+
+// NewCmdPush returns an instance of 'push' subcommand.
+func NewCmdPush(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f pushFlags
+	c := &cobra.Command{
+		Use:   "push {dir} {reference}",
+		Short: "Push a kustomization directory to an OCI registry",
+		Long: `Push a kustomization directory, with its bases and files, to an OCI
+registry as an artifact, and print the reference pinned to its digest.
+
+Remote bases and resources are pushed as references, not fetched.
+`,
+		Example: `
+	# Push the kustomization in the current directory.
+	kustomize push . ghcr.io/example/app:v1.0.0
+
+	# Push, recording the git repository and commit it came from.
+	kustomize push overlays/prod ghcr.io/example/app-prod:v1.0.0 \
+	  --source https://github.com/example/app --revision $(git rev-parse HEAD)
+`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPush(fSys, w, f, args[0], args[1])
+		},
+	}
+	f.AddFlags(c.Flags())
+	c.Flags().StringArrayVar(
+		&f.annotations, "annotation", nil,
+		"annotation of the artifact, as KEY=VALUE")
+	c.Flags().StringVar(
+		&f.source, "source", "",
+		"URL of the source of the kustomization, e.g. its git repository")
+	c.Flags().StringVar(
+		&f.revision, "revision", "",
+		"revision of the source of the kustomization, e.g. its git commit")
+	return c
+}
+
+func runPush(fSys filesys.FileSystem, w io.Writer, f pushFlags, dir, ref string) error {
+	o, err := f.Options()
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{
+		oci.AnnotationCreated: time.Now().UTC().Format(time.RFC3339),
+	}
+	if f.source != "" {
+		annotations[oci.AnnotationSource] = f.source
+	}
+	if f.revision != "" {
+		annotations[oci.AnnotationRevision] = f.revision
+	}
+	for _, a := range f.annotations {
+		kv := strings.SplitN(a, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("--annotation %q must be KEY=VALUE", a)
+		}
+		annotations[kv[0]] = kv[1]
+	}
+	r, err := oci.Push(fSys, dir, ref, annotations, o)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, r.String())
+	return nil
+}
@@ -8,8 +8,10 @@ import (
 	"log"
 	"strings"
 
+	"github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/oci"
 )
 
 // GlobPatterns accepts a slice of glob strings and returns the set of
@@ -90,3 +92,34 @@ func trimQuotes(s string) string {
 	}
 	return s
 }
+
+// RegistryFlags are the flags of the push and pull
+// commands configuring access to OCI registries.
+type RegistryFlags struct {
+	creds     string
+	plainHTTP bool
+}
+
+// AddFlags adds the flags to set.
+func (f *RegistryFlags) AddFlags(set *pflag.FlagSet) {
+	set.StringVar(
+		&f.creds, "creds", "",
+		"credentials for the registry, as USERNAME:PASSWORD; "+
+			"by default, those docker has for the registry")
+	set.BoolVar(
+		&f.plainHTTP, "plain-http", false,
+		"use http rather than https, e.g. for a local registry")
+}
+
+// Options returns the options of the flags.
+func (f *RegistryFlags) Options() (oci.Options, error) {
+	o := oci.Options{PlainHTTP: f.plainHTTP}
+	if f.creds != "" {
+		parts := strings.SplitN(f.creds, ":", 2)
+		if len(parts) != 2 {
+			return o, fmt.Errorf("--creds must be USERNAME:PASSWORD")
+		}
+		o.Username, o.Password = parts[0], parts[1]
+	}
+	return o, nil
+}
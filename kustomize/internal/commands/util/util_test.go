@@ -95,3 +95,18 @@ func (l fakeLoader) Load(location string) ([]byte, error) {
 func (l fakeLoader) Cleanup() error {
 	return nil
 }
+
+func TestRegistryFlagsOptions(t *testing.T) {
+	f := RegistryFlags{creds: "me:se:cret", plainHTTP: true}
+	o, err := f.Options()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Username != "me" || o.Password != "se:cret" || !o.PlainHTTP {
+		t.Fatalf("unexpected options %+v", o)
+	}
+	f = RegistryFlags{creds: "me"}
+	if _, err = f.Options(); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metadata discovers the metadata of functions, as
+// KRMFunctionDefinitions of the KRM Functions Catalog spec,
+// so that functionConfigs can be validated against the schemas
+// functions declare before they're run, and their usage shown.
+//
+// Definitions are read from Catalog documents, which list
+// them under spec.krmFunctions, or from the
+// config.kubernetes.io/function-definition label of the
+// container image of a function, as docker has it locally.
+//
+// A definition matches a function if one of its versions runs
+// the function's image, ignoring the tag and digest, or if the
+// apiVersion and kind of the functionConfig are a version of
+// the group and kind the definition declares.
+package metadata
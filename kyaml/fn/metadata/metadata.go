@@ -0,0 +1,315 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// APIVersion is the apiVersion of catalogs and definitions.
+	APIVersion = "config.kubernetes.io/v1alpha1"
+
+	// CatalogKind is the kind of catalogs of definitions.
+	CatalogKind = "Catalog"
+
+	// DefinitionKind is the kind of definitions of functions.
+	DefinitionKind = "KRMFunctionDefinition"
+
+	// ImageLabel is the label of a function image
+	// holding the definition of the function.
+	ImageLabel = "config.kubernetes.io/function-definition"
+)
+
+// Definition is the metadata of a function.
+type Definition struct {
+	APIVersion string          `json:"apiVersion,omitempty"`
+	Kind       string          `json:"kind,omitempty"`
+	Metadata   yaml.ObjectMeta `json:"metadata,omitempty"`
+	Spec       DefinitionSpec  `json:"spec,omitempty"`
+}
+
+// DefinitionSpec describes a function, and its versions.
+type DefinitionSpec struct {
+	// Group and Names.Kind are the group and
+	// kind of the functionConfig of the function.
+	Group string `json:"group,omitempty"`
+	Names struct {
+		Kind string `json:"kind,omitempty"`
+	} `json:"names,omitempty"`
+
+	Description string   `json:"description,omitempty"`
+	Publisher   string   `json:"publisher,omitempty"`
+	Home        string   `json:"home,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	Versions []Version `json:"versions,omitempty"`
+}
+
+// Version is a version of a function.
+type Version struct {
+	// Name is the version of the functionConfig, e.g. v1.
+	Name string `json:"name,omitempty"`
+
+	// Schema is the schema functionConfigs must match.
+	Schema *struct {
+		OpenAPIV3Schema *spec.Schema `json:"openAPIV3Schema,omitempty"`
+	} `json:"schema,omitempty"`
+
+	Idempotent bool `json:"idempotent,omitempty"`
+
+	// Usage documents the function, in markdown.
+	Usage string `json:"usage,omitempty"`
+
+	// Examples are URLs or paths of examples of using the function.
+	Examples []string `json:"examples,omitempty"`
+
+	License string `json:"license,omitempty"`
+
+	Runtime struct {
+		Container *struct {
+			Image          string `json:"image,omitempty"`
+			RequireNetwork bool   `json:"requireNetwork,omitempty"`
+		} `json:"container,omitempty"`
+	} `json:"runtime,omitempty"`
+}
+
+// Validate checks functionConfig against the schema of
+// the version, if it has one.
+func (v *Version) Validate(functionConfig *yaml.RNode) error {
+	if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil || functionConfig == nil {
+		return nil
+	}
+	b, err := functionConfig.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	var obj interface{}
+	if err = json.Unmarshal(b, &obj); err != nil {
+		return errors.Wrap(err)
+	}
+	return validate.AgainstSchema(v.Schema.OpenAPIV3Schema, obj, strfmt.Default)
+}
+
+// ReadCatalog reads the definitions in a file of Catalogs
+// and KRMFunctionDefinitions.
+func ReadCatalog(path string) ([]Definition, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defs, err := parseDefinitions(b)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "reading catalog %s", path)
+	}
+	return defs, nil
+}
+
+func parseDefinitions(b []byte) ([]Definition, error) {
+	nodes, err := (&kio.ByteReader{
+		Reader:                bytes.NewReader(b),
+		OmitReaderAnnotations: true,
+	}).Read()
+	if err != nil {
+		return nil, err
+	}
+	var defs []Definition
+	for _, n := range nodes {
+		meta, err := n.GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		j, err := n.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		switch kind := meta.Kind; kind {
+		case CatalogKind:
+			var c struct {
+				Spec struct {
+					KRMFunctions []Definition `json:"krmFunctions,omitempty"`
+				} `json:"spec,omitempty"`
+			}
+			if err = json.Unmarshal(j, &c); err != nil {
+				return nil, err
+			}
+			defs = append(defs, c.Spec.KRMFunctions...)
+		case DefinitionKind:
+			var d Definition
+			if err = json.Unmarshal(j, &d); err != nil {
+				return nil, err
+			}
+			defs = append(defs, d)
+		default:
+			return nil, errors.Errorf(
+				"expected a %s or %s, got a %s", CatalogKind, DefinitionKind, kind)
+		}
+	}
+	return defs, nil
+}
+
+// Finder finds the definitions of functions.
+type Finder struct {
+	// Definitions are searched first, e.g.
+	// those read from catalogs.
+	Definitions []Definition
+
+	// InspectImages enables reading definitions from the
+	// labels of function images, with the docker program.
+	InspectImages bool
+
+	// inspect returns the labels of an image.
+	// It's a variable so it can be mocked in tests.
+	inspect func(image string) (map[string]string, error)
+
+	inspected map[string]*Definition
+}
+
+// Find returns the definition, and version, of the function
+// running image with functionConfig.  Both are nil if none is
+// found.  image may be empty, e.g. for starlark functions.
+func (f *Finder) Find(image string, functionConfig *yaml.RNode) (*Definition, *Version, error) {
+	var apiVersion, kind string
+	if functionConfig != nil {
+		if meta, err := functionConfig.GetMeta(); err == nil {
+			apiVersion, kind = meta.APIVersion, meta.Kind
+		}
+	}
+	for i := range f.Definitions {
+		d := &f.Definitions[i]
+		if v := d.versionRunning(image); v != nil {
+			return d, v, nil
+		}
+		if v := d.versionOf(apiVersion, kind); v != nil {
+			return d, v, nil
+		}
+	}
+	if !f.InspectImages || image == "" {
+		return nil, nil, nil
+	}
+	d, err := f.fromImage(image)
+	if err != nil || d == nil {
+		return nil, nil, err
+	}
+	if v := d.versionOf(apiVersion, kind); v != nil {
+		return d, v, nil
+	}
+	if len(d.Spec.Versions) == 1 {
+		return d, &d.Spec.Versions[0], nil
+	}
+	return d, nil, nil
+}
+
+// versionRunning returns the version of d running image.
+func (d *Definition) versionRunning(image string) *Version {
+	if image == "" {
+		return nil
+	}
+	for i := range d.Spec.Versions {
+		c := d.Spec.Versions[i].Runtime.Container
+		if c != nil && c.Image != "" && repository(c.Image) == repository(image) {
+			return &d.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+// versionOf returns the version of d with the
+// given functionConfig apiVersion and kind.
+func (d *Definition) versionOf(apiVersion, kind string) *Version {
+	if kind == "" || kind != d.Spec.Names.Kind {
+		return nil
+	}
+	for i := range d.Spec.Versions {
+		v := d.Spec.Versions[i].Name
+		if d.Spec.Group != "" {
+			v = d.Spec.Group + "/" + v
+		}
+		if v == apiVersion {
+			return &d.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+// repository returns image without its tag or digest.
+func repository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// fromImage returns the definition in the labels of image,
+// or nil if it has none, or docker doesn't have it.
+func (f *Finder) fromImage(image string) (*Definition, error) {
+	if d, found := f.inspected[image]; found {
+		return d, nil
+	}
+	if f.inspected == nil {
+		f.inspected = map[string]*Definition{}
+	}
+	inspect := f.inspect
+	if inspect == nil {
+		inspect = dockerLabels
+	}
+	labels, err := inspect(image)
+	if err != nil {
+		return nil, err
+	}
+	var d *Definition
+	if labels[ImageLabel] != "" {
+		defs, err := parseDefinitions([]byte(labels[ImageLabel]))
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "reading label %s of %s", ImageLabel, image)
+		}
+		if len(defs) != 1 {
+			return nil, errors.Errorf(
+				"label %s of %s holds %d definitions", ImageLabel, image, len(defs))
+		}
+		d = &defs[0]
+	}
+	f.inspected[image] = d
+	return d, nil
+}
+
+// dockerLabels returns the labels of image, or none if
+// docker doesn't have it locally.
+func dockerLabels(image string) (map[string]string, error) {
+	docker, err := exec.LookPath("docker")
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "inspecting function images needs docker")
+	}
+	cmd := exec.Command(docker, "image", "inspect", "--format", "{{json .Config.Labels}}", image)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "No such image") {
+			return nil, nil
+		}
+		return nil, errors.Errorf("docker image inspect %s: %v: %s",
+			image, err, strings.TrimSpace(stderr.String()))
+	}
+	var labels map[string]string
+	if err = json.Unmarshal(stdout.Bytes(), &labels); err != nil {
+		return nil, errors.WrapPrefixf(err, "docker image inspect %s", image)
+	}
+	return labels, nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const catalog = `apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+metadata:
+  name: example
+spec:
+  krmFunctions:
+  - apiVersion: config.kubernetes.io/v1alpha1
+    kind: KRMFunctionDefinition
+    spec:
+      group: fn.example.com
+      names:
+        kind: SetNamespace
+      description: Sets the namespace of resources.
+      versions:
+      - name: v1
+        schema:
+          openAPIV3Schema:
+            type: object
+            required: [namespace]
+            properties:
+              namespace:
+                type: string
+        usage: Set namespace to the namespace to use.
+        runtime:
+          container:
+            image: example.com/set-namespace:v1.0.0
+---
+apiVersion: config.kubernetes.io/v1alpha1
+kind: KRMFunctionDefinition
+spec:
+  names:
+    kind: ConfigMap
+  description: Labels resources.
+  versions:
+  - name: v1
+    runtime:
+      container:
+        image: example.com/label
+`
+
+func readCatalog(t *testing.T) []Definition {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "catalog.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(catalog), 0600))
+	defs, err := ReadCatalog(path)
+	require.NoError(t, err)
+	return defs
+}
+
+func TestReadCatalog(t *testing.T) {
+	defs := readCatalog(t)
+	if !assert.Len(t, defs, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "SetNamespace", defs[0].Spec.Names.Kind)
+	assert.Equal(t, "Set namespace to the namespace to use.", defs[0].Spec.Versions[0].Usage)
+	assert.Equal(t, []string{"namespace"}, defs[0].Spec.Versions[0].Schema.OpenAPIV3Schema.Required)
+	assert.Equal(t, "example.com/label", defs[1].Spec.Versions[0].Runtime.Container.Image)
+}
+
+func TestReadCatalog_wrongKind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "catalog.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("kind: ConfigMap\n"), 0600))
+	_, err = ReadCatalog(path)
+	assert.EqualError(t, err, "reading catalog "+path+
+		": expected a Catalog or KRMFunctionDefinition, got a ConfigMap")
+}
+
+func TestFinder_Find(t *testing.T) {
+	f := &Finder{Definitions: readCatalog(t)}
+	testCases := []struct {
+		name           string
+		image          string
+		functionConfig string
+		expected       string
+	}{
+		{
+			name:     "by image",
+			image:    "example.com/set-namespace:v1.1.0",
+			expected: "SetNamespace",
+		},
+		{
+			name:  "by functionConfig",
+			image: "example.com/other@sha256:0123",
+			functionConfig: `apiVersion: fn.example.com/v1
+kind: SetNamespace
+`,
+			expected: "SetNamespace",
+		},
+		{
+			name:  "by core functionConfig",
+			image: "example.com/other",
+			functionConfig: `apiVersion: v1
+kind: ConfigMap
+`,
+			expected: "ConfigMap",
+		},
+		{
+			name:  "other version",
+			image: "example.com/other",
+			functionConfig: `apiVersion: fn.example.com/v2
+kind: SetNamespace
+`,
+		},
+		{
+			name:  "not found",
+			image: "example.com/set-namespace-v2:v1.0.0",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fc *yaml.RNode
+			if tc.functionConfig != "" {
+				fc = yaml.MustParse(tc.functionConfig)
+			}
+			d, v, err := f.Find(tc.image, fc)
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Nil(t, d)
+				assert.Nil(t, v)
+				return
+			}
+			if assert.NotNil(t, d) && assert.NotNil(t, v) {
+				assert.Equal(t, tc.expected, d.Spec.Names.Kind)
+			}
+		})
+	}
+}
+
+func TestFinder_FindInImage(t *testing.T) {
+	var inspected []string
+	f := &Finder{
+		InspectImages: true,
+		inspect: func(image string) (map[string]string, error) {
+			inspected = append(inspected, image)
+			if image != "example.com/labelled:v1" {
+				return nil, nil
+			}
+			return map[string]string{ImageLabel: `apiVersion: config.kubernetes.io/v1alpha1
+kind: KRMFunctionDefinition
+spec:
+  group: fn.example.com
+  names:
+    kind: Labeller
+  versions:
+  - name: v1
+    usage: Labels resources.
+`}, nil
+		},
+	}
+	for i := 0; i < 2; i++ {
+		d, v, err := f.Find("example.com/labelled:v1", nil)
+		require.NoError(t, err)
+		if assert.NotNil(t, d) && assert.NotNil(t, v) {
+			assert.Equal(t, "Labeller", d.Spec.Names.Kind)
+			assert.Equal(t, "Labels resources.", v.Usage)
+		}
+	}
+	d, v, err := f.Find("example.com/unlabelled:v1", nil)
+	require.NoError(t, err)
+	assert.Nil(t, d)
+	assert.Nil(t, v)
+	assert.Equal(t, []string{"example.com/labelled:v1", "example.com/unlabelled:v1"}, inspected)
+}
+
+func TestVersion_Validate(t *testing.T) {
+	v := readCatalog(t)[0].Spec.Versions[0]
+	assert.NoError(t, v.Validate(yaml.MustParse(`apiVersion: fn.example.com/v1
+kind: SetNamespace
+namespace: prod
+`)))
+	err := v.Validate(yaml.MustParse(`apiVersion: fn.example.com/v1
+kind: SetNamespace
+namespace: 1
+`))
+	assert.EqualError(t, err, "validation failure list:\n"+
+		"namespace in body must be of type string: \"number\"")
+	err = v.Validate(yaml.MustParse(`apiVersion: fn.example.com/v1
+kind: SetNamespace
+`))
+	assert.EqualError(t, err, "validation failure list:\n"+
+		".namespace in body is required")
+}
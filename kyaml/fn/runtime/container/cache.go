@@ -0,0 +1,137 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// imageDigest returns the digest identifying the content of image, or ""
+// if it isn't known, e.g. because the runtime hasn't pulled the image yet.
+// It's replaced by tests.
+var imageDigest = func(runtime Runtime, image string) string {
+	if i := strings.Index(image, "@sha256:"); i >= 0 {
+		return image[i+1:]
+	}
+	out, err := exec.Command(
+		string(runtime), "image", "inspect", "--format", "{{.Id}}", image).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// pullImage pulls image for platform, if it's set.  It's replaced by
+// tests.
+var pullImage = func(runtime Runtime, image, platform string) error {
+	args := []string{"pull"}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	out, err := exec.Command(string(runtime), append(args, image)...).CombinedOutput()
+	if err != nil {
+		return errors.Errorf("unable to pull %s: %v: %s", image, err, out)
+	}
+	return nil
+}
+
+// cacheable returns true if the output of the container only depends on
+// its image and its input, so it may be cached.
+func (c *Filter) cacheable() bool {
+	return c.CacheDir != "" && len(c.StorageMounts) == 0 &&
+		(c.Network == "" || c.Network == "none")
+}
+
+// cachedRun runs the container, unless its output for the same image
+// digest, environment and input, which includes the function config, is
+// cached in CacheDir.  Output is only cached if the container succeeds.
+func (c *Filter) cachedRun(reader io.Reader, writer io.Writer) error {
+	runtime, err := GetRuntime(string(c.Runtime))
+	if err != nil {
+		return err
+	}
+	// pull the image first, so the digest is the one of the image run
+	// rather than the one of a stale local image
+	if c.ImagePullPolicy == PullAlways && !strings.Contains(c.Image, "@sha256:") {
+		if err := pullImage(runtime, c.Image, c.Platform); err != nil {
+			return err
+		}
+	}
+	digest := imageDigest(runtime, c.Image)
+	if digest == "" {
+		return c.runContainer(reader, writer)
+	}
+
+	input, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	h := sha256.New()
+	h.Write([]byte(c.cacheKey(digest)))
+	h.Write(input)
+	path := filepath.Join(c.CacheDir, hex.EncodeToString(h.Sum(nil))+".yaml")
+
+	if output, err := ioutil.ReadFile(path); err == nil {
+		_, err = writer.Write(output)
+		return errors.Wrap(err)
+	}
+
+	output := &bytes.Buffer{}
+	if err := c.runContainer(bytes.NewReader(input), io.MultiWriter(writer, output)); err != nil {
+		return err
+	}
+	return writeCache(path, output.Bytes())
+}
+
+// cacheKey returns everything but the input that the output of the
+// container may depend on: its image digest, platform and network, and
+// the values of its Env.
+func (c *Filter) cacheKey(digest string) string {
+	network := c.Network
+	if network == "" {
+		network = "none"
+	}
+	key := []string{digest, c.Platform, network}
+	for _, m := range c.StorageMounts {
+		key = append(key, m.String())
+	}
+	for _, e := range c.Env {
+		if !strings.Contains(e, "=") {
+			e += "=" + os.Getenv(e)
+		}
+		key = append(key, e)
+	}
+	return strings.Join(key, "\n") + "\n"
+}
+
+// writeCache writes output to path, through a temporary file so that
+// concurrent runs never read partially written output.
+func writeCache(path string, output []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrap(err)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(output); err != nil {
+		f.Close()
+		return errors.Wrap(err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err)
+	}
+	return errors.Wrap(os.Rename(f.Name(), path))
+}
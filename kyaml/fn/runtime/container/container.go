@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
@@ -21,7 +22,7 @@ import (
 // If there is a error or validation failure, the process must exit
 // non-zero.
 // The full set of environment variables from the parent process
-// are passed to the container.
+// are passed to the container, unless its output is cached, see CacheDir.
 //
 // Function Scoping:
 // Filter applies the function only to Resources to which it is scoped.
@@ -133,6 +134,50 @@ type Filter struct {
 	// StorageMounts is a list of storage options that the container will have mounted.
 	StorageMounts []runtimeutil.StorageMount `yaml:"mounts,omitempty"`
 
+	// Runtime is the container engine to run the container with, see
+	// GetRuntime.  Defaults to the RuntimeEnvName environment variable,
+	// or docker.
+	Runtime Runtime `yaml:"runtime,omitempty"`
+
+	// Env are environment variables given to the container, as
+	// KEY=VALUE, or KEY to export the local value of KEY.  Containers
+	// which aren't cached are also given the whole local environment.
+	Env []string `yaml:"env,omitempty"`
+
+	// CacheDir, if set, caches the output of the container in this
+	// directory, keyed by the digest of its image, its Env and its input,
+	// so the container isn't run again for the same input.  Cached
+	// containers are only given Env, rather than the local environment.
+	// With the Always ImagePullPolicy, the image is pulled before
+	// its digest is resolved.  Containers with
+	// StorageMounts or a Network aren't cached, as their output may
+	// depend on more than their input.
+	CacheDir string `yaml:"cacheDir,omitempty"`
+
+	// ImagePullPolicy, if set, is when the image is pulled.  Otherwise
+	// the runtime decides.
+	ImagePullPolicy ImagePullPolicy `yaml:"imagePullPolicy,omitempty"`
+
+	// Platform, if set, is the platform of the image to run, e.g.
+	// linux/amd64, so functions run the same on hosts of any architecture.
+	Platform string `yaml:"platform,omitempty"`
+
+	// Memory, if set, limits the memory of the container, e.g. 512m,
+	// as with the --memory flag of docker run.
+	Memory string `yaml:"memory,omitempty"`
+
+	// CPUs, if set, limits the CPUs the container may use, e.g. 1.5,
+	// as with the --cpus flag of docker run.
+	CPUs string `yaml:"cpus,omitempty"`
+
+	// Timeout, if set, is how long the container may run before it's
+	// killed, and the Filter fails with a TimeoutError.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// containerName is the name of the container, if it may need to be
+	// killed after the Timeout.
+	containerName string
+
 	Exec runtimeexec.Filter
 }
 
@@ -147,35 +192,78 @@ func (c Filter) GetExit() error {
 }
 
 func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
-	c.setupExec()
-	return c.Exec.Filter(nodes)
+	if err := c.setupExec(); err != nil {
+		return nil, err
+	}
+	switch {
+	case c.cacheable():
+		c.Exec.FunctionFilter.Run = c.cachedRun
+	case c.Timeout > 0:
+		c.Exec.FunctionFilter.Run = c.runContainer
+	default:
+		return c.Exec.Filter(nodes)
+	}
+	return c.Exec.FunctionFilter.Filter(nodes)
 }
 
-func (c *Filter) setupExec() {
+func (c *Filter) setupExec() error {
 	// don't init 2x
 	if c.Exec.Path != "" {
-		return
+		return nil
 	}
 
-	path, args := c.getCommand()
+	path, args, err := c.getCommand()
+	if err != nil {
+		return err
+	}
 	c.Exec.Path = path
 	c.Exec.Args = args
+	return nil
 }
 
 // getArgs returns the command + args to run to spawn the container
-func (c *Filter) getCommand() (string, []string) {
-	// run the container using docker.  this is simpler than using the docker
-	// libraries, and ensures things like auth work the same as if the container
-	// was run from the cli.
+func (c *Filter) getCommand() (string, []string, error) {
+	// run the container using the cli of the runtime.  this is simpler than
+	// using the runtime libraries, and ensures things like auth work the same
+	// as if the container was run from the cli.
+	runtime, err := GetRuntime(string(c.Runtime))
+	if err != nil {
+		return "", nil, err
+	}
 
+	args := []string{"run",
+		"--rm", // delete the container afterward
+	}
+	args = append(args, runtime.attachArgs()...) // attach stdin, stdout, stderr
+
+	if c.Timeout > 0 {
+		// name the container, so it can be killed when it times out
+		c.containerName, err = containerName()
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, "--name", c.containerName)
+	}
+
+	options, err := c.RunArgs()
+	if err != nil {
+		return "", nil, err
+	}
+	a := append(append(args, options...), c.Image)
+	return string(runtime), a, nil
+}
+
+// RunArgs returns the options of the run command of the runtime which
+// run the container as Filter does: its network, user, pull policy,
+// platform, limits, mounts and exported environment.  Functions run as
+// containers by other means, e.g. as gRPC servers, run them with these.
+func (c *Filter) RunArgs() ([]string, error) {
 	network := "none"
 	if c.Network != "" {
 		network = c.Network
 	}
 
-	args := []string{"run",
-		"--rm",                                              // delete the container afterward
-		"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR", // attach stdin, stdout, stderr
+	args := []string{
 		"--network", network,
 
 		// added security options
@@ -184,6 +272,23 @@ func (c *Filter) getCommand() (string, []string) {
 		// note: don't make fs readonly because things like heredoc rely on writing tmp files
 	}
 
+	pull, err := c.ImagePullPolicy.pullArgs()
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, pull...)
+	if c.Platform != "" {
+		args = append(args, "--platform", c.Platform)
+	}
+
+	// limit the resources of the container, so it can't exhaust the host
+	if c.Memory != "" {
+		args = append(args, "--memory", c.Memory)
+	}
+	if c.CPUs != "" {
+		args = append(args, "--cpus", c.CPUs)
+	}
+
 	// TODO(joncwong): Allow StorageMount fields to have default values.
 	for _, storageMount := range c.StorageMounts {
 		args = append(args, "--mount", storageMount.String())
@@ -192,6 +297,15 @@ func (c *Filter) getCommand() (string, []string) {
 	os.Setenv("LOG_TO_STDERR", "true")
 	os.Setenv("STRUCTURED_RESULTS", "true")
 
+	// cached containers are only given Env, which their output is cached on
+	if c.cacheable() {
+		args = append(args, "-e", "LOG_TO_STDERR=true", "-e", "STRUCTURED_RESULTS=true")
+		for _, e := range c.Env {
+			args = append(args, "-e", e)
+		}
+		return args, nil
+	}
+
 	// export the local environment vars to the container
 	for _, pair := range os.Environ() {
 		items := strings.Split(pair, "=")
@@ -200,6 +314,8 @@ func (c *Filter) getCommand() (string, []string) {
 		}
 		args = append(args, "-e", items[0])
 	}
-	a := append(args, c.Image)
-	return "docker", a
+	for _, e := range c.Env {
+		args = append(args, "-e", e)
+	}
+	return args, nil
 }
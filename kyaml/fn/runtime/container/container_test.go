@@ -6,9 +6,12 @@ package container
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
@@ -59,6 +62,64 @@ metadata:
 			instance: Filter{Image: "example.com:version", Network: "test-1"},
 		},
 
+		{
+			name: "resource_limits",
+			functionConfig: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedArgs: []string{
+				"run",
+				"--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none",
+				"--user", "nobody",
+				"--security-opt=no-new-privileges",
+				"--memory", "512m",
+				"--cpus", "1.5",
+			},
+			instance: Filter{Image: "example.com:version", Memory: "512m", CPUs: "1.5"},
+		},
+
+		{
+			name: "image_pull_policy",
+			functionConfig: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedArgs: []string{
+				"run",
+				"--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none",
+				"--user", "nobody",
+				"--security-opt=no-new-privileges",
+				"--pull", "missing",
+			},
+			instance: Filter{Image: "example.com:version", ImagePullPolicy: PullIfNotPresent},
+		},
+
+		{
+			name: "platform",
+			functionConfig: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedArgs: []string{
+				"run",
+				"--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none",
+				"--user", "nobody",
+				"--security-opt=no-new-privileges",
+				"--platform", "linux/amd64",
+			},
+			instance: Filter{Image: "example.com:version", Platform: "linux/amd64"},
+		},
+
 		{
 			name: "storage_mounts",
 			functionConfig: `apiVersion: apps/v1
@@ -100,7 +161,9 @@ metadata:
 			tt.instance.Exec.FunctionConfig = cfg
 
 			os.Setenv("KYAML_TEST", "FOO")
-			tt.instance.setupExec()
+			if !assert.NoError(t, tt.instance.setupExec()) {
+				t.FailNow()
+			}
 
 			// configure expected env
 			for _, e := range os.Environ() {
@@ -123,6 +186,80 @@ metadata:
 	}
 }
 
+func TestFilter_setupExec_runtime(t *testing.T) {
+	var tests = []struct {
+		name           string
+		runtime        Runtime
+		env            string
+		expectedPath   string
+		expectedAttach []string
+		err            string
+	}{
+		{
+			name:           "docker",
+			runtime:        Docker,
+			expectedPath:   "docker",
+			expectedAttach: []string{"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR"},
+		},
+		{
+			name:           "podman",
+			runtime:        Podman,
+			expectedPath:   "podman",
+			expectedAttach: []string{"-i", "-a", "stdin", "-a", "stdout", "-a", "stderr"},
+		},
+		{
+			name:           "nerdctl",
+			runtime:        Nerdctl,
+			expectedPath:   "nerdctl",
+			expectedAttach: []string{"-i"},
+		},
+		{
+			name:           "env",
+			env:            "podman",
+			expectedPath:   "podman",
+			expectedAttach: []string{"-i", "-a", "stdin", "-a", "stdout", "-a", "stderr"},
+		},
+		{
+			name:           "field-over-env",
+			runtime:        Nerdctl,
+			env:            "podman",
+			expectedPath:   "nerdctl",
+			expectedAttach: []string{"-i"},
+		},
+		{
+			name:    "unsupported",
+			runtime: "rkt",
+			err:     "unsupported container runtime rkt, must be one of [docker podman nerdctl]",
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				os.Setenv(RuntimeEnvName, tt.env)
+				defer os.Unsetenv(RuntimeEnvName)
+			}
+			instance := Filter{Image: "example.com:version", Runtime: tt.runtime}
+			err := instance.setupExec()
+			if tt.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.err, err.Error())
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			assert.Equal(t, tt.expectedPath, instance.Exec.Path)
+			expectedArgs := append(append([]string{"run", "--rm"}, tt.expectedAttach...),
+				"--network", "none")
+			assert.Equal(t, expectedArgs, instance.Exec.Args[:len(expectedArgs)])
+		})
+	}
+}
+
 func TestFilter_Filter(t *testing.T) {
 	cfg, err := yaml.Parse(`apiVersion: apps/v1
 kind: Deployment
@@ -182,6 +319,198 @@ metadata:
 		t.FailNow()
 	}
 }
+func TestFilter_Filter_cache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kyaml-container-cache")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	runs := filepath.Join(dir, "runs")
+
+	digest := "sha256:1234"
+	defer func(f func(Runtime, string) string) { imageDigest = f }(imageDigest)
+	imageDigest = func(Runtime, string) string { return digest }
+	pulls := 0
+	defer func(f func(Runtime, string, string) error) { pullImage = f }(pullImage)
+	pullImage = func(Runtime, string, string) error {
+		// the pull updates the image
+		pulls++
+		digest = "sha256:pulled"
+		return nil
+	}
+	var policy ImagePullPolicy
+	var env []string
+
+	run := func() string {
+		cfg, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		input, err := (&kio.ByteReader{Reader: bytes.NewBufferString(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo
+`)}).Read()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		// record each run of the "container"
+		instance := Filter{Image: "example.com:version", CacheDir: filepath.Join(dir, "cache"),
+			ImagePullPolicy: policy, Env: env}
+		instance.Exec.FunctionConfig = cfg
+		instance.Exec.Path = "sh"
+		instance.Exec.Args = []string{"-c", "echo >> " + runs + "; sed s/Deployment/StatefulSet/g"}
+		output, err := instance.Filter(input)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		b := &bytes.Buffer{}
+		if !assert.NoError(t, kio.ByteWriter{Writer: b}.Write(output)) {
+			t.FailNow()
+		}
+		return b.String()
+	}
+	countRuns := func() int {
+		b, err := ioutil.ReadFile(runs)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return len(b)
+	}
+
+	expected := `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: deployment-foo
+  annotations:
+    config.kubernetes.io/path: 'statefulset_deployment-foo.yaml'
+`
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 1, countRuns())
+
+	// the output is read from the cache
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 1, countRuns())
+
+	// the container is run again when its image changes
+	digest = "sha256:5678"
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 2, countRuns())
+
+	// the local environment isn't given to the container, so it doesn't
+	// change the key
+	os.Setenv("KYAML_CONTAINER_CACHE_TEST", "1")
+	defer os.Unsetenv("KYAML_CONTAINER_CACHE_TEST")
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 2, countRuns())
+
+	// the container is run again when its Env changes, including the
+	// local values it exports
+	env = []string{"KYAML_CONTAINER_CACHE_TEST", "A=1"}
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 3, countRuns())
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 3, countRuns())
+	os.Setenv("KYAML_CONTAINER_CACHE_TEST", "2")
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 4, countRuns())
+
+	// the digest is resolved after pulling the image
+	policy = PullAlways
+	assert.Equal(t, expected, run())
+	assert.Equal(t, 1, pulls)
+	assert.Equal(t, 5, countRuns())
+}
+
+func TestFilter_RunArgs_cache(t *testing.T) {
+	os.Setenv("KYAML_TEST", "FOO")
+	defer os.Unsetenv("KYAML_TEST")
+
+	// cached containers are only given their Env
+	instance := Filter{Image: "example.com:version", CacheDir: "cache",
+		Env: []string{"A=1", "B"}}
+	args, err := instance.RunArgs()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{
+		"--network", "none",
+		"--user", "nobody",
+		"--security-opt=no-new-privileges",
+		"-e", "LOG_TO_STDERR=true",
+		"-e", "STRUCTURED_RESULTS=true",
+		"-e", "A=1",
+		"-e", "B",
+	}, args)
+
+	// others are also given the local environment
+	instance.CacheDir = ""
+	args, err = instance.RunArgs()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, args, "KYAML_TEST")
+	assert.Equal(t, []string{"-e", "A=1", "-e", "B"}, args[len(args)-4:])
+}
+
+func TestFilter_Filter_timeout(t *testing.T) {
+	cfg, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	instance := Filter{Image: "example.com:version", Timeout: 100 * time.Millisecond}
+	instance.Exec.FunctionConfig = cfg
+	instance.Exec.Path = "sh"
+	instance.Exec.Args = []string{"-c", "echo partial output >&2; exec sleep 10"}
+	start := time.Now()
+	_, err = instance.Filter(nil)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	timeout, ok := err.(*TimeoutError)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	assert.Equal(t, &TimeoutError{
+		Image:   "example.com:version",
+		Timeout: 100 * time.Millisecond,
+		Stderr:  "partial output\n",
+	}, timeout)
+	assert.Equal(t, "function example.com:version timed out after 100ms: partial output",
+		err.Error())
+}
+
+func TestFilter_setupExec_imagePullPolicy(t *testing.T) {
+	instance := Filter{Image: "example.com:version", ImagePullPolicy: "Sometimes"}
+	err := instance.setupExec()
+	if assert.Error(t, err) {
+		assert.Equal(t, "unsupported image pull policy Sometimes, "+
+			"must be one of [Always IfNotPresent Never]", err.Error())
+	}
+}
+
+func TestFilter_setupExec_timeout(t *testing.T) {
+	instance := Filter{Image: "example.com:version", Timeout: time.Minute}
+	if !assert.NoError(t, instance.setupExec()) {
+		t.FailNow()
+	}
+
+	// the container is named, so it can be killed when it times out
+	assert.Contains(t, instance.containerName, "kyaml-fn-")
+	assert.Contains(t, strings.Join(instance.Exec.Args, " "), "--name "+instance.containerName)
+}
+
 func TestFilter_String(t *testing.T) {
 	instance := Filter{Image: "foo"}
 	if !assert.Equal(t, "foo", instance.String()) {
@@ -0,0 +1,95 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"os"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// Runtime is the container engine whose cli runs the containers of functions.
+type Runtime string
+
+const (
+	Docker  Runtime = "docker"
+	Podman  Runtime = "podman"
+	Nerdctl Runtime = "nerdctl"
+)
+
+// RuntimeEnvName is the environment variable naming the Runtime
+// of the Filters which don't set one.
+const RuntimeEnvName = "KUSTOMIZE_CONTAINER_RUNTIME"
+
+// Runtimes are the supported runtimes.
+var Runtimes = []Runtime{Docker, Podman, Nerdctl}
+
+// GetRuntime returns the runtime named name.  If name is empty, it
+// returns the runtime named by the RuntimeEnvName environment variable,
+// or Docker if it isn't set.
+func GetRuntime(name string) (Runtime, error) {
+	if name == "" {
+		name = os.Getenv(RuntimeEnvName)
+	}
+	if name == "" {
+		return Docker, nil
+	}
+	for _, r := range Runtimes {
+		if string(r) == name {
+			return r, nil
+		}
+	}
+	return "", errors.Errorf(
+		"unsupported container runtime %s, must be one of %v", name, Runtimes)
+}
+
+// attachArgs returns the flags of run which keep stdin open, and
+// attach stdin, stdout and stderr to the container.
+func (r Runtime) attachArgs() []string {
+	switch r {
+	case Podman:
+		// podman names the streams in lower case
+		return []string{"-i", "-a", "stdin", "-a", "stdout", "-a", "stderr"}
+	case Nerdctl:
+		// nerdctl has no -a, and attaches all the streams with -i
+		return []string{"-i"}
+	default:
+		return []string{"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR"}
+	}
+}
+
+// ImagePullPolicy is when the image of a container is pulled.
+type ImagePullPolicy string
+
+const (
+	// PullAlways always pulls the image, e.g. so CI runs the latest image
+	// of a tag.
+	PullAlways ImagePullPolicy = "Always"
+
+	// PullIfNotPresent only pulls the image if the runtime doesn't have it.
+	PullIfNotPresent ImagePullPolicy = "IfNotPresent"
+
+	// PullNever never pulls the image, e.g. in air-gapped environments,
+	// and fails if the runtime doesn't have it.
+	PullNever ImagePullPolicy = "Never"
+)
+
+// pullArgs returns the flags of run pulling the image by policy, which
+// are the same for all the runtimes.  If policy is empty, the runtime
+// decides when to pull the image.
+func (policy ImagePullPolicy) pullArgs() ([]string, error) {
+	switch policy {
+	case "":
+		return nil, nil
+	case PullAlways:
+		return []string{"--pull", "always"}, nil
+	case PullIfNotPresent:
+		return []string{"--pull", "missing"}, nil
+	case PullNever:
+		return []string{"--pull", "never"}, nil
+	default:
+		return nil, errors.Errorf("unsupported image pull policy %s, must be one of %v",
+			policy, []ImagePullPolicy{PullAlways, PullIfNotPresent, PullNever})
+	}
+}
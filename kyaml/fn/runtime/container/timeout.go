@@ -0,0 +1,82 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// TimeoutError is the error of a container which didn't exit before
+// the Timeout of its Filter, and was killed.
+type TimeoutError struct {
+	// Image is the image of the container.
+	Image string
+
+	// Timeout is the timeout the container exceeded.
+	Timeout time.Duration
+
+	// Stderr is what the container wrote to stderr before it was killed.
+	Stderr string
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("function %s timed out after %v", e.Image, e.Timeout)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+// containerName returns a name for a container, so that it can be
+// killed if it times out.
+func containerName() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err)
+	}
+	return "kyaml-fn-" + hex.EncodeToString(b), nil
+}
+
+// runContainer runs the container, killing it if it doesn't exit
+// before the Timeout, if any.
+func (c *Filter) runContainer(reader io.Reader, writer io.Writer) error {
+	if c.Timeout <= 0 {
+		return c.Exec.Run(reader, writer)
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(c.Exec.Path, c.Exec.Args...)
+	cmd.Stdin = reader
+	cmd.Stdout = writer
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(c.Timeout):
+		// killing the cli doesn't stop the container, so kill it by name,
+		// and then the cli
+		if c.containerName != "" {
+			_ = exec.Command(c.Exec.Path, "kill", c.containerName).Run()
+		}
+		_ = cmd.Process.Kill()
+		<-done
+		return &TimeoutError{Image: c.Image, Timeout: c.Timeout, Stderr: stderr.String()}
+	}
+}
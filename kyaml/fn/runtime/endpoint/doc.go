@@ -0,0 +1,16 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package endpoint contains a kio.Filter which runs a function hosted as
+// an HTTPS service, so organizations may host shared transformations
+// rather than distribute images.
+//
+// Filter POSTs the ResourceList to the endpoint, with the same scoping
+// and results as functions run in containers, and reads the transformed
+// ResourceList from the response.  Responses other than 2xx fail the
+// function with the response body.
+//
+// As the URL of a function is read from the package it's run on, the
+// headers, e.g. credentials, are only sent to URLs which match the URL
+// prefixes the Filter is given.
+package endpoint
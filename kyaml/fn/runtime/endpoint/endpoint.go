@@ -0,0 +1,202 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package endpoint
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// defaultMaxResponseSize is the default MaxResponseSize.
+const defaultMaxResponseSize = 256 << 20
+
+// Filter runs a function hosted at an HTTPS endpoint.
+type Filter struct {
+	// URL is the https URL the ResourceList is POSTed to.
+	URL string
+
+	// Timeout, if set, is how long the endpoint may take to respond.
+	Timeout time.Duration
+
+	// Headers are set on the request, e.g. an Authorization header.
+	// Their values are expanded with os.ExpandEnv, so tokens needn't
+	// be written down, e.g. "Bearer ${FN_TOKEN}".  Headers are only
+	// sent to URLs matching URLPrefixes, which must then be set.
+	Headers map[string]string
+
+	// URLPrefixes, if set, are the URLs which URL must start with, e.g.
+	// https://fn.example.com/functions/, so the URL of a function, which
+	// is read from the package, can't send the Headers elsewhere.  URL
+	// must have the scheme and host of the prefix, and a path starting
+	// with the path segments of the prefix.  The endpoint may only
+	// redirect to URLs matching them too.
+	URLPrefixes []string
+
+	// MaxResponseSize, if set, bounds the size of the response, so an
+	// endpoint can't exhaust the memory of the host.  Defaults to 256MiB.
+	MaxResponseSize int64
+
+	// TLSConfig, if set, configures the TLS of the requests, e.g. with
+	// the CA of the endpoint or a client certificate, see TLSConfig.
+	TLSConfig *tls.Config
+
+	runtimeutil.FunctionFilter
+}
+
+func (c *Filter) String() string {
+	return fmt.Sprintf("url: %v", c.URL)
+}
+
+func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	c.FunctionFilter.Run = c.Run
+	return c.FunctionFilter.Filter(nodes)
+}
+
+// Check returns an error if the endpoint may not be run, because its
+// URL isn't https or doesn't match URLPrefixes.
+func (c *Filter) Check() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return errors.WrapPrefixf(err, "invalid function endpoint %s", c.URL)
+	}
+	return c.checkURL(c.URL, u)
+}
+
+// checkURL returns an error if u, parsed from raw, which is the URL of
+// the endpoint or one it redirects to, isn't https or doesn't match
+// URLPrefixes.
+func (c *Filter) checkURL(raw string, u *url.URL) error {
+	if u.Scheme != "https" {
+		return errors.Errorf("function endpoint %s must use https", raw)
+	}
+	if len(c.URLPrefixes) == 0 {
+		if len(c.Headers) > 0 {
+			return errors.Errorf(
+				"function endpoint %s can't be sent headers without URL prefixes", raw)
+		}
+		return nil
+	}
+	for _, prefix := range c.URLPrefixes {
+		p, err := url.Parse(prefix)
+		if err != nil {
+			return errors.WrapPrefixf(err, "invalid function endpoint URL prefix %s", prefix)
+		}
+		if hasPrefix(u, p) {
+			return nil
+		}
+	}
+	return errors.Errorf("function endpoint %s doesn't match any of the URL prefixes %v",
+		raw, c.URLPrefixes)
+}
+
+// hasPrefix returns true if u has the scheme and host of prefix, and a
+// path starting with its path segments, so e.g. https://example.com/a
+// doesn't match https://example.com.other.org or https://example.com/ab.
+func hasPrefix(u, prefix *url.URL) bool {
+	if !strings.EqualFold(u.Scheme, prefix.Scheme) || !strings.EqualFold(u.Host, prefix.Host) ||
+		u.User != nil {
+		return false
+	}
+	p := strings.TrimSuffix(prefix.EscapedPath(), "/")
+	path := u.EscapedPath()
+	return p == "" || path == p || strings.HasPrefix(path, p+"/")
+}
+
+// Run POSTs the ResourceList read from reader to the endpoint, and
+// writes the ResourceList of the response to writer.
+func (c *Filter) Run(reader io.Reader, writer io.Writer) error {
+	if err := c.Check(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.URL, reader)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+	req.Header.Set("Accept", "application/yaml")
+	for k, v := range c.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	client := &http.Client{
+		Timeout:   c.Timeout,
+		Transport: &http.Transport{TLSClientConfig: c.TLSConfig, Proxy: http.ProxyFromEnvironment},
+		// redirects are sent the Headers too, so they're checked
+		// like the URL of the endpoint
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.Errorf("function endpoint %s redirected too many times", c.URL)
+			}
+			return c.checkURL(req.URL.String(), req.URL)
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WrapPrefixf(err, "function endpoint %s", c.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		msg := strings.TrimSpace(string(b))
+		if msg == "" {
+			return errors.Errorf("function endpoint %s responded %s", c.URL, resp.Status)
+		}
+		return errors.Errorf("function endpoint %s responded %s: %s", c.URL, resp.Status, msg)
+	}
+	max := c.MaxResponseSize
+	if max == 0 {
+		max = defaultMaxResponseSize
+	}
+	n, err := io.Copy(writer, io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if n > max {
+		return errors.Errorf("function endpoint %s responded with more than %d bytes", c.URL, max)
+	}
+	return nil
+}
+
+// TLSConfig returns the TLS config trusting the CA certificates in
+// caFile, in addition to the system's, and presenting the client
+// certificate in certFile and keyFile.  Any of the files may be empty.
+func TLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "loading client certificate")
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package endpoint_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/endpoint"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// newServer returns a server which replaces Deployment with StatefulSet
+// in the ResourceLists POSTed to it, if they're authorized, and redirects
+// requests to /redirect to the URL in their "to" parameter.
+func newServer(t *testing.T) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/redirect") {
+			http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusTemporaryRedirect)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/yaml" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		b, err := ioutil.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if strings.Contains(string(b), "slow") {
+			time.Sleep(time.Second)
+		}
+		_, err = w.Write(bytes.ReplaceAll(b, []byte("Deployment"), []byte("StatefulSet")))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestFilter_Filter(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	var tests = []struct {
+		name           string
+		input          string
+		expectedOutput string
+		expectedError  string
+		instance       endpoint.Filter
+	}{
+		{
+			name: "transform",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedOutput: `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: foo
+  annotations:
+    config.kubernetes.io/path: 'statefulset_foo.yaml'
+`,
+			instance: endpoint.Filter{
+				URL:         server.URL,
+				Headers:     map[string]string{"Authorization": "Bearer ${ENDPOINT_TEST_TOKEN}"},
+				URLPrefixes: []string{server.URL},
+				TLSConfig:   tlsConfig,
+			},
+		},
+		{
+			name: "unauthorized",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "function endpoint " + server.URL + " responded 401 Unauthorized: not authorized",
+			instance: endpoint.Filter{
+				URL:       server.URL,
+				TLSConfig: tlsConfig,
+			},
+		},
+		{
+			name: "untrusted",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "x509",
+			instance: endpoint.Filter{
+				URL:         server.URL,
+				Headers:     map[string]string{"Authorization": "Bearer s3cret"},
+				URLPrefixes: []string{server.URL},
+			},
+		},
+		{
+			name: "timeout",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: slow
+`,
+			expectedError: "Client.Timeout",
+			instance: endpoint.Filter{
+				URL:         server.URL,
+				Headers:     map[string]string{"Authorization": "Bearer s3cret"},
+				URLPrefixes: []string{server.URL},
+				Timeout:     100 * time.Millisecond,
+				TLSConfig:   tlsConfig,
+			},
+		},
+		{
+			name: "headers without URL prefixes",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "function endpoint " + server.URL +
+				" can't be sent headers without URL prefixes",
+			instance: endpoint.Filter{
+				URL:       server.URL,
+				Headers:   map[string]string{"Authorization": "Bearer s3cret"},
+				TLSConfig: tlsConfig,
+			},
+		},
+		{
+			name: "not matching URL prefixes",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "function endpoint " + server.URL +
+				" doesn't match any of the URL prefixes [https://fn.example.com/]",
+			instance: endpoint.Filter{
+				URL:         server.URL,
+				Headers:     map[string]string{"Authorization": "Bearer s3cret"},
+				URLPrefixes: []string{"https://fn.example.com/"},
+				TLSConfig:   tlsConfig,
+			},
+		},
+		{
+			name: "http",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "function endpoint http://example.com must use https",
+			instance:      endpoint.Filter{URL: "http://example.com"},
+		},
+		{
+			name: "redirect not matching URL prefixes",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "function endpoint " + server.URL +
+				"/other doesn't match any of the URL prefixes [" + server.URL + "/fn/]",
+			instance: endpoint.Filter{
+				URL:         server.URL + "/fn/redirect?to=" + server.URL + "/other",
+				Headers:     map[string]string{"Authorization": "Bearer s3cret"},
+				URLPrefixes: []string{server.URL + "/fn/"},
+				TLSConfig:   tlsConfig,
+			},
+		},
+		{
+			name: "redirect to http",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "function endpoint http://example.com must use https",
+			instance: endpoint.Filter{
+				URL:       server.URL + "/redirect?to=http://example.com",
+				TLSConfig: tlsConfig,
+			},
+		},
+		{
+			name: "response too large",
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedError: "function endpoint " + server.URL + " responded with more than 10 bytes",
+			instance: endpoint.Filter{
+				URL:             server.URL,
+				Headers:         map[string]string{"Authorization": "Bearer s3cret"},
+				URLPrefixes:     []string{server.URL},
+				MaxResponseSize: 10,
+				TLSConfig:       tlsConfig,
+			},
+		},
+	}
+
+	os.Setenv("ENDPOINT_TEST_TOKEN", "s3cret")
+	defer os.Unsetenv("ENDPOINT_TEST_TOKEN")
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := yaml.Parse(tt.input)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			output, err := tt.instance.Filter([]*yaml.RNode{input})
+			if tt.expectedError != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			if !assert.NoError(t, err) || !assert.Len(t, output, 1) {
+				t.FailNow()
+			}
+			assert.Equal(t, tt.expectedOutput, output[0].MustString())
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	config, err := endpoint.TLSConfig("", "", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Nil(t, config.RootCAs)
+
+	f, err := ioutil.TempFile("", "ca")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	_, err = endpoint.TLSConfig(f.Name(), "", "")
+	assert.EqualError(t, err, "no certificates found in "+f.Name())
+}
+
+func TestFilter_Check(t *testing.T) {
+	var tests = []struct {
+		url      string
+		prefixes []string
+		allowed  bool
+	}{
+		{url: "https://fn.example.com/set-labels", allowed: true},
+		{url: "https://fn.example.com/set-labels", prefixes: []string{"https://fn.example.com"},
+			allowed: true},
+		{url: "https://FN.example.com/fns/set-labels", prefixes: []string{"https://fn.example.com/fns/"},
+			allowed: true},
+		{url: "https://fn.example.com/fns", prefixes: []string{"https://fn.example.com/fns"},
+			allowed: true},
+		{url: "https://fn.example.com/fns-other/set-labels", prefixes: []string{"https://fn.example.com/fns"}},
+		{url: "https://fn.example.com.other.org/set-labels", prefixes: []string{"https://fn.example.com"}},
+		{url: "https://fn.example.com@other.org/set-labels", prefixes: []string{"https://fn.example.com"}},
+		{url: "https://fn.example.com:8443/set-labels", prefixes: []string{"https://fn.example.com"}},
+		{url: "https://other.org/set-labels",
+			prefixes: []string{"https://fn.example.com", "https://other.org/"}, allowed: true},
+	}
+	for _, tt := range tests {
+		instance := endpoint.Filter{URL: tt.url, URLPrefixes: tt.prefixes}
+		err := instance.Check()
+		if tt.allowed {
+			assert.NoError(t, err, tt.url)
+		} else {
+			assert.Error(t, err, tt.url)
+		}
+	}
+}
+
+func TestFilter_String(t *testing.T) {
+	instance := endpoint.Filter{URL: "https://fn.example.com/set-labels"}
+	assert.Equal(t, "url: https://fn.example.com/set-labels", instance.String())
+}
@@ -2,4 +2,10 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Package exec contains the exec function implementation.
+//
+// Filter runs a local executable as a function, with the same ResourceList
+// contract, scoping and results as functions run in containers, so functions
+// may be developed and run without building images.  container.Filter is
+// implemented on top of it, running the container runtime's cli, and
+// kio/filters.ExecFilter exposes it alongside the other kio filters.
 package exec
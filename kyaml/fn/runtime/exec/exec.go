@@ -4,6 +4,7 @@
 package exec
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
@@ -22,6 +23,10 @@ type Filter struct {
 	runtimeutil.FunctionFilter
 }
 
+func (c *Filter) String() string {
+	return fmt.Sprintf("path: %v args: %v", c.Path, c.Args)
+}
+
 func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	c.FunctionFilter.Run = c.Run
 	return c.FunctionFilter.Filter(nodes)
@@ -110,3 +110,8 @@ metadata:
 		})
 	}
 }
+
+func TestFilter_String(t *testing.T) {
+	instance := exec.Filter{Path: "sed", Args: []string{"s/Deployment/StatefulSet/g"}}
+	assert.Equal(t, "path: sed args: [s/Deployment/StatefulSet/g]", instance.String())
+}
@@ -0,0 +1,33 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package grpc contains a kio.Filter which runs a function served over
+// gRPC by a long-running server, so pipelines running the same function
+// many times start it only once rather than once per invocation.
+//
+// A Server is started once, either as a container or as a local
+// executable, and listens on the unix socket in the AddressEnvName
+// environment variable.  The socket is in a directory created for the
+// server and mounted into its container, so containers are run with the
+// same options as container functions, without a network unless one is
+// set.  Each Filter run by the server sends its ResourceList in a unary
+// call of the FunctionService over plaintext HTTP/2, with the same
+// scoping and results as functions run in containers, and reads the
+// transformed ResourceList from the response:
+//
+//	syntax = "proto3";
+//	package kustomize.fn.v1;
+//
+//	message ResourceList {
+//	  // yaml is the ResourceList serialized as YAML
+//	  bytes yaml = 1;
+//	}
+//
+//	service FunctionService {
+//	  rpc Run(ResourceList) returns (ResourceList);
+//	}
+//
+// Calls which fail with a non-zero grpc-status fail the function with
+// the grpc-message.  Serve serves a function this way, e.g. the Execute
+// of a framework command.
+package grpc
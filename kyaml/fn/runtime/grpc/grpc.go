@@ -0,0 +1,113 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter runs a function served by a Server, which is started once and
+// reused by all the Filters it serves.
+type Filter struct {
+	// Server serves the function.  It must be started.
+	Server *Server
+
+	// Timeout, if set, is how long the server may take to respond.
+	Timeout time.Duration
+
+	runtimeutil.FunctionFilter
+}
+
+func (c *Filter) String() string {
+	return fmt.Sprintf("grpc: %v", c.Server)
+}
+
+func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	c.FunctionFilter.Run = c.Run
+	return c.FunctionFilter.Filter(nodes)
+}
+
+// Run calls the server with the ResourceList read from reader, and
+// writes the ResourceList of the response to writer.
+func (c *Filter) Run(reader io.Reader, writer io.Writer) error {
+	input, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	body := &bytes.Buffer{}
+	if err := writeMessage(body, input); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, "http://"+host+RunPath, body)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("TE", "trailers")
+	resp, err := c.Server.client.Do(req)
+	if err != nil {
+		return errors.WrapPrefixf(err, "function server %s", c.Server)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("function server %s responded %s", c.Server, resp.Status)
+	}
+
+	// the message is only read if the call succeeds, and the status is
+	// only known once the body is read
+	output, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WrapPrefixf(err, "function server %s", c.Server)
+	}
+	if err := status(resp); err != nil {
+		return errors.WrapPrefixf(err, "function server %s", c.Server)
+	}
+	output, err = readMessage(bytes.NewReader(output))
+	if err != nil {
+		return errors.WrapPrefixf(err, "function server %s", c.Server)
+	}
+	_, err = writer.Write(output)
+	return errors.Wrap(err)
+}
+
+// status returns the error of a call which didn't succeed.  Responses
+// without a message have the status in their headers rather than their
+// trailers.
+func status(resp *http.Response) error {
+	header := resp.Trailer
+	if header.Get("Grpc-Status") == "" {
+		header = resp.Header
+	}
+	code := header.Get("Grpc-Status")
+	switch code {
+	case "0":
+		return nil
+	case "":
+		return errors.Errorf("no grpc-status in the response")
+	}
+	msg := header.Get("Grpc-Message")
+	if m, err := url.PathUnescape(msg); err == nil {
+		msg = m
+	}
+	return errors.Errorf("grpc-status %s: %s", code, msg)
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/grpc"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// serverEnvName is set to a file recording the starts of the test binary
+// run as a function server, which replaces Deployment with StatefulSet.
+const serverEnvName = "KYAML_GRPC_TEST_SERVER"
+
+func TestMain(m *testing.M) {
+	starts := os.Getenv(serverEnvName)
+	if starts == "" {
+		os.Exit(m.Run())
+	}
+	f, err := os.OpenFile(starts, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err == nil {
+		_, err = f.WriteString("started\n")
+		f.Close()
+	}
+	if err == nil {
+		err = grpc.Serve(func(reader io.Reader, writer io.Writer) error {
+			b, err := ioutil.ReadAll(reader)
+			if err != nil {
+				return err
+			}
+			if bytes.Contains(b, []byte("fail")) {
+				return errors.Errorf("failed: %s", "50% of the time")
+			}
+			_, err = writer.Write(bytes.ReplaceAll(b, []byte("Deployment"), []byte("StatefulSet")))
+			return err
+		})
+	}
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func run(t *testing.T, server *grpc.Server, input string) (string, error) {
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewBufferString(input)}).Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	instance := &grpc.Filter{Server: server}
+	output, err := instance.Filter(nodes)
+	if err != nil {
+		return "", err
+	}
+	b := &bytes.Buffer{}
+	if !assert.NoError(t, kio.ByteWriter{Writer: b}.Write(output)) {
+		t.FailNow()
+	}
+	return b.String(), nil
+}
+
+func TestFilter_Filter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kyaml-grpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	starts := filepath.Join(dir, "starts")
+	os.Setenv(serverEnvName, starts)
+	defer os.Unsetenv(serverEnvName)
+
+	servers := &grpc.Servers{}
+	defer servers.Close()
+	server, err := servers.Get(container.Filter{}, os.Args[0])
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	for _, name := range []string{"foo", "bar"} {
+		// the server is started once, and reused
+		s, err := servers.Get(container.Filter{}, os.Args[0])
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Same(t, server, s)
+
+		output, err := run(t, s, fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+`, name))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, fmt.Sprintf(`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: %s
+  annotations:
+    config.kubernetes.io/path: 'statefulset_%s.yaml'
+`, name, name), output)
+	}
+	b, err := ioutil.ReadFile(starts)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 1, strings.Count(string(b), "started"))
+
+	// the error of the function fails the Filter
+	_, err = run(t, server, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: fail
+`)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "grpc-status 2: failed: 50% of the time")
+	}
+
+	// the server is stopped once closed
+	assert.NoError(t, servers.Close())
+	_, err = run(t, server, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`)
+	assert.Error(t, err)
+}
+
+func TestServer_Start(t *testing.T) {
+	server := &grpc.Server{Path: "false"}
+	err := server.Start()
+	if assert.Error(t, err) {
+		assert.Equal(t, "function server false exited before serving", err.Error())
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const (
+	// AddressEnvName is the environment variable set to the address a
+	// function server listens on, e.g. unix:///var/run/kustomize-fn/fn.sock,
+	// or a TCP address such as 127.0.0.1:9446.
+	AddressEnvName = "KUSTOMIZE_FN_GRPC_ADDRESS"
+
+	// RunPath is the path of the Run method of the FunctionService.
+	RunPath = "/kustomize.fn.v1.FunctionService/Run"
+
+	contentType = "application/grpc"
+
+	// host is the host of the requests to servers, which are
+	// sent to their socket whatever the host.
+	host = "function"
+
+	// the gRPC status codes used by Handler
+	statusOK            = 0
+	statusUnknown       = 2
+	statusInvalid       = 3
+	statusUnimplemented = 12
+)
+
+// Serve serves fn as a function, over gRPC on the address in the
+// AddressEnvName environment variable, until it fails.
+func Serve(fn func(reader io.Reader, writer io.Writer) error) error {
+	address := os.Getenv(AddressEnvName)
+	if address == "" {
+		return errors.Errorf("%s must be set to the address to serve on", AddressEnvName)
+	}
+	handler := h2c.NewHandler(Handler(fn), &http2.Server{})
+	if !strings.HasPrefix(address, "unix://") {
+		return errors.Wrap(http.ListenAndServe(address, handler))
+	}
+	socket := strings.TrimPrefix(address, "unix://")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer l.Close()
+	// the user of the client may not be the user of the server,
+	// e.g. when it runs in a container as nobody
+	if err := os.Chmod(socket, 0666); err != nil {
+		return errors.Wrap(err)
+	}
+	return errors.Wrap(http.Serve(l, handler))
+}
+
+// Handler returns the http.Handler running fn for each call of the Run
+// method of the FunctionService.  It must be served over HTTP/2.
+func Handler(fn func(reader io.Reader, writer io.Writer) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if r.URL.Path != RunPath {
+			writeStatus(w, "", statusUnimplemented, "unknown method "+r.URL.Path)
+			return
+		}
+		input, err := readMessage(r.Body)
+		if err != nil {
+			writeStatus(w, "", statusInvalid, err.Error())
+			return
+		}
+		output := &bytes.Buffer{}
+		if err := fn(bytes.NewReader(input), output); err != nil {
+			writeStatus(w, "", statusUnknown, err.Error())
+			return
+		}
+		if err := writeMessage(w, output.Bytes()); err != nil {
+			return
+		}
+		writeStatus(w, http.TrailerPrefix, statusOK, "")
+	})
+}
+
+// writeStatus writes the status of a call, as trailers if prefix is
+// http.TrailerPrefix, or as headers of a response without a message.
+func writeStatus(w http.ResponseWriter, prefix string, code int, msg string) {
+	w.Header().Set(prefix+"Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set(prefix+"Grpc-Message", url.PathEscape(msg))
+	}
+}
+
+// writeMessage writes a length-prefixed ResourceList message holding
+// the ResourceList yaml.
+func writeMessage(w io.Writer, yaml []byte) error {
+	// the ResourceList message only has the bytes field 1
+	field := make([]byte, 1+binary.MaxVarintLen64)
+	field[0] = 1<<3 | 2
+	field = field[:1+binary.PutUvarint(field[1:], uint64(len(yaml)))]
+
+	prefix := make([]byte, 5) // uncompressed, followed by the length
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(field)+len(yaml)))
+	for _, b := range [][]byte{prefix, field, yaml} {
+		if _, err := w.Write(b); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// readMessage reads a length-prefixed ResourceList message, and returns
+// its ResourceList yaml.
+func readMessage(r io.Reader) ([]byte, error) {
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, errors.WrapPrefixf(err, "reading message")
+	}
+	if prefix[0] != 0 {
+		return nil, errors.Errorf("compressed messages aren't supported")
+	}
+	msg := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, errors.WrapPrefixf(err, "reading message")
+	}
+
+	var yaml []byte
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return nil, errors.Errorf("invalid message")
+		}
+		msg = msg[n:]
+		switch key & 7 {
+		case 0: // varint
+			_, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return nil, errors.Errorf("invalid message")
+			}
+			msg = msg[n:]
+		case 1: // fixed64
+			if len(msg) < 8 {
+				return nil, errors.Errorf("invalid message")
+			}
+			msg = msg[8:]
+		case 2: // bytes
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < l {
+				return nil, errors.Errorf("invalid message")
+			}
+			if key>>3 == 1 {
+				yaml = msg[n : n+int(l)]
+			}
+			msg = msg[n+int(l):]
+		case 5: // fixed32
+			if len(msg) < 4 {
+				return nil, errors.Errorf("invalid message")
+			}
+			msg = msg[4:]
+		default:
+			return nil, errors.Errorf("invalid message")
+		}
+	}
+	return yaml, nil
+}
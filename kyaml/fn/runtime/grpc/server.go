@@ -0,0 +1,281 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+const (
+	// socketName is the name of the socket servers listen on, in the
+	// directory created for them.
+	socketName = "fn.sock"
+
+	// containerSocketDir is where the socket directory is mounted in
+	// the containers of servers.
+	containerSocketDir = "/var/run/kustomize-fn"
+)
+
+// Server is a function server, started once and then run by any
+// number of Filters until it's closed.
+//
+// Servers listen on a unix socket in a directory created for them, which
+// only the user starting them may reach, rather than on a port, so
+// servers run as containers don't need a network.
+type Server struct {
+	// Container runs the server as a container, if its Image is set,
+	// with the same options as container functions: the network, which
+	// is none unless set, the user nobody, the pull policy, platform,
+	// limits and mounts, and the exported environment.
+	Container container.Filter
+
+	// Path is the executable of the server, if it's run locally.
+	Path string
+
+	// StartTimeout is how long the server may take to start serving.
+	// Defaults to 30 seconds.
+	StartTimeout time.Duration
+
+	dir         string
+	client      *http.Client
+	runtime     container.Runtime
+	containerID string
+	cmd         *exec.Cmd
+	exited      chan struct{}
+}
+
+func (s *Server) String() string {
+	if s.Container.Image != "" {
+		return s.Container.Image
+	}
+	return s.Path
+}
+
+// Start starts the server, and waits until it's serving.
+func (s *Server) Start() error {
+	if err := s.makeSocketDir(); err != nil {
+		return err
+	}
+	socket := filepath.Join(s.socketDir(), socketName)
+	s.client = &http.Client{Transport: &http2.Transport{
+		// the socket is local, so the calls needn't be encrypted
+		AllowHTTP: true,
+		DialTLS: func(_, _ string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
+	}}
+
+	if s.Container.Image != "" {
+		if err := s.startContainer(); err != nil {
+			_ = s.Close()
+			return err
+		}
+	} else {
+		s.cmd = exec.Command(s.Path)
+		s.cmd.Env = append(os.Environ(), AddressEnvName+"=unix://"+socket)
+		s.cmd.Stdout = os.Stderr
+		s.cmd.Stderr = os.Stderr
+		if err := s.cmd.Start(); err != nil {
+			_ = s.Close()
+			return errors.WrapPrefixf(err, "starting function server %s", s)
+		}
+		s.exited = make(chan struct{})
+		go func() {
+			_ = s.cmd.Wait()
+			close(s.exited)
+		}()
+	}
+	if err := s.waitUntilServing(); err != nil {
+		_ = s.Close()
+		return err
+	}
+	return nil
+}
+
+// makeSocketDir creates the directory of the socket.  It's writable by
+// anyone, as the user of a container may not be the user starting it,
+// but only reachable by the user starting it, through its parent.
+func (s *Server) makeSocketDir() error {
+	dir, err := ioutil.TempDir("", "kustomize-fn-grpc-")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	s.dir = dir
+	if err := os.Mkdir(s.socketDir(), 0777); err != nil {
+		return errors.Wrap(err)
+	}
+	// the mode of Mkdir is masked by the umask
+	return errors.Wrap(os.Chmod(s.socketDir(), 0777))
+}
+
+func (s *Server) socketDir() string {
+	return filepath.Join(s.dir, "socket")
+}
+
+// startContainer runs the container of the server detached.
+func (s *Server) startContainer() error {
+	runtime, args, err := s.containerCommand()
+	if err != nil {
+		return err
+	}
+	out, err := exec.Command(string(runtime), args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return errors.Errorf("starting function server %s: %v: %s",
+				s, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return errors.WrapPrefixf(err, "starting function server %s", s)
+	}
+	s.runtime = runtime
+	s.containerID = strings.TrimSpace(string(out))
+	return nil
+}
+
+// containerCommand returns the runtime and the arguments running the
+// container of the server, with the socket directory mounted.
+func (s *Server) containerCommand() (container.Runtime, []string, error) {
+	runtime, err := container.GetRuntime(string(s.Container.Runtime))
+	if err != nil {
+		return "", nil, err
+	}
+	c := s.Container
+	c.StorageMounts = append(append([]runtimeutil.StorageMount{},
+		c.StorageMounts...), runtimeutil.StorageMount{
+		MountType:     "bind",
+		Src:           s.socketDir(),
+		DstPath:       containerSocketDir,
+		ReadWriteMode: true,
+	})
+	options, err := c.RunArgs()
+	if err != nil {
+		return "", nil, err
+	}
+	args := append([]string{"run", "--rm", "--detach"}, options...)
+	args = append(args,
+		"--env", AddressEnvName+"=unix://"+path.Join(containerSocketDir, socketName),
+		c.Image)
+	return runtime, args, nil
+}
+
+// waitUntilServing waits until the server responds to a request.
+func (s *Server) waitUntilServing() error {
+	timeout := s.StartTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host, nil)
+		if err != nil {
+			cancel()
+			return errors.Wrap(err)
+		}
+		resp, err := s.client.Do(req)
+		cancel()
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		select {
+		case <-s.exited:
+			return errors.Errorf("function server %s exited before serving", s)
+		default:
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("function server %s isn't serving after %v: %v", s, timeout, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	if s.client != nil {
+		s.client.Transport.(*http2.Transport).CloseIdleConnections()
+	}
+	var err error
+	switch {
+	case s.containerID != "":
+		out, rmErr := exec.Command(string(s.runtime), "rm", "--force", s.containerID).CombinedOutput()
+		if rmErr != nil {
+			err = errors.Errorf("stopping function server %s: %v: %s",
+				s, rmErr, strings.TrimSpace(string(out)))
+		}
+	case s.cmd != nil && s.cmd.Process != nil:
+		_ = s.cmd.Process.Kill()
+		<-s.exited
+	}
+	if s.dir != "" {
+		_ = os.RemoveAll(s.dir)
+	}
+	return err
+}
+
+// Servers starts each function server once, when it's first needed,
+// so all the Filters of a pipeline running it share the same process.
+type Servers struct {
+	mu      sync.Mutex
+	servers map[serverKey]*Server
+}
+
+// serverKey identifies the server of Servers running an image on a
+// network, or an executable.
+type serverKey struct{ image, network, path string }
+
+// Get returns the server running the container c, or the executable at
+// path if the Image of c is empty, starting it if it isn't running yet.
+func (s *Servers) Get(c container.Filter, path string) (*Server, error) {
+	key := serverKey{image: c.Image, network: c.Network}
+	if c.Image == "" {
+		key = serverKey{path: path}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if server, ok := s.servers[key]; ok {
+		return server, nil
+	}
+	server := &Server{Path: key.path}
+	if c.Image != "" {
+		server.Container = c
+	}
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+	if s.servers == nil {
+		s.servers = map[serverKey]*Server{}
+	}
+	s.servers[key] = server
+	return server, nil
+}
+
+// Close stops all the servers.
+func (s *Servers) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result error
+	for key, server := range s.servers {
+		if err := server.Close(); err != nil && result == nil {
+			result = err
+		}
+		delete(s.servers, key)
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+)
+
+func TestServer_containerCommand(t *testing.T) {
+	s := &Server{
+		Container: container.Filter{
+			Image:           "example.com/fn:v1",
+			Runtime:         container.Podman,
+			ImagePullPolicy: container.PullNever,
+			Platform:        "linux/amd64",
+			Memory:          "512m",
+			CPUs:            "1",
+		},
+		dir: "/tmp/fn",
+	}
+	runtime, args, err := s.containerCommand()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, container.Podman, runtime)
+	command := strings.Join(args, " ")
+
+	// the server is run like a container function, without a network
+	for _, arg := range []string{
+		"run --rm --detach --network none --user nobody --security-opt=no-new-privileges",
+		"--pull never",
+		"--platform linux/amd64",
+		"--memory 512m",
+		"--cpus 1",
+		"--mount type=bind,source=/tmp/fn/socket,target=/var/run/kustomize-fn",
+		"--env " + AddressEnvName + "=unix:///var/run/kustomize-fn/fn.sock example.com/fn:v1",
+	} {
+		assert.Contains(t, command, arg)
+	}
+	assert.NotContains(t, command, "--publish")
+	assert.True(t, strings.HasSuffix(command, " example.com/fn:v1"))
+
+	// the server is given the network it's set to
+	s.Container.Network = "host"
+	_, args, err = s.containerCommand()
+	if assert.NoError(t, err) {
+		assert.Contains(t, strings.Join(args, " "), "--network host")
+	}
+}
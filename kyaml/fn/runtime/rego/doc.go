@@ -0,0 +1,23 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rego contains a kio.Filter which evaluates Rego policies
+// against resources with the opa program, and reports the violations
+// as the results of the ResourceList, without changing the resources.
+//
+// Policies may be Rego modules, or Gatekeeper ConstraintTemplates
+// holding them.  Each resource is evaluated on its own, and two kinds
+// of rules are supported:
+//
+//   - Gatekeeper style "violation" rules, which see the resource as
+//     input.review.object, and the parameters of the constraint as
+//     input.parameters.  Their results are objects with a "msg".
+//   - conftest style "deny" rules, which see the resource as input.
+//     Their results are messages.
+//
+// The functionConfig may be a Gatekeeper constraint: its
+// spec.parameters are passed to the policies, its spec.match.kinds,
+// spec.match.namespaces and spec.match.excludedNamespaces select the
+// resources evaluated, and a spec.enforcementAction of "warn" or
+// "dryrun" reports the violations as warnings rather than failing.
+package rego
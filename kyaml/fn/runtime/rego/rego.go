@@ -0,0 +1,443 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rego
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter evaluates Rego policies against resources.
+type Filter struct {
+	Name string
+
+	// Path is the path to a Rego module or Gatekeeper
+	// ConstraintTemplate, or to a directory of them.
+	Path string
+
+	// Program is a Rego module to evaluate.
+	Program string
+
+	runtimeutil.FunctionFilter
+}
+
+func (rf *Filter) String() string {
+	return fmt.Sprintf("name: %v path: %v program: %v", rf.Name, rf.Path, rf.Program)
+}
+
+func (rf *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	rf.FunctionFilter.Run = rf.Run
+	return rf.FunctionFilter.Filter(nodes)
+}
+
+// Run reads a ResourceList from reader, and writes it to
+// writer unchanged but for its results.  It returns the
+// results as an error if any violation is an error.
+func (rf *Filter) Run(reader io.Reader, writer io.Writer) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	rl, err := yaml.Parse(string(b))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	items, err := rl.Pipe(yaml.Lookup("items"))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	var resources []*yaml.RNode
+	if items != nil {
+		resources, err = items.Elements()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	c, err := readConstraint(rl)
+	if err != nil {
+		return err
+	}
+	modules, err := rf.modules()
+	if err != nil {
+		return err
+	}
+
+	var reviews []map[string]interface{}
+	var evaluated []*yaml.RNode
+	for i := range resources {
+		meta, err := resources[i].GetMeta()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if !c.matches(meta) {
+			continue
+		}
+		r, err := makeReview(resources[i], meta)
+		if err != nil {
+			return err
+		}
+		reviews = append(reviews, r)
+		evaluated = append(evaluated, resources[i])
+	}
+	violations, err := evaluate(modules, reviews, c.Spec.Parameters)
+	if err != nil {
+		return err
+	}
+
+	result := framework.Result{Name: rf.Name}
+	if result.Name == "" {
+		result.Name = "rego"
+	}
+	severity := framework.Error
+	if c.Spec.EnforcementAction == "warn" || c.Spec.EnforcementAction == "dryrun" {
+		severity = framework.Warning
+	}
+	for _, v := range violations {
+		if v.Index < 0 || v.Index >= len(evaluated) {
+			return errors.Errorf("violation of unknown resource %d", v.Index)
+		}
+		meta, _ := evaluated[v.Index].GetMeta()
+		item := framework.Item{
+			Message:     fmt.Sprintf("[%s] %s", v.Policy, v.Msg),
+			Severity:    severity,
+			ResourceRef: meta,
+		}
+		item.ResourceRef.Annotations = nil
+		item.ResourceRef.Labels = nil
+		item.File.Path = meta.Annotations[kioutil.PathAnnotation]
+		item.File.Index, _ = strconv.Atoi(meta.Annotations[kioutil.IndexAnnotation])
+		result.Items = append(result.Items, item)
+	}
+	if len(result.Items) > 0 {
+		rb, err := yaml.Marshal(result)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		results, err := yaml.Parse(string(rb))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if err = rl.PipeE(yaml.SetField("results", results)); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	s, err := rl.String()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if _, err = io.WriteString(writer, s); err != nil {
+		return errors.Wrap(err)
+	}
+	if result.ExitCode() != 0 {
+		return result
+	}
+	return nil
+}
+
+// constraint holds the parts of a Gatekeeper
+// constraint used as functionConfig.
+type constraint struct {
+	Spec struct {
+		EnforcementAction string                 `yaml:"enforcementAction,omitempty"`
+		Parameters        map[string]interface{} `yaml:"parameters,omitempty"`
+		Match             struct {
+			Kinds []struct {
+				APIGroups []string `yaml:"apiGroups,omitempty"`
+				Kinds     []string `yaml:"kinds,omitempty"`
+			} `yaml:"kinds,omitempty"`
+			Namespaces         []string `yaml:"namespaces,omitempty"`
+			ExcludedNamespaces []string `yaml:"excludedNamespaces,omitempty"`
+		} `yaml:"match,omitempty"`
+	} `yaml:"spec,omitempty"`
+}
+
+func readConstraint(rl *yaml.RNode) (*constraint, error) {
+	c := &constraint{}
+	fc, err := rl.Pipe(yaml.Lookup("functionConfig"))
+	if err != nil || fc == nil {
+		return c, errors.Wrap(err)
+	}
+	s, err := fc.String()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err = yaml.Unmarshal([]byte(s), c); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return c, nil
+}
+
+// matches returns true if the resource is selected
+// by the match of the constraint.
+func (c *constraint) matches(meta yaml.ResourceMeta) bool {
+	m := c.Spec.Match
+	if len(m.Namespaces) > 0 && !contains(m.Namespaces, meta.Namespace) {
+		return false
+	}
+	if contains(m.ExcludedNamespaces, meta.Namespace) {
+		return false
+	}
+	if len(m.Kinds) == 0 {
+		return true
+	}
+	group, _ := splitAPIVersion(meta.APIVersion)
+	for _, k := range m.Kinds {
+		if (contains(k.APIGroups, "*") || contains(k.APIGroups, group)) &&
+			(contains(k.Kinds, "*") || contains(k.Kinds, meta.Kind)) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func splitAPIVersion(apiVersion string) (group, version string) {
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		return apiVersion[:i], apiVersion[i+1:]
+	}
+	return "", apiVersion
+}
+
+// makeReview returns the resource as Gatekeeper
+// presents it to policies, as input.review.
+func makeReview(n *yaml.RNode, meta yaml.ResourceMeta) (map[string]interface{}, error) {
+	b, err := n.MarshalJSON()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var obj map[string]interface{}
+	if err = json.Unmarshal(b, &obj); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	group, version := splitAPIVersion(meta.APIVersion)
+	return map[string]interface{}{
+		"kind": map[string]interface{}{
+			"group": group, "version": version, "kind": meta.Kind,
+		},
+		"name":      meta.Name,
+		"namespace": meta.Namespace,
+		"operation": "CREATE",
+		"object":    obj,
+	}, nil
+}
+
+// modules returns the Rego modules of the filter.
+func (rf *Filter) modules() ([]string, error) {
+	var modules []string
+	if rf.Program != "" {
+		modules = append(modules, rf.Program)
+	}
+	if rf.Path == "" {
+		return modules, nil
+	}
+	err := filepath.Walk(rf.Path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch filepath.Ext(p) {
+		case ".rego":
+			b, err := ioutil.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			modules = append(modules, string(b))
+		case ".yaml", ".yml":
+			m, err := templateModules(p)
+			if err != nil {
+				return err
+			}
+			modules = append(modules, m...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if len(modules) == 0 {
+		return nil, errors.Errorf("no rego policies found in %s", rf.Path)
+	}
+	return modules, nil
+}
+
+// templateModules returns the Rego modules of the Gatekeeper
+// ConstraintTemplates in the file p.  Other files are ignored.
+func templateModules(p string) ([]string, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var modules []string
+	for _, doc := range strings.Split(string(b), "\n---") {
+		var t struct {
+			Kind string `yaml:"kind"`
+			Spec struct {
+				Targets []struct {
+					Rego string   `yaml:"rego"`
+					Libs []string `yaml:"libs"`
+				} `yaml:"targets"`
+			} `yaml:"spec"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &t); err != nil || t.Kind != "ConstraintTemplate" {
+			continue
+		}
+		for _, target := range t.Spec.Targets {
+			if target.Rego != "" {
+				modules = append(modules, target.Rego)
+			}
+			modules = append(modules, target.Libs...)
+		}
+	}
+	return modules, nil
+}
+
+var packageClause = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)`)
+
+// wrapperPackage is the package of the module evaluating
+// the rules of the policies on each resource.
+const wrapperPackage = "kustomize_rego_filter"
+
+// wrapperModule returns a module collecting the
+// violations of the policies in the given packages.
+func wrapperModule(packages []string) string {
+	var b strings.Builder
+	b.WriteString("package " + wrapperPackage + "\n")
+	for _, p := range packages {
+		fmt.Fprintf(&b, `
+violations[v] {
+	review := input.reviews[i]
+	r := data.%[1]s.violation[_] with input as {"review": review, "parameters": input.parameters}
+	v := {"index": i, "policy": %[1]q, "msg": object.get(r, "msg", sprintf("%%v", [r]))}
+}
+
+violations[v] {
+	review := input.reviews[i]
+	msg := data.%[1]s.deny[_] with input as review.object
+	v := {"index": i, "policy": %[1]q, "msg": sprintf("%%v", [msg])}
+}
+`, p)
+	}
+	return b.String()
+}
+
+// violation is a violation reported by the wrapper module.
+type violation struct {
+	Index  int    `json:"index"`
+	Policy string `json:"policy"`
+	Msg    string `json:"msg"`
+}
+
+// evaluate runs opa, returning the violations of the
+// modules by the reviewed resources, in a stable order.
+func evaluate(
+	modules []string, reviews []map[string]interface{},
+	parameters map[string]interface{}) ([]violation, error) {
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+	opaProgram, err := exec.LookPath("opa")
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "no 'opa' program on path")
+	}
+	dir, err := ioutil.TempDir("", "kustomize-rego-")
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer os.RemoveAll(dir)
+
+	seen := map[string]bool{}
+	var packages []string
+	for i, m := range modules {
+		if match := packageClause.FindStringSubmatch(m); match != nil && !seen[match[1]] {
+			seen[match[1]] = true
+			packages = append(packages, match[1])
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("policy%d.rego", i)), []byte(m), 0600)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+	err = ioutil.WriteFile(
+		filepath.Join(dir, wrapperPackage+".rego"), []byte(wrapperModule(packages)), 0600)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	input, err := json.Marshal(map[string]interface{}{
+		"reviews":    reviews,
+		"parameters": parameters,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	cmd := exec.Command(
+		opaProgram, "eval", "--format", "json", "--stdin-input",
+		"--data", dir, "data."+wrapperPackage+".violations")
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "opa: %s %s",
+			strings.TrimSpace(stderr.String()), strings.TrimSpace(string(out)))
+	}
+	return parseViolations(out)
+}
+
+// parseViolations returns the violations in
+// the output of "opa eval --format json".
+func parseViolations(out []byte) ([]violation, error) {
+	var r struct {
+		Result []struct {
+			Expressions []struct {
+				Value []violation `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out, &r); err != nil {
+		return nil, errors.WrapPrefixf(err, "parsing opa output")
+	}
+	var violations []violation
+	for _, result := range r.Result {
+		for _, e := range result.Expressions {
+			violations = append(violations, e.Value...)
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Index != violations[j].Index {
+			return violations[i].Index < violations[j].Index
+		}
+		if violations[i].Policy != violations[j].Policy {
+			return violations[i].Policy < violations[j].Policy
+		}
+		return violations[i].Msg < violations[j].Msg
+	})
+	return violations, nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rego
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const input = `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: web
+    namespace: prod
+    annotations:
+      config.kubernetes.io/path: web.yaml
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+    namespace: prod
+    annotations:
+      config.kubernetes.io/path: web.yaml
+      config.kubernetes.io/index: '1'
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+    namespace: kube-system
+functionConfig:
+  apiVersion: constraints.gatekeeper.sh/v1beta1
+  kind: K8sRequiredLabels
+  metadata:
+    name: must-have-owner
+  spec:
+    match:
+      kinds:
+      - apiGroups: ["", "apps"]
+        kinds: ["Deployment", "Service"]
+      excludedNamespaces: [kube-system]
+    parameters:
+      labels: [owner]
+`
+
+const policy = `package k8srequiredlabels
+
+violation[{"msg": msg}] {
+	required := input.parameters.labels[_]
+	not input.review.object.metadata.labels[required]
+	msg := sprintf("missing label %v", [required])
+}
+`
+
+// fakeOpa puts an opa program on the path which saves its
+// input in dir, and prints the violations of the service.
+func fakeOpa(t *testing.T, dir string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	script := `#!/bin/sh
+cat > ` + filepath.Join(dir, "input.json") + `
+echo "$@" > ` + filepath.Join(dir, "args") + `
+echo '{"result": [{"expressions": [{"value": [
+  {"index": 1, "policy": "k8srequiredlabels", "msg": "missing label owner"}
+]}]}]}'
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "opa"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestFilter_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-rego-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	fakeOpa(t, dir)
+
+	var out bytes.Buffer
+	rf := &Filter{Program: policy}
+	err = rf.Run(strings.NewReader(input), &out)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	result, ok := err.(framework.Result)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	assert.Equal(t, framework.Result{
+		Name: "rego",
+		Items: []framework.Item{{
+			Message:  "[k8srequiredlabels] missing label owner",
+			Severity: framework.Error,
+			ResourceRef: yaml.ResourceMeta{
+				APIVersion: "v1",
+				Kind:       "Service",
+				ObjectMeta: yaml.ObjectMeta{Name: "web", Namespace: "prod"},
+			},
+			File: framework.File{Path: "web.yaml", Index: 1},
+		}},
+	}, result)
+
+	// The resources pass through, with the results.
+	assert.Contains(t, out.String(), "kind: Deployment")
+	assert.Contains(t, out.String(), "namespace: kube-system")
+	assert.Contains(t, out.String(), "results:\n  name: rego\n  items:\n  - message: '[k8srequiredlabels] missing label owner'")
+
+	// The excluded namespace isn't evaluated, and the
+	// parameters are passed to the policies.
+	b, err := ioutil.ReadFile(filepath.Join(dir, "input.json"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, string(b), `"parameters":{"labels":["owner"]}`)
+	assert.Equal(t, 2, strings.Count(string(b), `"operation":"CREATE"`))
+	args, err := ioutil.ReadFile(filepath.Join(dir, "args"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, string(args), "eval --format json --stdin-input --data")
+}
+
+func TestFilter_Run_warn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-rego-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	fakeOpa(t, dir)
+
+	var out bytes.Buffer
+	rf := &Filter{Program: policy}
+	err = rf.Run(strings.NewReader(strings.Replace(
+		input, "  spec:\n", "  spec:\n    enforcementAction: warn\n", 1)), &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "severity: warning")
+}
+
+func TestFilter_Run_opa(t *testing.T) {
+	if _, err := exec.LookPath("opa"); err != nil {
+		t.Skip("no opa program on path")
+	}
+	var out bytes.Buffer
+	rf := &Filter{Program: policy}
+	err := rf.Run(strings.NewReader(input), &out)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, err.(framework.Result).Items, 2)
+}
+
+func TestTemplateModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-rego-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "template.yaml"), []byte(`
+apiVersion: templates.gatekeeper.sh/v1beta1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+spec:
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    rego: |
+      package k8srequiredlabels
+    libs:
+    - |
+      package lib.helpers
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "deny.rego"), []byte("package main\n"), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	modules, err := (&Filter{Path: dir}).modules()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{
+		"package main\n",
+		"package k8srequiredlabels\n",
+		"package lib.helpers",
+	}, modules)
+}
+
+func TestWrapperModule(t *testing.T) {
+	m := wrapperModule([]string{"k8srequiredlabels", "main"})
+	assert.Contains(t, m, "package kustomize_rego_filter\n")
+	assert.Contains(t, m, "data.k8srequiredlabels.violation[_] with input as")
+	assert.Contains(t, m, `"policy": "main", "msg": sprintf("%v", [msg])`)
+}
+
+func TestParseViolations(t *testing.T) {
+	v, err := parseViolations([]byte(`{"result": [{"expressions": [{"value": [
+  {"index": 2, "policy": "b", "msg": "x"},
+  {"index": 0, "policy": "b", "msg": "y"},
+  {"index": 0, "policy": "a", "msg": "z"}
+]}]}]}`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []violation{
+		{Index: 0, Policy: "a", Msg: "z"},
+		{Index: 0, Policy: "b", Msg: "y"},
+		{Index: 2, Policy: "b", Msg: "x"},
+	}, v)
+
+	v, err = parseViolations([]byte(`{}`))
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+}
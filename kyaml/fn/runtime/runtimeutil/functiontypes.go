@@ -34,8 +34,57 @@ type FunctionSpec struct {
 	// ExecSpec is the spec for running a function as an executable
 	Exec ExecSpec `json:"exec,omitempty" yaml:"exec,omitempty"`
 
+	// Rego is the spec for running a function evaluating rego policies
+	Rego RegoSpec `json:"rego,omitempty" yaml:"rego,omitempty"`
+
+	// Endpoint is the spec for running a function hosted as an HTTPS service
+	Endpoint EndpointSpec `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+
+	// GRPC is the spec for running a function served over gRPC by a
+	// server which is started once and reused
+	GRPC GRPCSpec `json:"grpc,omitempty" yaml:"grpc,omitempty"`
+
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+
+	// Selector, if set, scopes the function to the Resources it selects,
+	// rather than to those under the directory of the function
+	Selector *Selector `json:"selector,omitempty" yaml:"selector,omitempty"`
+}
+
+// Selector selects Resources by their type and metadata.  Empty fields
+// match any Resource.
+type Selector struct {
+	// APIVersion is the apiVersion of the Resources, e.g. apps/v1
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+
+	// Kind is the kind of the Resources, e.g. Deployment
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Labels are labels the Resources must have
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Annotations are annotations the Resources must have
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// Matches returns true if the Resource with meta is selected.
+func (s *Selector) Matches(meta yaml.ResourceMeta) bool {
+	if (s.APIVersion != "" && s.APIVersion != meta.APIVersion) ||
+		(s.Kind != "" && s.Kind != meta.Kind) {
+		return false
+	}
+	for k, v := range s.Labels {
+		if value, found := meta.Labels[k]; !found || value != v {
+			return false
+		}
+	}
+	for k, v := range s.Annotations {
+		if value, found := meta.Annotations[k]; !found || value != v {
+			return false
+		}
+	}
+	return true
 }
 
 type ExecSpec struct {
@@ -52,6 +101,15 @@ type ContainerSpec struct {
 
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+
+	// Memory and CPUs, if set, limit the memory and CPUs of the container,
+	// e.g. 512m and 1.5, overriding the defaults of the runner
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+	CPUs   string `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+
+	// Env are the environment variables the function needs, as KEY=VALUE,
+	// or KEY to export the local value of KEY
+	Env []string `json:"env,omitempty" yaml:"env,omitempty"`
 }
 
 // ContainerNetwork
@@ -69,6 +127,41 @@ type StarlarkSpec struct {
 
 	// URL specifies a url containing a starlark script
 	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Program specifies a starlark script embedded in the function config
+	Program string `json:"program,omitempty" yaml:"program,omitempty"`
+}
+
+// RegoSpec defines how to run a function evaluating rego policies
+// with the opa program
+type RegoSpec struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Path specifies a path to a rego module or Gatekeeper
+	// ConstraintTemplate, or to a directory of them
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Program specifies a rego module
+	Program string `json:"program,omitempty" yaml:"program,omitempty"`
+}
+
+// EndpointSpec defines how to run a function hosted as an HTTPS service
+type EndpointSpec struct {
+	// URL is the https URL the ResourceList is POSTed to
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// GRPCSpec defines how to start the server of a function served over gRPC
+type GRPCSpec struct {
+	// Image is the container image of the server
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// Network defines the network of the container of the server, which
+	// has none unless it's required
+	Network ContainerNetwork `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Path is the executable of the server, if it's run locally
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
 }
 
 // StorageMount represents a container's mounted storage option(s)
@@ -152,15 +245,27 @@ func getFunctionSpecFromAnnotation(n *yaml.RNode, meta yaml.ResourceMeta) *Funct
 	return &fs
 }
 
+// StringToStorageMount parses a mount of the form of docker's --mount flag,
+// e.g. type=bind,src=/tmp/charts,dst=/charts,rw=true.  Mounts are read-only
+// unless rw=true, or a bare rw option, or readonly=false (or ro=false).
 func StringToStorageMount(s string) StorageMount {
 	m := make(map[string]string)
 	options := strings.Split(s, ",")
 	for _, option := range options {
 		keyVal := strings.SplitN(option, "=", 2)
-		if len(keyVal) == 2 {
+		switch {
+		case len(keyVal) == 2:
 			m[keyVal[0]] = keyVal[1]
+		case option == "rw":
+			m["rw"] = "true"
 		}
 	}
+	if v, found := m["readonly"]; found {
+		m["ro"] = v
+	}
+	if m["ro"] == "false" || m["ro"] == "0" {
+		m["rw"] = "true"
+	}
 	var sm StorageMount
 	for key, value := range m {
 		switch {
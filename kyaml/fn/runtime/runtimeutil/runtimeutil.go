@@ -15,6 +15,7 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/log"
 
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -33,6 +34,10 @@ type FunctionFilter struct {
 	// resources scoped to it by path.
 	GlobalScope bool
 
+	// Selector, if set, scopes the function to the resources it selects rather than to
+	// those scoped to it by path, e.g. where resources aren't laid out by directory.
+	Selector *Selector
+
 	// ResultsFile is the file to write function ResourceList.results to.
 	// If unset, results will not be written.
 	ResultsFile string
@@ -55,6 +60,11 @@ func (c FunctionFilter) GetExit() error {
 	return c.exit
 }
 
+// GetResults returns the ResourceList.results emitted from Run
+func (c FunctionFilter) GetResults() *yaml.RNode {
+	return c.results
+}
+
 // functionsDirectoryName is keyword directory name for functions scoped 1 directory higher
 const functionsDirectoryName = "functions"
 
@@ -85,6 +95,9 @@ func (c *FunctionFilter) getFunctionScope() (string, error) {
 func (c *FunctionFilter) scope(dir string, nodes []*yaml.RNode) ([]*yaml.RNode, []*yaml.RNode, error) {
 	// scope container filtered Resources to Resources under that directory
 	var input, saved []*yaml.RNode
+	if c.Selector != nil {
+		return c.selectScope(nodes)
+	}
 	if c.GlobalScope {
 		return nodes, nil, nil
 	}
@@ -128,6 +141,47 @@ func (c *FunctionFilter) scope(dir string, nodes []*yaml.RNode) ([]*yaml.RNode,
 	return input, saved, nil
 }
 
+// selectScope partitions nodes into the Resources the Selector selects, and
+// the Resources it doesn't.
+func (c *FunctionFilter) selectScope(nodes []*yaml.RNode) ([]*yaml.RNode, []*yaml.RNode, error) {
+	var input, saved []*yaml.RNode
+	for i := range nodes {
+		m, err := nodes[i].GetMeta()
+		if err != nil {
+			return nil, nil, err
+		}
+		if c.Selector.Matches(m) {
+			input = append(input, nodes[i])
+		} else {
+			saved = append(saved, nodes[i])
+		}
+	}
+	return input, saved, nil
+}
+
+// FunctionScope returns the directory of the resources the function is
+// scoped to, or "" if it's scoped to all of them, or selects them.
+func (c *FunctionFilter) FunctionScope() (string, error) {
+	if c.GlobalScope || c.Selector != nil {
+		return "", nil
+	}
+	dir, err := c.getFunctionScope()
+	if err != nil || dir == "." {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Scope partitions nodes into the Resources scoped to the function,
+// and the Resources which are not.
+func (c *FunctionFilter) Scope(nodes []*yaml.RNode) ([]*yaml.RNode, []*yaml.RNode, error) {
+	functionDir, err := c.getFunctionScope()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.scope(functionDir, nodes)
+}
+
 func (c *FunctionFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	in := &bytes.Buffer{}
 	out := &bytes.Buffer{}
@@ -162,7 +216,11 @@ func (c *FunctionFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	r := &kio.ByteReader{Reader: out}
 
 	// don't exit immediately if the function fails -- write out the validation
+	log.Debug("running function", "scope", functionDir, "resources", len(input))
 	c.exit = c.Run(in, out)
+	if c.exit != nil {
+		log.Warn("function failed", "scope", functionDir, "error", c.exit)
+	}
 
 	output, err := r.Read()
 	if err != nil {
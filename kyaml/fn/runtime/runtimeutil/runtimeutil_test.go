@@ -647,6 +647,125 @@ metadata:
 			},
 		},
 
+		// verify the function only sees resources selected by its selector, regardless
+		// of the directory containing the functionConfig
+		{
+			name: "scope_resources_by_selector",
+			instance: FunctionFilter{Selector: &Selector{
+				Kind:   "Deployment",
+				Labels: map[string]string{"app": "frontend"},
+			}},
+			run: testRun{
+				expectedInput: `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: deployment-foo
+    labels:
+      app: frontend
+    annotations:
+      config.kubernetes.io/path: 'baz/bar/d.yaml'
+      config.k8s.io/id: '1'
+functionConfig:
+  apiVersion: example.com/v1
+  kind: Example
+  metadata:
+    name: foo
+    annotations:
+      config.kubernetes.io/path: 'foo/bar.yaml'
+`,
+				output: `apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: deployment-foo
+    labels:
+      app: frontend
+    annotations:
+      config.kubernetes.io/path: 'baz/bar/d.yaml'
+      new: annotation
+      config.k8s.io/id: '1'
+`,
+			},
+			functionConfig: `
+apiVersion: example.com/v1
+kind: Example
+metadata:
+  name: foo
+  annotations:
+    config.kubernetes.io/path: 'foo/bar.yaml'
+`,
+			input: []string{
+				// this should be in scope
+				`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo
+  labels:
+    app: frontend
+  annotations:
+    config.kubernetes.io/path: 'baz/bar/d.yaml'
+`,
+				// this should not be in scope
+				`
+apiVersion: v1
+kind: Service
+metadata:
+  name: service-foo
+  labels:
+    app: frontend
+  annotations:
+    config.kubernetes.io/path: 'foo/bar/s.yaml'
+`,
+				// this should not be in scope
+				`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-bar
+  labels:
+    app: backend
+  annotations:
+    config.kubernetes.io/path: 'foo/bar/d.yaml'
+`},
+			expectedOutput: []string{
+				`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo
+  labels:
+    app: frontend
+  annotations:
+    config.kubernetes.io/path: 'baz/bar/d.yaml'
+    new: annotation
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: service-foo
+  labels:
+    app: frontend
+  annotations:
+    config.kubernetes.io/path: 'foo/bar/s.yaml'
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-bar
+  labels:
+    app: backend
+  annotations:
+    config.kubernetes.io/path: 'foo/bar/d.yaml'
+`,
+			},
+		},
+
 		// verify the functions can see all resources if global scope is set
 		{
 			name:     "scope_resources_global",
@@ -1136,6 +1255,30 @@ container:
     image: foo:v1.0.0`,
 		},
 
+		{
+			name: "selector",
+			resource: `
+apiVersion: v1beta1
+kind: Example
+metadata:
+  annotations:
+    config.kubernetes.io/function: |-
+      container:
+        image: foo:v1.0.0
+      selector:
+        kind: Deployment
+        labels:
+          app: frontend
+`,
+			expectedFn: `
+container:
+    image: foo:v1.0.0
+selector:
+    kind: Deployment
+    labels:
+        app: frontend`,
+		},
+
 		{
 			name: "storage mounts json style",
 			resource: `
@@ -1421,6 +1564,22 @@ func Test_StringToStorageMount(t *testing.T) {
 			in:          "type=bind,source=/tmp/test/,target=/tmp/source/",
 			expectedOut: "type=bind,source=/tmp/test/,target=/tmp/source/,readonly",
 		},
+		{
+			in:          "type=volume,source=charts,target=/charts,rw",
+			expectedOut: "type=volume,source=charts,target=/charts",
+		},
+		{
+			in:          "type=volume,source=charts,target=/charts,readonly=false",
+			expectedOut: "type=volume,source=charts,target=/charts",
+		},
+		{
+			in:          "type=volume,source=charts,target=/charts,ro=false",
+			expectedOut: "type=volume,source=charts,target=/charts",
+		},
+		{
+			in:          "type=volume,source=charts,target=/charts,readonly=true",
+			expectedOut: "type=volume,source=charts,target=/charts,readonly",
+		},
 	}
 
 	for _, tc := range tests {
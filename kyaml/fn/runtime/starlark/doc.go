@@ -31,6 +31,17 @@
 // The items in the resourceList respect the io spec specified by:
 // https://github.com/kubernetes-sigs/kustomize/blob/master/cmd/config/docs/api-conventions/config-io.md
 //
+// Functions run by runfn may read the program from a path relative to the function config,
+// from a url, or embed it in the function config, so simple transformations don't need
+// containers or binaries:
+//
+//   config.kubernetes.io/function: |
+//     starlark:
+//       name: set-replicas
+//       program: |
+//         for resource in ctx.resource_list["items"]:
+//           resource["spec"]["replicas"] = 3
+//
 // The starlark language spec can be found here:
 // https://github.com/google/starlark-go/blob/master/doc/spec.md
 package starlark
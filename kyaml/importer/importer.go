@@ -0,0 +1,218 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package importer renders jsonnet programs and cdk8s apps, and
+// merges the Resources they produce into local packages, so that
+// they can be kustomized further.
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Reader reads the Resources rendered by a jsonnet program,
+// using the jsonnet program, or by a cdk8s app, using the
+// cdk8s program.  Exactly one of Jsonnet and Cdk8s is set.
+type Reader struct {
+	// Jsonnet is the path to a jsonnet program.
+	Jsonnet string
+
+	// JPaths are the library directories of the jsonnet program.
+	JPaths []string
+
+	// ExtStrs are the external variables of the
+	// jsonnet program, each as KEY=VALUE.
+	ExtStrs []string
+
+	// TLAStrs are the top level arguments of the
+	// jsonnet program, each as KEY=VALUE.
+	TLAStrs []string
+
+	// Cdk8s is the path to the directory of a cdk8s app.
+	Cdk8s string
+}
+
+var _ kio.Reader = Reader{}
+
+// Read renders the Resources.
+func (r Reader) Read() ([]*yaml.RNode, error) {
+	switch {
+	case r.Jsonnet != "" && r.Cdk8s != "":
+		return nil, errors.Errorf("jsonnet and cdk8s are mutually exclusive")
+	case r.Jsonnet != "":
+		return r.readJsonnet()
+	case r.Cdk8s != "":
+		return r.readCdk8s()
+	default:
+		return nil, errors.Errorf("either jsonnet or cdk8s must be specified")
+	}
+}
+
+func (r Reader) readJsonnet() ([]*yaml.RNode, error) {
+	var args []string
+	for _, p := range r.JPaths {
+		args = append(args, "--jpath", p)
+	}
+	for _, s := range r.ExtStrs {
+		args = append(args, "--ext-str", s)
+	}
+	for _, s := range r.TLAStrs {
+		args = append(args, "--tla-str", s)
+	}
+	out, err := run("jsonnet", "", append(args, r.Jsonnet)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSON(out)
+}
+
+func (r Reader) readCdk8s() ([]*yaml.RNode, error) {
+	dir, err := ioutil.TempDir("", "kustomize-cdk8s-")
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err = run("cdk8s", r.Cdk8s, "synth", "--output", dir); err != nil {
+		return nil, err
+	}
+	// cdk8s writes a file for each chart, named
+	// so that they sort in the order of the charts.
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	sort.Strings(files)
+	var result []*yaml.RNode
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		nodes, err := (&kio.ByteReader{
+			Reader:                bytes.NewReader(b),
+			OmitReaderAnnotations: true,
+		}).Read()
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "reading %s", filepath.Base(f))
+		}
+		result = append(result, nodes...)
+	}
+	return result, nil
+}
+
+// run runs program with args in dir, returning its output.
+func run(program string, dir string, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "no '%s' program on path", program)
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "%s: %s", program, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// ParseJSON returns the Resources in the JSON output of a
+// jsonnet program: a Resource, a List of Resources, or an
+// array or object of those, nested to any depth, in the
+// manner of kubecfg.  Object fields are read in order of
+// their keys.
+func ParseJSON(b []byte) ([]*yaml.RNode, error) {
+	var result []*yaml.RNode
+	if err := parseJSON(b, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func parseJSON(b []byte, result *[]*yaml.RNode) error {
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("[")) {
+		var elements []json.RawMessage
+		if err := json.Unmarshal(b, &elements); err != nil {
+			return errors.Wrap(err)
+		}
+		for _, e := range elements {
+			if err := parseJSON(e, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var header struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Items      []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(b, &header); err != nil {
+		if bytes.Equal(b, []byte("null")) {
+			return nil
+		}
+		return errors.Wrap(err)
+	}
+	switch {
+	case header.Kind != "" && strings.HasSuffix(header.Kind, "List") && header.Items != nil:
+		for _, e := range header.Items {
+			if err := parseJSON(e, result); err != nil {
+				return err
+			}
+		}
+	case header.APIVersion != "" && header.Kind != "":
+		n, err := yaml.ConvertJSONToYamlNode(string(b))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		*result = append(*result, n)
+	default:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(b, &fields); err != nil {
+			return errors.Wrap(err)
+		}
+		var keys []string
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := parseJSON(fields[k], result); err != nil {
+				return errors.WrapPrefixf(err, "reading %s", k)
+			}
+		}
+	}
+	return nil
+}
+
+// Import merges the Resources read by r into the package at
+// pkgPath, creating it if needed.  Resources already in the
+// package are merged with the imported ones of the same
+// Group/Version/Kind/Namespace/Name, keeping the fields and
+// comments only they have, and stay in their files.  Other
+// Resources are written to files named after their kinds and
+// names.  The files are formatted.
+func Import(pkgPath string, r kio.Reader) error {
+	if err := os.MkdirAll(pkgPath, 0700); err != nil {
+		return errors.Wrap(err)
+	}
+	return kio.Pipeline{
+		Inputs:  []kio.Reader{kio.LocalPackageReader{PackagePath: pkgPath}, r},
+		Filters: []kio.Filter{filters.MergeFilter{}, filters.FormatFilter{}},
+		Outputs: []kio.Writer{kio.LocalPackageWriter{PackagePath: pkgPath}},
+	}.Execute()
+}
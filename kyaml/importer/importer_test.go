@@ -0,0 +1,287 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// fakeProgram puts a shell script named name on the PATH,
+// restoring the PATH when the test ends.
+func fakeProgram(t *testing.T, name, script string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake programs are shell scripts")
+	}
+	dir, err := ioutil.TempDir("", "kustomize-importer-test-")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0700))
+	path := os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+}
+
+func stringAll(nodes []*yaml.RNode) (string, error) {
+	var out bytes.Buffer
+	err := kio.ByteWriter{Writer: &out}.Write(nodes)
+	return out.String(), err
+}
+
+func TestParseJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:  "resource",
+			input: `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "a"}}`,
+			expected: `apiVersion: v1
+kind: Service
+metadata:
+  name: a
+`,
+		},
+		{
+			name: "list",
+			input: `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "a"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "b"}}
+]}`,
+			expected: `apiVersion: v1
+kind: Service
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`,
+		},
+		{
+			name: "nested",
+			input: `{
+  "z": {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "a"}},
+  "a": [
+    {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "b"}},
+    {"x": {"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "c"}}},
+    null
+  ]
+}`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: c
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := ParseJSON([]byte(tc.input))
+			require.NoError(t, err)
+			actual, err := stringAll(nodes)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestParseJSON_notAResource(t *testing.T) {
+	_, err := ParseJSON([]byte(`{"a": "b"}`))
+	assert.Error(t, err)
+}
+
+func TestReader_jsonnet(t *testing.T) {
+	fakeProgram(t, "jsonnet", `echo "$@" >&2
+[ "$*" = "--jpath lib --ext-str env=prod --tla-str replicas=3 app.jsonnet" ] || exit 1
+echo '{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "a"}}'
+`)
+	nodes, err := Reader{
+		Jsonnet: "app.jsonnet",
+		JPaths:  []string{"lib"},
+		ExtStrs: []string{"env=prod"},
+		TLAStrs: []string{"replicas=3"},
+	}.Read()
+	require.NoError(t, err)
+	actual, err := stringAll(nodes)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: a
+`, actual)
+}
+
+func TestReader_jsonnetFails(t *testing.T) {
+	fakeProgram(t, "jsonnet", `echo "app.jsonnet:1:1 Unexpected end of file" >&2
+exit 1
+`)
+	_, err := Reader{Jsonnet: "app.jsonnet"}.Read()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jsonnet: app.jsonnet:1:1 Unexpected end of file")
+}
+
+func TestReader_cdk8s(t *testing.T) {
+	app, err := ioutil.TempDir("", "kustomize-importer-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(app)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(app, "cdk8s.yaml"), []byte("app: node main.js\n"), 0600))
+
+	// synthesizes from the app directory, into the --output directory
+	fakeProgram(t, "cdk8s", `[ "$1 $2" = "synth --output" ] && [ -f cdk8s.yaml ] || exit 1
+cat > "$3/0000-b.k8s.yaml" <<EOF
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+EOF
+cat > "$3/0000-a.k8s.yaml" <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: a
+EOF
+`)
+	nodes, err := Reader{Cdk8s: app}.Read()
+	require.NoError(t, err)
+	actual, err := stringAll(nodes)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+`, actual)
+}
+
+func TestReader_invalid(t *testing.T) {
+	_, err := Reader{}.Read()
+	assert.EqualError(t, err, "either jsonnet or cdk8s must be specified")
+	_, err = Reader{Jsonnet: "a.jsonnet", Cdk8s: "app"}.Read()
+	assert.EqualError(t, err, "jsonnet and cdk8s are mutually exclusive")
+}
+
+func TestImport(t *testing.T) {
+	fakeProgram(t, "jsonnet", `cat <<EOF
+[
+  {"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "app"},
+   "spec": {"replicas": 3, "template": {"spec": {"containers": [{"name": "app", "image": "app:v2"}]}}}},
+  {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "app", "namespace": "prod"},
+   "spec": {"ports": [{"port": 80}]}}
+]
+EOF
+`)
+	pkg, err := ioutil.TempDir("", "kustomize-importer-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(pkg)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "app.yaml"), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+        args: [--verbose] # kept
+`), 0600))
+
+	require.NoError(t, Import(pkg, Reader{Jsonnet: "app.jsonnet"}))
+
+	b, err := ioutil.ReadFile(filepath.Join(pkg, "app.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v2
+        args: [--verbose] # kept
+`, string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(pkg, "prod", "service_app.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: prod
+spec:
+  ports:
+  - port: 80
+`, string(b))
+}
+
+func TestImport_newPackage(t *testing.T) {
+	fakeProgram(t, "jsonnet", `echo '{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "app"}}'`)
+	dir, err := ioutil.TempDir("", "kustomize-importer-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	pkg := filepath.Join(dir, "app")
+
+	require.NoError(t, Import(pkg, Reader{Jsonnet: "app.jsonnet"}))
+
+	b, err := ioutil.ReadFile(filepath.Join(pkg, "service_app.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`, string(b))
+}
@@ -5,6 +5,7 @@ package kio
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
@@ -116,8 +117,13 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 		return nil, errors.Wrap(err)
 	}
 
-	// replace the ending \r\n (line ending used in windows) with \n and then separate by \n---\n
-	values := strings.Split(strings.Replace(input.String(), "\r\n", "\n", -1), "\n---\n")
+	// JSON documents, e.g. the output of kubectl get -o json, are YAML
+	// too, but they may be concatenated without separators
+	values, fromJSON := splitJSON(input.Bytes())
+	if !fromJSON {
+		// replace the ending \r\n (line ending used in windows) with \n and then separate by \n---\n
+		values = strings.Split(strings.Replace(input.String(), "\r\n", "\n", -1), "\n---\n")
+	}
 
 	index := 0
 	for i := range values {
@@ -161,7 +167,16 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 			if items != nil {
 				for i := range items.Value.Content() {
 					// add items
-					output = append(output, yaml.NewRNode(items.Value.Content()[i]))
+					item := yaml.NewRNode(items.Value.Content()[i])
+					// the items of the Lists of JSON-emitting tools aren't
+					// annotated, unlike the items of ResourceLists
+					if fromJSON && meta.Kind == "List" {
+						if err := r.annotate(item, index); err != nil {
+							return nil, err
+						}
+						index++
+					}
+					output = append(output, item)
 				}
 			}
 			continue
@@ -182,6 +197,36 @@ func isEmptyDocument(node *yaml.Node) bool {
 		node.Content[0].Tag == yaml.NullNodeTag
 }
 
+// splitJSON returns the JSON documents of input, and the elements of its
+// JSON arrays, or false if input isn't JSON.
+func splitJSON(input []byte) ([]string, bool) {
+	trimmed := bytes.TrimSpace(input)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+	var values []string
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			return values, true
+		}
+		if err != nil {
+			// e.g. a YAML flow mapping
+			return nil, false
+		}
+		var elements []json.RawMessage
+		if value[0] == '[' && json.Unmarshal(value, &elements) == nil {
+			for i := range elements {
+				values = append(values, string(elements[i]))
+			}
+			continue
+		}
+		values = append(values, string(value))
+	}
+}
+
 func (r *ByteReader) decode(index int, decoder *yaml.Decoder) (*yaml.RNode, error) {
 	node := &yaml.Node{}
 	err := decoder.Decode(node)
@@ -196,10 +241,18 @@ func (r *ByteReader) decode(index int, decoder *yaml.Decoder) (*yaml.RNode, erro
 		return nil, nil
 	}
 
+	n := yaml.NewRNode(node)
+	if err := r.annotate(n, index); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// annotate sets the annotations of the reader on n, read at index.
+func (r *ByteReader) annotate(n *yaml.RNode, index int) error {
 	// set annotations on the read Resources
 	// sort the annotations by key so the output Resources is consistent (otherwise the
 	// annotations will be in a random order)
-	n := yaml.NewRNode(node)
 	if r.SetAnnotations == nil {
 		r.SetAnnotations = map[string]string{}
 	}
@@ -212,10 +265,13 @@ func (r *ByteReader) decode(index int, decoder *yaml.Decoder) (*yaml.RNode, erro
 	}
 	sort.Strings(keys)
 	for _, k := range keys {
-		_, err = n.Pipe(yaml.SetAnnotation(k, r.SetAnnotations[k]))
+		_, err := n.Pipe(yaml.SetAnnotation(k, r.SetAnnotations[k]))
 		if err != nil {
-			return nil, errors.Wrap(err)
+			return errors.Wrap(err)
 		}
 	}
-	return yaml.NewRNode(node), nil
+	// share the strings repeated across Resources, e.g. apiVersions
+	// and annotation keys, rather than keeping a copy per Resource
+	yaml.InternMeta(n)
+	return nil
 }
@@ -349,6 +349,70 @@ metadata:
 			},
 			instance: ByteReader{},
 		},
+
+		//
+		//
+		//
+		{
+			name: "json_stream",
+			input: `{"kind": "Deployment"}
+{"kind": "Service"}
+`,
+			expectedItems: []string{
+				`{"kind": "Deployment", metadata: {annotations: {config.kubernetes.io/index: '0'}}}`,
+				`{"kind": "Service", metadata: {annotations: {config.kubernetes.io/index: '1'}}}`,
+			},
+			instance: ByteReader{},
+		},
+
+		//
+		//
+		//
+		{
+			name:  "json_array",
+			input: `[{"kind": "Deployment"}, {"kind": "Service"}]`,
+			expectedItems: []string{
+				`{"kind": "Deployment", metadata: {annotations: {config.kubernetes.io/index: '0'}}}`,
+				`{"kind": "Service", metadata: {annotations: {config.kubernetes.io/index: '1'}}}`,
+			},
+			instance: ByteReader{},
+		},
+
+		//
+		//
+		//
+		{
+			name: "json_list",
+			input: `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"kind": "Deployment"},
+    {"kind": "Service"}
+  ]
+}
+`,
+			expectedItems: []string{
+				`{"kind": "Deployment", metadata: {annotations: {config.kubernetes.io/index: '0', config.kubernetes.io/path: 'resources.json'}}}`,
+				`{"kind": "Service", metadata: {annotations: {config.kubernetes.io/index: '1', config.kubernetes.io/path: 'resources.json'}}}`,
+			},
+			wrappingAPIVersion: "v1",
+			wrappingAPIKind:    "List",
+			instance: ByteReader{
+				SetAnnotations: map[string]string{"config.kubernetes.io/path": "resources.json"}},
+		},
+
+		//
+		//
+		//
+		{
+			name:  "yaml_flow_mapping",
+			input: `{a: b}`,
+			expectedItems: []string{
+				`{a: b, metadata: {annotations: {config.kubernetes.io/index: '0'}}}`,
+			},
+			instance: ByteReader{},
+		},
 	}
 
 	for i := range testCases {
@@ -4,6 +4,7 @@
 package kio
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 
@@ -43,11 +44,34 @@ type ByteWriter struct {
 
 	// Sort if set, will cause ByteWriter to sort the the nodes before writing them.
 	Sort bool
+
+	// Format is the format the Resources are written in.  Defaults to YAMLOutput.
+	Format OutputFormat
 }
 
+// OutputFormat is a format ByteWriter writes Resources in.
+type OutputFormat string
+
+const (
+	// YAMLOutput writes the Resources as a stream of YAML documents.
+	YAMLOutput OutputFormat = ""
+
+	// JSONOutput writes the Resources as a JSON array, e.g. for jq.
+	JSONOutput OutputFormat = "json"
+
+	// NDJSONOutput writes each Resource as JSON on a line of its own.
+	NDJSONOutput OutputFormat = "ndjson"
+)
+
 var _ Writer = ByteWriter{}
 
 func (w ByteWriter) Write(nodes []*yaml.RNode) error {
+	switch w.Format {
+	case YAMLOutput, JSONOutput, NDJSONOutput:
+	default:
+		return errors.Errorf("unsupported output format %s, must be one of %v",
+			w.Format, []OutputFormat{JSONOutput, NDJSONOutput})
+	}
 	yaml.DoSerializationHacksOnNodes(nodes)
 	if w.Sort {
 		if err := kioutil.SortNodes(nodes); err != nil {
@@ -83,6 +107,13 @@ func (w ByteWriter) Write(nodes []*yaml.RNode) error {
 
 	// don't wrap the elements
 	if w.WrappingKind == "" {
+		if w.Format != YAMLOutput {
+			docs := make([]*yaml.Node, len(nodes))
+			for i := range nodes {
+				docs[i] = nodes[i].Document()
+			}
+			return w.writeJSON(docs, w.Format == JSONOutput)
+		}
 		for i := range nodes {
 			if err := w.encode(encoder, nodes[i].Document()); err != nil {
 				return err
@@ -118,7 +149,12 @@ func (w ByteWriter) Write(nodes []*yaml.RNode) error {
 	for i := range nodes {
 		items.Content = append(items.Content, nodes[i].YNode())
 	}
-	err := w.encode(encoder, doc)
+	var err error
+	if w.Format != YAMLOutput {
+		err = w.writeJSON([]*yaml.Node{doc}, false)
+	} else {
+		err = w.encode(encoder, doc)
+	}
 	yaml.UndoSerializationHacksOnNodes(nodes)
 	return err
 }
@@ -138,3 +174,106 @@ func (w ByteWriter) encode(encoder *yaml.Encoder, doc *yaml.Node) error {
 	}
 	return encoder.Encode(doc)
 }
+
+// writeJSON writes docs in the JSON format of the ByteWriter, as an
+// array if array is true.  Unlike json.Marshal of the RNodes, it keeps
+// the order of the fields.
+func (w ByteWriter) writeJSON(docs []*yaml.Node, array bool) error {
+	out := &bytes.Buffer{}
+	if array {
+		out.WriteString("[")
+	}
+	for i := range docs {
+		if array && i > 0 {
+			out.WriteString(",")
+		}
+		if err := encodeJSON(out, docs[i]); err != nil {
+			return err
+		}
+		if w.Format == NDJSONOutput {
+			out.WriteString("\n")
+		}
+	}
+	if array {
+		out.WriteString("]")
+	}
+	if w.Format == JSONOutput {
+		indented := &bytes.Buffer{}
+		if err := json.Indent(indented, out.Bytes(), "", "  "); err != nil {
+			return errors.Wrap(err)
+		}
+		indented.WriteString("\n")
+		out = indented
+	}
+	_, err := w.Writer.Write(out.Bytes())
+	return errors.Wrap(err)
+}
+
+// encodeJSON writes node to out as compact JSON.  Scalars are written
+// as the JSON types their YAML tags resolve to, and comments are dropped.
+func encodeJSON(out *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			out.WriteString("null")
+			return nil
+		}
+		return encodeJSON(out, node.Content[0])
+	case yaml.AliasNode:
+		return encodeJSON(out, node.Alias)
+	case yaml.MappingNode:
+		out.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			if err := encodeJSONValue(out, node.Content[i].Value); err != nil {
+				return err
+			}
+			out.WriteString(":")
+			if err := encodeJSON(out, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		out.WriteString("}")
+		return nil
+	case yaml.SequenceNode:
+		out.WriteString("[")
+		for i := range node.Content {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			if err := encodeJSON(out, node.Content[i]); err != nil {
+				return err
+			}
+		}
+		out.WriteString("]")
+		return nil
+	}
+
+	switch node.ShortTag() {
+	case yaml.NodeTagNull:
+		out.WriteString("null")
+		return nil
+	case yaml.NodeTagBool, yaml.NodeTagInt, yaml.NodeTagFloat:
+		var v interface{}
+		if err := node.Decode(&v); err == nil {
+			if b, err := json.Marshal(v); err == nil {
+				out.Write(b)
+				return nil
+			}
+		}
+		// e.g. .inf has no JSON number, so it's written as a string
+	}
+	return encodeJSONValue(out, node.Value)
+}
+
+// encodeJSONValue writes v to out as JSON.
+func encodeJSONValue(out *bytes.Buffer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	out.Write(b)
+	return nil
+}
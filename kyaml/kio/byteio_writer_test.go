@@ -189,6 +189,83 @@ metadata:
     config.kubernetes.io/path: "a/b/a_test.yaml"
 `,
 		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "json",
+			instance: ByteWriter{Format: JSONOutput},
+			items: []string{
+				`kind: b # first
+a: [1, 1.5, true, null, "2", x]
+`,
+				`z: {y: x}`,
+			},
+			expectedOutput: `[
+  {
+    "kind": "b",
+    "a": [
+      1,
+      1.5,
+      true,
+      null,
+      "2",
+      "x"
+    ]
+  },
+  {
+    "z": {
+      "y": "x"
+    }
+  }
+]
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "ndjson",
+			instance: ByteWriter{Format: NDJSONOutput},
+			items: []string{
+				`kind: b # first
+a: [1, "2"]
+`,
+				`z: {y: x}`,
+			},
+			expectedOutput: `{"kind":"b","a":[1,"2"]}
+{"z":{"y":"x"}}
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name: "ndjson_wrap_resource_list",
+			instance: ByteWriter{
+				Format:             NDJSONOutput,
+				WrappingKind:       ResourceListKind,
+				WrappingAPIVersion: ResourceListAPIVersion,
+			},
+			items: []string{
+				`a: b`,
+			},
+			expectedOutput: `{"apiVersion":"config.kubernetes.io/v1alpha1","kind":"ResourceList","items":[{"a":"b"}]}
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "unsupported_format",
+			instance: ByteWriter{Format: "xml"},
+			items:    []string{`a: b`},
+			err:      "unsupported output format xml, must be one of [json ndjson]",
+		},
 	}
 
 	for i := range testCases {
@@ -0,0 +1,46 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ExecFilter runs a local executable as a function, the way
+// container functions are run: the Resources in its scope are
+// written to its stdin as a ResourceList, and read back from
+// its stdout, and its results are handled by FunctionFilter.
+// Functions may so be developed and run without building an
+// image.
+//
+// ExecFilter isn't one of the Filters, since it would let
+// configuration run any executable.
+type ExecFilter struct {
+	// Path is the path of the executable.
+	Path string `yaml:"path,omitempty"`
+
+	// Args are the arguments of the executable.
+	Args []string `yaml:"args,omitempty"`
+
+	runtimeutil.FunctionFilter
+}
+
+var _ kio.Filter = &ExecFilter{}
+
+func (f *ExecFilter) String() string {
+	return fmt.Sprintf("path: %v args: %v", f.Path, f.Args)
+}
+
+func (f *ExecFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	e := &exec.Filter{Path: f.Path, Args: f.Args, FunctionFilter: f.FunctionFilter}
+	output, err := e.Filter(input)
+	// keep the results and exit of the run
+	f.FunctionFilter = e.FunctionFilter
+	return output, err
+}
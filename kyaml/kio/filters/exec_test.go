@@ -0,0 +1,72 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestExecFilter(t *testing.T) {
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("no sed program on path")
+	}
+	fc, err := yaml.Parse(`apiVersion: example.com/v1
+kind: Sed
+metadata:
+  name: sed
+  annotations:
+    config.kubernetes.io/path: 'a/sed.yaml'
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var out strings.Builder
+	err = kio.Pipeline{
+		Inputs: []kio.Reader{&kio.ByteReader{Reader: strings.NewReader(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  annotations:
+    config.kubernetes.io/path: 'a/deployment.yaml'
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+  annotations:
+    config.kubernetes.io/path: 'b/deployment.yaml'
+`)}},
+		Filters: []kio.Filter{&ExecFilter{
+			Path:           "sed",
+			Args:           []string{"s/kind: Deployment/kind: StatefulSet/"},
+			FunctionFilter: runtimeutil.FunctionFilter{FunctionConfig: fc},
+		}},
+		Outputs: []kio.Writer{kio.ByteWriter{Writer: &out}},
+	}.Execute()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	// only the Deployment in the scope of the function changes
+	assert.Equal(t, `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: a
+  annotations:
+    config.kubernetes.io/path: 'a/deployment.yaml'
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+  annotations:
+    config.kubernetes.io/path: 'b/deployment.yaml'
+`, out.String())
+}
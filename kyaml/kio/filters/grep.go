@@ -115,3 +115,60 @@ func (f GrepFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 	}
 	return output, nil
 }
+
+// GrepAllFilter filters RNodes matched by all of its Filters, e.g.
+// GrepFilters matching different fields.
+type GrepAllFilter struct {
+	Filters     []kio.Filter
+	InvertMatch bool
+}
+
+var _ kio.Filter = GrepAllFilter{}
+
+func (f GrepAllFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	matched := input
+	for i := range f.Filters {
+		var err error
+		if matched, err = f.Filters[i].Filter(matched); err != nil {
+			return nil, err
+		}
+	}
+	return selectMatched(input, matched, f.InvertMatch), nil
+}
+
+// GrepAnyFilter filters RNodes matched by any of its Filters, e.g.
+// GrepFilters or GrepAllFilters, keeping the order of the RNodes.
+type GrepAnyFilter struct {
+	Filters     []kio.Filter
+	InvertMatch bool
+}
+
+var _ kio.Filter = GrepAnyFilter{}
+
+func (f GrepAnyFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	var matched []*yaml.RNode
+	for i := range f.Filters {
+		m, err := f.Filters[i].Filter(input)
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, m...)
+	}
+	return selectMatched(input, matched, f.InvertMatch), nil
+}
+
+// selectMatched returns the RNodes of input which are in matched, or which
+// aren't if invert is true, in the order of input.
+func selectMatched(input, matched []*yaml.RNode, invert bool) []*yaml.RNode {
+	m := map[*yaml.RNode]bool{}
+	for i := range matched {
+		m[matched[i]] = true
+	}
+	var output []*yaml.RNode
+	for i := range input {
+		if m[input[i]] != invert {
+			output = append(output, input[i])
+		}
+	}
+	return output
+}
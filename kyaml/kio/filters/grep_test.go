@@ -5,6 +5,7 @@ package filters_test
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -155,3 +156,107 @@ func TestGrepFilter_error(t *testing.T) {
 	}
 	assert.Nil(t, v)
 }
+
+func TestGrepAllFilter_GrepAnyFilter(t *testing.T) {
+	in := `kind: Deployment
+metadata:
+  name: foo
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: gcr.io/example/app:v1
+---
+kind: Deployment
+metadata:
+  name: bar
+spec:
+  replicas: 5
+  template:
+    spec:
+      containers:
+      - name: app
+        image: gcr.io/example/app:v1
+---
+kind: Deployment
+metadata:
+  name: baz
+spec:
+  replicas: 5
+  template:
+    spec:
+      containers:
+      - name: app
+        image: docker.io/example/app:v1
+---
+kind: Service
+metadata:
+  name: qux
+`
+	compare := func(a, b string) (int, error) {
+		return strings.Compare(a, b), nil
+	}
+	replicas := GrepFilter{
+		Path: []string{"spec", "replicas"}, Value: "3", MatchType: GreaterThan, Compare: compare}
+	image := GrepFilter{
+		Path:  []string{"spec", "template", "spec", "containers", "[name=app]", "image"},
+		Value: "^gcr.io/", MatchType: Regexp}
+	service := GrepFilter{Path: []string{"kind"}, Value: "^Service$", MatchType: Regexp}
+
+	var tests = []struct {
+		name     string
+		filter   kio.Filter
+		expected []string
+	}{
+		{
+			name:     "all",
+			filter:   GrepAllFilter{Filters: []kio.Filter{replicas, image}},
+			expected: []string{"bar"},
+		},
+		{
+			name:     "all-invert",
+			filter:   GrepAllFilter{Filters: []kio.Filter{replicas, image}, InvertMatch: true},
+			expected: []string{"foo", "baz", "qux"},
+		},
+		{
+			name:     "any",
+			filter:   GrepAnyFilter{Filters: []kio.Filter{service, replicas}},
+			expected: []string{"bar", "baz", "qux"},
+		},
+		{
+			name: "any-of-all",
+			filter: GrepAnyFilter{Filters: []kio.Filter{
+				GrepAllFilter{Filters: []kio.Filter{replicas, image}}, service}},
+			expected: []string{"bar", "qux"},
+		},
+		{
+			name:     "any-invert",
+			filter:   GrepAnyFilter{Filters: []kio.Filter{service, image}, InvertMatch: true},
+			expected: []string{"baz"},
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := (&kio.ByteReader{Reader: bytes.NewBufferString(in)}).Read()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			nodes, err = tt.filter.Filter(nodes)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var actual []string
+			for i := range nodes {
+				meta, err := nodes[i].GetMeta()
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				actual = append(actual, meta.Name)
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -5,6 +5,7 @@ package filters
 
 import (
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -30,6 +31,21 @@ type Merge3 struct {
 	// This may be necessary if the directory contains multiple copies of
 	// the same resource, or resources patches.
 	MergeOnPath bool
+
+	// InferAssociativeLists if set to true will infer merge keys for lists
+	// which don't have a schema from the fields in the list elements.
+	InferAssociativeLists bool
+
+	// ConflictStrategy resolves the conflicts of the merge:
+	// fields which the dest and the update both changed to
+	// different values.  merge3.TakeUpdate if 0.  With
+	// merge3.FailOnConflict, the merge fails if any
+	// resource has conflicts, and nothing is written.
+	ConflictStrategy merge3.ConflictStrategy
+
+	// OnConflict, if not nil, is told of each conflict,
+	// with the resource it's in.
+	OnConflict func(yaml.ResourceMeta, merge3.Conflict)
 }
 
 func (m Merge3) Merge() error {
@@ -67,7 +83,7 @@ func (m Merge3) Merge() error {
 // Filter combines Resources with the same GVK + N + NS into tuples, and then merges them
 func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	// index the nodes by their identity
-	tl := tuples{mergeOnPath: m.MergeOnPath}
+	tl := tuples{mergeOnPath: m.MergeOnPath, infer: m.InferAssociativeLists}
 	for i := range nodes {
 		if err := tl.add(nodes[i]); err != nil {
 			return nil, err
@@ -76,6 +92,13 @@ func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 
 	// iterate over the inputs, merging as needed
 	var output []*yaml.RNode
+	var conflicts []string
+	strategy := m.ConflictStrategy
+	if strategy == merge3.FailOnConflict {
+		// Whether to fail is decided once all are merged, since
+		// the annotations of the readers always conflict.
+		strategy = merge3.TakeUpdate
+	}
 	for i := range tl.list {
 		t := tl.list[i]
 		switch {
@@ -93,7 +116,18 @@ func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 			// don't include the resource in the output
 		default:
 			// dest and updated are non-nil -- merge them
-			node, err := t.merge()
+			node, cs, err := merge3.MergeWithConflicts(
+				t.dest, t.original, t.updated, t.infer, strategy)
+			for _, c := range cs {
+				if isReaderAnnotation(c.Path) {
+					continue
+				}
+				if m.OnConflict != nil {
+					m.OnConflict(t.meta, c)
+				}
+				conflicts = append(conflicts, fmt.Sprintf("%s %s: %s",
+					t.meta.Kind, resourceName(t.meta), c))
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -102,9 +136,36 @@ func (m Merge3) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 			}
 		}
 	}
+	if m.ConflictStrategy == merge3.FailOnConflict && len(conflicts) > 0 {
+		return nil, fmt.Errorf("%d conflicts:\n  %s",
+			len(conflicts), strings.Join(conflicts, "\n  "))
+	}
 	return output, nil
 }
 
+// isReaderAnnotation returns whether path is that of an
+// annotation set by the readers of the packages, which
+// always differ between them.
+func isReaderAnnotation(path []string) bool {
+	if len(path) != 3 || path[0] != "metadata" || path[1] != "annotations" {
+		return false
+	}
+	switch path[2] {
+	case mergeSourceAnnotation, kioutil.PathAnnotation, kioutil.IndexAnnotation:
+		return true
+	}
+	return false
+}
+
+// resourceName returns the namespace and name of the
+// resource meta is of, e.g. default/web.
+func resourceName(meta yaml.ResourceMeta) string {
+	if meta.Namespace == "" {
+		return meta.Name
+	}
+	return meta.Namespace + "/" + meta.Name
+}
+
 // tuples combines nodes with the same GVK + N + NS
 type tuples struct {
 	list []*tuple
@@ -112,6 +173,9 @@ type tuples struct {
 	// mergeOnPath if set to true will use the resource filepath
 	// as part of the merge key
 	mergeOnPath bool
+
+	// infer if set to true will infer merge keys for lists without a schema
+	infer bool
 }
 
 // isSameResource returns true if meta1 and meta2 are for the same logic resource
@@ -153,7 +217,7 @@ func (ts *tuples) add(node *yaml.RNode) error {
 			return t.add(node)
 		}
 	}
-	t := &tuple{meta: nodeMeta}
+	t := &tuple{meta: nodeMeta, infer: ts.infer}
 	if err := t.add(node); err != nil {
 		return err
 	}
@@ -167,6 +231,7 @@ type tuple struct {
 	original *yaml.RNode
 	updated  *yaml.RNode
 	dest     *yaml.RNode
+	infer    bool
 }
 
 // add sets the corresponding tuple field for the node
@@ -196,8 +261,3 @@ func (t *tuple) add(node *yaml.RNode) error {
 	}
 	return nil
 }
-
-// merge performs a 3-way merge on the tuple
-func (t *tuple) merge() (*yaml.RNode, error) {
-	return merge3.Merge(t.dest, t.original, t.updated)
-}
@@ -15,6 +15,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge3"
 )
 
 func TestMerge3_Merge(t *testing.T) {
@@ -140,3 +142,50 @@ func TestMerge3_Merge_fail(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+// TestMerge3_Merge_conflicts tests that fields changed both
+// locally and remotely are reported, and fail the merge with
+// merge3.FailOnConflict, leaving the destination as it was.
+func TestMerge3_Merge_conflicts(t *testing.T) {
+	_, datadir, _, ok := runtime.Caller(0)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	datadir = filepath.Join(filepath.Dir(datadir), "testdata")
+
+	dir, err := ioutil.TempDir("", "kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	if !assert.NoError(t, copyutil.CopyDir(
+		filepath.Join(datadir, "dataset1-localupdates"),
+		filepath.Join(dir, "dataset1"))) {
+		t.FailNow()
+	}
+
+	var conflicts []string
+	err = filters.Merge3{
+		OriginalPath:     filepath.Join(datadir, "dataset1"),
+		UpdatedPath:      filepath.Join(datadir, "dataset1-remoteupdates"),
+		DestPath:         filepath.Join(dir, "dataset1"),
+		ConflictStrategy: merge3.FailOnConflict,
+		OnConflict: func(meta yaml.ResourceMeta, c merge3.Conflict) {
+			conflicts = append(conflicts, meta.Name+" "+c.String())
+		},
+	}.Merge()
+	if !assert.EqualError(t, err, `1 conflicts:
+  Deployment app: spec.replicas: original "1", dest "2", updated "3"`) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{`app spec.replicas: original "1", dest "2", updated "3"`}, conflicts)
+
+	diffs, err := copyutil.Diff(
+		filepath.Join(dir, "dataset1"),
+		filepath.Join(datadir, "dataset1-localupdates"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, diffs.List())
+}
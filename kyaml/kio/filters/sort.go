@@ -0,0 +1,92 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultFirstKinds are the kinds which SortFilter puts first by default,
+// as other resources may live in them or be instances of them.
+var DefaultFirstKinds = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"ResourceQuota",
+	"LimitRange",
+	"StorageClass",
+	"PriorityClass",
+	"ServiceAccount",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"ConfigMap",
+	"Secret",
+}
+
+// DefaultLastKinds are the kinds which SortFilter puts last by default,
+// as they may reject or break requests for the other resources until
+// the services backing them run.
+var DefaultLastKinds = []string{
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+// SortFilter orders RNodes so that they may be applied safely: the
+// kinds of First come first, in order, and the kinds of Last come last,
+// in order.  The order of the other RNodes, and of RNodes of the same
+// kind, is kept.
+type SortFilter struct {
+	// First are the kinds which come first.  Defaults to DefaultFirstKinds.
+	First []string `yaml:"first,omitempty"`
+
+	// Last are the kinds which come last.  Defaults to DefaultLastKinds.
+	Last []string `yaml:"last,omitempty"`
+}
+
+var _ kio.Filter = SortFilter{}
+
+func (f SortFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	priorities := make(map[*yaml.RNode]int, len(input))
+	for i := range input {
+		meta, err := input[i].GetMeta()
+		if err != nil {
+			return nil, err
+		}
+		priorities[input[i]] = f.Priority(meta.Kind)
+	}
+	output := append([]*yaml.RNode{}, input...)
+	sort.SliceStable(output, func(i, j int) bool {
+		return priorities[output[i]] < priorities[output[j]]
+	})
+	return output, nil
+}
+
+// Priority returns the position of kind in the order, which is negative
+// for the kinds of First, positive for the kinds of Last, and 0 for the
+// other kinds.
+func (f SortFilter) Priority(kind string) int {
+	first, last := f.First, f.Last
+	if first == nil {
+		first = DefaultFirstKinds
+	}
+	if last == nil {
+		last = DefaultLastKinds
+	}
+	for i := range first {
+		if first[i] == kind {
+			return i - len(first)
+		}
+	}
+	for i := range last {
+		if last[i] == kind {
+			return i + 1
+		}
+	}
+	return 0
+}
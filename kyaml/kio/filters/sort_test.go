@@ -0,0 +1,95 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	. "sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+func TestSortFilter_Filter(t *testing.T) {
+	in := `kind: ValidatingWebhookConfiguration
+metadata:
+  name: a
+---
+kind: Deployment
+metadata:
+  name: b
+---
+kind: APIService
+metadata:
+  name: c
+---
+kind: CustomResourceDefinition
+metadata:
+  name: d
+---
+kind: Service
+metadata:
+  name: e
+---
+kind: Namespace
+metadata:
+  name: f
+`
+	var tests = []struct {
+		name     string
+		filter   SortFilter
+		expected []string
+	}{
+		{
+			name:     "default",
+			filter:   SortFilter{},
+			expected: []string{"f", "d", "b", "e", "c", "a"},
+		},
+		{
+			name:     "overridden",
+			filter:   SortFilter{First: []string{"Service"}, Last: []string{"Namespace"}},
+			expected: []string{"e", "a", "b", "c", "d", "f"},
+		},
+		{
+			name:     "none",
+			filter:   SortFilter{First: []string{}, Last: []string{}},
+			expected: []string{"a", "b", "c", "d", "e", "f"},
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			nodes, err := (&kio.ByteReader{
+				Reader:                bytes.NewBufferString(in),
+				OmitReaderAnnotations: true,
+			}).Read()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			sorted, err := test.filter.Filter(nodes)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var names []string
+			for i := range sorted {
+				meta, err := sorted[i].GetMeta()
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				names = append(names, meta.Name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestSortFilter_Priority(t *testing.T) {
+	f := SortFilter{}
+	assert.True(t, f.Priority("Namespace") < f.Priority("CustomResourceDefinition"))
+	assert.True(t, f.Priority("CustomResourceDefinition") < f.Priority("Deployment"))
+	assert.Equal(t, 0, f.Priority("Deployment"))
+	assert.True(t, f.Priority("Deployment") < f.Priority("APIService"))
+	assert.True(t, f.Priority("APIService") < f.Priority("MutatingWebhookConfiguration"))
+}
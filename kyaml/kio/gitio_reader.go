@@ -0,0 +1,177 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// GitPackageReader reads ResourceNodes from a package in a directory of
+// a git repository, as LocalPackageReader reads local packages.
+//
+// The repository is fetched with the git program, shallowly and at Ref
+// only, into CacheDir, by repository and commit.  Refs other than commit
+// hashes are looked up with "git ls-remote" first, so that a branch is
+// only fetched again once it has moved.
+type GitPackageReader struct {
+	// Repo is the URL of the repository, e.g.
+	// https://github.com/example/configs.git.
+	Repo string `yaml:"repo,omitempty"`
+
+	// Ref is the branch, tag or commit hash of the package.  Defaults
+	// to the default branch of the repository.
+	Ref string `yaml:"ref,omitempty"`
+
+	// Directory is the directory of the package in the repository.
+	// Defaults to the root of the repository.
+	Directory string `yaml:"directory,omitempty"`
+
+	// CacheDir is where the repositories are fetched.  Defaults to
+	// kustomize/packages in the user's cache directory, e.g. under
+	// $XDG_CACHE_HOME.  The repositories are never removed from it.
+	CacheDir string `yaml:"cacheDir,omitempty"`
+
+	// PackageReader reads the package once it's fetched.  Its
+	// PackagePath is set to the package directory.
+	PackageReader LocalPackageReader `yaml:"packageReader,omitempty"`
+}
+
+var _ Reader = GitPackageReader{}
+
+// Read fetches the package, unless it's cached, and reads the Resources.
+func (r GitPackageReader) Read() ([]*yaml.RNode, error) {
+	if r.Repo == "" {
+		return nil, errors.Errorf("must specify repo")
+	}
+	// git would take them for options, e.g. --upload-pack running any
+	// command, even though they're passed after --
+	if strings.HasPrefix(r.Repo, "-") {
+		return nil, errors.Errorf("repo %s must not start with -", r.Repo)
+	}
+	if strings.HasPrefix(r.Ref, "-") {
+		return nil, errors.Errorf("ref %s must not start with -", r.Ref)
+	}
+	dir := filepath.Clean(r.Directory)
+	if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+		return nil, errors.Errorf("package directory %s must be in the repository", r.Directory)
+	}
+	clone, err := r.fetch()
+	if err != nil {
+		return nil, err
+	}
+	r.PackageReader.PackagePath = filepath.Join(clone, dir)
+	return r.PackageReader.Read()
+}
+
+var commitHash = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// fetch returns the directory of the clone of the repository at Ref.
+func (r GitPackageReader) fetch() (string, error) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "no git program on path")
+	}
+	ref := r.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	cacheDir := r.CacheDir
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		cacheDir = filepath.Join(userCacheDir, "kustomize", "packages")
+	}
+	repoDir := filepath.Join(cacheDir, fmt.Sprintf("%x", sha256.Sum256([]byte(r.Repo))))
+
+	commit := ref
+	if !commitHash.MatchString(ref) {
+		out, err := runGit(git, "", "ls-remote", "--", r.Repo, ref)
+		if err != nil {
+			return "", err
+		}
+		if commit = parseLsRemote(out, ref); commit == "" {
+			return "", errors.Errorf("no ref %s in %s", ref, r.Repo)
+		}
+	}
+	dir := filepath.Join(repoDir, commit)
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(repoDir, 0700); err != nil {
+		return "", errors.Wrap(err)
+	}
+	tmp, err := ioutil.TempDir(repoDir, "clone-")
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"remote", "add", "--", "origin", r.Repo},
+		{"fetch", "--depth=1", "--", "origin", commit},
+		{"checkout", "FETCH_HEAD"},
+	} {
+		if _, err := runGit(git, tmp, args...); err != nil {
+			os.RemoveAll(tmp)
+			return "", err
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		// another reader stored the same commit first
+		os.RemoveAll(tmp)
+		if _, statErr := os.Stat(dir); statErr != nil {
+			return "", errors.Wrap(err)
+		}
+	}
+	return dir, nil
+}
+
+// runGit runs git with args in dir, and returns its output.
+func runGit(git, dir string, args ...string) (string, error) {
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(git, args...)
+	cmd.Dir = dir
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Errorf("git %s: %v: %s",
+			strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// parseLsRemote returns the commit which ref names in the output of
+// "git ls-remote", preferring the commit a tag points to over the tag
+// object itself.
+func parseLsRemote(out, ref string) string {
+	var commit string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[1] {
+		case "refs/tags/" + ref + "^{}":
+			return fields[0]
+		case ref, "refs/heads/" + ref, "refs/tags/" + ref:
+			if commit == "" {
+				commit = fields[0]
+			}
+		}
+	}
+	return commit
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// makeGitRepo makes a repository with a package in the app directory,
+// and returns its file URL.
+func makeGitRepo(t *testing.T, dir string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("no git program on path")
+	}
+	repo := filepath.Join(dir, "repo")
+	if !assert.NoError(t, os.MkdirAll(filepath.Join(repo, "app"), 0700)) {
+		t.FailNow()
+	}
+	err := ioutil.WriteFile(filepath.Join(repo, "app", "cm.yaml"), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"checkout", "-q", "-b", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "app"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); !assert.NoError(t, err, string(out)) {
+			t.FailNow()
+		}
+	}
+	return "file://" + filepath.ToSlash(repo)
+}
+
+func TestGitPackageReader_Read(t *testing.T) {
+	d, err := ioutil.TempDir("", "kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+	repo := makeGitRepo(t, d)
+
+	for _, ref := range []string{"", "main", "v1"} {
+		nodes, err := GitPackageReader{
+			Repo:      repo,
+			Ref:       ref,
+			Directory: "app",
+			CacheDir:  filepath.Join(d, "cache"),
+		}.Read()
+		if !assert.NoError(t, err, ref) {
+			t.FailNow()
+		}
+		if !assert.Len(t, nodes, 1) {
+			t.FailNow()
+		}
+		s, err := nodes[0].String()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/index: '0'
+    config.kubernetes.io/path: 'cm.yaml'
+`, s)
+	}
+
+	// all the refs name the same commit, which is fetched once
+	repos, err := ioutil.ReadDir(filepath.Join(d, "cache"))
+	if !assert.NoError(t, err) || !assert.Len(t, repos, 1) {
+		t.FailNow()
+	}
+	commits, err := ioutil.ReadDir(filepath.Join(d, "cache", repos[0].Name()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, commits, 1)
+}
+
+func TestGitPackageReader_Read_errors(t *testing.T) {
+	d, err := ioutil.TempDir("", "kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+	repo := makeGitRepo(t, d)
+
+	_, err = GitPackageReader{Repo: repo, Directory: "../app", CacheDir: d}.Read()
+	assert.EqualError(t, err, "package directory ../app must be in the repository")
+
+	_, err = GitPackageReader{Repo: repo, Ref: "missing", CacheDir: d}.Read()
+	assert.EqualError(t, err, "no ref missing in "+repo)
+
+	_, err = GitPackageReader{Repo: "file:///does/not/exist", CacheDir: d}.Read()
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "git ls-remote"), err.Error())
+	}
+
+	// options would run any command
+	marker := filepath.Join(d, "marker")
+	option := "--upload-pack=touch " + marker
+	_, err = GitPackageReader{Repo: option, CacheDir: d}.Read()
+	assert.EqualError(t, err, "repo "+option+" must not start with -")
+	_, err = GitPackageReader{Repo: repo, Ref: option, CacheDir: d}.Read()
+	assert.EqualError(t, err, "ref "+option+" must not start with -")
+	_, err = os.Stat(marker)
+	assert.True(t, os.IsNotExist(err))
+}
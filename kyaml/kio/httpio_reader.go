@@ -0,0 +1,235 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// defaultMaxHTTPSize is the default MaxSize of HTTPReader.
+const defaultMaxHTTPSize = 256 << 20
+
+// HTTPReader reads ResourceNodes from YAML documents published at https
+// URLs.
+//
+// Unless OmitReaderAnnotations is set, the Resources are annotated with a
+// path made of the host and path of their URL, e.g.
+// example.com/manifests/app.yaml, so that they may be written to a
+// package, as well as with their index in the document.
+type HTTPReader struct {
+	// URLs are the https URLs of the documents.
+	URLs []string `yaml:"urls,omitempty"`
+
+	// Headers are set on the requests, e.g. an Authorization header.
+	// Their values are expanded with os.ExpandEnv, so tokens needn't be
+	// written down, e.g. "Bearer ${TOKEN}".  Headers are only sent to
+	// URLs matching HeaderURLPrefixes, which must then be set.
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// HeaderURLPrefixes are the URLs which URLs must start with to be
+	// sent the Headers, e.g. https://example.com/releases/, so they
+	// aren't sent elsewhere, including by a redirect.  A URL must have
+	// the scheme and host of the prefix, and a path starting with the
+	// path segments of the prefix.
+	HeaderURLPrefixes []string `yaml:"headerURLPrefixes,omitempty"`
+
+	// Checksums, if set, are the sha256 digests of the documents in hex,
+	// by their URLs.  Read fails if a document doesn't match its digest.
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+
+	// CacheDir, if set, keeps the documents which have Checksums, so
+	// that they're only fetched once.
+	CacheDir string `yaml:"cacheDir,omitempty"`
+
+	// Timeout, if set, is how long each request may take.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// MaxSize, if set, bounds the size of each document.
+	// Defaults to 256MiB.
+	MaxSize int64 `yaml:"maxSize,omitempty"`
+
+	// Client, if set, sends the requests, e.g. with its own TLS config.
+	Client *http.Client `yaml:"-"`
+
+	// OmitReaderAnnotations will cause the reader to skip annotating
+	// Resources with their path and index.
+	OmitReaderAnnotations bool `yaml:"omitReaderAnnotations,omitempty"`
+
+	// SetAnnotations are annotations to set on the Resources as they are read.
+	SetAnnotations map[string]string `yaml:"setAnnotations,omitempty"`
+}
+
+var _ Reader = HTTPReader{}
+
+func (r HTTPReader) Read() ([]*yaml.RNode, error) {
+	if len(r.Headers) > 0 && len(r.HeaderURLPrefixes) == 0 {
+		return nil, errors.Errorf(
+			"headers require URL prefixes, so they're only sent to the URLs they're meant for")
+	}
+	var output ResourceNodeSlice
+	for _, u := range r.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "invalid url %s", u)
+		}
+		if parsed.Scheme != "https" {
+			return nil, errors.Errorf("%s must use https", u)
+		}
+		b, err := r.fetch(u)
+		if err != nil {
+			return nil, err
+		}
+
+		annotations := map[string]string{}
+		for k, v := range r.SetAnnotations {
+			annotations[k] = v
+		}
+		if !r.OmitReaderAnnotations {
+			annotations[kioutil.PathAnnotation] = path.Join(parsed.Host, parsed.Path)
+		}
+		nodes, err := (&ByteReader{
+			Reader:                bytes.NewReader(b),
+			OmitReaderAnnotations: r.OmitReaderAnnotations,
+			SetAnnotations:        annotations,
+		}).Read()
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "reading %s", u)
+		}
+		output = append(output, nodes...)
+	}
+	return output, nil
+}
+
+// fetch returns the document at u, from CacheDir if it's cached there,
+// after verifying its checksum if it has one.
+func (r HTTPReader) fetch(u string) ([]byte, error) {
+	checksum := strings.ToLower(r.Checksums[u])
+	var cachePath string
+	if checksum != "" && r.CacheDir != "" {
+		cachePath = filepath.Join(r.CacheDir, checksum+".yaml")
+		if b, err := ioutil.ReadFile(cachePath); err == nil && sha256Hex(b) == checksum {
+			return b, nil
+		}
+	}
+
+	b, err := r.get(u)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != "" {
+		if actual := sha256Hex(b); actual != checksum {
+			return nil, errors.Errorf("checksum of %s is %s, not %s", u, actual, checksum)
+		}
+	}
+	if cachePath != "" {
+		if err := os.MkdirAll(r.CacheDir, 0700); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err := ioutil.WriteFile(cachePath, b, 0600); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+	return b, nil
+}
+
+// get requests the document at u.
+func (r HTTPReader) get(u string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	req.Header.Set("Accept", "application/yaml")
+	if r.sendsHeaders(req.URL) {
+		for k, v := range r.Headers {
+			req.Header.Set(k, os.ExpandEnv(v))
+		}
+	}
+
+	client := &http.Client{}
+	if r.Client != nil {
+		*client = *r.Client
+	}
+	if r.Timeout != 0 {
+		client.Timeout = r.Timeout
+	}
+	// redirects are made with the headers of the request, so they
+	// must be https too, and lose the Headers outside the prefixes
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.Errorf("%s redirected too many times", u)
+		}
+		if req.URL.Scheme != "https" {
+			return errors.Errorf("%s must use https", req.URL)
+		}
+		if !r.sendsHeaders(req.URL) {
+			for k := range r.Headers {
+				req.Header.Del(k)
+			}
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "fetching %s", u)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.Errorf("fetching %s: responded %s", u, resp.Status)
+	}
+	max := r.MaxSize
+	if max == 0 {
+		max = defaultMaxHTTPSize
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if int64(len(b)) > max {
+		return nil, errors.Errorf("fetching %s: larger than %d bytes", u, max)
+	}
+	return b, nil
+}
+
+// sendsHeaders returns true if u matches one of HeaderURLPrefixes, so
+// e.g. https://example.com/a matches https://example.com/a/ but not
+// https://example.com.other.org or https://example.com/ab.
+func (r HTTPReader) sendsHeaders(u *url.URL) bool {
+	if u.User != nil {
+		return false
+	}
+	for _, prefix := range r.HeaderURLPrefixes {
+		p, err := url.Parse(prefix)
+		if err != nil || !strings.EqualFold(u.Scheme, p.Scheme) ||
+			!strings.EqualFold(u.Host, p.Host) {
+			continue
+		}
+		pp := strings.TrimSuffix(p.EscapedPath(), "/")
+		path := u.EscapedPath()
+		if pp == "" || path == pp || strings.HasPrefix(path, pp+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,192 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+const httpReaderDocument = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`
+
+// sha256 of httpReaderDocument
+const httpReaderChecksum = "e9b64183f570d0b0be6dc7b8011ff8a72b49a3814eb9319f3a448ce739faee9d"
+
+func TestHTTPReader_Read(t *testing.T) {
+	var auth string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(httpReaderDocument))
+	}))
+	defer srv.Close()
+
+	os.Setenv("HTTP_READER_TOKEN", "secret")
+	defer os.Unsetenv("HTTP_READER_TOKEN")
+	nodes, err := HTTPReader{
+		URLs:              []string{srv.URL + "/manifests/app.yaml"},
+		Headers:           map[string]string{"Authorization": "Bearer ${HTTP_READER_TOKEN}"},
+		HeaderURLPrefixes: []string{srv.URL + "/manifests"},
+		Client:            srv.Client(),
+	}.Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "Bearer secret", auth)
+
+	out := &bytes.Buffer{}
+	if !assert.NoError(t, ByteWriter{Writer: out, KeepReaderAnnotations: true}.Write(nodes)) {
+		t.FailNow()
+	}
+	host := srv.Listener.Addr().String()
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  annotations:
+    config.kubernetes.io/index: '0'
+    config.kubernetes.io/path: '`+host+`/manifests/app.yaml'
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  annotations:
+    config.kubernetes.io/index: '1'
+    config.kubernetes.io/path: '`+host+`/manifests/app.yaml'
+`, out.String())
+}
+
+func TestHTTPReader_Read_headers(t *testing.T) {
+	var auth []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/private/moved.yaml" {
+			http.Redirect(w, r, "/public/app.yaml", http.StatusFound)
+			return
+		}
+		auth = append(auth, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(httpReaderDocument))
+	}))
+	defer srv.Close()
+
+	r := HTTPReader{
+		URLs: []string{
+			srv.URL + "/private/app.yaml",
+			srv.URL + "/public/app.yaml",
+			srv.URL + "/private/moved.yaml",
+		},
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Client:  srv.Client(),
+	}
+	_, err := r.Read()
+	assert.EqualError(t, err,
+		"headers require URL prefixes, so they're only sent to the URLs they're meant for")
+
+	// only the URL matching the prefix is sent the header,
+	// which is dropped when it redirects outside the prefix
+	r.HeaderURLPrefixes = []string{srv.URL + "/private/"}
+	nodes, err := r.Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, nodes, 6)
+	assert.Equal(t, []string{"Bearer secret", "", ""}, auth)
+}
+
+func TestHTTPReader_Read_redirectToHTTP(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com/app.yaml", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	_, err := HTTPReader{URLs: []string{srv.URL}, Client: srv.Client()}.Read()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "http://example.com/app.yaml must use https")
+	}
+}
+
+func TestHTTPReader_Read_maxSize(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(httpReaderDocument))
+	}))
+	defer srv.Close()
+
+	_, err := HTTPReader{URLs: []string{srv.URL}, MaxSize: 10, Client: srv.Client()}.Read()
+	assert.EqualError(t, err, "fetching "+srv.URL+": larger than 10 bytes")
+
+	nodes, err := HTTPReader{
+		URLs:    []string{srv.URL},
+		MaxSize: int64(len(httpReaderDocument)),
+		Client:  srv.Client(),
+	}.Read()
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+}
+
+func TestHTTPReader_Read_checksum(t *testing.T) {
+	requests := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(httpReaderDocument))
+	}))
+	defer srv.Close()
+	d, err := ioutil.TempDir("", "kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+
+	u := srv.URL + "/app.yaml"
+	_, err = HTTPReader{
+		URLs:      []string{u},
+		Checksums: map[string]string{u: "0000"},
+		Client:    srv.Client(),
+	}.Read()
+	assert.EqualError(t, err,
+		"checksum of "+u+" is "+httpReaderChecksum+", not 0000")
+
+	r := HTTPReader{
+		URLs:      []string{u},
+		Checksums: map[string]string{u: httpReaderChecksum},
+		CacheDir:  d,
+		Client:    srv.Client(),
+	}
+	for i := 0; i < 2; i++ {
+		nodes, err := r.Read()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Len(t, nodes, 2)
+	}
+	// the second read is from the cache
+	assert.Equal(t, 2, requests)
+}
+
+func TestHTTPReader_Read_errors(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := HTTPReader{URLs: []string{"http://example.com/app.yaml"}}.Read()
+	assert.EqualError(t, err, "http://example.com/app.yaml must use https")
+
+	_, err = HTTPReader{URLs: []string{srv.URL}, Client: srv.Client()}.Read()
+	assert.EqualError(t, err, "fetching "+srv.URL+": responded 404 Not Found")
+}
@@ -4,7 +4,9 @@
 package kio
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -70,12 +72,25 @@ type LocalPackageReadWriter struct {
 	// NoDeleteFiles if set to true, LocalPackageReadWriter won't delete any files
 	NoDeleteFiles bool `yaml:"noDeleteFiles,omitempty"`
 
+	// OnFormattingChurn, if set, is told about the files which Write
+	// would only reformat, see LocalPackageWriter.
+	OnFormattingChurn func(paths []string) error `yaml:"-"`
+
+	// Atomic, if set, writes the files atomically, see LocalPackageWriter.
+	Atomic bool `yaml:"atomic,omitempty"`
+
+	// Backup, if set, keeps copies of the files which are overwritten,
+	// see LocalPackageWriter, and renames the files which are deleted
+	// to their backups.
+	Backup bool `yaml:"backup,omitempty"`
+
 	files sets.String
 }
 
 func (r *LocalPackageReadWriter) Read() ([]*yaml.RNode, error) {
 	nodes, err := LocalPackageReader{
 		PackagePath:         r.PackagePath,
+		PackageFileName:     r.PackageFileName,
 		MatchFilesGlob:      r.MatchFilesGlob,
 		IncludeSubpackages:  r.IncludeSubpackages,
 		ErrorIfNonResources: r.ErrorIfNonResources,
@@ -107,13 +122,22 @@ func (r *LocalPackageReadWriter) Write(nodes []*yaml.RNode) error {
 		PackagePath:           r.PackagePath,
 		ClearAnnotations:      clear,
 		KeepReaderAnnotations: r.KeepReaderAnnotations,
+		OnFormattingChurn:     r.OnFormattingChurn,
+		Atomic:                r.Atomic,
+		Backup:                r.Backup,
 	}.Write(nodes)
 	if err != nil {
 		return errors.Wrap(err)
 	}
 	deleteFiles := r.files.Difference(newFiles)
 	for f := range deleteFiles {
-		if err = os.Remove(filepath.Join(r.PackagePath, f)); err != nil {
+		path := filepath.Join(r.PackagePath, f)
+		if r.Backup {
+			err = os.Rename(path, path+BackupSuffix)
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil {
 			return errors.Wrap(err)
 		}
 	}
@@ -162,8 +186,34 @@ type LocalPackageReader struct {
 
 	// SetAnnotations are annotations to set on the Resources as they are read.
 	SetAnnotations map[string]string `yaml:"setAnnotations,omitempty"`
+
+	// MaxFileSize, if greater than 0, configures Read to skip files larger than
+	// MaxFileSize bytes without reading them.
+	MaxFileSize int64 `yaml:"maxFileSize,omitempty"`
+
+	// SkipNonResourceFiles configures Read to skip files which don't look like they
+	// contain Resources -- binary files, and files never mentioning apiVersion or kind --
+	// rather than parsing them.
+	SkipNonResourceFiles bool `yaml:"skipNonResourceFiles,omitempty"`
+
+	// SkippedFiles, if not nil, has a SkippedFile appended for each file skipped
+	// because of MaxFileSize or SkipNonResourceFiles.
+	SkippedFiles *[]SkippedFile `yaml:"-"`
+}
+
+// SkippedFile is a file which LocalPackageReader skipped without parsing.
+type SkippedFile struct {
+	// Path is the path of the file relative to the package.
+	Path string
+	// Reason is why the file was skipped.
+	Reason string
 }
 
+const (
+	SkipReasonTooLarge    = "file is larger than the maximum file size"
+	SkipReasonNotResource = "file doesn't look like it contains Resources"
+)
+
 var _ Reader = LocalPackageReader{}
 
 var DefaultMatch = []string{"*.yaml", "*.yml"}
@@ -222,8 +272,21 @@ func (r LocalPackageReader) Read() ([]*yaml.RNode, error) {
 			return errors.WrapPrefixf(err, pathRelativeTo)
 		}
 
+		if r.MaxFileSize > 0 && info.Size() > r.MaxFileSize {
+			r.skipFile(path, SkipReasonTooLarge)
+			return nil
+		}
+		b, err := ioutil.ReadFile(filepath.Join(pathRelativeTo, path))
+		if err != nil {
+			return errors.WrapPrefixf(err, filepath.Join(pathRelativeTo, path))
+		}
+		if r.SkipNonResourceFiles && !LooksLikeResources(b) {
+			r.skipFile(path, SkipReasonNotResource)
+			return nil
+		}
+
 		r.initReaderAnnotations(path, info)
-		nodes, err := r.readFile(filepath.Join(pathRelativeTo, path), info)
+		nodes, err := r.readFile(b)
 		if err != nil {
 			return errors.WrapPrefixf(err, filepath.Join(pathRelativeTo, path))
 		}
@@ -233,23 +296,33 @@ func (r LocalPackageReader) Read() ([]*yaml.RNode, error) {
 	return operand, err
 }
 
-// readFile reads the ResourceNodes from a file
-func (r *LocalPackageReader) readFile(path string, _ os.FileInfo) ([]*yaml.RNode, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
+// readFile reads the ResourceNodes from the contents of a file
+func (r *LocalPackageReader) readFile(b []byte) ([]*yaml.RNode, error) {
 	rr := &ByteReader{
 		DisableUnwrapping:     true,
-		Reader:                f,
+		Reader:                bytes.NewReader(b),
 		OmitReaderAnnotations: r.OmitReaderAnnotations,
 		SetAnnotations:        r.SetAnnotations,
 	}
 	return rr.Read()
 }
 
+// skipFile records that the file at path was skipped.
+func (r *LocalPackageReader) skipFile(path, reason string) {
+	if r.SkippedFiles != nil {
+		*r.SkippedFiles = append(*r.SkippedFiles, SkippedFile{Path: path, Reason: reason})
+	}
+}
+
+// LooksLikeResources returns false if b is certainly not YAML or JSON Resources:
+// if it contains a NUL byte, as binary files do, or never mentions both apiVersion
+// and kind.  It's much cheaper than parsing b.
+func LooksLikeResources(b []byte) bool {
+	return bytes.IndexByte(b, 0) < 0 &&
+		bytes.Contains(b, []byte("apiVersion")) &&
+		bytes.Contains(b, []byte("kind"))
+}
+
 // ShouldSkipFile returns true if the file should be skipped
 func (r *LocalPackageReader) ShouldSkipFile(info os.FileInfo) (bool, error) {
 	// check if the files are in scope
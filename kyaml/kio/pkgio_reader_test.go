@@ -264,6 +264,46 @@ metadata:
 	}
 }
 
+func TestLocalPackageReader_Read_skipFiles(t *testing.T) {
+	s := setupDirectories(t, "a")
+	defer s.clean()
+	s.writeFile(t, filepath.Join("a", "deploy.yaml"), []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`))
+	s.writeFile(t, filepath.Join("a", "big.yaml"), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: big
+data:
+  a: `+strings.Repeat("x", 100)+`
+`))
+	s.writeFile(t, filepath.Join("a", "binary.yaml"), []byte("apiVersion\x00kind"))
+	s.writeFile(t, filepath.Join("a", "values.yaml"), readFileB)
+
+	var skipped []SkippedFile
+	nodes, err := LocalPackageReader{
+		PackagePath:          "a",
+		MaxFileSize:          100,
+		SkipNonResourceFiles: true,
+		SkippedFiles:         &skipped,
+	}.Read()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, nodes, 1) {
+		meta, err := nodes[0].GetMeta()
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", meta.Name)
+	}
+	assert.Equal(t, []SkippedFile{
+		{Path: "big.yaml", Reason: SkipReasonTooLarge},
+		{Path: "binary.yaml", Reason: SkipReasonNotResource},
+		{Path: "values.yaml", Reason: SkipReasonNotResource},
+	}, skipped)
+}
+
 func TestLocalPackageReader_Read_pkgOmitAnnotations(t *testing.T) {
 	s := setupDirectories(t, filepath.Join("a", "b"), filepath.Join("a", "c"))
 	defer s.clean()
@@ -454,6 +494,29 @@ metadata:
 	}
 }
 
+func TestLocalPackageReadWriter_Read_skipSubpackage(t *testing.T) {
+	s := setupDirectories(t, filepath.Join("a", "b"), filepath.Join("a", "c"))
+	defer s.clean()
+	s.writeFile(t, filepath.Join("a", "b", "a_test.yaml"), readFileA)
+	s.writeFile(t, filepath.Join("a", "c", "c_test.yaml"), readFileB)
+	s.writeFile(t, filepath.Join("a", "c", "pkgFile"), pkgFile)
+
+	rw := &LocalPackageReadWriter{PackagePath: s.root, PackageFileName: "pkgFile"}
+	nodes, err := rw.Read()
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Len(t, nodes, 2)
+
+	rw = &LocalPackageReadWriter{
+		PackagePath: s.root, PackageFileName: "pkgFile", IncludeSubpackages: true}
+	nodes, err = rw.Read()
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Len(t, nodes, 3)
+}
+
 func TestLocalPackageReader_Read_includeSubpackage(t *testing.T) {
 	s := setupDirectories(t, filepath.Join("a", "b"), filepath.Join("a", "c"))
 	defer s.clean()
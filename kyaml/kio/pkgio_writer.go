@@ -4,9 +4,12 @@
 package kio
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
@@ -26,8 +29,29 @@ type LocalPackageWriter struct {
 
 	// ClearAnnotations will clear annotations before writing the resources
 	ClearAnnotations []string `yaml:"clearAnnotations,omitempty"`
+
+	// OnFormattingChurn, if set, is called before any file is written
+	// with the paths of the existing files which the write would change
+	// without changing their Resources semantically, e.g. only
+	// reindent them or drop their comments.  If it returns an error,
+	// Write fails with it without writing any file.
+	OnFormattingChurn func(paths []string) error `yaml:"-"`
+
+	// Atomic, if set, writes the files to a staging directory in the
+	// package, and only renames them into place once all of them are
+	// written, so that a failed write doesn't leave the package with
+	// some files written and others not.
+	Atomic bool `yaml:"atomic,omitempty"`
+
+	// Backup, if set, keeps a copy of each file which is overwritten,
+	// at the path of the file with BackupSuffix appended.
+	Backup bool `yaml:"backup,omitempty"`
 }
 
+// BackupSuffix is appended to the paths of the files which
+// LocalPackageWriter overwrites to name their backups.
+const BackupSuffix = ".bak"
+
 var _ Writer = LocalPackageWriter{}
 
 func (r LocalPackageWriter) Write(nodes []*yaml.RNode) error {
@@ -80,30 +104,64 @@ func (r LocalPackageWriter) Write(nodes []*yaml.RNode) error {
 		}
 	}
 
-	// write files
+	// render files
+	contents := map[string][]byte{}
 	for path := range outputFiles {
-		outputPath := filepath.Join(r.PackagePath, path)
-		err = os.MkdirAll(filepath.Dir(filepath.Join(r.PackagePath, path)), 0700)
-		if err != nil {
+		var b bytes.Buffer
+		w := ByteWriter{
+			Writer:                &b,
+			KeepReaderAnnotations: r.KeepReaderAnnotations,
+			ClearAnnotations:      r.ClearAnnotations,
+		}
+		if err = w.Write(outputFiles[path]); err != nil {
 			return errors.Wrap(err)
 		}
+		contents[path] = b.Bytes()
+	}
 
-		f, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(0600))
+	if r.OnFormattingChurn != nil {
+		churned, err := formattingChurn(r.PackagePath, contents)
 		if err != nil {
-			return errors.Wrap(err)
+			return err
 		}
-		if err := func() error {
-			defer f.Close()
-			w := ByteWriter{
-				Writer:                f,
-				KeepReaderAnnotations: r.KeepReaderAnnotations,
-				ClearAnnotations:      r.ClearAnnotations,
+		if len(churned) > 0 {
+			if err = r.OnFormattingChurn(churned); err != nil {
+				return err
 			}
-			if err = w.Write(outputFiles[path]); err != nil {
-				return errors.Wrap(err)
+		}
+	}
+
+	var paths []string
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// the backups are written before the files they back up
+	if r.Backup {
+		backups, err := r.readBackups(paths)
+		if err != nil {
+			return err
+		}
+		var backupPaths []string
+		for _, path := range paths {
+			if b, found := backups[path]; found {
+				backupPaths = append(backupPaths, path+BackupSuffix)
+				contents[path+BackupSuffix] = b
 			}
-			return nil
-		}(); err != nil {
+		}
+		paths = append(backupPaths, paths...)
+	}
+
+	if r.Atomic {
+		return r.writeAtomic(paths, contents)
+	}
+
+	// write files
+	for _, path := range paths {
+		outputPath := filepath.Join(r.PackagePath, path)
+		err = ioutil.WriteFile(outputPath, contents[path], os.FileMode(0600))
+		if err != nil {
 			return errors.Wrap(err)
 		}
 	}
@@ -111,6 +169,52 @@ func (r LocalPackageWriter) Write(nodes []*yaml.RNode) error {
 	return nil
 }
 
+// readBackups returns the contents of the existing files of paths, which
+// are about to be overwritten.
+func (r LocalPackageWriter) readBackups(paths []string) (map[string][]byte, error) {
+	backups := map[string][]byte{}
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(filepath.Join(r.PackagePath, path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		backups[path] = b
+	}
+	return backups, nil
+}
+
+// writeAtomic writes contents to a staging directory in the package,
+// and then renames them into place in the order of paths.  If any file
+// can't be written, the package is left unchanged.
+func (r LocalPackageWriter) writeAtomic(paths []string, contents map[string][]byte) error {
+	staging, err := ioutil.TempDir(r.PackagePath, ".kio-staging-")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer os.RemoveAll(staging)
+
+	for _, path := range paths {
+		stagedPath := filepath.Join(staging, path)
+		if err := os.MkdirAll(filepath.Dir(stagedPath), 0700); err != nil {
+			return errors.Wrap(err)
+		}
+		err := ioutil.WriteFile(stagedPath, contents[path], os.FileMode(0600))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	for _, path := range paths {
+		err := os.Rename(filepath.Join(staging, path), filepath.Join(r.PackagePath, path))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
 func (r LocalPackageWriter) errorIfMissingRequiredAnnotation(nodes []*yaml.RNode) error {
 	for i := range nodes {
 		for _, s := range requiredResourcePackageAnnotations {
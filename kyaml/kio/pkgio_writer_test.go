@@ -269,6 +269,145 @@ metadata:
 	}
 }
 
+// TestLocalPackageReadWriter_Write_formattingChurn tests:
+// - Files which would only be reformatted are reported before any is written
+// - Write fails without writing any file if OnFormattingChurn fails
+func TestLocalPackageReadWriter_Write_formattingChurn(t *testing.T) {
+	d, err := ioutil.TempDir("", "kyaml-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+	files := map[string]string{
+		"changed.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: changed\n",
+		"reindented.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n    name: reindented\n",
+		"unchanged.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: unchanged\n",
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(d, name), []byte(content), 0600)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	setChanged := FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+		for i := range nodes {
+			m, err := nodes[i].GetMeta()
+			if err != nil {
+				return nil, err
+			}
+			if m.Name == "changed" {
+				if err := nodes[i].PipeE(yaml.SetLabel("app", "web")); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return nodes, nil
+	})
+
+	var churned []string
+	rw := &LocalPackageReadWriter{
+		PackagePath: d,
+		OnFormattingChurn: func(paths []string) error {
+			churned = paths
+			return fmt.Errorf("formatting churn")
+		},
+	}
+	err = Pipeline{Inputs: []Reader{rw}, Filters: []Filter{setChanged}, Outputs: []Writer{rw}}.Execute()
+	if !assert.EqualError(t, err, "formatting churn") {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"reindented.yaml"}, churned)
+	for name, content := range files {
+		b, err := ioutil.ReadFile(filepath.Join(d, name))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, content, string(b))
+	}
+
+	rw.OnFormattingChurn = func(paths []string) error { return nil }
+	err = Pipeline{Inputs: []Reader{rw}, Filters: []Filter{setChanged}, Outputs: []Writer{rw}}.Execute()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	b, err := ioutil.ReadFile(filepath.Join(d, "reindented.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: reindented\n", string(b))
+}
+
+// TestLocalPackageWriter_Write_atomic tests:
+// - Atomic writes the same files, and leaves no staging directory
+func TestLocalPackageWriter_Write_atomic(t *testing.T) {
+	d, node1, node2, node3 := getWriterInputs(t)
+	defer os.RemoveAll(d)
+
+	w := LocalPackageWriter{PackagePath: d, Atomic: true}
+	err := w.Write([]*yaml.RNode{node2, node1, node3})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(d, "a", "b", "a_test.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `a: b #first
+---
+c: d # second
+`, string(b))
+
+	files, err := ioutil.ReadDir(d)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{"a"}, names)
+}
+
+// TestLocalPackageWriter_Write_backup tests:
+// - Backup keeps copies of the overwritten files only
+func TestLocalPackageWriter_Write_backup(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		d, node1, node2, node3 := getWriterInputs(t)
+		defer os.RemoveAll(d)
+		err := os.MkdirAll(filepath.Join(d, "a", "b"), 0700)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		err = ioutil.WriteFile(filepath.Join(d, "a", "b", "a_test.yaml"), []byte("a: old\n"), 0600)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		w := LocalPackageWriter{PackagePath: d, Atomic: atomic, Backup: true}
+		err = w.Write([]*yaml.RNode{node2, node1, node3})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(d, "a", "b", "a_test.yaml.bak"))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, "a: old\n", string(b))
+		b, err = ioutil.ReadFile(filepath.Join(d, "a", "b", "a_test.yaml"))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, `a: b #first
+---
+c: d # second
+`, string(b))
+		_, err = os.Stat(filepath.Join(d, "a", "b", "b_test.yaml.bak"))
+		assert.True(t, os.IsNotExist(err))
+	}
+}
+
 func getWriterInputs(t *testing.T) (string, *yaml.RNode, *yaml.RNode, *yaml.RNode) {
 	node1, err := yaml.Parse(`a: b #first
 metadata:
@@ -0,0 +1,77 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// formattingChurn returns the sorted paths of the files of contents
+// which exist under dir with other bytes, but the same documents.
+func formattingChurn(dir string, contents map[string][]byte) ([]string, error) {
+	var churned []string
+	for path, content := range contents {
+		old, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if bytes.Equal(old, content) {
+			continue
+		}
+		same, err := sameDocuments(old, content)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "comparing %s", path)
+		}
+		if same {
+			churned = append(churned, path)
+		}
+	}
+	sort.Strings(churned)
+	return churned, nil
+}
+
+// sameDocuments returns whether the YAML documents of a
+// and b have the same values, ignoring their formatting
+// and comments.
+func sameDocuments(a, b []byte) (bool, error) {
+	da, err := decodeDocuments(a)
+	if err != nil {
+		return false, err
+	}
+	db, err := decodeDocuments(b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(da, db), nil
+}
+
+func decodeDocuments(b []byte) ([]interface{}, error) {
+	var docs []interface{}
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc interface{}
+		err := d.Decode(&doc)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package log provides leveled, structured logging for kustomize libraries
+// and commands.
+//
+// Libraries log through the package level functions (Error, Warn, Info, Debug),
+// which write to the Logger set with SetLogger.  Messages are accompanied by
+// alternating key value pairs:
+//
+//	log.Info("loading plugin", "path", path)
+//
+// By default warnings and errors are written to stderr as text.
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+const (
+	ErrorLevel Level = iota
+	WarnLevel
+	InfoLevel
+	DebugLevel
+)
+
+var levelNames = []string{"error", "warn", "info", "debug"}
+
+func (l Level) String() string {
+	if l < ErrorLevel || l > DebugLevel {
+		return fmt.Sprintf("Level(%d)", l)
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the Level for its name.
+func ParseLevel(s string) (Level, error) {
+	for i := range levelNames {
+		if strings.EqualFold(s, levelNames[i]) {
+			return Level(i), nil
+		}
+	}
+	return 0, errors.Errorf("unknown log level %q, must be one of: %s",
+		s, strings.Join(levelNames, ", "))
+}
+
+// Format is the encoding of log messages.
+type Format string
+
+const (
+	// TextFormat writes messages as "LEVEL message key=value ...".
+	TextFormat Format = "text"
+	// JSONFormat writes each message as a json object on its own line.
+	JSONFormat Format = "json"
+)
+
+// ParseFormat returns the Format for its name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case TextFormat, JSONFormat:
+		return f, nil
+	default:
+		return "", errors.Errorf("unknown log format %q, must be one of: %s, %s",
+			s, TextFormat, JSONFormat)
+	}
+}
+
+// Logger writes leveled log messages.  keysAndValues are alternating
+// keys and values providing context for the message.
+type Logger interface {
+	Error(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Debug(msg string, keysAndValues ...interface{})
+}
+
+// New returns a Logger writing messages of level or higher severity to w.
+func New(w io.Writer, level Level, format Format) Logger {
+	return &writerLogger{w: w, level: level, format: format}
+}
+
+// writerLogger implements Logger by writing to an io.Writer.
+type writerLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	level  Level
+	format Format
+}
+
+func (l *writerLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(ErrorLevel, msg, keysAndValues)
+}
+
+func (l *writerLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(WarnLevel, msg, keysAndValues)
+}
+
+func (l *writerLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(InfoLevel, msg, keysAndValues)
+}
+
+func (l *writerLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(DebugLevel, msg, keysAndValues)
+}
+
+func (l *writerLogger) log(level Level, msg string, keysAndValues []interface{}) {
+	if level > l.level {
+		return
+	}
+	var b []byte
+	if l.format == JSONFormat {
+		b = formatJSON(level, msg, keysAndValues)
+	} else {
+		b = formatText(level, msg, keysAndValues)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.w.Write(b)
+}
+
+func formatText(level Level, msg string, keysAndValues []interface{}) []byte {
+	s := &strings.Builder{}
+	s.WriteString(strings.ToUpper(level.String()))
+	s.WriteString(" ")
+	s.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		k, v := keyValue(keysAndValues, i)
+		value := fmt.Sprint(v)
+		if strings.ContainsAny(value, " \t\n\"") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(s, " %s=%s", k, value)
+	}
+	s.WriteString("\n")
+	return []byte(s.String())
+}
+
+func formatJSON(level Level, msg string, keysAndValues []interface{}) []byte {
+	m := map[string]interface{}{"level": level.String(), "msg": msg}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		k, v := keyValue(keysAndValues, i)
+		if err, ok := v.(error); ok {
+			// errors don't marshal to json
+			v = err.Error()
+		}
+		m[k] = v
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		// fallback to the text format for values which can't be marshalled
+		return formatText(level, msg, keysAndValues)
+	}
+	return append(b, '\n')
+}
+
+// keyValue returns the key and value starting at index i.
+func keyValue(keysAndValues []interface{}, i int) (string, interface{}) {
+	k := fmt.Sprint(keysAndValues[i])
+	if i+1 >= len(keysAndValues) {
+		return k, "(MISSING)"
+	}
+	return k, keysAndValues[i+1]
+}
+
+var (
+	globalMu sync.RWMutex
+	global   = New(os.Stderr, WarnLevel, TextFormat)
+)
+
+// SetLogger sets the Logger used by the package level functions.
+func SetLogger(l Logger) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	global = l
+}
+
+// GetLogger returns the Logger used by the package level functions.
+func GetLogger() Logger {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return global
+}
+
+// Error logs msg at ErrorLevel to the global Logger.
+func Error(msg string, keysAndValues ...interface{}) {
+	GetLogger().Error(msg, keysAndValues...)
+}
+
+// Warn logs msg at WarnLevel to the global Logger.
+func Warn(msg string, keysAndValues ...interface{}) {
+	GetLogger().Warn(msg, keysAndValues...)
+}
+
+// Info logs msg at InfoLevel to the global Logger.
+func Info(msg string, keysAndValues ...interface{}) {
+	GetLogger().Info(msg, keysAndValues...)
+}
+
+// Debug logs msg at DebugLevel to the global Logger.
+func Debug(msg string, keysAndValues ...interface{}) {
+	GetLogger().Debug(msg, keysAndValues...)
+}
+
+// Flags are the command line flags configuring the global Logger.
+type Flags struct {
+	Level  string
+	Format string
+}
+
+// AddFlags registers the --log-level and --log-format flags on fs.
+func (f *Flags) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Level, "log-level", WarnLevel.String(),
+		"log messages of this or higher severity. one of: "+strings.Join(levelNames, ", "))
+	fs.StringVar(&f.Format, "log-format", string(TextFormat),
+		"format of log messages. one of: text, json")
+}
+
+// Configure sets the global Logger to write to w as specified by the flags.
+func (f *Flags) Configure(w io.Writer) error {
+	level, err := ParseLevel(f.Level)
+	if err != nil {
+		return err
+	}
+	format, err := ParseFormat(f.Format)
+	if err != nil {
+		return err
+	}
+	SetLogger(New(w, level, format))
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package log_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/log"
+)
+
+func TestLogger_text(t *testing.T) {
+	b := &bytes.Buffer{}
+	l := log.New(b, log.InfoLevel, log.TextFormat)
+	l.Debug("not logged")
+	l.Info("loading plugin", "path", "/a/b", "attempt", 1)
+	l.Warn("var not replaced", "var", "has space")
+	l.Error("failed", "error", fmt.Errorf("bad thing"))
+	assert.Equal(t, `INFO loading plugin path=/a/b attempt=1
+WARN var not replaced var="has space"
+ERROR failed error="bad thing"
+`, b.String())
+}
+
+func TestLogger_json(t *testing.T) {
+	b := &bytes.Buffer{}
+	l := log.New(b, log.DebugLevel, log.JSONFormat)
+	l.Debug("loading plugin", "path", "/a/b", "attempt", 1)
+	l.Error("failed", "error", fmt.Errorf("bad thing"), "dangling")
+	assert.Equal(t, `{"attempt":1,"level":"debug","msg":"loading plugin","path":"/a/b"}
+{"dangling":"(MISSING)","error":"bad thing","level":"error","msg":"failed"}
+`, b.String())
+}
+
+func TestFlags_Configure(t *testing.T) {
+	old := log.GetLogger()
+	defer log.SetLogger(old)
+
+	b := &bytes.Buffer{}
+	f := &log.Flags{Level: "debug", Format: "json"}
+	if !assert.NoError(t, f.Configure(b)) {
+		t.FailNow()
+	}
+	log.Debug("hello", "name", "world")
+	assert.Equal(t, `{"level":"debug","msg":"hello","name":"world"}
+`, b.String())
+
+	f = &log.Flags{Level: "verbose", Format: "text"}
+	assert.EqualError(t, f.Configure(b),
+		`unknown log level "verbose", must be one of: error, warn, info, debug`)
+
+	f = &log.Flags{Level: "info", Format: "xml"}
+	assert.EqualError(t, f.Configure(b),
+		`unknown log format "xml", must be one of: text, json`)
+}
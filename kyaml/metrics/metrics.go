@@ -0,0 +1,81 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics lets programs which run builds and functions
+// continuously, e.g. config controllers and render services,
+// collect metrics of them.
+//
+// Libraries report to a Recorder given to them, if any.  Metrics
+// are named and labelled as Prometheus expects, and Prometheus
+// adapts them to the Prometheus text format:
+//
+//	p := metrics.NewPrometheus()
+//	http.Handle("/metrics", p)
+//	opts.Metrics = p
+package metrics
+
+import (
+	"time"
+)
+
+// The metrics kustomize records.
+const (
+	// BuildsTotal counts kustomize builds, by result.
+	BuildsTotal = "kustomize_builds_total"
+
+	// BuildDurationSeconds is a histogram of
+	// the durations of builds, by result.
+	BuildDurationSeconds = "kustomize_build_duration_seconds"
+
+	// BuildCacheLookupsTotal counts lookups of bases in
+	// build caches, by result, which is hit or miss.
+	BuildCacheLookupsTotal = "kustomize_build_cache_lookups_total"
+
+	// FunctionRunsTotal counts runs of functions,
+	// by function and result.
+	FunctionRunsTotal = "kustomize_function_runs_total"
+
+	// FunctionDurationSeconds is a histogram of the
+	// durations of function runs, by function and result.
+	FunctionDurationSeconds = "kustomize_function_duration_seconds"
+)
+
+// Labels and their values.
+const (
+	LabelResult   = "result"
+	LabelFunction = "function"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+	ResultHit     = "hit"
+	ResultMiss    = "miss"
+)
+
+// Recorder records metrics.  Labels are given as alternating
+// names and values.  Implementations must be safe for
+// concurrent use.
+type Recorder interface {
+	// IncCounter adds one to the counter name.
+	IncCounter(name string, labels ...string)
+
+	// Observe adds value to the histogram name.
+	Observe(name string, value float64, labels ...string)
+}
+
+// RecordRun records a run which started at start, and
+// failed if err isn't nil, by adding one to counter, and
+// its duration in seconds to histogram.  The labels are
+// followed by the result label.  r may be nil.
+func RecordRun(r Recorder, counter, histogram string,
+	start time.Time, err error, labels ...string) {
+	if r == nil {
+		return
+	}
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+	}
+	labels = append(labels[:len(labels):len(labels)], LabelResult, result)
+	r.IncCounter(counter, labels...)
+	r.Observe(histogram, time.Since(start).Seconds(), labels...)
+}
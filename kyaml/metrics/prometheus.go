@@ -0,0 +1,206 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the
+// buckets of histograms, the Prometheus client's defaults.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// help documents the metrics kustomize records.
+var help = map[string]string{
+	BuildsTotal:             "Number of kustomize builds.",
+	BuildDurationSeconds:    "Durations of kustomize builds in seconds.",
+	BuildCacheLookupsTotal:  "Number of lookups of bases in build caches.",
+	FunctionRunsTotal:       "Number of function runs.",
+	FunctionDurationSeconds: "Durations of function runs in seconds.",
+}
+
+// Prometheus is a Recorder keeping metrics in memory, and
+// serving them over HTTP in the Prometheus text format.
+type Prometheus struct {
+	// Buckets are the upper bounds of the buckets of
+	// histograms, in increasing order.
+	Buckets []float64
+
+	mu         sync.Mutex
+	counters   map[string]map[string]float64
+	histograms map[string]map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheus returns a Prometheus with the DefaultBuckets.
+func NewPrometheus() *Prometheus {
+	return &Prometheus{
+		Buckets:    DefaultBuckets,
+		counters:   map[string]map[string]float64{},
+		histograms: map[string]map[string]*histogram{},
+	}
+}
+
+// IncCounter implements Recorder.
+func (p *Prometheus) IncCounter(name string, labels ...string) {
+	key := formatLabels(labels)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.counters[name] == nil {
+		p.counters[name] = map[string]float64{}
+	}
+	p.counters[name][key]++
+}
+
+// Observe implements Recorder.
+func (p *Prometheus) Observe(name string, value float64, labels ...string) {
+	key := formatLabels(labels)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.histograms[name] == nil {
+		p.histograms[name] = map[string]*histogram{}
+	}
+	h := p.histograms[name][key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(p.Buckets))}
+		p.histograms[name][key] = h
+	}
+	for i, le := range p.Buckets {
+		if value <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format,
+// sorted by name and labels.
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, name := range sortedKeys(p.counters) {
+		writeHeader(cw, name, "counter")
+		series := p.counters[name]
+		for _, labels := range sortedKeys(series) {
+			fmt.Fprintf(cw, "%s%s %s\n", name, labels, formatValue(series[labels]))
+		}
+	}
+	for _, name := range sortedKeys(p.histograms) {
+		writeHeader(cw, name, "histogram")
+		series := p.histograms[name]
+		for _, labels := range sortedKeys(series) {
+			h := series[labels]
+			for i, le := range p.Buckets {
+				fmt.Fprintf(cw, "%s_bucket%s %d\n",
+					name, withLabel(labels, "le", formatValue(le)), h.counts[i])
+			}
+			fmt.Fprintf(cw, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), h.count)
+			fmt.Fprintf(cw, "%s_sum%s %s\n", name, labels, formatValue(h.sum))
+			fmt.Fprintf(cw, "%s_count%s %d\n", name, labels, h.count)
+		}
+	}
+	if err := cw.w.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, cw.err
+}
+
+func writeHeader(w io.Writer, name, typ string) {
+	if h, found := help[name]; found {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, h)
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// labelEscaper escapes label values as the format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats alternating label names and values
+// as {name="value",...}, sorted by name.
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel adds a label to formatted labels.
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + labelEscaper.Replace(value) + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]map[string]float64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]map[string]*histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]float64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// countingWriter counts the bytes written, and
+// keeps the first error, for WriteTo.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
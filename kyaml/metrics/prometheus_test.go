@@ -0,0 +1,58 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus(t *testing.T) {
+	p := NewPrometheus()
+	p.Buckets = []float64{.5, 1}
+	p.IncCounter(BuildsTotal, LabelResult, ResultSuccess)
+	p.IncCounter(BuildsTotal, LabelResult, ResultSuccess)
+	p.IncCounter(BuildsTotal, LabelResult, ResultError)
+	p.IncCounter("custom_total", "b", "2", "a", "say \"hi\"\n")
+	p.Observe(FunctionDurationSeconds, .25, LabelFunction, "example.com/fn")
+	p.Observe(FunctionDurationSeconds, .75, LabelFunction, "example.com/fn")
+	p.Observe(FunctionDurationSeconds, 2, LabelFunction, "example.com/fn")
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `# TYPE custom_total counter
+custom_total{a="say \"hi\"\n",b="2"} 1
+# HELP kustomize_builds_total Number of kustomize builds.
+# TYPE kustomize_builds_total counter
+kustomize_builds_total{result="error"} 1
+kustomize_builds_total{result="success"} 2
+# HELP kustomize_function_duration_seconds Durations of function runs in seconds.
+# TYPE kustomize_function_duration_seconds histogram
+kustomize_function_duration_seconds_bucket{function="example.com/fn",le="0.5"} 1
+kustomize_function_duration_seconds_bucket{function="example.com/fn",le="1"} 2
+kustomize_function_duration_seconds_bucket{function="example.com/fn",le="+Inf"} 3
+kustomize_function_duration_seconds_sum{function="example.com/fn"} 3
+kustomize_function_duration_seconds_count{function="example.com/fn"} 3
+`, w.Body.String())
+}
+
+func TestRecordRun(t *testing.T) {
+	p := NewPrometheus()
+	RecordRun(nil, BuildsTotal, BuildDurationSeconds, time.Now(), nil)
+	RecordRun(p, FunctionRunsTotal, FunctionDurationSeconds,
+		time.Now(), errors.New("failed"), LabelFunction, "fn")
+	var b strings.Builder
+	_, err := p.WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(),
+		`kustomize_function_runs_total{function="fn",result="error"} 1`)
+	assert.Contains(t, b.String(),
+		`kustomize_function_duration_seconds_count{function="fn",result="error"} 1`)
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/go-openapi/spec"
 	"sigs.k8s.io/kustomize/kyaml/errors"
@@ -17,18 +18,61 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
-// globalSchema contains global state information about the openapi
-var globalSchema openapiData
+// globalSchema holds the current *openapiData.  The data is never changed
+// once stored; changes store a changed copy instead, so that reading
+// the schema from many goroutines doesn't need a lock.
+var globalSchema atomic.Value
+
+// globalSchemaMu serializes changes to globalSchema.
+var globalSchemaMu sync.Mutex
 
 // openapiData contains the parsed openapi state.  this is in a struct rather than
-// a list of vars so that it can be reset from tests.
+// a list of vars so that it can be replaced as a whole.
 type openapiData struct {
-	setup                sync.Once
+	// initialized is true once the built-in schema has been added,
+	// or would have been if noUseBuiltInSchema weren't set.
+	initialized          bool
 	schema               spec.Schema
 	schemaByResourceType map[yaml.TypeMeta]*spec.Schema
 	noUseBuiltInSchema   bool
 }
 
+// copy returns a copy of the data which can be changed
+// without changing the original.
+func (d *openapiData) copy() *openapiData {
+	c := &openapiData{
+		initialized:          d.initialized,
+		schema:               d.schema,
+		schemaByResourceType: make(map[yaml.TypeMeta]*spec.Schema, len(d.schemaByResourceType)),
+		noUseBuiltInSchema:   d.noUseBuiltInSchema,
+	}
+	for k, v := range d.schemaByResourceType {
+		c.schemaByResourceType[k] = v
+	}
+	c.schema.Definitions = make(spec.Definitions, len(d.schema.Definitions))
+	for k, v := range d.schema.Definitions {
+		c.schema.Definitions[k] = v
+	}
+	return c
+}
+
+// loadData returns the current data, which may not be initialized yet.
+func loadData() *openapiData {
+	if d, ok := globalSchema.Load().(*openapiData); ok {
+		return d
+	}
+	return &openapiData{}
+}
+
+// updateData replaces the current data with a copy changed by fn.
+func updateData(fn func(d *openapiData)) {
+	globalSchemaMu.Lock()
+	defer globalSchemaMu.Unlock()
+	d := loadData().copy()
+	fn(d)
+	globalSchema.Store(d)
+}
+
 // ResourceSchema wraps the OpenAPI Schema.
 type ResourceSchema struct {
 	// Schema is the OpenAPI schema for a Resource or field
@@ -48,8 +92,7 @@ func (rs *ResourceSchema) IsEmpty() bool {
 // which can be used for duck-typed Resources -- e.g. contains common fields such
 // as metadata, replicas and spec.template.spec
 func SchemaForResourceType(t yaml.TypeMeta) *ResourceSchema {
-	initSchema()
-	rs, found := globalSchema.schemaByResourceType[t]
+	rs, found := initSchema().schemaByResourceType[t]
 	if !found {
 		return nil
 	}
@@ -74,7 +117,13 @@ func AddSchemaFromFileUsingField(path, field string) error {
 	if err != nil {
 		return err
 	}
+	return AddSchemaFromBytesUsingField(b, field)
+}
 
+// AddSchemaFromBytesUsingField parses the OpenAPI definitions from the specified
+// field of the yaml or json document b, e.g. the content of a file before it's
+// written.  If field is the empty string, use the whole document as OpenAPI.
+func AddSchemaFromBytesUsingField(b []byte, field string) error {
 	// parse the yaml file (json is a subset of yaml, so will also parse)
 	y, err := yaml.Parse(string(b))
 	if err != nil {
@@ -123,28 +172,30 @@ func AddSchema(s []byte) (*spec.Schema, error) {
 
 // ResetOpenAPI resets the openapi data to empty
 func ResetOpenAPI() {
-	globalSchema = openapiData{}
+	globalSchemaMu.Lock()
+	defer globalSchemaMu.Unlock()
+	globalSchema.Store(&openapiData{})
 }
 
 // AddDefinitions adds the definitions to the global schema.
 func AddDefinitions(definitions spec.Definitions) {
-	// initialize values if they have not yet been set
-	if globalSchema.schemaByResourceType == nil {
-		globalSchema.schemaByResourceType = map[yaml.TypeMeta]*spec.Schema{}
-	}
-	if globalSchema.schema.Definitions == nil {
-		globalSchema.schema.Definitions = spec.Definitions{}
-	}
+	updateData(func(d *openapiData) {
+		d.addDefinitions(definitions)
+	})
+}
+
+// addDefinitions adds the definitions to the schema of d.
+func (d *openapiData) addDefinitions(definitions spec.Definitions) {
 
 	// index the schema definitions so we can lookup them up for Resources
 	for k := range definitions {
 		// index by GVK, if no GVK is found then it is the schema for a subfield
 		// of a Resource
-		d := definitions[k]
+		def := definitions[k]
 
 		// copy definitions to the schema
-		globalSchema.schema.Definitions[k] = d
-		gvk, found := d.VendorExtensible.Extensions[kubernetesGVKExtensionKey]
+		d.schema.Definitions[k] = def
+		gvk, found := def.VendorExtensible.Extensions[kubernetesGVKExtensionKey]
 		if !found {
 			continue
 		}
@@ -164,7 +215,7 @@ func AddDefinitions(definitions spec.Definitions) {
 		if g != "" {
 			apiVersion = g + "/" + apiVersion
 		}
-		globalSchema.schemaByResourceType[yaml.TypeMeta{Kind: m[kindKey].(string), APIVersion: apiVersion}] = &d
+		d.schemaByResourceType[yaml.TypeMeta{Kind: m[kindKey].(string), APIVersion: apiVersion}] = &def
 	}
 }
 
@@ -200,14 +251,13 @@ func GetSchema(s string) (*ResourceSchema, error) {
 // schema as part of the global schema.
 // Must be called before the schema is used.
 func SuppressBuiltInSchemaUse() {
-	globalSchema.noUseBuiltInSchema = true
+	updateData(func(d *openapiData) {
+		d.noUseBuiltInSchema = true
+	})
 }
 
 // Elements returns the Schema for the elements of an array.
 func (rs *ResourceSchema) Elements() *ResourceSchema {
-	// load the schema from swagger.json
-	initSchema()
-
 	if len(rs.Schema.Type) != 1 || rs.Schema.Type[0] != "array" {
 		// either not an array, or array has multiple types
 		return nil
@@ -251,9 +301,6 @@ func (rs *ResourceSchema) Lookup(path ...string) *ResourceSchema {
 
 // Field returns the Schema for a field.
 func (rs *ResourceSchema) Field(field string) *ResourceSchema {
-	// load the schema from swagger.json
-	initSchema()
-
 	// locate the Schema
 	s, found := rs.Schema.Properties[field]
 	switch {
@@ -324,35 +371,54 @@ const (
 	kindKey = "kind"
 )
 
-// initSchema parses the json schema
-func initSchema() {
-	globalSchema.setup.Do(func() {
-		if globalSchema.noUseBuiltInSchema {
+// initSchema parses the built-in json schema if it hasn't been yet,
+// and returns the current data.
+func initSchema() *openapiData {
+	if d := loadData(); d.initialized {
+		return d
+	}
+	updateData(func(d *openapiData) {
+		if d.initialized {
+			// initialized by another goroutine
+			return
+		}
+		d.initialized = true
+		if d.noUseBuiltInSchema {
 			// don't parse the built in schema
 			return
 		}
 
 		// parse the swagger, this should never fail
-		if _, err := parse(kubernetesapi.MustAsset(kubernetesAPIAssetName)); err != nil {
-			// this should never happen
-			panic(err)
-		}
-
-		if _, err := parse(kustomizationapi.MustAsset(kustomizationAPIAssetName)); err != nil {
-			// this should never happen
-			panic(err)
+		for _, asset := range [][]byte{
+			kubernetesapi.MustAsset(kubernetesAPIAssetName),
+			kustomizationapi.MustAsset(kustomizationAPIAssetName),
+		} {
+			sc, err := unmarshalSchema(asset)
+			if err != nil {
+				// this should never happen
+				panic(err)
+			}
+			d.addDefinitions(sc.Definitions)
 		}
 	})
+	return loadData()
 }
 
 // parse parses and indexes a single json schema
 func parse(b []byte) (*spec.Schema, error) {
-	var sc spec.Schema
+	sc, err := unmarshalSchema(b)
+	if err != nil {
+		return nil, err
+	}
+	AddDefinitions(sc.Definitions)
+	return sc, nil
+}
 
+func unmarshalSchema(b []byte) (*spec.Schema, error) {
+	var sc spec.Schema
 	if err := sc.UnmarshalJSON(b); err != nil {
 		return nil, errors.Wrap(err)
 	}
-	AddDefinitions(sc.Definitions)
 	return &sc, nil
 }
 
@@ -382,6 +448,5 @@ func resolve(root interface{}, ref *spec.Ref) (*spec.Schema, error) {
 }
 
 func rootSchema() *spec.Schema {
-	initSchema()
-	return &globalSchema.schema
+	return &initSchema().schema
 }
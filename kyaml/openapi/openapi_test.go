@@ -6,6 +6,7 @@ package openapi
 import (
 	"fmt"
 	"io/ioutil"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,14 +15,14 @@ import (
 
 func TestAddSchema(t *testing.T) {
 	// reset package vars
-	globalSchema = openapiData{}
+	ResetOpenAPI()
 
 	_, err := AddSchema(additionalSchema)
 	if !assert.NoError(t, err) {
 		t.FailNow()
 	}
 	s, err := GetSchema(`{"$ref": "#/definitions/io.k8s.config.setters.replicas"}`)
-	if !assert.Greater(t, len(globalSchema.schema.Definitions), 200) {
+	if !assert.Greater(t, len(loadData().schema.Definitions), 200) {
 		t.FailNow()
 	}
 	if !assert.NoError(t, err) {
@@ -33,7 +34,7 @@ func TestAddSchema(t *testing.T) {
 
 func TestNoUseBuiltInSchema_AddSchema(t *testing.T) {
 	// reset package vars
-	globalSchema = openapiData{}
+	ResetOpenAPI()
 
 	SuppressBuiltInSchemaUse()
 	_, err := AddSchema(additionalSchema)
@@ -41,7 +42,7 @@ func TestNoUseBuiltInSchema_AddSchema(t *testing.T) {
 		t.FailNow()
 	}
 	s, err := GetSchema(`{"$ref": "#/definitions/io.k8s.config.setters.replicas"}`)
-	if !assert.Equal(t, len(globalSchema.schema.Definitions), 1) {
+	if !assert.Equal(t, len(loadData().schema.Definitions), 1) {
 		t.FailNow()
 	}
 	if !assert.NoError(t, err) {
@@ -66,7 +67,7 @@ var additionalSchema = []byte(`
 
 func TestSchemaForResourceType(t *testing.T) {
 	// reset package vars
-	globalSchema = openapiData{}
+	ResetOpenAPI()
 
 	s := SchemaForResourceType(
 		yaml.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"})
@@ -153,7 +154,7 @@ openAPI:
 	if !assert.NoError(t, err) {
 		t.FailNow()
 	}
-	if !assert.Greater(t, len(globalSchema.schema.Definitions), 200) {
+	if !assert.Greater(t, len(loadData().schema.Definitions), 200) {
 		t.FailNow()
 	}
 	assert.Equal(t, `map[x-k8s-cli:map[setter:map[name:image-name value:nginx]]]`,
@@ -204,7 +205,7 @@ openAPI:
 	if !assert.NoError(t, err) {
 		t.FailNow()
 	}
-	if !assert.Greater(t, len(globalSchema.schema.Definitions), 200) {
+	if !assert.Greater(t, len(loadData().schema.Definitions), 200) {
 		t.FailNow()
 	}
 
@@ -233,7 +234,71 @@ kind: Example
 		t.FailNow()
 	}
 
-	if !assert.Equal(t, len(globalSchema.schema.Definitions), 0) {
+	if !assert.Equal(t, len(loadData().schema.Definitions), 0) {
 		t.FailNow()
 	}
 }
+
+// Reading the schema while definitions are added from
+// other goroutines must be safe, e.g. under -race.
+func TestConcurrentReadsAndWrites(t *testing.T) {
+	ResetOpenAPI()
+	deployment := yaml.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s := SchemaForResourceType(deployment)
+			if s == nil || s.Field("spec").Field("replicas") == nil {
+				t.Errorf("expected replicas schema")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			_, err := AddSchema([]byte(fmt.Sprintf(
+				`{"definitions": {"io.k8s.config.setters.s%d": {"type": "integer"}}}`, i)))
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 8; i++ {
+		s, err := GetSchema(fmt.Sprintf(
+			`{"$ref": "#/definitions/io.k8s.config.setters.s%d"}`, i))
+		if !assert.NoError(t, err) || !assert.Equal(t, "integer", s.Schema.Type[0]) {
+			t.FailNow()
+		}
+	}
+}
+
+// BenchmarkSchemaForResourceType compares reading the schema from
+// the snapshot with reading it under the lock serializing changes.
+func BenchmarkSchemaForResourceType(b *testing.B) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	tm := yaml.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+	if SchemaForResourceType(tm) == nil {
+		b.Fatal("missing schema")
+	}
+
+	b.Run("snapshot", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = SchemaForResourceType(tm)
+			}
+		})
+	})
+	b.Run("mutex", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				globalSchemaMu.Lock()
+				_ = loadData().schemaByResourceType[tm]
+				globalSchemaMu.Unlock()
+			}
+		})
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/log"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// continueFilter runs a function which, if it fails, passes its input on
+// unchanged rather than failing the run, see RunFns.ContinueOnError.
+type continueFilter struct {
+	filter   kio.Filter
+	function string
+
+	// err is the error of the function, if it failed.
+	err error
+}
+
+func (f *continueFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	// functions may change their input before they fail, so they're
+	// given a copy of it
+	input := make([]*yaml.RNode, len(nodes))
+	for i := range nodes {
+		input[i] = yaml.NewRNode(copyNode(nodes[i].YNode()))
+	}
+	output, err := f.filter.Filter(input)
+	if err != nil {
+		log.Warn("function failed, continuing with its input", "function", f.function, "error", err)
+		f.err = err
+		return nodes, nil
+	}
+	return output, nil
+}
+
+// copyNode returns a deep copy of n.
+func copyNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i := range n.Content {
+		c.Content[i] = copyNode(n.Content[i])
+	}
+	return &c
+}
+
+// unwrap returns the filter running the function of filter, without the
+// filters recording its metrics and continuing on its errors.
+func unwrap(filter kio.Filter) kio.Filter {
+	for {
+		switch f := filter.(type) {
+		case *continueFilter:
+			filter = f.filter
+		case *meteredFilter:
+			filter = f.filter
+		default:
+			return filter
+		}
+	}
+}
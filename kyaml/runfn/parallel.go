@@ -0,0 +1,140 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// parallelize returns the filters to run for fltrs, in which consecutive
+// container functions whose scopes don't overlap are run concurrently, by
+// at most parallelism at once.
+func parallelize(fltrs []kio.Filter, parallelism int) ([]kio.Filter, error) {
+	if parallelism <= 1 {
+		return fltrs, nil
+	}
+
+	var result []kio.Filter
+	var group parallelFilter
+	flush := func() {
+		switch len(group.filters) {
+		case 0:
+		case 1:
+			result = append(result, group.filters[0].filter)
+		default:
+			g := group
+			result = append(result, &g)
+		}
+		group = parallelFilter{parallelism: parallelism}
+	}
+	flush()
+
+	for i := range fltrs {
+		cf := containerFilter(fltrs[i])
+		if cf == nil {
+			flush()
+			result = append(result, fltrs[i])
+			continue
+		}
+		dir, err := cf.Exec.FunctionScope()
+		if err != nil {
+			return nil, err
+		}
+		if dir == "" {
+			// functions scoped to all the resources depend on, and are
+			// depended on by, every other function
+			flush()
+			result = append(result, fltrs[i])
+			continue
+		}
+		if group.overlaps(dir) {
+			flush()
+		}
+		group.filters = append(group.filters, scopedFilter{filter: fltrs[i], container: cf, dir: dir})
+	}
+	flush()
+	return result, nil
+}
+
+// containerFilter returns the container function filter runs, or nil
+// if it doesn't run one.
+func containerFilter(filter kio.Filter) *container.Filter {
+	cf, _ := unwrap(filter).(*container.Filter)
+	return cf
+}
+
+// scopedFilter is a container function scoped to the resources of dir.
+type scopedFilter struct {
+	filter    kio.Filter
+	container *container.Filter
+	dir       string
+}
+
+// parallelFilter runs container functions whose scopes don't overlap
+// concurrently.  Each function is given the resources in its scope, and
+// the resources output by the functions follow each other in the order
+// of the functions, followed by the resources in none of their scopes.
+//
+// Resources which a function outputs to the scope of another function
+// aren't input to the other function, as they would be were the functions
+// run one after the other.
+type parallelFilter struct {
+	filters     []scopedFilter
+	parallelism int
+}
+
+// overlaps returns true if the scope dir overlaps the scope of any
+// function of f, as with FunctionFilter, which scopes resources by
+// the prefixes of their directories.
+func (f *parallelFilter) overlaps(dir string) bool {
+	for _, sf := range f.filters {
+		if strings.HasPrefix(sf.dir, dir) || strings.HasPrefix(dir, sf.dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *parallelFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	inputs := make([][]*yaml.RNode, len(f.filters))
+	saved := nodes
+	for i := range f.filters {
+		var err error
+		inputs[i], saved, err = f.filters[i].container.Exec.Scope(saved)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	outputs := make([][]*yaml.RNode, len(f.filters))
+	errs := make([]error, len(f.filters))
+	workers := make(chan struct{}, f.parallelism)
+	var wg sync.WaitGroup
+	for i := range f.filters {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			outputs[i], errs[i] = f.filters[i].filter.Filter(inputs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var result []*yaml.RNode
+	for i := range outputs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		result = append(result, outputs[i]...)
+	}
+	return append(result, saved...), nil
+}
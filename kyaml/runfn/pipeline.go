@@ -0,0 +1,117 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// PipelineKind is the kind of the resources declaring a Pipeline.
+const PipelineKind = "Pipeline"
+
+// ReadPipeline reads the Pipeline in the file at path, which declares
+// the functions to run in the order of its steps, rather than discovering
+// them in the package.  The functions are returned as function configs
+// for Functions.
+//
+// Each step is a function spec, as in the config.kubernetes.io/function
+// annotation, and the config of the function, which defaults to an empty
+// ConfigMap.  Paths of starlark and rego functions are relative to the
+// package.
+//
+//	apiVersion: config.kubernetes.io/v1alpha1
+//	kind: Pipeline
+//	metadata:
+//	  name: app
+//	steps:
+//	- container:
+//	    image: gcr.io/example/set-namespace:v1
+//	  config:
+//	    apiVersion: v1
+//	    kind: ConfigMap
+//	    data:
+//	      namespace: app
+//	- starlark:
+//	    path: fns/set-replicas.star
+func ReadPipeline(path string) ([]*yaml.RNode, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	pipeline, err := yaml.Parse(string(b))
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "reading pipeline %s", path)
+	}
+	meta, err := pipeline.GetMeta()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "reading pipeline %s", path)
+	}
+	if meta.Kind != PipelineKind {
+		return nil, errors.Errorf("%s must be a %s, not a %s", path, PipelineKind, meta.Kind)
+	}
+
+	steps, err := pipeline.Pipe(yaml.Lookup("steps"))
+	if err != nil || steps == nil {
+		return nil, errors.Errorf("pipeline %s has no steps", path)
+	}
+	elements, err := steps.Elements()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "reading pipeline %s", path)
+	}
+
+	var fns []*yaml.RNode
+	for i, step := range elements {
+		fn, err := pipelineFunction(meta.Name, i, step)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "step %d of pipeline %s", i, path)
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// pipelineFunction returns the function config of the i'th step of the
+// pipeline name, annotated with the function spec of the step.
+func pipelineFunction(name string, i int, step *yaml.RNode) (*yaml.RNode, error) {
+	fn, err := step.Pipe(yaml.Lookup("config"))
+	if err != nil {
+		return nil, err
+	}
+	if fn == nil {
+		fn, err = yaml.Parse(fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s-%d
+`, name, i))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the step without its config is the function spec
+	if _, err := step.Pipe(yaml.Clear("config")); err != nil {
+		return nil, err
+	}
+	spec, err := step.String()
+	if err != nil {
+		return nil, err
+	}
+	value := yaml.NewScalarRNode(spec)
+	value.YNode().Style = yaml.LiteralStyle
+	err = fn.PipeE(
+		yaml.LookupCreate(yaml.MappingNode, "metadata", "annotations"),
+		yaml.SetField(runtimeutil.FunctionAnnotationKey, value))
+	if err != nil {
+		return nil, err
+	}
+	if s := runtimeutil.GetFunctionSpec(fn); s == nil || functionName(*s) == "unknown" {
+		return nil, errors.Errorf("no function declared")
+	}
+	return fn, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Policy restricts the container functions which may run, e.g. so that
+// developers may only run the functions their platform team trusts.
+//
+//	allow:
+//	- glob: gcr.io/my-org/*
+//	- regex: ^docker\.io/library/[a-z-]+:v[0-9.]+$
+//	deny:
+//	- glob: gcr.io/my-org/experimental-*
+//	denyNetwork: true
+//	denyMounts: true
+type Policy struct {
+	// Allow, if set, are the images which may run.  Other images may not.
+	Allow []ImagePattern `yaml:"allow,omitempty"`
+
+	// Deny are the images which may not run, even if they're allowed.
+	Deny []ImagePattern `yaml:"deny,omitempty"`
+
+	// DenyNetwork, if true, denies functions access to the network.
+	DenyNetwork bool `yaml:"denyNetwork,omitempty"`
+
+	// DenyMounts, if true, denies functions storage mounts.
+	DenyMounts bool `yaml:"denyMounts,omitempty"`
+}
+
+// ImagePattern matches images by a glob, as with path.Match, or by a
+// regular expression.
+type ImagePattern struct {
+	Glob  string `yaml:"glob,omitempty"`
+	Regex string `yaml:"regex,omitempty"`
+}
+
+func (p ImagePattern) String() string {
+	if p.Regex != "" {
+		return "regex " + p.Regex
+	}
+	return "glob " + p.Glob
+}
+
+// match returns true if image matches the pattern.
+func (p ImagePattern) match(image string) (bool, error) {
+	if (p.Glob == "") == (p.Regex == "") {
+		return false, errors.Errorf("image patterns must have either a glob or a regex")
+	}
+	if p.Regex != "" {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return false, errors.WrapPrefixf(err, "invalid image pattern %s", p)
+		}
+		return re.MatchString(image), nil
+	}
+	match, err := path.Match(p.Glob, image)
+	if err != nil {
+		return false, errors.WrapPrefixf(err, "invalid image pattern %s", p)
+	}
+	return match, nil
+}
+
+// PolicyViolation is the error of a function which the Policy doesn't
+// allow to run.
+type PolicyViolation struct {
+	// Image is the image of the function.
+	Image string
+
+	// Reason is why the function may not run.
+	Reason string
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("function %s violates the policy: %s", v.Image, v.Reason)
+}
+
+// ReadPolicy reads a Policy from the file at path.
+func ReadPolicy(path string) (*Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	p := &Policy{}
+	if err := yaml.Unmarshal(b, p); err != nil {
+		return nil, errors.WrapPrefixf(err, "reading policy %s", path)
+	}
+	// ensure the patterns are valid before any function runs
+	for _, pattern := range append(p.Allow, p.Deny...) {
+		if _, err := pattern.match(""); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Check returns a PolicyViolation if the function running image, with
+// or without network access and storage mounts, may not run.
+func (p *Policy) Check(image string, network, mounts bool) error {
+	if len(p.Allow) > 0 {
+		allowed, err := p.matchAny(p.Allow, image)
+		if err != nil {
+			return err
+		}
+		if allowed == nil {
+			return &PolicyViolation{Image: image, Reason: "the image isn't allowed"}
+		}
+	}
+	denied, err := p.matchAny(p.Deny, image)
+	if err != nil {
+		return err
+	}
+	if denied != nil {
+		return &PolicyViolation{Image: image, Reason: "the image is denied by " + denied.String()}
+	}
+	if network && p.DenyNetwork {
+		return &PolicyViolation{Image: image, Reason: "network access is denied"}
+	}
+	if mounts && p.DenyMounts {
+		return &PolicyViolation{Image: image, Reason: "storage mounts are denied"}
+	}
+	return nil
+}
+
+// matchAny returns the first of patterns which image matches, or nil
+// if it matches none.
+func (p *Policy) matchAny(patterns []ImagePattern, image string) (*ImagePattern, error) {
+	for i := range patterns {
+		match, err := patterns[i].match(image)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			return &patterns[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ResultsFileName is the file of ResultsDir the results of all the
+// functions are merged into.
+const ResultsFileName = "results.yaml"
+
+// Results are the results of all the functions of a run, merged into
+// one document, e.g. so CI can report them across the functions.
+//
+//	items:
+//	- function: gcr.io/example/validate:v1
+//	  severity: error
+//	  message: replicas must be at most 10
+//	  file: apps/deployment.yaml
+//	  field: spec.replicas
+//	  resource:
+//	    apiVersion: apps/v1
+//	    kind: Deployment
+//	    name: app
+type Results struct {
+	Items []ResultItem `yaml:"items,omitempty"`
+}
+
+// ResultItem is a result of a function.
+type ResultItem struct {
+	// Function is the name of the function which emitted the result.
+	Function string `yaml:"function,omitempty"`
+
+	// Severity is the severity of the result.
+	Severity framework.Severity `yaml:"severity,omitempty"`
+
+	// Message is a human readable message.
+	Message string `yaml:"message,omitempty"`
+
+	// File is the path of the file containing the resource.
+	File string `yaml:"file,omitempty"`
+
+	// Field is the path of the field of the resource.
+	Field string `yaml:"field,omitempty"`
+
+	// Resource identifies the resource.
+	Resource yaml.ResourceIdentifier `yaml:"resource,omitempty"`
+}
+
+// ExitCode returns the highest of the exit codes which codes map the
+// severities of the items to.  Items whose severity isn't in codes exit
+// with 1 if they're errors, and 0 otherwise.
+func (r *Results) ExitCode(codes map[framework.Severity]int) int {
+	var code int
+	for _, item := range r.Items {
+		c, found := codes[item.Severity]
+		if !found && item.Severity == framework.Error {
+			c = 1
+		}
+		if c > code {
+			code = c
+		}
+	}
+	return code
+}
+
+// ExitCodeError is the error of a run which exits with a non-zero
+// code, because a function failed or by the severities of the results.
+type ExitCodeError struct {
+	// Code is the exit code of the run.
+	Code int
+
+	// Err is the error of the run, if a function failed.
+	Err error
+
+	// Results are the results of all the functions.
+	Results *Results
+}
+
+func (e *ExitCodeError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("function results exit with code %d", e.Code)
+}
+
+// mergeResults merges the results of the functions of fltrs into one
+// Results, writes them to ResultsDir, and, if ExitCodes is set, maps
+// them and err, the error of the run, to the exit code of the run.
+func (r RunFns) mergeResults(fltrs []kio.Filter, err error) error {
+	results := &Results{}
+	for i := range fltrs {
+		items, rerr := resultItems(fltrs[i])
+		if rerr != nil {
+			if err != nil {
+				return err
+			}
+			return rerr
+		}
+		results.Items = append(results.Items, items...)
+	}
+
+	if r.ResultsDir != "" && len(results.Items) > 0 {
+		b := &bytes.Buffer{}
+		werr := yaml.NewEncoder(b).Encode(results)
+		if werr == nil {
+			werr = ioutil.WriteFile(filepath.Join(r.ResultsDir, ResultsFileName), b.Bytes(), 0600)
+		}
+		if werr != nil && err == nil {
+			return errors.Wrap(werr)
+		}
+	}
+
+	if r.ExitCodes == nil {
+		return err
+	}
+	code := results.ExitCode(r.ExitCodes)
+	if err != nil && code == 0 {
+		code = 1
+	}
+	if code == 0 {
+		return nil
+	}
+	return &ExitCodeError{Code: code, Err: err, Results: results}
+}
+
+// resultItems returns the items of the results the function of filter
+// emitted, if it emitted any.
+func resultItems(filter kio.Filter) ([]ResultItem, error) {
+	var items []ResultItem
+	if cf, ok := filter.(*continueFilter); ok && cf.err != nil {
+		// the function failed, and the run continued
+		items = append(items, ResultItem{
+			Function: cf.function,
+			Severity: framework.Error,
+			Message:  "function failed: " + cf.err.Error(),
+		})
+	}
+	filter = unwrap(filter)
+	name := fmt.Sprint(filter)
+	if cf, ok := filter.(*container.Filter); ok {
+		name = cf.Image
+		filter = &cf.Exec
+	}
+	rf, ok := filter.(interface{ GetResults() *yaml.RNode })
+	if !ok || rf.GetResults() == nil {
+		return items, nil
+	}
+
+	var result framework.Result
+	if err := yaml.Unmarshal([]byte(rf.GetResults().MustString()), &result); err != nil {
+		return nil, errors.WrapPrefixf(err, "reading the results of function %s", name)
+	}
+	if result.Name != "" {
+		name = result.Name
+	}
+	for _, item := range result.Items {
+		ref := item.ResourceRef
+		items = append(items, ResultItem{
+			Function: name,
+			Severity: item.Severity,
+			Message:  item.Message,
+			File:     item.File.Path,
+			Field:    item.Field.Path,
+			Resource: yaml.ResourceIdentifier{
+				Name:       ref.Name,
+				Namespace:  ref.Namespace,
+				APIVersion: ref.APIVersion,
+				Kind:       ref.Kind,
+			},
+		})
+	}
+	return items, nil
+}
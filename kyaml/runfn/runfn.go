@@ -4,6 +4,7 @@
 package runfn
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io"
 	"os"
@@ -12,14 +13,21 @@ import (
 	"sort"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/metadata"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/endpoint"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/grpc"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/rego"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/metrics"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -55,6 +63,49 @@ type RunFns struct {
 	// NetworkName is the name of the docker network to use for the container
 	NetworkName string
 
+	// ContainerRuntime is the container engine to run container functions
+	// with, see container.GetRuntime.
+	ContainerRuntime string
+
+	// CacheDir, if set, caches the output of container functions in this
+	// directory, so they aren't run again for the same image and input,
+	// see container.Filter.
+	CacheDir string
+
+	// Env are environment variables given to container functions, along
+	// with those the functions declare, as KEY=VALUE, or KEY to export the
+	// local value of KEY, see container.Filter.
+	Env []string
+
+	// Timeout, if set, is how long each container function may run
+	// before it's killed, and how long endpoint functions may take to
+	// respond, see container.Filter and endpoint.Filter.
+	Timeout time.Duration
+
+	// ImagePullPolicy, if set, is when the images of container functions
+	// are pulled, see container.ImagePullPolicy.
+	ImagePullPolicy string
+
+	// Platform, if set, is the platform of the images of container
+	// functions to run, e.g. linux/amd64, see container.Filter.
+	Platform string
+
+	// Memory and CPUs, if set, are the default limits of the memory and
+	// CPUs of container functions which don't set their own, e.g. 512m and
+	// 1.5, so a misbehaving function can't exhaust the host, see
+	// container.Filter.
+	Memory string
+	CPUs   string
+
+	// Policy, if set, is checked before any container function runs,
+	// and restricts which may run.
+	Policy *Policy
+
+	// Parallelism, if greater than 1, is the most container functions run
+	// at once.  Consecutive container functions whose scopes don't overlap
+	// are run concurrently, rather than one after the other.
+	Parallelism int
+
 	// Output can be set to write the result to Output rather than back to the directory
 	Output io.Writer
 
@@ -68,15 +119,70 @@ type RunFns struct {
 	// EnableExec will enable exec functions
 	EnableExec bool
 
+	// EnableRego will enable functions evaluating rego policies
+	EnableRego bool
+
+	// EnableEndpoints will enable functions hosted as HTTPS services
+	EnableEndpoints bool
+
+	// EndpointHeaders are set on the requests to endpoint functions,
+	// e.g. an Authorization header, see endpoint.Filter.
+	EndpointHeaders map[string]string
+
+	// EndpointURLPrefixes, if set, are the URLs which the URLs of endpoint
+	// functions must start with.  They must be set with EndpointHeaders,
+	// so headers are only sent where they're meant to.
+	EndpointURLPrefixes []string
+
+	// EndpointTLSConfig, if set, configures the TLS of the requests to
+	// endpoint functions, see endpoint.TLSConfig.
+	EndpointTLSConfig *tls.Config
+
+	// EnableGRPC will enable functions served over gRPC, whose servers
+	// are started once per run and reused by all the functions they
+	// serve, see grpc.Servers.  Servers run as containers have the same
+	// options as container functions, and servers run as local
+	// executables also need EnableExec.
+	EnableGRPC bool
+
 	// DisableContainers will disable functions run as containers
 	DisableContainers bool
 
-	// ResultsDir is where to write each functions results
+	// ResultsDir is where to write each functions results, and the
+	// results of all the functions merged into ResultsFileName.
 	ResultsDir string
 
+	// ContinueOnError, if true, doesn't abort the run when a function
+	// fails: the input of the function is passed on unchanged, its error
+	// is added to the results, and Execute fails once all the functions
+	// have run, so one failing function doesn't hide the errors of others.
+	ContinueOnError bool
+
+	// ExitCodes, if set, maps the severities of the results of the
+	// functions to the exit code of the run: if any maps to a non-zero
+	// code, or a function fails, Execute returns an ExitCodeError.
+	ExitCodes map[framework.Severity]int
+
+	// FunctionMetadata, if set, finds the definitions of the
+	// functions, so their functionConfigs are validated against
+	// the schemas they declare before they're run.
+	FunctionMetadata *metadata.Finder
+
+	// Metrics, if set, records the runs of the functions.
+	Metrics metrics.Recorder
+
+	// OnFormattingChurn, if set, is told about the files of Path which
+	// writing the results back would only reformat, and may fail the
+	// run before any is written, see kio.LocalPackageWriter.
+	OnFormattingChurn func(paths []string) error
+
 	// resultsCount is used to generate the results filename for each container
 	resultsCount uint32
 
+	// grpcServers are the servers of the gRPC functions of the run, which
+	// are closed once it's done
+	grpcServers *grpc.Servers
+
 	// functionFilterProvider provides a filter to perform the function.
 	// this is a variable so it can be mocked in tests
 	functionFilterProvider func(
@@ -84,9 +190,8 @@ type RunFns struct {
 }
 
 // Execute runs the command
-func (r RunFns) Execute() error {
+func (r RunFns) Execute() (err error) {
 	// make the path absolute so it works on mac
-	var err error
 	r.Path, err = filepath.Abs(r.Path)
 	if err != nil {
 		return errors.Wrap(err)
@@ -94,6 +199,12 @@ func (r RunFns) Execute() error {
 
 	// default the containerFilterProvider if it hasn't been override.  Split out for testing.
 	(&r).init()
+	r.grpcServers = &grpc.Servers{}
+	defer func() {
+		if closeErr := r.grpcServers.Close(); err == nil {
+			err = closeErr
+		}
+	}()
 	nodes, fltrs, output, err := r.getNodesAndFilters()
 	if err != nil {
 		return err
@@ -110,7 +221,11 @@ func (r RunFns) getNodesAndFilters() (
 	// the same one for reading must be used for writing if deleting Resources
 	var outputPkg *kio.LocalPackageReadWriter
 	if r.Path != "" {
-		outputPkg = &kio.LocalPackageReadWriter{PackagePath: r.Path, MatchFilesGlob: kio.MatchAll}
+		outputPkg = &kio.LocalPackageReadWriter{
+			PackagePath:       r.Path,
+			MatchFilesGlob:    kio.MatchAll,
+			OnFormattingChurn: r.OnFormattingChurn,
+		}
 	}
 
 	if r.Input == nil {
@@ -169,11 +284,15 @@ func (r RunFns) runFunctions(
 		// the output is nil (reading from Input)
 		outputs = append(outputs, kio.ByteWriter{Writer: r.Output})
 	}
-	err := kio.Pipeline{
-		Inputs: []kio.Reader{input}, Filters: fltrs, Outputs: outputs}.Execute()
+	pipelineFltrs, err := parallelize(fltrs, r.Parallelism)
 	if err != nil {
 		return err
 	}
+	err = kio.Pipeline{
+		Inputs: []kio.Reader{input}, Filters: pipelineFltrs, Outputs: outputs}.Execute()
+	if err != nil {
+		return r.mergeResults(fltrs, err)
+	}
 
 	// check for deferred function errors
 	var errs []string
@@ -186,10 +305,16 @@ func (r RunFns) runFunctions(
 			errs = append(errs, cf.GetExit().Error())
 		}
 	}
+	// check for the errors of functions the run continued after
+	for i := range fltrs {
+		if cf, ok := fltrs[i].(*continueFilter); ok && cf.err != nil {
+			errs = append(errs, fmt.Sprintf("function %s failed: %v", cf.function, cf.err))
+		}
+	}
 	if len(errs) > 0 {
-		return fmt.Errorf(strings.Join(errs, "\n---\n"))
+		return r.mergeResults(fltrs, fmt.Errorf(strings.Join(errs, "\n---\n")))
 	}
-	return nil
+	return r.mergeResults(fltrs, nil)
 }
 
 // getFunctionsFromInput scans the input for functions and runs them
@@ -241,13 +366,31 @@ func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
 	for i := range fns {
 		api := fns[i]
 		spec := runtimeutil.GetFunctionSpec(api)
-		if spec.Container.Network.Required {
+		if spec.Container.Network.Required || spec.GRPC.Network.Required {
 			if !r.Network {
 				// TODO(eddiezane): Provide error info about which function needs the network
 				return fltrs, errors.Errorf("network required but not enabled with --network")
 			}
 			spec.Network = r.NetworkName
 		}
+		if r.Policy != nil && !r.DisableContainers && spec.Container.Image != "" {
+			err := r.Policy.Check(
+				spec.Container.Image, spec.Network != "", len(r.StorageMounts) > 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if r.Policy != nil && r.EnableGRPC && !r.DisableContainers && spec.GRPC.Image != "" {
+			// the servers run as containers like container functions
+			err := r.Policy.Check(
+				spec.GRPC.Image, spec.Network != "", len(r.StorageMounts) > 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := r.validateFunctionConfig(*spec, api); err != nil {
+			return nil, err
+		}
 		c, err := r.functionFilterProvider(*spec, api)
 		if err != nil {
 			return nil, err
@@ -260,11 +403,74 @@ func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
 		if global && ok {
 			cf.Exec.GlobalScope = true
 		}
+		if r.Metrics != nil {
+			c = &meteredFilter{filter: c, function: functionName(*spec), metrics: r.Metrics}
+		}
+		if r.ContinueOnError {
+			c = &continueFilter{filter: c, function: functionName(*spec)}
+		}
 		fltrs = append(fltrs, c)
 	}
 	return fltrs, nil
 }
 
+// validateFunctionConfig checks the functionConfig api of a
+// function against the schema of the function, if it's found.
+func (r RunFns) validateFunctionConfig(spec runtimeutil.FunctionSpec, api *yaml.RNode) error {
+	if r.FunctionMetadata == nil {
+		return nil
+	}
+	d, v, err := r.FunctionMetadata.Find(spec.Container.Image, api)
+	if err != nil || v == nil {
+		return err
+	}
+	if err = v.Validate(api); err != nil {
+		return errors.Errorf("functionConfig of function %s doesn't match its schema: %v",
+			d.Spec.Names.Kind, err)
+	}
+	return nil
+}
+
+// meteredFilter records the runs of a function's Filter.
+type meteredFilter struct {
+	filter   kio.Filter
+	function string
+	metrics  metrics.Recorder
+}
+
+func (f *meteredFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	start := time.Now()
+	nodes, err := f.filter.Filter(nodes)
+	metrics.RecordRun(f.metrics, metrics.FunctionRunsTotal, metrics.FunctionDurationSeconds,
+		start, err, metrics.LabelFunction, f.function)
+	return nodes, err
+}
+
+// functionName names the function of spec in metrics.
+func functionName(spec runtimeutil.FunctionSpec) string {
+	switch {
+	case spec.Container.Image != "":
+		return spec.Container.Image
+	case spec.Exec.Path != "":
+		return "exec:" + spec.Exec.Path
+	case spec.Starlark.Path != "" || spec.Starlark.URL != "":
+		return "starlark:" + spec.Starlark.Path + spec.Starlark.URL
+	case spec.Starlark.Program != "":
+		return "starlark:" + spec.Starlark.Name
+	case spec.Rego.Path != "":
+		return "rego:" + spec.Rego.Path
+	case spec.Rego.Program != "":
+		return "rego:" + spec.Rego.Name
+	case spec.Endpoint.URL != "":
+		return "endpoint:" + spec.Endpoint.URL
+	case spec.GRPC.Image != "":
+		return "grpc:" + spec.GRPC.Image
+	case spec.GRPC.Path != "":
+		return "grpc:" + spec.GRPC.Path
+	}
+	return "unknown"
+}
+
 // sortFns sorts functions so that functions with the longest paths come first
 func sortFns(buff *kio.PackageBuffer) {
 	// sort the nodes so that we traverse them depth first
@@ -344,19 +550,36 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode) (kio.Filter
 		atomic.AddUint32(&r.resultsCount, 1)
 	}
 	if !r.DisableContainers && spec.Container.Image != "" {
+		memory, cpus := spec.Container.Memory, spec.Container.CPUs
+		if memory == "" {
+			memory = r.Memory
+		}
+		if cpus == "" {
+			cpus = r.CPUs
+		}
 		// TODO: Add a test for this behavior
 		cf := &container.Filter{
-			Image:         spec.Container.Image,
-			Network:       spec.Network,
-			StorageMounts: r.StorageMounts,
+			Image:           spec.Container.Image,
+			Network:         spec.Network,
+			StorageMounts:   r.StorageMounts,
+			Runtime:         container.Runtime(r.ContainerRuntime),
+			Env:             append(append([]string(nil), spec.Container.Env...), r.Env...),
+			CacheDir:        r.CacheDir,
+			Timeout:         r.Timeout,
+			ImagePullPolicy: container.ImagePullPolicy(r.ImagePullPolicy),
+			Platform:        r.Platform,
+			Memory:          memory,
+			CPUs:            cpus,
 		}
 		cf.Exec.FunctionConfig = api
 		cf.Exec.GlobalScope = r.GlobalScope
+		cf.Exec.Selector = spec.Selector
 		cf.Exec.ResultsFile = resultsFile
 		cf.Exec.DeferFailure = spec.DeferFailure
 		return cf, nil
 	}
-	if r.EnableStarlark && (spec.Starlark.Path != "" || spec.Starlark.URL != "") {
+	if r.EnableStarlark && (spec.Starlark.Path != "" || spec.Starlark.URL != "" ||
+		spec.Starlark.Program != "") {
 		// the script path is relative to the function config file
 		m, err := api.GetMeta()
 		if err != nil {
@@ -377,22 +600,101 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode) (kio.Filter
 			}
 			p = filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), spec.Starlark.Path))
 		}
-		fmt.Println(p)
 
-		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: p, URL: spec.Starlark.URL}
+		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: p, URL: spec.Starlark.URL,
+			Program: spec.Starlark.Program}
 
 		sf.FunctionConfig = api
 		sf.GlobalScope = r.GlobalScope
+		sf.Selector = spec.Selector
 		sf.ResultsFile = resultsFile
 		sf.DeferFailure = spec.DeferFailure
 		return sf, nil
 	}
 
+	if r.EnableRego && (spec.Rego.Path != "" || spec.Rego.Program != "") {
+		// the policy path is relative to the function config file
+		var p string
+		if spec.Rego.Path != "" {
+			m, err := api.GetMeta()
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			p = filepath.ToSlash(path.Clean(m.Annotations[kioutil.PathAnnotation]))
+			spec.Rego.Path = filepath.ToSlash(path.Clean(spec.Rego.Path))
+			if filepath.IsAbs(spec.Rego.Path) || path.IsAbs(spec.Rego.Path) {
+				return nil, errors.Errorf(
+					"absolute function path %s not allowed", spec.Rego.Path)
+			}
+			if strings.HasPrefix(spec.Rego.Path, "..") {
+				return nil, errors.Errorf(
+					"function path %s not allowed to start with ../", spec.Rego.Path)
+			}
+			p = filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), spec.Rego.Path))
+		}
+
+		rf := &rego.Filter{Name: spec.Rego.Name, Path: p, Program: spec.Rego.Program}
+
+		rf.FunctionConfig = api
+		rf.GlobalScope = r.GlobalScope
+		rf.Selector = spec.Selector
+		rf.ResultsFile = resultsFile
+		rf.DeferFailure = spec.DeferFailure
+		return rf, nil
+	}
+
+	if r.EnableEndpoints && spec.Endpoint.URL != "" {
+		ef := &endpoint.Filter{
+			URL:         spec.Endpoint.URL,
+			Timeout:     r.Timeout,
+			Headers:     r.EndpointHeaders,
+			URLPrefixes: r.EndpointURLPrefixes,
+			TLSConfig:   r.EndpointTLSConfig,
+		}
+		// fail before any function runs
+		if err := ef.Check(); err != nil {
+			return nil, err
+		}
+
+		ef.FunctionConfig = api
+		ef.GlobalScope = r.GlobalScope
+		ef.Selector = spec.Selector
+		ef.ResultsFile = resultsFile
+		ef.DeferFailure = spec.DeferFailure
+		return ef, nil
+	}
+
+	if r.EnableGRPC && (spec.GRPC.Image != "" && !r.DisableContainers ||
+		spec.GRPC.Image == "" && spec.GRPC.Path != "" && r.EnableExec) {
+		// the server is started by the first function it serves
+		s, err := r.grpcServers.Get(container.Filter{
+			Image:           spec.GRPC.Image,
+			Network:         spec.Network,
+			StorageMounts:   r.StorageMounts,
+			Runtime:         container.Runtime(r.ContainerRuntime),
+			ImagePullPolicy: container.ImagePullPolicy(r.ImagePullPolicy),
+			Platform:        r.Platform,
+			Memory:          r.Memory,
+			CPUs:            r.CPUs,
+		}, spec.GRPC.Path)
+		if err != nil {
+			return nil, err
+		}
+		gf := &grpc.Filter{Server: s, Timeout: r.Timeout}
+
+		gf.FunctionConfig = api
+		gf.GlobalScope = r.GlobalScope
+		gf.ResultsFile = resultsFile
+		gf.DeferFailure = spec.DeferFailure
+		return gf, nil
+	}
+
 	if r.EnableExec && spec.Exec.Path != "" {
 		ef := &exec.Filter{Path: spec.Exec.Path}
 
 		ef.FunctionConfig = api
 		ef.GlobalScope = r.GlobalScope
+		ef.Selector = spec.Selector
 		ef.ResultsFile = resultsFile
 		ef.DeferFailure = spec.DeferFailure
 		return ef, nil
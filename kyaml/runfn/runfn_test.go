@@ -16,10 +16,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/metadata"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/metrics"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -215,7 +219,9 @@ func TestRunFns_getFilters(t *testing.T) {
 		// value to set for NoFunctionsFromInput
 		noFunctionsFromInput *bool
 
-		enableStarlark bool
+		enableStarlark  bool
+		enableRego      bool
+		enableEndpoints bool
 
 		disableContainers bool
 	}{
@@ -529,6 +535,34 @@ metadata:
 			},
 		},
 
+		// Test
+		//
+		//
+		{name: "starlark-function-program",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        name: set-replicas
+        program: |
+          for resource in ctx.resource_list["items"]:
+            resource["spec"]["replicas"] = 3
+`,
+				},
+			},
+			enableStarlark: true,
+			outFn: func(path string) []string {
+				return []string{"name: set-replicas path:  url:  program: " +
+					"for resource in ctx.resource_list[\"items\"]:\n  resource[\"spec\"][\"replicas\"] = 3"}
+			},
+		},
+
 		// Test
 		//
 		//
@@ -585,6 +619,100 @@ metadata:
     config.kubernetes.io/function: |
       starlark:
         path: a/b/c
+`,
+				},
+			},
+		},
+
+		{name: "rego-function",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      rego:
+        path: policies
+`,
+				},
+			},
+			enableRego: true,
+			outFn: func(path string) []string {
+				return []string{
+					fmt.Sprintf("name:  path: %s/foo/policies program:", filepath.ToSlash(path))}
+			},
+		},
+
+		{name: "rego-function-escape-parent",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      rego:
+        path: ../policies
+`,
+				},
+			},
+			enableRego: true,
+			error:      "function path ../policies not allowed to start with ../",
+		},
+
+		{name: "rego-function-disabled",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      rego:
+        path: policies
+`,
+				},
+			},
+		},
+
+		{name: "endpoint-function",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      endpoint:
+        url: https://fn.example.com/set-labels
+`,
+				},
+			},
+			enableEndpoints: true,
+			out:             []string{"url: https://fn.example.com/set-labels"},
+		},
+
+		{name: "endpoint-function-disabled",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      endpoint:
+        url: https://fn.example.com/set-labels
 `,
 				},
 			},
@@ -642,6 +770,8 @@ metadata:
 			// init the instance
 			r := &RunFns{
 				EnableStarlark:       tt.enableStarlark,
+				EnableRego:           tt.enableRego,
+				EnableEndpoints:      tt.enableEndpoints,
 				DisableContainers:    tt.disableContainers,
 				FunctionPaths:        fnPaths,
 				Functions:            parsedFns,
@@ -906,6 +1036,105 @@ func TestCmd_Execute_setInput(t *testing.T) {
 	assert.Contains(t, string(b), "kind: StatefulSet")
 }
 
+// TestCmd_Execute_functionMetadata tests validating functionConfigs
+// against the schemas of their functions
+func TestCmd_Execute_functionMetadata(t *testing.T) {
+	catalog := `apiVersion: config.kubernetes.io/v1alpha1
+kind: KRMFunctionDefinition
+spec:
+  names:
+    kind: ValueReplacer
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        required: [stringMatch, replace]
+        properties:
+          replace:
+            type: string
+            enum: [%s]
+    runtime:
+      container:
+        image: gcr.io/example.com/image:v2
+`
+	for _, tc := range []struct {
+		allowed  string
+		expected string
+	}{
+		{allowed: "StatefulSet"},
+		{allowed: "DaemonSet", expected: "functionConfig of function ValueReplacer " +
+			"doesn't match its schema: validation failure list:\n" +
+			"replace in body should be one of [DaemonSet]"},
+	} {
+		dir := setupTest(t)
+		defer os.RemoveAll(dir)
+		if !assert.NoError(t, ioutil.WriteFile(
+			filepath.Join(dir, "filter.yaml"), []byte(ValueReplacerYAMLData), 0600)) {
+			return
+		}
+		catalogFile := filepath.Join(dir, "..", filepath.Base(dir)+"-catalog.yaml")
+		defer os.Remove(catalogFile)
+		if !assert.NoError(t, ioutil.WriteFile(
+			catalogFile, []byte(fmt.Sprintf(catalog, tc.allowed)), 0600)) {
+			return
+		}
+		defs, err := metadata.ReadCatalog(catalogFile)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		instance := RunFns{
+			Path:                   dir,
+			FunctionMetadata:       &metadata.Finder{Definitions: defs},
+			functionFilterProvider: getFilterProvider(t),
+		}
+		instance.init()
+		err = instance.Execute()
+		if tc.expected != "" {
+			assert.EqualError(t, err, tc.expected)
+			continue
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		b, err := ioutil.ReadFile(
+			filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Contains(t, string(b), "kind: StatefulSet")
+	}
+}
+
+func TestCmd_Execute_metrics(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+	if !assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "filter.yaml"), []byte(ValueReplacerYAMLData), 0600)) {
+		return
+	}
+	p := metrics.NewPrometheus()
+	instance := RunFns{
+		Path:                   dir,
+		Metrics:                p,
+		functionFilterProvider: getFilterProvider(t),
+	}
+	instance.init()
+	if !assert.NoError(t, instance.Execute()) {
+		return
+	}
+	var b strings.Builder
+	_, err := p.WriteTo(&b)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, b.String(), `kustomize_function_runs_total`+
+		`{function="gcr.io/example.com/image:version",result="success"} 1`)
+	assert.Contains(t, b.String(), `kustomize_function_duration_seconds_count`+
+		`{function="gcr.io/example.com/image:version",result="success"} 1`)
+}
+
 // setupTest initializes a temp test directory containing test data
 func setupTest(t *testing.T) string {
 	dir, err := ioutil.TempDir("", "kustomize-kyaml-test")
@@ -953,3 +1182,653 @@ func getFilterProvider(t *testing.T) func(runtimeutil.FunctionSpec, *yaml.RNode)
 		}, nil
 	}
 }
+
+// sedFilter returns a container filter, scoped by the path of its function
+// config, which runs sed with args instead of a container.
+func sedFilter(t *testing.T, path string, args ...string) *container.Filter {
+	fc := yaml.MustParse(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  name: fn
+`)
+	if path != "" {
+		if !assert.NoError(t, fc.PipeE(yaml.SetAnnotation(kioutil.PathAnnotation, path))) {
+			t.FailNow()
+		}
+	}
+	cf := &container.Filter{Image: path}
+	cf.Exec.FunctionConfig = fc
+	cf.Exec.Path = "sed"
+	cf.Exec.Args = args
+	return cf
+}
+
+func TestParallelize(t *testing.T) {
+	a := sedFilter(t, "a/fn.yaml")
+	b := sedFilter(t, "b/fn.yaml")
+	ac := sedFilter(t, "a/c/fn.yaml")
+	global := sedFilter(t, "")
+	d := sedFilter(t, "d/fn.yaml")
+	fltrs := []kio.Filter{a, b, ac, global, d}
+
+	result, err := parallelize(fltrs, 1)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, fltrs, result)
+
+	result, err = parallelize(fltrs, 4)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, result, 4) {
+		t.FailNow()
+	}
+	// a and b are run concurrently, but a/c overlaps a, and the global
+	// function overlaps every function
+	pf, ok := result[0].(*parallelFilter)
+	if assert.True(t, ok) {
+		assert.Equal(t, []scopedFilter{
+			{filter: a, container: a, dir: "a"},
+			{filter: b, container: b, dir: "b"},
+		}, pf.filters)
+		assert.Equal(t, 4, pf.parallelism)
+	}
+	assert.Equal(t, []kio.Filter{ac, global, d}, result[1:])
+}
+
+func TestParallelFilter_Filter(t *testing.T) {
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewBufferString(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+  annotations:
+    config.kubernetes.io/path: c/deployment.yaml
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  annotations:
+    config.kubernetes.io/path: a/deployment.yaml
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+  annotations:
+    config.kubernetes.io/path: b/deployment.yaml
+`), OmitReaderAnnotations: true}).Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	fltrs, err := parallelize([]kio.Filter{
+		sedFilter(t, "a/fn.yaml", "s/kind: Deployment/kind: StatefulSet/g"),
+		sedFilter(t, "b/fn.yaml", "s/kind: Deployment/kind: DaemonSet/g"),
+	}, 2)
+	if !assert.NoError(t, err) || !assert.Len(t, fltrs, 1) {
+		t.FailNow()
+	}
+	output, err := fltrs[0].Filter(nodes)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// the outputs of the functions are in their order, followed by the
+	// resources in neither of their scopes
+	var actual []string
+	for _, n := range output {
+		meta, err := n.GetMeta()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		actual = append(actual, meta.Kind+" "+meta.Name)
+	}
+	assert.Equal(t, []string{"StatefulSet a", "DaemonSet b", "Deployment c"}, actual)
+}
+
+func TestPolicy_Check(t *testing.T) {
+	var tests = []struct {
+		name    string
+		policy  Policy
+		image   string
+		network bool
+		mounts  bool
+		err     string
+	}{
+		{
+			name:   "allowed-glob",
+			policy: Policy{Allow: []ImagePattern{{Glob: "gcr.io/my-org/*"}}},
+			image:  "gcr.io/my-org/fn:v1",
+		},
+		{
+			name:   "allowed-regex",
+			policy: Policy{Allow: []ImagePattern{{Regex: `^docker\.io/library/`}}},
+			image:  "docker.io/library/fn:v1",
+		},
+		{
+			name:   "not-allowed",
+			policy: Policy{Allow: []ImagePattern{{Glob: "gcr.io/my-org/*"}}},
+			image:  "gcr.io/other-org/fn:v1",
+			err:    "function gcr.io/other-org/fn:v1 violates the policy: the image isn't allowed",
+		},
+		{
+			name: "denied",
+			policy: Policy{
+				Allow: []ImagePattern{{Glob: "gcr.io/my-org/*"}},
+				Deny:  []ImagePattern{{Glob: "gcr.io/my-org/experimental-*"}},
+			},
+			image: "gcr.io/my-org/experimental-fn:v1",
+			err: "function gcr.io/my-org/experimental-fn:v1 violates the policy: " +
+				"the image is denied by glob gcr.io/my-org/experimental-*",
+		},
+		{
+			name:    "network",
+			policy:  Policy{DenyNetwork: true},
+			image:   "gcr.io/my-org/fn:v1",
+			network: true,
+			err:     "function gcr.io/my-org/fn:v1 violates the policy: network access is denied",
+		},
+		{
+			name:   "mounts",
+			policy: Policy{DenyMounts: true},
+			image:  "gcr.io/my-org/fn:v1",
+			mounts: true,
+			err:    "function gcr.io/my-org/fn:v1 violates the policy: storage mounts are denied",
+		},
+		{
+			name:   "invalid-pattern",
+			policy: Policy{Deny: []ImagePattern{{Regex: "("}}},
+			image:  "gcr.io/my-org/fn:v1",
+			err: "invalid image pattern regex (: " +
+				"error parsing regexp: missing closing ): `(`",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.image, tt.network, tt.mounts)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestRunFns_getFunctionFilters_policy(t *testing.T) {
+	fn := yaml.MustParse(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: gcr.io/other-org/fn:v1
+`)
+	r := RunFns{Policy: &Policy{Allow: []ImagePattern{{Glob: "gcr.io/my-org/*"}}}}
+	_, err := r.getFunctionFilters(true, fn)
+	if assert.Error(t, err) {
+		_, ok := err.(*PolicyViolation)
+		assert.True(t, ok)
+	}
+}
+
+func TestRunFns_getFunctionFilters_grpc(t *testing.T) {
+	fn := yaml.MustParse(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      grpc:
+        path: fn-server
+`)
+	// servers run locally also need exec functions enabled
+	r := RunFns{EnableGRPC: true}
+	r.init()
+	fltrs, err := r.getFunctionFilters(true, fn)
+	if assert.NoError(t, err) {
+		assert.Empty(t, fltrs)
+	}
+
+	// servers run as containers only have a network if they require one
+	fn = yaml.MustParse(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      grpc:
+        image: gcr.io/my-org/fn:v1
+        network:
+          required: true
+`)
+	_, err = r.getFunctionFilters(true, fn)
+	if assert.Error(t, err) {
+		assert.Equal(t, "network required but not enabled with --network", err.Error())
+	}
+	r.Network = true
+	r.NetworkName = "bridge"
+	r.Policy = &Policy{DenyNetwork: true}
+	_, err = r.getFunctionFilters(true, fn)
+	if assert.Error(t, err) {
+		assert.Equal(t, "function gcr.io/my-org/fn:v1 violates the policy: "+
+			"network access is denied", err.Error())
+	}
+}
+
+func TestRunFns_getFunctionFilters_limits(t *testing.T) {
+	fn := yaml.MustParse(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: gcr.io/example.com/image:version
+        memory: 1g
+        env:
+        - A=1
+`)
+	r := RunFns{Memory: "512m", CPUs: "1.5", Env: []string{"B"}}
+	r.init()
+	fltrs, err := r.getFunctionFilters(true, fn)
+	if !assert.NoError(t, err) || !assert.Len(t, fltrs, 1) {
+		t.FailNow()
+	}
+	cf, ok := fltrs[0].(*container.Filter)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	// the limit of the function overrides the default
+	assert.Equal(t, "1g", cf.Memory)
+	assert.Equal(t, "1.5", cf.CPUs)
+	// the environment of the function is given along with the runner's
+	assert.Equal(t, []string{"A=1", "B"}, cf.Env)
+}
+
+func TestReadPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.yaml")
+
+	err = ioutil.WriteFile(path, []byte(`allow:
+- glob: gcr.io/my-org/*
+deny:
+- regex: experimental
+denyNetwork: true
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	policy, err := ReadPolicy(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, &Policy{
+		Allow:       []ImagePattern{{Glob: "gcr.io/my-org/*"}},
+		Deny:        []ImagePattern{{Regex: "experimental"}},
+		DenyNetwork: true,
+	}, policy)
+
+	err = ioutil.WriteFile(path, []byte(`allow:
+- glob: gcr.io/my-org/*
+  regex: gcr.io
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = ReadPolicy(path)
+	if assert.Error(t, err) {
+		assert.Equal(t, "image patterns must have either a glob or a regex", err.Error())
+	}
+}
+
+// resultsFilter returns a container filter which emits its input with a
+// result of severity, and fails if it's an error, instead of running a
+// container.
+func resultsFilter(severity string) *container.Filter {
+	cf := &container.Filter{Image: "gcr.io/example/" + severity}
+	cf.Exec.FunctionConfig = yaml.MustParse(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  name: fn
+`)
+	cf.Exec.Path = "sh"
+	cf.Exec.Args = []string{"-c", `cat; cat <<EOF
+results:
+  items:
+  - message: replicas must be at most 10
+    severity: ` + severity + `
+    resourceRef:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: app
+    field:
+      path: spec.replicas
+    file:
+      path: apps/deployment.yaml
+EOF
+test ` + severity + ` != error`}
+	return cf
+}
+
+func TestCmd_Execute_results(t *testing.T) {
+	var tests = []struct {
+		name      string
+		images    []string
+		exitCodes map[framework.Severity]int
+		code      int
+		err       string
+		results   string
+	}{
+		{
+			name:   "warning",
+			images: []string{"warning"},
+			results: `items:
+- function: gcr.io/example/warning
+  severity: warning
+  message: replicas must be at most 10
+  file: apps/deployment.yaml
+  field: spec.replicas
+  resource:
+    name: app
+    apiVersion: apps/v1
+    kind: Deployment
+`,
+		},
+		{
+			name:      "warning-exit-code",
+			images:    []string{"warning"},
+			exitCodes: map[framework.Severity]int{framework.Warning: 2},
+			code:      2,
+			err:       "function results exit with code 2",
+		},
+		{
+			name:      "info-exit-code",
+			images:    []string{"info"},
+			exitCodes: map[framework.Severity]int{framework.Warning: 2},
+		},
+		{
+			name:      "error",
+			images:    []string{"warning", "error"},
+			exitCodes: map[framework.Severity]int{framework.Warning: 2, framework.Error: 3},
+			code:      3,
+			err:       "exit status 1",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kustomize-test")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+
+			var fns []*yaml.RNode
+			for _, image := range tt.images {
+				fns = append(fns, yaml.MustParse(fmt.Sprintf(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: %s
+`, image)))
+			}
+			instance := RunFns{
+				Input: bytes.NewBufferString(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`),
+				Output:     &bytes.Buffer{},
+				Functions:  fns,
+				ResultsDir: dir,
+				ExitCodes:  tt.exitCodes,
+				functionFilterProvider: func(
+					spec runtimeutil.FunctionSpec, _ *yaml.RNode) (kio.Filter, error) {
+					return resultsFilter(spec.Container.Image), nil
+				},
+			}
+			err = instance.Execute()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Equal(t, tt.err, err.Error())
+				e, ok := err.(*ExitCodeError)
+				if assert.True(t, ok) {
+					assert.Equal(t, tt.code, e.Code)
+					assert.Len(t, e.Results.Items, len(tt.images))
+				}
+			}
+
+			if tt.results != "" {
+				b, err := ioutil.ReadFile(filepath.Join(dir, ResultsFileName))
+				if assert.NoError(t, err) {
+					assert.Equal(t, tt.results, string(b))
+				}
+			}
+		})
+	}
+}
+
+func TestCmd_Execute_continueOnError(t *testing.T) {
+	var fns []*yaml.RNode
+	for _, image := range []string{"error", "warning"} {
+		fns = append(fns, yaml.MustParse(fmt.Sprintf(`apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: %s
+`, image)))
+	}
+	var fltrs []*container.Filter
+	output := &bytes.Buffer{}
+	instance := RunFns{
+		Input: bytes.NewBufferString(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`),
+		Output:          output,
+		Functions:       fns,
+		ContinueOnError: true,
+		ExitCodes:       map[framework.Severity]int{framework.Warning: 2},
+		functionFilterProvider: func(
+			spec runtimeutil.FunctionSpec, _ *yaml.RNode) (kio.Filter, error) {
+			f := resultsFilter(spec.Container.Image)
+			fltrs = append(fltrs, f)
+			return f, nil
+		},
+	}
+	err := instance.Execute()
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+
+	// the failing function didn't stop the other from running
+	if assert.Len(t, fltrs, 2) {
+		assert.NotNil(t, fltrs[1].Exec.GetResults())
+	}
+	e, ok := err.(*ExitCodeError)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	assert.Equal(t, 2, e.Code)
+	assert.Equal(t, "function error failed: exit status 1", e.Err.Error())
+	var messages []string
+	for _, item := range e.Results.Items {
+		messages = append(messages, fmt.Sprintf("%s %s: %s", item.Function, item.Severity, item.Message))
+	}
+	assert.Equal(t, []string{
+		"error error: function failed: exit status 1",
+		"gcr.io/example/error error: replicas must be at most 10",
+		"gcr.io/example/warning warning: replicas must be at most 10",
+	}, messages)
+
+	// the input of the failing function was passed on unchanged, and
+	// given a path by the other
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/path: 'deployment_app.yaml'
+`, output.String())
+}
+
+func TestReadPipeline(t *testing.T) {
+	var tests = []struct {
+		name     string
+		pipeline string
+		expected []string
+		err      string
+	}{
+		{
+			name: "steps",
+			pipeline: `apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+metadata:
+  name: app
+steps:
+- container:
+    image: gcr.io/example/set-namespace:v1
+  config:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: set-namespace
+    data:
+      namespace: app
+- starlark:
+    path: fns/set-replicas.star
+`,
+			expected: []string{`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: set-namespace
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: gcr.io/example/set-namespace:v1
+data:
+  namespace: app
+`, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-1
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: fns/set-replicas.star
+`},
+		},
+		{
+			name: "wrong-kind",
+			pipeline: `apiVersion: v1
+kind: ConfigMap
+`,
+			err: "%s must be a Pipeline, not a ConfigMap",
+		},
+		{
+			name: "no-steps",
+			pipeline: `apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+`,
+			err: "pipeline %s has no steps",
+		},
+		{
+			name: "no-function",
+			pipeline: `apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+steps:
+- config:
+    apiVersion: v1
+    kind: ConfigMap
+`,
+			err: "step 0 of pipeline %s: no function declared",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kustomize-test")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "pipeline.yaml")
+			if !assert.NoError(t, ioutil.WriteFile(path, []byte(tt.pipeline), 0600)) {
+				t.FailNow()
+			}
+
+			fns, err := ReadPipeline(path)
+			if tt.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, fmt.Sprintf(tt.err, path), err.Error())
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var actual []string
+			for _, fn := range fns {
+				actual = append(actual, fn.MustString())
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestCmd_Execute_pipeline(t *testing.T) {
+	dir := setupTest(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "..", filepath.Base(dir)+"-pipeline.yaml")
+	if !assert.NoError(t, ioutil.WriteFile(path, []byte(`apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+metadata:
+  name: app
+steps:
+- container:
+    image: gcr.io/example/replace:v1
+  config:
+    apiVersion: v1
+    kind: ValueReplacer
+    stringMatch: Deployment
+    replace: StatefulSet
+`), 0600)) {
+		t.FailNow()
+	}
+	defer os.Remove(path)
+
+	fns, err := ReadPipeline(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	instance := RunFns{
+		Path:                   dir,
+		Functions:              fns,
+		functionFilterProvider: getFilterProvider(t),
+	}
+	if !assert.NoError(t, instance.Execute()) {
+		t.FailNow()
+	}
+	b, err := ioutil.ReadFile(
+		filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, string(b), "kind: StatefulSet")
+}
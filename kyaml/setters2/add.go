@@ -5,6 +5,7 @@ package setters2
 
 import (
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-openapi/spec"
@@ -21,6 +22,11 @@ type Add struct {
 	// Optional.  If unspecified match all field values.
 	FieldValue string
 
+	// FieldValueRegex if set will add the OpenAPI reference to fields whose value
+	// matches this regular expression, e.g. for regex substitutions.
+	// Optional.
+	FieldValueRegex string
+
 	// FieldName if set will add the OpenAPI reference to fields with this name or path
 	// FieldName may be the full name of the field, full path to the field, or the path suffix.
 	// e.g. all of the following would match spec.template.spec.containers.image --
@@ -40,16 +46,26 @@ type Add struct {
 
 	// Count is the number of fields the setter applies to
 	Count int
+
+	// fieldValueRegex is FieldValueRegex compiled
+	fieldValueRegex *regexp.Regexp
 }
 
 // Filter implements yaml.Filter
 func (a *Add) Filter(object *yaml.RNode) (*yaml.RNode, error) {
-	if a.FieldName == "" && a.FieldValue == "" {
+	if a.FieldName == "" && a.FieldValue == "" && a.FieldValueRegex == "" {
 		return nil, errors.Errorf("must specify either fieldName or fieldValue")
 	}
 	if a.Ref == "" {
 		return nil, errors.Errorf("must specify ref")
 	}
+	if a.FieldValueRegex != "" && a.fieldValueRegex == nil {
+		re, err := regexp.Compile(a.FieldValueRegex)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		a.fieldValueRegex = re
+	}
 	return object, accept(a, object)
 }
 
@@ -108,6 +124,9 @@ func (a *Add) visitScalar(object *yaml.RNode, p string, _ *openapi.ResourceSchem
 	if a.FieldValue != "" && a.FieldValue != object.YNode().Value {
 		return nil
 	}
+	if a.fieldValueRegex != nil && !a.fieldValueRegex.MatchString(object.YNode().Value) {
+		return nil
+	}
 	a.Count++
 	return a.addRef(object)
 }
@@ -172,6 +191,53 @@ type SetterDefinition struct {
 	// live apply/preview. This field is added to the setter definition to record
 	// the package publisher's intent to make the setter required to be set.
 	Required bool `yaml:"required,omitempty"`
+
+	// Targets are fields the setter sets which don't have comments
+	// referencing it, see Target.
+	Targets []Target `yaml:"targets,omitempty"`
+
+	// RecordHistory, if true, has each value the setter is set to recorded
+	// in its History, with who set it and when, so changes can be audited.
+	RecordHistory bool `yaml:"recordHistory,omitempty"`
+
+	// History are the values the setter was set to, oldest first,
+	// if RecordHistory is true.
+	History []HistoryEntry `yaml:"history,omitempty"`
+
+	// Deprecated indicates that the setter shouldn't be set anymore, e.g.
+	// because it has been renamed.  Deprecated setters are still set, but
+	// Set warns about them, see Deprecation.
+	Deprecated bool `yaml:"deprecated,omitempty"`
+
+	// ReplacedBy, if set, is the name of the setter to set instead of
+	// the deprecated setter.
+	ReplacedBy string `yaml:"replacedBy,omitempty"`
+
+	// Locked, if true, pins the value of the setter, e.g. to a registry
+	// mandated by the organization publishing the package.  The values of
+	// locked setters can only be changed with SetOpenAPI.Force.
+	Locked bool `yaml:"locked,omitempty"`
+
+	// Substitutions are the names of the substitutions which use the setter,
+	// directly or through nested substitutions.  It's set by List, and isn't
+	// part of the definition.
+	Substitutions []string `yaml:"-"`
+}
+
+// HistoryEntry is a value a setter was set to, recorded
+// in its definition if it has recordHistory.
+type HistoryEntry struct {
+	// Value is the value the setter was set to.
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+
+	// ListValues are the values a list setter was set to.
+	ListValues []string `yaml:"listValues,omitempty" json:"listValues,omitempty"`
+
+	// SetBy is who set the value, if recorded.
+	SetBy string `yaml:"setBy,omitempty" json:"setBy,omitempty"`
+
+	// Timestamp is when the value was set, in RFC 3339 format, in UTC.
+	Timestamp string `yaml:"timestamp" json:"timestamp"`
 }
 
 func (sd SetterDefinition) AddToFile(path string) error {
@@ -251,18 +317,98 @@ type SubstitutionDefinition struct {
 	Name string `yaml:"name"`
 
 	// Pattern is the substitution pattern into which setter values are substituted
-	Pattern string `yaml:"pattern"`
+	Pattern string `yaml:"pattern,omitempty"`
+
+	// Regex, if set rather than Pattern, is a regular expression matched against
+	// the values of the fields, whose capture groups the values replace, so the
+	// text around them may vary between fields, e.g. ^(?:.*/)?[^/:@]+:(?P<TAG>[^@]+)$
+	// replaces only the tags of images.
+	Regex string `yaml:"regex,omitempty"`
 
 	// Values are setters which are substituted into pattern to produce a field value
 	Values []Value `yaml:"values"`
+
+	// Targets are fields the substitution sets which don't have comments
+	// referencing it, see Target.
+	Targets []Target `yaml:"targets,omitempty"`
 }
 
 type Value struct {
-	// Marker is the string marker in pattern that is replace by the referenced setter.
+	// Marker is the string marker in pattern that is replace by the referenced setter,
+	// or the name or number of the capture group of a Regex.
 	Marker string `yaml:"marker"`
 
 	// Ref is a reference to a setter to pull the replacement value from.
 	Ref string `yaml:"ref"`
+
+	// Default, if set, replaces the marker when the value of the setter
+	// is empty, e.g. so an image falls back to the latest tag, rather
+	// than ending with a colon.
+	Default string `yaml:"default,omitempty"`
+}
+
+// Target is a field set by a setter or substitution without having a comment
+// which references it, so that the resources aren't annotated, and the field
+// is still set after other tools rewrite them.  The field is selected by its
+// resource and its path, rather than by its comment.
+type Target struct {
+	// Select selects the resources with the field.
+	Select Selector `yaml:"select" json:"select"`
+
+	// FieldPath is the path to the field, whose parts are separated by '.',
+	// and whose list elements are matched by [field=value], e.g.
+	// spec.template.spec.containers[name=nginx].image
+	FieldPath string `yaml:"fieldPath" json:"fieldPath"`
+}
+
+// Selector selects resources by their metadata.  Empty fields
+// match any resource.
+type Selector struct {
+	APIVersion string `yaml:"apiVersion,omitempty" json:"apiVersion,omitempty"`
+	Kind       string `yaml:"kind,omitempty" json:"kind,omitempty"`
+	Name       string `yaml:"name,omitempty" json:"name,omitempty"`
+	Namespace  string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+}
+
+// matches returns true if the resource with meta is selected.
+func (s Selector) matches(meta yaml.ResourceMeta) bool {
+	return (s.APIVersion == "" || s.APIVersion == meta.APIVersion) &&
+		(s.Kind == "" || s.Kind == meta.Kind) &&
+		(s.Name == "" || s.Name == meta.Name) &&
+		(s.Namespace == "" || s.Namespace == meta.Namespace)
+}
+
+// fieldPath returns the parts of the FieldPath of t, e.g.
+// [spec template spec containers [name=nginx] image], keeping
+// the '.'s of the values of list elements.
+func (t Target) fieldPath() []string {
+	var parts []string
+	var part strings.Builder
+	next := func() {
+		if part.Len() > 0 {
+			parts = append(parts, part.String())
+			part.Reset()
+		}
+	}
+	inElement := false
+	for _, r := range t.FieldPath {
+		switch {
+		case r == '[' && !inElement:
+			next()
+			inElement = true
+			part.WriteRune(r)
+		case r == ']' && inElement:
+			part.WriteRune(r)
+			next()
+			inElement = false
+		case r == '.' && !inElement:
+			next()
+		default:
+			part.WriteRune(r)
+		}
+	}
+	next()
+	return parts
 }
 
 func (sd SubstitutionDefinition) AddToFile(path string) error {
@@ -4,6 +4,7 @@
 package setters2
 
 import (
+	"encoding/json"
 	"strings"
 
 	"github.com/go-openapi/spec"
@@ -14,8 +15,16 @@ import (
 )
 
 // Delete delete setter or substitution references from resource fields.
-// Requires that FieldName have been set.
+// Requires that FieldName or Name have been set.
 type Delete struct {
+	// Name, if set, is the name of the setter whose references are deleted
+	// from the fields which have them, in full or shorthand form, e.g.
+	// # {"$ref":"#/definitions/io.k8s.cli.setters.replicas"} or
+	// # {"$openapi":"replicas"}.  References to other setters are kept.
+	// If FieldName is set as well, the comments of the fields matching it
+	// which don't parse as references, e.g. because of a typo, are deleted
+	// too, as they were before references were matched by name.
+	Name string
 
 	// FieldName if delete the OpenAPI reference to fields with this name or path
 	// FieldName may be the full name of the field, full path to the field, or the path suffix.
@@ -27,8 +36,8 @@ type Delete struct {
 
 // Filter implements yaml.Filter
 func (d *Delete) Filter(object *yaml.RNode) (*yaml.RNode, error) {
-	if d.FieldName == "" {
-		return nil, errors.Errorf("must specify fieldName")
+	if d.FieldName == "" && d.Name == "" {
+		return nil, errors.Errorf("must specify fieldName or name")
 	}
 	return object, accept(d, object)
 }
@@ -38,14 +47,27 @@ func (d *Delete) visitSequence(_ *yaml.RNode, _ string, _ *openapi.ResourceSchem
 	return nil
 }
 
-func (d *Delete) visitMapping(_ *yaml.RNode, _ string, _ *openapi.ResourceSchema) error {
-	// no-op
-	return nil
+// visitMapping implements visitor
+// visitMapping will remove references to the setter Name from the field names,
+// which hold them for sequence and mapping fields.
+func (d *Delete) visitMapping(object *yaml.RNode, _ string, _ *openapi.ResourceSchema) error {
+	if d.Name == "" {
+		return nil
+	}
+	return object.VisitFields(func(node *yaml.MapNode) error {
+		d.clearReferences(node.Key.YNode(), "")
+		return nil
+	})
 }
 
 // visitScalar implements visitor
 // visitScalar will remove the reference on each scalar field whose name matches.
 func (d *Delete) visitScalar(object *yaml.RNode, p string, _ *openapi.ResourceSchema) error {
+	if d.Name != "" {
+		d.clearReferences(object.YNode(), p)
+		return nil
+	}
+
 	// check if the field matches
 	if d.FieldName != "" && !strings.HasSuffix(p, d.FieldName) {
 		return nil
@@ -68,9 +90,49 @@ func (d *Delete) visitScalar(object *yaml.RNode, p string, _ *openapi.ResourceSc
 	return nil
 }
 
-// DeleterDefinition may be used to update a files OpenAPI definitions with a new setter.
+// clearReferences removes the comments of n, the field at path p, which
+// reference the setter Name.
+func (d *Delete) clearReferences(n *yaml.Node, p string) {
+	if d.references(n.LineComment, p) {
+		n.LineComment = ""
+	}
+	if d.references(n.HeadComment, p) {
+		n.HeadComment = ""
+	}
+}
+
+// references returns whether comment, of the field at path p, is a field
+// reference to the setter Name, in full or shorthand form.  Comments which
+// don't parse are references if p matches FieldName.
+func (d *Delete) references(comment, p string) bool {
+	if comment == "" {
+		return false
+	}
+	ref := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(strings.TrimLeft(comment, "#")), &ref); err != nil {
+		return d.FieldName != "" && p != "" && strings.HasSuffix(p, d.FieldName)
+	}
+	return ref["$ref"] == fieldmeta.DefinitionsPrefix+fieldmeta.SetterDefinitionPrefix+d.Name ||
+		ref[fieldmeta.ShortHandRef()] == d.Name
+}
+
+// DeleteOpenAPI deletes the definition of a setter from OpenAPI definitions,
+// unless a substitution refers to it.  Delete removes the references to the
+// setter from resource fields.
+type DeleteOpenAPI struct {
+	// Name is the name of the setter to delete.
+	Name string `yaml:"name"`
+}
+
+// DeleteFromFile deletes the setter from the OpenAPI definitions in a file.
+func (d DeleteOpenAPI) DeleteFromFile(path string) error {
+	return yaml.UpdateFile(d, path)
+}
+
+// DeleterDefinition may be used to delete a setter from a files OpenAPI
+// definitions.  It's equivalent to DeleteOpenAPI.
 type DeleterDefinition struct {
-	// Name is the name of the setter to create or update.
+	// Name is the name of the setter to delete.
 	Name string `yaml:"name"`
 }
 
@@ -78,6 +140,10 @@ func (dd DeleterDefinition) DeleteFromFile(path string) error {
 	return yaml.UpdateFile(dd, path)
 }
 
+func (dd DeleterDefinition) Filter(object *yaml.RNode) (*yaml.RNode, error) {
+	return DeleteOpenAPI{Name: dd.Name}.Filter(object)
+}
+
 // SubstReferringSetter check if the setter used in substitution and return the substitution name if true
 func SubstReferringSetter(definitions *yaml.RNode, key string) string {
 	fieldNames, err := definitions.Fields()
@@ -114,8 +180,8 @@ func SubstReferringSetter(definitions *yaml.RNode, key string) string {
 	return ""
 }
 
-func (dd DeleterDefinition) Filter(object *yaml.RNode) (*yaml.RNode, error) {
-	key := fieldmeta.SetterDefinitionPrefix + dd.Name
+func (d DeleteOpenAPI) Filter(object *yaml.RNode) (*yaml.RNode, error) {
+	key := fieldmeta.SetterDefinitionPrefix + d.Name
 
 	definitions, err := object.Pipe(yaml.Lookup(openapi.SupplementaryOpenAPIFieldName, "definitions"))
 	if err != nil || definitions == nil {
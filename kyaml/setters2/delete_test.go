@@ -189,3 +189,159 @@ openAPI:
 `
 	assert.Equal(t, expected, string(b))
 }
+
+func TestDelete_Filter_name(t *testing.T) {
+	r, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    count: "3" # {"$openapi":"replicas"}
+spec:
+  replicas: 3 # {"$ref":"#/definitions/io.k8s.cli.setters.replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx # {"$ref":"#/definitions/io.k8s.cli.setters.image"}
+        args: # {"$ref":"#/definitions/io.k8s.cli.setters.replicas"}
+        - a
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	result, err := (&Delete{Name: "replicas"}).Filter(r)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	actual, err := result.String()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    count: "3"
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx # {"$ref":"#/definitions/io.k8s.cli.setters.image"}
+        args:
+        - a
+`, actual)
+}
+
+func TestDelete_Filter_nameUnparsed(t *testing.T) {
+	r, err := yaml.Parse(`apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 3 # {"$openapi" : "replicas"}}
+  paused: true # {"$openapi" : "paused"}}
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	result, err := (&Delete{Name: "replicas", FieldName: "replicas"}).Filter(r)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	actual, err := result.String()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 3
+  paused: true # {"$openapi" : "paused"}}
+`, actual)
+}
+
+func TestDeleteOpenAPI_Filter(t *testing.T) {
+	var tests = []struct {
+		name           string
+		setter         string
+		expectedOutput string
+		expectedError  string
+	}{
+		{
+			name:   "delete-tag",
+			setter: "tag",
+			expectedOutput: `openAPI:
+  definitions:
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: nginx
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: IMAGE_SETTER
+          values:
+          - marker: IMAGE_SETTER
+            ref: '#/definitions/io.k8s.cli.setters.image'
+`,
+		},
+		{
+			name:          "delete-substituted",
+			setter:        "image",
+			expectedError: "setter is used in substitution image, please delete the substitution first",
+		},
+		{
+			name:          "delete-missing",
+			setter:        "replicas",
+			expectedError: "setter does not exist",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			r, err := yaml.Parse(`openAPI:
+  definitions:
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: nginx
+    io.k8s.cli.setters.tag:
+      x-k8s-cli:
+        setter:
+          name: tag
+          value: "1.7.9"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: IMAGE_SETTER
+          values:
+          - marker: IMAGE_SETTER
+            ref: '#/definitions/io.k8s.cli.setters.image'
+`)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			result, err := DeleteOpenAPI{Name: test.setter}.Filter(r)
+			if test.expectedError != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, test.expectedError, err.Error())
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			actual, err := result.String()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, test.expectedOutput, actual)
+		})
+	}
+}
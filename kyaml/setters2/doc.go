@@ -30,10 +30,19 @@
 //
 //   x-k8s-cli.setter.name: name of the setter
 //   x-k8s-cli.setter.value: value of the setter that should be applied to fields
+//   x-k8s-cli.setter.recordHistory: optional, if true SetOpenAPI appends each value to
+//     x-k8s-cli.setter.history, with who set it and when -- see SetterHistory
+//   x-k8s-cli.setter.deprecated: optional, if true the setter is still set, but Set lists it
+//     in its Deprecations, so it can be renamed without breaking those who set it
+//   x-k8s-cli.setter.replacedBy: optional, the name of the setter replacing a deprecated setter
 //
 // The setter definition key must be of the form "io.k8s.cli.setters.NAME", where NAME matches the
 // value of "x-k8s-cli.setter.name".
 //
+// Values are validated against the rest of the definition, which is OpenAPI schema, before they're
+// set -- e.g. "minimum" and "maximum" can keep replicas from being set to 0, and "pattern" and
+// "maxLength" can keep names to DNS-1123 labels.
+//
 // When Set.Filter is called, the named setter will have its value applied to all resource
 // fields referencing it.
 //
@@ -52,6 +61,11 @@
 // then calling Set{Name: "replicas"}.Filter(deployment) would update the Deployment spec.replicas
 // value from 4 to 5.
 //
+// If the setter definition declares a "type", fields are set to values of that type: e.g.
+// the values of "integer" and "boolean" setters are unquoted, and the values of "string"
+// setters are quoted if they would otherwise be read as numbers or booleans.  Substitutions
+// are strings, unless their definitions declare another type.
+//
 // Updated OpenAPI:
 //
 //  {
@@ -126,8 +140,11 @@
 //
 //  x-k8s-cli.substitution.name: name of the substitution
 //  x-k8s-cli.substitution.pattern: string pattern to substitute markers into
+//  x-k8s-cli.substitution.regex: regular expression to substitute capture groups of, instead of pattern
 //  x-k8s-cli.substitution.values.marker: the marker substring within pattern to replace
 //  x-k8s-cli.substitution.values.ref: the setter ref containing the value to replace the marker with
+//  x-k8s-cli.substitution.values.default: optional, the value to replace the marker with if the value
+//    of the setter is empty, e.g. "latest" for the tag of an image
 //
 // The substitution is composed of a "pattern" containing markers, and a list of setter "values"
 // which are substituted into the markers.
@@ -149,12 +166,62 @@
 // either "image-name" or "image-tag" is set.  Whenever any setter referenced by a substitution
 // is set, the substitution will be recalculated by substituting its values into its pattern.
 //
+// Regex substitutions replace only parts of the field values, so the text around them may
+// vary between fields.  Their "regex" is matched against the value of each field, and the
+// capture group each marker names, or numbers, is replaced by the value of its setter.
+// e.g. the following substitution sets only the tags of images, whatever their names:
+//
+//   "io.k8s.cli.substitutions.image-tag": {
+//     "x-k8s-cli": {
+//       "substitution": {
+//         "name": "image-tag",
+//         "regex": "^(?:.*/)?[^/:]+:(?P<tag>[^@]+)$",
+//         "values": [
+//           {"marker": "tag", "ref": "#/definitions/io.k8s.cli.setters.image-tag"}
+//         ]
+//       }
+//     }
+//   }
+//
+// Fields which don't match the regex are left unchanged.  Regex substitutions can't be
+// nested in other substitutions.
+//
 //
 // If the OpenAPI io.k8s.cli.setters.image-name x-k8s-cli.setter.value was changed from "1.8.1"
 // to "1.8.2", then calling either Set{Name: "image-name"}.Filter(deployment) or
 // Set{Name: "image-tag"}.Filter(deployment) would update the Deployment field
 // spec.template.spec.container[name=nginx].image from "nginx:1.8.1" to "nginx:1.8.2".
 //
+// Targets
+//
+// Setters and substitutions may set fields without comments referencing them, e.g. so that the
+// resources aren't annotated, or because other tools rewrite them and drop the comments.
+// Their "targets" select the fields by the kind, apiVersion, name and namespace of their
+// resources, and by their paths, in which list elements are matched by [field=value].
+// Empty select fields match any resource.
+//
+//   "io.k8s.cli.setters.replicas": {
+//     "x-k8s-cli": {
+//       "setter": {
+//         "name": "replicas",
+//         "value": "4",
+//         "targets": [
+//           {"select": {"kind": "Deployment", "name": "nginx"}, "fieldPath": "spec.replicas"}
+//         ]
+//       }
+//     }
+//   }
+//
+// Fields which reference a setter or substitution by a comment are set by it, rather than
+// by a target, and targeted fields which are missing are skipped.
+//
+// Groups
+//
+// Setters may be grouped by prefixing their names with the name of the group and a ".",
+// e.g. frontend.replicas and backend.replicas.  Groups may be nested, e.g. frontend.web.image
+// is in both the frontend and frontend.web groups.  List and Set may be restricted to the
+// setters of a Group.
+//
 // Adding Field References
 //
 // References to setters and substitutions may be added to fields using the Add Filter.
@@ -0,0 +1,26 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package setters2
+
+import "strings"
+
+// GroupSeparator separates the groups of setters from their names, e.g.
+// the frontend.replicas and backend.replicas setters are in the frontend
+// and backend groups.  Groups may be nested, e.g. frontend.web.replicas
+// is in both the frontend and frontend.web groups.
+const GroupSeparator = "."
+
+// InGroup returns true if the setter with name is in group, or in
+// a group nested in it.
+func InGroup(name, group string) bool {
+	return strings.HasPrefix(name, group+GroupSeparator)
+}
+
+// GroupName returns the name of the setter with name in group.
+func GroupName(group, name string) string {
+	if group == "" {
+		return name
+	}
+	return group + GroupSeparator + name
+}
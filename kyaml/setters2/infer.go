@@ -0,0 +1,309 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package setters2
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/sets"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Infer infers the values of setters from the values of the fields which
+// reference them, or which they target, rather than setting the fields to the
+// values of the setters.  This is the reverse of Set, so that setters may be
+// adopted by packages whose fields have already been customized, without
+// clobbering their values.
+//
+// The values of substitutions are matched against their patterns, or regexes,
+// to infer the values of their setters.  Markers of patterns match greedily,
+// so if a value could be split between markers more than one way, the earlier
+// markers take as much of it as they can.  Fields which don't match are skipped.
+//
+// If fields referencing the same setter have different values, Filter returns
+// an error, as there's no one value for the setter.
+type Infer struct {
+	// Values are the values inferred for the setters, keyed by their names.
+	// List setters have a value for each element.
+	Values map[string][]string
+
+	// fields are the fields the Values were inferred from, for errors
+	fields map[string]string
+
+	// resource identifies the resource being filtered
+	resource string
+}
+
+// Filter implements Infer as a yaml.Filter
+func (i *Infer) Filter(object *yaml.RNode) (*yaml.RNode, error) {
+	if i.Values == nil {
+		i.Values = map[string][]string{}
+		i.fields = map[string]string{}
+	}
+	i.resource = ""
+	if meta, err := object.GetMeta(); err == nil {
+		i.resource = meta.Kind + "/" + meta.Name
+	}
+	if err := accept(i, object); err != nil {
+		return object, err
+	}
+	return object, acceptTargets(i, object)
+}
+
+// Names returns the names of the setters with inferred values, sorted.
+func (i *Infer) Names() []string {
+	var names []string
+	for name := range i.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (i *Infer) visitMapping(_ *yaml.RNode, _ string, _ *openapi.ResourceSchema) error {
+	return nil
+}
+
+// visitSequence infers the values of list setters from their sequences
+func (i *Infer) visitSequence(object *yaml.RNode, p string, schema *openapi.ResourceSchema) error {
+	ext, err := getExtFromComment(schema)
+	if err != nil {
+		return err
+	}
+	if ext == nil || ext.Setter == nil {
+		return nil
+	}
+	var values []string
+	for _, e := range object.YNode().Content {
+		if e.Kind != yaml.ScalarNode {
+			return nil
+		}
+		values = append(values, e.Value)
+	}
+	if len(values) == 0 {
+		// setters can't be set to empty lists
+		return nil
+	}
+	return i.infer(ext.Setter.Name, values, p)
+}
+
+// visitScalar infers the values of setters from the fields referencing
+// them, or referencing substitutions of them
+func (i *Infer) visitScalar(object *yaml.RNode, p string, schema *openapi.ResourceSchema) error {
+	ext, err := getExtFromComment(schema)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ext == nil:
+		return nil
+	case ext.Setter != nil:
+		return i.infer(ext.Setter.Name, []string{enumKey(ext, object.YNode().Value)}, p)
+	case ext.Substitution != nil:
+		return i.inferSubstitution(object.YNode().Value, ext, p)
+	}
+	return nil
+}
+
+// inferSubstitution infers the values of the setters of the substitution
+// in ext from value, the value of the field at path p.
+func (i *Infer) inferSubstitution(value string, ext *CliExtension, p string) error {
+	var re *regexp.Regexp
+	var markers []*CliExtension
+	if ext.Substitution.Regex != "" {
+		var err error
+		re, markers, err = regexSetters(ext)
+		if err != nil {
+			return err
+		}
+	} else {
+		expr, m, err := patternExpr(ext, sets.String{})
+		if err != nil {
+			return err
+		}
+		re, err = regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return errors.WrapPrefixf(err, "substitution %s", ext.Substitution.Name)
+		}
+		markers = m
+	}
+
+	match := re.FindStringSubmatchIndex(value)
+	if match == nil {
+		// the field has been changed so it no longer fits the substitution
+		return nil
+	}
+	for g, setterExt := range markers {
+		start, end := match[2*(g+1)], match[2*(g+1)+1]
+		if setterExt == nil || start < 0 {
+			continue
+		}
+		v := enumKey(setterExt, value[start:end])
+		if err := i.infer(setterExt.Setter.Name, []string{v}, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patternExpr returns a regular expression matching the values of the
+// substitution in ext, with a capture group for each marker of a setter,
+// and the setters of the groups, in order.  Nested substitutions are
+// expanded into the expression, rather than captured.
+func patternExpr(ext *CliExtension, visited sets.String) (string, []*CliExtension, error) {
+	if visited.Has(ext.Substitution.Name) {
+		return "", nil, errors.Errorf(
+			"cyclic substitution detected with name " + ext.Substitution.Name)
+	}
+	visited.Insert(ext.Substitution.Name)
+	defer delete(visited, ext.Substitution.Name)
+
+	var b strings.Builder
+	var markers []*CliExtension
+	pattern := ext.Substitution.Pattern
+	for len(pattern) > 0 {
+		// find the first marker in what's left of the pattern,
+		// preferring the longest of markers at the same place
+		index, value := -1, substitutionSetterReference{}
+		for _, v := range ext.Substitution.Values {
+			if v.Marker == "" {
+				continue
+			}
+			j := strings.Index(pattern, v.Marker)
+			if j < 0 {
+				continue
+			}
+			if index < 0 || j < index || j == index && len(v.Marker) > len(value.Marker) {
+				index, value = j, v
+			}
+		}
+		if index < 0 {
+			b.WriteString(regexp.QuoteMeta(pattern))
+			break
+		}
+		b.WriteString(regexp.QuoteMeta(pattern[:index]))
+		pattern = pattern[index+len(value.Marker):]
+
+		defExt, err := refExt(ext, value)
+		if err != nil {
+			return "", nil, err
+		}
+		if defExt.Substitution != nil {
+			if defExt.Substitution.Regex != "" {
+				return "", nil, errors.Errorf(
+					"substitution %s has a regex, so it can't be nested in substitution %s",
+					defExt.Substitution.Name, ext.Substitution.Name)
+			}
+			expr, m, err := patternExpr(defExt, visited)
+			if err != nil {
+				return "", nil, err
+			}
+			b.WriteString("(?:" + expr + ")")
+			markers = append(markers, m...)
+			continue
+		}
+		b.WriteString("(.*)")
+		markers = append(markers, defExt)
+	}
+	return b.String(), markers, nil
+}
+
+// regexSetters returns the compiled regex of the substitution in ext,
+// and the setter of each of its capture groups, or nil for groups
+// without a marker.
+func regexSetters(ext *CliExtension) (*regexp.Regexp, []*CliExtension, error) {
+	re, err := regexp.Compile(ext.Substitution.Regex)
+	if err != nil {
+		return nil, nil, errors.WrapPrefixf(err, "substitution %s", ext.Substitution.Name)
+	}
+	markers := make([]*CliExtension, re.NumSubexp())
+	for _, v := range ext.Substitution.Values {
+		g := captureGroup(re, v.Marker)
+		if g < 1 {
+			return nil, nil, errors.Errorf("marker %s of substitution %s is not a capture group of its regex",
+				v.Marker, ext.Substitution.Name)
+		}
+		defExt, err := refExt(ext, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if defExt.Substitution != nil {
+			return nil, nil, errors.Errorf("substitution %s has a regex, so it can't nest substitution %s",
+				ext.Substitution.Name, defExt.Substitution.Name)
+		}
+		markers[g-1] = defExt
+	}
+	return re, markers, nil
+}
+
+// refExt returns the extension of the setter or substitution which
+// the value v of the substitution in ext references.
+func refExt(ext *CliExtension, v substitutionSetterReference) (*CliExtension, error) {
+	if v.Ref == "" {
+		return nil, errors.Errorf(
+			"missing reference on substitution " + ext.Substitution.Name)
+	}
+	ref, err := spec.NewRef(v.Ref)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	def, err := openapi.Resolve(&ref)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defExt, err := GetExtFromSchema(def)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if defExt == nil || defExt.Setter == nil && defExt.Substitution == nil {
+		return nil, errors.Errorf("substitution %s references %s, which is not a setter or substitution",
+			ext.Substitution.Name, v.Ref)
+	}
+	return defExt, nil
+}
+
+// enumKey returns the first key of the enum values of the setter
+// in ext which maps to value, or value if there is none.
+func enumKey(ext *CliExtension, value string) string {
+	var keys []string
+	for k, v := range ext.Setter.EnumValues {
+		if v == value {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return value
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// infer records values as the values of the setter with name,
+// inferred from the field at path p.
+func (i *Infer) infer(name string, values []string, p string) error {
+	field := i.resource + " " + strings.TrimPrefix(p, ".")
+	if cur, found := i.Values[name]; found {
+		if strings.Join(cur, "\n") != strings.Join(values, "\n") || len(cur) != len(values) {
+			return errors.Errorf("setter %s has conflicting values %s in %s, and %s in %s",
+				name, listValue(cur), i.fields[name], listValue(values), field)
+		}
+		return nil
+	}
+	i.Values[name] = values
+	i.fields[name] = field
+	return nil
+}
+
+// listValue returns values as a scalar, or in flow style if it's a list.
+func listValue(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
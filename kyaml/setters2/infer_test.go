@@ -0,0 +1,296 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package setters2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestInfer_Filter(t *testing.T) {
+	var tests = []struct {
+		name     string
+		openapi  string
+		input    string
+		expected map[string][]string
+		err      string
+	}{
+		{
+			name: "infer-setters",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "1"
+    io.k8s.cli.setters.args:
+      type: array
+      x-k8s-cli:
+        setter:
+          name: args
+          listValues: ["--quiet"]
+`,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 3 # {"$openapi":"replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        args: # {"$openapi":"args"}
+        - --verbose
+        - --port=8080
+`,
+			expected: map[string][]string{
+				"replicas": {"3"},
+				"args":     {"--verbose", "--port=8080"},
+			},
+		},
+		{
+			name: "infer-enum",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.size:
+      x-k8s-cli:
+        setter:
+          name: size
+          value: small
+          enumValues:
+            small: "1"
+            large: "5"
+`,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 5 # {"$openapi":"size"}
+`,
+			expected: map[string][]string{
+				"size": {"large"},
+			},
+		},
+		{
+			name: "infer-substitution",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-name:
+      x-k8s-cli:
+        setter:
+          name: image-name
+          value: nginx
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: 1.7.9
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: IMAGE_NAME:IMAGE_TAG
+          values:
+          - marker: IMAGE_NAME
+            ref: "#/definitions/io.k8s.cli.setters.image-name"
+          - marker: IMAGE_TAG
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+`,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: gcr.io:5000/nginx:1.8.1 # {"$openapi":"image"}
+`,
+			expected: map[string][]string{
+				"image-name": {"gcr.io:5000/nginx"},
+				"image-tag":  {"1.8.1"},
+			},
+		},
+		{
+			name: "infer-nested-substitution",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.project:
+      x-k8s-cli:
+        setter:
+          name: project
+          value: dev
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: 1.7.9
+    io.k8s.cli.substitutions.registry:
+      x-k8s-cli:
+        substitution:
+          name: registry
+          pattern: gcr.io/${project}
+          values:
+          - marker: ${project}
+            ref: "#/definitions/io.k8s.cli.setters.project"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: ${registry}/nginx:${image-tag}
+          values:
+          - marker: ${registry}
+            ref: "#/definitions/io.k8s.cli.substitutions.registry"
+          - marker: ${image-tag}
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+`,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: gcr.io/prod/nginx:1.8.1 # {"$openapi":"image"}
+`,
+			expected: map[string][]string{
+				"project":   {"prod"},
+				"image-tag": {"1.8.1"},
+			},
+		},
+		{
+			name: "infer-regex-substitution",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: 1.7.9
+    io.k8s.cli.substitutions.tagged-image:
+      x-k8s-cli:
+        substitution:
+          name: tagged-image
+          regex: "^(?:.*/)?[^/:]+:(?P<tag>[^@]+)$"
+          values:
+          - marker: tag
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+`,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: gcr.io/project/nginx:1.8.1 # {"$openapi":"tagged-image"}
+      - name: sidecar
+        image: sidecar # {"$openapi":"tagged-image"}
+`,
+			expected: map[string][]string{
+				"image-tag": {"1.8.1"},
+			},
+		},
+		{
+			name: "infer-targets",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "1"
+          targets:
+          - select: {kind: Deployment}
+            fieldPath: spec.replicas
+`,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 3
+`,
+			expected: map[string][]string{
+				"replicas": {"3"},
+			},
+		},
+		{
+			name: "conflicting-values",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "1"
+`,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  annotations:
+    replicas: "3" # {"$openapi":"replicas"}
+spec:
+  replicas: 4 # {"$openapi":"replicas"}
+`,
+			err: "setter replicas has conflicting values 3 in Deployment/nginx metadata.annotations.replicas, " +
+				"and 4 in Deployment/nginx spec.replicas",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			defer openapi.ResetOpenAPI()
+			initSchema(t, test.openapi)
+
+			r, err := yaml.Parse(test.input)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			expected := r.MustString()
+
+			instance := &Infer{}
+			result, err := instance.Filter(r)
+			if test.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, test.err, err.Error())
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, test.expected, instance.Values)
+
+			// the resource is left as it is
+			assert.Equal(t, expected, result.MustString())
+		})
+	}
+}
@@ -11,13 +11,18 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/sets"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
-// List lists the setters specified in the OpenAPI
+// List lists the setters specified in the OpenAPI, as structs which
+// tooling may use, e.g. to build UIs over the parameters of packages.
 type List struct {
 	Name string
 
+	// Group, if set, lists only the setters in the group, see InGroup.
+	Group string
+
 	Setters []SetterDefinition
 
 	Substitutions []SubstitutionDefinition
@@ -57,6 +62,12 @@ func (l *List) listSetters(object *yaml.RNode, resourcePath string) error {
 		return nil
 	}
 
+	// find the substitutions using each setter
+	usedBy, err := substitutionsBySetter(def)
+	if err != nil {
+		return err
+	}
+
 	// iterate over definitions -- find those that are setters
 	err = def.VisitFields(func(node *yaml.MapNode) error {
 		setter := SetterDefinition{}
@@ -91,6 +102,10 @@ func (l *List) listSetters(object *yaml.RNode, resourcePath string) error {
 			// not the setter that was requested by list
 			return nil
 		}
+		if l.Group != "" && !InGroup(setter.Name, l.Group) {
+			// not in the group that was requested by list
+			return nil
+		}
 
 		// the description is not part of the extension, and should be pulled out
 		// separately from the extension values.
@@ -104,6 +119,7 @@ func (l *List) listSetters(object *yaml.RNode, resourcePath string) error {
 		if err != nil {
 			return err
 		}
+		setter.Substitutions = usedBy[setter.Name]
 
 		l.Setters = append(l.Setters, setter)
 		return nil
@@ -180,6 +196,69 @@ func (l *List) listSubst(object *yaml.RNode) error {
 	return nil
 }
 
+// substitutionsBySetter returns the sorted names of the substitutions of the
+// OpenAPI definitions def which use each setter, directly or through the
+// substitutions nested in them, keyed by the names of the setters.
+func substitutionsBySetter(def *yaml.RNode) (map[string][]string, error) {
+	setterPrefix := fieldmeta.DefinitionsPrefix + fieldmeta.SetterDefinitionPrefix
+	substPrefix := fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix
+
+	// the values of each substitution
+	values := map[string][]Value{}
+	var names []string
+	err := def.VisitFields(func(node *yaml.MapNode) error {
+		if !strings.HasPrefix(node.Key.YNode().Value, fieldmeta.SubstitutionDefinitionPrefix) {
+			return nil
+		}
+		substNode, err := node.Value.Pipe(yaml.Lookup(K8sCliExtensionKey, "substitution"))
+		if err != nil || yaml.IsEmpty(substNode) {
+			return err
+		}
+		b, err := substNode.String()
+		if err != nil {
+			return err
+		}
+		subst := SubstitutionDefinition{}
+		if err := yaml.Unmarshal([]byte(b), &subst); err != nil {
+			return err
+		}
+		values[subst.Name] = subst.Values
+		names = append(names, subst.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	usedBy := map[string][]string{}
+	for _, name := range names {
+		// walk the nested substitutions, minding cycles
+		setters := sets.String{}
+		visited := sets.String{}
+		var walk func(subst string)
+		walk = func(subst string) {
+			if visited.Has(subst) {
+				return
+			}
+			visited.Insert(subst)
+			for _, v := range values[subst] {
+				switch {
+				case strings.HasPrefix(v.Ref, setterPrefix):
+					setters.Insert(strings.TrimPrefix(v.Ref, setterPrefix))
+				case strings.HasPrefix(v.Ref, substPrefix):
+					walk(strings.TrimPrefix(v.Ref, substPrefix))
+				}
+			}
+		}
+		walk(name)
+		for setter := range setters {
+			usedBy[setter] = append(usedBy[setter], name)
+		}
+	}
+	return usedBy, nil
+}
+
 // count returns the number of fields set by the setter with name
 // set filter is leveraged for this but the resources are not written
 // back to files as only LocalPackageReader is invoked and not writer
@@ -16,6 +16,7 @@ func TestList(t *testing.T) {
 	var tests = []struct {
 		name     string
 		setter   string
+		group    string
 		openapi  string
 		input    string
 		expected []SetterDefinition
@@ -45,6 +46,116 @@ spec:
 				{Name: "replicas", Value: "3", SetBy: "me", Description: "hello world", Count: 1},
 			},
 		},
+		{
+			name: "list-nested-substitutions",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-name:
+      x-k8s-cli:
+        setter:
+          name: image-name
+          value: "nginx"
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.7.9"
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+    io.k8s.cli.substitutions.tag:
+      x-k8s-cli:
+        substitution:
+          name: tag
+          pattern: ":${image-tag}"
+          values:
+          - marker: ${image-tag}
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: ${image-name}${tag}
+          values:
+          - marker: ${image-name}
+            ref: "#/definitions/io.k8s.cli.setters.image-name"
+          - marker: ${tag}
+            ref: "#/definitions/io.k8s.cli.substitutions.tag"
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+ `,
+			expected: []SetterDefinition{
+				{Name: "image-name", Value: "nginx", Substitutions: []string{"image"}},
+				{Name: "image-tag", Value: "1.7.9", Substitutions: []string{"image", "tag"}},
+				{Name: "replicas", Value: "3"},
+			},
+		},
+		{
+			name: "list-deprecated",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+          deprecated: true
+          replacedBy: replica-count
+    io.k8s.cli.setters.replica-count:
+      x-k8s-cli:
+        setter:
+          name: replica-count
+          value: "3"
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+ `,
+			expected: []SetterDefinition{
+				{Name: "replica-count", Value: "3"},
+				{Name: "replicas", Value: "3", Deprecated: true, ReplacedBy: "replica-count"},
+			},
+		},
+		{
+			name: "list-targets",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+          targets:
+          - select:
+              kind: Deployment
+            fieldPath: spec.replicas
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3
+ `,
+			expected: []SetterDefinition{
+				{Name: "replicas", Value: "3", Count: 1, Targets: []Target{{
+					Select:    Selector{Kind: "Deployment"},
+					FieldPath: "spec.replicas",
+				}}},
+			},
+		},
 		{
 			name: "list-multiple",
 			openapi: `
@@ -98,9 +209,11 @@ spec:
         image: nginx # {"$ref": "#/definitions/io.k8s.cli.setters.image"}
  `,
 			expected: []SetterDefinition{
-				{Name: "image", Value: "nginx", SetBy: "me2", Description: "hello world 2", Count: 2},
+				{Name: "image", Value: "nginx", SetBy: "me2", Description: "hello world 2", Count: 2,
+					Substitutions: []string{"image"}},
 				{Name: "replicas", Value: "3", SetBy: "me1", Description: "hello world 1", Count: 1},
-				{Name: "tag", Value: "1.7.9", SetBy: "me3", Description: "hello world 3", Count: 1},
+				{Name: "tag", Value: "1.7.9", SetBy: "me3", Description: "hello world 3", Count: 1,
+					Substitutions: []string{"image"}},
 			},
 		},
 		{
@@ -170,9 +283,11 @@ spec:
         image: nginx
 `,
 			expected: []SetterDefinition{
-				{Name: "image", Value: "nginx", SetBy: "me2", Description: "hello world 2", Count: 3},
+				{Name: "image", Value: "nginx", SetBy: "me2", Description: "hello world 2", Count: 3,
+					Substitutions: []string{"image"}},
 				{Name: "replicas", Value: "3", SetBy: "me1", Description: "hello world 1", Count: 2},
-				{Name: "tag", Value: "1.7.9", SetBy: "me3", Description: "hello world 3", Count: 2},
+				{Name: "tag", Value: "1.7.9", SetBy: "me3", Description: "hello world 3", Count: 2,
+					Substitutions: []string{"image"}},
 			},
 		},
 		{
@@ -243,7 +358,48 @@ spec:
 `,
 			setter: "image",
 			expected: []SetterDefinition{
-				{Name: "image", Value: "nginx", SetBy: "me2", Description: "hello world 2", Count: 3},
+				{Name: "image", Value: "nginx", SetBy: "me2", Description: "hello world 2", Count: 3,
+					Substitutions: []string{"image"}},
+			},
+		},
+		{
+			name: "list-group",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.frontend.replicas:
+      x-k8s-cli:
+        setter:
+          name: frontend.replicas
+          value: "3"
+    io.k8s.cli.setters.frontend.web.replicas:
+      x-k8s-cli:
+        setter:
+          name: frontend.web.replicas
+          value: "2"
+    io.k8s.cli.setters.backend.replicas:
+      x-k8s-cli:
+        setter:
+          name: backend.replicas
+          value: "5"
+    io.k8s.cli.setters.frontend:
+      x-k8s-cli:
+        setter:
+          name: frontend
+          value: nginx
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3 # {"$openapi":"frontend.replicas"}
+ `,
+			group: "frontend",
+			expected: []SetterDefinition{
+				{Name: "frontend.replicas", Value: "3", Count: 1},
+				{Name: "frontend.web.replicas", Value: "2"},
 			},
 		},
 	}
@@ -275,7 +431,7 @@ spec:
 			}
 
 			// invoke the setter
-			instance := &List{Name: test.setter}
+			instance := &List{Name: test.setter, Group: test.group}
 			err = instance.ListSetters(f.Name(), r.Name())
 			if !assert.NoError(t, err) {
 				t.FailNow()
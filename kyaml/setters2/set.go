@@ -5,8 +5,13 @@ package setters2
 
 import (
 	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
@@ -32,17 +37,131 @@ type Set struct {
 
 	// SetAll if set to true will set all setters regardless of name
 	SetAll bool
+
+	// Names, if set, are the names of more setters to set along with
+	// Name, so that they're all set in one pass over the resources.
+	Names sets.String
+
+	// Group, if set, sets all the setters in the group, see InGroup.
+	Group string
+
+	// DryRun, if true, leaves the fields as they are, but still
+	// records the Changes which would be made to them.
+	DryRun bool
+
+	// Changes are the changes made to the values of fields by
+	// calling Filter, or which would be made if DryRun is true.
+	Changes []Change
+
+	// Deprecations are the deprecated setters which set fields
+	// by calling Filter, each listed once.
+	Deprecations []Deprecation
+
+	// file and resource identify the resource being filtered
+	file, resource string
+}
+
+// Change is a change to the value of a field made by a setter.
+type Change struct {
+	// File is the path of the file of the resource, if known.
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+
+	// Resource is the kind and name of the resource, e.g. Deployment/nginx
+	Resource string `yaml:"resource" json:"resource"`
+
+	// Field is the path to the field, e.g. spec.replicas
+	Field string `yaml:"field" json:"field"`
+
+	// OldValue is the value of the field before the change.
+	// The values of sequences are listed in flow style.
+	OldValue string `yaml:"oldValue" json:"oldValue"`
+
+	// NewValue is the value of the field after the change.
+	NewValue string `yaml:"newValue" json:"newValue"`
+}
+
+// Deprecation warns that a setter which was set is deprecated.  Deprecated
+// setters still set fields, so that packages may rename their setters
+// without breaking those who set them by their old names.
+type Deprecation struct {
+	// Setter is the name of the deprecated setter.
+	Setter string `yaml:"setter" json:"setter"`
+
+	// ReplacedBy, if set, is the name of the setter to set instead.
+	ReplacedBy string `yaml:"replacedBy,omitempty" json:"replacedBy,omitempty"`
+}
+
+func (d Deprecation) String() string {
+	if d.ReplacedBy == "" {
+		return fmt.Sprintf("setter %s is deprecated", d.Setter)
+	}
+	return fmt.Sprintf("setter %s is deprecated, set %s instead", d.Setter, d.ReplacedBy)
+}
+
+// recordDeprecation records the setter of ext if it's deprecated,
+// and hasn't been recorded already.
+func (s *Set) recordDeprecation(ext *CliExtension) {
+	if !ext.Setter.Deprecated {
+		return
+	}
+	for _, d := range s.Deprecations {
+		if d.Setter == ext.Setter.Name {
+			return
+		}
+	}
+	s.Deprecations = append(s.Deprecations,
+		Deprecation{Setter: ext.Setter.Name, ReplacedBy: ext.Setter.ReplacedBy})
 }
 
 // Filter implements Set as a yaml.Filter
 func (s *Set) Filter(object *yaml.RNode) (*yaml.RNode, error) {
-	return object, accept(s, object)
+	s.file, _, _ = kioutil.GetFileAnnotations(object)
+	s.resource = ""
+	if meta, err := object.GetMeta(); err == nil {
+		s.resource = meta.Kind + "/" + meta.Name
+	}
+	if err := accept(s, object); err != nil {
+		return object, err
+	}
+	return object, acceptTargets(s, object)
+}
+
+// recordChange records the change to the value of field at path p,
+// which was old, and undoes it if s.DryRun is true.
+func (s *Set) recordChange(field *yaml.RNode, p string, old yaml.Node) {
+	oldValue, newValue := nodeValue(&old), nodeValue(field.YNode())
+	if oldValue != newValue {
+		s.Changes = append(s.Changes, Change{
+			File:     s.file,
+			Resource: s.resource,
+			Field:    strings.TrimPrefix(p, "."),
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+	if s.DryRun {
+		*field.YNode() = old
+	}
+}
+
+// nodeValue returns the value of the scalar or sequence node n,
+// with the values of sequences listed in flow style.
+func nodeValue(n *yaml.Node) string {
+	if n.Kind != yaml.SequenceNode {
+		return n.Value
+	}
+	var values []string
+	for _, e := range n.Content {
+		values = append(values, e.Value)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
 }
 
 // isMatch returns true if the setter with name should have the field
 // value set
 func (s *Set) isMatch(name string) bool {
-	return s.SetAll || s.Name == name
+	return s.SetAll || s.Name == name || s.Names.Has(name) ||
+		s.Group != "" && InGroup(name, s.Group)
 }
 
 func (s *Set) visitMapping(object *yaml.RNode, p string, _ *openapi.ResourceSchema) error {
@@ -61,6 +180,7 @@ func (s *Set) visitSequence(object *yaml.RNode, p string, schema *openapi.Resour
 		return nil
 	}
 	s.Count++
+	old := *object.YNode()
 
 	// set the values on the sequences
 	var elements []*yaml.Node
@@ -69,14 +189,25 @@ func (s *Set) visitSequence(object *yaml.RNode, p string, schema *openapi.Resour
 			return err
 		}
 	}
+	s.recordDeprecation(ext)
+	items := schema.Elements()
 	for i := range ext.Setter.ListValues {
 		v := ext.Setter.ListValues[i]
 		n := yaml.NewScalarRNode(v).YNode()
 		n.Style = yaml.DoubleQuotedStyle
+		if items != nil {
+			// don't quote the elements if they're e.g. integers
+			yaml.FormatNonStringStyle(n, *items.Schema)
+		}
 		elements = append(elements, n)
 	}
 	object.YNode().Content = elements
-	object.YNode().Style = yaml.FoldedStyle
+	if object.YNode().Style&yaml.FlowStyle == 0 || object.YNode().LineComment == "" {
+		// fan the values out in block style, unless the setter comment is
+		// on the flow style sequence itself, which block style would drop
+		object.YNode().Style = yaml.FoldedStyle
+	}
+	s.recordChange(object, p, old)
 	return nil
 }
 
@@ -90,6 +221,7 @@ func (s *Set) visitScalar(object *yaml.RNode, p string, schema *openapi.Resource
 	if ext == nil {
 		return nil
 	}
+	old := *object.YNode()
 
 	// perform a direct set of the field if it matches
 	ok, err := s.set(object, ext, schema.Schema)
@@ -98,23 +230,25 @@ func (s *Set) visitScalar(object *yaml.RNode, p string, schema *openapi.Resource
 	}
 	if ok {
 		s.Count++
+		s.recordChange(object, p, old)
 		return nil
 	}
 
 	// perform a substitution of the field if it matches
-	sub, err := s.substitute(object, ext)
+	sub, err := s.substitute(object, ext, schema.Schema)
 	if err != nil {
 		return err
 	}
 	if sub {
 		s.Count++
+		s.recordChange(object, p, old)
 	}
 	return nil
 }
 
 // substitute updates the value of field from ext if ext contains a substitution that
 // depends on a setter whose name matches s.Name.
-func (s *Set) substitute(field *yaml.RNode, ext *CliExtension) (bool, error) {
+func (s *Set) substitute(field *yaml.RNode, ext *CliExtension, sch *spec.Schema) (bool, error) {
 	// check partial setters to see if they contain the setter as part of a
 	// substitution
 	if ext.Substitution == nil {
@@ -129,7 +263,13 @@ func (s *Set) substitute(field *yaml.RNode, ext *CliExtension) (bool, error) {
 	// parsing, it indicates the match
 	nameMatch := false
 
-	res, err := s.substituteUtil(ext, visited, &nameMatch)
+	var res string
+	var err error
+	if ext.Substitution.Regex != "" {
+		res, err = s.substituteRegex(field.YNode().Value, ext, visited, &nameMatch)
+	} else {
+		res, err = s.substituteUtil(ext, visited, &nameMatch)
+	}
 	if err != nil {
 		return false, err
 	}
@@ -141,8 +281,13 @@ func (s *Set) substitute(field *yaml.RNode, ext *CliExtension) (bool, error) {
 
 	field.YNode().Value = res
 
-	// substitutions are always strings
-	field.YNode().Tag = yaml.NodeTagString
+	// substitutions are strings, unless their definition declares another type,
+	// so quote results such as "8080" which would otherwise be read as numbers
+	if len(sch.Type) == 1 {
+		formatValue(field.YNode(), *sch)
+	} else {
+		formatValue(field.YNode(), *spec.StringProperty())
+	}
 
 	return true, nil
 }
@@ -164,53 +309,140 @@ func (s *Set) substituteUtil(ext *CliExtension, visited sets.String, nameMatch *
 	// if substitution references to another substitution, recursively
 	// process the nested substitutions to replace the pattern with setter values
 	for _, v := range ext.Substitution.Values {
-		if v.Ref == "" {
-			return "", errors.Errorf(
-				"missing reference on substitution " + ext.Substitution.Name)
-		}
-		ref, err := spec.NewRef(v.Ref)
+		val, err := s.markerValue(ext, v, visited, nameMatch)
 		if err != nil {
-			return "", errors.Wrap(err)
+			return "", err
 		}
-		def, err := openapi.Resolve(&ref) // resolve the def to its openAPI def
-		if err != nil {
-			return "", errors.Wrap(err)
+		pattern = strings.ReplaceAll(pattern, v.Marker, val)
+	}
+
+	return pattern, nil
+}
+
+// substituteRegex replaces the capture groups of the regex of the substitution
+// in ext, matched against the field value, with the values their markers
+// reference.  The rest of the field value is kept, so it may vary between fields.
+// Fields which don't match the regex are left as they are.
+func (s *Set) substituteRegex(
+	value string, ext *CliExtension, visited sets.String, nameMatch *bool) (string, error) {
+	visited.Insert(ext.Substitution.Name)
+	re, err := regexp.Compile(ext.Substitution.Regex)
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "substitution %s", ext.Substitution.Name)
+	}
+	match := re.FindStringSubmatchIndex(value)
+	if match == nil {
+		return value, nil
+	}
+
+	// the values of the capture groups, by their numbers
+	groups := map[int]string{}
+	for _, v := range ext.Substitution.Values {
+		i := captureGroup(re, v.Marker)
+		if i < 1 {
+			return "", errors.Errorf("marker %s of substitution %s is not a capture group of its regex",
+				v.Marker, ext.Substitution.Name)
 		}
-		defExt, err := GetExtFromSchema(def) // parse the extension out of the openAPI
+		val, err := s.markerValue(ext, v, visited, nameMatch)
 		if err != nil {
-			return "", errors.Wrap(err)
+			return "", err
 		}
-
-		if defExt.Substitution != nil {
-			// parse recursively if it reference is substitution
-			substVal, err := s.substituteUtil(defExt, visited, nameMatch)
-			if err != nil {
-				return "", err
-			}
-			pattern = strings.ReplaceAll(pattern, v.Marker, substVal)
+		groups[i] = val
+	}
+
+	// capture groups are numbered in the order they start, so the
+	// value is rebuilt from left to right, skipping the groups within
+	// a group already replaced, and those which didn't match
+	var b strings.Builder
+	last := 0
+	for i := 1; i <= re.NumSubexp(); i++ {
+		val, found := groups[i]
+		start, end := match[2*i], match[2*i+1]
+		if !found || start < last {
 			continue
 		}
+		b.WriteString(value[last:start])
+		b.WriteString(val)
+		last = end
+	}
+	b.WriteString(value[last:])
+	return b.String(), nil
+}
 
-		// if code reaches this point, this is a setter, so validate the setter schema
-		if err := validateAgainstSchema(defExt, def); err != nil {
-			return "", err
+// captureGroup returns the number of the capture group of re which
+// marker names or numbers, or -1 if there is no such group.
+func captureGroup(re *regexp.Regexp, marker string) int {
+	for i, name := range re.SubexpNames() {
+		if i > 0 && name == marker {
+			return i
 		}
+	}
+	if i, err := strconv.Atoi(marker); err == nil && i > 0 && i <= re.NumSubexp() {
+		return i
+	}
+	return -1
+}
 
-		if s.isMatch(defExt.Setter.Name) {
-			// the substitution depends on the specified setter
-			*nameMatch = true
-		}
+// markerValue returns the value of the setter or nested substitution
+// which the value v of the substitution in ext references, or the
+// default of v if that value is empty.
+func (s *Set) markerValue(
+	ext *CliExtension, v substitutionSetterReference, visited sets.String, nameMatch *bool) (string, error) {
+	val, err := s.refValue(ext, v, visited, nameMatch)
+	if err != nil || val != "" {
+		return val, err
+	}
+	return v.Default, nil
+}
 
-		if val, found := defExt.Setter.EnumValues[defExt.Setter.Value]; found {
-			// the setter has an enum-map.  we should replace the marker with the
-			// enum value looked up from the map rather than the enum key
-			pattern = strings.ReplaceAll(pattern, v.Marker, val)
-		} else {
-			pattern = strings.ReplaceAll(pattern, v.Marker, defExt.Setter.Value)
+// refValue returns the value of the setter or nested substitution
+// which the value v of the substitution in ext references.
+func (s *Set) refValue(
+	ext *CliExtension, v substitutionSetterReference, visited sets.String, nameMatch *bool) (string, error) {
+	if v.Ref == "" {
+		return "", errors.Errorf(
+			"missing reference on substitution " + ext.Substitution.Name)
+	}
+	ref, err := spec.NewRef(v.Ref)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	def, err := openapi.Resolve(&ref) // resolve the def to its openAPI def
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	defExt, err := GetExtFromSchema(def) // parse the extension out of the openAPI
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+
+	if defExt.Substitution != nil {
+		if defExt.Substitution.Regex != "" {
+			// a regex substitution needs the value of a field to match
+			return "", errors.Errorf("substitution %s has a regex, so it can't be nested in substitution %s",
+				defExt.Substitution.Name, ext.Substitution.Name)
 		}
+		// parse recursively if it reference is substitution
+		return s.substituteUtil(defExt, visited, nameMatch)
 	}
 
-	return pattern, nil
+	// if code reaches this point, this is a setter, so validate the setter schema
+	if err := validateAgainstSchema(defExt, def); err != nil {
+		return "", err
+	}
+
+	if s.isMatch(defExt.Setter.Name) {
+		// the substitution depends on the specified setter
+		*nameMatch = true
+		s.recordDeprecation(defExt)
+	}
+
+	if val, found := defExt.Setter.EnumValues[defExt.Setter.Value]; found {
+		// the setter has an enum-map.  we should replace the marker with the
+		// enum value looked up from the map rather than the enum key
+		return val, nil
+	}
+	return defExt.Setter.Value, nil
 }
 
 // set applies the value from ext to field if its name matches s.Name
@@ -223,6 +455,7 @@ func (s *Set) set(field *yaml.RNode, ext *CliExtension, sch *spec.Schema) (bool,
 	if err := validateAgainstSchema(ext, sch); err != nil {
 		return false, err
 	}
+	s.recordDeprecation(ext)
 
 	if val, found := ext.Setter.EnumValues[ext.Setter.Value]; found {
 		// the setter has an enum-map.  we should replace the marker with the
@@ -235,10 +468,45 @@ func (s *Set) set(field *yaml.RNode, ext *CliExtension, sch *spec.Schema) (bool,
 	field.YNode().Value = ext.Setter.Value
 
 	// format the node so it is quoted if it is a string
-	yaml.FormatNonStringStyle(field.YNode(), *sch)
+	formatValue(field.YNode(), *sch)
 	return true, nil
 }
 
+// formatValue tags and quotes the scalar node for the type declared by sch, so that
+// the value is read back as that type, rather than as whatever it looks like, or
+// whatever the field was before it was set.  Nodes are left as they are if sch
+// doesn't declare a type.
+func formatValue(node *yaml.Node, sch spec.Schema) {
+	if len(sch.Type) != 1 {
+		return
+	}
+	quoted := node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0
+	switch t := sch.Type[0]; {
+	case t == "string" && sch.Format == "int-or-string":
+		if _, err := strconv.Atoi(node.Value); err == nil {
+			node.Tag = yaml.NodeTagInt
+			node.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+			return
+		}
+		node.Tag = yaml.NodeTagString
+	case t == "string":
+		node.Tag = yaml.NodeTagString
+		if !quoted && node.Value != "" && yaml.IsValueNonString(node.Value) {
+			// must quote values so they are parsed as strings
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	case t == "integer" || t == "int":
+		node.Tag = yaml.NodeTagInt
+		node.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+	case t == "boolean" || t == "bool":
+		node.Tag = yaml.NodeTagBool
+		node.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+	case t == "number":
+		node.Tag = yaml.NodeTagFloat
+		node.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+	}
+}
+
 // validateAgainstSchema validates the input setter value against user provided
 // openAI schema
 func validateAgainstSchema(ext *CliExtension, sch *spec.Schema) error {
@@ -297,7 +565,11 @@ func validateAgainstSchema(ext *CliExtension, sch *spec.Schema) error {
 	}
 	err = validate.AgainstSchema(&sc, input, strfmt.Default)
 	if err != nil {
-		return errors.Errorf("The input value doesn't validate against provided OpenAPI schema: %v\n", err.Error())
+		// the failures are listed one per line after a header,
+		// and are kept one per line
+		failures := strings.TrimPrefix(err.Error(), "validation failure list:\n")
+		return errors.Errorf("invalid value for setter %s: %s",
+			ext.Setter.Name, strings.TrimSpace(failures))
 	}
 	return nil
 }
@@ -342,6 +614,15 @@ type SetOpenAPI struct {
 	Description string `yaml:"description"`
 
 	SetBy string `yaml:"setBy"`
+
+	// FromEnv, if set, is the environment variable to set the value to,
+	// rather than Value.  It's recorded as fromEnv in the definition of
+	// the setter, so it's known where the value came from.
+	FromEnv string `yaml:"fromEnv"`
+
+	// Force, if true, changes the values of locked setters, which
+	// otherwise can't be changed.
+	Force bool `yaml:"force"`
 }
 
 // UpdateFile updates the OpenAPI definitions in a file with the given setter value.
@@ -366,12 +647,31 @@ func (s SetOpenAPI) Filter(object *yaml.RNode) (*yaml.RNode, error) {
 		return nil, errors.Errorf("no setter %s found", s.Name)
 	}
 
+	if s.FromEnv != "" {
+		value, found := os.LookupEnv(s.FromEnv)
+		if !found {
+			return nil, errors.Errorf(
+				"environment variable %s for setter %s is not set", s.FromEnv, s.Name)
+		}
+		s.Value = value
+	}
+
 	// record the OpenAPI type for the setter
 	var t string
 	if n := oa.Field("type"); n != nil {
 		t = n.Value.YNode().Value
 	}
 
+	if err := s.checkLocked(def, t); err != nil {
+		return nil, err
+	}
+
+	// ensure the set value validates against the schema of the
+	// setter, e.g. its type and enum, before anything is set
+	if err := s.validate(oa, t); err != nil {
+		return nil, err
+	}
+
 	// if the setter contains an enumValues map, then ensure the set value appears
 	// as a key in the map
 	if values, err := def.Pipe(
@@ -451,6 +751,21 @@ func (s SetOpenAPI) Filter(object *yaml.RNode) (*yaml.RNode, error) {
 		return nil, err
 	}
 
+	// record where the value came from, or that it no longer comes from
+	// an environment variable
+	if s.FromEnv != "" {
+		err = def.PipeE(&yaml.FieldSetter{Name: "fromEnv", StringValue: s.FromEnv})
+	} else {
+		err = def.PipeE(&yaml.FieldClearer{Name: "fromEnv"})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordHistory(def, t); err != nil {
+		return nil, err
+	}
+
 	if s.Description != "" {
 		d, err := object.Pipe(yaml.LookupCreate(
 			yaml.MappingNode, "openAPI", "definitions", key))
@@ -465,6 +780,85 @@ func (s SetOpenAPI) Filter(object *yaml.RNode) (*yaml.RNode, error) {
 	return object, nil
 }
 
+// now returns the current time, and is replaced by tests.
+var now = time.Now
+
+// recordHistory appends the value of s to the history of the setter
+// definition def, whose type is t, if the setter records its history.
+func (s SetOpenAPI) recordHistory(def *yaml.RNode, t string) error {
+	record, err := def.Pipe(yaml.Lookup("recordHistory"))
+	if err != nil {
+		return err
+	}
+	if record == nil || record.YNode().Value != "true" {
+		return nil
+	}
+
+	entry := HistoryEntry{
+		Value:     s.Value,
+		SetBy:     s.SetBy,
+		Timestamp: now().UTC().Format(time.RFC3339),
+	}
+	if t == "array" {
+		entry.Value = ""
+		entry.ListValues = append([]string{s.Value}, s.ListValues...)
+	}
+	b, err := yaml.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	e, err := yaml.Parse(string(b))
+	if err != nil {
+		return err
+	}
+	history, err := def.Pipe(yaml.LookupCreate(yaml.SequenceNode, "history"))
+	if err != nil {
+		return err
+	}
+	return history.PipeE(yaml.Append(e.YNode()))
+}
+
+// checkLocked returns an error if the setter definition def, whose type
+// is t, is locked and s would change its value, unless s is forced.
+func (s SetOpenAPI) checkLocked(def *yaml.RNode, t string) error {
+	if s.Force {
+		return nil
+	}
+	current := &setter{}
+	if err := def.YNode().Decode(current); err != nil {
+		return errors.Wrap(err)
+	}
+	if !current.Locked {
+		return nil
+	}
+	if t == "array" {
+		if reflect.DeepEqual(current.ListValues, append([]string{s.Value}, s.ListValues...)) {
+			return nil
+		}
+	} else if current.Value == s.Value {
+		return nil
+	}
+	return errors.Errorf("setter %s is locked, its value can't be changed", s.Name)
+}
+
+// validate returns an error if the value of s doesn't validate against
+// the schema of its definition oa, whose type is t.
+func (s SetOpenAPI) validate(oa *yaml.RNode, t string) error {
+	b, err := oa.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	sch := &spec.Schema{}
+	if err := sch.UnmarshalJSON(b); err != nil {
+		return errors.Wrap(err)
+	}
+	ext := &CliExtension{Setter: &setter{Name: s.Name, Value: s.Value}}
+	if t == "array" {
+		ext.Setter.ListValues = append([]string{s.Value}, s.ListValues...)
+	}
+	return validateAgainstSchema(ext, sch)
+}
+
 // SetAll applies the set filter for all yaml nodes and only returns the nodes whose
 // corresponding file has at least one node with input setter
 func SetAll(s *Set) kio.Filter {
@@ -5,6 +5,7 @@ package setters2
 
 import (
 	"encoding/json"
+	"os"
 	"strings"
 	"testing"
 
@@ -152,6 +153,249 @@ metadata:
   name: nginx-deployment
   annotations:
     foo: 4 # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
+ `,
+		},
+		{
+			name:        "set-string-type-quotes-value",
+			description: "values of string setters are quoted if they would otherwise be read as other types",
+			setter:      "enabled",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.enabled:
+      type: string
+      x-k8s-cli:
+        setter:
+          name: enabled
+          value: "true"
+ `,
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  annotations:
+    enabled: no # {"$openapi":"enabled"}
+ `,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  annotations:
+    enabled: "true" # {"$openapi":"enabled"}
+ `,
+		},
+		{
+			name:        "set-string-type-over-integer",
+			description: "values of string setters are tagged as strings, whatever the type of the field was",
+			setter:      "port-name",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.port-name:
+      type: string
+      x-k8s-cli:
+        setter:
+          name: port-name
+          value: "http"
+ `,
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - name: 8080 # {"$openapi":"port-name"}
+ `,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - name: http # {"$openapi":"port-name"}
+ `,
+		},
+		{
+			name:        "set-integer-type-unquotes-value",
+			description: "values of integer setters are unquoted, even if the field was quoted",
+			setter:      "port",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.port:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: port
+          value: "8080"
+ `,
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - port: "80" # {"$openapi":"port"}
+ `,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - port: 8080 # {"$openapi":"port"}
+ `,
+		},
+		{
+			name:        "set-boolean-type-unquotes-value",
+			description: "values of boolean setters are unquoted, even if the field was quoted",
+			setter:      "enabled",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.enabled:
+      type: boolean
+      x-k8s-cli:
+        setter:
+          name: enabled
+          value: "true"
+ `,
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  annotations:
+    enabled: 'false' # {"$openapi":"enabled"}
+ `,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  annotations:
+    enabled: true # {"$openapi":"enabled"}
+ `,
+		},
+		{
+			name:        "set-int-or-string",
+			description: "int-or-string setters are unquoted for integers, and tagged as strings otherwise",
+			setter:      "target-port",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.target-port:
+      type: string
+      format: int-or-string
+      x-k8s-cli:
+        setter:
+          name: target-port
+          value: "8080"
+ `,
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - targetPort: "http" # {"$openapi":"target-port"}
+ `,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - targetPort: 8080 # {"$openapi":"target-port"}
+ `,
+		},
+		{
+			name:        "substitute-quotes-value",
+			description: "substitutions are strings, so results which look like other types are quoted",
+			setter:      "port",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.port:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: port
+          value: "8080"
+    io.k8s.cli.substitutions.port-annotation:
+      x-k8s-cli:
+        substitution:
+          name: port-annotation
+          pattern: PORT
+          values:
+          - marker: PORT
+            ref: "#/definitions/io.k8s.cli.setters.port"
+ `,
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  annotations:
+    port: "80" # {"$openapi":"port-annotation"}
+ `,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  annotations:
+    port: "8080" # {"$openapi":"port-annotation"}
+ `,
+		},
+		{
+			name:        "substitute-typed",
+			description: "substitutions with a declared type are formatted as that type",
+			setter:      "port",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.port:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: port
+          value: "8080"
+    io.k8s.cli.substitutions.port-offset:
+      type: integer
+      x-k8s-cli:
+        substitution:
+          name: port-offset
+          pattern: 1PORT
+          values:
+          - marker: PORT
+            ref: "#/definitions/io.k8s.cli.setters.port"
+ `,
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - port: "180" # {"$openapi":"port-offset"}
+ `,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - port: 18080 # {"$openapi":"port-offset"}
  `,
 		},
 		{
@@ -327,6 +571,107 @@ spec:
 			expected: `
 apiVersion: apps/v1
 kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1 # {"$ref": "#/definitions/io.k8s.cli.substitutions.image"}
+ `,
+		},
+		{
+			name:        "substitute-default",
+			description: "markers of setters with empty values are replaced by their defaults",
+			setter:      "image-tag",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-name:
+      x-k8s-cli:
+        setter:
+          name: image-name
+          value: "nginx"
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: ""
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: ${image-name}:${image-tag}
+          values:
+          - marker: ${image-name}
+            ref: "#/definitions/io.k8s.cli.setters.image-name"
+          - marker: ${image-tag}
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+            default: latest
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$ref": "#/definitions/io.k8s.cli.substitutions.image"}
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:latest # {"$ref": "#/definitions/io.k8s.cli.substitutions.image"}
+ `,
+		},
+		{
+			name:        "substitute-default-unused",
+			description: "defaults aren't used for setters with values",
+			setter:      "image-tag",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.8.1"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: nginx:${image-tag}
+          values:
+          - marker: ${image-tag}
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+            default: latest
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$ref": "#/definitions/io.k8s.cli.substitutions.image"}
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
 metadata:
   name: nginx-deployment
 spec:
@@ -599,17 +944,25 @@ spec:
  `,
 		},
 		{
-			name:   "set-args-list",
-			setter: "args",
+			name:        "substitute-regex",
+			description: "only the capture groups of the regex are replaced",
+			setter:      "image-tag",
 			openapi: `
 openAPI:
   definitions:
-    io.k8s.cli.setters.args:
+    io.k8s.cli.setters.image-tag:
       x-k8s-cli:
-        type: array
         setter:
-          name: args
-          listValues: ["1", "2", "3"]
+          name: image-tag
+          value: "1.8.1"
+    io.k8s.cli.substitutions.tag:
+      x-k8s-cli:
+        substitution:
+          name: tag
+          regex: ^(?:.*/)?[^/:@]+:(?P<tag>[^@]+)$
+          values:
+          - marker: tag
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
  `,
 			input: `
 apiVersion: apps/v1
@@ -617,8 +970,15 @@ kind: Deployment
 metadata:
   name: nginx-deployment
 spec:
-  # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
-  replicas: []
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$ref": "#/definitions/io.k8s.cli.substitutions.tag"}
+      - name: sidecar
+        image: gcr.io/example/sidecar:1.7.9 # {"$ref": "#/definitions/io.k8s.cli.substitutions.tag"}
+      - name: untagged
+        image: busybox # {"$ref": "#/definitions/io.k8s.cli.substitutions.tag"}
  `,
 			expected: `
 apiVersion: apps/v1
@@ -626,37 +986,123 @@ kind: Deployment
 metadata:
   name: nginx-deployment
 spec:
-  # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
-  replicas:
-  - "1"
-  - "2"
-  - "3"
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1 # {"$ref": "#/definitions/io.k8s.cli.substitutions.tag"}
+      - name: sidecar
+        image: gcr.io/example/sidecar:1.8.1 # {"$ref": "#/definitions/io.k8s.cli.substitutions.tag"}
+      - name: untagged
+        image: busybox # {"$ref": "#/definitions/io.k8s.cli.substitutions.tag"}
  `,
 		},
 		{
-			name:   "set-args-list-replace",
-			setter: "args",
+			name:        "substitute-regex-numbered-groups",
+			description: "markers may be the numbers of capture groups",
+			setter:      "domain",
 			openapi: `
 openAPI:
   definitions:
-    io.k8s.cli.setters.args:
+    io.k8s.cli.setters.domain:
       x-k8s-cli:
-        type: array
         setter:
-          name: args
-          listValues: ["1", "2", "3"]
+          name: domain
+          value: "example.org"
+    io.k8s.cli.setters.port:
+      x-k8s-cli:
+        setter:
+          name: port
+          value: "8443"
+    io.k8s.cli.substitutions.endpoint:
+      x-k8s-cli:
+        substitution:
+          name: endpoint
+          regex: ^https://[a-z]+\.([a-z.]+):([0-9]+)/
+          values:
+          - marker: "1"
+            ref: "#/definitions/io.k8s.cli.setters.domain"
+          - marker: "2"
+            ref: "#/definitions/io.k8s.cli.setters.port"
  `,
 			input: `
-apiVersion: apps/v1
-kind: Deployment
+apiVersion: v1
+kind: ConfigMap
 metadata:
-  name: nginx-deployment
-spec:
-  # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
-  replicas: ["4", "5"]
+  name: endpoints
+data:
+  api: https://api.example.com:443/v1 # {"$ref": "#/definitions/io.k8s.cli.substitutions.endpoint"}
+  auth: https://auth.example.com:443/login # {"$ref": "#/definitions/io.k8s.cli.substitutions.endpoint"}
  `,
 			expected: `
-apiVersion: apps/v1
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: endpoints
+data:
+  api: https://api.example.org:8443/v1 # {"$ref": "#/definitions/io.k8s.cli.substitutions.endpoint"}
+  auth: https://auth.example.org:8443/login # {"$ref": "#/definitions/io.k8s.cli.substitutions.endpoint"}
+ `,
+		},
+		{
+			name:   "set-args-list",
+			setter: "args",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.args:
+      x-k8s-cli:
+        type: array
+        setter:
+          name: args
+          listValues: ["1", "2", "3"]
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
+  replicas: []
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
+  replicas:
+  - "1"
+  - "2"
+  - "3"
+ `,
+		},
+		{
+			name:   "set-args-list-replace",
+			setter: "args",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.args:
+      x-k8s-cli:
+        type: array
+        setter:
+          name: args
+          listValues: ["1", "2", "3"]
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
+  replicas: ["4", "5"]
+ `,
+			expected: `
+apiVersion: apps/v1
 kind: Deployment
 metadata:
   name: nginx-deployment
@@ -666,6 +1112,117 @@ spec:
   - "1"
   - "2"
   - "3"
+ `,
+		},
+		{
+			name:        "set-args-list-flow",
+			description: "the setter comment on a flow style sequence is kept",
+			setter:      "args",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.args:
+      x-k8s-cli:
+        type: array
+        setter:
+          name: args
+          listValues: ["--v=2", "--port=80"]
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        args: ["--v=1"] # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        args: ["--v=2", "--port=80"] # {"$ref": "#/definitions/io.k8s.cli.setters.args"}
+ `,
+		},
+		{
+			name:        "set-ports-list-integer-items",
+			description: "elements are formatted for the type of the items",
+			setter:      "ports",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.ports:
+      type: array
+      items:
+        type: integer
+      x-k8s-cli:
+        setter:
+          name: ports
+          listValues: ["80", "443"]
+ `,
+			input: `
+apiVersion: example.com/v1
+kind: Firewall
+metadata:
+  name: web
+spec:
+  ports: # {"$ref": "#/definitions/io.k8s.cli.setters.ports"}
+  - 8080
+ `,
+			expected: `
+apiVersion: example.com/v1
+kind: Firewall
+metadata:
+  name: web
+spec:
+  ports: # {"$ref": "#/definitions/io.k8s.cli.setters.ports"}
+  - 80
+  - 443
+ `,
+		},
+		{
+			name:        "set-cidrs-list-string-items",
+			description: "elements are quoted for string items",
+			setter:      "cidrs",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.cidrs:
+      type: array
+      items:
+        type: string
+      x-k8s-cli:
+        setter:
+          name: cidrs
+          listValues: ["10.0.0.0/8", "192.168.0.0/16"]
+ `,
+			input: `
+apiVersion: example.com/v1
+kind: Firewall
+metadata:
+  name: web
+spec:
+  allowed: # {"$ref": "#/definitions/io.k8s.cli.setters.cidrs"}
+  - 0.0.0.0/0
+ `,
+			expected: `
+apiVersion: example.com/v1
+kind: Firewall
+metadata:
+  name: web
+spec:
+  allowed: # {"$ref": "#/definitions/io.k8s.cli.setters.cidrs"}
+  - "10.0.0.0/8"
+  - "192.168.0.0/16"
  `,
 		},
 		{
@@ -678,89 +1235,548 @@ openAPI:
     io.k8s.cli.setters.foo:
       x-k8s-cli:
         setter:
-          name: foo
-          value: "4"
-      type: int
- `,
-			input: `
+          name: foo
+          value: "4"
+      type: int
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    foo: 3 # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    foo: 4 # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
+ `,
+		},
+		{
+			name:        "set-with-invalid-type-bool",
+			description: "if a type is set to bool instead of boolean, we accept it",
+			setter:      "foo",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.foo:
+      x-k8s-cli:
+        setter:
+          name: foo
+          value: "true"
+      type: bool
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    foo: false # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    foo: true # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
+ `,
+		},
+		{
+			name:   "set-targets",
+			setter: "replicas",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+          targets:
+          - select:
+              kind: Deployment
+              name: nginx-deployment
+            fieldPath: spec.replicas
+          - select:
+              kind: Deployment
+              name: other
+            fieldPath: spec.replicas
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 4
+ `,
+		},
+		{
+			name:   "set-targets-list-element",
+			setter: "image-tag",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.8.1"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: nginx:${image-tag}
+          values:
+          - marker: ${image-tag}
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+          targets:
+          - select:
+              kind: Deployment
+            fieldPath: spec.template.spec.containers[name=nginx.v1].image
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx.v1
+        image: nginx:1.7.9
+      - name: sidecar
+        image: sidecar:1.7.9
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx.v1
+        image: nginx:1.8.1
+      - name: sidecar
+        image: sidecar:1.7.9
+ `,
+		},
+		{
+			name:   "set-targets-list",
+			setter: "args",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.args:
+      type: array
+      x-k8s-cli:
+        setter:
+          name: args
+          listValues: ["a", "b"]
+          targets:
+          - fieldPath: spec.args
+ `,
+			input: `
+apiVersion: example.com/v1
+kind: Example
+metadata:
+  name: example
+spec:
+  args:
+  - c
+ `,
+			expected: `
+apiVersion: example.com/v1
+kind: Example
+metadata:
+  name: example
+spec:
+  args:
+  - "a"
+  - "b"
+ `,
+		},
+		{
+			name:   "set-targets-comment-precedence",
+			setter: "replicas",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+          targets:
+          - fieldPath: spec.replicas
+    io.k8s.cli.setters.other-replicas:
+      x-k8s-cli:
+        setter:
+          name: other-replicas
+          value: "5"
+ `,
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3 # {"$openapi":"other-replicas"}
+ `,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3 # {"$openapi":"other-replicas"}
+ `,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			// reset the openAPI afterward
+			defer openapi.ResetOpenAPI()
+			initSchema(t, test.openapi)
+
+			// parse the input to be modified
+			r, err := yaml.Parse(test.input)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			// invoke the setter
+			instance := &Set{Name: test.setter}
+			result, err := instance.Filter(r)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			// compare the actual and expected output
+			actual, err := result.String()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			actual = strings.TrimSpace(actual)
+			expected := strings.TrimSpace(test.expected)
+			if !assert.Equal(t, expected, actual) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestSet_Filter_dryRun(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	initSchema(t, `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.8.1"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: nginx:${image-tag}
+          values:
+          - marker: ${image-tag}
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+    io.k8s.cli.setters.args:
+      type: array
+      x-k8s-cli:
+        setter:
+          name: args
+          listValues: ["--verbose", "--port=8080"]
+ `)
+	input := strings.TrimSpace(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    config.kubernetes.io/path: deployment.yaml
+spec:
+  replicas: 3 # {"$openapi":"replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1 # {"$openapi":"image"}
+        args: # {"$openapi":"args"}
+        - --quiet
+`)
+	r, err := yaml.Parse(input)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	instance := &Set{SetAll: true, DryRun: true}
+	result, err := instance.Filter(r)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 3, instance.Count)
+	assert.Equal(t, []Change{
+		{
+			File:     "deployment.yaml",
+			Resource: "Deployment/nginx-deployment",
+			Field:    "spec.replicas",
+			OldValue: "3",
+			NewValue: "4",
+		},
+		{
+			File:     "deployment.yaml",
+			Resource: "Deployment/nginx-deployment",
+			Field:    "spec.template.spec.containers.args",
+			OldValue: "[--quiet]",
+			NewValue: "[--verbose, --port=8080]",
+		},
+	}, instance.Changes)
+
+	// the fields are left as they are
+	actual, err := result.String()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, input, strings.TrimSpace(actual))
+}
+
+func TestSet_Filter_deprecated(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	initSchema(t, `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+          deprecated: true
+          replacedBy: replica-count
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.8.1"
+          deprecated: true
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: nginx:${image-tag}
+          values:
+          - marker: ${image-tag}
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+ `)
+	r, err := yaml.Parse(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+  annotations:
+    replicas: "3" # {"$openapi":"replicas"}
+spec:
+  replicas: 3 # {"$openapi":"replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$openapi":"image"}
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// deprecated setters are still set, but each is listed once
+	instance := &Set{SetAll: true}
+	_, err = instance.Filter(r)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 3, instance.Count)
+	assert.Equal(t, []Deprecation{
+		{Setter: "replicas", ReplacedBy: "replica-count"},
+		{Setter: "image-tag"},
+	}, instance.Deprecations)
+	assert.Equal(t, "setter replicas is deprecated, set replica-count instead",
+		instance.Deprecations[0].String())
+	assert.Equal(t, "setter image-tag is deprecated", instance.Deprecations[1].String())
+}
+
+func TestSet_Filter_group(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	initSchema(t, `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.frontend.replicas:
+      x-k8s-cli:
+        setter:
+          name: frontend.replicas
+          value: "3"
+    io.k8s.cli.setters.frontend.web.image:
+      x-k8s-cli:
+        setter:
+          name: frontend.web.image
+          value: nginx:1.8.1
+    io.k8s.cli.setters.frontend-replicas:
+      x-k8s-cli:
+        setter:
+          name: frontend-replicas
+          value: "4"
+    io.k8s.cli.setters.backend.replicas:
+      x-k8s-cli:
+        setter:
+          name: backend.replicas
+          value: "5"
+ `)
+	r, err := yaml.Parse(`
 apiVersion: apps/v1
 kind: Deployment
 metadata:
   name: nginx-deployment
   annotations:
-    foo: 3 # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
- `,
-			expected: `
-apiVersion: apps/v1
+    frontend-replicas: "1" # {"$openapi":"frontend-replicas"}
+    backend-replicas: "1" # {"$openapi":"backend.replicas"}
+spec:
+  replicas: 1 # {"$openapi":"frontend.replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$openapi":"frontend.web.image"}
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// only the setters in the group, and in the groups nested in it, are set
+	instance := &Set{Group: "frontend"}
+	_, err = instance.Filter(r)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 2, instance.Count)
+	assert.Equal(t, `apiVersion: apps/v1
 kind: Deployment
 metadata:
   name: nginx-deployment
   annotations:
-    foo: 4 # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
+    frontend-replicas: "1" # {"$openapi":"frontend-replicas"}
+    backend-replicas: "1" # {"$openapi":"backend.replicas"}
+spec:
+  replicas: 3 # {"$openapi":"frontend.replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1 # {"$openapi":"frontend.web.image"}
+`, r.MustString())
+}
+
+func TestSet_Filter_regexErrors(t *testing.T) {
+	var tests = []struct {
+		name    string
+		openapi string
+		err     string
+	}{
+		{
+			name: "marker-not-a-group",
+			openapi: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.8.1"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          regex: ^[^:]+:(?P<tag>.+)$
+          values:
+          - marker: version
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
  `,
+			err: "marker version of substitution image is not a capture group of its regex",
 		},
 		{
-			name:        "set-with-invalid-type-bool",
-			description: "if a type is set to bool instead of boolean, we accept it",
-			setter:      "foo",
+			name: "nested-regex",
 			openapi: `
 openAPI:
   definitions:
-    io.k8s.cli.setters.foo:
+    io.k8s.cli.setters.image-tag:
       x-k8s-cli:
         setter:
-          name: foo
-          value: "true"
-      type: bool
- `,
-			input: `
-apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: nginx-deployment
-  annotations:
-    foo: false # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
- `,
-			expected: `
-apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: nginx-deployment
-  annotations:
-    foo: true # {"$ref": "#/definitions/io.k8s.cli.setters.foo"}
+          name: image-tag
+          value: "1.8.1"
+    io.k8s.cli.substitutions.tag:
+      x-k8s-cli:
+        substitution:
+          name: tag
+          regex: ^[^:]+:(?P<tag>.+)$
+          values:
+          - marker: tag
+            ref: "#/definitions/io.k8s.cli.setters.image-tag"
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: nginx:TAG
+          values:
+          - marker: TAG
+            ref: "#/definitions/io.k8s.cli.substitutions.tag"
  `,
+			err: "substitution tag has a regex, so it can't be nested in substitution image",
 		},
 	}
 	for i := range tests {
 		test := tests[i]
 		t.Run(test.name, func(t *testing.T) {
-			// reset the openAPI afterward
 			defer openapi.ResetOpenAPI()
 			initSchema(t, test.openapi)
 
-			// parse the input to be modified
-			r, err := yaml.Parse(test.input)
-			if !assert.NoError(t, err) {
-				t.FailNow()
-			}
-
-			// invoke the setter
-			instance := &Set{Name: test.setter}
-			result, err := instance.Filter(r)
-			if !assert.NoError(t, err) {
-				t.FailNow()
-			}
-
-			// compare the actual and expected output
-			actual, err := result.String()
+			r, err := yaml.Parse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: images
+data:
+  image: nginx:1.7.9 # {"$ref": "#/definitions/io.k8s.cli.substitutions.image"}
+`)
 			if !assert.NoError(t, err) {
 				t.FailNow()
 			}
-			actual = strings.TrimSpace(actual)
-			expected := strings.TrimSpace(test.expected)
-			if !assert.Equal(t, expected, actual) {
-				t.FailNow()
-			}
+			_, err = (&Set{Name: "image-tag"}).Filter(r)
+			assert.EqualError(t, err, test.err)
 		})
 	}
 }
@@ -971,7 +1987,100 @@ func TestSetOpenAPI_Filter(t *testing.T) {
 		description string
 		setBy       string
 		err         string
+		fromEnv     string
+		env         map[string]string
+		force       bool
 	}{
+		{
+			name:   "set-locked",
+			setter: "registry",
+			value:  "docker.io",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.registry:
+      x-k8s-cli:
+        setter:
+          name: registry
+          value: gcr.io
+          locked: true
+ `,
+			err: "setter registry is locked, its value can't be changed",
+		},
+		{
+			name:   "set-locked-same-value",
+			setter: "registry",
+			value:  "gcr.io",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.registry:
+      x-k8s-cli:
+        setter:
+          name: registry
+          value: gcr.io
+          locked: true
+ `,
+			expected: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.registry:
+      x-k8s-cli:
+        setter:
+          name: registry
+          value: gcr.io
+          locked: true
+          setBy: me
+          isSet: true
+`,
+			setBy: "me",
+		},
+		{
+			name:   "set-locked-list",
+			setter: "args",
+			value:  "--verbose",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.args:
+      type: array
+      x-k8s-cli:
+        setter:
+          name: args
+          listValues: ["--quiet"]
+          locked: true
+ `,
+			err: "setter args is locked, its value can't be changed",
+		},
+		{
+			name:   "set-locked-force",
+			setter: "registry",
+			value:  "docker.io",
+			force:  true,
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.registry:
+      x-k8s-cli:
+        setter:
+          name: registry
+          value: gcr.io
+          locked: true
+ `,
+			expected: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.registry:
+      x-k8s-cli:
+        setter:
+          name: registry
+          value: docker.io
+          locked: true
+          setBy: me
+          isSet: true
+`,
+			setBy: "me",
+		},
 		{
 			name:   "set-replicas",
 			setter: "replicas",
@@ -1268,6 +2377,67 @@ openAPI:
  `,
 			err: "hello does not match the possible values for replicas: [foo,baz]",
 		},
+		{
+			name:   "set-replicas-wrong-type",
+			setter: "replicas",
+			value:  "three",
+			err:    `invalid value for setter replicas: replicas in body must be of type integer: "string"`,
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+ `,
+		},
+		{
+			name:   "set-tier-not-in-enum",
+			setter: "tier",
+			value:  "gold",
+			err:    "invalid value for setter tier: tier in body should be one of [bronze silver]",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.tier:
+      type: string
+      enum: [bronze, silver]
+      x-k8s-cli:
+        setter:
+          name: tier
+          value: bronze
+ `,
+		},
+		{
+			name:   "set-tier-in-enum",
+			setter: "tier",
+			value:  "silver",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.tier:
+      type: string
+      enum: [bronze, silver]
+      x-k8s-cli:
+        setter:
+          name: tier
+          value: bronze
+ `,
+			expected: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.tier:
+      type: string
+      enum: [bronze, silver]
+      x-k8s-cli:
+        setter:
+          name: tier
+          value: silver
+          isSet: true
+ `,
+		},
 		{
 			name:   "error",
 			setter: "replicas",
@@ -1331,10 +2501,99 @@ openAPI:
           isSet: true
 `,
 		},
+		{
+			name:    "set-from-env",
+			setter:  "image-tag",
+			fromEnv: "KYAML_TEST_IMAGE_TAG",
+			env:     map[string]string{"KYAML_TEST_IMAGE_TAG": "1.8.1"},
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.7.9"
+ `,
+			expected: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.8.1"
+          isSet: true
+          fromEnv: KYAML_TEST_IMAGE_TAG
+ `,
+		},
+		{
+			name:    "set-from-env-not-set",
+			setter:  "image-tag",
+			fromEnv: "KYAML_TEST_IMAGE_TAG",
+			err:     "environment variable KYAML_TEST_IMAGE_TAG for setter image-tag is not set",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.7.9"
+ `,
+		},
+		{
+			name:    "set-from-env-invalid",
+			setter:  "replicas",
+			fromEnv: "KYAML_TEST_REPLICAS",
+			env:     map[string]string{"KYAML_TEST_REPLICAS": "three"},
+			err: "invalid value for setter replicas: " +
+				"replicas in body must be of type integer: \"string\"",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+ `,
+		},
+		{
+			name:   "set-value-clears-from-env",
+			setter: "image-tag",
+			value:  "1.9.0",
+			input: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.8.1"
+          isSet: true
+          fromEnv: KYAML_TEST_IMAGE_TAG
+ `,
+			expected: `
+openAPI:
+  definitions:
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.9.0"
+          isSet: true
+ `,
+		},
 	}
 	for i := range tests {
 		test := tests[i]
 		t.Run(test.name, func(t *testing.T) {
+			for k, v := range test.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
 			in, err := yaml.Parse(test.input)
 			if !assert.NoError(t, err) {
 				t.FailNow()
@@ -1343,7 +2602,8 @@ openAPI:
 			// invoke the setter
 			instance := &SetOpenAPI{
 				Name: test.setter, Value: test.value, ListValues: test.values,
-				SetBy: test.setBy, Description: test.description}
+				SetBy: test.setBy, Description: test.description, FromEnv: test.fromEnv,
+				Force: test.force}
 			result, err := instance.Filter(in)
 			if test.err != "" {
 				if !assert.EqualError(t, err, test.err) {
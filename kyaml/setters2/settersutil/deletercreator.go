@@ -17,7 +17,7 @@ type DeleterCreator struct {
 }
 
 func (d DeleterCreator) Delete(openAPIPath, resourcesPath string) error {
-	dd := setters2.DeleterDefinition{
+	dd := setters2.DeleteOpenAPI{
 		Name: d.Name,
 	}
 	if err := dd.DeleteFromFile(openAPIPath); err != nil {
@@ -35,6 +35,9 @@ func (d DeleterCreator) Delete(openAPIPath, resourcesPath string) error {
 		Inputs: []kio.Reader{inout},
 		Filters: []kio.Filter{kio.FilterAll(
 			&setters2.Delete{
+				Name: d.Name,
+				// also delete the comments of fields named after the
+				// setter which don't parse, as before
 				FieldName: d.Name,
 			})},
 		Outputs: []kio.Writer{inout},
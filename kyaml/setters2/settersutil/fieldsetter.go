@@ -6,7 +6,10 @@ package settersutil
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/setters2"
@@ -28,11 +31,27 @@ type FieldSetter struct {
 
 	SetBy string
 
+	// FromEnv, if set, is the environment variable to set the value to,
+	// see setters2.SetOpenAPI.
+	FromEnv string
+
+	// Force, if true, sets the setter even if it's locked,
+	// see setters2.SetOpenAPI.
+	Force bool
+
 	Count int
 
 	OpenAPIPath string
 
 	ResourcesPath string
+
+	// OnFormattingChurn, if set, is told about the resource files
+	// which setting would only reformat, see kio.LocalPackageWriter.
+	OnFormattingChurn func(paths []string) error
+
+	// OnDeprecation, if set, is told about each deprecated setter
+	// which sets fields, see setters2.Deprecation.
+	OnDeprecation func(d setters2.Deprecation)
 }
 
 func (fs *FieldSetter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
@@ -42,6 +61,80 @@ func (fs *FieldSetter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 
 // Set updates the OpenAPI definitions and resources with the new setter value
 func (fs FieldSetter) Set(openAPIPath, resourcesPath string) (int, error) {
+	return fs.set(openAPIPath, resourcesPath, "")
+}
+
+// PackageCount is the number of fields set in a package.
+type PackageCount struct {
+	// Path is the path of the package, relative to the root package.
+	Path string
+
+	// Count is the number of fields set in the package.
+	Count int
+}
+
+// SetRecursive sets the setter in the package at resourcesPath, as Set does,
+// and in each of its subpackages -- directories with their own file of OpenAPI
+// definitions, named as openAPIPath is -- which define a setter of the same name.
+// Each package is set from its own definitions, and without the resources of
+// its subpackages.  The number of fields set is returned for each package set,
+// in the order they're found.  If a package fails to be set, the packages before
+// it stay set.
+func (fs FieldSetter) SetRecursive(openAPIPath, resourcesPath string) ([]PackageCount, error) {
+	fileName := filepath.Base(openAPIPath)
+	resourcesPath = filepath.Clean(resourcesPath)
+	var counts []PackageCount
+	err := filepath.Walk(resourcesPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		pkgOpenAPIPath := filepath.Join(path, fileName)
+		if path == resourcesPath {
+			// the root package is always set
+			pkgOpenAPIPath = openAPIPath
+		} else if defined, err := definesSetter(pkgOpenAPIPath, fs.Name); err != nil {
+			return err
+		} else if !defined {
+			return nil
+		}
+		count, err := fs.set(pkgOpenAPIPath, path, fileName)
+		if err != nil {
+			return errors.WrapPrefixf(err, path)
+		}
+		rel, err := filepath.Rel(resourcesPath, path)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		counts = append(counts, PackageCount{Path: rel, Count: count})
+		return nil
+	})
+	return counts, err
+}
+
+// definesSetter returns true if the file at openAPIPath
+// exists, and defines the setter with name.
+func definesSetter(openAPIPath, name string) (bool, error) {
+	if _, err := os.Stat(openAPIPath); os.IsNotExist(err) {
+		return false, nil
+	}
+	y, err := yaml.ReadFile(openAPIPath)
+	if err != nil {
+		return false, err
+	}
+	def, err := y.Pipe(yaml.Lookup(openapi.SupplementaryOpenAPIFieldName,
+		"definitions", fieldmeta.SetterDefinitionPrefix+name))
+	if err != nil {
+		return false, err
+	}
+	return def != nil, nil
+}
+
+// set updates the OpenAPI definitions and resources with the new setter value,
+// skipping the resources of subpackages with a packageFileName, if set
+func (fs FieldSetter) set(openAPIPath, resourcesPath, packageFileName string) (int, error) {
 	// Update the OpenAPI definitions
 	soa := setters2.SetOpenAPI{
 		Name:        fs.Name,
@@ -49,6 +142,8 @@ func (fs FieldSetter) Set(openAPIPath, resourcesPath string) (int, error) {
 		ListValues:  fs.ListValues,
 		Description: fs.Description,
 		SetBy:       fs.SetBy,
+		FromEnv:     fs.FromEnv,
+		Force:       fs.Force,
 	}
 
 	// the input field value is updated in the openAPI file and then parsed
@@ -78,7 +173,12 @@ func (fs FieldSetter) Set(openAPIPath, resourcesPath string) (int, error) {
 	// Update the resources with the new value
 	// Set NoDeleteFiles to true as SetAll will return only the nodes of files which should be updated and
 	// hence, rest of the files should not be deleted
-	inout := &kio.LocalPackageReadWriter{PackagePath: resourcesPath, NoDeleteFiles: true}
+	inout := &kio.LocalPackageReadWriter{
+		PackagePath:       resourcesPath,
+		PackageFileName:   packageFileName,
+		NoDeleteFiles:     true,
+		OnFormattingChurn: fs.OnFormattingChurn,
+	}
 	s := &setters2.Set{Name: fs.Name}
 	err = kio.Pipeline{
 		Inputs:  []kio.Reader{inout},
@@ -91,8 +191,63 @@ func (fs FieldSetter) Set(openAPIPath, resourcesPath string) (int, error) {
 		if writeErr := ioutil.WriteFile(openAPIPath, curOpenAPI, stat.Mode().Perm()); writeErr != nil {
 			return 0, writeErr
 		}
+		return s.Count, err
+	}
+	warnDeprecations(s, fs.OnDeprecation)
+	return s.Count, nil
+}
+
+// warnDeprecations tells onDeprecation about the deprecated
+// setters which s set, if it's set.
+func warnDeprecations(s *setters2.Set, onDeprecation func(d setters2.Deprecation)) {
+	if onDeprecation == nil {
+		return
+	}
+	for _, d := range s.Deprecations {
+		onDeprecation(d)
+	}
+}
+
+// Preview returns the changes Set would make to the values of the fields
+// of the resources, without writing the OpenAPI definitions or the resources.
+func (fs FieldSetter) Preview(openAPIPath, resourcesPath string) ([]setters2.Change, error) {
+	soa := setters2.SetOpenAPI{
+		Name:       fs.Name,
+		Value:      fs.Value,
+		ListValues: fs.ListValues,
+		SetBy:      fs.SetBy,
+		FromEnv:    fs.FromEnv,
+		Force:      fs.Force,
+	}
+
+	// update the definitions in memory only
+	y, err := yaml.ReadFile(openAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := y.PipeE(soa); err != nil {
+		return nil, err
+	}
+	s, err := y.String()
+	if err != nil {
+		return nil, err
+	}
+	if err := openapi.AddSchemaFromBytesUsingField(
+		[]byte(s), openapi.SupplementaryOpenAPIFieldName); err != nil {
+		return nil, err
+	}
+
+	// find the changes without writing the resources
+	set := &setters2.Set{Name: fs.Name, DryRun: true}
+	err = kio.Pipeline{
+		Inputs:  []kio.Reader{&kio.LocalPackageReader{PackagePath: resourcesPath}},
+		Filters: []kio.Filter{setters2.SetAll(set)},
+	}.Execute()
+	if err != nil {
+		return nil, err
 	}
-	return s.Count, err
+	warnDeprecations(set, fs.OnDeprecation)
+	return set.Changes, nil
 }
 
 // SetAllSetterDefinitions reads all the Setter Definitions from the OpenAPI
@@ -6,10 +6,13 @@ package settersutil
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/setters2"
 )
 
 func TestSetAllSetterDefinitions(t *testing.T) {
@@ -104,3 +107,187 @@ spec:
 		t.FailNow()
 	}
 }
+
+func TestFieldSetter_Set_invalidValue(t *testing.T) {
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+`
+	resourceFile := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 4 # {"$ref": "#/definitions/io.k8s.cli.setters.replicas"}
+`
+	dir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	openAPIPath := filepath.Join(dir, "Krmfile")
+	resourcePath := filepath.Join(dir, "deployment.yaml")
+	if !assert.NoError(t, ioutil.WriteFile(openAPIPath, []byte(openAPIFile), 0600)) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, ioutil.WriteFile(resourcePath, []byte(resourceFile), 0600)) {
+		t.FailNow()
+	}
+
+	fs := FieldSetter{Name: "replicas", Value: "four"}
+	_, err = fs.Set(openAPIPath, dir)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `invalid value for setter replicas: `+
+		`replicas in body must be of type integer: "string"`, err.Error())
+
+	for path, expected := range map[string]string{
+		openAPIPath: openAPIFile, resourcePath: resourceFile} {
+		b, err := ioutil.ReadFile(path)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, expected, string(b))
+	}
+}
+
+func TestFieldSetter_Preview(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+`
+	resourceFile := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 4 # {"$ref": "#/definitions/io.k8s.cli.setters.replicas"}
+`
+	dir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	openAPIPath := filepath.Join(dir, "Krmfile")
+	resourcePath := filepath.Join(dir, "deployment.yaml")
+	if !assert.NoError(t, ioutil.WriteFile(openAPIPath, []byte(openAPIFile), 0600)) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, ioutil.WriteFile(resourcePath, []byte(resourceFile), 0600)) {
+		t.FailNow()
+	}
+
+	fs := FieldSetter{Name: "replicas", Value: "5"}
+	changes, err := fs.Preview(openAPIPath, dir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []setters2.Change{{
+		File:     "deployment.yaml",
+		Resource: "Deployment/nginx-deployment",
+		Field:    "spec.replicas",
+		OldValue: "4",
+		NewValue: "5",
+	}}, changes)
+
+	// nothing is written
+	for path, expected := range map[string]string{
+		openAPIPath: openAPIFile, resourcePath: resourceFile} {
+		b, err := ioutil.ReadFile(path)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, expected, string(b))
+	}
+}
+
+func TestFieldSetter_SetRecursive(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "4"
+`
+	resourceFile := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 4 # {"$openapi":"replicas"}
+`
+	dir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"Krmfile":                  openAPIFile,
+		"deployment.yaml":          resourceFile,
+		"app/Krmfile":              openAPIFile,
+		"app/deployment.yaml":      resourceFile,
+		"app/db/Krmfile":           openAPIFile,
+		"app/db/deployment.yaml":   resourceFile,
+		"other/Krmfile":            `openAPI: {}`,
+		"other/deployment.yaml":    resourceFile,
+		"manifests/deployment.yml": resourceFile,
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700)) {
+			t.FailNow()
+		}
+		if !assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600)) {
+			t.FailNow()
+		}
+	}
+
+	fs := FieldSetter{Name: "replicas", Value: "5"}
+	counts, err := fs.SetRecursive(filepath.Join(dir, "Krmfile"), dir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []PackageCount{
+		{Path: ".", Count: 2},
+		{Path: "app", Count: 1},
+		{Path: filepath.Join("app", "db"), Count: 1},
+	}, counts)
+
+	for path, set := range map[string]bool{
+		"Krmfile":                  true,
+		"deployment.yaml":          true,
+		"app/Krmfile":              true,
+		"app/deployment.yaml":      true,
+		"app/db/Krmfile":           true,
+		"app/db/deployment.yaml":   true,
+		"other/deployment.yaml":    false,
+		"manifests/deployment.yml": true,
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		expected := files[path]
+		if set {
+			expected = strings.Replace(expected, "4", "5", 1)
+		}
+		if set && filepath.Base(path) == "Krmfile" {
+			expected += "          isSet: true\n"
+		}
+		assert.Equal(t, expected, string(b), path)
+	}
+}
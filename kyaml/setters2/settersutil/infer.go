@@ -0,0 +1,114 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/setters2"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// InferredValue is the value of a setter inferred from the
+// fields which reference it.
+type InferredValue struct {
+	// Name is the name of the setter.
+	Name string
+
+	// OldValues are the values of the setter before it was inferred.
+	// List setters have a value for each element.
+	OldValues []string
+
+	// Values are the values inferred for the setter.
+	Values []string
+}
+
+// SetterInferrer updates the values of the setters in the OpenAPI definitions
+// to the values of the fields which reference them, see setters2.Infer, so
+// that a package whose fields were customized may adopt setters.  The
+// resources aren't changed.
+type SetterInferrer struct {
+	// SetBy is recorded as who set the setters.
+	SetBy string
+
+	// DryRun, if true, returns the values which would be inferred,
+	// without updating the OpenAPI definitions.
+	DryRun bool
+}
+
+// Infer updates the OpenAPI definitions with the values of the setters inferred
+// from the resources, and returns the values of the setters which changed.
+// If any value is invalid, nothing is updated.
+func (si SetterInferrer) Infer(openAPIPath, resourcesPath string) ([]InferredValue, error) {
+	l := setters2.List{}
+	if err := l.ListSetters(openAPIPath, resourcesPath); err != nil {
+		return nil, err
+	}
+
+	// infer the values from the resources, which ListSetters
+	// has loaded the definitions for
+	i := &setters2.Infer{}
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{&kio.LocalPackageReader{PackagePath: resourcesPath}},
+		Filters: []kio.Filter{kio.FilterAll(i)},
+	}.Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var inferred []InferredValue
+	for _, name := range i.Names() {
+		values := i.Values[name]
+		var oldValues []string
+		for _, s := range l.Setters {
+			if s.Name != name {
+				continue
+			}
+			oldValues = append([]string{s.Value}, s.ListValues...)
+			if s.Value == "" {
+				oldValues = s.ListValues
+			}
+		}
+		if equalValues(oldValues, values) {
+			continue
+		}
+		inferred = append(inferred, InferredValue{Name: name, OldValues: oldValues, Values: values})
+	}
+	if si.DryRun || len(inferred) == 0 {
+		return inferred, nil
+	}
+
+	// update all the values before writing the OpenAPI file,
+	// so that it's updated for all of them, or none
+	err = yaml.UpdateFile(yaml.FilterFunc(func(object *yaml.RNode) (*yaml.RNode, error) {
+		for _, v := range inferred {
+			soa := setters2.SetOpenAPI{
+				Name:       v.Name,
+				Value:      v.Values[0],
+				ListValues: v.Values[1:],
+				SetBy:      si.SetBy,
+			}
+			if _, err := soa.Filter(object); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}), openAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	return inferred, nil
+}
+
+// equalValues returns true if a and b have the same values, in the same order.
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
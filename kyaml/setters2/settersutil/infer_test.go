@@ -0,0 +1,146 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+func TestSetterInferrer_Infer(t *testing.T) {
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "1"
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: 1.8.1
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: nginx:${image-tag}
+          values:
+          - marker: ${image-tag}
+            ref: '#/definitions/io.k8s.cli.setters.image-tag'
+`
+	resources := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 3 # {"$openapi":"replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1 # {"$openapi":"image"}
+`
+	testCases := []struct {
+		name      string
+		dryRun    bool
+		resources string
+		expected  []InferredValue
+		openAPI   string
+		err       string
+	}{
+		{
+			name:      "infer",
+			resources: resources,
+			expected: []InferredValue{
+				{Name: "replicas", OldValues: []string{"1"}, Values: []string{"3"}},
+			},
+			openAPI: `openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+          isSet: true
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: 1.8.1
+    io.k8s.cli.substitutions.image:
+      x-k8s-cli:
+        substitution:
+          name: image
+          pattern: nginx:${image-tag}
+          values:
+          - marker: ${image-tag}
+            ref: '#/definitions/io.k8s.cli.setters.image-tag'
+`,
+		},
+		{
+			name:      "dry-run",
+			dryRun:    true,
+			resources: resources,
+			expected: []InferredValue{
+				{Name: "replicas", OldValues: []string{"1"}, Values: []string{"3"}},
+			},
+			openAPI: openAPIFile,
+		},
+		{
+			name: "invalid",
+			resources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: three # {"$openapi":"replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.9 # {"$openapi":"image"}
+`,
+			err:     "invalid value for setter replicas",
+			openAPI: openAPIFile,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer openapi.ResetOpenAPI()
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			openAPIPath := filepath.Join(dir, "Krmfile")
+			require.NoError(t, ioutil.WriteFile(openAPIPath, []byte(openAPIFile), 0600))
+			resourcesPath := filepath.Join(dir, "deployment.yaml")
+			require.NoError(t, ioutil.WriteFile(resourcesPath, []byte(tc.resources), 0600))
+
+			inferred, err := SetterInferrer{DryRun: tc.dryRun}.Infer(openAPIPath, dir)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, inferred)
+			}
+
+			// the resources are never changed
+			b, err := ioutil.ReadFile(resourcesPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.resources, string(b))
+			b, err = ioutil.ReadFile(openAPIPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.openAPI, string(b))
+		})
+	}
+}
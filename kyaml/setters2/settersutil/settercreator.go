@@ -4,7 +4,9 @@
 package settersutil
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/go-openapi/spec"
@@ -47,6 +49,25 @@ type SetterCreator struct {
 	// the package publisher's intent to make the setter required to be set.
 	Required bool
 
+	// RecordHistory, if true, has the values the setter is set to recorded
+	// in its definition, see setters2.SetterDefinition.
+	RecordHistory bool
+
+	// Locked, if true, pins the value of the setter, so it can only be
+	// changed by forcing it, see setters2.SetterDefinition.
+	Locked bool
+
+	// Minimum and Maximum, if set, are the least and greatest values the
+	// setter may be set to, e.g. so replicas can't be set to 0.
+	Minimum, Maximum *float64
+
+	// MaxLength, if set, is the most characters a value of the setter may have.
+	MaxLength *int64
+
+	// Pattern, if set, is a regular expression which values of the setter
+	// must match, e.g. ^[a-z0-9]([-a-z0-9]*[a-z0-9])?$ for DNS-1123 labels.
+	Pattern string
+
 	// Path to openAPI file
 	OpenAPIPath string
 
@@ -59,7 +80,12 @@ func (c *SetterCreator) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
 }
 
 func (c SetterCreator) Create(openAPIPath, resourcesPath string) error {
-	err := validateSchema(c.Schema)
+	schema, err := c.constrainSchema()
+	if err != nil {
+		return err
+	}
+	c.Schema = schema
+	err = validateSchema(c.Schema)
 	if err != nil {
 		return errors.Errorf("invalid schema: %v", err)
 	}
@@ -88,6 +114,7 @@ func (c SetterCreator) Create(openAPIPath, resourcesPath string) error {
 	sd := setters2.SetterDefinition{
 		Name: c.Name, Value: c.FieldValue, SetBy: c.SetBy, Description: c.Description,
 		Type: c.Type, Schema: c.Schema, Required: c.Required,
+		RecordHistory: c.RecordHistory, Locked: c.Locked,
 	}
 	if err := sd.AddToFile(openAPIPath); err != nil {
 		return err
@@ -110,6 +137,66 @@ func (c SetterCreator) Create(openAPIPath, resourcesPath string) error {
 	return nil
 }
 
+// constrainSchema returns the schema of the setter with its constraints
+// added, which Set validates the values of the setter against.  A
+// constraint which the schema already has with a different value is
+// an error.
+func (c SetterCreator) constrainSchema() (string, error) {
+	if c.Minimum == nil && c.Maximum == nil && c.MaxLength == nil && c.Pattern == "" {
+		return c.Schema, nil
+	}
+	var sc spec.Schema
+	if c.Schema != "" {
+		if err := sc.UnmarshalJSON([]byte(c.Schema)); err != nil {
+			return "", errors.Errorf("unable to parse schema: %v", err)
+		}
+	}
+
+	if c.Minimum != nil {
+		if sc.Minimum != nil && *sc.Minimum != *c.Minimum {
+			return "", errors.Errorf("minimum %v doesn't match the minimum of the schema (%v)",
+				*c.Minimum, *sc.Minimum)
+		}
+		sc.Minimum = c.Minimum
+	}
+	if c.Maximum != nil {
+		if sc.Maximum != nil && *sc.Maximum != *c.Maximum {
+			return "", errors.Errorf("maximum %v doesn't match the maximum of the schema (%v)",
+				*c.Maximum, *sc.Maximum)
+		}
+		sc.Maximum = c.Maximum
+	}
+	if sc.Minimum != nil && sc.Maximum != nil && *sc.Minimum > *sc.Maximum {
+		return "", errors.Errorf("minimum %v is greater than maximum %v", *sc.Minimum, *sc.Maximum)
+	}
+	if c.MaxLength != nil {
+		if sc.MaxLength != nil && *sc.MaxLength != *c.MaxLength {
+			return "", errors.Errorf("maxLength %d doesn't match the maxLength of the schema (%d)",
+				*c.MaxLength, *sc.MaxLength)
+		}
+		if *c.MaxLength < 0 {
+			return "", errors.Errorf("maxLength %d must not be negative", *c.MaxLength)
+		}
+		sc.MaxLength = c.MaxLength
+	}
+	if c.Pattern != "" {
+		if sc.Pattern != "" && sc.Pattern != c.Pattern {
+			return "", errors.Errorf("pattern %s doesn't match the pattern of the schema (%s)",
+				c.Pattern, sc.Pattern)
+		}
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return "", errors.Errorf("invalid pattern %s: %v", c.Pattern, err)
+		}
+		sc.Pattern = c.Pattern
+	}
+
+	b, err := json.Marshal(sc.SchemaProps)
+	if err != nil {
+		return "", errors.Errorf("error marshalling schema: %v", err)
+	}
+	return string(b), nil
+}
+
 // The types recognized by by the go openapi validation library:
 // https://github.com/go-openapi/validate/blob/master/helpers.go#L35
 var validTypeValues = []string{
@@ -0,0 +1,129 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+func TestSetterCreator_Create_constraints(t *testing.T) {
+	one, ten := float64(1), float64(10)
+	maxLength := int64(15)
+	resourceFile := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 3
+  template:
+    spec:
+      serviceAccountName: nginx
+`
+	krmfile := `apiVersion: v1alpha1
+kind: Krmfile
+`
+	testCases := []struct {
+		name    string
+		creator SetterCreator
+		value   string
+		openAPI string
+		err     string
+	}{
+		{
+			name: "minimum-maximum",
+			creator: SetterCreator{Name: "replicas", FieldName: "replicas", FieldValue: "3",
+				Type: "integer", Minimum: &one, Maximum: &ten},
+			value: "0",
+			openAPI: `apiVersion: v1alpha1
+kind: Krmfile
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      maximum: 10
+      minimum: 1
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+`,
+			err: "invalid value for setter replicas: replicas in body should be greater than or equal to 1",
+		},
+		{
+			name: "pattern-max-length",
+			creator: SetterCreator{Name: "service-account", FieldName: "serviceAccountName",
+				FieldValue: "nginx", Type: "string", MaxLength: &maxLength,
+				Pattern: "^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"},
+			value: "Nginx_SA",
+			openAPI: `apiVersion: v1alpha1
+kind: Krmfile
+openAPI:
+  definitions:
+    io.k8s.cli.setters.service-account:
+      maxLength: 15
+      pattern: ^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+      type: string
+      x-k8s-cli:
+        setter:
+          name: service-account
+          value: nginx
+`,
+			err: "invalid value for setter service-account: " +
+				"service-account in body should match '^[a-z0-9]([-a-z0-9]*[a-z0-9])?$'",
+		},
+		{
+			name: "schema-conflict",
+			creator: SetterCreator{Name: "replicas", FieldName: "replicas", FieldValue: "3",
+				Schema: `{"minimum": 2}`, Minimum: &one},
+			err: "minimum 1 doesn't match the minimum of the schema (2)",
+		},
+		{
+			name: "minimum-greater-than-maximum",
+			creator: SetterCreator{Name: "replicas", FieldName: "replicas", FieldValue: "3",
+				Minimum: &ten, Maximum: &one},
+			err: "minimum 10 is greater than maximum 1",
+		},
+		{
+			name: "invalid-pattern",
+			creator: SetterCreator{Name: "service-account", FieldName: "serviceAccountName",
+				FieldValue: "nginx", Pattern: "^[a-z"},
+			err: "invalid pattern ^[a-z: error parsing regexp: missing closing ]: `[a-z`",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer openapi.ResetOpenAPI()
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			openAPIPath := filepath.Join(dir, "Krmfile")
+			require.NoError(t, ioutil.WriteFile(openAPIPath, []byte(krmfile), 0600))
+			resourcePath := filepath.Join(dir, "deployment.yaml")
+			require.NoError(t, ioutil.WriteFile(resourcePath, []byte(resourceFile), 0600))
+
+			err = tc.creator.Create(openAPIPath, dir)
+			if tc.err != "" && tc.openAPI == "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			b, err := ioutil.ReadFile(openAPIPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.openAPI, string(b))
+
+			// values violating the constraints can't be set
+			_, err = FieldSetter{Name: tc.creator.Name, Value: tc.value}.Set(openAPIPath, dir)
+			require.Error(t, err)
+			assert.Equal(t, tc.err, err.Error())
+		})
+	}
+}
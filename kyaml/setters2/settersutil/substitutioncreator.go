@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/go-openapi/spec"
@@ -29,9 +30,18 @@ type SubstitutionCreator struct {
 	// Pattern is the substitution pattern
 	Pattern string
 
+	// Regex, if set rather than Pattern, is a regular expression for the field
+	// values whose named capture groups are replaced by the setters or
+	// substitutions of their names, see setters2.SubstitutionDefinition.
+	Regex string
+
 	// Values are the substitution values for the pattern
 	Values []setters2.Value
 
+	// Defaults are the values which replace the markers of setters whose
+	// values are empty, keyed by the names of the setters.
+	Defaults map[string]string
+
 	// FieldName if set will add the OpenAPI reference to fields with this name or path
 	// FieldName may be the full name of the field, full path to the field, or the path suffix.
 	// e.g. all of the following would match spec.template.spec.containers.image --
@@ -56,15 +66,25 @@ func (c *SubstitutionCreator) Filter(input []*yaml.RNode) ([]*yaml.RNode, error)
 }
 
 func (c SubstitutionCreator) Create(openAPIPath, resourcesPath string) error {
-	values, err := markersAndRefs(c.Name, c.Pattern)
+	var values []setters2.Value
+	var err error
+	if c.Regex != "" {
+		values, err = regexMarkersAndRefs(c.Name, c.Regex)
+	} else {
+		values, err = markersAndRefs(c.Name, c.Pattern)
+	}
 	if err != nil {
 		return err
 	}
+	if err := setDefaults(values, c.Defaults); err != nil {
+		return err
+	}
 	c.Values = values
 	d := setters2.SubstitutionDefinition{
 		Name:    c.Name,
 		Values:  c.Values,
 		Pattern: c.Pattern,
+		Regex:   c.Regex,
 	}
 
 	// the input substitution definition is updated in the openAPI file and then parsed
@@ -123,16 +143,21 @@ func (c SubstitutionCreator) Create(openAPIPath, resourcesPath string) error {
 		return err
 	}
 
-	// Update the resources with the setter reference
+	// Update the resources with the setter reference, on all the
+	// fields matching the regex of a regex substitution
+	add := &setters2.Add{
+		FieldName:  c.FieldName,
+		FieldValue: c.FieldValue,
+		Ref:        fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix + c.Name,
+	}
+	if c.Regex != "" {
+		add.FieldValue = ""
+		add.FieldValueRegex = c.Regex
+	}
 	inout := &kio.LocalPackageReadWriter{PackagePath: resourcesPath}
 	return kio.Pipeline{
-		Inputs: []kio.Reader{inout},
-		Filters: []kio.Filter{kio.FilterAll(
-			&setters2.Add{
-				FieldName:  c.FieldName,
-				FieldValue: c.FieldValue,
-				Ref:        fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix + c.Name,
-			})},
+		Inputs:  []kio.Reader{inout},
+		Filters: []kio.Filter{kio.FilterAll(add)},
 		Outputs: []kio.Writer{inout},
 	}.Execute()
 }
@@ -155,21 +180,11 @@ func markersAndRefs(substName, pattern string) ([]setters2.Value, error) {
 			return nil, fmt.Errorf("setters must have different name than the substitution: %s", name)
 		}
 
-		ref, err := spec.NewRef(fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix + name)
+		markerRef, err := refForMarker(name)
 		if err != nil {
 			return nil, err
 		}
 
-		var markerRef string
-		subst, _ := openapi.Resolve(&ref)
-		// check if the substitution exists with the marker name or fall back to creating setter
-		// ref with the name
-		if subst != nil {
-			markerRef = fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix + name
-		} else {
-			markerRef = fieldmeta.DefinitionsPrefix + fieldmeta.SetterDefinitionPrefix + name
-		}
-
 		values = append(
 			values,
 			setters2.Value{Marker: marker, Ref: markerRef},
@@ -178,6 +193,77 @@ func markersAndRefs(substName, pattern string) ([]setters2.Value, error) {
 	return values, nil
 }
 
+// regexMarkersAndRefs takes the input regex, and creates a marker and
+// corresponding openAPI ref for each of its named capture groups
+func regexMarkersAndRefs(substName, regex string) ([]setters2.Value, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var values []setters2.Value
+	seen := sets.String{}
+	for _, name := range re.SubexpNames()[1:] {
+		if name == "" || seen.Has(name) {
+			continue
+		}
+		seen.Insert(name)
+		if name == substName {
+			return nil, fmt.Errorf("setters must have different name than the substitution: %s", name)
+		}
+		markerRef, err := refForMarker(name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, setters2.Value{Marker: name, Ref: markerRef})
+	}
+	if len(values) == 0 {
+		return nil, errors.Errorf("unable to find setter or substitution names in regex, " +
+			"setter names must be the names of capture groups, e.g. (?P<tag>[^:]+)$")
+	}
+	return values, nil
+}
+
+// setDefaults sets the default of each of the values whose marker
+// is of a setter or substitution with a name in defaults.
+func setDefaults(values []setters2.Value, defaults map[string]string) error {
+	found := sets.String{}
+	for i := range values {
+		name := strings.TrimSuffix(strings.TrimPrefix(values[i].Marker, "${"), "}")
+		if d, ok := defaults[name]; ok {
+			values[i].Default = d
+			found.Insert(name)
+		}
+	}
+	var names []string
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !found.Has(name) {
+			return errors.Errorf("default for %s, which isn't a setter of the substitution", name)
+		}
+	}
+	return nil
+}
+
+// refForMarker returns the openAPI ref for the marker of the setter
+// or substitution name
+func refForMarker(name string) (string, error) {
+	ref, err := spec.NewRef(fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix + name)
+	if err != nil {
+		return "", err
+	}
+
+	subst, _ := openapi.Resolve(&ref)
+	// check if the substitution exists with the marker name or fall back to creating setter
+	// ref with the name
+	if subst != nil {
+		return fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix + name, nil
+	}
+	return fieldmeta.DefinitionsPrefix + fieldmeta.SetterDefinitionPrefix + name, nil
+}
+
 // CreateSettersForSubstitution creates the setters for all the references in the substitution
 // values if they don't already exist in openAPIPath file.
 func (c SubstitutionCreator) CreateSettersForSubstitution(openAPIPath string) error {
@@ -273,6 +359,9 @@ func checkForCycles(ext *setters2.CliExtension, visited sets.String) error {
 // GetValuesForMarkers parses the pattern and field value to derive values for the
 // markers in the pattern string. Returns error if the marker values can't be derived
 func (c SubstitutionCreator) GetValuesForMarkers() (map[string]string, error) {
+	if c.Regex != "" {
+		return c.getValuesForGroups()
+	}
 	m := make(map[string]string)
 	indices, err := c.GetStartIndices()
 	if err != nil {
@@ -325,6 +414,26 @@ func (c SubstitutionCreator) GetValuesForMarkers() (map[string]string, error) {
 	return m, nil
 }
 
+// getValuesForGroups matches the regex against the field value
+// to derive the values of the markers of its capture groups
+func (c SubstitutionCreator) getValuesForGroups() (map[string]string, error) {
+	re, err := regexp.Compile(c.Regex)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	match := re.FindStringSubmatch(c.FieldValue)
+	if match == nil {
+		return nil, errors.Errorf("field value %s doesn't match regex %s", c.FieldValue, c.Regex)
+	}
+	m := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if _, found := m[name]; i > 0 && name != "" && !found {
+			m[name] = match[i]
+		}
+	}
+	return m, nil
+}
+
 // GetStartIndices returns the start indices of all the markers in the pattern
 func (c SubstitutionCreator) GetStartIndices() (map[int]string, error) {
 	indices := make(map[int]string)
@@ -15,6 +15,7 @@ func TestGetValuesForMarkers(t *testing.T) {
 	var tests = []struct {
 		name           string
 		pattern        string
+		regex          string
 		fieldValue     string
 		markers        []string
 		expectedError  error
@@ -69,6 +70,20 @@ func TestGetValuesForMarkers(t *testing.T) {
 			fieldValue:    "something/nginx::0.1.0/otherthing/nginx::0.1.0/",
 			expectedError: errors.Errorf("unable to derive values for markers"),
 		},
+		{
+			name:           "regex",
+			markers:        []string{"image", "tag"},
+			regex:          `^(?:.*/)?(?P<image>[^/:]+):(?P<tag>[^@]+)$`,
+			fieldValue:     "gcr.io/example/nginx:0.1.0",
+			expectedOutput: map[string]string{"image": "nginx", "tag": "0.1.0"},
+		},
+		{
+			name:          "unmatched regex",
+			markers:       []string{"tag"},
+			regex:         `^[^:]+:(?P<tag>[^@]+)$`,
+			fieldValue:    "nginx",
+			expectedError: errors.Errorf("field value nginx doesn't match regex ^[^:]+:(?P<tag>[^@]+)$"),
+		},
 	}
 	for i := range tests {
 		test := tests[i]
@@ -83,6 +98,7 @@ func TestGetValuesForMarkers(t *testing.T) {
 
 			sc := SubstitutionCreator{
 				Pattern:    test.pattern,
+				Regex:      test.regex,
 				Values:     values,
 				FieldValue: test.fieldValue,
 			}
@@ -109,3 +125,23 @@ func TestGetValuesForMarkers(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDefaults(t *testing.T) {
+	values := []setters2.Value{
+		{Marker: "${image-name}", Ref: "#/definitions/io.k8s.cli.setters.image-name"},
+		{Marker: "${image-tag}", Ref: "#/definitions/io.k8s.cli.setters.image-tag"},
+	}
+	err := setDefaults(values, map[string]string{"image-tag": "latest"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []setters2.Value{
+		{Marker: "${image-name}", Ref: "#/definitions/io.k8s.cli.setters.image-name"},
+		{Marker: "${image-tag}", Ref: "#/definitions/io.k8s.cli.setters.image-tag", Default: "latest"},
+	}, values)
+
+	err = setDefaults(values, map[string]string{"tag": "latest"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "default for tag, which isn't a setter of the substitution", err.Error())
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/sets"
+	"sigs.k8s.io/kustomize/kyaml/setters2"
+)
+
+// SuggestSetters suggests setters for the commonly parameterized fields of the
+// resources, see setters2.Suggest.  If create is true, the setters and their
+// substitutions are defined in the OpenAPI file, and the fields reference them.
+func SuggestSetters(openAPIPath, resourcesPath string, create bool) ([]*setters2.Suggestion, error) {
+	l := setters2.List{}
+	if err := l.ListSetters(openAPIPath, resourcesPath); err != nil {
+		return nil, err
+	}
+	if err := l.ListSubst(openAPIPath); err != nil {
+		return nil, err
+	}
+	existing := sets.String{}
+	for _, s := range l.Setters {
+		existing.Insert(s.Name)
+	}
+	for _, s := range l.Substitutions {
+		existing.Insert(s.Name)
+	}
+
+	// Set NoDeleteFiles to true as Suggest will return only the nodes of files
+	// which should be updated, and hence, the rest of the files should not be deleted
+	inout := &kio.LocalPackageReadWriter{PackagePath: resourcesPath, NoDeleteFiles: true}
+	s := &setters2.Suggest{Existing: existing, Create: create}
+	p := kio.Pipeline{
+		Inputs:  []kio.Reader{inout},
+		Filters: []kio.Filter{s},
+	}
+	if create {
+		p.Outputs = []kio.Writer{inout}
+	}
+	if err := p.Execute(); err != nil {
+		return nil, err
+	}
+	if !create {
+		return s.Suggestions, nil
+	}
+
+	// Update the OpenAPI definitions to have the setters
+	for _, sg := range s.Suggestions {
+		sd := setters2.SetterDefinition{Name: sg.Name, Value: sg.Value, Type: sg.Type}
+		if err := sd.AddToFile(openAPIPath); err != nil {
+			return nil, err
+		}
+		if sg.Substitution == nil {
+			continue
+		}
+		if err := sg.Substitution.AddToFile(openAPIPath); err != nil {
+			return nil, err
+		}
+	}
+	return s.Suggestions, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+func TestSuggestSetters(t *testing.T) {
+	openapi.ResetOpenAPI()
+	defer openapi.ResetOpenAPI()
+	dir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	openAPIPath := filepath.Join(dir, "Krmfile")
+	err = ioutil.WriteFile(openAPIPath, []byte(`apiVersion: v1alpha1
+kind: Krmfile
+openAPI:
+  definitions:
+    io.k8s.cli.setters.namespace:
+      x-k8s-cli:
+        setter:
+          name: namespace
+          value: prod
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	resourcePath := filepath.Join(dir, "deployment.yaml")
+	err = ioutil.WriteFile(resourcePath, []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// suggesting changes nothing
+	suggestions, err := SuggestSetters(openAPIPath, dir, false)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"prod-namespace", "web-replicas", "nginx-tag"}, names)
+	actual, err := ioutil.ReadFile(resourcePath)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NotContains(t, string(actual), "$openapi")
+
+	// creating references and defines them
+	_, err = SuggestSetters(openAPIPath, dir, true)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	actual, err = ioutil.ReadFile(openAPIPath)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: v1alpha1
+kind: Krmfile
+openAPI:
+  definitions:
+    io.k8s.cli.setters.namespace:
+      x-k8s-cli:
+        setter:
+          name: namespace
+          value: prod
+    io.k8s.cli.setters.prod-namespace:
+      x-k8s-cli:
+        setter:
+          name: prod-namespace
+          value: prod
+    io.k8s.cli.setters.web-replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: web-replicas
+          value: "3"
+    io.k8s.cli.setters.nginx-tag:
+      x-k8s-cli:
+        setter:
+          name: nginx-tag
+          value: 1.7.9
+    io.k8s.cli.substitutions.nginx-image:
+      x-k8s-cli:
+        substitution:
+          name: nginx-image
+          pattern: nginx:${nginx-tag}
+          values:
+          - marker: ${nginx-tag}
+            ref: '#/definitions/io.k8s.cli.setters.nginx-tag'
+`, string(actual))
+
+	// the setters may be set
+	fs := FieldSetter{Name: "nginx-tag", Value: "1.8.1"}
+	if _, err := fs.Set(openAPIPath, dir); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	actual, err = ioutil.ReadFile(resourcePath)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod # {"$openapi":"prod-namespace"}
+spec:
+  replicas: 3 # {"$openapi":"web-replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1 # {"$openapi":"nginx-image"}
+`), strings.TrimSpace(string(actual)))
+}
@@ -0,0 +1,179 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/setters2"
+)
+
+// TerraformOutput is an output of a terraform configuration.
+type TerraformOutput struct {
+	// Value is the value of the output: a string,
+	// json.Number, bool, or a list or map of those.
+	Value interface{} `json:"value"`
+
+	// Sensitive is true if terraform hides the value.
+	Sensitive bool `json:"sensitive"`
+}
+
+// ReadTerraformOutputs reads the outputs of a terraform configuration
+// from a file written by `terraform output -json`, or from a state
+// file, keyed by their names.
+func ReadTerraformOutputs(path string) (map[string]TerraformOutput, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var content map[string]json.RawMessage
+	if err := d.Decode(&content); err != nil {
+		return nil, errors.WrapPrefixf(err, "reading terraform outputs %s", path)
+	}
+	if _, isState := content["terraform_version"]; isState {
+		if content["outputs"] == nil {
+			return map[string]TerraformOutput{}, nil
+		}
+		b = content["outputs"]
+	}
+	var outputs map[string]TerraformOutput
+	d = json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&outputs); err != nil {
+		return nil, errors.WrapPrefixf(err, "reading terraform outputs %s", path)
+	}
+	return outputs, nil
+}
+
+// TerraformSetter sets setters to the values of the outputs
+// of a terraform configuration, so infrastructure values,
+// e.g. the IDs of networks, flow into the resources.
+type TerraformSetter struct {
+	// Outputs are the terraform outputs, keyed by name.
+	Outputs map[string]TerraformOutput
+
+	// Mappings are the names of the outputs to set setters
+	// to, keyed by the names of the setters.  Other setters are
+	// set to the output of the same name, with '-' read as '_'.
+	Mappings map[string]string
+
+	// SetBy is recorded as who set the setters.
+	SetBy string
+
+	// Force, if true, sets locked setters, see setters2.SetOpenAPI.
+	Force bool
+}
+
+// Set sets each setter defined in the OpenAPI file which has
+// a matching output, and returns the number of fields set for
+// each setter set.  Sensitive outputs are only used for the
+// setters mapped to them.
+func (ts TerraformSetter) Set(openAPIPath, resourcesPath string) (map[string]int, error) {
+	l := setters2.List{}
+	if err := l.ListSetters(openAPIPath, resourcesPath); err != nil {
+		return nil, err
+	}
+	defined := map[string]bool{}
+	for _, s := range l.Setters {
+		defined[s.Name] = true
+	}
+	for name := range ts.Mappings {
+		if !defined[name] {
+			return nil, errors.Errorf("no setter %s found", name)
+		}
+	}
+
+	counts := map[string]int{}
+	for _, s := range l.Setters {
+		output, mapped := ts.Mappings[s.Name]
+		if !mapped {
+			output = s.Name
+			if _, found := ts.Outputs[output]; !found {
+				output = strings.Replace(s.Name, "-", "_", -1)
+			}
+		}
+		o, found := ts.Outputs[output]
+		switch {
+		case !found && mapped:
+			return nil, errors.Errorf(
+				"no terraform output %s found for setter %s", output, s.Name)
+		case !found:
+			continue
+		case o.Sensitive && !mapped:
+			// sensitive values are only written to
+			// the resources if explicitly asked for
+			continue
+		}
+		values, err := terraformValues(o.Value)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "terraform output %s", output)
+		}
+		if len(values) > 1 && len(s.ListValues) == 0 {
+			return nil, errors.Errorf(
+				"terraform output %s is a list, but setter %s isn't", output, s.Name)
+		}
+		fs := FieldSetter{
+			Name:        s.Name,
+			Value:       values[0],
+			ListValues:  values[1:],
+			Description: fmt.Sprintf("terraform output %s", output),
+			SetBy:       ts.SetBy,
+			Force:       ts.Force,
+		}
+		count, err := fs.Set(openAPIPath, resourcesPath)
+		if err != nil {
+			return nil, err
+		}
+		counts[s.Name] = count
+	}
+	return counts, nil
+}
+
+// terraformValues returns the value of an output as
+// setter values: one for a scalar, or one for each
+// element of a list.
+func terraformValues(v interface{}) ([]string, error) {
+	list, isList := v.([]interface{})
+	if !isList {
+		s, err := terraformScalar(v)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+	if len(list) == 0 {
+		return nil, errors.Errorf("setters can't be set to empty lists")
+	}
+	var values []string
+	for _, e := range list {
+		s, err := terraformScalar(e)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+func terraformScalar(v interface{}) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case json.Number:
+		return x.String(), nil
+	case bool:
+		return fmt.Sprint(x), nil
+	case nil:
+		return "", errors.Errorf("setters can't be set to null")
+	default:
+		return "", errors.Errorf("setters can't be set to maps or nested lists")
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTerraformOutputs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "output",
+			content: `{
+  "vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"},
+  "db_password": {"sensitive": true, "type": "string", "value": "hunter2"},
+  "subnets": {"sensitive": false, "type": ["list", "string"], "value": ["a", "b"]},
+  "port": {"sensitive": false, "type": "number", "value": 5432}
+}`,
+		},
+		{
+			name: "state",
+			content: `{
+  "version": 4,
+  "terraform_version": "0.13.0",
+  "outputs": {
+    "vpc_id": {"value": "vpc-123", "type": "string"},
+    "db_password": {"value": "hunter2", "type": "string", "sensitive": true},
+    "subnets": {"value": ["a", "b"], "type": ["list", "string"]},
+    "port": {"value": 5432, "type": "number"}
+  },
+  "resources": []
+}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "outputs.json")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tc.content), 0600))
+
+			outputs, err := ReadTerraformOutputs(path)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]TerraformOutput{
+				"vpc_id":      {Value: "vpc-123"},
+				"db_password": {Value: "hunter2", Sensitive: true},
+				"subnets":     {Value: []interface{}{"a", "b"}},
+				"port":        {Value: json.Number("5432")},
+			}, outputs)
+		})
+	}
+}
+
+func TestTerraformSetter_Set(t *testing.T) {
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.vpc-id:
+      x-k8s-cli:
+        setter:
+          name: vpc-id
+          value: "unset"
+    io.k8s.cli.setters.db-port:
+      x-k8s-cli:
+        setter:
+          name: db-port
+          value: "0"
+    io.k8s.cli.setters.subnets:
+      type: array
+      x-k8s-cli:
+        setter:
+          name: subnets
+          listValues: ["unset"]
+    io.k8s.cli.setters.db-password:
+      x-k8s-cli:
+        setter:
+          name: db-password
+          value: "unset"
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: "nginx"
+`
+	resourceFile := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: infra
+data:
+  vpc: unset # {"$openapi":"vpc-id"}
+  port: "0" # {"$openapi":"db-port"}
+  password: unset # {"$openapi":"db-password"}
+  image: nginx # {"$openapi":"image"}
+  subnets: # {"$openapi":"subnets"}
+  - unset
+`
+	outputs := map[string]TerraformOutput{
+		"vpc_id":      {Value: "vpc-123"},
+		"port":        {Value: json.Number("5432")},
+		"subnets":     {Value: []interface{}{"a", "b"}},
+		"db_password": {Value: "hunter2", Sensitive: true},
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	openAPIPath := filepath.Join(dir, "Krmfile")
+	require.NoError(t, ioutil.WriteFile(openAPIPath, []byte(openAPIFile), 0600))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "configmap.yaml"), []byte(resourceFile), 0600))
+
+	counts, err := TerraformSetter{
+		Outputs:  outputs,
+		Mappings: map[string]string{"db-port": "port"},
+		SetBy:    "terraform",
+	}.Set(openAPIPath, dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"vpc-id": 1, "db-port": 1, "subnets": 1}, counts)
+
+	actual, err := ioutil.ReadFile(filepath.Join(dir, "configmap.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: infra
+data:
+  vpc: vpc-123 # {"$openapi":"vpc-id"}
+  port: "5432" # {"$openapi":"db-port"}
+  password: unset # {"$openapi":"db-password"}
+  image: nginx # {"$openapi":"image"}
+  subnets: # {"$openapi":"subnets"}
+  - "a"
+  - "b"
+`, string(actual))
+}
+
+func TestTerraformSetter_SetErrors(t *testing.T) {
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.vpc-id:
+      x-k8s-cli:
+        setter:
+          name: vpc-id
+          value: "unset"
+`
+	testCases := []struct {
+		name     string
+		setter   TerraformSetter
+		expected string
+	}{
+		{
+			name: "unknown setter",
+			setter: TerraformSetter{
+				Mappings: map[string]string{"subnet-id": "subnet_id"},
+			},
+			expected: "no setter subnet-id found",
+		},
+		{
+			name: "unknown output",
+			setter: TerraformSetter{
+				Mappings: map[string]string{"vpc-id": "vpc"},
+			},
+			expected: "no terraform output vpc found for setter vpc-id",
+		},
+		{
+			name: "list for scalar",
+			setter: TerraformSetter{
+				Outputs: map[string]TerraformOutput{
+					"vpc_id": {Value: []interface{}{"a", "b"}},
+				},
+			},
+			expected: "terraform output vpc_id is a list, but setter vpc-id isn't",
+		},
+		{
+			name: "map",
+			setter: TerraformSetter{
+				Outputs: map[string]TerraformOutput{
+					"vpc_id": {Value: map[string]interface{}{"a": "b"}},
+				},
+			},
+			expected: "terraform output vpc_id: setters can't be set to maps or nested lists",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			openAPIPath := filepath.Join(dir, "Krmfile")
+			require.NoError(t, ioutil.WriteFile(openAPIPath, []byte(openAPIFile), 0600))
+
+			_, err = tc.setter.Set(openAPIPath, dir)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/sets"
+	"sigs.k8s.io/kustomize/kyaml/setters2"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ReadValues reads the values of setters from a values file, whose
+// fields are the names of the setters, e.g.
+//
+//	replicas: 3
+//	image-tag: 1.8.1
+//	args: [--verbose, --port=8080]
+//
+// Each value is a scalar, or a list of scalars for list setters.
+// The setters of groups may be nested in maps of the groups, e.g.
+//
+//	frontend:
+//	  replicas: 3
+//
+// is the value of the frontend.replicas setter, see setters2.InGroup.
+func ReadValues(path string) (map[string][]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	values := map[string][]string{}
+	if strings.TrimSpace(string(b)) == "" {
+		return values, nil
+	}
+	y, err := yaml.Parse(string(b))
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "reading values %s", path)
+	}
+	if y.YNode().Kind != yaml.MappingNode {
+		return nil, errors.Errorf("values file %s must map setters to their values", path)
+	}
+	if err := readGroupValues(values, "", y); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// readGroupValues reads the values of the setters of group from
+// the map y into values, and those of the groups nested in it.
+func readGroupValues(values map[string][]string, group string, y *yaml.RNode) error {
+	return y.VisitFields(func(node *yaml.MapNode) error {
+		name := setters2.GroupName(group, node.Key.YNode().Value)
+		v := node.Value.YNode()
+		switch v.Kind {
+		case yaml.ScalarNode:
+			values[name] = []string{v.Value}
+		case yaml.SequenceNode:
+			if len(v.Content) == 0 {
+				return errors.Errorf("setters can't be set to empty lists")
+			}
+			for _, e := range v.Content {
+				if e.Kind != yaml.ScalarNode {
+					return errors.Errorf(
+						"value of setter %s must be a list of scalars", name)
+				}
+				values[name] = append(values[name], e.Value)
+			}
+		case yaml.MappingNode:
+			if len(v.Content) == 0 {
+				return errors.Errorf("group %s has no values", name)
+			}
+			return readGroupValues(values, name, node.Value)
+		default:
+			return errors.Errorf("value of setter %s must be a scalar, a list, or a map of a group", name)
+		}
+		return nil
+	})
+}
+
+// ValuesSetter sets many setters at once, e.g. to the values of a
+// values file.  The values are all validated against the definitions
+// of their setters before any is set, and then the resources are
+// updated in a single pass, rather than one for each setter.
+type ValuesSetter struct {
+	// Values are the values of the setters, keyed by their names.
+	// List setters have a value for each element.
+	Values map[string][]string
+
+	// Group, if set, is the group of the setters, whose names the
+	// Values are keyed by are relative to, see setters2.InGroup.
+	Group string
+
+	// SetBy is recorded as who set the setters.
+	SetBy string
+
+	// Force, if true, sets locked setters, see setters2.SetOpenAPI.
+	Force bool
+
+	// OnFormattingChurn, if set, is told about the resource files
+	// which setting would only reformat, see kio.LocalPackageWriter.
+	OnFormattingChurn func(paths []string) error
+
+	// OnDeprecation, if set, is told about each deprecated setter
+	// which sets fields, see setters2.Deprecation.
+	OnDeprecation func(d setters2.Deprecation)
+}
+
+// Set updates the OpenAPI definitions and resources with the values,
+// and returns the number of fields set.  If any value is invalid,
+// nothing is set.
+func (vs ValuesSetter) Set(openAPIPath, resourcesPath string) (int, error) {
+	stat, err := os.Stat(openAPIPath)
+	if err != nil {
+		return 0, err
+	}
+	curOpenAPI, err := ioutil.ReadFile(openAPIPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// update the definitions in order, so the first invalid value is
+	// reported the same way every time
+	values := map[string][]string{}
+	var names []string
+	for name, v := range vs.Values {
+		name = setters2.GroupName(vs.Group, name)
+		values[name] = v
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// validate and update all the values before writing the OpenAPI file,
+	// so that it's updated for all of them, or none
+	err = yaml.UpdateFile(yaml.FilterFunc(func(object *yaml.RNode) (*yaml.RNode, error) {
+		for _, name := range names {
+			values := values[name]
+			soa := setters2.SetOpenAPI{
+				Name:       name,
+				Value:      values[0],
+				ListValues: values[1:],
+				SetBy:      vs.SetBy,
+				Force:      vs.Force,
+			}
+			if _, err := soa.Filter(object); err != nil {
+				return nil, err
+			}
+			if err := checkListValue(object, name, values); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}), openAPIPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// Load the updated definitions
+	if err := openapi.AddSchemaFromFile(openAPIPath); err != nil {
+		return 0, err
+	}
+
+	// Update the resources with all the values at once
+	inout := &kio.LocalPackageReadWriter{
+		PackagePath:       resourcesPath,
+		NoDeleteFiles:     true,
+		OnFormattingChurn: vs.OnFormattingChurn,
+	}
+	s := &setters2.Set{Names: sets.String{}}
+	s.Names.Insert(names...)
+	err = kio.Pipeline{
+		Inputs:  []kio.Reader{inout},
+		Filters: []kio.Filter{setters2.SetAll(s)},
+		Outputs: []kio.Writer{inout},
+	}.Execute()
+
+	// revert openAPI file if set operation fails
+	if err != nil {
+		if writeErr := ioutil.WriteFile(openAPIPath, curOpenAPI, stat.Mode().Perm()); writeErr != nil {
+			return 0, writeErr
+		}
+		return s.Count, err
+	}
+	warnDeprecations(s, vs.OnDeprecation)
+	return s.Count, nil
+}
+
+// checkListValue returns an error if values is a list,
+// but the setter with name in the OpenAPI object isn't.
+func checkListValue(object *yaml.RNode, name string, values []string) error {
+	if len(values) < 2 {
+		return nil
+	}
+	t, err := object.Pipe(yaml.Lookup(
+		"openAPI", "definitions", fieldmeta.SetterDefinitionPrefix+name, "type"))
+	if err != nil {
+		return err
+	}
+	if t == nil || t.YNode().Value != "array" {
+		return errors.Errorf("value of setter %s is a list, but it isn't a list setter", name)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package settersutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+func TestReadValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected map[string][]string
+		err      string
+	}{
+		{
+			name: "values",
+			content: `
+replicas: 3
+image-tag: "1.8.1"
+args: [--verbose, --port=8080]
+`,
+			expected: map[string][]string{
+				"replicas":  {"3"},
+				"image-tag": {"1.8.1"},
+				"args":      {"--verbose", "--port=8080"},
+			},
+		},
+		{
+			name:     "empty",
+			content:  ``,
+			expected: map[string][]string{},
+		},
+		{
+			name: "groups",
+			content: `
+frontend:
+  replicas: 3
+  image:
+    tag: 1.8.1
+backend:
+  replicas: 5
+`,
+			expected: map[string][]string{
+				"frontend.replicas":  {"3"},
+				"frontend.image.tag": {"1.8.1"},
+				"backend.replicas":   {"5"},
+			},
+		},
+		{
+			name:    "empty-group",
+			content: `frontend: {}`,
+			err:     "group frontend has no values",
+		},
+		{
+			name:    "nested-list",
+			content: `args: [[a]]`,
+			err:     "value of setter args must be a list of scalars",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "values.yaml")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tc.content), 0600))
+
+			values, err := ReadValues(path)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, values)
+		})
+	}
+}
+
+func TestValuesSetter_Set(t *testing.T) {
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "1"
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: "nginx:1.7.9"
+    io.k8s.cli.setters.args:
+      type: array
+      x-k8s-cli:
+        setter:
+          name: args
+          listValues: ["--quiet"]
+`
+	resources := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 1 # {"$openapi":"replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$openapi":"image"}
+        args: # {"$openapi":"args"}
+        - "--quiet"
+`
+	testCases := []struct {
+		name      string
+		values    map[string][]string
+		count     int
+		resources string
+		err       string
+	}{
+		{
+			name: "set",
+			values: map[string][]string{
+				"replicas": {"3"},
+				"image":    {"nginx:1.8.1"},
+				"args":     {"--verbose", "--port=8080"},
+			},
+			count: 3,
+			resources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 3 # {"$openapi":"replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.8.1 # {"$openapi":"image"}
+        args: # {"$openapi":"args"}
+        - "--verbose"
+        - "--port=8080"
+`,
+		},
+		{
+			name: "invalid",
+			values: map[string][]string{
+				"image":    {"nginx:1.8.1"},
+				"replicas": {"three"},
+			},
+			err: "invalid value for setter replicas",
+		},
+		{
+			name: "not-a-list",
+			values: map[string][]string{
+				"image": {"nginx:1.8.1", "nginx:1.8.2"},
+			},
+			err: "value of setter image is a list, but it isn't a list setter",
+		},
+		{
+			name: "missing",
+			values: map[string][]string{
+				"namespace": {"prod"},
+			},
+			err: "no setter namespace found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer openapi.ResetOpenAPI()
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			openAPIPath := filepath.Join(dir, "Krmfile")
+			require.NoError(t, ioutil.WriteFile(openAPIPath, []byte(openAPIFile), 0600))
+			resourcesPath := filepath.Join(dir, "deployment.yaml")
+			require.NoError(t, ioutil.WriteFile(resourcesPath, []byte(resources), 0600))
+
+			count, err := ValuesSetter{Values: tc.values}.Set(openAPIPath, dir)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+
+				// nothing is set
+				b, err := ioutil.ReadFile(openAPIPath)
+				require.NoError(t, err)
+				assert.Equal(t, openAPIFile, string(b))
+				b, err = ioutil.ReadFile(resourcesPath)
+				require.NoError(t, err)
+				assert.Equal(t, resources, string(b))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.count, count)
+
+			b, err := ioutil.ReadFile(resourcesPath)
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tc.resources), strings.TrimSpace(string(b)))
+			b, err = ioutil.ReadFile(openAPIPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(b), `value: "3"`)
+		})
+	}
+}
+
+func TestValuesSetter_Set_group(t *testing.T) {
+	openAPIFile := `openAPI:
+  definitions:
+    io.k8s.cli.setters.frontend.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: frontend.replicas
+          value: "1"
+    io.k8s.cli.setters.backend.replicas:
+      type: integer
+      x-k8s-cli:
+        setter:
+          name: backend.replicas
+          value: "1"
+`
+	resources := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 1 # {"$openapi":"frontend.replicas"}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  replicas: 1 # {"$openapi":"backend.replicas"}
+`
+	defer openapi.ResetOpenAPI()
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	openAPIPath := filepath.Join(dir, "Krmfile")
+	require.NoError(t, ioutil.WriteFile(openAPIPath, []byte(openAPIFile), 0600))
+	resourcesPath := filepath.Join(dir, "deployment.yaml")
+	require.NoError(t, ioutil.WriteFile(resourcesPath, []byte(resources), 0600))
+
+	count, err := ValuesSetter{
+		Group:  "frontend",
+		Values: map[string][]string{"replicas": {"3"}},
+	}.Set(openAPIPath, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	b, err := ioutil.ReadFile(resourcesPath)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 3 # {"$openapi":"frontend.replicas"}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  replicas: 1 # {"$openapi":"backend.replicas"}
+`, string(b))
+}
@@ -0,0 +1,295 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package setters2
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/sets"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Suggestion is a setter suggested for fields which are commonly
+// parameterized.
+type Suggestion struct {
+	// Name is the name of the setter.
+	Name string
+
+	// Value is the value of the fields, and so of the setter.
+	Value string
+
+	// Type is the OpenAPI type of the setter, if known.
+	Type string
+
+	// Substitution, if not nil, is the substitution the fields
+	// reference, e.g. of images, for setters of only part of
+	// the fields, e.g. the tags of the images.
+	Substitution *SubstitutionDefinition
+
+	// Fields are the fields for the setter, as the kind and name
+	// of their resource, and their path, e.g.
+	// Deployment/nginx spec.replicas
+	Fields []string
+
+	// nodes are the fields for the setter
+	nodes []*yaml.RNode
+}
+
+// Suggest suggests setters for the fields of the resources which are commonly
+// parameterized -- namespaces, replicas, the tags of images, and the resource
+// limits of containers -- so that packages may be parameterized in bulk, rather
+// than one create-setter at a time.  Fields with the same value share a setter,
+// unless they're of different resources or containers whose setters are named
+// by them.  Fields which already reference a setter or substitution are skipped.
+type Suggest struct {
+	// Existing are the names of the setters and substitutions which are
+	// already defined, which aren't suggested.
+	Existing sets.String
+
+	// Create, if true, references the setters suggested from their fields,
+	// or their substitutions, and only the resources of the files with
+	// such fields are returned.  The definitions must be added separately.
+	Create bool
+
+	// Suggestions are the setters suggested, in the order of their
+	// first fields.
+	Suggestions []*Suggestion
+
+	// byName are the Suggestions by name
+	byName map[string]*Suggestion
+
+	// fields is the number of fields of the Suggestions
+	fields int
+}
+
+// Filter implements kio.Filter
+func (s *Suggest) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	s.byName = map[string]*Suggestion{}
+	filesToUpdate := sets.String{}
+	for i := range nodes {
+		preCount := s.fields
+		if err := s.suggestForResource(nodes[i]); err != nil {
+			return nil, err
+		}
+		if s.fields > preCount {
+			path, _, err := kioutil.GetFileAnnotations(nodes[i])
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			filesToUpdate.Insert(path)
+		}
+	}
+	if !s.Create {
+		return nodes, nil
+	}
+	for _, sg := range s.Suggestions {
+		ref := fieldmeta.DefinitionsPrefix + fieldmeta.SetterDefinitionPrefix + sg.Name
+		if sg.Substitution != nil {
+			ref = fieldmeta.DefinitionsPrefix + fieldmeta.SubstitutionDefinitionPrefix +
+				sg.Substitution.Name
+		}
+		for _, n := range sg.nodes {
+			if err := (&Add{Ref: ref}).addRef(n); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// return only the nodes of the files with fields referencing the setters
+	var nodesInUpdatedFiles []*yaml.RNode
+	for i := range nodes {
+		path, _, err := kioutil.GetFileAnnotations(nodes[i])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if filesToUpdate.Has(path) {
+			nodesInUpdatedFiles = append(nodesInUpdatedFiles, nodes[i])
+		}
+	}
+	return nodesInUpdatedFiles, nil
+}
+
+// podSpecPaths are the paths to the pod specs of the resources
+// which have them.
+var podSpecPaths = [][]string{
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+	{"spec"},
+}
+
+// suggestForResource suggests setters for the fields of resource.
+func (s *Suggest) suggestForResource(resource *yaml.RNode) error {
+	meta, err := resource.GetMeta()
+	if err != nil || meta.Kind == "" || meta.Name == "" {
+		// not a resource
+		return nil
+	}
+	id := meta.Kind + "/" + meta.Name
+
+	if ns := fieldNode(resource, "metadata", "namespace"); ns != nil {
+		v := ns.YNode().Value
+		s.suggest([]string{"namespace", v + "-namespace"},
+			ns, "", v, id+" metadata.namespace", nil)
+	}
+
+	if r := fieldNode(resource, "spec", "replicas"); r != nil {
+		s.suggest([]string{
+			meta.Name + "-replicas",
+			meta.Name + "-" + strings.ToLower(meta.Kind) + "-replicas"},
+			r, "integer", r.YNode().Value, id+" spec.replicas", nil)
+	}
+
+	for _, p := range podSpecPaths {
+		podSpec, err := resource.Pipe(yaml.Lookup(p...))
+		if err != nil {
+			return err
+		}
+		if podSpec == nil || podSpec.YNode().Kind != yaml.MappingNode {
+			continue
+		}
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, err := podSpec.Pipe(yaml.Lookup(field))
+			if err != nil {
+				return err
+			}
+			if containers == nil {
+				continue
+			}
+			path := strings.Join(p, ".") + "." + field
+			if err := containers.VisitElements(func(c *yaml.RNode) error {
+				s.suggestForContainer(c, meta.Name, id, path)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		break
+	}
+	return nil
+}
+
+// suggestForContainer suggests setters for the image tag
+// and resource limits of container c.
+func (s *Suggest) suggestForContainer(c *yaml.RNode, resourceName, id, path string) {
+	name := ""
+	if n := fieldNode(c, "name"); n != nil {
+		name = n.YNode().Value
+	}
+	path = fmt.Sprintf("%s[name=%s]", path, name)
+
+	if image := fieldNode(c, "image"); image != nil {
+		s.suggestForImage(image, id+" "+path+".image")
+	}
+
+	if name == "" {
+		return
+	}
+	for _, resource := range []string{"cpu", "memory"} {
+		limit := fieldNode(c, "resources", "limits", resource)
+		if limit == nil {
+			continue
+		}
+		s.suggest([]string{
+			name + "-" + resource + "-limit",
+			resourceName + "-" + name + "-" + resource + "-limit"},
+			limit, "", limit.YNode().Value, id+" "+path+".resources.limits."+resource, nil)
+	}
+}
+
+// suggestForImage suggests a setter for the tag of image,
+// with a substitution for the image.
+func (s *Suggest) suggestForImage(image *yaml.RNode, field string) {
+	v := image.YNode().Value
+	if strings.Contains(v, "@") {
+		// images with digests are pinned
+		return
+	}
+	i := strings.LastIndex(v, ":")
+	if i < 0 || strings.Contains(v[i:], "/") {
+		// no tag, only a registry port
+		return
+	}
+	name, tag := v[:i], v[i+1:]
+	base := name[strings.LastIndex(name, "/")+1:]
+	s.suggest([]string{
+		base + "-tag",
+		strings.NewReplacer("/", "-", ".", "-", ":", "-").Replace(name) + "-tag"},
+		image, "", tag, field, func(setter string) *SubstitutionDefinition {
+			marker := "${" + setter + "}"
+			return &SubstitutionDefinition{
+				Name:    strings.TrimSuffix(setter, "-tag") + "-image",
+				Pattern: name + ":" + marker,
+				Values: []Value{{
+					Marker: marker,
+					Ref:    fieldmeta.DefinitionsPrefix + fieldmeta.SetterDefinitionPrefix + setter,
+				}},
+			}
+		})
+}
+
+// suggest suggests a setter for the field node, of the value, named
+// by the first of the candidate names which is free, or whose setter
+// has the same value, and substitution if any.
+func (s *Suggest) suggest(candidates []string, node *yaml.RNode, t, value, field string,
+	substitution func(setter string) *SubstitutionDefinition) {
+	if value == "" || hasReference(node) {
+		return
+	}
+	for i := 0; ; i++ {
+		name := candidates[len(candidates)-1] + fmt.Sprintf("-%d", i-len(candidates)+2)
+		if i < len(candidates) {
+			name = candidates[i]
+		}
+		var sub *SubstitutionDefinition
+		if substitution != nil {
+			sub = substitution(name)
+		}
+		if s.Existing.Has(name) || sub != nil && s.Existing.Has(sub.Name) {
+			continue
+		}
+		sg, found := s.byName[name]
+		if found && (sg.Value != value || !sameSubstitution(sg.Substitution, sub)) {
+			continue
+		}
+		if !found {
+			sg = &Suggestion{Name: name, Value: value, Type: t, Substitution: sub}
+			s.byName[name] = sg
+			s.Suggestions = append(s.Suggestions, sg)
+		}
+		sg.Fields = append(sg.Fields, field)
+		sg.nodes = append(sg.nodes, node)
+		s.fields++
+		return
+	}
+}
+
+// sameSubstitution returns true if a and b substitute
+// the same pattern, or are both nil.
+func sameSubstitution(a, b *SubstitutionDefinition) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name && a.Pattern == b.Pattern
+}
+
+// fieldNode returns the scalar field of object at path,
+// or nil if there is none.
+func fieldNode(object *yaml.RNode, path ...string) *yaml.RNode {
+	f, err := object.Pipe(yaml.Lookup(path...))
+	if err != nil || f == nil || f.YNode().Kind != yaml.ScalarNode {
+		return nil
+	}
+	return f
+}
+
+// hasReference returns true if the field node already
+// references a setter or substitution.
+func hasReference(node *yaml.RNode) bool {
+	fm := fieldmeta.FieldMeta{}
+	return fm.Read(node) == nil && !fm.IsEmpty()
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package setters2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/sets"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const suggestInput = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9
+        resources:
+          limits:
+            cpu: 500m
+            memory: 128Mi
+      - name: sidecar
+        image: gcr.io/example/sidecar@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: prod
+spec:
+  replicas: 2 # {"$ref": "#/definitions/io.k8s.cli.setters.api-replicas"}
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: registry.example.com:5000/nginx:1.7.9
+      containers:
+      - name: nginx
+        image: nginx:1.7.9
+        resources:
+          limits:
+            cpu: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: staging
+`
+
+func TestSuggest_Filter(t *testing.T) {
+	nodes, err := (&kio.ByteReader{
+		Reader:                strings.NewReader(suggestInput),
+		OmitReaderAnnotations: true,
+	}).Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	s := &Suggest{Existing: sets.String{}}
+	s.Existing.Insert("api-replicas")
+	out, err := s.Filter(nodes)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, nodes, out)
+
+	var actual []string
+	for _, sg := range s.Suggestions {
+		a := sg.Name + "=" + sg.Value + " " + sg.Type
+		if sg.Substitution != nil {
+			a += " " + sg.Substitution.Name + ":" + sg.Substitution.Pattern
+		}
+		actual = append(actual, a+" "+strings.Join(sg.Fields, ", "))
+	}
+	assert.Equal(t, []string{
+		"namespace=prod  Deployment/web metadata.namespace, Deployment/api metadata.namespace",
+		"web-replicas=3 integer Deployment/web spec.replicas",
+		"nginx-tag=1.7.9  nginx-image:nginx:${nginx-tag} " +
+			"Deployment/web spec.template.spec.containers[name=nginx].image, " +
+			"Deployment/api spec.template.spec.containers[name=nginx].image",
+		"nginx-cpu-limit=500m  Deployment/web spec.template.spec.containers[name=nginx].resources.limits.cpu",
+		"nginx-memory-limit=128Mi  Deployment/web spec.template.spec.containers[name=nginx].resources.limits.memory",
+		"registry-example-com-5000-nginx-tag=1.7.9  " +
+			"registry-example-com-5000-nginx-image:registry.example.com:5000/nginx:${registry-example-com-5000-nginx-tag} " +
+			"Deployment/api spec.template.spec.initContainers[name=migrate].image",
+		"api-nginx-cpu-limit=1  Deployment/api spec.template.spec.containers[name=nginx].resources.limits.cpu",
+		"staging-namespace=staging  ConfigMap/settings metadata.namespace",
+	}, actual)
+}
+
+func TestSuggest_Filter_create(t *testing.T) {
+	nodes, err := (&kio.ByteReader{
+		Reader: strings.NewReader(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  annotations:
+    config.kubernetes.io/path: web.yaml
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  annotations:
+    config.kubernetes.io/path: secret.yaml
+`),
+		OmitReaderAnnotations: true,
+	}).Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	out, err := (&Suggest{Create: true}).Filter(nodes)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, out, 1) {
+		t.FailNow()
+	}
+	actual, err := out[0].String()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod # {"$openapi":"namespace"}
+  annotations:
+    config.kubernetes.io/path: web.yaml
+spec:
+  replicas: 3 # {"$openapi":"web-replicas"}
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.7.9 # {"$openapi":"nginx-image"}
+`), strings.TrimSpace(actual))
+}
+
+func TestSuggest_Filter_numberedNames(t *testing.T) {
+	var nodes []*yaml.RNode
+	for _, ns := range []string{"a", "b", "c"} {
+		nodes = append(nodes, yaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: `+ns+`
+`))
+	}
+	s := &Suggest{Existing: sets.String{}}
+	s.Existing.Insert("b-namespace")
+	if _, err := s.Filter(nodes); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var names []string
+	for _, sg := range s.Suggestions {
+		names = append(names, sg.Name+"="+sg.Value)
+	}
+	assert.Equal(t, []string{
+		"namespace=a", "b-namespace-2=b", "c-namespace=c"}, names)
+}
@@ -23,17 +23,30 @@ type setter struct {
 	EnumValues map[string]string `yaml:"enumValues,omitempty" json:"enumValues,omitempty"`
 	Required   bool              `yaml:"required,omitempty" json:"required,omitempty"`
 	IsSet      bool              `yaml:"isSet,omitempty" json:"isSet,omitempty"`
+	FromEnv    string            `yaml:"fromEnv,omitempty" json:"fromEnv,omitempty"`
+	Targets    []Target          `yaml:"targets,omitempty" json:"targets,omitempty"`
+
+	RecordHistory bool           `yaml:"recordHistory,omitempty" json:"recordHistory,omitempty"`
+	History       []HistoryEntry `yaml:"history,omitempty" json:"history,omitempty"`
+
+	Deprecated bool   `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	ReplacedBy string `yaml:"replacedBy,omitempty" json:"replacedBy,omitempty"`
+
+	Locked bool `yaml:"locked,omitempty" json:"locked,omitempty"`
 }
 
 type substitution struct {
 	Name    string                        `yaml:"name,omitempty" json:"name,omitempty"`
 	Pattern string                        `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Regex   string                        `yaml:"regex,omitempty" json:"regex,omitempty"`
 	Values  []substitutionSetterReference `yaml:"values,omitempty" json:"values,omitempty"`
+	Targets []Target                      `yaml:"targets,omitempty" json:"targets,omitempty"`
 }
 
 type substitutionSetterReference struct {
-	Ref    string `yaml:"ref,omitempty" json:"ref,omitempty"`
-	Marker string `yaml:"marker,omitempty" json:"marker,omitempty"`
+	Ref     string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	Marker  string `yaml:"marker,omitempty" json:"marker,omitempty"`
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
 }
 
 //K8sCliExtensionKey is the name of the OpenAPI field containing the setter extensions
@@ -4,28 +4,135 @@
 package setters2
 
 import (
+	"os"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 // CheckRequiredSettersSet iterates through all the setter definitions in openAPI
 // schema and returns error if any of the setter has required field true and isSet false
 func CheckRequiredSettersSet() error {
+	var unset []string
 	for key := range openapi.Schema().Definitions {
-		if strings.Contains(key, fieldmeta.SetterDefinitionPrefix) {
+		if strings.HasPrefix(key, fieldmeta.SetterDefinitionPrefix) {
 			val := openapi.Schema().Definitions[key]
 			defExt, err := GetExtFromSchema(&val) // parse the extension out of the openAPI
 			if err != nil {
 				return errors.Wrap(err)
 			}
-			if defExt.Setter.Required && !defExt.Setter.IsSet {
-				return errors.Errorf("setter %s is required but not set, "+
-					"please set it to new value and try again", strings.TrimPrefix(key, fieldmeta.SetterDefinitionPrefix))
+			if defExt != nil && defExt.Setter != nil &&
+				defExt.Setter.Required && !defExt.Setter.IsSet {
+				unset = append(unset, strings.TrimPrefix(key, fieldmeta.SetterDefinitionPrefix))
 			}
 		}
 	}
-	return nil
+	sort.Strings(unset)
+	return requiredSettersError(unset)
+}
+
+// UnsetRequiredSettersInFile returns the sorted names of the setters of the
+// OpenAPI definitions in the file at path, e.g. a Krmfile, which are required
+// but not set.  There are none if the file doesn't exist.
+func UnsetRequiredSettersInFile(path string) ([]string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	object, err := yaml.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	definitions, err := object.Pipe(yaml.Lookup(openapi.SupplementaryOpenAPIFieldName, "definitions"))
+	if err != nil || definitions == nil {
+		return nil, err
+	}
+	var unset []string
+	err = definitions.VisitFields(func(node *yaml.MapNode) error {
+		key := node.Key.YNode().Value
+		if !strings.HasPrefix(key, fieldmeta.SetterDefinitionPrefix) {
+			return nil
+		}
+		def, err := node.Value.Pipe(yaml.Lookup(K8sCliExtensionKey, "setter"))
+		if err != nil || def == nil {
+			return err
+		}
+		s := setter{}
+		if err := def.Document().Decode(&s); err != nil {
+			return errors.Wrap(err)
+		}
+		if s.Required && !s.IsSet {
+			unset = append(unset, strings.TrimPrefix(key, fieldmeta.SetterDefinitionPrefix))
+		}
+		return nil
+	})
+	sort.Strings(unset)
+	return unset, err
+}
+
+// CheckRequiredSetters is a kio.Filter which fails if any setter of the
+// OpenAPI definitions in the file OpenAPIPath is required but not set,
+// so that a package isn't rendered with the placeholder values of the
+// setters its consumers must set.  The Resources are passed through.
+type CheckRequiredSetters struct {
+	// OpenAPIPath is the path to the file with the definitions, e.g. a
+	// Krmfile.  No setters are required if it doesn't exist.
+	OpenAPIPath string
+}
+
+// Filter implements kio.Filter
+func (c CheckRequiredSetters) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	unset, err := UnsetRequiredSettersInFile(c.OpenAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := requiredSettersError(unset); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// requiredSettersError returns the error for the required
+// setters unset, or nil if there are none.
+func requiredSettersError(unset []string) error {
+	switch len(unset) {
+	case 0:
+		return nil
+	case 1:
+		return errors.Errorf("setter %s is required but not set, "+
+			"please set it to new value and try again", unset[0])
+	default:
+		return errors.Errorf("setters %s are required but not set, "+
+			"please set them to new values and try again", strings.Join(unset, ", "))
+	}
+}
+
+// SetterHistory returns the values the setter with name was set to, oldest
+// first, from its definition in the OpenAPI file at path.  Only setters with
+// recordHistory have a history.
+func SetterHistory(path, name string) ([]HistoryEntry, error) {
+	y, err := yaml.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	def, err := y.Pipe(yaml.Lookup(openapi.SupplementaryOpenAPIFieldName, "definitions",
+		fieldmeta.SetterDefinitionPrefix+name, K8sCliExtensionKey, "setter"))
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, errors.Errorf("no setter %s found", name)
+	}
+	s, err := def.String()
+	if err != nil {
+		return nil, err
+	}
+	sd := SetterDefinition{}
+	if err := yaml.Unmarshal([]byte(s), &sd); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return sd.History, nil
 }
@@ -9,9 +9,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func TestCheckRequiredSettersSet(t *testing.T) {
@@ -170,3 +172,177 @@ openAPI:
 		})
 	}
 }
+
+func TestCheckRequiredSetters_Filter(t *testing.T) {
+	var tests = []struct {
+		name             string
+		inputOpenAPIfile string
+		expectedUnset    []string
+		expectedError    string
+	}{
+		{
+			name: "required unset",
+			inputOpenAPIfile: `
+apiVersion: v1alpha1
+kind: Krmfile
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+          required: true
+    io.k8s.cli.setters.image:
+      x-k8s-cli:
+        setter:
+          name: image
+          value: nginx
+          required: true
+    io.k8s.cli.setters.tag:
+      x-k8s-cli:
+        setter:
+          name: tag
+          value: "1.7.9"
+          required: true
+          isSet: true
+    io.k8s.cli.substitutions.image-tag:
+      x-k8s-cli:
+        substitution:
+          name: image-tag
+          pattern: IMAGE:TAG
+ `,
+			expectedUnset: []string{"image", "replicas"},
+			expectedError: "setters image, replicas are required but not set, " +
+				"please set them to new values and try again",
+		},
+		{
+			name: "required set",
+			inputOpenAPIfile: `
+apiVersion: v1alpha1
+kind: Krmfile
+openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+          required: true
+          isSet: true
+ `,
+		},
+		{
+			name: "no definitions",
+			inputOpenAPIfile: `
+apiVersion: v1alpha1
+kind: Krmfile
+ `,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "Krmfile")
+			err = ioutil.WriteFile(path, []byte(test.inputOpenAPIfile), 0600)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			unset, err := UnsetRequiredSettersInFile(path)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, test.expectedUnset, unset)
+
+			nodes := []*yaml.RNode{yaml.MustParse("kind: Deployment\n")}
+			out, err := CheckRequiredSetters{OpenAPIPath: path}.Filter(nodes)
+			if test.expectedError != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Equal(t, test.expectedError, err.Error())
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, nodes, out)
+		})
+	}
+}
+
+func TestCheckRequiredSetters_Filter_noFile(t *testing.T) {
+	out, err := CheckRequiredSetters{OpenAPIPath: "/does/not/exist/Krmfile"}.Filter(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+}
+
+func TestSetterHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "Krmfile")
+	err = ioutil.WriteFile(path, []byte(`openAPI:
+  definitions:
+    io.k8s.cli.setters.replicas:
+      x-k8s-cli:
+        setter:
+          name: replicas
+          value: "3"
+          recordHistory: true
+    io.k8s.cli.setters.image-tag:
+      x-k8s-cli:
+        setter:
+          name: image-tag
+          value: "1.7.9"
+`), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	oldNow := now
+	defer func() { now = oldNow }()
+	times := []time.Time{
+		time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2020, 7, 2, 8, 30, 0, 0, time.FixedZone("PDT", -7*60*60)),
+	}
+	for i, s := range []SetOpenAPI{
+		{Name: "replicas", Value: "4", SetBy: "alice"},
+		{Name: "replicas", Value: "5"},
+	} {
+		now = func() time.Time { return times[i] }
+		if !assert.NoError(t, s.UpdateFile(path)) {
+			t.FailNow()
+		}
+		if !assert.NoError(t, (SetOpenAPI{Name: "image-tag", Value: "1.8.1"}).UpdateFile(path)) {
+			t.FailNow()
+		}
+	}
+
+	history, err := SetterHistory(path, "replicas")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []HistoryEntry{
+		{Value: "4", SetBy: "alice", Timestamp: "2020-07-01T12:00:00Z"},
+		{Value: "5", Timestamp: "2020-07-02T15:30:00Z"},
+	}, history)
+
+	// setters without recordHistory have no history
+	history, err = SetterHistory(path, "image-tag")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, history)
+
+	_, err = SetterHistory(path, "namespace")
+	if assert.Error(t, err) {
+		assert.Equal(t, "no setter namespace found", err.Error())
+	}
+}
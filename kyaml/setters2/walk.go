@@ -4,6 +4,10 @@
 package setters2
 
 import (
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -56,12 +60,17 @@ func acceptImpl(v visitor, object *yaml.RNode, p string, oa *openapi.ResourceSch
 		})
 	case yaml.SequenceNode:
 		// get the schema for the sequence node, use the schema provided if not present
-		// on the field
+		// on the field, e.g. the comment of a flow style sequence is on the sequence
+		if fs := getFieldSchema(object); fs != nil {
+			oa = fs
+		}
 		if err := v.visitSequence(object, p, oa); err != nil {
 			return err
 		}
 		// get the schema for the elements
-		oa = getSchema(object, oa, "")
+		if oa != nil {
+			oa = oa.Elements()
+		}
 		return object.VisitElements(func(node *yaml.RNode) error {
 			// Traverse each list element
 			return acceptImpl(v, node, p, oa)
@@ -82,17 +91,8 @@ func acceptImpl(v visitor, object *yaml.RNode, p string, oa *openapi.ResourceSch
 // field is the name of the field
 func getSchema(r *yaml.RNode, s *openapi.ResourceSchema, field string) *openapi.ResourceSchema {
 	// get the override schema if it exists on the field
-	fm := fieldmeta.FieldMeta{}
-	if err := fm.Read(r); err == nil && !fm.IsEmpty() {
-		// per-field schema, this is fine
-		if fm.Schema.Ref.String() != "" {
-			// resolve the reference
-			s, err := openapi.Resolve(&fm.Schema.Ref)
-			if err == nil && s != nil {
-				fm.Schema = *s
-			}
-		}
-		return &openapi.ResourceSchema{Schema: &fm.Schema}
+	if fs := getFieldSchema(r); fs != nil {
+		return fs
 	}
 
 	// get the schema for a field of the node if the field is provided
@@ -121,3 +121,84 @@ func getSchema(r *yaml.RNode, s *openapi.ResourceSchema, field string) *openapi.
 	}
 	return openapi.SchemaForResourceType(yaml.TypeMeta{Kind: m.Kind, APIVersion: m.APIVersion})
 }
+
+// getFieldSchema returns the schema from the comment of r,
+// or nil if r has no such comment.
+func getFieldSchema(r *yaml.RNode) *openapi.ResourceSchema {
+	fm := fieldmeta.FieldMeta{}
+	if err := fm.Read(r); err != nil || fm.IsEmpty() {
+		return nil
+	}
+	// per-field schema, this is fine
+	if fm.Schema.Ref.String() != "" {
+		// resolve the reference
+		s, err := openapi.Resolve(&fm.Schema.Ref)
+		if err == nil && s != nil {
+			fm.Schema = *s
+		}
+	}
+	return &openapi.ResourceSchema{Schema: &fm.Schema}
+}
+
+// acceptTargets invokes the appropriate function on v for each field of object
+// which setters and substitutions target by their path, rather than by comments,
+// see Target.
+func acceptTargets(v visitor, object *yaml.RNode) error {
+	meta, err := object.GetMeta()
+	if err != nil || meta.Kind == "" {
+		// not a resource
+		return nil
+	}
+
+	// visit the definitions in order, so fields targeted by more than one
+	// are visited the same way every time
+	definitions := openapi.Schema().Definitions
+	var keys []string
+	for k := range definitions {
+		if strings.HasPrefix(k, fieldmeta.CLIDefinitionsPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sch := definitions[k]
+		ext, err := GetExtFromSchema(&sch)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		var targets []Target
+		switch {
+		case ext == nil:
+			continue
+		case ext.Setter != nil:
+			targets = ext.Setter.Targets
+		case ext.Substitution != nil:
+			targets = ext.Substitution.Targets
+		}
+		for _, t := range targets {
+			if !t.Select.matches(meta) {
+				continue
+			}
+			field, err := object.Pipe(yaml.Lookup(t.fieldPath()...))
+			if err != nil {
+				return err
+			}
+			if field == nil || hasReference(field) {
+				// the field is missing, or is set by its comment
+				continue
+			}
+			schema := &openapi.ResourceSchema{Schema: &sch}
+			switch field.YNode().Kind {
+			case yaml.ScalarNode:
+				err = v.visitScalar(field, t.FieldPath, schema)
+			case yaml.SequenceNode:
+				err = v.visitSequence(field, t.FieldPath, schema)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -613,6 +613,25 @@ metadata:
 `, assertNoErrorString(t)(r0.String()))
 }
 
+func TestClearLabel(t *testing.T) {
+	r0 := assertNoError(t)(Parse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+ labels:
+   z: y
+   app: java
+`))
+
+	rn := assertNoError(t)(r0.Pipe(ClearLabel("app")))
+	assert.Equal(t, "java\n", assertNoErrorString(t)(rn.String()))
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    z: y
+`, assertNoErrorString(t)(r0.String()))
+}
+
 func TestGetAnnotation(t *testing.T) {
 	r0 := assertNoError(t)(Parse(`apiVersion: apps/v1
 kind: Deployment
@@ -0,0 +1,104 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"sync"
+)
+
+const (
+	// maxInternedValueLen is the length of the longest field value
+	// InternMeta interns.  Longer values, such as
+	// last-applied-configuration annotations, are rarely repeated.
+	maxInternedValueLen = 128
+
+	// maxInternedStrings bounds the memory held by the pool in
+	// long running processes.  Once full, strings are no longer
+	// interned, but are otherwise unaffected.
+	maxInternedStrings = 1 << 16
+)
+
+// stringPool holds the interned strings.
+var stringPool = struct {
+	sync.RWMutex
+	strings map[string]string
+}{strings: map[string]string{}}
+
+// Intern returns a string equal to s which shares its memory with
+// all the other strings of the same value returned by Intern.
+func Intern(s string) string {
+	stringPool.RLock()
+	interned, found := stringPool.strings[s]
+	stringPool.RUnlock()
+	if found {
+		return interned
+	}
+
+	stringPool.Lock()
+	defer stringPool.Unlock()
+	if interned, found := stringPool.strings[s]; found {
+		return interned
+	}
+	if len(stringPool.strings) >= maxInternedStrings {
+		return s
+	}
+	stringPool.strings[s] = s
+	return s
+}
+
+// InternMeta interns the strings of rn which are most often repeated
+// across Resources: the apiVersion and kind, the metadata field names,
+// the namespace, and the keys and short values of the labels and
+// annotations.  Decoding gives each Resource its own copy of these,
+// which dominates memory use for pipelines with many Resources.
+func InternMeta(rn *RNode) {
+	if rn == nil || rn.YNode() == nil {
+		return
+	}
+	n := rn.YNode()
+	if n.Kind == DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if n.Kind != MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		key.Value = Intern(key.Value)
+		switch key.Value {
+		case APIVersionField, KindField:
+			internValue(value)
+		case MetadataField:
+			internMetadata(value)
+		}
+	}
+}
+
+func internMetadata(n *Node) {
+	if n.Kind != MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		key.Value = Intern(key.Value)
+		switch key.Value {
+		case NamespaceField:
+			internValue(value)
+		case LabelsField, AnnotationsField:
+			if value.Kind != MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				value.Content[j].Value = Intern(value.Content[j].Value)
+				internValue(value.Content[j+1])
+			}
+		}
+	}
+}
+
+func internValue(n *Node) {
+	if n.Kind == ScalarNode && len(n.Value) <= maxInternedValueLen {
+		n.Value = Intern(n.Value)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// data returns the address of the memory backing s.
+func data(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestIntern(t *testing.T) {
+	a := strings.Repeat("a", 3)
+	b := strings.Repeat("a", 3)
+	assert.NotEqual(t, data(a), data(b))
+	assert.Equal(t, data(Intern(a)), data(Intern(b)))
+	assert.Equal(t, "aaa", Intern(b))
+}
+
+func TestInternMeta(t *testing.T) {
+	input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: bar
+  labels:
+    app: web
+  annotations:
+    config.kubernetes.io/path: 'foo.yaml'
+    long: '` + strings.Repeat("x", maxInternedValueLen+1) + `'
+spec:
+  replicas: 1
+`
+	r1, err := Parse(input)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	r2, err := Parse(input)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	InternMeta(r1)
+	InternMeta(r2)
+
+	// interning doesn't change the content
+	assert.Equal(t, input, r1.MustString())
+
+	for _, path := range [][]string{
+		{"apiVersion"},
+		{"kind"},
+		{"metadata", "namespace"},
+		{"metadata", "labels", "app"},
+		{"metadata", "annotations", "config.kubernetes.io/path"},
+	} {
+		v1 := r1.Field(path[0]).Value
+		v2 := r2.Field(path[0]).Value
+		for _, p := range path[1:] {
+			v1 = v1.Field(p).Value
+			v2 = v2.Field(p).Value
+		}
+		assert.Equal(t, data(v1.YNode().Value), data(v2.YNode().Value), path)
+	}
+
+	// names and long values aren't interned
+	assert.NotEqual(t,
+		data(r1.Field("metadata").Value.Field("name").Value.YNode().Value),
+		data(r2.Field("metadata").Value.Field("name").Value.YNode().Value))
+	assert.NotEqual(t,
+		data(r1.Field("metadata").Value.Field("annotations").Value.Field("long").Value.YNode().Value),
+		data(r2.Field("metadata").Value.Field("annotations").Value.Field("long").Value.YNode().Value))
+}
+
+// BenchmarkInternMeta reports the heap retained by Resources read with and
+// without interning their metadata.
+func BenchmarkInternMeta(b *testing.B) {
+	input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: production
+  labels:
+    app.kubernetes.io/name: web
+    app.kubernetes.io/part-of: shop
+  annotations:
+    config.kubernetes.io/index: '0'
+    config.kubernetes.io/path: 'deployment.yaml'
+spec:
+  replicas: 1
+`
+	for _, intern := range []bool{false, true} {
+		name := "copy"
+		if intern {
+			name = "intern"
+		}
+		b.Run(name, func(b *testing.B) {
+			const resources = 1000
+			var retained uint64
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				nodes := make([]*RNode, resources)
+				for j := range nodes {
+					nodes[j] = MustParse(input)
+					if intern {
+						InternMeta(nodes[j])
+					}
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				retained += after.HeapAlloc - before.HeapAlloc
+				runtime.KeepAlive(nodes)
+			}
+			b.ReportMetric(float64(retained)/float64(b.N*resources), "retained-B/resource")
+		})
+	}
+}
@@ -113,3 +113,20 @@ func (s LabelSetter) Filter(rn *RNode) (*RNode, error) {
 func SetLabel(key, value string) LabelSetter {
 	return LabelSetter{Key: key, Value: value}
 }
+
+// LabelClearer removes a label at metadata.labels.
+// Returns nil if the label or field does not exist.
+type LabelClearer struct {
+	Kind string `yaml:"kind,omitempty"`
+	Key  string `yaml:"key,omitempty"`
+}
+
+func (c LabelClearer) Filter(rn *RNode) (*RNode, error) {
+	return rn.Pipe(
+		PathGetter{Path: []string{"metadata", "labels"}},
+		FieldClearer{Name: c.Key})
+}
+
+func ClearLabel(key string) LabelClearer {
+	return LabelClearer{Key: key}
+}
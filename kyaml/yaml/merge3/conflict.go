@@ -0,0 +1,43 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package merge3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict is a field which the dest and the update both
+// changed from the original, to different values.  Values
+// are "" where the field is missing, and lists are in flow
+// style, e.g. [a, b].
+type Conflict struct {
+	// Path is the field path, e.g.
+	// spec.template.spec.containers.[name=app].image
+	Path []string
+
+	Original string
+	Dest     string
+	Updated  string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: original %q, dest %q, updated %q",
+		strings.Join(c.Path, "."), c.Original, c.Dest, c.Updated)
+}
+
+// ConflictError is returned by merges with the
+// FailOnConflict strategy which found conflicts.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	var conflicts []string
+	for _, c := range e.Conflicts {
+		conflicts = append(conflicts, c.String())
+	}
+	return fmt.Sprintf("%d conflicts:\n  %s",
+		len(conflicts), strings.Join(conflicts, "\n  "))
+}
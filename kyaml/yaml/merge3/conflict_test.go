@@ -0,0 +1,161 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package merge3_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	. "sigs.k8s.io/kustomize/kyaml/yaml/merge3"
+)
+
+const (
+	conflictOrigin = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  paused: false
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0
+        args: [serve]
+`
+	conflictUpdate = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  paused: true
+  minReadySeconds: 10
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:2.0
+        args: [serve, --verbose]
+`
+	conflictDest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  paused: false
+  minReadySeconds: 5
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0
+        args: [serve, --quiet]
+`
+)
+
+var expectedConflicts = []Conflict{
+	{
+		Path:     []string{"spec", "minReadySeconds"},
+		Original: "", Dest: "5", Updated: "10",
+	},
+	{
+		Path:     []string{"spec", "replicas"},
+		Original: "1", Dest: "3", Updated: "2",
+	},
+	{
+		Path:     []string{"spec", "template", "spec", "containers", "[name=web]", "args"},
+		Original: "[serve]", Dest: "[serve, --quiet]", Updated: "[serve, --verbose]",
+	},
+}
+
+func mergeWithConflicts(t *testing.T, strategy ConflictStrategy) (string, []Conflict, error) {
+	var nodes []*yaml.RNode
+	for _, s := range []string{conflictDest, conflictOrigin, conflictUpdate} {
+		node, err := yaml.Parse(s)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		nodes = append(nodes, node)
+	}
+	result, conflicts, err := MergeWithConflicts(nodes[0], nodes[1], nodes[2], false, strategy)
+	if err != nil || result == nil {
+		return "", conflicts, err
+	}
+	s, err := result.String()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return s, conflicts, nil
+}
+
+func TestMergeWithConflictsTakeUpdate(t *testing.T) {
+	actual, conflicts, err := mergeWithConflicts(t, TakeUpdate)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, expectedConflicts, conflicts)
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  paused: true
+  minReadySeconds: 10
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:2.0
+        args: [serve, --verbose]
+`), strings.TrimSpace(actual))
+}
+
+func TestMergeWithConflictsTakeDest(t *testing.T) {
+	actual, conflicts, err := mergeWithConflicts(t, TakeDest)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, expectedConflicts, conflicts)
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  paused: true
+  minReadySeconds: 5
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:2.0
+        args: [serve, --quiet]
+`), strings.TrimSpace(actual))
+}
+
+func TestMergeWithConflictsFailOnConflict(t *testing.T) {
+	_, conflicts, err := mergeWithConflicts(t, FailOnConflict)
+	assert.Equal(t, expectedConflicts, conflicts)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `3 conflicts:
+  spec.minReadySeconds: original "", dest "5", updated "10"
+  spec.replicas: original "1", dest "3", updated "2"
+  spec.template.spec.containers.[name=web].args: original "[serve]", dest "[serve, --quiet]", updated "[serve, --verbose]"`,
+		err.Error())
+	_, ok := err.(*ConflictError)
+	assert.True(t, ok)
+}
@@ -11,11 +11,39 @@ import (
 )
 
 func Merge(dest, original, update *yaml.RNode) (*yaml.RNode, error) {
+	return MergeInfer(dest, original, update, false)
+}
+
+// MergeInfer performs a 3-way merge.  If infer is true, merge keys for lists
+// without a schema are inferred from the fields of the list elements.
+func MergeInfer(dest, original, update *yaml.RNode, infer bool) (*yaml.RNode, error) {
 	// if update == nil && original != nil => declarative deletion
 
 	return walk.Walker{
-		Visitor: Visitor{},
-		Sources: []*yaml.RNode{dest, original, update}}.Walk()
+		InferAssociativeLists: infer,
+		Visitor:               Visitor{},
+		Sources:               []*yaml.RNode{dest, original, update}}.Walk()
+}
+
+// MergeWithConflicts performs a 3-way merge like MergeInfer,
+// and returns the conflicts found: fields which the dest and
+// the update both changed to different values.  They're
+// resolved by strategy.  With FailOnConflict, the conflicts
+// are also returned in a ConflictError.
+func MergeWithConflicts(dest, original, update *yaml.RNode, infer bool,
+	strategy ConflictStrategy) (*yaml.RNode, []Conflict, error) {
+	var conflicts []Conflict
+	result, err := walk.Walker{
+		InferAssociativeLists: infer,
+		Visitor:               Visitor{Strategy: strategy, conflicts: &conflicts},
+		Sources:               []*yaml.RNode{dest, original, update}}.Walk()
+	if err != nil {
+		return nil, nil, err
+	}
+	if strategy == FailOnConflict && len(conflicts) > 0 {
+		return nil, conflicts, &ConflictError{Conflicts: conflicts}
+	}
+	return result, conflicts, nil
 }
 
 func MergeStrings(dest, original, update string, infer bool) (string, error) {
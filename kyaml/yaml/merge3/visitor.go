@@ -4,19 +4,104 @@
 package merge3
 
 import (
+	"strings"
+
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/kustomize/kyaml/yaml/walk"
 )
 
+// ConflictStrategy resolves Conflicts.
 type ConflictStrategy uint
 
 const (
-	// TODO: Support more strategies
+	// TakeUpdate resolves conflicts with the updated value.
 	TakeUpdate ConflictStrategy = 1 + iota
+
+	// TakeDest resolves conflicts with the dest value.
+	TakeDest
+
+	// FailOnConflict fails merges which find conflicts
+	// with a ConflictError.
+	FailOnConflict
 )
 
-type Visitor struct{}
+type Visitor struct {
+	// Strategy resolves conflicts.  TakeUpdate if 0.
+	Strategy ConflictStrategy
+
+	// conflicts records the conflicts found, if it isn't nil.
+	conflicts *[]Conflict
+}
+
+// conflict records a conflict at path if the dest and the
+// update both changed a field to different values, and
+// returns whether they did.
+func (m Visitor) conflict(path []string, nodes walk.Sources, values strValues) bool {
+	if yaml.IsNull(nodes.Updated()) || yaml.IsNull(nodes.Dest()) ||
+		yaml.IsEmpty(nodes.Updated()) || yaml.IsEmpty(nodes.Dest()) ||
+		values.Update == values.Dest {
+		return false
+	}
+	if !yaml.IsEmpty(nodes.Origin()) &&
+		(values.Update == values.Origin || values.Dest == values.Origin) {
+		return false
+	}
+	if yaml.IsEmpty(nodes.Origin()) {
+		values.Origin = ""
+	}
+	if m.conflicts != nil {
+		*m.conflicts = append(*m.conflicts, Conflict{
+			Path:     append([]string{}, path...),
+			Original: values.Origin,
+			Dest:     values.Dest,
+			Updated:  values.Update,
+		})
+	}
+	return true
+}
+
+// VisitScalarField visits a scalar like VisitScalar,
+// first resolving a conflict in it, if there's one.
+func (m Visitor) VisitScalarField(
+	path []string, nodes walk.Sources, s *openapi.ResourceSchema) (*yaml.RNode, error) {
+	var values strValues
+	if !yaml.IsEmpty(nodes.Updated()) {
+		values.Update = nodes.Updated().YNode().Value
+	}
+	if !yaml.IsEmpty(nodes.Origin()) {
+		values.Origin = nodes.Origin().YNode().Value
+	}
+	if !yaml.IsEmpty(nodes.Dest()) {
+		values.Dest = nodes.Dest().YNode().Value
+	}
+	if m.conflict(path, nodes, values) && m.Strategy == TakeDest {
+		return nodes.Dest(), nil
+	}
+	return m.VisitScalar(nodes, s)
+}
+
+// VisitListField visits a list like VisitList, first resolving
+// a conflict in it, if it's a non-associative list with one.
+// Conflicts in the elements of associative lists are found
+// as the elements are visited.
+func (m Visitor) VisitListField(
+	path []string, nodes walk.Sources, s *openapi.ResourceSchema, kind walk.ListKind) (*yaml.RNode, error) {
+	if kind == walk.NonAssociateList &&
+		!yaml.IsEmpty(nodes.Updated()) && !yaml.IsEmpty(nodes.Dest()) {
+		values, err := m.getStrValues(nodes)
+		if err != nil {
+			return nil, err
+		}
+		values.Origin = strings.TrimSpace(values.Origin)
+		values.Update = strings.TrimSpace(values.Update)
+		values.Dest = strings.TrimSpace(values.Dest)
+		if m.conflict(path, nodes, values) && m.Strategy == TakeDest {
+			return nodes.Dest(), nil
+		}
+	}
+	return m.VisitList(nodes, s, kind)
+}
 
 func (m Visitor) VisitMap(nodes walk.Sources, s *openapi.ResourceSchema) (*yaml.RNode, error) {
 	if yaml.IsNull(nodes.Updated()) || yaml.IsNull(nodes.Dest()) {
@@ -159,3 +244,4 @@ type strValues struct {
 }
 
 var _ walk.Visitor = Visitor{}
+var _ walk.FieldVisitor = Visitor{}
@@ -47,6 +47,7 @@ func (l *Walker) walkAssociativeSequence() (*yaml.RNode, error) {
 				Visitor:               l,
 				Schema:                s,
 				Sources:               l.elementValue(key, value),
+				Path:                  append(l.Path, "["+key+"="+value+"]"),
 			}.Walk()
 			if err != nil {
 				return nil, err
@@ -93,6 +94,7 @@ func (l *Walker) walkAssociativeSequence() (*yaml.RNode, error) {
 			Visitor:               l,
 			Schema:                s,
 			Sources:               l.elementValue(key /*empty key implies primitive*/, value),
+			Path:                  append(l.Path, "[="+value+"]"),
 		}.Walk()
 		if err != nil {
 			return nil, err
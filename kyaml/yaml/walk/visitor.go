@@ -24,5 +24,15 @@ type Visitor interface {
 	VisitList(Sources, *openapi.ResourceSchema, ListKind) (*yaml.RNode, error)
 }
 
+// FieldVisitor may be implemented by Visitors which need the
+// field path of the scalars and non-associative lists they
+// visit, e.g. to report conflicts.  Its methods are called
+// in place of VisitScalar and VisitList for those nodes.
+type FieldVisitor interface {
+	VisitScalarField([]string, Sources, *openapi.ResourceSchema) (*yaml.RNode, error)
+
+	VisitListField([]string, Sources, *openapi.ResourceSchema, ListKind) (*yaml.RNode, error)
+}
+
 // ClearNode is returned if GrepFilter should do nothing after calling Set
 var ClearNode *yaml.RNode
@@ -38,6 +38,27 @@ type Walker struct {
 	VisitKeysAsScalars bool
 }
 
+// VisitScalarField passes path to the Visitor, if it's a
+// FieldVisitor.  Walkers of fields are the Visitors of
+// their elements, so the path reaches the outermost Visitor.
+func (l Walker) VisitScalarField(
+	path []string, nodes Sources, s *openapi.ResourceSchema) (*yaml.RNode, error) {
+	if v, ok := l.Visitor.(FieldVisitor); ok {
+		return v.VisitScalarField(path, nodes, s)
+	}
+	return l.VisitScalar(nodes, s)
+}
+
+// VisitListField passes path to the Visitor, if
+// it's a FieldVisitor.  See VisitScalarField.
+func (l Walker) VisitListField(
+	path []string, nodes Sources, s *openapi.ResourceSchema, kind ListKind) (*yaml.RNode, error) {
+	if v, ok := l.Visitor.(FieldVisitor); ok {
+		return v.VisitListField(path, nodes, s, kind)
+	}
+	return l.VisitList(nodes, s, kind)
+}
+
 func (l Walker) Kind() yaml.Kind {
 	for _, s := range l.Sources {
 		if !yaml.IsEmpty(s) {
@@ -10,6 +10,7 @@ import (
 	"sigs.k8s.io/kustomize/api/filters/prefixsuffix"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filtersutil"
 	"sigs.k8s.io/yaml"
@@ -20,6 +21,10 @@ type plugin struct {
 	Prefix     string        `json:"prefix,omitempty" yaml:"prefix,omitempty"`
 	Suffix     string        `json:"suffix,omitempty" yaml:"suffix,omitempty"`
 	FieldSpecs types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Targets, if not empty, limits the transformation to
+	// the resources matching at least one of the selectors.
+	Targets []*types.Selector `json:"targets,omitempty" yaml:"targets,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -38,6 +43,7 @@ func (p *plugin) Config(
 	p.Prefix = ""
 	p.Suffix = ""
 	p.FieldSpecs = nil
+	p.Targets = nil
 	err = yaml.Unmarshal(c, p)
 	if err != nil {
 		return
@@ -52,11 +58,18 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	// Even if both the Prefix and Suffix are empty we want
 	// to proceed with the transformation. This allows to add contextual
 	// information to the resources (AddNamePrefix and AddNameSuffix).
+	selected, err := p.selectTargets(m)
+	if err != nil {
+		return err
+	}
 	for _, r := range m.Resources() {
 		// TODO: move this test into the filter (i.e. make a better filter)
 		if p.shouldSkip(r.OrgId()) {
 			continue
 		}
+		if selected != nil && !selected[r] {
+			continue
+		}
 		id := r.OrgId()
 		// current default configuration contains
 		// only one entry: "metadata/name" with no GVK
@@ -91,6 +104,29 @@ func smellsLikeANameChange(fs *types.FieldSpec) bool {
 	return fs.Path == "metadata/name"
 }
 
+// selectTargets returns the resources matching the targets,
+// or nil if there are no targets.
+func (p *plugin) selectTargets(
+	m resmap.ResMap) (map[*resource.Resource]bool, error) {
+	if len(p.Targets) == 0 {
+		return nil, nil
+	}
+	selected := make(map[*resource.Resource]bool)
+	for _, t := range p.Targets {
+		if t == nil {
+			continue
+		}
+		resources, err := m.Select(*t)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			selected[r] = true
+		}
+	}
+	return selected, nil
+}
+
 func (p *plugin) shouldSkip(id resid.ResId) bool {
 	for _, path := range prefixSuffixFieldSpecsToSkip {
 		if id.IsSelected(&path.Gvk) {
@@ -150,3 +150,58 @@ metadata:
   name: cm
 `)
 }
+
+func TestPrefixSuffixTransformerTargets(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PrefixSuffixTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: PrefixSuffixTransformer
+metadata:
+  name: notImportantHere
+prefix: baked-
+fieldSpecs:
+  - path: metadata/name
+targets:
+  - kind: ConfigMap
+  - labelSelector: app=pie
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: apple
+  labels:
+    app: pie
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: cherry
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: pie
+  name: baked-apple
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: cherry
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: baked-cm
+`)
+}
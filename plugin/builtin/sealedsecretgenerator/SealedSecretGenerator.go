@@ -0,0 +1,228 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/kv"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Generate a Bitnami SealedSecret, encrypting each value
+// the way kubeseal does, so that only the controller
+// holding the private key of the certificate can unseal it.
+//
+// Like kubeseal's, the encryption is randomized, so the
+// encrypted values differ on every build, even of the same
+// values: builds including a SealedSecret aren't
+// reproducible, fail VerifyDeterminism, and show a change
+// to the SealedSecret whenever they're diffed.  Commit the
+// generated SealedSecret, rather than regenerating it, where
+// that matters.
+type plugin struct {
+	h                *resmap.PluginHelpers
+	types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	types.SealedSecretArgs
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(h *resmap.PluginHelpers, config []byte) (err error) {
+	p.SealedSecretArgs = types.SealedSecretArgs{}
+	err = yaml.Unmarshal(config, p)
+	if p.SealedSecretArgs.Name == "" {
+		p.SealedSecretArgs.Name = p.Name
+	}
+	if p.SealedSecretArgs.Namespace == "" {
+		p.SealedSecretArgs.Namespace = p.Namespace
+	}
+	p.h = h
+	return
+}
+
+func (p *plugin) Generate() (resmap.ResMap, error) {
+	label, annotations, err := p.sealingLabel()
+	if err != nil {
+		return nil, err
+	}
+	certPem, err := p.h.Loader().Load(p.Cert)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading cert %s", p.Cert)
+	}
+	key, err := parseSealingCert(certPem)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing cert %s", p.Cert)
+	}
+	kvLdr := kv.NewLoader(p.h.Loader(), p.h.Validator())
+	pairs, err := kvLdr.Load(p.KvPairSources)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := map[string]interface{}{}
+	for _, pair := range pairs {
+		if err = kvLdr.Validator().ErrIfInvalidKey(pair.Key); err != nil {
+			return nil, err
+		}
+		if _, found := encrypted[pair.Key]; found {
+			return nil, fmt.Errorf(
+				"cannot add key %s, another key by that name already exists", pair.Key)
+		}
+		b, err := sealValue(key, []byte(pair.Value), label)
+		if err != nil {
+			return nil, errors.Wrapf(err, "sealing %s", pair.Key)
+		}
+		encrypted[pair.Key] = base64.StdEncoding.EncodeToString(b)
+	}
+
+	meta := map[string]interface{}{"name": p.SealedSecretArgs.Name}
+	if p.SealedSecretArgs.Namespace != "" {
+		meta["namespace"] = p.SealedSecretArgs.Namespace
+	}
+	template := map[string]interface{}{"metadata": meta}
+	if p.Options != nil {
+		if len(p.Options.Labels) > 0 {
+			meta["labels"] = toInterfaceMap(p.Options.Labels)
+		}
+		if len(p.Options.Annotations) > 0 {
+			meta["annotations"] = toInterfaceMap(p.Options.Annotations)
+		}
+	}
+	secretType := p.Type
+	if secretType == "" {
+		secretType = "Opaque"
+	}
+	template["type"] = secretType
+	sealedMeta := map[string]interface{}{}
+	for k, v := range meta {
+		sealedMeta[k] = v
+	}
+	if len(annotations) > 0 {
+		all := map[string]interface{}{}
+		if a, ok := meta["annotations"].(map[string]interface{}); ok {
+			for k, v := range a {
+				all[k] = v
+			}
+		}
+		for k, v := range annotations {
+			all[k] = v
+		}
+		sealedMeta["annotations"] = all
+	}
+
+	// The name is sealed, so it mustn't change.
+	args := p.GeneratorArgs
+	options := types.GeneratorOptions{}
+	if args.Options != nil {
+		options = *args.Options
+	}
+	options.DisableNameSuffixHash = true
+	args.Options = &options
+
+	res := p.h.ResmapFactory().RF().FromMapAndOption(map[string]interface{}{
+		"apiVersion": "bitnami.com/v1alpha1",
+		"kind":       "SealedSecret",
+		"metadata":   sealedMeta,
+		"spec": map[string]interface{}{
+			"encryptedData": encrypted,
+			"template":      template,
+		},
+	}, &args)
+	return p.h.ResmapFactory().FromResource(res), nil
+}
+
+// sealingLabel returns the label binding the sealed values to
+// the scope, and the annotations telling the controller the scope.
+func (p *plugin) sealingLabel() (string, map[string]string, error) {
+	ns := p.SealedSecretArgs.Namespace
+	switch p.Scope {
+	case "", "strict":
+		if ns == "" {
+			return "", nil, fmt.Errorf(
+				"sealed secret %s needs a namespace in the strict scope", p.SealedSecretArgs.Name)
+		}
+		return ns + "/" + p.SealedSecretArgs.Name, nil, nil
+	case "namespace-wide":
+		if ns == "" {
+			return "", nil, fmt.Errorf(
+				"sealed secret %s needs a namespace in the namespace-wide scope", p.SealedSecretArgs.Name)
+		}
+		return ns, map[string]string{
+			"sealedsecrets.bitnami.com/namespace-wide": "true"}, nil
+	case "cluster-wide":
+		return "", map[string]string{
+			"sealedsecrets.bitnami.com/cluster-wide": "true"}, nil
+	default:
+		return "", nil, fmt.Errorf(
+			"unknown scope %q; expected strict, namespace-wide or cluster-wide", p.Scope)
+	}
+}
+
+// parseSealingCert returns the RSA public key
+// of the PEM encoded certificate b.
+func parseSealingCert(b []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA public key, got %T", cert.PublicKey)
+	}
+	return key, nil
+}
+
+// sealValue encrypts value as kubeseal does: with a new AES-256-GCM
+// session key, which is itself encrypted with RSA-OAEP, prefixed
+// by its length.  The session key is never reused, so the nonce
+// can be zero.
+func sealValue(key *rsa.PublicKey, value []byte, label string) ([]byte, error) {
+	sessionKey := make([]byte, 32)
+	if _, err := rand.Read(sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(
+		sha256.New(), rand.Reader, key, sessionKey, []byte(label))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, 2, 2+len(encryptedKey)+len(value)+aead.Overhead())
+	binary.BigEndian.PutUint16(result, uint16(len(encryptedKey)))
+	result = append(result, encryptedKey...)
+	return aead.Seal(result, make([]byte, aead.NonceSize()), value, nil), nil
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func makeCert(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestSealedSecretGenerator(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("SealedSecretGenerator")
+	defer th.Reset()
+
+	th.WriteF("cert.pem", makeCert(t))
+
+	rm := th.LoadAndRunGenerator(`
+apiVersion: builtin
+kind: SealedSecretGenerator
+metadata:
+  name: mySecret
+cert: cert.pem
+scope: cluster-wide
+type: kubernetes.io/basic-auth
+literals:
+- username=admin
+- password=iloveyou
+`)
+
+	// The encrypted values differ on every run,
+	// so they're masked before comparing.
+	th.AssertActualEqualsExpectedWithTweak(rm, func(x []byte) []byte {
+		var lines []string
+		for _, line := range strings.Split(string(x), "\n") {
+			if i := strings.Index(line, ": "); i > 0 && len(line)-i > 100 {
+				line = line[:i] + ": SEALED"
+			}
+			lines = append(lines, line)
+		}
+		return []byte(strings.Join(lines, "\n"))
+	}, `
+apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  annotations:
+    sealedsecrets.bitnami.com/cluster-wide: "true"
+  name: mySecret
+spec:
+  encryptedData:
+    password: SEALED
+    username: SEALED
+  template:
+    metadata:
+      name: mySecret
+    type: kubernetes.io/basic-auth
+`)
+}
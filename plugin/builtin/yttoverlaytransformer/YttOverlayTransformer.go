@@ -0,0 +1,208 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// yttIndexAnnotation tracks resources through ytt,
+// by their index in the resources given to it.
+const yttIndexAnnotation = "kustomize.config.k8s.io/ytt-index"
+
+// Run ytt overlays against the resources selected by Target,
+// or all of them.  Resources the overlays change replace the
+// originals in place, those they remove are removed, and those
+// they add are appended.
+//
+// Overlays may run arbitrary ytt code, and the ytt program is
+// found on the PATH, so the transformer must be enabled with
+// FnPluginLoadingOptions.EnableYtt (--enable-ytt).
+type plugin struct {
+	h *resmap.PluginHelpers
+
+	// Overlays are the ytt files to run, in order, relative
+	// to the kustomization.  Files of data values may be
+	// listed too.
+	Overlays []string `json:"overlays,omitempty" yaml:"overlays,omitempty"`
+
+	// DataValues are passed to ytt with --data-value.
+	DataValues map[string]string `json:"dataValues,omitempty" yaml:"dataValues,omitempty"`
+
+	Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	p.Overlays = nil
+	p.DataValues = nil
+	p.Target = nil
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	if len(p.Overlays) == 0 {
+		return fmt.Errorf("must specify at least one overlay")
+	}
+	p.h = h
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	selected := m.Resources()
+	if p.Target != nil {
+		var err error
+		selected, err = m.Select(*p.Target)
+		if err != nil {
+			return err
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	dir, err := ioutil.TempDir("", "kustomize-ytt-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	input, err := p.writeInput(dir, selected)
+	if err != nil {
+		return err
+	}
+	args := []string{"-f", input}
+	for i, overlay := range p.Overlays {
+		b, err := p.h.Loader().Load(overlay)
+		if err != nil {
+			return errors.Wrapf(err, "loading ytt overlay %s", overlay)
+		}
+		// Files keep their names, which ytt reports in errors,
+		// in directories of their own, so they can't collide.
+		path := filepath.Join(dir, strconv.Itoa(i+1), filepath.Base(overlay))
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(path, b, 0600); err != nil {
+			return err
+		}
+		args = append(args, "-f", path)
+	}
+	var keys []string
+	for k := range p.DataValues {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--data-value", k+"="+p.DataValues[k])
+	}
+	out, err := runYtt(args)
+	if err != nil {
+		return err
+	}
+	outputs, err := p.h.ResmapFactory().NewResMapFromBytes(out)
+	if err != nil {
+		return errors.Wrap(err, "reading ytt output")
+	}
+	return replaceSelected(m, selected, outputs)
+}
+
+// writeInput writes the selected resources, annotated
+// with their index, to a file in dir.
+func (p *plugin) writeInput(dir string, selected []*resource.Resource) (string, error) {
+	var buf bytes.Buffer
+	for i, r := range selected {
+		c := r.DeepCopy()
+		annotations := c.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[yttIndexAnnotation] = strconv.Itoa(i)
+		c.SetAnnotations(annotations)
+		b, err := c.AsYAML()
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(b)
+	}
+	path := filepath.Join(dir, "resources.yaml")
+	return path, ioutil.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// replaceSelected replaces the selected resources of m
+// with the outputs of ytt.
+func replaceSelected(m resmap.ResMap, selected []*resource.Resource, outputs resmap.ResMap) error {
+	kept := make([]bool, len(selected))
+	var added []*resource.Resource
+	for _, r := range outputs.Resources() {
+		annotations := r.GetAnnotations()
+		v, found := annotations[yttIndexAnnotation]
+		if !found {
+			added = append(added, r)
+			continue
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i >= len(selected) || kept[i] {
+			return fmt.Errorf(
+				"ytt output has an invalid %s annotation %q", yttIndexAnnotation, v)
+		}
+		delete(annotations, yttIndexAnnotation)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		r.SetAnnotations(annotations)
+		selected[i].ResetPrimaryData(r)
+		kept[i] = true
+	}
+	for i, r := range selected {
+		if !kept[i] {
+			if err := m.Remove(r.CurId()); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range added {
+		if err := m.Append(r); err != nil {
+			return errors.Wrap(err, "adding resource made by ytt")
+		}
+	}
+	return nil
+}
+
+// runYtt runs the ytt program with args,
+// returning what it writes to stdout.
+func runYtt(args []string) ([]byte, error) {
+	ytt, err := exec.LookPath("ytt")
+	if err != nil {
+		return nil, errors.Wrap(err, "running ytt overlays needs the ytt program")
+	}
+	cmd := exec.Command(ytt, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ytt: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
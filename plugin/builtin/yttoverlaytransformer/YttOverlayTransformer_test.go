@@ -0,0 +1,101 @@
+// Copyright 2020 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// A fake ytt program, which replaces the resources
+// given to it with a ConfigMap, as an overlay removing
+// them and adding one would.
+const fakeYtt = `#!/bin/sh
+echo "apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added"
+`
+
+func TestYttOverlayTransformer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ytt is a shell script")
+	}
+	bin, err := ioutil.TempDir("", "kustomize-ytt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bin)
+	if err = ioutil.WriteFile(filepath.Join(bin, "ytt"), []byte(fakeYtt), 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	th := kusttest_test.MakeEnhancedHarness(t).
+		SetFnpLoadingOptions(types.FnPluginLoadingOptions{EnableYtt: true}).
+		PrepBuiltin("YttOverlayTransformer")
+	defer th.Reset()
+
+	th.WriteF("overlay.yaml", "#@ load(\"@ytt:overlay\", \"overlay\")\n")
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: YttOverlayTransformer
+metadata:
+  name: notImportantHere
+overlays:
+- overlay.yaml
+target:
+  kind: Deployment
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added
+`)
+}
+
+func TestYttOverlayTransformerNeedsOverlays(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		SetFnpLoadingOptions(types.FnPluginLoadingOptions{EnableYtt: true}).
+		PrepBuiltin("YttOverlayTransformer")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: YttOverlayTransformer
+metadata:
+  name: notImportantHere
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	if err == nil || !strings.Contains(err.Error(), "must specify at least one overlay") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}